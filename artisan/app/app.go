@@ -7,6 +7,7 @@ package app
 
 import (
 	"errors"
+	"io/ioutil"
 	"os"
 	"strconv"
 
@@ -186,4 +187,134 @@ var Commands = []*cli.Command{
 			return nil
 		},
 	},
+	{
+		Name:      "app:key:add",
+		Category:  category,
+		Usage:     "authorize a public key to log in to sftp server with application or token",
+		UsageText: "app:key:add [command options]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "uid",
+				Aliases: []string{"u"},
+				Usage:   "application uid",
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Aliases: []string{"t"},
+				Usage:   "token uid, the key is authorized for token if it's specified",
+			},
+			&cli.StringFlag{
+				Name:    "key-file",
+				Aliases: []string{"k"},
+				Usage:   "public key file, such as ~/.ssh/id_rsa.pub",
+			},
+		},
+		Before: before,
+		Action: func(ctx *cli.Context) error {
+			app, token, err := findAppAndToken(ctx.String("uid"), ctx.String("token"))
+			if err != nil {
+				return err
+			}
+			content, err := ioutil.ReadFile(ctx.String("key-file"))
+			if err != nil {
+				return err
+			}
+			key, err := models.NewPublicKey(app, token, string(content), connection)
+			if err != nil {
+				return err
+			}
+			renderPublicKeys([]models.PublicKey{*key}, token)
+			return nil
+		},
+	},
+	{
+		Name:      "app:key:list",
+		Category:  category,
+		Usage:     "list the public keys of application or token",
+		UsageText: "app:key:list [command options]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "uid",
+				Aliases: []string{"u"},
+				Usage:   "application uid",
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Aliases: []string{"t"},
+				Usage:   "token uid, the keys of token are listed if it's specified",
+			},
+		},
+		Before: before,
+		Action: func(ctx *cli.Context) error {
+			app, token, err := findAppAndToken(ctx.String("uid"), ctx.String("token"))
+			if err != nil {
+				return err
+			}
+			keys, err := models.FindPublicKeys(app, token, connection)
+			if err != nil {
+				return err
+			}
+			renderPublicKeys(keys, token)
+			return nil
+		},
+	},
+	{
+		Name:      "app:key:delete",
+		Category:  category,
+		Usage:     "revoke a public key",
+		UsageText: "app:key:delete [command options]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "key",
+				Usage: "public key uid",
+			},
+		},
+		Before: before,
+		Action: func(ctx *cli.Context) error {
+			key, err := models.FindPublicKeyByUID(ctx.String("key"), connection)
+			if err != nil {
+				return err
+			}
+			if err = key.Delete(connection); err != nil {
+				return err
+			}
+			logger.Infof("delete public key: %s", key.UID)
+			return nil
+		},
+	},
+}
+
+// findAppAndToken is used to find app by uid, and token by tokenUID if it isn't
+// empty, the token must belong to the app
+func findAppAndToken(uid, tokenUID string) (app *models.App, token *models.Token, err error) {
+	if app, err = models.FindAppByUID(uid, connection); err != nil {
+		return nil, nil, err
+	}
+	if tokenUID == "" {
+		return app, nil, nil
+	}
+	if token, err = models.FindTokenByUID(tokenUID, connection); err != nil {
+		return nil, nil, err
+	}
+	if token.AppID != app.ID {
+		return nil, nil, errors.New("token doesn't belong to the application")
+	}
+	return app, token, nil
+}
+
+// renderPublicKeys is used to print public keys as a table
+func renderPublicKeys(keys []models.PublicKey, token *models.Token) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"UID", "Token", "Fingerprint", "Comment", "CreatedAt"})
+	for _, key := range keys {
+		tokenUID, comment := "", ""
+		if token != nil {
+			tokenUID = token.UID
+		}
+		if key.Comment != nil {
+			comment = *key.Comment
+		}
+		table.Append([]string{key.UID, tokenUID, key.Fingerprint, comment, key.CreatedAt.Format("2006-01-02 15:04:05")})
+	}
+	table.Render()
 }
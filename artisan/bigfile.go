@@ -17,6 +17,11 @@ import (
 	"github.com/bigfile/bigfile/artisan/migrate"
 	"github.com/bigfile/bigfile/artisan/multi"
 	"github.com/bigfile/bigfile/artisan/rpc"
+	"github.com/bigfile/bigfile/artisan/s3"
+	"github.com/bigfile/bigfile/artisan/sftp"
+	"github.com/bigfile/bigfile/artisan/sweeper"
+	"github.com/bigfile/bigfile/artisan/webdav"
+	"github.com/bigfile/bigfile/artisan/webhook"
 	"github.com/bigfile/bigfile/config"
 	"github.com/bigfile/bigfile/log"
 	"github.com/gookit/color"
@@ -120,6 +125,11 @@ func main() {
 	commands = append(commands, rpc.Commands...)
 	commands = append(commands, ftp.Commands...)
 	commands = append(commands, multi.Commands...)
+	commands = append(commands, sweeper.Commands...)
+	commands = append(commands, webhook.Commands...)
+	commands = append(commands, s3.Commands...)
+	commands = append(commands, webdav.Commands...)
+	commands = append(commands, sftp.Commands...)
 	app.Commands = commands
 
 	sort.Sort(cli.FlagsByName(app.Flags))
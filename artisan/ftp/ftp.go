@@ -100,7 +100,7 @@ var (
 				},
 			},
 			Action: func(ctx *cli.Context) error {
-				logger := &loggerAdapter{log.MustNewLogger(nil)}
+				logger := ftp.NewAuditLogger(&loggerAdapter{log.MustNewLogger(nil)})
 				passivePortRange := ctx.String("passive-port-range")
 				if len(passivePortRange) > 0 {
 					portRange := strings.Split(passivePortRange, "-")
@@ -124,7 +124,7 @@ var (
 					Auth:           &ftp.Auth{},
 					Port:           port,
 					Logger:         logger,
-					Factory:        &ftp.Factory{},
+					Factory:        &ftp.Factory{Logger: logger},
 					KeyFile:        ctx.String("key-file"),
 					Hostname:       host,
 					CertFile:       ctx.String("cert-file"),
@@ -29,12 +29,17 @@ import (
 	"github.com/bigfile/bigfile/internal/util"
 	"github.com/bigfile/bigfile/log"
 	"github.com/bigfile/bigfile/rpc"
+	"github.com/bigfile/bigfile/s3"
+	"github.com/bigfile/bigfile/service"
+	"github.com/bigfile/bigfile/sftp"
+	"github.com/bigfile/bigfile/webdav"
 	"github.com/gin-gonic/gin"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/op/go-logging"
 	"goftp.io/server"
+	"golang.org/x/crypto/ssh"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
@@ -98,7 +103,7 @@ var (
 					return nil
 				}
 
-				wg.Add(3)
+				wg.Add(8)
 
 				go func() {
 					defer wg.Done()
@@ -115,6 +120,31 @@ var (
 					_ = startRPCServer(ctx, sig)
 				}()
 
+				go func() {
+					defer wg.Done()
+					startSweeper(ctx, sig)
+				}()
+
+				go func() {
+					defer wg.Done()
+					startWebhookDispatcher(ctx, sig)
+				}()
+
+				go func() {
+					defer wg.Done()
+					_ = startS3Server(ctx, sig)
+				}()
+
+				go func() {
+					defer wg.Done()
+					_ = startWebDAVServer(ctx, sig)
+				}()
+
+				go func() {
+					defer wg.Done()
+					_ = startSFTPServer(ctx, sig)
+				}()
+
 				quit := make(chan os.Signal, 1)
 				signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 				<-quit
@@ -219,6 +249,56 @@ var (
 					Usage: "rpc service listen port",
 					Value: 10986,
 				},
+				// s3 parameters
+				&cli.Int64Flag{
+					Name:  "s3-port",
+					Usage: "s3 gateway listen port",
+					Value: 10987,
+				},
+				&cli.StringFlag{
+					Name:  "s3-region",
+					Usage: "the region that is reported to s3 clients",
+					Value: s3.DefaultRegion,
+				},
+				// webdav parameters
+				&cli.Int64Flag{
+					Name:  "webdav-port",
+					Usage: "WebDAV server listen port",
+					Value: 10988,
+				},
+				// sftp parameters
+				&cli.Int64Flag{
+					Name:  "sftp-port",
+					Usage: "sftp server listen port",
+					Value: 2222,
+				},
+				&cli.StringFlag{
+					Name:  "sftp-host-key",
+					Usage: "private key file of ssh host, server-key is used if it's empty",
+					Value: "",
+				},
+				// sweeper parameters
+				&cli.DurationFlag{
+					Name:  "sweeper-interval",
+					Usage: "the interval between two rounds of deleting expired files and nonces",
+					Value: time.Minute,
+				},
+				&cli.IntFlag{
+					Name:  "sweeper-limit",
+					Usage: "the max number of expired files or nonces that are deleted in one round",
+					Value: 100,
+				},
+				// webhook parameters
+				&cli.DurationFlag{
+					Name:  "webhook-interval",
+					Usage: "the interval between two rounds of sending webhook deliveries",
+					Value: 5 * time.Second,
+				},
+				&cli.IntFlag{
+					Name:  "webhook-limit",
+					Usage: "the max number of webhook deliveries that are sent in one round",
+					Value: 100,
+				},
 			},
 			Before: func(ctx *cli.Context) (err error) {
 				gin.SetMode(gin.ReleaseMode)
@@ -281,12 +361,13 @@ func startFtpServer(ctx *cli.Context, sig chan struct{}) error {
 	}
 	host := ctx.String("host")
 	port := int(ctx.Uint("ftp-port"))
+	auditLogger := ftp.NewAuditLogger(logger)
 	options := &server.ServerOpts{
 		TLS:            true,
 		Auth:           &ftp.Auth{},
 		Port:           port,
-		Logger:         logger,
-		Factory:        &ftp.Factory{},
+		Logger:         auditLogger,
+		Factory:        &ftp.Factory{Logger: auditLogger},
 		KeyFile:        ctx.String("server-key"),
 		Hostname:       host,
 		CertFile:       ctx.String("server-cert"),
@@ -341,12 +422,18 @@ func startRPCServer(ctx *cli.Context, sig chan struct{}) (err error) {
 			grpc_middleware.ChainStreamServer(
 				grpc_prometheus.StreamServerInterceptor,
 				grpc_recovery.StreamServerInterceptor(),
+				rpc.SignatureStreamServerInterceptor,
+				rpc.RateLimitStreamServerInterceptor,
+				rpc.NonceStreamServerInterceptor,
 			),
 		),
 		grpc.UnaryInterceptor(
 			grpc_middleware.ChainUnaryServer(
 				grpc_prometheus.UnaryServerInterceptor,
 				grpc_recovery.UnaryServerInterceptor(),
+				rpc.SignatureUnaryServerInterceptor,
+				rpc.RateLimitUnaryServerInterceptor,
+				rpc.NonceUnaryServerInterceptor,
 			),
 		),
 	)
@@ -360,6 +447,15 @@ func startRPCServer(ctx *cli.Context, sig chan struct{}) (err error) {
 	rpc.RegisterFileReadServer(rpcServer, service)
 	rpc.RegisterFileUpdateServer(rpcServer, service)
 	rpc.RegisterFileDeleteServer(rpcServer, service)
+	rpc.RegisterFileStatServer(rpcServer, service)
+	rpc.RegisterFileLockServer(rpcServer, service)
+	rpc.RegisterFileBatchServer(rpcServer, service)
+	rpc.RegisterFileRetentionServer(rpcServer, service)
+	rpc.RegisterFileArchiveServer(rpcServer, service)
+	rpc.RegisterFileExtractServer(rpcServer, service)
+	rpc.RegisterChangeFeedServer(rpcServer, service)
+	rpc.RegisterWebhookServer(rpcServer, service)
+	rpc.RegisterWatchServer(rpcServer, service)
 
 	go func() {
 		log.MustNewLogger(nil).Debugf("bigfile rpc service listening on: tcp://%s", listener.Addr().String())
@@ -372,3 +468,87 @@ func startRPCServer(ctx *cli.Context, sig chan struct{}) (err error) {
 	rpcServer.GracefulStop()
 	return nil
 }
+
+func startSweeper(ctx *cli.Context, sig chan struct{}) {
+	db := databases.MustNewConnection(&config.DefaultConfig.Database)
+	log.MustNewLogger(nil).Debugf("bigfile sweeper is running every %s", ctx.Duration("sweeper-interval"))
+	go service.SweepExpiredNonces(db, ctx.Duration("sweeper-interval"), ctx.Int("sweeper-limit"), sig)
+	service.SweepExpiredFiles(db, ctx.Duration("sweeper-interval"), ctx.Int("sweeper-limit"), sig)
+	log.MustNewLogger(nil).Debug("Shutdown Sweeper ...")
+}
+
+func startWebhookDispatcher(ctx *cli.Context, sig chan struct{}) {
+	db := databases.MustNewConnection(&config.DefaultConfig.Database)
+	log.MustNewLogger(nil).Debugf("bigfile webhook dispatcher is running every %s", ctx.Duration("webhook-interval"))
+	service.DispatchWebhooks(db, ctx.Duration("webhook-interval"), ctx.Int("webhook-limit"), sig)
+	log.MustNewLogger(nil).Debug("Shutdown Webhook Dispatcher ...")
+}
+
+func startS3Server(ctx *cli.Context, sig chan struct{}) error {
+	addr := fmt.Sprintf("%s:%d", ctx.String("host"), ctx.Int64("s3-port"))
+	s := libHTTP.Server{
+		Addr:    addr,
+		Handler: s3.NewServer(ctx.String("s3-region"), nil),
+	}
+	go func() {
+		logger.Debugf("bigfile s3 gateway listening on: https://%s", addr)
+		if err := s.ListenAndServeTLS(ctx.String("server-cert"), ctx.String("server-key")); err != nil && err != libHTTP.ErrServerClosed {
+			logger.Errorf("s3 gateway error: %s", err)
+		}
+	}()
+	<-sig
+	logger.Debug("Shutdown S3 Gateway ...")
+	c, cancel := context.WithTimeout(context.Background(), ctx.Duration("http-wait-shutdown"))
+	defer cancel()
+	if err := s.Shutdown(c); err != nil {
+		logger.Errorf("S3 Gateway Shutdown: %s", err)
+	}
+	logger.Debug("S3 Gateway exiting")
+	return nil
+}
+
+func startWebDAVServer(ctx *cli.Context, sig chan struct{}) error {
+	addr := fmt.Sprintf("%s:%d", ctx.String("host"), ctx.Int64("webdav-port"))
+	s := libHTTP.Server{
+		Addr:    addr,
+		Handler: webdav.NewServer("", nil),
+	}
+	go func() {
+		logger.Debugf("bigfile WebDAV server listening on: https://%s", addr)
+		if err := s.ListenAndServeTLS(ctx.String("server-cert"), ctx.String("server-key")); err != nil && err != libHTTP.ErrServerClosed {
+			logger.Errorf("WebDAV server error: %s", err)
+		}
+	}()
+	<-sig
+	logger.Debug("Shutdown WebDAV Server ...")
+	c, cancel := context.WithTimeout(context.Background(), ctx.Duration("http-wait-shutdown"))
+	defer cancel()
+	if err := s.Shutdown(c); err != nil {
+		logger.Errorf("WebDAV Server Shutdown: %s", err)
+	}
+	logger.Debug("WebDAV Server exiting")
+	return nil
+}
+
+func startSFTPServer(ctx *cli.Context, sig chan struct{}) error {
+	hostKeyFile := ctx.String("sftp-host-key")
+	if hostKeyFile == "" {
+		hostKeyFile = ctx.String("server-key")
+	}
+	hostKey, err := sftp.LoadHostKey(hostKeyFile)
+	if err != nil {
+		logger.Errorf("sftp, load host key failed, %s", err)
+		return err
+	}
+	addr := fmt.Sprintf("%s:%d", ctx.String("host"), ctx.Int64("sftp-port"))
+	s := sftp.NewServer([]ssh.Signer{hostKey}, nil)
+	go func() {
+		logger.Debugf("bigfile sftp server listening on: sftp://%s", addr)
+		if err := s.ListenAndServe(addr); err != nil && err != sftp.ErrServerClosed {
+			logger.Errorf("sftp server error: %s", err)
+		}
+	}()
+	<-sig
+	logger.Debug("Shutdown SFTP Server ...")
+	return s.Close()
+}
@@ -138,12 +138,18 @@ var (
 						grpc_middleware.ChainStreamServer(
 							grpc_prometheus.StreamServerInterceptor,
 							grpc_recovery.StreamServerInterceptor(),
+							rpc.SignatureStreamServerInterceptor,
+							rpc.RateLimitStreamServerInterceptor,
+							rpc.NonceStreamServerInterceptor,
 						),
 					),
 					grpc.UnaryInterceptor(
 						grpc_middleware.ChainUnaryServer(
 							grpc_prometheus.UnaryServerInterceptor,
 							grpc_recovery.UnaryServerInterceptor(),
+							rpc.SignatureUnaryServerInterceptor,
+							rpc.RateLimitUnaryServerInterceptor,
+							rpc.NonceUnaryServerInterceptor,
 						),
 					),
 				)
@@ -157,6 +163,15 @@ var (
 				rpc.RegisterFileReadServer(rpcServer, service)
 				rpc.RegisterFileUpdateServer(rpcServer, service)
 				rpc.RegisterFileDeleteServer(rpcServer, service)
+				rpc.RegisterFileStatServer(rpcServer, service)
+				rpc.RegisterFileLockServer(rpcServer, service)
+				rpc.RegisterFileBatchServer(rpcServer, service)
+				rpc.RegisterFileRetentionServer(rpcServer, service)
+				rpc.RegisterFileArchiveServer(rpcServer, service)
+				rpc.RegisterFileExtractServer(rpcServer, service)
+				rpc.RegisterChangeFeedServer(rpcServer, service)
+				rpc.RegisterWebhookServer(rpcServer, service)
+				rpc.RegisterWatchServer(rpcServer, service)
 
 				go func() {
 					log.MustNewLogger(nil).Infof("bigfile rpc service listening on: tcp://%s", listener.Addr().String())
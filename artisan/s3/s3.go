@@ -0,0 +1,115 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+// Package s3 is used to provide the entry of s3 gateway
+package s3
+
+import (
+	ctx "context"
+	"fmt"
+	libHTTP "net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases"
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/bigfile/bigfile/log"
+	"github.com/bigfile/bigfile/s3"
+	"gopkg.in/urfave/cli.v2"
+
+	// import migration
+	_ "github.com/bigfile/bigfile/databases/migrate/migrations"
+)
+
+var (
+	category = "s3"
+	logger   = log.MustNewLogger(nil)
+
+	// Commands represent the s3 gateway start command
+	Commands = []*cli.Command{
+		{
+			Name:      "s3:start",
+			Category:  category,
+			Usage:     "start s3 compatible gateway",
+			UsageText: "s3:start [command options]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "host",
+					Aliases: []string{"H"},
+					Usage:   "s3 gateway listen ip",
+					Value:   "0.0.0.0",
+				},
+				&cli.Int64Flag{
+					Name:    "port",
+					Aliases: []string{"P"},
+					Usage:   "s3 gateway listen port",
+					Value:   10987,
+				},
+				&cli.StringFlag{
+					Name:  "region",
+					Usage: "the region that is reported to s3 clients",
+					Value: s3.DefaultRegion,
+				},
+				&cli.DurationFlag{
+					Name:  "wait-shutdown",
+					Usage: "wait time before timeout for closing server",
+					Value: 5 * time.Second,
+				},
+				&cli.StringFlag{
+					Name:  "cert-file",
+					Usage: "certificate file for starting https service",
+				},
+				&cli.StringFlag{
+					Name:  "cert-key",
+					Usage: "certificate key file for starting https service",
+				},
+			},
+			Action: func(context *cli.Context) error {
+				addr := fmt.Sprintf("%s:%d", context.String("host"), context.Int64("port"))
+				server := libHTTP.Server{
+					Addr:    addr,
+					Handler: s3.NewServer(context.String("region"), nil),
+				}
+				certFile := context.String("cert-file")
+				certKey := context.String("cert-key")
+
+				go func() {
+					if certFile != "" && certKey != "" {
+						logger.Infof("bigfile s3 gateway listening on: https://%s", addr)
+						if err := server.ListenAndServeTLS(certFile, certKey); err != nil && err != libHTTP.ErrServerClosed {
+							logger.Errorf("s3 gateway error: %s", err)
+						}
+					} else {
+						logger.Infof("bigfile s3 gateway listening on: http://%s", addr)
+						if err := server.ListenAndServe(); err != nil && err != libHTTP.ErrServerClosed {
+							logger.Errorf("s3 gateway error: %s", err)
+						}
+					}
+				}()
+
+				quit := make(chan os.Signal, 1)
+				signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+				<-quit
+				logger.Debug("Shutdown S3 Gateway ...")
+
+				ctx, cancel := ctx.WithTimeout(ctx.Background(), context.Duration("wait-shutdown"))
+				defer cancel()
+				if err := server.Shutdown(ctx); err != nil {
+					logger.Fatal("S3 Gateway Shutdown:", err)
+				}
+				logger.Debug("S3 Gateway exiting")
+				return nil
+			},
+			Before: func(context *cli.Context) (err error) {
+				db := databases.MustNewConnection(&config.DefaultConfig.Database)
+				migrate.DefaultMC.SetConnection(db)
+				migrate.DefaultMC.Upgrade()
+				return nil
+			},
+		},
+	}
+)
@@ -0,0 +1,92 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+// Package sftp is used to provide the entry of sftp server
+package sftp
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases"
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/bigfile/bigfile/log"
+	"github.com/bigfile/bigfile/sftp"
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/urfave/cli.v2"
+
+	// import migration
+	_ "github.com/bigfile/bigfile/databases/migrate/migrations"
+)
+
+var (
+	category = "sftp"
+	logger   = log.MustNewLogger(nil)
+
+	// Commands represent the sftp server start command
+	Commands = []*cli.Command{
+		{
+			Name:      "sftp:start",
+			Category:  category,
+			Usage:     "start sftp server",
+			UsageText: "sftp:start [command options]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "host",
+					Aliases: []string{"H"},
+					Usage:   "sftp server listen ip",
+					Value:   "0.0.0.0",
+				},
+				&cli.Int64Flag{
+					Name:    "port",
+					Aliases: []string{"P"},
+					Usage:   "sftp server listen port",
+					Value:   2222,
+				},
+				&cli.StringSliceFlag{
+					Name:  "host-key",
+					Usage: "private key file of ssh host, it can be specified multiple times",
+				},
+			},
+			Action: func(context *cli.Context) error {
+				var hostKeys []ssh.Signer
+				if len(context.StringSlice("host-key")) == 0 {
+					logger.Error("host key is required")
+					return nil
+				}
+				for _, file := range context.StringSlice("host-key") {
+					hostKey, err := sftp.LoadHostKey(file)
+					if err != nil {
+						return err
+					}
+					hostKeys = append(hostKeys, hostKey)
+				}
+				addr := fmt.Sprintf("%s:%d", context.String("host"), context.Int64("port"))
+				server := sftp.NewServer(hostKeys, nil)
+
+				go func() {
+					logger.Infof("bigfile sftp server listening on: sftp://%s", addr)
+					if err := server.ListenAndServe(addr); err != nil && err != sftp.ErrServerClosed {
+						logger.Errorf("sftp server error: %s", err)
+					}
+				}()
+
+				quit := make(chan os.Signal, 1)
+				signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+				<-quit
+				logger.Debug("Shutdown SFTP Server ...")
+				return server.Close()
+			},
+			Before: func(context *cli.Context) (err error) {
+				db := databases.MustNewConnection(&config.DefaultConfig.Database)
+				migrate.DefaultMC.SetConnection(db)
+				migrate.DefaultMC.Upgrade()
+				return nil
+			},
+		},
+	}
+)
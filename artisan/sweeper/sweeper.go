@@ -0,0 +1,76 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+// Package sweeper is the entry for the background sweeper of expired files
+package sweeper
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases"
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/bigfile/bigfile/log"
+	"github.com/bigfile/bigfile/service"
+	"gopkg.in/urfave/cli.v2"
+
+	// import migration
+	_ "github.com/bigfile/bigfile/databases/migrate/migrations"
+)
+
+var (
+	category = "sweeper"
+
+	// Commands represent the sweeper start command
+	Commands = []*cli.Command{
+		{
+			Name:      "sweeper:start",
+			Category:  category,
+			Usage:     "start the sweeper that deletes expired files",
+			UsageText: "sweeper:start [command options]",
+			Flags: []cli.Flag{
+				&cli.DurationFlag{
+					Name:  "interval",
+					Usage: "the interval between two rounds of sweeping",
+					Value: time.Minute,
+				},
+				&cli.IntFlag{
+					Name:  "limit",
+					Usage: "the max number of files that are deleted in one round",
+					Value: 100,
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				var (
+					stop = make(chan struct{})
+					done = make(chan struct{})
+					db   = databases.MustNewConnection(&config.DefaultConfig.Database)
+				)
+
+				go func() {
+					defer close(done)
+					log.MustNewLogger(nil).Infof("bigfile sweeper is running every %s", ctx.Duration("interval"))
+					service.SweepExpiredFiles(db, ctx.Duration("interval"), ctx.Int("limit"), stop)
+				}()
+
+				quit := make(chan os.Signal, 1)
+				signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+				<-quit
+				log.MustNewLogger(nil).Debug("Shutdown Sweeper ...")
+				close(stop)
+				<-done
+				return nil
+			},
+			Before: func(context *cli.Context) (err error) {
+				db := databases.MustNewConnection(&config.DefaultConfig.Database)
+				migrate.DefaultMC.SetConnection(db)
+				migrate.DefaultMC.Upgrade()
+				return nil
+			},
+		},
+	}
+)
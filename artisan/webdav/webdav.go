@@ -0,0 +1,115 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+// Package webdav is used to provide the entry of WebDAV server
+package webdav
+
+import (
+	ctx "context"
+	"fmt"
+	libHTTP "net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases"
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/bigfile/bigfile/log"
+	"github.com/bigfile/bigfile/webdav"
+	"gopkg.in/urfave/cli.v2"
+
+	// import migration
+	_ "github.com/bigfile/bigfile/databases/migrate/migrations"
+)
+
+var (
+	category = "webdav"
+	logger   = log.MustNewLogger(nil)
+
+	// Commands represent the WebDAV server start command
+	Commands = []*cli.Command{
+		{
+			Name:      "webdav:start",
+			Category:  category,
+			Usage:     "start WebDAV server",
+			UsageText: "webdav:start [command options]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "host",
+					Aliases: []string{"H"},
+					Usage:   "WebDAV server listen ip",
+					Value:   "0.0.0.0",
+				},
+				&cli.Int64Flag{
+					Name:    "port",
+					Aliases: []string{"P"},
+					Usage:   "WebDAV server listen port",
+					Value:   10988,
+				},
+				&cli.StringFlag{
+					Name:  "prefix",
+					Usage: "the url path prefix of WebDAV server",
+					Value: "",
+				},
+				&cli.DurationFlag{
+					Name:  "wait-shutdown",
+					Usage: "wait time before timeout for closing server",
+					Value: 5 * time.Second,
+				},
+				&cli.StringFlag{
+					Name:  "cert-file",
+					Usage: "certificate file for starting https service",
+				},
+				&cli.StringFlag{
+					Name:  "cert-key",
+					Usage: "certificate key file for starting https service",
+				},
+			},
+			Action: func(context *cli.Context) error {
+				addr := fmt.Sprintf("%s:%d", context.String("host"), context.Int64("port"))
+				server := libHTTP.Server{
+					Addr:    addr,
+					Handler: webdav.NewServer(context.String("prefix"), nil),
+				}
+				certFile := context.String("cert-file")
+				certKey := context.String("cert-key")
+
+				go func() {
+					if certFile != "" && certKey != "" {
+						logger.Infof("bigfile WebDAV server listening on: https://%s", addr)
+						if err := server.ListenAndServeTLS(certFile, certKey); err != nil && err != libHTTP.ErrServerClosed {
+							logger.Errorf("WebDAV server error: %s", err)
+						}
+					} else {
+						logger.Infof("bigfile WebDAV server listening on: http://%s", addr)
+						if err := server.ListenAndServe(); err != nil && err != libHTTP.ErrServerClosed {
+							logger.Errorf("WebDAV server error: %s", err)
+						}
+					}
+				}()
+
+				quit := make(chan os.Signal, 1)
+				signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+				<-quit
+				logger.Debug("Shutdown WebDAV Server ...")
+
+				ctx, cancel := ctx.WithTimeout(ctx.Background(), context.Duration("wait-shutdown"))
+				defer cancel()
+				if err := server.Shutdown(ctx); err != nil {
+					logger.Fatal("WebDAV Server Shutdown:", err)
+				}
+				logger.Debug("WebDAV Server exiting")
+				return nil
+			},
+			Before: func(context *cli.Context) (err error) {
+				db := databases.MustNewConnection(&config.DefaultConfig.Database)
+				migrate.DefaultMC.SetConnection(db)
+				migrate.DefaultMC.Upgrade()
+				return nil
+			},
+		},
+	}
+)
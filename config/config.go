@@ -18,6 +18,10 @@ type Configurator struct {
 	Log      `yaml:"log,omitempty"`
 	HTTP     `yaml:"http,omitempty"`
 	Chunk    `yaml:"chunk,omitempty"`
+	Nonce    `yaml:"nonce,omitempty"`
+	Limit    `yaml:"limit,omitempty"`
+	Webhook  `yaml:"webhook,omitempty"`
+	Watch    `yaml:"watch,omitempty"`
 }
 
 // ParseConfigFile is used to parse configuration from yaml file to
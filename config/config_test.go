@@ -52,7 +52,24 @@ http:
   corsAllowAllOrigins: false
   corsMaxAge: 3600
 chunk:
-  rootPath: storage/chunks`
+  rootPath: storage/chunks
+nonce:
+  store: memory
+  capacity: 1000
+limit:
+  appRequests: 100
+  tokenRequests: 10.5
+  tokenBurst: 20
+  appBandwidth: 10485760
+  tokenBandwidth: 1048576
+  shareRequests: 5
+  sharePasswordFailures: 20
+webhook:
+  allowedHosts:
+    - hooks.internal
+    - 10.0.0.0/8
+watch:
+  interval: 10000`
 
 func assertConfigurator(t *testing.T, configurator *Configurator) {
 	confirm := assert.New(t)
@@ -91,6 +108,22 @@ func assertConfigurator(t *testing.T, configurator *Configurator) {
 	confirm.Equal(int64(3600), configurator.HTTP.CORSMaxAge)
 
 	confirm.Equal("storage/chunks", configurator.Chunk.RootPath)
+
+	confirm.Equal(NonceStoreMemory, configurator.NonceStore)
+	confirm.Equal(1000, configurator.NonceCapacity)
+
+	confirm.Equal(float64(100), configurator.LimitAppRequests)
+	confirm.Equal(int64(0), configurator.LimitAppBurst)
+	confirm.Equal(10.5, configurator.LimitTokenRequests)
+	confirm.Equal(int64(20), configurator.LimitTokenBurst)
+	confirm.Equal(int64(10485760), configurator.LimitAppBandwidth)
+	confirm.Equal(int64(1048576), configurator.LimitTokenBandwidth)
+	confirm.Equal(float64(5), configurator.LimitShareRequests)
+	confirm.Equal(int64(20), configurator.LimitSharePasswordFailures)
+
+	confirm.Equal([]string{"hooks.internal", "10.0.0.0/8"}, configurator.WebhookAllowedHosts)
+
+	confirm.Equal(int64(10000), configurator.WatchInterval)
 }
 
 func TestParseConfigFile(t *testing.T) {
@@ -58,6 +58,7 @@ func init() {
 			LimitRateByIPEnable:   false,
 			LimitRateByIPInterval: 1000,
 			LimitRateByIPMaxNum:   100,
+			SignedBodyMaxSize:     1073741824,
 			CORSEnable:            false,
 			CORSAllowAllOrigins:   false,
 			CORSAllowCredentials:  false,
@@ -69,5 +70,17 @@ func init() {
 		Chunk{
 			RootPath: "storage/chunks",
 		},
+		Nonce{
+			NonceStore:    NonceStoreDatabase,
+			NonceCapacity: 100000,
+		},
+		Limit{
+			LimitShareRequests:         10,
+			LimitSharePasswordFailures: 10,
+		},
+		Webhook{},
+		Watch{
+			WatchInterval: 5000,
+		},
 	}
 }
@@ -26,6 +26,11 @@ type HTTP struct {
 	// default: 100
 	LimitRateByIPMaxNum uint `yaml:"limitRateByIPMaxNum,omitempty"`
 
+	// SignedBodyMaxSize represent the max bytes of the body of request that is
+	// signed by v2 signature, the body is hashed before the signature is
+	// verified. Zero means unlimited, default: 1073741824, that is 1GB
+	SignedBodyMaxSize int64 `yaml:"signedBodyMaxSize,omitempty"`
+
 	CORSEnable           bool     `yaml:"corsEnable,omitempty"`
 	CORSAllowAllOrigins  bool     `yaml:"corsAllowAllOrigins,omitempty"`
 	CORSAllowOrigins     []string `yaml:"corsAllowOrigins,omitempty"`
@@ -0,0 +1,46 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package config
+
+// Limit represent config for the request rate and bandwidth limits of apps
+// and tokens, they're applied to http, rpc and ftp. Zero means unlimited.
+type Limit struct {
+	// LimitAppRequests represent the max number of requests per second of an
+	// app, default: 0
+	LimitAppRequests float64 `yaml:"appRequests,omitempty"`
+
+	// LimitAppBurst represent the max number of requests of an app that can be
+	// accepted at once, default: the ceil of LimitAppRequests
+	LimitAppBurst int64 `yaml:"appBurst,omitempty"`
+
+	// LimitTokenRequests represent the max number of requests per second of a
+	// token, default: 0
+	LimitTokenRequests float64 `yaml:"tokenRequests,omitempty"`
+
+	// LimitTokenBurst represent the max number of requests of a token that can
+	// be accepted at once, default: the ceil of LimitTokenRequests
+	LimitTokenBurst int64 `yaml:"tokenBurst,omitempty"`
+
+	// LimitAppBandwidth represent the max bytes per second that are uploaded
+	// or downloaded by an app, default: 0
+	LimitAppBandwidth int64 `yaml:"appBandwidth,omitempty"`
+
+	// LimitTokenBandwidth represent the max bytes per second that are uploaded
+	// or downloaded by a token, default: 0
+	LimitTokenBandwidth int64 `yaml:"tokenBandwidth,omitempty"`
+
+	// LimitShareRequests represent the max number of requests per second of a
+	// share link from an ip, default: 10
+	LimitShareRequests float64 `yaml:"shareRequests,omitempty"`
+
+	// LimitShareBurst represent the max number of requests of a share link
+	// from an ip that can be accepted at once, default: the ceil of
+	// LimitShareRequests
+	LimitShareBurst int64 `yaml:"shareBurst,omitempty"`
+
+	// LimitSharePasswordFailures represent the max number of wrong passwords
+	// of a share link from an ip per hour, default: 10
+	LimitSharePasswordFailures int64 `yaml:"sharePasswordFailures,omitempty"`
+}
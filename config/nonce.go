@@ -0,0 +1,27 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package config
+
+const (
+	// NonceStoreDatabase represent that nonces are stored in database, they're
+	// shared by all instances
+	NonceStoreDatabase = "database"
+
+	// NonceStoreMemory represent that nonces are stored in memory, they're
+	// only seen by the instance itself
+	NonceStoreMemory = "memory"
+)
+
+// Nonce represent config for the nonces of replay protection
+type Nonce struct {
+	// NonceStore is one of database and memory, default: database
+	NonceStore string `yaml:"store,omitempty"`
+
+	// NonceCapacity represent the max number of nonces in memory store, the
+	// least recently used one is evicted if it's full, default: 100000. The
+	// nonces of the requests without signed timestamp never expire, they're
+	// only forgotten by eviction, so database store is recommended for them.
+	NonceCapacity int `yaml:"capacity,omitempty"`
+}
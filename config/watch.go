@@ -0,0 +1,13 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package config
+
+// Watch represent config for watching the changes of files
+type Watch struct {
+	// WatchInterval represent the interval of checking the journal for new
+	// changes, every watch checks it once per interval after it has caught
+	// up, unit: ms, default: 5000
+	WatchInterval int64 `yaml:"interval,omitempty"`
+}
@@ -0,0 +1,14 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package config
+
+// Webhook represent config for the deliveries of webhooks
+type Webhook struct {
+	// WebhookAllowedHosts represent the hosts, ips or cidrs that webhooks can
+	// be delivered to, even if they're loopback, private or other reserved
+	// addresses. The reserved addresses are rejected by default, so that the
+	// internal network can't be reached by webhooks, default: empty
+	WebhookAllowedHosts []string `yaml:"allowedHosts,omitempty"`
+}
@@ -0,0 +1,68 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&AddFullPathToFilesTable20190901143256{})
+}
+
+// AddFullPathToFilesTable20190901143256 represent some database operate
+type AddFullPathToFilesTable20190901143256 struct{}
+
+// Name represent operate name, it's unique
+func (a *AddFullPathToFilesTable20190901143256) Name() string {
+	return "add_full_path_to_files_table_20190901143256"
+}
+
+// Up is executed in upgrading
+func (a *AddFullPathToFilesTable20190901143256) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	var err error
+	if err = db.Exec(`
+		ALTER TABLE files
+		  ADD fullPath VARCHAR(1000) NOT NULL DEFAULT '' AFTER downloadCount,
+		  ADD pathHash CHAR(32) NOT NULL DEFAULT '' AFTER fullPath,
+		  ADD KEY appId_pathHash_idx (appId, pathHash),
+		  ADD KEY appId_fullPath_idx (appId, fullPath(255))
+	`).Error; err != nil {
+		return err
+	}
+
+	// fill the path of existing files level by level, from root directories
+	if err = db.Exec("UPDATE files SET fullPath = '/' WHERE pid = 0").Error; err != nil {
+		return err
+	}
+	for {
+		result := db.Exec(`
+			UPDATE files c JOIN files p ON c.pid = p.id
+			SET c.fullPath = CONCAT(IF(p.fullPath = '/', '', p.fullPath), '/', c.name), c.updatedAt = c.updatedAt
+			WHERE c.fullPath = '' AND p.fullPath != ''
+		`)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			break
+		}
+	}
+	return db.Exec("UPDATE files SET pathHash = MD5(fullPath), updatedAt = updatedAt").Error
+}
+
+// Down is executed in downgrading
+func (a *AddFullPathToFilesTable20190901143256) Down(db *gorm.DB) error {
+	// execute when rollback database
+	return db.Exec(`
+		ALTER TABLE files
+		  DROP KEY appId_pathHash_idx,
+		  DROP KEY appId_fullPath_idx,
+		  DROP COLUMN pathHash,
+		  DROP COLUMN fullPath
+	`).Error
+}
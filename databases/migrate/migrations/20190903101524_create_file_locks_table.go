@@ -0,0 +1,50 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&CreateFileLocksTable20190903101524{})
+}
+
+// CreateFileLocksTable20190903101524 represent some database operate
+type CreateFileLocksTable20190903101524 struct{}
+
+// Name represent operate name, it's unique
+func (c *CreateFileLocksTable20190903101524) Name() string {
+	return "create_file_locks_table_20190903101524"
+}
+
+// Up is executed in upgrading
+func (c *CreateFileLocksTable20190903101524) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS file_locks (
+		  id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+		  uid CHAR(32) NOT NULL,
+		  appId BIGINT(20) UNSIGNED NOT NULL,
+		  fileId BIGINT(20) UNSIGNED NOT NULL,
+		  owner VARCHAR(64) NOT NULL,
+		  exclusive TINYINT UNSIGNED NOT NULL DEFAULT 0,
+		  expiredAt timestamp(6) NOT NULL,
+		  createdAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  updatedAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+		  PRIMARY KEY (id),
+		  UNIQUE INDEX uid_UNIQUE (uid ASC),
+		  UNIQUE fileId_owner_unique (fileId, owner),
+		  KEY appId_expiredAt_idx (appId, expiredAt))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error
+}
+
+// Down is executed in downgrading
+func (c *CreateFileLocksTable20190903101524) Down(db *gorm.DB) error {
+	// execute when rollback database
+	return db.DropTableIfExists("file_locks").Error
+}
@@ -0,0 +1,42 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&AddExpiredAtToFilesTable20190906092311{})
+}
+
+// AddExpiredAtToFilesTable20190906092311 represent some database operate
+type AddExpiredAtToFilesTable20190906092311 struct{}
+
+// Name represent operate name, it's unique
+func (a *AddExpiredAtToFilesTable20190906092311) Name() string {
+	return "add_expired_at_to_files_table_20190906092311"
+}
+
+// Up is executed in upgrading
+func (a *AddExpiredAtToFilesTable20190906092311) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	return db.Exec(`
+		ALTER TABLE files
+		  ADD expiredAt TIMESTAMP(6) NULL DEFAULT NULL AFTER pathHash,
+		  ADD KEY expiredAt_idx (expiredAt)
+	`).Error
+}
+
+// Down is executed in downgrading
+func (a *AddExpiredAtToFilesTable20190906092311) Down(db *gorm.DB) error {
+	// execute when rollback database
+	return db.Exec(`
+		ALTER TABLE files
+		  DROP KEY expiredAt_idx,
+		  DROP COLUMN expiredAt
+	`).Error
+}
@@ -0,0 +1,44 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&AddRetentionToFilesTable20190908160745{})
+}
+
+// AddRetentionToFilesTable20190908160745 represent some database operate
+type AddRetentionToFilesTable20190908160745 struct{}
+
+// Name represent operate name, it's unique
+func (a *AddRetentionToFilesTable20190908160745) Name() string {
+	return "add_retention_to_files_table_20190908160745"
+}
+
+// Up is executed in upgrading
+func (a *AddRetentionToFilesTable20190908160745) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	return db.Exec(`
+		ALTER TABLE files
+		  ADD retentionMode TINYINT UNSIGNED NOT NULL DEFAULT 0 AFTER expiredAt,
+		  ADD retainUntil TIMESTAMP(6) NULL DEFAULT NULL AFTER retentionMode,
+		  ADD legalHold TINYINT UNSIGNED NOT NULL DEFAULT 0 AFTER retainUntil
+	`).Error
+}
+
+// Down is executed in downgrading
+func (a *AddRetentionToFilesTable20190908160745) Down(db *gorm.DB) error {
+	// execute when rollback database
+	return db.Exec(`
+		ALTER TABLE files
+		  DROP COLUMN legalHold,
+		  DROP COLUMN retainUntil,
+		  DROP COLUMN retentionMode
+	`).Error
+}
@@ -0,0 +1,64 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&CreateJournalsTable20190910083145{})
+}
+
+// CreateJournalsTable20190910083145 represent some database operate
+type CreateJournalsTable20190910083145 struct{}
+
+// Name represent operate name, it's unique
+func (c *CreateJournalsTable20190910083145) Name() string {
+	return "create_journals_table_20190910083145"
+}
+
+// Up is executed in upgrading
+func (c *CreateJournalsTable20190910083145) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS journal_sequences (
+		  appId BIGINT(20) UNSIGNED NOT NULL,
+		  sequence BIGINT(20) UNSIGNED NOT NULL DEFAULT 0,
+		  PRIMARY KEY (appId))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS journals (
+		  id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+		  appId BIGINT(20) UNSIGNED NOT NULL,
+		  sequence BIGINT(20) UNSIGNED NOT NULL,
+		  fileId BIGINT(20) UNSIGNED NOT NULL,
+		  fileUid CHAR(32) NOT NULL,
+		  event VARCHAR(16) NOT NULL,
+		  isDir TINYINT UNSIGNED NOT NULL DEFAULT 0,
+		  hidden TINYINT UNSIGNED NOT NULL DEFAULT 0,
+		  size INT UNSIGNED NOT NULL DEFAULT 0,
+		  path VARCHAR(1000) NOT NULL,
+		  previousPath VARCHAR(1000) NULL,
+		  createdAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  PRIMARY KEY (id),
+		  UNIQUE INDEX appId_sequence_unique (appId, sequence),
+		  KEY fileId_idx (fileId))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error
+}
+
+// Down is executed in downgrading
+func (c *CreateJournalsTable20190910083145) Down(db *gorm.DB) error {
+	// execute when rollback database
+	if err := db.DropTableIfExists("journals").Error; err != nil {
+		return err
+	}
+	return db.DropTableIfExists("journal_sequences").Error
+}
@@ -0,0 +1,76 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&CreateWebhooksTable20190912093021{})
+}
+
+// CreateWebhooksTable20190912093021 represent some database operate
+type CreateWebhooksTable20190912093021 struct{}
+
+// Name represent operate name, it's unique
+func (c *CreateWebhooksTable20190912093021) Name() string {
+	return "create_webhooks_table_20190912093021"
+}
+
+// Up is executed in upgrading
+func (c *CreateWebhooksTable20190912093021) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS webhooks (
+		  id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+		  uid CHAR(32) NOT NULL,
+		  appId BIGINT(20) UNSIGNED NOT NULL,
+		  url VARCHAR(1000) NOT NULL,
+		  events VARCHAR(100) NOT NULL DEFAULT '',
+		  pathPrefix VARCHAR(1000) NOT NULL DEFAULT '/',
+		  createdAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  updatedAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  deletedAt timestamp(6) NULL DEFAULT NULL,
+		  PRIMARY KEY (id),
+		  UNIQUE INDEX uid_UNIQUE (uid ASC),
+		  KEY appId_idx (appId))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		  id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+		  uid CHAR(32) NOT NULL,
+		  webhookId BIGINT(20) UNSIGNED NOT NULL,
+		  event VARCHAR(16) NOT NULL,
+		  sequence BIGINT(20) UNSIGNED NOT NULL,
+		  payload TEXT NOT NULL,
+		  status VARCHAR(16) NOT NULL,
+		  attempts INT UNSIGNED NOT NULL DEFAULT 0,
+		  nextAttemptAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  responseCode INT NULL DEFAULT NULL,
+		  lastError VARCHAR(1000) NULL DEFAULT NULL,
+		  deliveredAt timestamp(6) NULL DEFAULT NULL,
+		  createdAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  updatedAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  PRIMARY KEY (id),
+		  UNIQUE INDEX uid_UNIQUE (uid ASC),
+		  KEY webhookId_idx (webhookId),
+		  KEY status_nextAttemptAt_idx (status, nextAttemptAt))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error
+}
+
+// Down is executed in downgrading
+func (c *CreateWebhooksTable20190912093021) Down(db *gorm.DB) error {
+	// execute when rollback database
+	if err := db.DropTableIfExists("webhook_deliveries").Error; err != nil {
+		return err
+	}
+	return db.DropTableIfExists("webhooks").Error
+}
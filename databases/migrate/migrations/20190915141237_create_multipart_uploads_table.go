@@ -0,0 +1,64 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&CreateMultipartUploadsTable20190915141237{})
+}
+
+// CreateMultipartUploadsTable20190915141237 represent some database operate
+type CreateMultipartUploadsTable20190915141237 struct{}
+
+// Name represent operate name, it's unique
+func (c *CreateMultipartUploadsTable20190915141237) Name() string {
+	return "create_multipart_uploads_table_20190915141237"
+}
+
+// Up is executed in upgrading
+func (c *CreateMultipartUploadsTable20190915141237) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS multipart_uploads (
+		  id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+		  uid CHAR(32) NOT NULL,
+		  appId BIGINT(20) UNSIGNED NOT NULL,
+		  path VARCHAR(1000) NOT NULL,
+		  owner CHAR(32) NOT NULL,
+		  createdAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  updatedAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  PRIMARY KEY (id),
+		  UNIQUE INDEX uid_UNIQUE (uid ASC),
+		  KEY appId_idx (appId))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS multipart_parts (
+		  id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+		  uploadId BIGINT(20) UNSIGNED NOT NULL,
+		  number INT NOT NULL,
+		  objectId BIGINT(20) UNSIGNED NOT NULL,
+		  createdAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  updatedAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  PRIMARY KEY (id),
+		  UNIQUE INDEX uploadId_number_UNIQUE (uploadId, number))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error
+}
+
+// Down is executed in downgrading
+func (c *CreateMultipartUploadsTable20190915141237) Down(db *gorm.DB) error {
+	// execute when rollback database
+	if err := db.DropTableIfExists("multipart_parts").Error; err != nil {
+		return err
+	}
+	return db.DropTableIfExists("multipart_uploads").Error
+}
@@ -0,0 +1,49 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&CreatePublicKeysTable20190917102533{})
+}
+
+// CreatePublicKeysTable20190917102533 represent some database operate
+type CreatePublicKeysTable20190917102533 struct{}
+
+// Name represent operate name, it's unique
+func (c *CreatePublicKeysTable20190917102533) Name() string {
+	return "create_public_keys_table_20190917102533"
+}
+
+// Up is executed in upgrading
+func (c *CreatePublicKeysTable20190917102533) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS public_keys (
+		  id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+		  uid CHAR(32) NOT NULL,
+		  appId BIGINT(20) UNSIGNED NOT NULL,
+		  tokenId BIGINT(20) UNSIGNED NULL DEFAULT NULL,
+		  fingerprint VARCHAR(64) NOT NULL,
+		  publicKey TEXT NOT NULL,
+		  comment VARCHAR(255) NULL DEFAULT NULL,
+		  createdAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  updatedAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  PRIMARY KEY (id),
+		  UNIQUE INDEX uid_UNIQUE (uid ASC),
+		  KEY appId_tokenId_fingerprint_idx (appId, tokenId, fingerprint))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error
+}
+
+// Down is executed in downgrading
+func (c *CreatePublicKeysTable20190917102533) Down(db *gorm.DB) error {
+	// execute when rollback database
+	return db.DropTableIfExists("public_keys").Error
+}
@@ -0,0 +1,53 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&CreateSharesTable20190920091512{})
+}
+
+// CreateSharesTable20190920091512 represent some database operate
+type CreateSharesTable20190920091512 struct{}
+
+// Name represent operate name, it's unique
+func (c *CreateSharesTable20190920091512) Name() string {
+	return "create_shares_table_20190920091512"
+}
+
+// Up is executed in upgrading
+func (c *CreateSharesTable20190920091512) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS shares (
+		  id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+		  uid CHAR(32) NOT NULL,
+		  appId BIGINT(20) UNSIGNED NOT NULL,
+		  tokenId BIGINT(20) UNSIGNED NOT NULL,
+		  fileId BIGINT(20) UNSIGNED NOT NULL,
+		  password CHAR(60) NULL DEFAULT NULL,
+		  expiredAt timestamp(6) NULL DEFAULT NULL,
+		  maxDownloads BIGINT(20) UNSIGNED NULL DEFAULT NULL,
+		  downloadCount BIGINT(20) UNSIGNED NOT NULL DEFAULT 0,
+		  createdAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  updatedAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  deletedAt timestamp(6) NULL DEFAULT NULL,
+		  PRIMARY KEY (id),
+		  UNIQUE INDEX uid_UNIQUE (uid ASC),
+		  KEY appId_idx (appId),
+		  KEY fileId_idx (fileId))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error
+}
+
+// Down is executed in downgrading
+func (c *CreateSharesTable20190920091512) Down(db *gorm.DB) error {
+	// execute when rollback database
+	return db.DropTableIfExists("shares").Error
+}
@@ -0,0 +1,45 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&CreateNoncesTable20190925103047{})
+}
+
+// CreateNoncesTable20190925103047 represent some database operate
+type CreateNoncesTable20190925103047 struct{}
+
+// Name represent operate name, it's unique
+func (c *CreateNoncesTable20190925103047) Name() string {
+	return "create_nonces_table_20190925103047"
+}
+
+// Up is executed in upgrading
+func (c *CreateNoncesTable20190925103047) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS nonces (
+		  id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+		  appId BIGINT(20) UNSIGNED NOT NULL,
+		  nonce VARCHAR(48) NOT NULL,
+		  expiredAt timestamp(6) NOT NULL,
+		  createdAt timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		  PRIMARY KEY (id),
+		  UNIQUE INDEX appId_nonce_UNIQUE (appId ASC, nonce ASC),
+		  KEY expiredAt_idx (expiredAt))
+		ENGINE = InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci
+	`).Error
+}
+
+// Down is executed in downgrading
+func (c *CreateNoncesTable20190925103047) Down(db *gorm.DB) error {
+	// execute when rollback database
+	return db.DropTableIfExists("nonces").Error
+}
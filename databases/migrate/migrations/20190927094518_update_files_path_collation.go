@@ -0,0 +1,45 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&UpdateFilesPathCollation20190927094518{})
+}
+
+// UpdateFilesPathCollation20190927094518 represent some database operate. The
+// name and fullPath are compared case sensitively, as pathHash is the md5 of
+// the exact path, otherwise the unique index of name rejects the paths that
+// can't be found by pathHash.
+type UpdateFilesPathCollation20190927094518 struct{}
+
+// Name represent operate name, it's unique
+func (u *UpdateFilesPathCollation20190927094518) Name() string {
+	return "update_files_path_collation_20190927094518"
+}
+
+// Up is executed in upgrading
+func (u *UpdateFilesPathCollation20190927094518) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	return db.Exec(`
+		ALTER TABLE files
+		  MODIFY name VARCHAR(255) CHARACTER SET utf8 COLLATE utf8_bin NOT NULL DEFAULT '',
+		  MODIFY fullPath VARCHAR(1000) CHARACTER SET utf8 COLLATE utf8_bin NOT NULL DEFAULT ''
+	`).Error
+}
+
+// Down is executed in downgrading
+func (u *UpdateFilesPathCollation20190927094518) Down(db *gorm.DB) error {
+	// execute when rollback database
+	return db.Exec(`
+		ALTER TABLE files
+		  MODIFY name VARCHAR(255) CHARACTER SET utf8 COLLATE utf8_general_ci NOT NULL DEFAULT '',
+		  MODIFY fullPath VARCHAR(1000) CHARACTER SET utf8 COLLATE utf8_general_ci NOT NULL DEFAULT ''
+	`).Error
+}
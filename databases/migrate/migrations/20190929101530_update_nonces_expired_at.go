@@ -0,0 +1,41 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"github.com/bigfile/bigfile/databases/migrate"
+	"github.com/jinzhu/gorm"
+)
+
+func init() {
+	migrate.DefaultMC.Register(&UpdateNoncesExpiredAt20190929101530{})
+}
+
+// UpdateNoncesExpiredAt20190929101530 represent some database operate. The
+// nonces of the requests without signed timestamp never expire, their expiry
+// is beyond the range of timestamp.
+type UpdateNoncesExpiredAt20190929101530 struct{}
+
+// Name represent operate name, it's unique
+func (u *UpdateNoncesExpiredAt20190929101530) Name() string {
+	return "update_nonces_expired_at_20190929101530"
+}
+
+// Up is executed in upgrading
+func (u *UpdateNoncesExpiredAt20190929101530) Up(db *gorm.DB) error {
+	// execute when upgrade database
+	return db.Exec(`ALTER TABLE nonces MODIFY expiredAt DATETIME(6) NOT NULL`).Error
+}
+
+// Down is executed in downgrading
+func (u *UpdateNoncesExpiredAt20190929101530) Down(db *gorm.DB) error {
+	// execute when rollback database, the expiry is limited to the max of timestamp
+	if err := db.Exec(
+		`UPDATE nonces SET expiredAt = '2038-01-19 03:14:07' WHERE expiredAt > '2038-01-19 03:14:07'`,
+	).Error; err != nil {
+		return err
+	}
+	return db.Exec(`ALTER TABLE nonces MODIFY expiredAt TIMESTAMP(6) NOT NULL`).Error
+}
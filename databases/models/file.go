@@ -5,12 +5,14 @@
 package models
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
-	"fmt"
 	"io"
 	"path"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bigfile/bigfile/internal/util"
 	"github.com/jinzhu/gorm"
@@ -35,11 +37,21 @@ var (
 	ErrAccessDenied = errors.New("file can't be accessed by some tokens")
 	// ErrDeleteNonEmptyDir represent delete non-empty directory
 	ErrDeleteNonEmptyDir = errors.New("delete non-empty directory")
+	// ErrMoveToSubDir represent that try to move a directory into itself
+	ErrMoveToSubDir = errors.New("directory can't be moved into itself")
+	// ErrCopyToSubDir represent that try to copy a directory into itself
+	ErrCopyToSubDir = errors.New("directory can't be copied into itself")
+	// ErrFileExpired represent that the file or one of its ancestors has expired
+	ErrFileExpired = errors.New("file has expired")
+	// ErrRestoreDir represent that try to restore a deleted directory
+	ErrRestoreDir = errors.New("only file can be restored")
 )
 
 // File represent a file or a directory of system. If it's a file
 // it has to associate with an object. Actually, the object hold
-// the real content of file.
+// the real content of file. Every file also stores its complete
+// path and the md5 hash of the path, so that looking up a file by
+// path or scanning a subtree only costs one indexed query.
 type File struct {
 	ID            uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
 	UID           string     `gorm:"type:CHAR(32) NOT NULL;UNIQUE;column:uid"`
@@ -52,6 +64,12 @@ type File struct {
 	IsDir         int8       `gorm:"type:tinyint;column:isDir;DEFAULT:0"`
 	Hidden        int8       `gorm:"type:tinyint;column:hidden;DEFAULT:0"`
 	DownloadCount uint64     `gorm:"type:BIGINT(20);column:downloadCount;DEFAULT:0"`
+	FullPath      string     `gorm:"type:VARCHAR(1000) NOT NULL;DEFAULT:'';column:fullPath"`
+	PathHash      string     `gorm:"type:CHAR(32) NOT NULL;DEFAULT:'';column:pathHash"`
+	ExpiredAt     *time.Time `gorm:"type:TIMESTAMP(6) NULL;INDEX;column:expiredAt"`
+	RetentionMode int8       `gorm:"type:tinyint;column:retentionMode;DEFAULT:0"`
+	RetainUntil   *time.Time `gorm:"type:TIMESTAMP(6) NULL;column:retainUntil"`
+	LegalHold     int8       `gorm:"type:tinyint;column:legalHold;DEFAULT:0"`
 	CreatedAt     time.Time  `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
 	UpdatedAt     time.Time  `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:updatedAt"`
 	DeletedAt     *time.Time `gorm:"type:TIMESTAMP(6);INDEX;column:deletedAt"`
@@ -63,35 +81,88 @@ type File struct {
 	Histories []History `gorm:"foreignkey:fileId;association_autoupdate:false;association_autocreate:false"`
 }
 
-func pathCacheKey(app *App, path string) string {
-	return fmt.Sprintf("%d-%s", app.ID, path)
-}
-
 // TableName represent the name of files table
 func (f *File) TableName() string {
 	return "files"
 }
 
+// normalizePath will transform path to the form that stored in fullPath column,
+// there is no difference between a relative path and an absolute path. The
+// path is cleaned, so the empty and dot segments are removed, and the parent
+// segments never go beyond the root.
+func normalizePath(p string) string {
+	return path.Clean("/" + strings.TrimSpace(p))
+}
+
+// joinPath is used to join the path of parent directory and the name of file
+func joinPath(dir, name string) string {
+	return strings.TrimSuffix(dir, "/") + "/" + name
+}
+
+// hashPath return the md5 hash of path, it's equal to MD5() of mysql. The
+// path is hashed as is, so name and fullPath are compared with utf8_bin too.
+func hashPath(p string) string {
+	h := md5.Sum([]byte(p))
+	return hex.EncodeToString(h[:])
+}
+
+// escapeLike escape the special characters of mysql like pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// BeforeCreate will fill the path columns before file is created
+func (f *File) BeforeCreate(tx *gorm.DB) error {
+	if f.FullPath == "" {
+		if f.PID == 0 {
+			f.FullPath = "/"
+		} else {
+			parent := &File{}
+			if err := tx.Unscoped().Select("fullPath").Where("id = ?", f.PID).First(parent).Error; err != nil {
+				return err
+			}
+			f.FullPath = joinPath(parent.FullPath, f.Name)
+		}
+	}
+	f.PathHash = hashPath(f.FullPath)
+	return nil
+}
+
+// Descendants return a query that includes all files and directories in the
+// subtree of f, f itself is excluded. Trashed files are included only if db
+// is unscoped.
+func (f *File) Descendants(db *gorm.DB) *gorm.DB {
+	return db.Model(&File{}).Where(
+		"appId = ? and fullPath like ?", f.AppID, escapeLike(strings.TrimSuffix(f.FullPath, "/"))+"/%")
+}
+
 func (f *File) executeDelete(forceDelete bool, db *gorm.DB) error {
 	if f.IsDir == 0 {
 		return db.Delete(f).Error
 	}
 
-	var err error
+	var (
+		err        error
+		childCount int
+	)
 
-	if err = db.Preload("Children").Find(f).Error; err != nil {
+	if err = db.Model(&File{}).Where("pid = ?", f.ID).Count(&childCount).Error; err != nil {
 		return err
 	}
 
-	if len(f.Children) == 0 {
+	if childCount == 0 {
 		return db.Delete(f).Error
 	}
 
 	if forceDelete {
-		for _, child := range f.Children {
-			if err = child.executeDelete(forceDelete, db); err != nil {
-				return err
-			}
+		if _, err = f.Path(db); err != nil {
+			return err
+		}
+		if err = f.Descendants(db).Where("isDir = ?", IsDir).UpdateColumn("size", 0).Error; err != nil {
+			return err
+		}
+		if err = f.Descendants(db).UpdateColumn("deletedAt", gorm.NowFunc()).Error; err != nil {
+			return err
 		}
 		db.Model(f).Update("size", 0)
 		return db.Delete(f).Error
@@ -103,6 +174,10 @@ func (f *File) executeDelete(forceDelete bool, db *gorm.DB) error {
 // 'forceDelete' determine to delete or not sub directories and files
 func (f *File) Delete(forceDelete bool, db *gorm.DB) (err error) {
 
+	if err = f.CheckProtection(db); err != nil {
+		return err
+	}
+
 	if f.Parent == nil {
 		if err = db.Preload("Parent").Find(f).Error; err != nil {
 			return err
@@ -120,7 +195,134 @@ func (f *File) Delete(forceDelete bool, db *gorm.DB) (err error) {
 		}
 	}
 
-	return db.Unscoped().Find(f).Error
+	if err = db.Unscoped().Find(f).Error; err != nil {
+		return err
+	}
+
+	return recordJournal(f, JournalDelete, nil, db)
+}
+
+// Restore is used to restore a deleted file to its original path, the missing
+// ancestor directories are created again. The path mustn't be occupied by
+// another file or directory.
+func (f *File) Restore(db *gorm.DB) (err error) {
+	var (
+		p      string
+		parent *File
+	)
+
+	if f.DeletedAt == nil {
+		return nil
+	}
+
+	if f.IsDir == IsDir {
+		return ErrRestoreDir
+	}
+
+	if f.App.ID == 0 {
+		if err = db.Unscoped().Preload("App").Find(f).Error; err != nil {
+			return err
+		}
+	}
+
+	if p, err = f.Path(db); err != nil {
+		return err
+	}
+
+	if _, err = FindFileByPath(&f.App, p, db); err == nil {
+		return ErrFileExisted
+	}
+
+	if parent, err = CreateOrGetLastDirectory(&f.App, path.Dir(p), db); err != nil {
+		return err
+	}
+
+	if err = db.Unscoped().Model(f).Updates(map[string]interface{}{
+		"pid":       parent.ID,
+		"deletedAt": nil,
+	}).Error; err != nil {
+		return err
+	}
+	f.PID = parent.ID
+	f.Parent = parent
+	f.DeletedAt = nil
+
+	if err = parent.UpdateParentSize(f.Size, db); err != nil {
+		return err
+	}
+
+	return recordJournal(f, JournalRestore, nil, db)
+}
+
+// IsExpired represent whether the file has expired. A file also expires with
+// any of its ancestor directories.
+func (f *File) IsExpired(db *gorm.DB) (bool, error) {
+	var (
+		err    error
+		p      string
+		count  int
+		now    = gorm.NowFunc()
+		hashes []string
+	)
+	if f.ExpiredAt != nil && !f.ExpiredAt.After(now) {
+		return true, nil
+	}
+	if p, err = f.Path(db); err != nil {
+		return false, err
+	}
+	paths := pathAndAncestors(p)
+	for _, ancestor := range paths[:len(paths)-1] {
+		hashes = append(hashes, hashPath(ancestor))
+	}
+	if len(hashes) == 0 {
+		return false, nil
+	}
+	err = db.Model(&File{}).
+		Where("appId = ? and pathHash in (?) and expiredAt <= ?", f.AppID, hashes, now).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SetExpiredAt is used to change the expiry of file, nil represent that the
+// file never expires. The file under retention or legal hold can't be given
+// an expiry, see CheckProtection, because it's deleted once it expires.
+func (f *File) SetExpiredAt(expiredAt *time.Time, db *gorm.DB) error {
+	if expiredAt != nil {
+		if err := f.CheckProtection(db); err != nil {
+			return err
+		}
+	}
+	f.ExpiredAt = expiredAt
+	return db.Model(f).Update("expiredAt", expiredAt).Error
+}
+
+// SetHidden is used to change the hidden attribute of file
+func (f *File) SetHidden(hidden int8, db *gorm.DB) error {
+	if f.Hidden == hidden {
+		return nil
+	}
+	f.Hidden = hidden
+	if err := db.Model(f).Update("hidden", hidden).Error; err != nil {
+		return err
+	}
+	return recordJournal(f, JournalHide, nil, db)
+}
+
+// NotExpired is a scope that excludes the files that have expired
+func NotExpired(db *gorm.DB) *gorm.DB {
+	return db.Where("expiredAt IS NULL OR expiredAt > ?", gorm.NowFunc())
+}
+
+// FindExpiredFiles is used to find the files that have expired, but haven't
+// been deleted. Only the files whose id is greater than afterID are returned,
+// the earliest created first, the files that are under retention or legal
+// hold are excluded.
+func FindExpiredFiles(afterID uint64, limit int, db *gorm.DB) (files []File, err error) {
+	var now = gorm.NowFunc()
+	err = db.Where("id > ? and expiredAt <= ?", afterID, now).
+		Where("legalHold = 0 and (retentionMode = 0 or retainUntil IS NULL or retainUntil <= ?)", now).
+		Order("id").Limit(limit).Find(&files).Error
+	return files, err
 }
 
 // CanBeAccessedByToken represent whether the file can be accessed by the token
@@ -150,52 +352,103 @@ func (f *File) Reader(rootPath *string, db *gorm.DB) (io.ReadSeeker, error) {
 	return (&f.Object).Reader(rootPath, db)
 }
 
-// Path is used to get the complete path of file
+// Path is used to get the complete path of file. The path is always read
+// from database by primary key, because it may be changed by moving some
+// ancestor directory.
 func (f *File) Path(db *gorm.DB) (string, error) {
+	if f.ID == 0 {
+		return f.FullPath, nil
+	}
+	var file = &File{}
+	if err := db.Unscoped().Select("fullPath, pathHash").Where("id = ?", f.ID).First(file).Error; err != nil {
+		return "", err
+	}
+	f.FullPath = file.FullPath
+	f.PathHash = file.PathHash
+	return f.FullPath, nil
+}
 
-	if f.PID == 0 && f.IsDir == IsDir {
-		return "/", nil
+// pathAndAncestors return all the paths from the root directory to p, p itself
+// is the last one.
+func pathAndAncestors(p string) []string {
+	var paths = []string{"/"}
+	for _, part := range strings.Split(strings.Trim(normalizePath(p), "/"), "/") {
+		if part == "" {
+			continue
+		}
+		paths = append(paths, joinPath(paths[len(paths)-1], part))
 	}
+	return paths
+}
 
+// addSizeDeltas add size to the directory dirPath and all its ancestors in deltas
+func addSizeDeltas(deltas map[string]int, dirPath string, size int) {
+	for _, p := range pathAndAncestors(dirPath) {
+		deltas[p] += size
+	}
+}
+
+// applySizeDeltas is used to apply the size deltas of directories, deltas is
+// keyed by the path of directory. All directories are changed by one statement
+// and the rows are locked in ascending order of primary key, so concurrent
+// transactions always acquire the locks in the same order and can't deadlock.
+func applySizeDeltas(appID uint64, deltas map[string]int, db *gorm.DB) error {
 	var (
-		parts   []string
-		current = *f
+		err    error
+		dirs   []File
+		hashes []string
+		ids    []uint64
+		args   []interface{}
+		cases  strings.Builder
 	)
-	for {
-		parts = append(parts, current.Name)
-		if current.PID == 0 {
-			break
+
+	for p, size := range deltas {
+		if size != 0 {
+			hashes = append(hashes, hashPath(p))
 		}
-		temp := &File{}
-		db.Where("id = ?", current.PID).Find(temp)
-		current = *temp
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	if err = db.Select("id, fullPath").
+		Where("appId = ? and isDir = ? and pathHash in (?)", appID, IsDir, hashes).
+		Order("id").Find(&dirs).Error; err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		return nil
 	}
 
-	util.ReverseSlice(parts)
+	for _, dir := range dirs {
+		cases.WriteString(" WHEN ? THEN ?")
+		args = append(args, dir.ID, deltas[dir.FullPath])
+		ids = append(ids, dir.ID)
+	}
+	args = append(args, ids)
 
-	return strings.Join(parts, "/"), nil
+	return db.Exec(
+		"UPDATE files SET size = size + CASE id"+cases.String()+" ELSE 0 END WHERE id IN (?) ORDER BY id",
+		args...,
+	).Error
 }
 
-// UpdateParentSize is used to update parent directory size. note, size may be a negative number.
-func (f *File) UpdateParentSize(size int, db *gorm.DB) error {
-	var dirIds []uint64
-	current := f
-	for {
-		current.Size += size
-		dirIds = append(dirIds, current.ID)
-		if current.PID == 0 {
-			break
-		}
-		if current.Parent == nil {
-			current.Parent = &File{}
-		}
-		if err := db.Model(current).Association("Parent").Find(current.Parent).Error; err != nil {
-			return err
-		}
-		current = current.Parent
+// UpdateParentSize is used to update the size of directory and all its ancestors.
+// note, size may be a negative number.
+func (f *File) UpdateParentSize(size int, db *gorm.DB) (err error) {
+	var (
+		p      string
+		deltas = make(map[string]int)
+	)
+	if p, err = f.Path(db); err != nil {
+		return err
 	}
-
-	return db.Model(&File{}).Where("id in (?)", dirIds).UpdateColumn("size", gorm.Expr("size + ?", size)).Error
+	addSizeDeltas(deltas, p, size)
+	if err = applySizeDeltas(f.AppID, deltas, db); err != nil {
+		return err
+	}
+	f.Size += size
+	return nil
 }
 
 func (f *File) createHistory(objectID uint64, path string, db *gorm.DB) error {
@@ -209,9 +462,33 @@ func (f *File) OverWriteFromReader(reader io.Reader, hidden int8, rootPath *stri
 		return ErrOverwriteDir
 	}
 
+	if err = f.CheckProtection(db); err != nil {
+		return err
+	}
+
+	var object *Object
+
+	if object, err = CreateObjectFromReader(reader, rootPath, db); err != nil {
+		return err
+	}
+
+	return f.OverWriteFromObject(object, hidden, db)
+}
+
+// OverWriteFromObject is used to overwrite the object by an existing object,
+// the object can be stored before the transaction of overwriting
+func (f *File) OverWriteFromObject(object *Object, hidden int8, db *gorm.DB) (err error) {
+
+	if f.IsDir == IsDir {
+		return ErrOverwriteDir
+	}
+
+	if err = f.CheckProtection(db); err != nil {
+		return err
+	}
+
 	var (
 		p        string
-		object   *Object
 		sizeDiff int
 	)
 
@@ -223,10 +500,6 @@ func (f *File) OverWriteFromReader(reader io.Reader, hidden int8, rootPath *stri
 		return err
 	}
 
-	if object, err = CreateObjectFromReader(reader, rootPath, db); err != nil {
-		return err
-	}
-
 	f.Object = *object
 	f.ObjectID = object.ID
 	f.Hidden = hidden
@@ -241,7 +514,11 @@ func (f *File) OverWriteFromReader(reader io.Reader, hidden int8, rootPath *stri
 		return err
 	}
 	db.Preload("Parent").Preload("App").Find(f)
-	return f.Parent.UpdateParentSize(sizeDiff, db)
+	if err = f.Parent.UpdateParentSize(sizeDiff, db); err != nil {
+		return err
+	}
+
+	return recordJournal(f, JournalOverwrite, nil, db)
 }
 
 func (f *File) mustPath(db *gorm.DB) string {
@@ -249,9 +526,48 @@ func (f *File) mustPath(db *gorm.DB) string {
 	return p
 }
 
+// moveDescendants is used to replace the path prefix of all descendants after
+// the directory is moved, the whole subtree is updated by one statement.
+func (f *File) moveDescendants(previousPath string, db *gorm.DB) error {
+	var from = utf8.RuneCountInString(previousPath) + 1
+	return db.Exec(
+		"UPDATE files SET pathHash = MD5(CONCAT(?, SUBSTRING(fullPath, ?))), "+
+			"fullPath = CONCAT(?, SUBSTRING(fullPath, ?)), updatedAt = updatedAt "+
+			"WHERE appId = ? AND fullPath LIKE ?",
+		f.FullPath, from, f.FullPath, from, f.AppID, escapeLike(previousPath)+"/%",
+	).Error
+}
+
 // MoveTo move file to another path, the input path must be complete and new path.
-// if the input path ios the same as the previous path, nothing changes.
+// if the input path ios the same as the previous path, nothing changes. If db
+// isn't in a transaction, the file is moved in its own transaction, which locks
+// the journal sequence of app first.
 func (f *File) MoveTo(newPath string, db *gorm.DB) (err error) {
+	if !util.InTransaction(db) {
+		db = db.Begin()
+		defer func() {
+			if reErr := recover(); reErr != nil {
+				db.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				db.Rollback()
+				return
+			}
+			if err = db.Commit().Error; err == nil {
+				NotifyJournal(f.AppID)
+			}
+		}()
+		if err = LockJournalSequence(f.AppID, db); err != nil {
+			return err
+		}
+	}
+	return f.moveTo(newPath, db)
+}
+
+// moveTo is used to move file in the transaction of db
+func (f *File) moveTo(newPath string, db *gorm.DB) (err error) {
+	newPath = normalizePath(newPath)
 	var (
 		newPathDir      = path.Dir(newPath)
 		newPathDirFile  *File
@@ -268,6 +584,14 @@ func (f *File) MoveTo(newPath string, db *gorm.DB) (err error) {
 		return nil
 	}
 
+	if err = f.CheckProtection(db); err != nil {
+		return err
+	}
+
+	if f.IsDir == IsDir && strings.HasPrefix(newPath, previousPath+"/") {
+		return ErrMoveToSubDir
+	}
+
 	if f.App.ID == 0 {
 		if err = db.Preload("App").Find(f).Error; err != nil {
 			return err
@@ -290,37 +614,146 @@ func (f *File) MoveTo(newPath string, db *gorm.DB) (err error) {
 
 	f.Name = newPathFileName
 	f.Ext = newPathExt
+	f.FullPath = newPath
+	f.PathHash = hashPath(newPath)
 
-	defer func() {
-		pathToFileCache.Delete(previousPath)
-		_ = pathToFileCache.Add(pathCacheKey(&f.App, f.mustPath(db)), f, time.Minute*10)
-	}()
+	if f.IsDir == IsDir {
+		if err = f.moveDescendants(previousPath, db); err != nil {
+			return err
+		}
+	}
 
 	// only change the file name, still is in the same directory
 	if newPathDirFile.ID == f.PID {
-		return db.Model(f).Updates(map[string]interface{}{"name": f.Name, "ext": f.Ext}).Error
-	}
-
-	if f.Parent == nil || f.Parent.ID == 0 {
-		f.Parent = &File{}
-		if err = db.Model(f).Association("Parent").Find(f.Parent).Error; err != nil {
+		if err = db.Model(f).Updates(map[string]interface{}{
+			"name": f.Name, "ext": f.Ext, "fullPath": f.FullPath, "pathHash": f.PathHash,
+		}).Error; err != nil {
 			return err
 		}
+		return recordJournal(f, JournalMove, &previousPath, db)
 	}
 
-	if err = newPathDirFile.UpdateParentSize(f.Size, db); err != nil {
+	// the size is moved from previous ancestors to new ancestors in one
+	// statement, the common ancestors are not changed at all.
+	var deltas = make(map[string]int)
+	addSizeDeltas(deltas, path.Dir(previousPath), -f.Size)
+	addSizeDeltas(deltas, newPathDirFile.FullPath, f.Size)
+	if err = applySizeDeltas(f.AppID, deltas, db); err != nil {
 		return err
 	}
+	newPathDirFile.Size += f.Size
+	f.PID = newPathDirFile.ID
+	f.Parent = newPathDirFile
 
-	if err = f.Parent.UpdateParentSize(-f.Size, db); err != nil {
+	if err = db.Model(f).Updates(map[string]interface{}{
+		"pid": f.PID, "name": f.Name, "ext": f.Ext, "fullPath": f.FullPath, "pathHash": f.PathHash,
+	}).Error; err != nil {
 		return err
 	}
-	f.PID = newPathDirFile.ID
-	f.Parent = newPathDirFile
-	f.Name = newPathFileName
-	f.Ext = newPathExt
 
-	return db.Model(f).Updates(map[string]interface{}{"pid": f.PID, "name": f.Name, "ext": f.Ext}).Error
+	return recordJournal(f, JournalMove, &previousPath, db)
+}
+
+// CopyTo copy file to another path, the input path must be complete and new path.
+// The copy shares the object with the origin file, so no content is duplicated.
+// If the file is a directory, all the files and directories in it are copied.
+func (f *File) CopyTo(newPath string, db *gorm.DB) (copied *File, err error) {
+	newPath = normalizePath(newPath)
+	var (
+		previousPath   string
+		newPathDirFile *File
+		descendants    []File
+		dirs           = make(map[uint64]*File)
+		deltas         = make(map[string]int)
+	)
+
+	if previousPath, err = f.Path(db); err != nil {
+		return nil, err
+	}
+
+	if f.IsDir == IsDir && (newPath == previousPath || strings.HasPrefix(newPath, previousPath+"/")) {
+		return nil, ErrCopyToSubDir
+	}
+
+	if f.App.ID == 0 {
+		if err = db.Preload("App").Find(f).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := FindFileByPathWithTrashed(&f.App, newPath, db); err == nil {
+		return nil, ErrFileExisted
+	}
+
+	if newPathDirFile, err = CreateOrGetLastDirectory(&f.App, path.Dir(newPath), db); err != nil {
+		return nil, err
+	}
+
+	copied = &File{
+		UID:       UID(),
+		PID:       newPathDirFile.ID,
+		AppID:     f.AppID,
+		ObjectID:  f.ObjectID,
+		Size:      f.Size,
+		Name:      path.Base(newPath),
+		Ext:       strings.TrimPrefix(path.Ext(newPath), "."),
+		IsDir:     f.IsDir,
+		Hidden:    f.Hidden,
+		FullPath:  newPath,
+		ExpiredAt: f.ExpiredAt,
+		App:       f.App,
+		Parent:    newPathDirFile,
+	}
+	if err = db.Create(copied).Error; err != nil {
+		return nil, err
+	}
+	if err = recordJournal(copied, JournalCreate, nil, db); err != nil {
+		return nil, err
+	}
+
+	if f.IsDir == IsDir {
+		// parent directory is always in front of its children
+		if err = f.Descendants(db).Order("fullPath").Find(&descendants).Error; err != nil {
+			return nil, err
+		}
+		dirs[f.ID] = copied
+		for _, descendant := range descendants {
+			parent, ok := dirs[descendant.PID]
+			if !ok {
+				continue
+			}
+			child := &File{
+				UID:       UID(),
+				PID:       parent.ID,
+				AppID:     descendant.AppID,
+				ObjectID:  descendant.ObjectID,
+				Size:      descendant.Size,
+				Name:      descendant.Name,
+				Ext:       descendant.Ext,
+				IsDir:     descendant.IsDir,
+				Hidden:    descendant.Hidden,
+				FullPath:  joinPath(parent.FullPath, descendant.Name),
+				ExpiredAt: descendant.ExpiredAt,
+			}
+			if err = db.Create(child).Error; err != nil {
+				return nil, err
+			}
+			if err = recordJournal(child, JournalCreate, nil, db); err != nil {
+				return nil, err
+			}
+			if child.IsDir == IsDir {
+				dirs[descendant.ID] = child
+			}
+		}
+	}
+
+	addSizeDeltas(deltas, newPathDirFile.FullPath, f.Size)
+	if err = applySizeDeltas(f.AppID, deltas, db); err != nil {
+		return nil, err
+	}
+	newPathDirFile.Size += f.Size
+
+	return copied, nil
 }
 
 // AppendFromReader is used to append content from reader to file
@@ -330,6 +763,10 @@ func (f *File) AppendFromReader(reader io.Reader, hidden int8, rootPath *string,
 		return ErrAppendToDir
 	}
 
+	if err = f.CheckProtection(db); err != nil {
+		return err
+	}
+
 	var (
 		size   int
 		object *Object
@@ -352,36 +789,59 @@ func (f *File) AppendFromReader(reader io.Reader, hidden int8, rootPath *string,
 		return err
 	}
 
-	return f.Parent.UpdateParentSize(size, db)
+	if err = f.Parent.UpdateParentSize(size, db); err != nil {
+		return err
+	}
+
+	return recordJournal(f, JournalAppend, nil, db)
 }
 
 // CreateOrGetLastDirectory is used to get last level directory, there is no difference
-// between a relative path and an absolute path
+// between a relative path and an absolute path. All existing directories on the path
+// are loaded by one query, only the missing ones will be created.
 func CreateOrGetLastDirectory(app *App, dirPath string, db *gorm.DB) (*File, error) {
 	var (
-		parent = &File{ID: 0}
-		err    error
-		parts  = strings.Split(strings.TrimRight(strings.TrimSpace(dirPath), "/"), "/")
+		err      error
+		dirs     []File
+		parent   *File
+		existed  = make(map[string]*File)
+		prefixes = pathAndAncestors(dirPath)
+		hashes   = make([]string, 0, len(prefixes))
 	)
 
-	if parts[0] != "" {
-		if parent, err = CreateOrGetRootPath(app, db); err != nil {
-			return nil, err
-		}
+	for _, prefix := range prefixes {
+		hashes = append(hashes, hashPath(prefix))
 	}
 
-	for _, part := range parts {
-		file := &File{}
-		if err = db.Where("appId = ? and pid = ? and name = ?", app.ID, parent.ID, part).First(file).Error; err != nil {
-			if !util.IsRecordNotFound(err) {
-				return nil, err
+	if err = db.Where("appId = ? and pathHash in (?)", app.ID, hashes).Find(&dirs).Error; err != nil {
+		return nil, err
+	}
+	for index := range dirs {
+		existed[dirs[index].FullPath] = &dirs[index]
+	}
+
+	for _, prefix := range prefixes {
+		file, ok := existed[prefix]
+		if !ok {
+			if parent == nil {
+				return nil, gorm.ErrRecordNotFound
+			}
+			file = &File{
+				UID:      UID(),
+				PID:      parent.ID,
+				AppID:    app.ID,
+				Name:     path.Base(prefix),
+				IsDir:    IsDir,
+				FullPath: prefix,
 			}
-			file.AppID = app.ID
-			file.PID = parent.ID
-			file.Name = part
-			file.IsDir = 1
-			file.UID = UID()
 			if err = db.Save(file).Error; err != nil {
+				// the directory may be created by another transaction concurrently
+				file = &File{}
+				if db.Where("appId = ? and pathHash = ? and fullPath = ?",
+					app.ID, hashPath(prefix), prefix).First(file).Error != nil {
+					return nil, err
+				}
+			} else if err = recordJournal(file, JournalCreate, nil, db); err != nil {
 				return nil, err
 			}
 		}
@@ -404,8 +864,23 @@ func CreateOrGetRootPath(app *App, db *gorm.DB) (*File, error) {
 
 // CreateFileFromReader is used to create a file from reader.
 func CreateFileFromReader(app *App, savePath string, reader io.Reader, hidden int8, rootPath *string, db *gorm.DB) (file *File, err error) {
+	var object *Object
+
+	if f, err := FindFileByPathWithTrashed(app, savePath, db); err == nil && f.ID > 0 {
+		return nil, ErrFileExisted
+	}
+
+	if object, err = CreateObjectFromReader(reader, rootPath, db); err != nil {
+		return nil, err
+	}
+
+	return CreateFileFromObject(app, savePath, object, hidden, db)
+}
+
+// CreateFileFromObject is used to create a file by an existing object, the
+// object can be stored before the transaction of creating
+func CreateFileFromObject(app *App, savePath string, object *Object, hidden int8, db *gorm.DB) (file *File, err error) {
 	var (
-		object    *Object
 		parentDir *File
 		dirPrefix = path.Dir(savePath)
 		fileName  = path.Base(savePath)
@@ -419,10 +894,6 @@ func CreateFileFromReader(app *App, savePath string, reader io.Reader, hidden in
 		return nil, err
 	}
 
-	if object, err = CreateObjectFromReader(reader, rootPath, db); err != nil {
-		return nil, err
-	}
-
 	file = &File{
 		UID:      UID(),
 		PID:      parentDir.ID,
@@ -432,6 +903,7 @@ func CreateFileFromReader(app *App, savePath string, reader io.Reader, hidden in
 		Name:     fileName,
 		Ext:      strings.TrimPrefix(path.Ext(fileName), "."),
 		Hidden:   hidden,
+		FullPath: joinPath(parentDir.FullPath, fileName),
 		Object:   *object,
 		App:      *app,
 		Parent:   parentDir,
@@ -441,7 +913,11 @@ func CreateFileFromReader(app *App, savePath string, reader io.Reader, hidden in
 		return nil, err
 	}
 
-	return file, parentDir.UpdateParentSize(object.Size, db)
+	if err = parentDir.UpdateParentSize(object.Size, db); err != nil {
+		return nil, err
+	}
+
+	return file, recordJournal(file, JournalCreate, nil, db)
 }
 
 // FindFileByUID is used to find a file by uid
@@ -461,46 +937,70 @@ func FindFileByUID(uid string, trashed bool, db *gorm.DB) (*File, error) {
 
 // FindFileByPathWithTrashed is used to find a file by the specify path, include deleted path
 func FindFileByPathWithTrashed(app *App, path string, db *gorm.DB) (*File, error) {
-	return FindFileByPath(app, path, db.Unscoped(), true)
+	return FindFileByPath(app, path, db.Unscoped())
 }
 
 // FindFileByPath is used to find a file by the specify path
-func FindFileByPath(app *App, path string, db *gorm.DB, useCache bool) (*File, error) {
-	var cacheKey = pathCacheKey(app, path)
-
-	if useCache {
-		if fileValue, ok := pathToFileCache.Get(cacheKey); ok {
-			file := fileValue.(*File)
-			if err := db.Where("id = ?", file.ID).Find(file).Error; err == nil {
-				file.App = *app
-				return file, nil
-			}
-		}
-	}
-
+func FindFileByPath(app *App, path string, db *gorm.DB) (*File, error) {
 	var (
-		err    error
-		parent = &File{}
-		parts  = strings.Split(strings.Trim(strings.TrimSpace(path), "/"), "/")
+		file     = &File{}
+		fullPath = normalizePath(path)
+		err      error
 	)
-
-	if parts[0] != "" {
-		if parent, err = CreateOrGetRootPath(app, db); err != nil {
-			return nil, err
-		}
+	if err = db.Where(
+		"appId = ? and pathHash = ? and fullPath = ?", app.ID, hashPath(fullPath), fullPath,
+	).First(file).Error; err != nil {
+		return nil, err
 	}
+	file.App = *app
+	return file, nil
+}
 
-	for _, part := range parts {
-		var file = &File{}
-		// deleted files should be considered
-		if err = db.Where("appId = ? and pid = ? and name = ?", app.ID, parent.ID, part).First(file).Error; err != nil {
-			return nil, err
-		}
-		parent = file
-	}
-	parent.App = *app
+// FindFilesByPathPrefix is used to find the files whose path starts with prefix
+// and is greater than after, both are compared byte by byte. Directories, hidden
+// and expired files are excluded. The files are sorted by path, and their objects
+// are preloaded.
+func FindFilesByPathPrefix(app *App, prefix, after string, limit int, db *gorm.DB) (files []File, err error) {
+	query := db.Preload("Object").Scopes(NotExpired).
+		Where("appId = ? and isDir = 0 and hidden = 0", app.ID).
+		Where("fullPath like binary ?", escapeLike(prefix)+"%")
+	if after != "" {
+		query = query.Where("binary fullPath > ?", after)
+	}
+	err = query.Order("binary fullPath").Limit(limit).Find(&files).Error
+	return files, err
+}
 
-	_ = pathToFileCache.Add(cacheKey, parent, time.Minute*10)
+// FindTrashedFiles is used to find the deleted files in the directory dirPath,
+// including the files in its subdirectories, directories are excluded. The files
+// are sorted by the deleted time in descending order.
+func FindTrashedFiles(app *App, dirPath string, limit int, db *gorm.DB) (files []File, err error) {
+	err = db.Unscoped().Preload("Object").
+		Where("appId = ? and isDir = 0 and deletedAt is not null", app.ID).
+		Where("fullPath like binary ?", escapeLike(strings.TrimSuffix(normalizePath(dirPath), "/"))+"/%").
+		Order("deletedAt DESC").Order("id DESC").Limit(limit).Find(&files).Error
+	return files, err
+}
 
-	return parent, nil
+// FindHistories is used to find the previous versions of file, the latest is
+// the first. Their objects are preloaded.
+func (f *File) FindHistories(db *gorm.DB) (histories []History, err error) {
+	err = db.Preload("Object").Where("fileId = ?", f.ID).Order("id DESC").Find(&histories).Error
+	return histories, err
+}
+
+// CountHistories is used to count the previous versions of file
+func (f *File) CountHistories(db *gorm.DB) (count int, err error) {
+	err = db.Model(&History{}).Where("fileId = ?", f.ID).Count(&count).Error
+	return count, err
+}
+
+// IncreaseDownloadCount is used to count a download of file
+func (f *File) IncreaseDownloadCount(db *gorm.DB) error {
+	if err := db.Model(&File{}).Where("id = ?", f.ID).
+		UpdateColumn("downloadCount", gorm.Expr("downloadCount + 1")).Error; err != nil {
+		return err
+	}
+	f.DownloadCount++
+	return nil
 }
@@ -0,0 +1,201 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// ErrFileLocked represent that the file is locked by others
+	ErrFileLocked = errors.New("file is locked by others")
+	// ErrFileLockExpired represent that the lease of lock has already expired
+	ErrFileLockExpired = errors.New("file lock has already expired")
+	// ErrFileLockNotOwned represent that the lock is held by others
+	ErrFileLockNotOwned = errors.New("file lock is held by others")
+)
+
+// FileLock represent a lock on file or directory. A lock is a lease, it
+// will be invalid automatically after expiredAt, so it must be refreshed
+// by its owner before that. An exclusive lock forbids other owners to
+// modify the file, if the file is a directory, the whole subtree is locked.
+// A shared lock is advisory, it only conflicts with exclusive locks.
+//
+// Owner identifies who holds the lock, it's the uid of token for http and
+// rpc requests, and the uid of app for the sessions that log in with app,
+// such as ftp.
+type FileLock struct {
+	ID        uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
+	UID       string    `gorm:"type:CHAR(32) NOT NULL;UNIQUE;column:uid"`
+	AppID     uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:appId"`
+	FileID    uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:fileId"`
+	Owner     string    `gorm:"type:VARCHAR(64) NOT NULL;column:owner"`
+	Exclusive int8      `gorm:"type:tinyint;column:exclusive;DEFAULT:0"`
+	ExpiredAt time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;column:expiredAt"`
+	CreatedAt time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+	UpdatedAt time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:updatedAt"`
+}
+
+// TableName represent the name of file lock table
+func (l *FileLock) TableName() string {
+	return "file_locks"
+}
+
+// IsExpired represent whether the lease of lock has expired
+func (l *FileLock) IsExpired() bool {
+	return !l.ExpiredAt.After(gorm.NowFunc())
+}
+
+// Refresh is used to extend the lease of lock, the lock must be held by owner
+func (l *FileLock) Refresh(owner string, ttl time.Duration, db *gorm.DB) error {
+	if l.Owner != owner {
+		return ErrFileLockNotOwned
+	}
+	if l.IsExpired() {
+		return ErrFileLockExpired
+	}
+	l.ExpiredAt = gorm.NowFunc().Add(ttl)
+	return db.Model(l).Update("expiredAt", l.ExpiredAt).Error
+}
+
+// Release is used to release the lock, the lock must be held by owner
+func (l *FileLock) Release(owner string, db *gorm.DB) error {
+	if l.Owner != owner {
+		return ErrFileLockNotOwned
+	}
+	return db.Delete(l).Error
+}
+
+// relatedLocks is used to find the active locks that are held by others on path,
+// the ancestors of path and the descendants of path. The locks are read FOR
+// UPDATE, so that they can't be released or refreshed until the transaction
+// ends.
+func relatedLocks(appID uint64, p, owner string, db *gorm.DB) ([]FileLock, error) {
+	var (
+		locks  []FileLock
+		paths  = pathAndAncestors(p)
+		hashes = make([]string, 0, len(paths))
+	)
+	for _, ancestor := range paths {
+		hashes = append(hashes, hashPath(ancestor))
+	}
+	err := db.Set("gorm:query_option", "FOR UPDATE").Table("file_locks").Select("file_locks.*").
+		Joins("JOIN files ON files.id = file_locks.fileId and files.deletedAt is null").
+		Where("file_locks.appId = ? and file_locks.owner != ? and file_locks.expiredAt > ?",
+			appID, owner, gorm.NowFunc()).
+		Where("files.pathHash in (?) or files.fullPath like ?",
+			hashes, escapeLike(strings.TrimSuffix(paths[len(paths)-1], "/"))+"/%").
+		Find(&locks).Error
+	return locks, err
+}
+
+// CheckPathLock is used to check whether the path can be modified by owner. The
+// path is not required to exist, so it also can be used before creating a file.
+// ErrFileLocked will be returned when the path, some ancestor or some descendant
+// is locked exclusively by others. It should be called after the journal sequence
+// of app is locked in the same transaction, see LockJournalSequence, so that no
+// lock can be acquired on path until the transaction ends.
+func CheckPathLock(app *App, p, owner string, db *gorm.DB) error {
+	locks, err := relatedLocks(app.ID, p, owner, db)
+	if err != nil {
+		return err
+	}
+	for _, lock := range locks {
+		if lock.Exclusive == 1 {
+			return ErrFileLocked
+		}
+	}
+	return nil
+}
+
+// CheckLock is used to check whether the file can be modified by owner
+func (f *File) CheckLock(owner string, db *gorm.DB) error {
+	p, err := f.Path(db)
+	if err != nil {
+		return err
+	}
+	return CheckPathLock(&App{ID: f.AppID}, p, owner, db)
+}
+
+// Locks is used to get the active locks on this file
+func (f *File) Locks(db *gorm.DB) ([]FileLock, error) {
+	var locks []FileLock
+	err := db.Where("fileId = ? and expiredAt > ?", f.ID, gorm.NowFunc()).Order("id").Find(&locks).Error
+	return locks, err
+}
+
+// AcquireFileLock is used to lock a file or a directory for owner. If the owner
+// has already held a lock on the file, the lock will be changed and returned.
+// The journal sequence of app should have been locked in the same transaction,
+// so that the lock isn't acquired while the path is being modified.
+func AcquireFileLock(file *File, owner string, exclusive bool, ttl time.Duration, db *gorm.DB) (*FileLock, error) {
+	var (
+		err    error
+		p      string
+		locks  []FileLock
+		dirs   []File
+		hashes []string
+		lock   = &FileLock{}
+	)
+
+	if p, err = file.Path(db); err != nil {
+		return nil, err
+	}
+
+	// lock the file and all its ancestors in ascending order of primary key,
+	// so that acquiring locks in the same tree is serialized.
+	for _, ancestor := range pathAndAncestors(p) {
+		hashes = append(hashes, hashPath(ancestor))
+	}
+	if err = db.Set("gorm:query_option", "FOR UPDATE").Select("id").
+		Where("appId = ? and pathHash in (?)", file.AppID, hashes).
+		Order("id").Find(&dirs).Error; err != nil {
+		return nil, err
+	}
+
+	if err = db.Where("appId = ? and expiredAt <= ?", file.AppID, gorm.NowFunc()).Delete(&FileLock{}).Error; err != nil {
+		return nil, err
+	}
+
+	if locks, err = relatedLocks(file.AppID, p, owner, db); err != nil {
+		return nil, err
+	}
+	for _, related := range locks {
+		if exclusive || related.Exclusive == 1 {
+			return nil, ErrFileLocked
+		}
+	}
+
+	if err = db.Where("fileId = ? and owner = ?", file.ID, owner).First(lock).Error; err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return nil, err
+		}
+		lock = &FileLock{UID: UID(), AppID: file.AppID, FileID: file.ID, Owner: owner}
+	}
+
+	lock.Exclusive = 0
+	if exclusive {
+		lock.Exclusive = 1
+	}
+	lock.ExpiredAt = gorm.NowFunc().Add(ttl)
+
+	return lock, db.Save(lock).Error
+}
+
+// FindFileLockByUID is used to find a lock by uid
+func FindFileLockByUID(uid string, db *gorm.DB) (*FileLock, error) {
+	var (
+		lock = &FileLock{}
+		err  error
+	)
+	if err = db.Where("uid = ?", uid).First(lock).Error; err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
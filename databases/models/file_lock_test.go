@@ -0,0 +1,94 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLock_TableName(t *testing.T) {
+	assert.Equal(t, "file_locks", (&FileLock{}).TableName())
+}
+
+func TestFileLock_IsExpired(t *testing.T) {
+	assert.True(t, (&FileLock{ExpiredAt: time.Now().Add(-time.Second)}).IsExpired())
+	assert.False(t, (&FileLock{ExpiredAt: time.Now().Add(time.Minute)}).IsExpired())
+}
+
+func TestAcquireFileLock(t *testing.T) {
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	dir, err := CreateOrGetLastDirectory(app, "/save/to/a", trx)
+	assert.Nil(t, err)
+	subDir, err := CreateOrGetLastDirectory(app, "/save/to/a/b", trx)
+	assert.Nil(t, err)
+
+	lock, err := AcquireFileLock(dir, "alice", true, time.Minute, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, int8(1), lock.Exclusive)
+	assert.Equal(t, dir.ID, lock.FileID)
+
+	// acquire again, the lock is reused
+	sameLock, err := AcquireFileLock(dir, "alice", false, time.Minute, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, lock.UID, sameLock.UID)
+	assert.Equal(t, int8(0), sameLock.Exclusive)
+
+	// shared locks can coexist
+	_, err = AcquireFileLock(subDir, "bob", false, time.Minute, trx)
+	assert.Nil(t, err)
+	_, err = AcquireFileLock(subDir, "bob", true, time.Minute, trx)
+	assert.Equal(t, ErrFileLocked, err)
+
+	locks, err := dir.Locks(trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(locks))
+
+	foundLock, err := FindFileLockByUID(lock.UID, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, lock.ID, foundLock.ID)
+	_, err = FindFileLockByUID("not exist", trx)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestFile_CheckLock(t *testing.T) {
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	dir, err := CreateOrGetLastDirectory(app, "/save/to/a", trx)
+	assert.Nil(t, err)
+	subDir, err := CreateOrGetLastDirectory(app, "/save/to/a/b", trx)
+	assert.Nil(t, err)
+	root, err := CreateOrGetRootPath(app, trx)
+	assert.Nil(t, err)
+
+	lock, err := AcquireFileLock(dir, "alice", true, time.Minute, trx)
+	assert.Nil(t, err)
+
+	assert.Nil(t, subDir.CheckLock("alice", trx))
+	assert.Equal(t, ErrFileLocked, subDir.CheckLock("bob", trx))
+	assert.Equal(t, ErrFileLocked, root.CheckLock("bob", trx))
+	assert.Equal(t, ErrFileLocked, CheckPathLock(app, "/save/to/a/c/1.bytes", "bob", trx))
+	assert.Nil(t, CheckPathLock(app, "/save/to/b", "bob", trx))
+
+	assert.Equal(t, ErrFileLockNotOwned, lock.Refresh("bob", time.Minute, trx))
+	assert.Nil(t, lock.Refresh("alice", time.Hour, trx))
+	assert.Equal(t, ErrFileLockNotOwned, lock.Release("bob", trx))
+	assert.Nil(t, lock.Release("alice", trx))
+	assert.Nil(t, subDir.CheckLock("bob", trx))
+
+	lock, err = AcquireFileLock(dir, "alice", true, -time.Minute, trx)
+	assert.Nil(t, err)
+	assert.True(t, lock.IsExpired())
+	assert.Nil(t, subDir.CheckLock("bob", trx))
+	assert.Equal(t, ErrFileLockExpired, lock.Refresh("alice", time.Minute, trx))
+}
@@ -0,0 +1,119 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// RetentionNone represent that the file isn't under retention
+	RetentionNone = int8(0)
+	// RetentionGovernance represent that the retention can only be shortened
+	// or removed by the app
+	RetentionGovernance = int8(1)
+	// RetentionCompliance represent that the retention can't be shortened or
+	// removed by anyone, until it has passed
+	RetentionCompliance = int8(2)
+)
+
+var (
+	// ErrFileUnderRetention represent that the file, one of its ancestors or
+	// descendants is under retention, so it can't be changed
+	ErrFileUnderRetention = errors.New("file is under retention")
+	// ErrFileUnderLegalHold represent that the file, one of its ancestors or
+	// descendants is under legal hold, so it can't be changed
+	ErrFileUnderLegalHold = errors.New("file is under legal hold")
+	// ErrRetentionLocked represent that try to shorten or remove the retention
+	ErrRetentionLocked = errors.New("retention can't be shortened or removed")
+)
+
+// IsRetained represent whether the retention of file is still in effect
+func (f *File) IsRetained() bool {
+	return f.RetentionMode != RetentionNone && f.RetainUntil != nil && f.RetainUntil.After(gorm.NowFunc())
+}
+
+// CheckProtection is used to check whether the file can be overwritten,
+// appended, moved or deleted. The file is protected if itself or one of its
+// ancestors is under retention or legal hold. If the file is a directory, it's
+// also protected by its descendants, because they are moved or deleted with it.
+func (f *File) CheckProtection(db *gorm.DB) error {
+	var (
+		err       error
+		p         string
+		hashes    []string
+		protected []File
+	)
+
+	if p, err = f.Path(db); err != nil {
+		return err
+	}
+	for _, ancestor := range pathAndAncestors(p) {
+		hashes = append(hashes, hashPath(ancestor))
+	}
+
+	query := db.Model(&File{}).Select("legalHold, retentionMode, retainUntil").Where("appId = ?", f.AppID)
+	if f.IsDir == IsDir {
+		query = query.Where(
+			"pathHash in (?) or fullPath like ?", hashes, escapeLike(strings.TrimSuffix(p, "/"))+"/%")
+	} else {
+		query = query.Where("pathHash in (?)", hashes)
+	}
+	if err = query.Where("legalHold = 1 or (retentionMode != 0 and retainUntil > ?)", gorm.NowFunc()).
+		Order("legalHold DESC").Limit(1).Find(&protected).Error; err != nil {
+		return err
+	}
+
+	if len(protected) == 0 {
+		return nil
+	}
+	if protected[0].LegalHold == 1 {
+		return ErrFileUnderLegalHold
+	}
+	return ErrFileUnderRetention
+}
+
+// SetRetention is used to change the retention of file. The retention in effect
+// can be extended, but can't be shortened or removed, unless it's in governance
+// mode and bypassGovernance is true. Compliance mode can't be weakened at all.
+func (f *File) SetRetention(mode int8, retainUntil *time.Time, bypassGovernance bool, db *gorm.DB) (err error) {
+	var current = &File{}
+
+	if err = db.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", f.ID).First(current).Error; err != nil {
+		return err
+	}
+
+	if current.IsRetained() {
+		weakened := mode == RetentionNone || retainUntil == nil || retainUntil.Before(*current.RetainUntil) ||
+			(current.RetentionMode == RetentionCompliance && mode != RetentionCompliance)
+		if weakened && (current.RetentionMode == RetentionCompliance || !bypassGovernance) {
+			return ErrRetentionLocked
+		}
+	}
+
+	if mode == RetentionNone {
+		retainUntil = nil
+	}
+	f.RetentionMode = mode
+	f.RetainUntil = retainUntil
+
+	return db.Model(f).Updates(map[string]interface{}{
+		"retentionMode": mode,
+		"retainUntil":   retainUntil,
+	}).Error
+}
+
+// SetLegalHold is used to place or release the legal hold of file
+func (f *File) SetLegalHold(hold bool, db *gorm.DB) error {
+	f.LegalHold = 0
+	if hold {
+		f.LegalHold = 1
+	}
+	return db.Model(f).Update("legalHold", f.LegalHold).Error
+}
@@ -0,0 +1,82 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile_CheckProtection(t *testing.T) {
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	tempDir := NewTempDirForTest()
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := CreateFileFromReader(app, "/save/to/1.bytes", bytes.NewReader(Random(255)), int8(0), &tempDir, trx)
+	assert.Nil(t, err)
+	dir, err := FindFileByPath(app, "/save/to", trx)
+	assert.Nil(t, err)
+	root, err := CreateOrGetRootPath(app, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, file.CheckProtection(trx))
+
+	retainUntil := time.Now().Add(time.Hour)
+	assert.Nil(t, dir.SetRetention(RetentionGovernance, &retainUntil, false, trx))
+	assert.Equal(t, ErrFileUnderRetention, file.CheckProtection(trx))
+	assert.Equal(t, ErrFileUnderRetention, root.CheckProtection(trx))
+
+	assert.Equal(t, ErrFileUnderRetention, file.OverWriteFromReader(bytes.NewReader(Random(1)), 0, &tempDir, trx))
+	assert.Equal(t, ErrFileUnderRetention, file.AppendFromReader(bytes.NewReader(Random(1)), 0, &tempDir, trx))
+	assert.Equal(t, ErrFileUnderRetention, file.MoveTo("/save/2.bytes", trx))
+	assert.Equal(t, ErrFileUnderRetention, file.Delete(false, trx))
+	assert.Equal(t, ErrFileUnderRetention, file.SetExpiredAt(&retainUntil, trx))
+	assert.Nil(t, file.SetExpiredAt(nil, trx))
+
+	assert.Nil(t, dir.SetRetention(RetentionNone, nil, true, trx))
+	assert.Nil(t, file.CheckProtection(trx))
+
+	assert.Nil(t, file.SetLegalHold(true, trx))
+	assert.Equal(t, ErrFileUnderLegalHold, file.CheckProtection(trx))
+	assert.Equal(t, ErrFileUnderLegalHold, dir.Delete(true, trx))
+	assert.Nil(t, file.SetLegalHold(false, trx))
+	assert.Nil(t, file.MoveTo("/save/2.bytes", trx))
+}
+
+func TestFile_SetRetention(t *testing.T) {
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	dir, err := CreateOrGetLastDirectory(app, "/save/to", trx)
+	assert.Nil(t, err)
+	var (
+		soon  = time.Now().Add(time.Hour)
+		later = time.Now().Add(2 * time.Hour)
+	)
+
+	assert.Nil(t, dir.SetRetention(RetentionGovernance, &soon, false, trx))
+	assert.True(t, dir.IsRetained())
+	assert.Nil(t, dir.SetRetention(RetentionGovernance, &later, false, trx))
+	assert.Equal(t, ErrRetentionLocked, dir.SetRetention(RetentionGovernance, &soon, false, trx))
+	assert.Equal(t, ErrRetentionLocked, dir.SetRetention(RetentionNone, nil, false, trx))
+	assert.Nil(t, dir.SetRetention(RetentionGovernance, &soon, true, trx))
+
+	assert.Nil(t, dir.SetRetention(RetentionCompliance, &soon, false, trx))
+	assert.Equal(t, ErrRetentionLocked, dir.SetRetention(RetentionGovernance, &later, true, trx))
+	assert.Equal(t, ErrRetentionLocked, dir.SetRetention(RetentionNone, nil, true, trx))
+	assert.Nil(t, dir.SetRetention(RetentionCompliance, &later, false, trx))
+	assert.Equal(t, RetentionCompliance, dir.RetentionMode)
+}
@@ -6,13 +6,19 @@ package models
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/bigfile/bigfile/databases"
 	"github.com/bigfile/bigfile/internal/util"
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
@@ -22,6 +28,15 @@ func TestFile_TableName(t *testing.T) {
 	assert.Equal(t, (&File{}).TableName(), "files")
 }
 
+func TestNormalizePath(t *testing.T) {
+	assert.Equal(t, "/", normalizePath(""))
+	assert.Equal(t, "/", normalizePath(" / "))
+	assert.Equal(t, "/a/b", normalizePath("a/b/"))
+	assert.Equal(t, "/a/b", normalizePath("//a//./b"))
+	assert.Equal(t, "/b", normalizePath("/a/../b"))
+	assert.Equal(t, "/b", normalizePath("/../../b"))
+}
+
 func TestCreateOrGetRootPath(t *testing.T) {
 	app, trx, down, err := newAppForTest(nil, t)
 	assert.Nil(t, err)
@@ -64,6 +79,18 @@ func TestCreateOrGetLastDirectory(t *testing.T) {
 	rootDir3, _ := CreateOrGetRootPath(app, trx)
 	assert.Equal(t, rootDir1.ID, rootDir2.ID)
 	assert.Equal(t, rootDir1.ID, rootDir3.ID)
+
+	// the paths are case sensitive
+	lower, err := CreateOrGetLastDirectory(app, "/docs", trx)
+	assert.Nil(t, err)
+	upper, err := CreateOrGetLastDirectory(app, "/Docs/x", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, "/Docs/x", upper.FullPath)
+	assert.NotEqual(t, lower.ID, upper.PID)
+	found, err := FindFileByPath(app, "/Docs", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, upper.PID, found.ID)
+	assert.Equal(t, "Docs", found.Name)
 }
 
 func TestFile_UpdateParentSize(t *testing.T) {
@@ -142,10 +169,10 @@ func TestFindFileByPath(t *testing.T) {
 	imagesDir, err := FindFileByPathWithTrashed(app, "/save/to/images", trx)
 	assert.Nil(t, err)
 	assert.Nil(t, trx.Delete(imagesDir).Error)
-	_, err = FindFileByPath(app, "/save/to/images", trx, false)
+	_, err = FindFileByPath(app, "/save/to/images", trx)
 	assert.True(t, gorm.IsRecordNotFoundError(err))
 
-	rootDirByFindPAth, err := FindFileByPath(app, "/", trx, false)
+	rootDirByFindPAth, err := FindFileByPath(app, "/", trx)
 	assert.Nil(t, err)
 	rootDir, err := CreateOrGetRootPath(app, trx)
 	assert.Nil(t, err)
@@ -227,6 +254,46 @@ func TestCreateFileFromReader2(t *testing.T) {
 	assert.Equal(t, 556, root.Size)
 }
 
+func TestCreateFileFromObject(t *testing.T) {
+	var (
+		app     *App
+		trx     *gorm.DB
+		err     error
+		file    *File
+		object  *Object
+		down    func(*testing.T)
+		tempDir = NewTempDirForTest()
+	)
+
+	app, trx, down, err = newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			_ = os.RemoveAll(tempDir)
+		}
+	}()
+
+	object, err = CreateObjectFromReader(bytes.NewReader(Random(uint(556))), &tempDir, trx)
+	assert.Nil(t, err)
+	file, err = CreateFileFromObject(app, "/test/save/to/random.txt", object, int8(0), trx)
+	assert.Nil(t, err)
+	assert.Equal(t, object.ID, file.ObjectID)
+	assert.Equal(t, 556, file.Size)
+	assert.Equal(t, 556, file.Parent.Size)
+	_, err = CreateFileFromObject(app, "/test/save/to/random.txt", object, int8(0), trx)
+	assert.Equal(t, ErrFileExisted, err)
+
+	object, err = CreateObjectFromReader(bytes.NewReader(Random(uint(100))), &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, file.OverWriteFromObject(object, int8(0), trx))
+	assert.Equal(t, object.ID, file.ObjectID)
+	assert.Equal(t, 100, file.Size)
+	root, err := CreateOrGetRootPath(app, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, root.Size)
+}
+
 func TestFile_AppendFromReader(t *testing.T) {
 	var (
 		h           = sha256.New()
@@ -595,6 +662,100 @@ func TestFile_MoveTo3(t *testing.T) {
 	assert.Equal(t, file1.MoveTo(file2.mustPath(trx), trx), ErrFileExisted)
 }
 
+// TestFile_MoveTo4 is used to test that the paths of the whole subtree are changed
+func TestFile_MoveTo4(t *testing.T) {
+	var (
+		err               error
+		app               *App
+		trx               *gorm.DB
+		down              func(*testing.T)
+		tempDir           = NewTempDirForTest()
+		randomBytes       = Random(255)
+		randomBytesReader = bytes.NewReader(randomBytes)
+	)
+	app, trx, down, err = newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := CreateFileFromReader(app, "/save/to/a/b/1.bytes", randomBytesReader, int8(0), &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, "/save/to/a/b/1.bytes", file.FullPath)
+
+	dir, err := FindFileByPath(app, "/save/to", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrMoveToSubDir, dir.MoveTo("/save/to/a/c", trx))
+
+	assert.Nil(t, dir.MoveTo("/save/as", trx))
+	assert.Equal(t, "/save/as", dir.FullPath)
+
+	movedFile, err := FindFileByPath(app, "/save/as/a/b/1.bytes", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, file.ID, movedFile.ID)
+	assert.Equal(t, hashPath("/save/as/a/b/1.bytes"), movedFile.PathHash)
+
+	_, err = FindFileByPath(app, "/save/to/a/b/1.bytes", trx)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	var count int
+	assert.Nil(t, dir.Descendants(trx).Count(&count).Error)
+	assert.Equal(t, 3, count)
+}
+
+func TestFile_CopyTo(t *testing.T) {
+	var (
+		err     error
+		app     *App
+		trx     *gorm.DB
+		down    func(*testing.T)
+		tempDir = NewTempDirForTest()
+	)
+	app, trx, down, err = newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := CreateFileFromReader(app, "/save/to/a/1.bytes", bytes.NewReader(Random(255)), int8(0), &tempDir, trx)
+	assert.Nil(t, err)
+	_, err = CreateFileFromReader(app, "/save/to/a/b/2.bytes", bytes.NewReader(Random(100)), int8(1), &tempDir, trx)
+	assert.Nil(t, err)
+
+	copiedFile, err := file.CopyTo("/save/as/1.bytes", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, file.ObjectID, copiedFile.ObjectID)
+	assert.NotEqual(t, file.UID, copiedFile.UID)
+	_, err = file.CopyTo("/save/as/1.bytes", trx)
+	assert.Equal(t, ErrFileExisted, err)
+
+	dir, err := FindFileByPath(app, "/save/to/a", trx)
+	assert.Nil(t, err)
+	_, err = dir.CopyTo("/save/to/a/c", trx)
+	assert.Equal(t, ErrCopyToSubDir, err)
+
+	copiedDir, err := dir.CopyTo("/save/copy", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 355, copiedDir.Size)
+	copiedChild, err := FindFileByPath(app, "/save/copy/b/2.bytes", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, int8(1), copiedChild.Hidden)
+
+	root, err := CreateOrGetRootPath(app, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 255+355+355, root.Size)
+
+	saveDir, err := FindFileByPath(app, "/save", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 255+355+355, saveDir.Size)
+}
+
 func TestFile_Delete(t *testing.T) {
 	var (
 		err               error
@@ -635,3 +796,280 @@ func TestFile_Delete(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, aDir.DeletedAt)
 }
+
+// TestFile_ConcurrentUpdateSize runs many goroutines that upload, move and delete
+// files in the same tree concurrently, finally the size of every directory must be
+// equal to the total size of the files under it.
+func TestFile_ConcurrentUpdateSize(t *testing.T) {
+	var (
+		db      = databases.MustNewConnection(nil)
+		tempDir = NewTempDirForTest()
+		note    = "test"
+		workers = 30
+		wg      sync.WaitGroup
+	)
+	app, err := NewApp("test", &note, db)
+	assert.Nil(t, err)
+	defer func() {
+		db.Unscoped().Where("appId = ?", app.ID).Delete(&File{})
+		assert.Nil(t, DeleteAppPermanently(app, db))
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	runInTrx := func(fn func(trx *gorm.DB) error) error {
+		trx := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+		if err := fn(trx); err != nil {
+			trx.Rollback()
+			return err
+		}
+		return trx.Commit().Error
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var file *File
+			assert.Nil(t, runInTrx(func(trx *gorm.DB) (err error) {
+				savePath := fmt.Sprintf("/stress/%d/a/%d.bytes", i%3, i)
+				file, err = CreateFileFromReader(app, savePath, bytes.NewReader(Random(uint(100+i))), int8(0), &tempDir, trx)
+				return err
+			}))
+			if file == nil {
+				return
+			}
+			assert.Nil(t, runInTrx(func(trx *gorm.DB) error {
+				return file.MoveTo(fmt.Sprintf("/stress/%d/b/%d.bytes", (i+1)%3, i), trx)
+			}))
+			if i%2 == 0 {
+				assert.Nil(t, runInTrx(func(trx *gorm.DB) error {
+					file.Parent = nil
+					return file.Delete(false, trx)
+				}))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var dirs []File
+	assert.Nil(t, db.Where("appId = ? and isDir = ?", app.ID, IsDir).Find(&dirs).Error)
+	assert.True(t, len(dirs) > 0)
+	for _, dir := range dirs {
+		var total struct{ Size int }
+		assert.Nil(t, dir.Descendants(db).Select("COALESCE(SUM(size), 0) AS size").
+			Where("isDir = 0").Scan(&total).Error)
+		assert.Equal(t, total.Size, dir.Size, dir.FullPath)
+	}
+}
+
+func TestFile_IsExpired(t *testing.T) {
+	var (
+		err     error
+		app     *App
+		trx     *gorm.DB
+		down    func(*testing.T)
+		past    = time.Now().Add(-time.Second)
+		future  = time.Now().Add(time.Hour)
+		tempDir = NewTempDirForTest()
+	)
+	app, trx, down, err = newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := CreateFileFromReader(app, "/save/to/1.bytes", bytes.NewReader(Random(255)), int8(0), &tempDir, trx)
+	assert.Nil(t, err)
+	expired, err := file.IsExpired(trx)
+	assert.Nil(t, err)
+	assert.False(t, expired)
+
+	assert.Nil(t, file.SetExpiredAt(&future, trx))
+	expired, err = file.IsExpired(trx)
+	assert.Nil(t, err)
+	assert.False(t, expired)
+
+	dir, err := FindFileByPath(app, "/save", trx)
+	assert.Nil(t, err)
+	assert.Nil(t, dir.SetExpiredAt(&past, trx))
+	expired, err = file.IsExpired(trx)
+	assert.Nil(t, err)
+	assert.True(t, expired)
+
+	files, err := FindExpiredFiles(0, 10, trx.Where("appId = ?", app.ID))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, dir.ID, files[0].ID)
+	files, err = FindExpiredFiles(dir.ID, 10, trx.Where("appId = ?", app.ID))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(files))
+
+	var count int
+	assert.Nil(t, trx.Model(&File{}).Scopes(NotExpired).Where("pid = ?", dir.PID).Count(&count).Error)
+	assert.Equal(t, 0, count)
+}
+
+func TestFile_Restore(t *testing.T) {
+	var (
+		err     error
+		app     *App
+		trx     *gorm.DB
+		down    func(*testing.T)
+		tempDir = NewTempDirForTest()
+	)
+	app, trx, down, err = newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	_, err = CreateFileFromReader(app, "/save/to/1.bytes", bytes.NewReader(Random(255)), int8(0), &tempDir, trx)
+	assert.Nil(t, err)
+	dir, err := FindFileByPath(app, "/save/to", trx)
+	assert.Nil(t, err)
+	assert.Nil(t, dir.Delete(true, trx))
+	dir, err = FindFileByPathWithTrashed(app, "/save/to", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrRestoreDir, dir.Restore(trx))
+
+	file, err := FindFileByPathWithTrashed(app, "/save/to/1.bytes", trx)
+	assert.Nil(t, err)
+	assert.NotNil(t, file.DeletedAt)
+	assert.Nil(t, file.Restore(trx))
+	assert.Nil(t, file.Restore(trx))
+
+	file, err = FindFileByPath(app, "/save/to/1.bytes", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, "/save/to/1.bytes", file.mustPath(trx))
+	dir, err = FindFileByPath(app, "/save/to", trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 255, dir.Size)
+}
+
+func TestFindFilesByPathPrefix(t *testing.T) {
+	var (
+		err     error
+		app     *App
+		trx     *gorm.DB
+		down    func(*testing.T)
+		tempDir = NewTempDirForTest()
+	)
+	app, trx, down, err = newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	for _, p := range []string{"/a/1.bytes", "/a/b/2.bytes", "/a_b/3.bytes", "/A/4.bytes"} {
+		_, err = CreateFileFromReader(app, p, bytes.NewReader(Random(10)), int8(0), &tempDir, trx)
+		assert.Nil(t, err)
+	}
+
+	files, err := FindFilesByPathPrefix(app, "/a/", "", 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(files))
+	assert.Equal(t, "/a/1.bytes", files[0].FullPath)
+	assert.Equal(t, "/a/b/2.bytes", files[1].FullPath)
+	assert.NotEmpty(t, files[0].Object.Hash)
+
+	files, err = FindFilesByPathPrefix(app, "/a", "/a/1.bytes", 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(files))
+	assert.Equal(t, "/a/b/2.bytes", files[0].FullPath)
+	assert.Equal(t, "/a_b/3.bytes", files[1].FullPath)
+
+	files, err = FindFilesByPathPrefix(app, "/", "", 1, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, "/A/4.bytes", files[0].FullPath)
+}
+
+func TestFindTrashedFiles(t *testing.T) {
+	var (
+		err     error
+		app     *App
+		trx     *gorm.DB
+		down    func(*testing.T)
+		tempDir = NewTempDirForTest()
+	)
+	app, trx, down, err = newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	for _, p := range []string{"/a/1.bytes", "/a/b/2.bytes", "/a_b/3.bytes", "/c/4.bytes"} {
+		_, err = CreateFileFromReader(app, p, bytes.NewReader(Random(10)), int8(0), &tempDir, trx)
+		assert.Nil(t, err)
+	}
+	for _, p := range []string{"/a", "/a_b/3.bytes"} {
+		file, err := FindFileByPath(app, p, trx)
+		assert.Nil(t, err)
+		assert.Nil(t, file.Delete(true, trx))
+	}
+
+	files, err := FindTrashedFiles(app, "/a", 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(files))
+	for _, file := range files {
+		assert.True(t, strings.HasPrefix(file.FullPath, "/a/"))
+		assert.NotNil(t, file.DeletedAt)
+	}
+	files, err = FindTrashedFiles(app, "/", 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(files))
+	files, err = FindTrashedFiles(app, "/c", 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(files))
+}
+
+func TestFile_FindHistories(t *testing.T) {
+	var (
+		err     error
+		app     *App
+		trx     *gorm.DB
+		down    func(*testing.T)
+		tempDir = NewTempDirForTest()
+	)
+	app, trx, down, err = newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := CreateFileFromReader(app, "/1.bytes", bytes.NewReader(Random(10)), int8(0), &tempDir, trx)
+	assert.Nil(t, err)
+	firstObjectID := file.ObjectID
+	assert.Nil(t, file.OverWriteFromReader(bytes.NewReader(Random(20)), 0, &tempDir, trx))
+	secondObjectID := file.ObjectID
+	assert.Nil(t, file.OverWriteFromReader(bytes.NewReader(Random(30)), 0, &tempDir, trx))
+
+	histories, err := file.FindHistories(trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(histories))
+	assert.Equal(t, secondObjectID, histories[0].ObjectID)
+	assert.Equal(t, 20, histories[0].Object.Size)
+	assert.Equal(t, firstObjectID, histories[1].ObjectID)
+	assert.Equal(t, 10, histories[1].Object.Size)
+
+	count, err := file.CountHistories(trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
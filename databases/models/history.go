@@ -14,6 +14,8 @@ type History struct {
 	FileID    uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:fileId"`
 	Path      string    `gorm:"type:tinyint;column:path"`
 	CreatedAt time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+
+	Object Object `gorm:"foreignkey:objectId;association_autoupdate:false;association_autocreate:false"`
 }
 
 // TableName represent the name of history table
@@ -0,0 +1,223 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// JournalCreate represent that a file or a directory is created
+	JournalCreate = "create"
+	// JournalOverwrite represent that the content of file is replaced
+	JournalOverwrite = "overwrite"
+	// JournalAppend represent that some content is appended to file
+	JournalAppend = "append"
+	// JournalMove represent that a file or a directory is moved or renamed,
+	// the descendants of directory are moved with it implicitly
+	JournalMove = "move"
+	// JournalHide represent that the hidden attribute of file is changed
+	JournalHide = "hide"
+	// JournalDelete represent that a file or a directory is deleted, the
+	// descendants of directory are deleted with it implicitly
+	JournalDelete = "delete"
+	// JournalRestore represent that a deleted file is restored
+	JournalRestore = "restore"
+)
+
+// Journal represent an event of file tree, it's append-only. Sequence is
+// increasing monotonically in every app, it's used as the cursor of change
+// feed. Path is the path of file after the event, PreviousPath is only set
+// by move event.
+type Journal struct {
+	ID           uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
+	AppID        uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:appId"`
+	Sequence     uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:sequence"`
+	FileID       uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:fileId"`
+	FileUID      string    `gorm:"type:CHAR(32) NOT NULL;column:fileUid"`
+	Event        string    `gorm:"type:VARCHAR(16) NOT NULL;column:event"`
+	IsDir        int8      `gorm:"type:tinyint;column:isDir;DEFAULT:0"`
+	Hidden       int8      `gorm:"type:tinyint;column:hidden;DEFAULT:0"`
+	Size         int       `gorm:"type:int;column:size"`
+	Path         string    `gorm:"type:VARCHAR(1000) NOT NULL;column:path"`
+	PreviousPath *string   `gorm:"type:VARCHAR(1000) NULL;column:previousPath"`
+	CreatedAt    time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+}
+
+// TableName represent the name of journal table
+func (j *Journal) TableName() string {
+	return "journals"
+}
+
+// LockJournalSequence is used to lock the sequence of app until the transaction
+// is committed. The transactions that change the file tree must call it before
+// anything else is locked, so that the sequence is always locked before the
+// files and directories, and the concurrent transactions can't deadlock.
+func LockJournalSequence(appID uint64, db *gorm.DB) error {
+	return db.Exec(
+		"INSERT INTO journal_sequences (appId, sequence) VALUES (?, 0) "+
+			"ON DUPLICATE KEY UPDATE sequence = sequence", appID,
+	).Error
+}
+
+// nextJournalSequence is used to generate the next sequence of app. The row of
+// sequence is locked until the transaction is committed, so the journals of an
+// app are always committed in the order of sequence, and a reader never skips
+// an event that is committed later with a smaller sequence. The transaction
+// should have locked it by LockJournalSequence already.
+func nextJournalSequence(appID uint64, db *gorm.DB) (sequence uint64, err error) {
+	if err = db.Exec(
+		"INSERT INTO journal_sequences (appId, sequence) VALUES (?, LAST_INSERT_ID(1)) "+
+			"ON DUPLICATE KEY UPDATE sequence = LAST_INSERT_ID(sequence + 1)", appID,
+	).Error; err != nil {
+		return 0, err
+	}
+	err = db.Raw("SELECT LAST_INSERT_ID()").Row().Scan(&sequence)
+	return sequence, err
+}
+
+// journalNotifier is used to wake the watchers of app in process, the channel
+// of app is closed and replaced when new journals are committed
+type journalNotifier struct {
+	sync.Mutex
+	channels map[uint64]chan struct{}
+}
+
+var defaultJournalNotifier = &journalNotifier{channels: make(map[uint64]chan struct{})}
+
+// JournalNotification return a channel that is closed when new journals of app
+// are committed in this process, see NotifyJournal. The journals committed by
+// other instances aren't notified, they should still be polled.
+func JournalNotification(appID uint64) <-chan struct{} {
+	defaultJournalNotifier.Lock()
+	defer defaultJournalNotifier.Unlock()
+	channel, ok := defaultJournalNotifier.channels[appID]
+	if !ok {
+		channel = make(chan struct{})
+		defaultJournalNotifier.channels[appID] = channel
+	}
+	return channel
+}
+
+// NotifyJournal is used to wake the watchers of app, it must be called after
+// the transaction that records journals is committed, so that the woken
+// watchers are able to read them.
+func NotifyJournal(appID uint64) {
+	defaultJournalNotifier.Lock()
+	defer defaultJournalNotifier.Unlock()
+	if channel, ok := defaultJournalNotifier.channels[appID]; ok {
+		close(channel)
+		delete(defaultJournalNotifier.channels, appID)
+	}
+}
+
+// recordJournal is used to append an event of file to journal, it's called by
+// all the operations that change the file tree. The event is also pushed to
+// the subscribed webhooks. If db isn't in a transaction, the watchers are
+// notified after the journal is committed, otherwise the caller notifies them
+// after its transaction is committed.
+func recordJournal(f *File, event string, previousPath *string, db *gorm.DB) (err error) {
+	var journal = &Journal{
+		AppID:        f.AppID,
+		FileID:       f.ID,
+		FileUID:      f.UID,
+		Event:        event,
+		IsDir:        f.IsDir,
+		Hidden:       f.Hidden,
+		Size:         f.Size,
+		PreviousPath: previousPath,
+	}
+
+	if journal.Path, err = f.Path(db.Unscoped()); err != nil {
+		return err
+	}
+
+	// LAST_INSERT_ID() is bound to connection, both statements must be
+	// executed in the same transaction
+	if !util.InTransaction(db) {
+		db = db.Begin()
+		defer func() {
+			if err != nil {
+				db.Rollback()
+				return
+			}
+			if err = db.Commit().Error; err == nil {
+				NotifyJournal(f.AppID)
+			}
+		}()
+	}
+
+	if journal.Sequence, err = nextJournalSequence(f.AppID, db); err != nil {
+		return err
+	}
+
+	if err = db.Create(journal).Error; err != nil {
+		return err
+	}
+
+	return enqueueWebhookDeliveries(journal, db)
+}
+
+// FindJournals is used to find the journals whose sequence is in (after, until]
+// in ascending order. Only the events that happened in dirPath are included,
+// a move event is included if either path is in dirPath. The path out of
+// dirPath isn't exposed, so a move out of dirPath is returned as a delete
+// event of the previous path, and a move into dirPath is returned as a create
+// event of the new path.
+func FindJournals(app *App, after, until uint64, dirPath string, limit int, db *gorm.DB) ([]Journal, error) {
+	var (
+		err      error
+		journals []Journal
+		prefix   = normalizePath(dirPath)
+		query    = db.Where("appId = ? and sequence > ? and sequence <= ?", app.ID, after, until)
+		inPrefix = func(p string) bool {
+			return prefix == "/" || p == prefix || strings.HasPrefix(p, prefix+"/")
+		}
+	)
+
+	if prefix != "/" {
+		like := escapeLike(prefix) + "/%"
+		query = query.Where(
+			"path = ? or path like ? or previousPath = ? or previousPath like ?", prefix, like, prefix, like)
+	}
+
+	if err = query.Order("sequence").Limit(limit).Find(&journals).Error; err != nil {
+		return nil, err
+	}
+
+	for index := range journals {
+		journal := &journals[index]
+		if journal.Event != JournalMove || journal.PreviousPath == nil {
+			continue
+		}
+		switch {
+		case !inPrefix(journal.Path):
+			journal.Event = JournalDelete
+			journal.Path = *journal.PreviousPath
+			journal.PreviousPath = nil
+		case !inPrefix(*journal.PreviousPath):
+			journal.Event = JournalCreate
+			journal.PreviousPath = nil
+		}
+	}
+
+	return journals, nil
+}
+
+// LatestJournalSequence return the latest sequence of app, zero is returned if
+// nothing has happened in app
+func LatestJournalSequence(app *App, db *gorm.DB) (uint64, error) {
+	var journal Journal
+	err := db.Select("sequence").Where("appId = ?", app.ID).Order("sequence desc").First(&journal).Error
+	if util.IsRecordNotFound(err) {
+		return 0, nil
+	}
+	return journal.Sequence, err
+}
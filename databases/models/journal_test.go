@@ -0,0 +1,113 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal_TableName(t *testing.T) {
+	assert.Equal(t, "journals", (&Journal{}).TableName())
+}
+
+func TestRecordJournal(t *testing.T) {
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	tempDir := NewTempDirForTest()
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := CreateFileFromReader(app, "/save/to/1.bytes", bytes.NewReader(Random(255)), int8(0), &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, file.AppendFromReader(bytes.NewReader(Random(1)), int8(0), &tempDir, trx))
+	assert.Nil(t, file.OverWriteFromReader(bytes.NewReader(Random(2)), int8(0), &tempDir, trx))
+	assert.Nil(t, file.SetHidden(Hidden, trx))
+	assert.Nil(t, file.MoveTo("/moved/1.bytes", trx))
+	assert.Nil(t, file.Delete(false, trx))
+
+	latest, err := LatestJournalSequence(app, trx)
+	assert.Nil(t, err)
+	journals, err := FindJournals(app, 0, latest, "/", 100, trx)
+	assert.Nil(t, err)
+
+	var events []string
+	for index, journal := range journals {
+		events = append(events, journal.Event)
+		assert.Equal(t, uint64(index+1), journal.Sequence)
+	}
+	assert.Equal(t, []string{
+		JournalCreate, JournalCreate, JournalCreate, JournalAppend, JournalOverwrite,
+		JournalHide, JournalCreate, JournalMove, JournalDelete,
+	}, events)
+	assert.Equal(t, "/save/to/1.bytes", *journals[7].PreviousPath)
+	assert.Equal(t, "/moved/1.bytes", journals[7].Path)
+
+	// the move event is included by both the previous directory and the new one,
+	// but the path out of the directory isn't exposed
+	journals, err = FindJournals(app, 0, latest, "/save", 100, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(journals))
+	assert.Equal(t, JournalDelete, journals[6].Event)
+	assert.Equal(t, "/save/to/1.bytes", journals[6].Path)
+	assert.Nil(t, journals[6].PreviousPath)
+	journals, err = FindJournals(app, 0, latest, "/moved", 100, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(journals))
+	assert.Equal(t, JournalCreate, journals[1].Event)
+	assert.Equal(t, "/moved/1.bytes", journals[1].Path)
+	assert.Nil(t, journals[1].PreviousPath)
+	journals, err = FindJournals(app, 3, latest, "/", 2, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(journals))
+	assert.Equal(t, JournalAppend, journals[0].Event)
+}
+
+func TestLockJournalSequence(t *testing.T) {
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	// locking doesn't change the sequence
+	assert.Nil(t, LockJournalSequence(app.ID, trx))
+	assert.Nil(t, LockJournalSequence(app.ID, trx))
+	sequence, err := nextJournalSequence(app.ID, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), sequence)
+	assert.Nil(t, LockJournalSequence(app.ID, trx))
+	sequence, err = nextJournalSequence(app.ID, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), sequence)
+}
+
+func TestNotifyJournal(t *testing.T) {
+	notified := JournalNotification(1)
+	assert.Equal(t, notified, JournalNotification(1))
+	another := JournalNotification(2)
+
+	NotifyJournal(1)
+	select {
+	case <-notified:
+	default:
+		t.Fatal("the watcher of app isn't notified")
+	}
+	select {
+	case <-another:
+		t.Fatal("the watcher of another app is notified")
+	default:
+	}
+	assert.NotEqual(t, notified, JournalNotification(1))
+
+	// nothing happens if there isn't any watcher
+	NotifyJournal(3)
+}
@@ -0,0 +1,120 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// MultipartUpload represent an upload that is divided into parts, it's used by
+// the multipart upload of s3 gateway. Every part is saved as an object, they are
+// concatenated into the file at Path when the upload is completed.
+type MultipartUpload struct {
+	ID        uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
+	UID       string    `gorm:"type:CHAR(32) NOT NULL;UNIQUE;column:uid"`
+	AppID     uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:appId"`
+	Path      string    `gorm:"type:VARCHAR(1000) NOT NULL;column:path"`
+	Owner     string    `gorm:"type:CHAR(32) NOT NULL;column:owner"`
+	CreatedAt time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+	UpdatedAt time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:updatedAt"`
+
+	App App `gorm:"foreignkey:appId;association_autoupdate:false;association_autocreate:false"`
+}
+
+// TableName represent the name of multipart uploads table
+func (u *MultipartUpload) TableName() string {
+	return "multipart_uploads"
+}
+
+// MultipartPart represent a part of multipart upload, uploading a part with the
+// same number again replaces the previous one
+type MultipartPart struct {
+	ID        uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
+	UploadID  uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:uploadId"`
+	Number    int       `gorm:"type:int;column:number"`
+	ObjectID  uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:objectId"`
+	CreatedAt time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+	UpdatedAt time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:updatedAt"`
+
+	Object Object `gorm:"foreignkey:objectId;association_autoupdate:false;association_autocreate:false"`
+}
+
+// TableName represent the name of multipart parts table
+func (p *MultipartPart) TableName() string {
+	return "multipart_parts"
+}
+
+// NewMultipartUpload is used to start a multipart upload of path, owner is the
+// uid of app or token that starts it
+func NewMultipartUpload(app *App, path, owner string, db *gorm.DB) (*MultipartUpload, error) {
+	var upload = &MultipartUpload{
+		UID:   UID(),
+		AppID: app.ID,
+		Path:  normalizePath(path),
+		Owner: owner,
+		App:   *app,
+	}
+	return upload, db.Create(upload).Error
+}
+
+// FindMultipartUploadByUID is used to find a multipart upload by uid
+func FindMultipartUploadByUID(uid string, db *gorm.DB) (*MultipartUpload, error) {
+	var upload = &MultipartUpload{}
+	if err := db.Preload("App").Where("uid = ?", uid).First(upload).Error; err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// SavePart is used to save the content of reader as the part with number
+func (u *MultipartUpload) SavePart(number int, reader io.Reader, rootPath *string, db *gorm.DB) (*MultipartPart, error) {
+	var (
+		err    error
+		object *Object
+		part   = &MultipartPart{}
+	)
+
+	if object, err = CreateObjectFromReader(reader, rootPath, db); err != nil {
+		return nil, err
+	}
+
+	err = db.Where("uploadId = ? and number = ?", u.ID, number).First(part).Error
+	switch {
+	case err == nil:
+		err = db.Model(part).Update("objectId", object.ID).Error
+	case gorm.IsRecordNotFoundError(err):
+		part = &MultipartPart{UploadID: u.ID, Number: number, ObjectID: object.ID}
+		err = db.Create(part).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	part.ObjectID = object.ID
+	part.Object = *object
+	return part, nil
+}
+
+// Parts return the parts whose number is greater than after, they are sorted by
+// number, and their objects are preloaded. limit < 0 represent no limit.
+func (u *MultipartUpload) Parts(after, limit int, db *gorm.DB) (parts []MultipartPart, err error) {
+	err = db.Preload("Object").
+		Where("uploadId = ? and number > ?", u.ID, after).
+		Order("number").Limit(limit).Find(&parts).Error
+	return parts, err
+}
+
+// Delete is used to delete the upload and its parts, it's called after the
+// upload is completed or aborted. The objects of parts are kept, because they
+// may be shared with other files.
+func (u *MultipartUpload) Delete(db *gorm.DB) error {
+	if err := db.Where("uploadId = ?", u.ID).Delete(MultipartPart{}).Error; err != nil {
+		return err
+	}
+	return db.Delete(u).Error
+}
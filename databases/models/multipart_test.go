@@ -0,0 +1,66 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultipartUpload_TableName(t *testing.T) {
+	assert.Equal(t, "multipart_uploads", (&MultipartUpload{}).TableName())
+	assert.Equal(t, "multipart_parts", (&MultipartPart{}).TableName())
+}
+
+func TestMultipartUpload(t *testing.T) {
+	var tempDir = NewTempDirForTest()
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	upload, err := NewMultipartUpload(app, "save/to/1.txt", app.UID, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, "/save/to/1.txt", upload.Path)
+
+	upload, err = FindMultipartUploadByUID(upload.UID, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, app.ID, upload.App.ID)
+
+	_, err = upload.SavePart(2, strings.NewReader("world"), &tempDir, trx)
+	assert.Nil(t, err)
+	_, err = upload.SavePart(1, strings.NewReader("hi"), &tempDir, trx)
+	assert.Nil(t, err)
+	part, err := upload.SavePart(1, strings.NewReader("hello"), &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, part.Object.Size)
+
+	parts, err := upload.Parts(0, -1, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(parts))
+	assert.Equal(t, 1, parts[0].Number)
+	assert.Equal(t, part.ObjectID, parts[0].Object.ID)
+	assert.Equal(t, 2, parts[1].Number)
+
+	parts, err = upload.Parts(1, 1, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(parts))
+	assert.Equal(t, 2, parts[0].Number)
+
+	assert.Nil(t, upload.Delete(trx))
+	_, err = FindMultipartUploadByUID(upload.UID, trx)
+	assert.NotNil(t, err)
+	parts, err = upload.Parts(0, -1, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(parts))
+}
@@ -0,0 +1,62 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// ErrNonceUsed represent that the nonce has been used by the app, and it
+	// hasn't expired
+	ErrNonceUsed = errors.New("nonce has been used")
+
+	// NonceNeverExpire is the expiry of the nonces that are remembered forever
+	NonceNeverExpire = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// Nonce represent a nonce that has been used by app, it's remembered until
+// expiredAt, then it can be used again and it's deleted by the sweeper
+type Nonce struct {
+	ID        uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
+	AppID     uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:appId"`
+	Nonce     string    `gorm:"type:VARCHAR(48) NOT NULL;column:nonce"`
+	ExpiredAt time.Time `gorm:"type:DATETIME(6) NOT NULL;column:expiredAt"`
+	CreatedAt time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+}
+
+// TableName represent the name of nonce table
+func (n *Nonce) TableName() string {
+	return "nonces"
+}
+
+// UseNonce is used to record that nonce is used by app until expiredAt. If the
+// nonce has been used and it hasn't expired, ErrNonceUsed is returned. It's a
+// single statement, so the concurrent requests with the same nonce can't both
+// pass.
+func UseNonce(appID uint64, nonce string, expiredAt time.Time, db *gorm.DB) error {
+	result := db.Exec(
+		"INSERT INTO nonces (appId, nonce, expiredAt) VALUES (?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE expiredAt = IF(expiredAt <= ?, VALUES(expiredAt), expiredAt)",
+		appID, nonce, expiredAt, gorm.NowFunc(),
+	)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNonceUsed
+	}
+	return nil
+}
+
+// DeleteExpiredNonces is used to delete the nonces that have expired, at most
+// limit nonces are deleted, the number of deleted nonces is returned.
+func DeleteExpiredNonces(limit int, db *gorm.DB) (int64, error) {
+	result := db.Exec("DELETE FROM nonces WHERE expiredAt <= ? ORDER BY id LIMIT ?", gorm.NowFunc(), limit)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,57 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonce_TableName(t *testing.T) {
+	assert.Equal(t, "nonces", (&Nonce{}).TableName())
+}
+
+func TestUseNonce(t *testing.T) {
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	nonce := RandomWithMD5(128)
+	assert.Nil(t, UseNonce(app.ID, nonce, time.Now().Add(time.Hour), trx))
+	assert.Equal(t, ErrNonceUsed, UseNonce(app.ID, nonce, time.Now().Add(time.Hour), trx))
+
+	// the same nonce of other app isn't affected
+	assert.Nil(t, UseNonce(app.ID+1, nonce, time.Now().Add(time.Hour), trx))
+
+	// the expired nonce can be used again
+	expired := RandomWithMD5(128)
+	assert.Nil(t, UseNonce(app.ID, expired, time.Now().Add(-time.Second), trx))
+	assert.Nil(t, UseNonce(app.ID, expired, time.Now().Add(time.Hour), trx))
+	assert.Equal(t, ErrNonceUsed, UseNonce(app.ID, expired, time.Now().Add(time.Hour), trx))
+}
+
+func TestDeleteExpiredNonces(t *testing.T) {
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	assert.Nil(t, UseNonce(app.ID, RandomWithMD5(128), time.Now().Add(-time.Second), trx))
+	assert.Nil(t, UseNonce(app.ID, RandomWithMD5(128), time.Now().Add(-time.Second), trx))
+	assert.Nil(t, UseNonce(app.ID, RandomWithMD5(128), time.Now().Add(time.Hour), trx))
+
+	count, err := DeleteExpiredNonces(1, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = DeleteExpiredNonces(10, trx)
+	assert.Nil(t, err)
+	assert.True(t, count >= 1)
+
+	var remain int
+	assert.Nil(t, trx.Model(&Nonce{}).Where("appId = ?", app.ID).Count(&remain).Error)
+	assert.Equal(t, 1, remain)
+}
@@ -0,0 +1,117 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrInvalidPublicKey represent that the public key can't be parsed
+var ErrInvalidPublicKey = errors.New("invalid public key")
+
+// PublicKey represent an ssh public key that is allowed to log in to the sftp
+// server. It belongs to an app, or to a token of app when TokenID isn't nil,
+// the key of app can't be used to log in with token, and vice versa. Key is
+// saved in the format of authorized_keys, without comment.
+type PublicKey struct {
+	ID          uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
+	UID         string    `gorm:"type:CHAR(32) NOT NULL;UNIQUE;column:uid"`
+	AppID       uint64    `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:appId"`
+	TokenID     *uint64   `gorm:"type:BIGINT(20) UNSIGNED NULL;column:tokenId"`
+	Fingerprint string    `gorm:"type:VARCHAR(64) NOT NULL;column:fingerprint"`
+	Key         string    `gorm:"type:TEXT NOT NULL;column:publicKey"`
+	Comment     *string   `gorm:"type:VARCHAR(255) NULL;column:comment"`
+	CreatedAt   time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+	UpdatedAt   time.Time `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:updatedAt"`
+}
+
+// TableName represent the name of public key table
+func (k *PublicKey) TableName() string {
+	return "public_keys"
+}
+
+// Matches represent whether key is equal to this public key
+func (k *PublicKey) Matches(key ssh.PublicKey) bool {
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k.Key))
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(parsed.Marshal(), key.Marshal())
+}
+
+// publicKeyScope limit the query to the keys of app, or the keys of token if
+// token isn't nil
+func publicKeyScope(app *App, token *Token, db *gorm.DB) *gorm.DB {
+	db = db.Where("appId = ?", app.ID)
+	if token != nil {
+		return db.Where("tokenId = ?", token.ID)
+	}
+	return db.Where("tokenId IS NULL")
+}
+
+// NewPublicKey is used to authorize a public key for app, or for token if token
+// isn't nil. authorizedKey is a line of authorized_keys file.
+func NewPublicKey(app *App, token *Token, authorizedKey string, db *gorm.DB) (*PublicKey, error) {
+	var (
+		key       = &PublicKey{UID: UID(), AppID: app.ID}
+		parsed    ssh.PublicKey
+		comment   string
+		marshaled []byte
+		err       error
+	)
+	if parsed, comment, _, _, err = ssh.ParseAuthorizedKey([]byte(authorizedKey)); err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	marshaled = ssh.MarshalAuthorizedKey(parsed)
+	key.Key = strings.TrimSpace(string(marshaled))
+	key.Fingerprint = ssh.FingerprintSHA256(parsed)
+	if comment != "" {
+		key.Comment = &comment
+	}
+	if token != nil {
+		key.TokenID = &token.ID
+	}
+	return key, db.Create(key).Error
+}
+
+// FindPublicKeyByUID is used to find a public key by uid
+func FindPublicKeyByUID(uid string, db *gorm.DB) (*PublicKey, error) {
+	var key = &PublicKey{}
+	return key, db.Where("uid = ?", uid).First(key).Error
+}
+
+// FindPublicKeys is used to find the public keys of app, or of token if token
+// isn't nil
+func FindPublicKeys(app *App, token *Token, db *gorm.DB) ([]PublicKey, error) {
+	var keys []PublicKey
+	return keys, publicKeyScope(app, token, db).Order("id").Find(&keys).Error
+}
+
+// FindAuthorizedPublicKey is used to find the public key that is equal to key
+// in the keys of app, or of token if token isn't nil
+func FindAuthorizedPublicKey(app *App, token *Token, key ssh.PublicKey, db *gorm.DB) (*PublicKey, error) {
+	var keys []PublicKey
+	if err := publicKeyScope(app, token, db).
+		Where("fingerprint = ?", ssh.FingerprintSHA256(key)).Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	for index := range keys {
+		if keys[index].Matches(key) {
+			return &keys[index], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// Delete is used to revoke the public key
+func (k *PublicKey) Delete(db *gorm.DB) error {
+	return db.Delete(k).Error
+}
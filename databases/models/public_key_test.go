@@ -0,0 +1,78 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func newPublicKeyForTest(t *testing.T) ssh.PublicKey {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	assert.Nil(t, err)
+	return publicKey
+}
+
+func TestPublicKey_TableName(t *testing.T) {
+	assert.Equal(t, "public_keys", (&PublicKey{}).TableName())
+}
+
+func TestPublicKey_Matches(t *testing.T) {
+	publicKey := newPublicKeyForTest(t)
+	key := &PublicKey{Key: string(ssh.MarshalAuthorizedKey(publicKey))}
+	assert.True(t, key.Matches(publicKey))
+	assert.False(t, key.Matches(newPublicKeyForTest(t)))
+	assert.False(t, (&PublicKey{Key: "invalid"}).Matches(publicKey))
+}
+
+func TestNewPublicKey(t *testing.T) {
+	token, trx, down, err := NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	_, err = NewPublicKey(&token.App, nil, "invalid key", trx)
+	assert.Equal(t, ErrInvalidPublicKey, err)
+
+	appPublicKey := newPublicKeyForTest(t)
+	appKey, err := NewPublicKey(
+		&token.App, nil, string(ssh.MarshalAuthorizedKey(appPublicKey))+" user@host", trx)
+	assert.Nil(t, err)
+	assert.Nil(t, appKey.TokenID)
+	assert.Equal(t, "user@host", *appKey.Comment)
+	assert.Equal(t, ssh.FingerprintSHA256(appPublicKey), appKey.Fingerprint)
+
+	tokenPublicKey := newPublicKeyForTest(t)
+	tokenKey, err := NewPublicKey(&token.App, token, string(ssh.MarshalAuthorizedKey(tokenPublicKey)), trx)
+	assert.Nil(t, err)
+	assert.Equal(t, token.ID, *tokenKey.TokenID)
+	assert.Nil(t, tokenKey.Comment)
+
+	keys, err := FindPublicKeys(&token.App, nil, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(keys))
+	assert.Equal(t, appKey.UID, keys[0].UID)
+
+	key, err := FindAuthorizedPublicKey(&token.App, token, tokenPublicKey, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, tokenKey.UID, key.UID)
+	_, err = FindAuthorizedPublicKey(&token.App, token, appPublicKey, trx)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+	_, err = FindAuthorizedPublicKey(&token.App, nil, tokenPublicKey, trx)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+
+	key, err = FindPublicKeyByUID(tokenKey.UID, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, key.Delete(trx))
+	_, err = FindAuthorizedPublicKey(&token.App, token, tokenPublicKey, trx)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
@@ -0,0 +1,150 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrShareExpired represent that the share link has expired
+	ErrShareExpired = errors.New("share link has expired")
+	// ErrShareDownloadsExhausted represent that the max downloads of share link is reached
+	ErrShareDownloadsExhausted = errors.New("the downloads of share link has already exhausted")
+	// ErrSharePassword represent that the password of share link is wrong
+	ErrSharePassword = errors.New("wrong password of share link")
+)
+
+// Share represent a public link of file or directory, it can be used without
+// the credentials of api. Password is the bcrypt hash of password, the link
+// can be used without password if it's nil. MaxDownloads is nil if downloads
+// are unlimited. A revoked link is soft deleted.
+type Share struct {
+	ID            uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
+	UID           string     `gorm:"type:CHAR(32) NOT NULL;UNIQUE;column:uid"`
+	AppID         uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:appId"`
+	TokenID       uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:tokenId"`
+	FileID        uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:fileId"`
+	Password      *string    `gorm:"type:CHAR(60) NULL;column:password"`
+	ExpiredAt     *time.Time `gorm:"type:TIMESTAMP(6) NULL;column:expiredAt"`
+	MaxDownloads  *uint64    `gorm:"type:BIGINT(20) UNSIGNED NULL;column:maxDownloads"`
+	DownloadCount uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;DEFAULT:0;column:downloadCount"`
+	CreatedAt     time.Time  `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+	UpdatedAt     time.Time  `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:updatedAt"`
+	DeletedAt     *time.Time `gorm:"type:TIMESTAMP(6);INDEX;column:deletedAt"`
+
+	App  App  `gorm:"foreignkey:appId;association_autoupdate:false;association_autocreate:false"`
+	File File `gorm:"foreignkey:fileId;association_autoupdate:false;association_autocreate:false"`
+}
+
+// TableName represent the name of share table
+func (s *Share) TableName() string {
+	return "shares"
+}
+
+// NewShare is used to share file by token, password is hashed by bcrypt before
+// it's saved
+func NewShare(token *Token, file *File, password *string, expiredAt *time.Time, maxDownloads *uint64, db *gorm.DB) (*Share, error) {
+	var share = &Share{
+		UID:          UID(),
+		AppID:        token.AppID,
+		TokenID:      token.ID,
+		FileID:       file.ID,
+		ExpiredAt:    expiredAt,
+		MaxDownloads: maxDownloads,
+		File:         *file,
+	}
+	if password != nil {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		hashedPassword := string(hashed)
+		share.Password = &hashedPassword
+	}
+	return share, db.Create(share).Error
+}
+
+// FindShareByUID is used to find a share link by uid, the file and app are
+// preloaded, revoked links aren't included
+func FindShareByUID(uid string, db *gorm.DB) (*Share, error) {
+	var share = &Share{}
+	return share, db.Preload("App").Preload("File").Where("uid = ?", uid).First(share).Error
+}
+
+// FindSharesInPath is used to find the share links of app whose files are in
+// dirPath, the newest are returned first. Their files are preloaded.
+func FindSharesInPath(app *App, dirPath string, offset, limit int, db *gorm.DB) (total int, shares []Share, err error) {
+	var (
+		prefix = normalizePath(dirPath)
+		query  = db.Model(&Share{}).
+			Joins("join files on files.id = shares.fileId").
+			Where("shares.appId = ?", app.ID)
+	)
+	if prefix != "/" {
+		query = query.Where("files.fullPath = ? or files.fullPath like binary ?", prefix, escapeLike(prefix)+"/%")
+	}
+	if err = query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	err = query.Select("shares.*").Preload("File").Order("shares.id DESC").Offset(offset).Limit(limit).Find(&shares).Error
+	return total, shares, err
+}
+
+// CheckPassword is used to verify the password of share link, it always passes
+// if the link has no password
+func (s *Share) CheckPassword(password string) error {
+	if s.Password == nil {
+		return nil
+	}
+	if bcrypt.CompareHashAndPassword([]byte(*s.Password), []byte(password)) != nil {
+		return ErrSharePassword
+	}
+	return nil
+}
+
+// IsExpired represent whether the share link has expired
+func (s *Share) IsExpired() bool {
+	return s.ExpiredAt != nil && !s.ExpiredAt.After(gorm.NowFunc())
+}
+
+// Available is used to check whether the share link can still be used, it
+// can't be used after it expires or its downloads are exhausted
+func (s *Share) Available() error {
+	if s.IsExpired() {
+		return ErrShareExpired
+	}
+	if s.MaxDownloads != nil && s.DownloadCount >= *s.MaxDownloads {
+		return ErrShareDownloadsExhausted
+	}
+	return nil
+}
+
+// IncreaseDownloadCount is used to count a download of share link, it's done
+// in one statement, so that concurrent downloads can't exceed MaxDownloads
+func (s *Share) IncreaseDownloadCount(db *gorm.DB) error {
+	query := db.Model(&Share{}).Where("id = ?", s.ID)
+	if s.MaxDownloads != nil {
+		query = query.Where("downloadCount < ?", *s.MaxDownloads)
+	}
+	result := query.UpdateColumn("downloadCount", gorm.Expr("downloadCount + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareDownloadsExhausted
+	}
+	s.DownloadCount++
+	return nil
+}
+
+// Revoke is used to revoke the share link, it can't be used any more
+func (s *Share) Revoke(db *gorm.DB) error {
+	return db.Delete(s).Error
+}
@@ -0,0 +1,115 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestShare_TableName(t *testing.T) {
+	assert.Equal(t, "shares", (&Share{}).TableName())
+}
+
+func TestShare_CheckPassword(t *testing.T) {
+	assert.Nil(t, (&Share{}).CheckPassword(""))
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+	password := string(hashed)
+	share := &Share{Password: &password}
+	assert.Nil(t, share.CheckPassword("password"))
+	assert.Equal(t, ErrSharePassword, share.CheckPassword("wrong"))
+	assert.Equal(t, ErrSharePassword, share.CheckPassword(""))
+}
+
+func TestShare_Available(t *testing.T) {
+	var (
+		past         = time.Now().Add(-time.Second)
+		future       = time.Now().Add(time.Hour)
+		maxDownloads = uint64(2)
+	)
+	assert.Nil(t, (&Share{}).Available())
+	assert.Nil(t, (&Share{ExpiredAt: &future}).Available())
+	assert.Equal(t, ErrShareExpired, (&Share{ExpiredAt: &past}).Available())
+	assert.Nil(t, (&Share{MaxDownloads: &maxDownloads, DownloadCount: 1}).Available())
+	assert.Equal(t, ErrShareDownloadsExhausted, (&Share{MaxDownloads: &maxDownloads, DownloadCount: 2}).Available())
+}
+
+func TestNewShare(t *testing.T) {
+	var (
+		password     = "password"
+		maxDownloads = uint64(1)
+		tempDir      = NewTempDirForTest()
+	)
+	defer os.RemoveAll(tempDir)
+	token, trx, down, err := NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	file, err := CreateFileFromReader(&token.App, "/share/a.txt", strings.NewReader("share"), 0, &tempDir, trx)
+	assert.Nil(t, err)
+
+	share, err := NewShare(token, file, &password, nil, &maxDownloads, trx)
+	assert.Nil(t, err)
+	assert.NotEqual(t, password, *share.Password)
+
+	share, err = FindShareByUID(share.UID, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, file.ID, share.File.ID)
+	assert.Equal(t, token.AppID, share.App.ID)
+	assert.Nil(t, share.CheckPassword(password))
+
+	assert.Nil(t, share.IncreaseDownloadCount(trx))
+	assert.Equal(t, uint64(1), share.DownloadCount)
+	assert.Equal(t, ErrShareDownloadsExhausted, share.IncreaseDownloadCount(trx))
+	assert.Equal(t, ErrShareDownloadsExhausted, share.Available())
+
+	dir, err := FindFileByPath(&token.App, "/share", trx)
+	assert.Nil(t, err)
+	dirShare, err := NewShare(token, dir, nil, nil, nil, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, dirShare.Password)
+	assert.Nil(t, dirShare.IncreaseDownloadCount(trx))
+	assert.Nil(t, dirShare.IncreaseDownloadCount(trx))
+
+	total, shares, err := FindSharesInPath(&token.App, "/share", 0, 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, dirShare.UID, shares[0].UID)
+	assert.Equal(t, file.UID, shares[1].File.UID)
+
+	total, _, err = FindSharesInPath(&token.App, "/sha", 0, 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+
+	assert.Nil(t, share.Revoke(trx))
+	_, err = FindShareByUID(share.UID, trx)
+	assert.NotNil(t, err)
+	total, _, err = FindSharesInPath(&token.App, "/", 0, 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestFile_IncreaseDownloadCount(t *testing.T) {
+	tempDir := NewTempDirForTest()
+	defer os.RemoveAll(tempDir)
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	file, err := CreateFileFromReader(app, "/a.txt", strings.NewReader("a"), 0, &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, file.IncreaseDownloadCount(trx))
+	assert.Equal(t, uint64(1), file.DownloadCount)
+	file, err = FindFileByUID(file.UID, false, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), file.DownloadCount)
+}
@@ -5,7 +5,7 @@
 package models
 
 import (
-	"fmt"
+	"path"
 	"strings"
 	"time"
 
@@ -50,12 +50,10 @@ func (t *Token) Scope() string {
 	return t.Path
 }
 
-// PathWithScope will return a complete path with scope of token
-func (t *Token) PathWithScope(path string) string {
-	return fmt.Sprintf(
-		"%s/%s",
-		strings.TrimSuffix(t.Path, "/"), strings.Trim(path, "/"),
-	)
+// PathWithScope will return a complete path with scope of token, the given
+// path is cleaned as an absolute path first, so it can't escape from the scope
+func (t *Token) PathWithScope(p string) string {
+	return path.Join(t.Path, path.Clean("/"+p))
 }
 
 // BeforeSave will be called before token saved
@@ -80,10 +78,27 @@ func (t *Token) UpdateAvailableTimes(inc int, db *gorm.DB) error {
 	if t.AvailableTimes == -1 {
 		return nil
 	}
-	t.AvailableTimes--
+	t.AvailableTimes += inc
 	return db.Model(t).Update("availableTimes", t.AvailableTimes).Error
 }
 
+// ChargeAvailableTimes is used to take n available times of this token
+// atomically, false is returned if the token doesn't have n available times
+// left. The times are given back if n is negative. It's used when the times
+// are charged without locking the token.
+func (t *Token) ChargeAvailableTimes(n int, db *gorm.DB) (bool, error) {
+	if t.AvailableTimes == -1 {
+		return true, nil
+	}
+	result := db.Model(&Token{}).Where("id = ? AND availableTimes >= ?", t.ID, n).
+		UpdateColumn("availableTimes", gorm.Expr("availableTimes - ?", n))
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, db.Table(t.TableName()).Select("availableTimes").
+		Where("id = ?", t.ID).Row().Scan(&t.AvailableTimes)
+}
+
 // NewToken will generate a token by input params
 func NewToken(
 	app *App, path string, expiredAt *time.Time, ip, secret *string, availableTimes int, readOnly int8, db *gorm.DB,
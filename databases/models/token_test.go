@@ -172,6 +172,9 @@ func TestToken_PathWithScope(t *testing.T) {
 	confirm.Equal("/test/save/to/pkg/golang.pkg", token.PathWithScope("save/to/pkg/golang.pkg"))
 	confirm.Equal("/test/save/to/pkg/golang.pkg", token.PathWithScope("/save/to/pkg/golang.pkg"))
 	confirm.Equal("/test/save/to/pkg/golang.pkg", token.PathWithScope("/save/to/pkg/golang.pkg/"))
+	confirm.Equal("/test/save/to/pkg/golang.pkg", token.PathWithScope("//save/./to//pkg/golang.pkg"))
+	confirm.Equal("/test/golang.pkg", token.PathWithScope("/../../golang.pkg"))
+	confirm.Equal("/test", token.PathWithScope(""))
 }
 
 func TestToken_UpdateAvailableTimes(t *testing.T) {
@@ -207,3 +210,26 @@ func TestToken_UpdateAvailableTimes2(t *testing.T) {
 	assert.Nil(t, token.UpdateAvailableTimes(-1, trx))
 	assert.Equal(t, token.AvailableTimes, -1)
 }
+
+func TestToken_ChargeAvailableTimes(t *testing.T) {
+	confirm := assert.New(t)
+	token, trx, down, err := newTokenForTest(nil, t, "test", nil, nil, nil, 3, int8(0))
+	confirm.Nil(err)
+	defer down(t)
+
+	charged, err := token.ChargeAvailableTimes(2, trx)
+	confirm.Nil(err)
+	confirm.True(charged)
+	confirm.Equal(1, token.AvailableTimes)
+
+	charged, err = token.ChargeAvailableTimes(2, trx)
+	confirm.Nil(err)
+	confirm.False(charged)
+	confirm.Equal(1, token.AvailableTimes)
+
+	// the times can be given back by a negative n
+	charged, err = token.ChargeAvailableTimes(-1, trx)
+	confirm.Nil(err)
+	confirm.True(charged)
+	confirm.Equal(2, token.AvailableTimes)
+}
@@ -0,0 +1,335 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// WebhookEventCreated represent that a file or a directory is created
+	WebhookEventCreated = "created"
+	// WebhookEventUpdated represent that a file or a directory is changed,
+	// including overwrite, append, move and hide
+	WebhookEventUpdated = "updated"
+	// WebhookEventDeleted represent that a file or a directory is deleted
+	WebhookEventDeleted = "deleted"
+	// WebhookEventRestored represent that a deleted file is restored
+	WebhookEventRestored = "restored"
+
+	// WebhookDeliveryPending represent that the delivery is waiting to be sent
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliverySucceeded represent that the delivery has been accepted
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryFailed represent that the delivery is given up
+	WebhookDeliveryFailed = "failed"
+
+	// WebhookMaxAttempts is the max number of attempts of a delivery
+	WebhookMaxAttempts = 10
+	// WebhookMinBackoff is the delay before the first retry, it's doubled
+	// for every following retry
+	WebhookMinBackoff = 30 * time.Second
+	// WebhookMaxBackoff is the max delay between two attempts
+	WebhookMaxBackoff = time.Hour
+)
+
+// WebhookEvents contains all events that can be subscribed
+var WebhookEvents = []string{
+	WebhookEventCreated, WebhookEventUpdated, WebhookEventDeleted, WebhookEventRestored,
+}
+
+// Webhook represent an endpoint of app that is notified when files change.
+// Events is a comma separated list of subscribed events, all events are
+// subscribed if it's empty. Only the changes in PathPrefix are notified.
+type Webhook struct {
+	ID         uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
+	UID        string     `gorm:"type:CHAR(32) NOT NULL;UNIQUE;column:uid"`
+	AppID      uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:appId"`
+	URL        string     `gorm:"type:VARCHAR(1000) NOT NULL;column:url"`
+	Events     string     `gorm:"type:VARCHAR(100) NOT NULL;column:events"`
+	PathPrefix string     `gorm:"type:VARCHAR(1000) NOT NULL;column:pathPrefix"`
+	CreatedAt  time.Time  `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+	UpdatedAt  time.Time  `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:updatedAt"`
+	DeletedAt  *time.Time `gorm:"type:TIMESTAMP(6);INDEX;column:deletedAt"`
+	App        App        `gorm:"foreignkey:appId;association_foreignkey:id"`
+}
+
+// TableName represent the name of webhook table
+func (w *Webhook) TableName() string {
+	return "webhooks"
+}
+
+// EventList return the list of subscribed events
+func (w *Webhook) EventList() []string {
+	if w.Events == "" {
+		return WebhookEvents
+	}
+	return strings.Split(w.Events, ",")
+}
+
+// Subscribed represent whether the event in path should be sent to webhook, a
+// move event is sent if either path is in PathPrefix
+func (w *Webhook) Subscribed(event, path string, previousPath *string) bool {
+	var (
+		subscribed bool
+		prefix     = normalizePath(w.PathPrefix)
+		inPrefix   = func(p string) bool {
+			return prefix == "/" || p == prefix || strings.HasPrefix(p, prefix+"/")
+		}
+	)
+	for _, e := range w.EventList() {
+		if e == event {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		return false
+	}
+	return inPrefix(path) || (previousPath != nil && inPrefix(*previousPath))
+}
+
+// NewWebhook is used to register a webhook for app
+func NewWebhook(app *App, url string, events []string, pathPrefix string, db *gorm.DB) (*Webhook, error) {
+	var webhook = &Webhook{
+		UID:        UID(),
+		AppID:      app.ID,
+		URL:        url,
+		Events:     strings.Join(events, ","),
+		PathPrefix: normalizePath(pathPrefix),
+		App:        *app,
+	}
+	return webhook, db.Create(webhook).Error
+}
+
+// Delete is used to delete webhook, the pending deliveries are given up
+func (w *Webhook) Delete(db *gorm.DB) error {
+	if err := db.Model(&WebhookDelivery{}).
+		Where("webhookId = ? and status = ?", w.ID, WebhookDeliveryPending).
+		Updates(map[string]interface{}{
+			"status": WebhookDeliveryFailed, "lastError": "webhook is deleted",
+		}).Error; err != nil {
+		return err
+	}
+	return db.Delete(w).Error
+}
+
+// FindWebhookByUID is used to find a webhook by uid
+func FindWebhookByUID(uid string, db *gorm.DB) (*Webhook, error) {
+	var webhook = &Webhook{}
+	return webhook, db.Preload("App").Where("uid = ?", uid).First(webhook).Error
+}
+
+// FindWebhooksByApp is used to find all webhooks of app
+func FindWebhooksByApp(app *App, db *gorm.DB) ([]Webhook, error) {
+	var webhooks []Webhook
+	return webhooks, db.Where("appId = ?", app.ID).Order("id").Find(&webhooks).Error
+}
+
+// WebhookPayloadFile represent the file in webhook payload
+type WebhookPayloadFile struct {
+	UID          string  `json:"uid"`
+	Path         string  `json:"path"`
+	PreviousPath *string `json:"previousPath,omitempty"`
+	IsDir        bool    `json:"isDir"`
+	Hidden       bool    `json:"hidden"`
+	Size         int     `json:"size"`
+}
+
+// WebhookPayload represent the body of delivery, it's marshaled as json
+type WebhookPayload struct {
+	DeliveryUID string             `json:"deliveryUid"`
+	WebhookUID  string             `json:"webhookUid"`
+	Event       string             `json:"event"`
+	Sequence    uint64             `json:"sequence"`
+	OccurredAt  int64              `json:"occurredAt"`
+	File        WebhookPayloadFile `json:"file"`
+}
+
+// WebhookDelivery represent a notification of webhook. It's a persistent queue
+// of pending deliveries and the log of sent deliveries at the same time.
+type WebhookDelivery struct {
+	ID            uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT;primary_key"`
+	UID           string     `gorm:"type:CHAR(32) NOT NULL;UNIQUE;column:uid"`
+	WebhookID     uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:webhookId"`
+	Event         string     `gorm:"type:VARCHAR(16) NOT NULL;column:event"`
+	Sequence      uint64     `gorm:"type:BIGINT(20) UNSIGNED NOT NULL;column:sequence"`
+	Payload       string     `gorm:"type:TEXT NOT NULL;column:payload"`
+	Status        string     `gorm:"type:VARCHAR(16) NOT NULL;column:status"`
+	Attempts      int        `gorm:"type:int;column:attempts;DEFAULT:0"`
+	NextAttemptAt time.Time  `gorm:"type:TIMESTAMP(6) NOT NULL;column:nextAttemptAt"`
+	ResponseCode  *int       `gorm:"type:int;column:responseCode"`
+	LastError     *string    `gorm:"type:VARCHAR(1000) NULL;column:lastError"`
+	DeliveredAt   *time.Time `gorm:"type:TIMESTAMP(6) NULL;column:deliveredAt"`
+	CreatedAt     time.Time  `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:createdAt"`
+	UpdatedAt     time.Time  `gorm:"type:TIMESTAMP(6) NOT NULL;DEFAULT:CURRENT_TIMESTAMP(6);column:updatedAt"`
+	Webhook       Webhook    `gorm:"foreignkey:webhookId;association_foreignkey:id"`
+}
+
+// TableName represent the name of webhook delivery table
+func (d *WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookRetryBackoff return the delay before the next attempt, attempts is the
+// number of attempts that have been made
+func WebhookRetryBackoff(attempts int) time.Duration {
+	var backoff = WebhookMinBackoff
+	for i := 1; i < attempts && backoff < WebhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > WebhookMaxBackoff {
+		backoff = WebhookMaxBackoff
+	}
+	return backoff
+}
+
+// Claim is used to take the delivery before sending it. The next attempt is
+// postponed by lease, so that other dispatchers skip it, and the delivery will
+// be sent again after lease if the dispatcher exits unexpectedly.
+func (d *WebhookDelivery) Claim(lease time.Duration, db *gorm.DB) (bool, error) {
+	var (
+		now    = gorm.NowFunc()
+		result = db.Model(&WebhookDelivery{}).
+			Where("id = ? and status = ? and attempts = ? and nextAttemptAt <= ?",
+				d.ID, WebhookDeliveryPending, d.Attempts, now).
+			Update("nextAttemptAt", now.Add(lease))
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RecordAttempt is used to record the result of an attempt. The delivery
+// succeeds if the error is nil, otherwise it's retried with backoff until
+// WebhookMaxAttempts is reached.
+func (d *WebhookDelivery) RecordAttempt(responseCode *int, attemptErr error, db *gorm.DB) error {
+	var now = gorm.NowFunc()
+
+	d.Attempts++
+	d.ResponseCode = responseCode
+	d.LastError = nil
+	if attemptErr == nil {
+		d.Status = WebhookDeliverySucceeded
+		d.DeliveredAt = &now
+	} else {
+		lastError := attemptErr.Error()
+		if len(lastError) > 1000 {
+			lastError = lastError[:1000]
+		}
+		d.LastError = &lastError
+		if d.Attempts >= WebhookMaxAttempts {
+			d.Status = WebhookDeliveryFailed
+		} else {
+			d.NextAttemptAt = now.Add(WebhookRetryBackoff(d.Attempts))
+		}
+	}
+
+	return db.Model(d).Updates(map[string]interface{}{
+		"status":        d.Status,
+		"attempts":      d.Attempts,
+		"nextAttemptAt": d.NextAttemptAt,
+		"responseCode":  d.ResponseCode,
+		"lastError":     d.LastError,
+		"deliveredAt":   d.DeliveredAt,
+	}).Error
+}
+
+// FindDueWebhookDeliveries is used to find the pending deliveries whose next
+// attempt is due, the webhook and its app are preloaded
+func FindDueWebhookDeliveries(limit int, db *gorm.DB) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	return deliveries, db.Preload("Webhook").Preload("Webhook.App").
+		Where("status = ? and nextAttemptAt <= ?", WebhookDeliveryPending, gorm.NowFunc()).
+		Order("nextAttemptAt, id").Limit(limit).Find(&deliveries).Error
+}
+
+// FindWebhookDeliveries is used to find the deliveries of webhook from the
+// newest one, status is optional
+func FindWebhookDeliveries(webhook *Webhook, status *string, offset, limit int, db *gorm.DB) (
+	total int, deliveries []WebhookDelivery, err error) {
+	var query = db.Model(&WebhookDelivery{}).Where("webhookId = ?", webhook.ID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if err = query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	err = query.Order("id desc").Offset(offset).Limit(limit).Find(&deliveries).Error
+	return total, deliveries, err
+}
+
+// webhookEvent return the webhook event of journal event
+func webhookEvent(journalEvent string) string {
+	switch journalEvent {
+	case JournalCreate:
+		return WebhookEventCreated
+	case JournalDelete:
+		return WebhookEventDeleted
+	case JournalRestore:
+		return WebhookEventRestored
+	default:
+		return WebhookEventUpdated
+	}
+}
+
+// enqueueWebhookDeliveries is used to push the journal to the queue of every
+// subscribed webhook. It's called in the same transaction as the journal, so
+// a notification is sent if and only if the change is committed.
+func enqueueWebhookDeliveries(journal *Journal, db *gorm.DB) error {
+	var (
+		err      error
+		payload  []byte
+		webhooks []Webhook
+		event    = webhookEvent(journal.Event)
+		now      = gorm.NowFunc()
+	)
+
+	if webhooks, err = FindWebhooksByApp(&App{ID: journal.AppID}, db); err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(event, journal.Path, journal.PreviousPath) {
+			continue
+		}
+		delivery := &WebhookDelivery{
+			UID:           UID(),
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Sequence:      journal.Sequence,
+			Status:        WebhookDeliveryPending,
+			NextAttemptAt: now,
+		}
+		if payload, err = json.Marshal(&WebhookPayload{
+			DeliveryUID: delivery.UID,
+			WebhookUID:  webhook.UID,
+			Event:       event,
+			Sequence:    journal.Sequence,
+			OccurredAt:  now.Unix(),
+			File: WebhookPayloadFile{
+				UID:          journal.FileUID,
+				Path:         journal.Path,
+				PreviousPath: journal.PreviousPath,
+				IsDir:        journal.IsDir == IsDir,
+				Hidden:       journal.Hidden == Hidden,
+				Size:         journal.Size,
+			},
+		}); err != nil {
+			return err
+		}
+		delivery.Payload = string(payload)
+		if err = db.Create(delivery).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_TableName(t *testing.T) {
+	assert.Equal(t, "webhooks", (&Webhook{}).TableName())
+	assert.Equal(t, "webhook_deliveries", (&WebhookDelivery{}).TableName())
+}
+
+func TestWebhook_Subscribed(t *testing.T) {
+	var (
+		webhook      = &Webhook{Events: "created,deleted", PathPrefix: "/save"}
+		previousPath = "/save/1.bytes"
+	)
+	assert.Equal(t, []string{WebhookEventCreated, WebhookEventDeleted}, webhook.EventList())
+	assert.True(t, webhook.Subscribed(WebhookEventCreated, "/save", nil))
+	assert.True(t, webhook.Subscribed(WebhookEventDeleted, "/save/to/1.bytes", nil))
+	assert.False(t, webhook.Subscribed(WebhookEventUpdated, "/save/to/1.bytes", nil))
+	assert.False(t, webhook.Subscribed(WebhookEventCreated, "/saved/1.bytes", nil))
+	assert.True(t, webhook.Subscribed(WebhookEventCreated, "/other/1.bytes", &previousPath))
+
+	webhook = &Webhook{PathPrefix: "/"}
+	assert.Equal(t, WebhookEvents, webhook.EventList())
+	assert.True(t, webhook.Subscribed(WebhookEventRestored, "/anywhere", nil))
+}
+
+func TestWebhookRetryBackoff(t *testing.T) {
+	assert.Equal(t, WebhookMinBackoff, WebhookRetryBackoff(1))
+	assert.Equal(t, 2*WebhookMinBackoff, WebhookRetryBackoff(2))
+	assert.Equal(t, 8*WebhookMinBackoff, WebhookRetryBackoff(4))
+	assert.Equal(t, WebhookMaxBackoff, WebhookRetryBackoff(WebhookMaxAttempts))
+}
+
+func TestEnqueueWebhookDeliveries(t *testing.T) {
+	app, trx, down, err := newAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	webhook, err := NewWebhook(app, "http://127.0.0.1/hook", []string{WebhookEventCreated}, "/save", trx)
+	assert.Nil(t, err)
+	all, err := NewWebhook(app, "http://127.0.0.1/all", nil, "/", trx)
+	assert.Nil(t, err)
+	webhooks, err := FindWebhooksByApp(app, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(webhooks))
+
+	dir, err := CreateOrGetLastDirectory(app, "/save/to", trx)
+	assert.Nil(t, err)
+	_, err = CreateOrGetLastDirectory(app, "/other", trx)
+	assert.Nil(t, err)
+	assert.Nil(t, dir.SetHidden(Hidden, trx))
+
+	total, deliveries, err := FindWebhookDeliveries(webhook, nil, 0, 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	var payload WebhookPayload
+	assert.Nil(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, deliveries[0].UID, payload.DeliveryUID)
+	assert.Equal(t, webhook.UID, payload.WebhookUID)
+	assert.Equal(t, WebhookEventCreated, payload.Event)
+	assert.Equal(t, "/save/to", payload.File.Path)
+	assert.True(t, payload.File.IsDir)
+
+	total, _, err = FindWebhookDeliveries(all, nil, 0, 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, total)
+	pending := WebhookDeliveryPending
+	total, deliveries, err = FindWebhookDeliveries(all, &pending, 0, 1, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, 1, len(deliveries))
+	assert.Equal(t, WebhookEventUpdated, deliveries[0].Event)
+
+	due, err := FindDueWebhookDeliveries(100, trx)
+	assert.Nil(t, err)
+	assert.True(t, len(due) >= 6)
+
+	delivery := &deliveries[0]
+	claimed, err := delivery.Claim(time.Minute, trx)
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	claimed, err = delivery.Claim(time.Minute, trx)
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+
+	responseCode := 500
+	assert.Nil(t, delivery.RecordAttempt(&responseCode, errors.New("unexpected status code 500"), trx))
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, WebhookDeliveryPending, delivery.Status)
+	assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+	assert.Equal(t, "unexpected status code 500", *delivery.LastError)
+
+	responseCode = 200
+	assert.Nil(t, delivery.RecordAttempt(&responseCode, nil, trx))
+	assert.Equal(t, WebhookDeliverySucceeded, delivery.Status)
+	assert.NotNil(t, delivery.DeliveredAt)
+	assert.Nil(t, delivery.LastError)
+
+	delivery.Status = WebhookDeliveryPending
+	delivery.Attempts = WebhookMaxAttempts - 1
+	assert.Nil(t, delivery.RecordAttempt(nil, errors.New("timeout"), trx))
+	assert.Equal(t, WebhookDeliveryFailed, delivery.Status)
+
+	assert.Nil(t, webhook.Delete(trx))
+	total, _, err = FindWebhookDeliveries(webhook, &pending, 0, 10, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+	_, err = FindWebhookByUID(webhook.UID, trx)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,194 @@
+//   Copyright 2019 The bigfile Authors. All rights reserved.
+//   Use of this source code is governed by a MIT-style
+//   license that can be found in the LICENSE file.
+
+package ftp
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	jsoniter "github.com/json-iterator/go"
+	"goftp.io/server"
+)
+
+const (
+	// protocol is the protocol of ftp request records
+	protocol = "ftp"
+
+	// loginMethod is the method of login records
+	loginMethod = "LOGIN"
+
+	// connectionTerminated is the message that is logged when connection is closed
+	connectionTerminated = "Connection Terminated"
+)
+
+// AuditLogger is used to record the logins of ftp in the requests table, both
+// successful and failed. goftp doesn't expose the result of authentication, so
+// it's recognized from the responses of PASS command. The other messages are
+// passed to the wrapped logger.
+type AuditLogger struct {
+	server.Logger
+	sessions sync.Map
+}
+
+// NewAuditLogger return an AuditLogger that wraps logger
+func NewAuditLogger(logger server.Logger) *AuditLogger {
+	return &AuditLogger{Logger: logger}
+}
+
+// register is used to bind the driver to the session of connection
+func (a *AuditLogger) register(conn *server.Conn, d *Driver) {
+	if conn == nil {
+		return
+	}
+	if field := reflect.ValueOf(conn).Elem().FieldByName("sessionID"); field.IsValid() {
+		a.sessions.Store(field.String(), d)
+	}
+}
+
+// driver return the driver of session, nil is returned if it's unknown
+func (a *AuditLogger) driver(sessionID string) *Driver {
+	if d, ok := a.sessions.Load(sessionID); ok {
+		return d.(*Driver)
+	}
+	return nil
+}
+
+// Print implement server.Logger, the session is released when the connection
+// is terminated
+func (a *AuditLogger) Print(sessionID string, message interface{}) {
+	if message == connectionTerminated {
+		a.sessions.Delete(sessionID)
+	}
+	a.Logger.Print(sessionID, message)
+}
+
+// PrintCommand implement server.Logger
+func (a *AuditLogger) PrintCommand(sessionID string, command string, params string) {
+	if d := a.driver(sessionID); d != nil {
+		d.lastCommand = strings.ToUpper(command)
+		if d.lastCommand == "USER" {
+			d.loginUser = params
+		}
+	}
+	a.Logger.PrintCommand(sessionID, command, params)
+}
+
+// PrintResponse implement server.Logger, the login is recorded when PASS command
+// is responded, 230 represent that password is correct
+func (a *AuditLogger) PrintResponse(sessionID string, code int, message string) {
+	if d := a.driver(sessionID); d != nil && d.lastCommand == "PASS" {
+		d.lastCommand = ""
+		d.recordLogin(code == 230, message)
+	}
+	a.Logger.PrintResponse(sessionID, code, message)
+}
+
+// responseCode convert the error of operation to the code of request record
+func responseCode(err error) int {
+	switch {
+	case err == nil:
+		return 200
+	case gorm.IsRecordNotFoundError(err):
+		return 404
+	}
+	return 400
+}
+
+// recordLogin is used to record the login of session, the app and token are
+// recorded if they can be found by the login user
+func (d *Driver) recordLogin(success bool, message string) {
+	if d.protocol == "" {
+		return
+	}
+	var (
+		method      = loginMethod
+		requestBody string
+		record      = &models.Request{
+			Protocol:     d.protocol,
+			IP:           connIP(d.conn),
+			Method:       &method,
+			ResponseCode: 200,
+			ResponseBody: message,
+		}
+	)
+	if !success {
+		record.ResponseCode = 401
+	}
+	requestBody, _ = jsoniter.MarshalToString(map[string]interface{}{"user": d.loginUser})
+	record.RequestBody = requestBody
+	if strings.HasPrefix(d.loginUser, tokenPrefix) {
+		if token, err := models.FindTokenByUID(strings.TrimPrefix(d.loginUser, tokenPrefix), d.db); err == nil {
+			record.AppID, record.Token = &token.AppID, &token.UID
+		}
+	} else if app, err := models.FindAppByUID(d.loginUser, d.db); err == nil {
+		record.AppID = &app.ID
+	}
+	d.db.Create(record)
+}
+
+// record is used to record the command that changes or transfers the data of
+// file, path is the real path of file, body contains the details of command
+func (d *Driver) record(method, path string, body map[string]interface{}, err error) {
+	if d.protocol == "" || d.app == nil {
+		return
+	}
+	var (
+		requestBody string
+		record      = &models.Request{
+			Protocol:     d.protocol,
+			AppID:        &d.app.ID,
+			IP:           d.ip,
+			Method:       &method,
+			Service:      &path,
+			ResponseCode: responseCode(err),
+		}
+	)
+	if d.token != nil {
+		record.Token = &d.token.UID
+	}
+	if d.ip == nil {
+		record.IP = connIP(d.conn)
+	}
+	if err != nil {
+		record.ResponseBody = err.Error()
+	}
+	body["path"] = path
+	requestBody, _ = jsoniter.MarshalToString(body)
+	record.RequestBody = requestBody
+	d.db.Create(record)
+}
+
+// recordReadCloser is used to count the bytes that are downloaded, the download
+// is recorded when it's closed
+type recordReadCloser struct {
+	io.ReadCloser
+	bytes int64
+	err   error
+	close func(bytes int64, err error)
+}
+
+// Read implement io.Reader
+func (r *recordReadCloser) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	r.bytes += int64(n)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// Close implement io.Closer
+func (r *recordReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	if r.close != nil {
+		r.close(r.bytes, r.err)
+		r.close = nil
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+//   Copyright 2019 The bigfile Authors. All rights reserved.
+//   Use of this source code is governed by a MIT-style
+//   license that can be found in the LICENSE file.
+
+package ftp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"goftp.io/server"
+)
+
+func TestResponseCode(t *testing.T) {
+	assert.Equal(t, 200, responseCode(nil))
+	assert.Equal(t, 404, responseCode(gorm.ErrRecordNotFound))
+	assert.Equal(t, 400, responseCode(models.ErrAccessDenied))
+}
+
+func TestRecordReadCloser(t *testing.T) {
+	var (
+		recordedBytes int64
+		recordedErr   error
+		closeTimes    int
+		readErr       = errors.New("read failed")
+		reader        = &recordReadCloser{
+			ReadCloser: ioutil.NopCloser(strings.NewReader("hello world")),
+			close: func(bytes int64, err error) {
+				recordedBytes, recordedErr = bytes, err
+				closeTimes++
+			},
+		}
+	)
+	content, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", string(content))
+	assert.Nil(t, reader.Close())
+	assert.Nil(t, reader.Close())
+	assert.Equal(t, int64(11), recordedBytes)
+	assert.Nil(t, recordedErr)
+	assert.Equal(t, 1, closeTimes)
+
+	reader = &recordReadCloser{
+		ReadCloser: ioutil.NopCloser(io.MultiReader(strings.NewReader("hello"), iotestErrReader{readErr})),
+		close: func(bytes int64, err error) {
+			recordedBytes, recordedErr = bytes, err
+		},
+	}
+	_, err = ioutil.ReadAll(reader)
+	assert.Equal(t, readErr, err)
+	assert.Nil(t, reader.Close())
+	assert.Equal(t, int64(5), recordedBytes)
+	assert.Equal(t, readErr, recordedErr)
+}
+
+type iotestErrReader struct {
+	err error
+}
+
+func (r iotestErrReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+func TestAuditLogger_Sessions(t *testing.T) {
+	var (
+		logger = NewAuditLogger(&server.DiscardLogger{})
+		conn   = newConn("")
+		driver = &Driver{}
+	)
+	driver.audit = logger
+	driver.Init(conn)
+	sessionID := ""
+	logger.sessions.Range(func(key, value interface{}) bool {
+		sessionID = key.(string)
+		return true
+	})
+	assert.Equal(t, driver, logger.driver(sessionID))
+	assert.Nil(t, logger.driver("not-exist"))
+
+	logger.PrintCommand(sessionID, "user", "hello")
+	assert.Equal(t, "USER", driver.lastCommand)
+	assert.Equal(t, "hello", driver.loginUser)
+	logger.PrintCommand(sessionID, "PASS", "world")
+	logger.PrintResponse(sessionID, 230, "Password ok, continue")
+	assert.Equal(t, "", driver.lastCommand)
+
+	logger.Print(sessionID, connectionTerminated)
+	assert.Nil(t, logger.driver(sessionID))
+}
+
+func findRequestsForTest(t *testing.T, app *models.App, method string, db *gorm.DB) []models.Request {
+	var requests []models.Request
+	assert.Nil(t, db.Where("appId = ? AND protocol = ? AND method = ?", app.ID, protocol, method).
+		Order("id").Find(&requests).Error)
+	return requests
+}
+
+func TestAuditLogger_Login(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+	logger := NewAuditLogger(&server.DiscardLogger{})
+
+	login := func(user string, code int) {
+		driver := &Driver{db: trx, protocol: protocol, audit: logger}
+		driver.Init(newConnWithIP("", "192.168.0.1"))
+		var sessionID string
+		logger.sessions.Range(func(key, value interface{}) bool {
+			if value.(*Driver) == driver {
+				sessionID = key.(string)
+			}
+			return true
+		})
+		logger.PrintCommand(sessionID, "USER", user)
+		logger.PrintResponse(sessionID, 331, "User name ok, password required")
+		logger.PrintCommand(sessionID, "PASS", "****")
+		logger.PrintResponse(sessionID, code, "")
+		logger.Print(sessionID, connectionTerminated)
+	}
+	login(token.App.UID, 230)
+	login(tokenPrefix+token.UID, 550)
+
+	requests := findRequestsForTest(t, &token.App, loginMethod, trx)
+	assert.Equal(t, 2, len(requests))
+	assert.Equal(t, 200, requests[0].ResponseCode)
+	assert.Nil(t, requests[0].Token)
+	assert.Equal(t, "192.168.0.1", *requests[0].IP)
+	assert.Contains(t, requests[0].RequestBody, token.App.UID)
+	assert.Equal(t, 401, requests[1].ResponseCode)
+	assert.Equal(t, token.UID, *requests[1].Token)
+}
+
+func TestDriver_Record(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	tempDir := models.NewTempDirForTest()
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	driver := &Driver{
+		db:            trx,
+		conn:          newConnWithIP(tokenPrefix+token.UID, "192.168.0.1"),
+		rootChunkPath: &tempDir,
+		protocol:      protocol,
+	}
+
+	_, err = driver.PutFile("/file.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Nil(t, err)
+	_, err = driver.PutFile("/file.bytes", bytes.NewReader(models.Random(10)), true)
+	assert.Nil(t, err)
+	_, rc, err := driver.GetFile("/file.bytes", 2)
+	assert.Nil(t, err)
+	_, err = ioutil.ReadAll(rc)
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	_, _, err = driver.GetFile("/not-exist.bytes", 0)
+	assert.NotNil(t, err)
+	assert.Nil(t, driver.MakeDir("/dir"))
+	assert.Nil(t, driver.Rename("/file.bytes", "/dir/file.bytes"))
+	assert.Nil(t, driver.DeleteFile("/dir/file.bytes"))
+	assert.Nil(t, driver.DeleteDir("/dir"))
+
+	stor := findRequestsForTest(t, &token.App, "STOR", trx)
+	assert.Equal(t, 1, len(stor))
+	assert.Equal(t, token.UID, *stor[0].Token)
+	assert.Equal(t, "192.168.0.1", *stor[0].IP)
+	assert.Equal(t, "/file.bytes", *stor[0].Service)
+	assert.Contains(t, stor[0].RequestBody, `"bytes":22`)
+	appe := findRequestsForTest(t, &token.App, "APPE", trx)
+	assert.Equal(t, 1, len(appe))
+	assert.Contains(t, appe[0].RequestBody, `"bytes":10`)
+
+	retr := findRequestsForTest(t, &token.App, "RETR", trx)
+	assert.Equal(t, 2, len(retr))
+	assert.Equal(t, 200, retr[0].ResponseCode)
+	assert.Contains(t, retr[0].RequestBody, `"bytes":30`)
+	assert.Equal(t, 404, retr[1].ResponseCode)
+
+	rename := findRequestsForTest(t, &token.App, "RNTO", trx)
+	assert.Equal(t, 1, len(rename))
+	assert.Contains(t, rename[0].RequestBody, `"to":"/dir/file.bytes"`)
+	assert.Equal(t, 1, len(findRequestsForTest(t, &token.App, "MKD", trx)))
+	assert.Equal(t, 1, len(findRequestsForTest(t, &token.App, "DELE", trx)))
+	assert.Equal(t, 1, len(findRequestsForTest(t, &token.App, "RMD", trx)))
+}
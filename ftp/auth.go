@@ -11,6 +11,7 @@ import (
 
 	"github.com/bigfile/bigfile/databases"
 	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
 	"github.com/jinzhu/gorm"
 )
 
@@ -53,6 +54,9 @@ func (a *Auth) CheckPasswd(name, password string) (correct bool, err error) {
 		if token.Secret != nil && password != *token.Secret {
 			return correct, ErrTokenPassword
 		}
+		if err = service.ValidateToken(db, nil, true, token); err != nil {
+			return false, err
+		}
 		return true, nil
 	}
 	if err = db.Where("uid = ? and secret = ?", name, password).First(app).Error; err != nil {
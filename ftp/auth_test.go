@@ -6,8 +6,10 @@ package ftp
 
 import (
 	"testing"
+	"time"
 
 	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,3 +47,15 @@ func TestAuth_CheckPasswd2(t *testing.T) {
 	_, err := (&Auth{}).CheckPasswd(tokenPrefix, "")
 	assert.Equal(t, ErrTokenNotFound, err)
 }
+
+func TestAuth_CheckPasswdWithInvalidToken(t *testing.T) {
+	expiredAt := time.Now().Add(-time.Hour)
+	token, trx, down, err := models.NewTokenForTest(nil, t, "/", &expiredAt, nil, nil, -1, 0)
+	assert.Nil(t, err)
+	defer down(t)
+	testDbConn = trx
+	defer func() { testDbConn = nil }()
+
+	_, err = (&Auth{}).CheckPasswd(tokenPrefix+token.UID, "")
+	assert.Equal(t, service.ErrTokenExpired, err)
+}
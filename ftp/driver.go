@@ -5,41 +5,102 @@
 package ftp
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
+	"reflect"
 	"strings"
+	"unsafe"
 
 	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/service"
 	"github.com/jinzhu/gorm"
 	"goftp.io/server"
 )
 
+// listLimit is the number of files that are listed by DirectoryList at a time
+const listLimit = 20
+
 var appRootPath = "/"
 
-// Driver is used to operate files
+// Driver is used to operate files. The sessions of app can access all files
+// of app, but the operations of token sessions are executed by the services,
+// so that the policy of token is enforced, just like http and rpc.
 type Driver struct {
 	db            *gorm.DB
 	app           *models.App
+	token         *models.Token
+	ip            *string
 	conn          *server.Conn
 	rootPath      *string
 	rootDir       *models.File
 	rootChunkPath *string
+	owner         string
+	protocol      string
+	audit         *AuditLogger
+	loginUser     string
+	lastCommand   string
+}
+
+// NewDriver return a driver of the session that has logged in, token is nil
+// when logging in with app, ip is the address of client. It's used by the other
+// protocols that share the semantics of ftp, such as sftp.
+func NewDriver(
+	app *models.App, token *models.Token, ip, rootChunkPath *string, db *gorm.DB) (driver *Driver, err error) {
+	driver = &Driver{db: db, app: app, token: token, ip: ip, rootChunkPath: rootChunkPath}
+	if token != nil {
+		driver.rootPath, driver.owner = &token.Path, token.UID
+		driver.rootDir, err = models.CreateOrGetLastDirectory(app, token.Path, db)
+	} else {
+		driver.rootPath, driver.owner = &appRootPath, app.UID
+		driver.rootDir, err = models.CreateOrGetRootPath(app, db)
+	}
+	return driver, err
 }
 
 // Init is a hook, when new connection coming, it will be called
 func (d *Driver) Init(conn *server.Conn) {
 	d.conn = conn
+	if d.audit != nil {
+		d.audit.register(conn, d)
+	}
+}
+
+// connIP return the ip of ftp client, goftp doesn't expose the connection of
+// client, so it's read by reflection. nil is returned if it's unknown.
+func connIP(conn *server.Conn) *string {
+	if conn == nil {
+		return nil
+	}
+	field := reflect.ValueOf(conn).Elem().FieldByName("conn")
+	if !field.IsValid() || field.Type() != reflect.TypeOf((*net.Conn)(nil)).Elem() {
+		return nil
+	}
+	netConn := *(*net.Conn)(unsafe.Pointer(field.UnsafeAddr()))
+	if netConn == nil || netConn.RemoteAddr() == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(netConn.RemoteAddr().String())
+	if err != nil {
+		return nil
+	}
+	return &host
 }
 
 // buildPath is used to build the real
 func (d *Driver) buildPath(path string) string {
 	if d.app == nil && d.rootPath == nil {
 		loginUserName := d.conn.LoginUser()
+		d.ip = connIP(d.conn)
 		if strings.HasPrefix(loginUserName, tokenPrefix) {
 			tokenUID := strings.TrimPrefix(loginUserName, tokenPrefix)
 			token, _ := models.FindTokenByUID(tokenUID, d.db)
 			d.app = &token.App
+			d.token = token
 			d.rootPath = &token.Path
 			d.rootDir, _ = models.CreateOrGetLastDirectory(d.app, token.Path, d.db)
 		} else {
@@ -58,12 +119,116 @@ func (d *Driver) buildPath(path string) string {
 	)
 }
 
+// lockOwner represent the owner of file locks in this session, it's the uid
+// of token when logging in with token, otherwise, it's the uid of app.
+func (d *Driver) lockOwner() string {
+	if d.owner != "" {
+		return d.owner
+	}
+	if d.conn != nil && d.conn.LoginUser() != "" {
+		return strings.TrimPrefix(d.conn.LoginUser(), tokenPrefix)
+	}
+	return d.app.UID
+}
+
+// validateToken is used to validate the token of session for the operations
+// that aren't executed by services. The ip restriction can't be skipped when
+// the ip of client is unknown.
+func (d *Driver) validateToken(canReadOnly bool) error {
+	if d.token.IP != nil && d.ip == nil {
+		return service.ErrTokenIP
+	}
+	return service.ValidateToken(d.db, d.ip, canReadOnly, d.token)
+}
+
+// execute is used to validate and execute service, the exception of the first
+// validate error is returned if it exists
+func (d *Driver) execute(s service.Service) (interface{}, error) {
+	if d.token != nil && d.token.IP != nil && d.ip == nil {
+		return nil, service.ErrTokenIP
+	}
+	if _, err := service.DefaultRateLimiter().AllowRequest(d.app.ID, d.tokenID()); err != nil {
+		return nil, err
+	}
+	if validateErrors := s.Validate(); len(validateErrors) > 0 {
+		if validateErrors[0].Exception != nil {
+			return nil, validateErrors[0].Exception
+		}
+		return nil, validateErrors
+	}
+	return s.Execute(context.Background())
+}
+
+// tokenID return the id of token of session, it's zero when logging in with app
+func (d *Driver) tokenID() uint64 {
+	if d.token != nil {
+		return d.token.ID
+	}
+	return 0
+}
+
+// allowRequest is used to take a request of the session that has logged in
+// with app from the rate limits, the services are limited by execute
+func (d *Driver) allowRequest() error {
+	_, err := service.DefaultRateLimiter().AllowRequest(d.app.ID, 0)
+	return err
+}
+
+// baseService return the base of the services that are executed by driver
+func (d *Driver) baseService() service.BaseService {
+	return service.BaseService{DB: d.db, RootPath: d.rootChunkPath}
+}
+
+// transaction is used to execute the operation of the session that has logged
+// in with app in a transaction, it's committed only if fn returns nil. The
+// journal sequence of app is locked first.
+func (d *Driver) transaction(fn func(db *gorm.DB) error) (err error) {
+	if util.InTransaction(d.db) {
+		if err = models.LockJournalSequence(d.app.ID, d.db); err != nil {
+			return err
+		}
+		return fn(d.db)
+	}
+	trx := d.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	defer func() {
+		if reErr := recover(); reErr != nil {
+			trx.Rollback()
+			panic(reErr)
+		}
+	}()
+	if err = models.LockJournalSequence(d.app.ID, trx); err == nil {
+		err = fn(trx)
+	}
+	if err != nil {
+		trx.Rollback()
+		return err
+	}
+	if err = trx.Commit().Error; err == nil {
+		models.NotifyJournal(d.app.ID)
+	}
+	return err
+}
+
+// findFile is used to find the file by the path in session
+func (d *Driver) findFile(path string) (*models.File, error) {
+	return models.FindFileByPath(d.app, d.buildPath(path), d.db)
+}
+
 // Stat will return the information by the path
 func (d *Driver) Stat(path string) (fileInfo server.FileInfo, err error) {
 	var file *models.File
-	if file, err = models.FindFileByPath(d.app, d.buildPath(path), d.db, true); err != nil {
+	if dir, rest := virtualPath(path); dir != "" {
+		d.buildPath(path)
+		return d.virtualStat(dir, rest)
+	}
+	if file, err = d.findFile(path); err != nil {
 		return
 	}
+	if d.token != nil {
+		if err = d.validateToken(true); err != nil {
+			return
+		}
+	}
 	return &FileInfo{
 		name:     file.Name,
 		size:     int64(file.Size),
@@ -75,22 +240,92 @@ func (d *Driver) Stat(path string) (fileInfo server.FileInfo, err error) {
 // ChangeDir is used to toggle current directory, if the directory doesn't exist,
 // it will be created.
 func (d *Driver) ChangeDir(path string) (err error) {
-	_, err = models.CreateOrGetLastDirectory(d.app, d.buildPath(path), d.db)
-	return err
+	if dir, rest := virtualPath(path); dir != "" {
+		var info server.FileInfo
+		d.buildPath(path)
+		if info, err = d.virtualStat(dir, rest); err == nil && !info.IsDir() {
+			err = service.ErrListFile
+		}
+		return err
+	}
+	if d.buildPath(path); d.token == nil {
+		return d.transaction(func(db *gorm.DB) error {
+			_, err := models.CreateOrGetLastDirectory(d.app, d.buildPath(path), db)
+			return err
+		})
+	}
+	var dir *models.File
+	if dir, err = d.findFile(path); err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return err
+		}
+		return d.MakeDir(path)
+	}
+	if dir.IsDir != models.IsDir {
+		return service.ErrListFile
+	}
+	return d.validateToken(true)
 }
 
 // ListDir is used to list files and subDir of current dir
 func (d *Driver) ListDir(path string, callback func(server.FileInfo) error) (err error) {
-	var dir *models.File
-	if dir, err = models.CreateOrGetLastDirectory(d.app, d.buildPath(path), d.db); err != nil {
+	var children []models.File
+	if dir, rest := virtualPath(path); dir != "" {
+		var infos []server.FileInfo
+		d.buildPath(path)
+		if infos, err = d.virtualList(dir, rest); err != nil {
+			return
+		}
+		for _, info := range infos {
+			if err = callback(info); err != nil {
+				return
+			}
+		}
 		return
 	}
-	if err = d.db.Preload("Children", func(db *gorm.DB) *gorm.DB {
-		return db.Order("isDir DESC")
-	}).First(dir).Error; err != nil {
-		return
+	if d.buildPath(path); d.token == nil {
+		var dir *models.File
+		if err = d.transaction(func(db *gorm.DB) (err error) {
+			dir, err = models.CreateOrGetLastDirectory(d.app, d.buildPath(path), db)
+			return err
+		}); err != nil {
+			return
+		}
+		if err = d.db.Preload("Children", func(db *gorm.DB) *gorm.DB {
+			return db.Scopes(models.NotExpired).Order("isDir DESC")
+		}).First(dir).Error; err != nil {
+			return
+		}
+		children = dir.Children
+	} else {
+		for offset := 0; ; offset += listLimit {
+			var result interface{}
+			if result, err = d.execute(&service.DirectoryList{
+				BaseService: d.baseService(),
+				Token:       d.token,
+				IP:          d.ip,
+				SubDir:      path,
+				Sort:        "-type",
+				Offset:      offset,
+				Limit:       listLimit,
+			}); err != nil {
+				return
+			}
+			response := result.(*service.DirectoryListResponse)
+			children = append(children, response.Files...)
+			if len(response.Files) < listLimit || offset+listLimit >= response.Total {
+				break
+			}
+		}
 	}
-	for _, child := range dir.Children {
+	if _, rest := virtualPath(path); rest == "/" {
+		for _, name := range []string{versionsDir, trashDir} {
+			if err = callback(dirInfo(name)); err != nil {
+				return
+			}
+		}
+	}
+	for _, child := range children {
 		if err = callback(&FileInfo{
 			name: child.Name, size: int64(child.Size),
 			isDir: child.IsDir == models.IsDir, modeTime: child.UpdatedAt}); err != nil {
@@ -100,76 +335,224 @@ func (d *Driver) ListDir(path string, callback func(server.FileInfo) error) (err
 	return
 }
 
-// DeleteDir is used to delete a directory
-func (d *Driver) DeleteDir(path string) (err error) {
-	var dir *models.File
-	if dir, err = models.FindFileByPath(d.app, d.buildPath(path), d.db, true); err != nil {
+// deleteFile is used to delete a file or directory, method is the command of
+// ftp that is recorded
+func (d *Driver) deleteFile(method, path string) (err error) {
+	var (
+		file  *models.File
+		force = true
+	)
+	defer func() { d.record(method, d.buildPath(path), map[string]interface{}{}, err) }()
+	if dir, _ := virtualPath(path); dir != "" {
+		return ErrVirtualPath
+	}
+	if file, err = d.findFile(path); err != nil {
 		return
 	}
-	return dir.Delete(true, d.db)
+	if d.token != nil {
+		_, err = d.execute(&service.FileDelete{
+			BaseService: d.baseService(), Token: d.token, File: file, Force: &force, IP: d.ip})
+		return err
+	}
+	return d.transaction(func(db *gorm.DB) error {
+		if err := file.CheckLock(d.lockOwner(), db); err != nil {
+			return err
+		}
+		return file.Delete(true, db)
+	})
+}
+
+// DeleteDir is used to delete a directory
+func (d *Driver) DeleteDir(path string) (err error) {
+	return d.deleteFile("RMD", path)
 }
 
 // DeleteFile is used to delete file by the path
 func (d *Driver) DeleteFile(path string) (err error) {
-	var file *models.File
-	if file, err = models.FindFileByPath(d.app, d.buildPath(path), d.db, true); err != nil {
-		return
-	}
-	return file.Delete(true, d.db)
+	return d.deleteFile("DELE", path)
 }
 
 // Rename is used to move file or rename file
 func (d *Driver) Rename(fromPath string, toPath string) (err error) {
 	var file *models.File
-	if file, err = models.FindFileByPath(d.app, d.buildPath(fromPath), d.db, true); err != nil {
+	defer func() {
+		d.record("RNTO", d.buildPath(fromPath), map[string]interface{}{"to": d.buildPath(toPath)}, err)
+	}()
+	if dir, rest := virtualPath(fromPath); dir == trashDir && rest != "/" {
+		return d.restore(rest, toPath)
+	}
+	if fromDir, _ := virtualPath(fromPath); fromDir != "" {
+		return ErrVirtualPath
+	}
+	if toDir, _ := virtualPath(toPath); toDir != "" {
+		return ErrVirtualPath
+	}
+	if file, err = d.findFile(fromPath); err != nil {
 		return
 	}
-	return file.MoveTo(d.buildPath(toPath), d.db)
+	if d.token != nil {
+		_, err = d.execute(&service.FileUpdate{
+			BaseService: d.baseService(), Token: d.token, File: file, Path: &toPath, IP: d.ip})
+		return err
+	}
+	return d.transaction(func(db *gorm.DB) error {
+		if err := file.CheckLock(d.lockOwner(), db); err != nil {
+			return err
+		}
+		if err := models.CheckPathLock(d.app, d.buildPath(toPath), d.lockOwner(), db); err != nil {
+			return err
+		}
+		return file.MoveTo(d.buildPath(toPath), db)
+	})
 }
 
 // MakeDir is used to create dir
 func (d *Driver) MakeDir(path string) (err error) {
-	_, err = models.CreateOrGetLastDirectory(d.app, d.buildPath(path), d.db)
-	return err
+	defer func() { d.record("MKD", d.buildPath(path), map[string]interface{}{}, err) }()
+	if dir, _ := virtualPath(path); dir != "" {
+		return ErrVirtualPath
+	}
+	if d.buildPath(path); d.token != nil {
+		_, err = d.execute(&service.FileCreate{
+			BaseService: d.baseService(), Token: d.token, Path: path, IP: d.ip})
+		return err
+	}
+	return d.transaction(func(db *gorm.DB) error {
+		_, err := models.CreateOrGetLastDirectory(d.app, d.buildPath(path), db)
+		return err
+	})
 }
 
 // PutFile is used to upload file
 func (d *Driver) PutFile(path string, dataConn io.Reader, append bool) (bytes int64, err error) {
 	var (
-		file       *models.File
+		object     *models.Object
 		writeBytes int64
+		method     = "STOR"
 	)
 	if append {
-		if file, err = models.FindFileByPath(d.app, d.buildPath(path), d.db, true); err != nil {
-			return
+		method = "APPE"
+	}
+	defer func() { d.record(method, d.buildPath(path), map[string]interface{}{"bytes": bytes}, err) }()
+	if dir, _ := virtualPath(path); dir != "" {
+		return 0, ErrVirtualPath
+	}
+	if d.buildPath(path); d.token != nil {
+		return d.putFileWithToken(path, dataConn, append)
+	}
+	if err = d.allowRequest(); err != nil {
+		return
+	}
+	// the upload is stored before the transaction, so that the journal
+	// sequence isn't locked while it's streaming
+	dataConn = service.DefaultRateLimiter().ThrottleReader(dataConn, d.app.ID, 0)
+	if object, err = models.CreateObjectFromReader(dataConn, d.rootChunkPath, d.db); err != nil {
+		return
+	}
+	err = d.transaction(func(db *gorm.DB) error {
+		if err := models.CheckPathLock(d.app, d.buildPath(path), d.lockOwner(), db); err != nil {
+			return err
+		}
+		if !append {
+			file, err := models.CreateFileFromObject(d.app, d.buildPath(path), object, 0, db)
+			if err != nil {
+				return err
+			}
+			writeBytes = int64(file.Size)
+			return nil
+		}
+		file, err := models.FindFileByPath(d.app, d.buildPath(path), db)
+		if err != nil {
+			return err
+		}
+		reader, err := object.Reader(d.rootChunkPath, db)
+		if err != nil {
+			return err
 		}
 		originSize := file.Size
-		if err = file.AppendFromReader(dataConn, 0, d.rootChunkPath, d.db); err != nil {
-			return
+		if err = file.AppendFromReader(reader, 0, d.rootChunkPath, db); err != nil {
+			return err
 		}
 		writeBytes = int64(file.Size - originSize)
-	} else {
-		if file, err = models.CreateFileFromReader(
-			d.app, d.buildPath(path), dataConn, 0, d.rootChunkPath, d.db); err != nil {
-			return
+		return nil
+	})
+	return writeBytes, err
+}
+
+// putFileWithToken is used to upload file by FileCreate service
+func (d *Driver) putFileWithToken(path string, dataConn io.Reader, append bool) (bytes int64, err error) {
+	var (
+		result     interface{}
+		originSize int
+		fileCreate = &service.FileCreate{
+			BaseService: d.baseService(), Token: d.token, Path: path, Reader: dataConn, IP: d.ip}
+	)
+	if append {
+		fileCreate.Append = 1
+		if file, err := d.findFile(path); err == nil {
+			originSize = file.Size
 		}
-		writeBytes = int64(file.Size)
 	}
-	return writeBytes, nil
+	if result, err = d.execute(fileCreate); err != nil {
+		return
+	}
+	return int64(result.(*models.File).Size - originSize), nil
 }
 
-// GetFile is used to download a file
-func (d *Driver) GetFile(path string, offset int64) (size int64, rc io.ReadCloser, err error) {
+// readFile is used to open the file by path
+func (d *Driver) readFile(path string) (size int64, rs io.ReadSeeker, err error) {
 	var (
-		rs   io.ReadSeeker
-		file *models.File
+		file    *models.File
+		expired bool
+		result  interface{}
 	)
-	if file, err = models.FindFileByPath(d.app, path, d.db, true); err != nil {
+	if file, err = d.findFile(path); err != nil {
+		return
+	}
+	if d.token != nil {
+		if result, err = d.execute(&service.FileRead{
+			BaseService: d.baseService(), Token: d.token, File: file, IP: d.ip}); err != nil {
+			return
+		}
+		return int64(file.Size), result.(io.ReadSeeker), nil
+	}
+	if expired, err = file.IsExpired(d.db); err != nil {
+		return
+	}
+	if expired {
+		return 0, nil, models.ErrFileExpired
+	}
+	if err = d.allowRequest(); err != nil {
 		return
 	}
 	if rs, err = file.Reader(d.rootChunkPath, d.db); err != nil {
 		return
 	}
-	_, err = rs.Seek(offset, io.SeekStart)
-	return int64(file.Size), ioutil.NopCloser(rs), err
+	return int64(file.Size), service.DefaultRateLimiter().ThrottleReadSeeker(rs, d.app.ID, 0), nil
+}
+
+// GetFile is used to download a file, the versions and deleted files can also
+// be downloaded from the virtual directories
+func (d *Driver) GetFile(path string, offset int64) (size int64, rc io.ReadCloser, err error) {
+	var rs io.ReadSeeker
+	defer func() {
+		if err != nil {
+			d.record("RETR", d.buildPath(path), map[string]interface{}{"offset": offset, "bytes": 0}, err)
+		}
+	}()
+	if dir, rest := virtualPath(path); dir != "" {
+		d.buildPath(path)
+		size, rs, err = d.readVirtual(dir, rest)
+	} else {
+		size, rs, err = d.readFile(path)
+	}
+	if err != nil {
+		return
+	}
+	if _, err = rs.Seek(offset, io.SeekStart); err != nil {
+		return
+	}
+	return size, &recordReadCloser{ReadCloser: ioutil.NopCloser(rs), close: func(bytes int64, err error) {
+		d.record("RETR", d.buildPath(path), map[string]interface{}{"offset": offset, "bytes": bytes}, err)
+	}}, nil
 }
@@ -7,15 +7,19 @@ package ftp
 import (
 	"bytes"
 	"io/ioutil"
+	"net"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 	"unsafe"
 
+	"github.com/bigfile/bigfile/config"
 	"github.com/bigfile/bigfile/databases/models"
 	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/service"
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
 	"goftp.io/server"
@@ -48,6 +52,40 @@ func TestDriverBuildPath(t *testing.T) {
 	assert.Equal(t, "/save/to", driver.buildPath("/save/to"))
 }
 
+func TestNewDriver(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	tempDir := models.NewTempDirForTest()
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	driver, err := NewDriver(&token.App, nil, nil, &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, "/", driver.rootDir.FullPath)
+	assert.Equal(t, token.App.UID, driver.lockOwner())
+	assert.Equal(t, "/save/to", driver.buildPath("/save/to"))
+
+	assert.Nil(t, trx.Model(token).Update("path", "/test").Error)
+	driver, err = NewDriver(&token.App, token, nil, &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, "/test", driver.rootDir.FullPath)
+	assert.Equal(t, token.UID, driver.lockOwner())
+	assert.Equal(t, "/test/save/to", driver.buildPath("/save/to"))
+
+	// files are read in the scope of token
+	_, err = driver.PutFile("/file.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Nil(t, err)
+	size, _, err := driver.GetFile("/file.bytes", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(22), size)
+	_, err = models.FindFileByPath(&token.App, "/test/file.bytes", trx)
+	assert.Nil(t, err)
+}
+
 func newDriverForTest(t *testing.T) (driver *Driver, down func(*testing.T), err error) {
 	app, trx, down, err := models.NewAppForTest(nil, t)
 	assert.Nil(t, err)
@@ -221,6 +259,70 @@ func TestDriver_PutFile(t *testing.T) {
 	assert.Equal(t, int64(22), writeBytes)
 }
 
+func TestDriver_FileLock(t *testing.T) {
+	driver, down, err := newDriverForTest(t)
+	assert.Nil(t, err)
+	tempDir := models.NewTempDirForTest()
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	driver.rootChunkPath = &tempDir
+
+	file, err := models.CreateFileFromReader(
+		driver.app, "/create/dir/file.bytes", strings.NewReader(""), models.Hidden, &tempDir, driver.db)
+	assert.Nil(t, err)
+	lock, err := models.AcquireFileLock(file.Parent, "another owner", true, time.Minute, driver.db)
+	assert.Nil(t, err)
+
+	_, err = driver.PutFile("/create/dir/file.bytes", bytes.NewReader(models.Random(22)), true)
+	assert.Equal(t, models.ErrFileLocked, err)
+	_, err = driver.PutFile("/create/dir/random.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Equal(t, models.ErrFileLocked, err)
+	assert.Equal(t, models.ErrFileLocked, driver.Rename("/create/dir/file.bytes", "/create/file.bytes"))
+	assert.Equal(t, models.ErrFileLocked, driver.DeleteFile("/create/dir/file.bytes"))
+	assert.Equal(t, models.ErrFileLocked, driver.DeleteDir("/create"))
+
+	// the lock held by the app itself doesn't forbid anything
+	assert.Nil(t, lock.Release("another owner", driver.db))
+	_, err = models.AcquireFileLock(file.Parent, driver.app.UID, true, time.Minute, driver.db)
+	assert.Nil(t, err)
+	assert.Nil(t, driver.DeleteFile("/create/dir/file.bytes"))
+}
+
+func TestDriver_Retention(t *testing.T) {
+	driver, down, err := newDriverForTest(t)
+	assert.Nil(t, err)
+	tempDir := models.NewTempDirForTest()
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	driver.rootChunkPath = &tempDir
+
+	_, err = models.CreateFileFromReader(
+		driver.app, "/worm/dir/file.bytes", strings.NewReader("worm"), int8(0), &tempDir, driver.db)
+	assert.Nil(t, err)
+	dir, err := models.FindFileByPath(driver.app, "/worm", driver.db)
+	assert.Nil(t, err)
+	retainUntil := time.Now().Add(time.Hour)
+	assert.Nil(t, dir.SetRetention(models.RetentionCompliance, &retainUntil, false, driver.db))
+
+	_, err = driver.PutFile("/worm/dir/file.bytes", bytes.NewReader(models.Random(22)), true)
+	assert.Equal(t, models.ErrFileUnderRetention, err)
+	assert.Equal(t, models.ErrFileUnderRetention, driver.Rename("/worm/dir/file.bytes", "/file.bytes"))
+	assert.Equal(t, models.ErrFileUnderRetention, driver.DeleteFile("/worm/dir/file.bytes"))
+	assert.Equal(t, models.ErrFileUnderRetention, driver.DeleteDir("/worm"))
+
+	// new files are still allowed to be written into the directory
+	_, err = driver.PutFile("/worm/dir/another.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Nil(t, err)
+}
+
 func TestDriver_GetFile(t *testing.T) {
 	driver, down, err := newDriverForTest(t)
 	assert.Nil(t, err)
@@ -249,3 +351,160 @@ func TestDriver_GetFile(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, randomBytesHash, allContentHash)
 }
+
+// remoteConn is a fake connection of ftp client with remote address
+type remoteConn struct {
+	net.Conn
+	addr net.Addr
+}
+
+func (c *remoteConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+func newConnWithIP(user, ip string) *server.Conn {
+	conn := newConn(user)
+	*(*net.Conn)(unsafe.Pointer(reflect.ValueOf(conn).Elem().FieldByName("conn").UnsafeAddr())) = &remoteConn{addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 21}}
+	return conn
+}
+
+func TestConnIP(t *testing.T) {
+	assert.Nil(t, connIP(nil))
+	assert.Nil(t, connIP(newConn("hello")))
+	assert.Equal(t, "192.168.0.1", *connIP(newConnWithIP("hello", "192.168.0.1")))
+}
+
+func newTokenDriverForTest(
+	t *testing.T, expiredAt *time.Time, ip *string, availableTimes int, readOnly int8,
+) (*Driver, *models.Token, func()) {
+	token, trx, down, err := models.NewTokenForTest(nil, t, "/scope", expiredAt, ip, nil, availableTimes, readOnly)
+	assert.Nil(t, err)
+	tempDir := models.NewTempDirForTest()
+	driver := &Driver{db: trx, conn: newConnWithIP(tokenPrefix+token.UID, "192.168.0.1"), rootChunkPath: &tempDir}
+	return driver, token, func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}
+}
+
+func TestDriver_TokenReadOnly(t *testing.T) {
+	driver, token, down := newTokenDriverForTest(t, nil, nil, -1, 1)
+	defer down()
+	_, err := models.CreateFileFromReader(
+		&token.App, "/scope/file.bytes", bytes.NewReader(models.Random(22)), 0, driver.rootChunkPath, driver.db)
+	assert.Nil(t, err)
+
+	_, rc, err := driver.GetFile("/file.bytes", 0)
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	_, err = driver.Stat("/file.bytes")
+	assert.Nil(t, err)
+	assert.Nil(t, driver.ListDir("/", func(server.FileInfo) error { return nil }))
+
+	_, err = driver.PutFile("/new.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Equal(t, service.ErrTokenReadOnly, err)
+	assert.Equal(t, service.ErrTokenReadOnly, driver.MakeDir("/dir"))
+	assert.Equal(t, service.ErrTokenReadOnly, driver.Rename("/file.bytes", "/renamed.bytes"))
+	assert.Equal(t, service.ErrTokenReadOnly, driver.DeleteFile("/file.bytes"))
+}
+
+func TestDriver_TokenExpired(t *testing.T) {
+	expiredAt := time.Now().Add(-time.Hour)
+	driver, _, down := newTokenDriverForTest(t, &expiredAt, nil, -1, 0)
+	defer down()
+
+	_, err := driver.Stat("/")
+	assert.Equal(t, service.ErrTokenExpired, err)
+	assert.Equal(t, service.ErrTokenExpired, driver.ListDir("/", func(server.FileInfo) error { return nil }))
+	_, err = driver.PutFile("/file.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Equal(t, service.ErrTokenExpired, err)
+}
+
+func TestDriver_TokenAvailableTimes(t *testing.T) {
+	driver, _, down := newTokenDriverForTest(t, nil, nil, 2, 0)
+	defer down()
+
+	_, err := driver.PutFile("/file.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Nil(t, err)
+	_, rc, err := driver.GetFile("/file.bytes", 0)
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	_, _, err = driver.GetFile("/file.bytes", 0)
+	assert.Equal(t, service.ErrTokenAvailableTimesExhausted, err)
+}
+
+func TestDriver_RateLimit(t *testing.T) {
+	defer func(limit config.Limit) { config.DefaultConfig.Limit = limit }(config.DefaultConfig.Limit)
+	config.DefaultConfig.Limit = config.Limit{LimitTokenRequests: 1}
+
+	driver, _, down := newTokenDriverForTest(t, nil, nil, -1, 0)
+	defer down()
+
+	_, err := driver.PutFile("/file.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Nil(t, err)
+	_, _, err = driver.GetFile("/file.bytes", 0)
+	assert.Equal(t, service.ErrTooManyRequests, err)
+}
+
+func TestDriver_TokenIP(t *testing.T) {
+	ip := "10.0.0.1"
+	driver, _, down := newTokenDriverForTest(t, nil, &ip, -1, 0)
+	defer down()
+
+	_, err := driver.PutFile("/file.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Equal(t, service.ErrTokenIP, err)
+
+	// the ip restriction can't be skipped when the ip of client is unknown
+	driver.conn, driver.app, driver.rootPath = newConn(driver.conn.LoginUser()), nil, nil
+	_, err = driver.Stat("/")
+	assert.Equal(t, service.ErrTokenIP, err)
+
+	driver.conn, driver.app, driver.rootPath = newConnWithIP(driver.conn.LoginUser(), ip), nil, nil
+	_, err = driver.PutFile("/file.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Nil(t, err)
+}
+
+func TestDriver_TokenHiddenFile(t *testing.T) {
+	driver, token, down := newTokenDriverForTest(t, nil, nil, -1, 0)
+	defer down()
+	_, err := models.CreateFileFromReader(
+		&token.App, "/scope/hidden.bytes", bytes.NewReader(models.Random(22)), models.Hidden,
+		driver.rootChunkPath, driver.db)
+	assert.Nil(t, err)
+
+	_, _, err = driver.GetFile("/hidden.bytes", 0)
+	assert.Equal(t, service.ErrReadHiddenFile, err)
+}
+
+func TestDriver_TokenScope(t *testing.T) {
+	driver, token, down := newTokenDriverForTest(t, nil, nil, -1, 0)
+	defer down()
+	_, err := models.CreateFileFromReader(
+		&token.App, "/outside.bytes", bytes.NewReader(models.Random(22)), 0, driver.rootChunkPath, driver.db)
+	assert.Nil(t, err)
+
+	_, _, err = driver.GetFile("/../outside.bytes", 0)
+	assert.NotNil(t, err)
+	assert.NotNil(t, driver.Rename("/../outside.bytes", "/inside.bytes"))
+
+	assert.Nil(t, driver.MakeDir("/dir"))
+	_, err = driver.PutFile("/dir/file.bytes", bytes.NewReader(models.Random(22)), false)
+	assert.Nil(t, err)
+	written, err := driver.PutFile("/dir/file.bytes", bytes.NewReader(models.Random(10)), true)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), written)
+	var names []string
+	assert.Nil(t, driver.ListDir("/", func(info server.FileInfo) error {
+		names = append(names, info.Name())
+		return nil
+	}))
+	assert.Equal(t, []string{versionsDir, trashDir, "dir"}, names)
+	assert.Nil(t, driver.Rename("/dir/file.bytes", "/dir/renamed.bytes"))
+	_, err = models.FindFileByPath(&token.App, "/scope/dir/renamed.bytes", driver.db)
+	assert.Nil(t, err)
+	assert.Nil(t, driver.DeleteDir("/dir"))
+	_, err = driver.Stat("/dir")
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
@@ -9,10 +9,13 @@ import (
 	"goftp.io/server"
 )
 
-// Factory is a driver factory, is used to generate driver when new connection comes
-type Factory struct{}
+// Factory is a driver factory, is used to generate driver when new connection comes.
+// Logger should be the logger of server, the logins are recorded by it.
+type Factory struct {
+	Logger *AuditLogger
+}
 
 // NewDriver return a driver
 func (factory *Factory) NewDriver() (server.Driver, error) {
-	return &Driver{db: databases.MustNewConnection(nil)}, nil
+	return &Driver{db: databases.MustNewConnection(nil), protocol: protocol, audit: factory.Logger}, nil
 }
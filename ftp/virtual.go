@@ -0,0 +1,323 @@
+//   Copyright 2019 The bigfile Authors. All rights reserved.
+//   Use of this source code is governed by a MIT-style
+//   license that can be found in the LICENSE file.
+
+package ftp
+
+import (
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/jinzhu/gorm"
+	"goftp.io/server"
+)
+
+const (
+	// versionsDir is the virtual directory that contains the previous versions
+	// of files, the versions of /a/b.txt are listed in /.versions/a/b.txt
+	versionsDir = ".versions"
+
+	// trashDir is the virtual directory that contains the deleted files, they
+	// are kept in the original directories, and restored by moving out of it
+	trashDir = ".trash"
+
+	// versionTimeLayout is the layout of time in the names of versions, the
+	// colons are avoided, because they are illegal in the names of windows
+	versionTimeLayout = "2006-01-02T15-04-05.000000Z"
+
+	// trashLimit is the max number of deleted files in the trash
+	trashLimit = 1000
+)
+
+// ErrVirtualPath represent that the virtual directory is being modified
+var ErrVirtualPath = errors.New("the virtual directory is read-only")
+
+// virtualPath split path into the virtual directory and the path in it, dir is
+// empty if path isn't in any virtual directory. The virtual directories only
+// exist in the root of session.
+func virtualPath(p string) (dir, rest string) {
+	p = path.Clean("/" + p)
+	for _, dir = range []string{versionsDir, trashDir} {
+		if p == "/"+dir {
+			return dir, "/"
+		}
+		if strings.HasPrefix(p, "/"+dir+"/") {
+			return dir, strings.TrimPrefix(p, "/"+dir)
+		}
+	}
+	return "", p
+}
+
+// versionName return the name of version in the versions directory of file
+func versionName(file *models.File, history *models.History) string {
+	return history.CreatedAt.UTC().Format(versionTimeLayout) + "_" + file.Name
+}
+
+// dirInfo return the information of a virtual directory
+func dirInfo(name string) server.FileInfo {
+	return &FileInfo{name: name, isDir: true}
+}
+
+// validateVirtual is used to validate the token of session before accessing the
+// virtual directories, readOnly represent whether the access is read-only
+func (d *Driver) validateVirtual(readOnly bool) error {
+	if d.token == nil {
+		return nil
+	}
+	return d.validateToken(readOnly)
+}
+
+// versionFile is used to find the file whose versions are in the path of the
+// versions directory, rest is the path in it
+func (d *Driver) versionFile(rest string) (*models.File, error) {
+	file, err := d.findFile(rest)
+	if err != nil {
+		return nil, err
+	}
+	if d.token != nil && file.Hidden == models.Hidden {
+		return nil, service.ErrReadHiddenFile
+	}
+	return file, nil
+}
+
+// findVersion is used to find the version by the path in the versions directory
+func (d *Driver) findVersion(rest string) (*models.File, *models.History, error) {
+	var (
+		file      *models.File
+		histories []models.History
+		err       error
+	)
+	if file, err = d.versionFile(path.Dir(rest)); err != nil {
+		return nil, nil, err
+	}
+	if histories, err = file.FindHistories(d.db); err != nil {
+		return nil, nil, err
+	}
+	for index := range histories {
+		if versionName(file, &histories[index]) == path.Base(rest) {
+			return file, &histories[index], nil
+		}
+	}
+	return nil, nil, gorm.ErrRecordNotFound
+}
+
+// listVersions is used to list the versions directory. The directories are
+// mirrored, and every file is shown as a directory that contains its versions.
+func (d *Driver) listVersions(rest string) (infos []server.FileInfo, err error) {
+	var (
+		file      *models.File
+		histories []models.History
+	)
+	if file, err = d.versionFile(rest); err != nil {
+		return nil, err
+	}
+	if file.IsDir == models.IsDir {
+		if err = d.db.Preload("Children", func(db *gorm.DB) *gorm.DB {
+			return db.Scopes(models.NotExpired).Order("isDir DESC")
+		}).First(file).Error; err != nil {
+			return nil, err
+		}
+		for _, child := range file.Children {
+			if d.token == nil || child.Hidden != models.Hidden {
+				infos = append(infos, &FileInfo{name: child.Name, isDir: true, modeTime: child.UpdatedAt})
+			}
+		}
+		return infos, nil
+	}
+	if histories, err = file.FindHistories(d.db); err != nil {
+		return nil, err
+	}
+	for index, history := range histories {
+		infos = append(infos, &FileInfo{
+			name:     versionName(file, &histories[index]),
+			size:     int64(history.Object.Size),
+			modeTime: history.CreatedAt,
+		})
+	}
+	return infos, nil
+}
+
+// trashedFiles return the deleted files in the scope of session by the path
+// relative to the root of session, only the latest one is kept if several
+// files are deleted from the same path
+func (d *Driver) trashedFiles() (files map[string]*models.File, err error) {
+	var (
+		trashed []models.File
+		root    = strings.TrimSuffix(*d.rootPath, "/")
+	)
+	if trashed, err = models.FindTrashedFiles(d.app, *d.rootPath, trashLimit, d.db); err != nil {
+		return nil, err
+	}
+	files = make(map[string]*models.File)
+	for index := range trashed {
+		rel := strings.TrimPrefix(trashed[index].FullPath, root)
+		if _, ok := files[rel]; !ok && (d.token == nil || trashed[index].Hidden != models.Hidden) {
+			files[rel] = &trashed[index]
+		}
+	}
+	return files, nil
+}
+
+// listTrash is used to list the trash, the deleted files are kept in their
+// original directories
+func (d *Driver) listTrash(rest string) (infos []server.FileInfo, err error) {
+	var (
+		files  map[string]*models.File
+		dirs   = make(map[string]bool)
+		prefix = strings.TrimSuffix(rest, "/") + "/"
+	)
+	if files, err = d.trashedFiles(); err != nil {
+		return nil, err
+	}
+	for rel, file := range files {
+		if !strings.HasPrefix(rel, prefix) {
+			continue
+		}
+		if name := strings.TrimPrefix(rel, prefix); strings.Contains(name, "/") {
+			dirs[name[:strings.Index(name, "/")]] = true
+		} else {
+			infos = append(infos, &FileInfo{name: name, size: int64(file.Size), modeTime: *file.DeletedAt})
+		}
+	}
+	for name := range dirs {
+		infos = append(infos, dirInfo(name))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].IsDir() != infos[j].IsDir() {
+			return infos[i].IsDir()
+		}
+		return infos[i].Name() < infos[j].Name()
+	})
+	if len(infos) == 0 && rest != "/" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return infos, nil
+}
+
+// virtualStat return the information of path in the virtual directory
+func (d *Driver) virtualStat(dir, rest string) (server.FileInfo, error) {
+	if err := d.validateVirtual(true); err != nil {
+		return nil, err
+	}
+	if rest == "/" {
+		return dirInfo(dir), nil
+	}
+	if dir == versionsDir {
+		if file, err := d.versionFile(rest); err == nil {
+			return &FileInfo{name: file.Name, isDir: true, modeTime: file.UpdatedAt}, nil
+		}
+		file, history, err := d.findVersion(rest)
+		if err != nil {
+			return nil, err
+		}
+		return &FileInfo{
+			name:     versionName(file, history),
+			size:     int64(history.Object.Size),
+			modeTime: history.CreatedAt,
+		}, nil
+	}
+	files, err := d.trashedFiles()
+	if err != nil {
+		return nil, err
+	}
+	if file, ok := files[rest]; ok {
+		return &FileInfo{name: file.Name, size: int64(file.Size), modeTime: *file.DeletedAt}, nil
+	}
+	for rel := range files {
+		if strings.HasPrefix(rel, rest+"/") {
+			return dirInfo(path.Base(rest)), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// virtualList is used to list the path in the virtual directory
+func (d *Driver) virtualList(dir, rest string) ([]server.FileInfo, error) {
+	if err := d.validateVirtual(true); err != nil {
+		return nil, err
+	}
+	if dir == versionsDir {
+		return d.listVersions(rest)
+	}
+	return d.listTrash(rest)
+}
+
+// readVirtual is used to open the version in the versions directory or the
+// deleted file in the trash, the available times of token is consumed like
+// reading a file
+func (d *Driver) readVirtual(dir, rest string) (size int64, rs io.ReadSeeker, err error) {
+	var (
+		object  *models.Object
+		history *models.History
+		files   map[string]*models.File
+		file    *models.File
+		ok      bool
+	)
+	if err = d.validateVirtual(true); err != nil {
+		return
+	}
+	if dir == versionsDir {
+		if _, history, err = d.findVersion(rest); err != nil {
+			return
+		}
+		object = &history.Object
+	} else {
+		if files, err = d.trashedFiles(); err != nil {
+			return
+		}
+		if file, ok = files[rest]; !ok {
+			return 0, nil, gorm.ErrRecordNotFound
+		}
+		object = &file.Object
+	}
+	if _, err = service.DefaultRateLimiter().AllowRequest(d.app.ID, d.tokenID()); err != nil {
+		return
+	}
+	if d.token != nil {
+		if err = d.token.UpdateAvailableTimes(-1, d.db); err != nil {
+			return
+		}
+	}
+	if rs, err = object.Reader(d.rootChunkPath, d.db); err != nil {
+		return
+	}
+	return int64(object.Size), service.DefaultRateLimiter().ThrottleReadSeeker(rs, d.app.ID, d.tokenID()), nil
+}
+
+// restore is used to restore the deleted file that is moved out of trash, it
+// will be moved to toPath if it isn't the original path. The original path
+// mustn't be occupied by another file.
+func (d *Driver) restore(rest, toPath string) (err error) {
+	var (
+		files    map[string]*models.File
+		file     *models.File
+		ok       bool
+		toDir, _ = virtualPath(toPath)
+	)
+	if toDir != "" {
+		return ErrVirtualPath
+	}
+	if err = d.validateVirtual(false); err != nil {
+		return
+	}
+	if files, err = d.trashedFiles(); err != nil {
+		return
+	}
+	if file, ok = files[rest]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	restore := &service.FileRestore{BaseService: d.baseService(), File: file, IP: d.ip, Owner: d.lockOwner()}
+	if d.token != nil {
+		restore.Token, restore.Path = d.token, &toPath
+	} else {
+		targetPath := d.buildPath(toPath)
+		restore.App, restore.Path = d.app, &targetPath
+	}
+	_, err = d.execute(restore)
+	return err
+}
@@ -0,0 +1,167 @@
+//   Copyright 2019 The bigfile Authors. All rights reserved.
+//   Use of this source code is governed by a MIT-style
+//   license that can be found in the LICENSE file.
+
+package ftp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/service"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"goftp.io/server"
+)
+
+func TestVirtualPath(t *testing.T) {
+	for _, c := range []struct{ path, dir, rest string }{
+		{"/", "", "/"},
+		{"/a/b", "", "/a/b"},
+		{"/.versions", versionsDir, "/"},
+		{"/.versions/", versionsDir, "/"},
+		{"/.versions/a/b.txt", versionsDir, "/a/b.txt"},
+		{".trash/a", trashDir, "/a"},
+		{"/.trashed/a", "", "/.trashed/a"},
+		{"/a/.trash", "", "/a/.trash"},
+	} {
+		dir, rest := virtualPath(c.path)
+		assert.Equal(t, c.dir, dir, c.path)
+		assert.Equal(t, c.rest, rest, c.path)
+	}
+}
+
+func TestVersionName(t *testing.T) {
+	createdAt := time.Date(2019, 9, 18, 10, 25, 33, 123456000, time.FixedZone("CST", 8*3600))
+	assert.Equal(t,
+		"2019-09-18T02-25-33.123456Z_b.txt",
+		versionName(&models.File{Name: "b.txt"}, &models.History{CreatedAt: createdAt}),
+	)
+}
+
+func listNamesForTest(t *testing.T, driver *Driver, path string) (names []string) {
+	assert.Nil(t, driver.ListDir(path, func(info server.FileInfo) error {
+		names = append(names, info.Name())
+		return nil
+	}))
+	return names
+}
+
+func TestDriver_Versions(t *testing.T) {
+	driver, down, err := newDriverForTest(t)
+	assert.Nil(t, err)
+	tempDir := models.NewTempDirForTest()
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	driver.rootChunkPath = &tempDir
+
+	firstContent := models.Random(10)
+	file, err := models.CreateFileFromReader(
+		driver.app, "/dir/file.bytes", bytes.NewReader(firstContent), 0, &tempDir, driver.db)
+	assert.Nil(t, err)
+	assert.Nil(t, file.OverWriteFromReader(bytes.NewReader(models.Random(20)), 0, &tempDir, driver.db))
+	histories, err := file.FindHistories(driver.db)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(histories))
+	name := versionName(file, &histories[0])
+
+	assert.Equal(t, []string{versionsDir, trashDir, "dir"}, listNamesForTest(t, driver, "/"))
+	assert.Equal(t, []string{"dir"}, listNamesForTest(t, driver, "/.versions"))
+	assert.Equal(t, []string{"file.bytes"}, listNamesForTest(t, driver, "/.versions/dir"))
+	assert.Equal(t, []string{name}, listNamesForTest(t, driver, "/.versions/dir/file.bytes"))
+
+	info, err := driver.Stat("/.versions/dir/file.bytes")
+	assert.Nil(t, err)
+	assert.True(t, info.IsDir())
+	info, err = driver.Stat("/.versions/dir/file.bytes/" + name)
+	assert.Nil(t, err)
+	assert.False(t, info.IsDir())
+	assert.Equal(t, int64(10), info.Size())
+	assert.Nil(t, driver.ChangeDir("/.versions/dir/file.bytes"))
+
+	size, rc, err := driver.GetFile("/.versions/dir/file.bytes/"+name, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), size)
+	content, err := ioutil.ReadAll(rc)
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	assert.Equal(t, firstContent, content)
+	_, _, err = driver.GetFile("/.versions/dir/file.bytes/not-exist", 0)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+
+	// the virtual directory is read-only
+	_, err = driver.PutFile("/.versions/dir/file.bytes/new.bytes", bytes.NewReader(models.Random(10)), false)
+	assert.Equal(t, ErrVirtualPath, err)
+	assert.Equal(t, ErrVirtualPath, driver.MakeDir("/.versions/new"))
+	assert.Equal(t, ErrVirtualPath, driver.DeleteFile("/.versions/dir/file.bytes/"+name))
+	assert.Equal(t, ErrVirtualPath, driver.Rename("/.versions/dir/file.bytes/"+name, "/restored.bytes"))
+	assert.Equal(t, ErrVirtualPath, driver.Rename("/dir/file.bytes", "/.versions/file.bytes"))
+}
+
+func TestDriver_Trash(t *testing.T) {
+	driver, down, err := newDriverForTest(t)
+	assert.Nil(t, err)
+	tempDir := models.NewTempDirForTest()
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	driver.rootChunkPath = &tempDir
+
+	for _, p := range []string{"/dir/1.bytes", "/dir/sub/2.bytes", "/3.bytes"} {
+		_, err = models.CreateFileFromReader(driver.app, p, bytes.NewReader(models.Random(10)), 0, &tempDir, driver.db)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, driver.DeleteDir("/dir"))
+	assert.Nil(t, driver.DeleteFile("/3.bytes"))
+
+	assert.Equal(t, []string{"dir", "3.bytes"}, listNamesForTest(t, driver, "/.trash"))
+	assert.Equal(t, []string{"sub", "1.bytes"}, listNamesForTest(t, driver, "/.trash/dir"))
+	assert.NotNil(t, driver.ListDir("/.trash/not-exist", func(server.FileInfo) error { return nil }))
+	info, err := driver.Stat("/.trash/dir/sub")
+	assert.Nil(t, err)
+	assert.True(t, info.IsDir())
+	info, err = driver.Stat("/.trash/dir/sub/2.bytes")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), info.Size())
+	_, rc, err := driver.GetFile("/.trash/3.bytes", 0)
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+
+	// deleted file is restored by moving out of trash
+	assert.Nil(t, driver.Rename("/.trash/dir/1.bytes", "/dir/1.bytes"))
+	_, err = models.FindFileByPath(driver.app, "/dir/1.bytes", driver.db)
+	assert.Nil(t, err)
+	assert.Nil(t, driver.Rename("/.trash/dir/sub/2.bytes", "/restored/2.bytes"))
+	_, err = models.FindFileByPath(driver.app, "/restored/2.bytes", driver.db)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"3.bytes"}, listNamesForTest(t, driver, "/.trash"))
+	assert.Equal(t, ErrVirtualPath, driver.Rename("/.trash/3.bytes", "/.trash/4.bytes"))
+	assert.True(t, gorm.IsRecordNotFoundError(driver.Rename("/.trash/not-exist", "/not-exist")))
+}
+
+func TestDriver_TokenTrash(t *testing.T) {
+	driver, token, down := newTokenDriverForTest(t, nil, nil, -1, 1)
+	defer down()
+	for _, p := range []string{"/scope/1.bytes", "/outside.bytes"} {
+		file, err := models.CreateFileFromReader(
+			&token.App, p, bytes.NewReader(models.Random(10)), 0, driver.rootChunkPath, driver.db)
+		assert.Nil(t, err)
+		assert.Nil(t, file.Delete(true, driver.db))
+	}
+
+	// only the deleted files in the scope of token are listed
+	assert.Equal(t, []string{"1.bytes"}, listNamesForTest(t, driver, "/.trash"))
+	assert.Equal(t, service.ErrTokenReadOnly, driver.Rename("/.trash/1.bytes", "/1.bytes"))
+}
@@ -0,0 +1,83 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type changeFeedInput struct {
+	Token  string  `form:"token" binding:"required"`
+	Nonce  string  `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign   *string `form:"sign" binding:"omitempty"`
+	SubDir string  `form:"subDir,default=/" binding:"omitempty"`
+	Cursor uint64  `form:"cursor,default=0" binding:"omitempty"`
+	Limit  int     `form:"limit,default=100" binding:"omitempty,min=1,max=1000"`
+}
+
+// ChangeFeedHandler is used to fetch the changes of file tree after cursor
+func ChangeFeedHandler(ctx *gin.Context) {
+	var (
+		ip                = ctx.ClientIP()
+		db                = ctx.MustGet("db").(*gorm.DB)
+		err               error
+		token             = ctx.MustGet("token").(*models.Token)
+		input             = ctx.MustGet("inputParam").(*changeFeedInput)
+		changeFeedSrv     *service.ChangeFeed
+		changeFeedSrvResp interface{}
+
+		code     = 400
+		reErrors map[string][]string
+		success  bool
+		data     interface{}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	changeFeedSrv = &service.ChangeFeed{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		IP:          &ip,
+		SubDir:      input.SubDir,
+		Cursor:      input.Cursor,
+		Limit:       input.Limit,
+	}
+
+	if err = changeFeedSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	if changeFeedSrvResp, err = changeFeedSrv.Execute(context.Background()); err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	response := changeFeedSrvResp.(*service.ChangeFeedResponse)
+	changes := make([]map[string]interface{}, len(response.Changes))
+	for index := range response.Changes {
+		changes[index] = journalResp(&response.Changes[index])
+	}
+	data = map[string]interface{}{
+		"cursor":  response.Cursor,
+		"hasMore": response.HasMore,
+		"changes": changes,
+	}
+	code = 200
+	success = true
+}
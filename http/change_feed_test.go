@@ -0,0 +1,36 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeFeedHandler(t *testing.T) {
+	ctx, file, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+	db := ctx.MustGet("db").(*gorm.DB)
+
+	assert.Nil(t, file.MoveTo("/save/moved.bytes", db))
+
+	ctx.Set("inputParam", &changeFeedInput{SubDir: "/save", Limit: 100})
+	ChangeFeedHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	responseData := response.Data.(map[string]interface{})
+	assert.False(t, responseData["hasMore"].(bool))
+	changes := responseData["changes"].([]interface{})
+	assert.Equal(t, 4, len(changes))
+	last := changes[3].(map[string]interface{})
+	assert.Equal(t, models.JournalMove, last["event"].(string))
+	assert.Equal(t, "/save/to/random.bytes", last["previousPath"].(string))
+	assert.Equal(t, last["sequence"].(float64), responseData["cursor"].(float64))
+}
@@ -0,0 +1,144 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/log"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// fileArchiveInput is used to download a directory or some files as archive,
+// fileUids is a comma separated list of file uid
+type fileArchiveInput struct {
+	Token    string  `form:"token" binding:"required"`
+	SubDir   *string `form:"subDir" binding:"omitempty"`
+	FileUIDs *string `form:"fileUids" binding:"omitempty"`
+	Format   string  `form:"format,default=zip" binding:"omitempty,oneof=zip tar.gz"`
+	Nonce    *string `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign     *string `form:"sign" binding:"omitempty"`
+}
+
+// archiveResponseWriter writes the response headers just before the first
+// byte of archive, so that the errors occurred before that can still be
+// responded as json
+type archiveResponseWriter struct {
+	ctx      *gin.Context
+	filename string
+	written  bool
+}
+
+func (a *archiveResponseWriter) Write(p []byte) (int, error) {
+	if !a.written {
+		a.written = true
+		a.ctx.Set("ignoreRespBody", true)
+		a.ctx.Header("Content-Type", archiveContentType(a.filename))
+		a.ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, a.filename))
+		a.ctx.Status(http.StatusOK)
+	}
+	return a.ctx.Writer.Write(p)
+}
+
+func archiveContentType(filename string) string {
+	if strings.HasSuffix(filename, ".zip") {
+		return "application/zip"
+	}
+	return "application/gzip"
+}
+
+// archiveFilename return the name of archive, it's named after the directory
+// or the only one file selected
+func archiveFilename(subDir *string, files []*models.File, format string) string {
+	var name = "archive"
+	if subDir != nil {
+		if base := path.Base("/" + strings.Trim(*subDir, "/")); base != "/" {
+			name = base
+		}
+	} else if len(files) == 1 {
+		name = strings.TrimSuffix(files[0].Name, "."+files[0].Ext)
+	}
+	return name + "." + format
+}
+
+// FileArchiveHandler is used to download a directory or some files as a zip
+// or tar.gz archive, the archive is built while it's being downloaded
+func FileArchiveHandler(ctx *gin.Context) {
+	var (
+		ip             = ctx.ClientIP()
+		db             = ctx.MustGet("db").(*gorm.DB)
+		err            error
+		file           *models.File
+		files          []*models.File
+		token          = ctx.MustGet("token").(*models.Token)
+		input          = ctx.MustGet("inputParam").(*fileArchiveInput)
+		requestID      = ctx.GetInt64("requestId")
+		writer         *archiveResponseWriter
+		fileArchiveSrv *service.FileArchive
+	)
+
+	if input.FileUIDs != nil {
+		for _, fileUID := range strings.Split(*input.FileUIDs, ",") {
+			if fileUID = strings.TrimSpace(fileUID); fileUID == "" {
+				continue
+			}
+			if file, err = models.FindFileByUID(fileUID, false, db); err != nil {
+				ctx.JSON(400, &Response{
+					RequestID: requestID,
+					Success:   false,
+					Errors:    generateErrors(err, "fileUids"),
+				})
+				return
+			}
+			files = append(files, file)
+		}
+	}
+
+	writer = &archiveResponseWriter{ctx: ctx, filename: archiveFilename(input.SubDir, files, input.Format)}
+	fileArchiveSrv = &service.FileArchive{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		IP:          &ip,
+		SubDir:      input.SubDir,
+		Files:       files,
+		Format:      input.Format,
+		Writer:      writer,
+	}
+
+	if isTesting {
+		fileArchiveSrv.RootPath = testingChunkRootPath
+	}
+
+	if err = fileArchiveSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		ctx.JSON(400, &Response{
+			RequestID: requestID,
+			Success:   false,
+			Errors:    generateErrors(err, ""),
+		})
+		return
+	}
+
+	if _, err = fileArchiveSrv.Execute(ctx.Request.Context()); err != nil {
+		if !writer.written {
+			ctx.JSON(400, &Response{
+				RequestID: requestID,
+				Success:   false,
+				Errors:    generateErrors(err, ""),
+			})
+			return
+		}
+		// the archive has been partially sent, it's interrupted without the
+		// trailer of archive, so the client is able to find it's broken
+		log.MustNewLogger(nil).Errorf("request %d: archive is interrupted: %s", requestID, err)
+		ctx.Abort()
+	}
+}
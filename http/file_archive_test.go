@@ -0,0 +1,54 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileArchiveHandler(t *testing.T) {
+	ctx, file, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	subDir := "/save"
+	ctx.Set("inputParam", &fileArchiveInput{SubDir: &subDir, Format: "zip"})
+	FileArchiveHandler(ctx)
+	assert.Equal(t, "application/zip", ctx.Writer.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="save.zip"`, ctx.Writer.Header().Get("Content-Disposition"))
+
+	body := writer.body.Bytes()
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(reader.File))
+	assert.Equal(t, "to/", reader.File[0].Name)
+	assert.Equal(t, "to/"+file.Name, reader.File[1].Name)
+}
+
+func TestFileArchiveHandler2(t *testing.T) {
+	ctx, _, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	fileUIDs := "not exist"
+	ctx.Set("inputParam", &fileArchiveInput{FileUIDs: &fileUIDs, Format: "zip"})
+	FileArchiveHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, "record not found", response.Errors["fileUids"][0])
+
+	writer.body.Reset()
+	ctx.Set("inputParam", &fileArchiveInput{Format: "zip"})
+	FileArchiveHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.NotEmpty(t, response.Errors["FileArchive.SubDir"])
+}
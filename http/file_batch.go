@@ -0,0 +1,115 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// fileBatchInput represent the input of batch, operations is a json array,
+// every element looks like: {"op": "move", "path": "/a", "to": "/b"}
+type fileBatchInput struct {
+	Token      string  `form:"token" binding:"required"`
+	Nonce      string  `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign       *string `form:"sign" binding:"omitempty"`
+	Operations string  `form:"operations" binding:"required"`
+}
+
+// FileBatchHandler is used to execute a list of file operations atomically
+func FileBatchHandler(ctx *gin.Context) {
+	var (
+		ip               = ctx.ClientIP()
+		db               = ctx.MustGet("db").(*gorm.DB)
+		err              error
+		token            = ctx.MustGet("token").(*models.Token)
+		input            = ctx.MustGet("inputParam").(*fileBatchInput)
+		operations       []*service.FileBatchOperation
+		fileBatchSrv     *service.FileBatch
+		fileBatchSrvResp interface{}
+
+		code     = 400
+		reErrors map[string][]string
+		success  bool
+		data     interface{}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	if err = json.Unmarshal([]byte(input.Operations), &operations); err != nil {
+		reErrors = generateErrors(err, "operations")
+		return
+	}
+
+	fileBatchSrv = &service.FileBatch{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		IP:          &ip,
+		Operations:  operations,
+	}
+
+	if err = fileBatchSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	fileBatchSrvResp, err = fileBatchSrv.Execute(context.Background())
+	if results, ok := fileBatchSrvResp.([]*service.FileBatchResult); ok {
+		items := make([]map[string]interface{}, len(results))
+		for index, result := range results {
+			var respErr error
+			if items[index], respErr = fileBatchResultResp(result, db); respErr != nil {
+				reErrors = generateErrors(respErr, "")
+				return
+			}
+		}
+		data = items
+	}
+
+	if err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	code = 200
+	success = true
+}
+
+// fileBatchResultResp is used to generate the json response of an operation in batch
+func fileBatchResultResp(result *service.FileBatchResult, db *gorm.DB) (map[string]interface{}, error) {
+	var (
+		err  error
+		resp = map[string]interface{}{
+			"index":   result.Index,
+			"op":      result.Op,
+			"success": result.Error == nil,
+		}
+	)
+
+	if result.Error != nil {
+		resp["error"] = result.Error.Error()
+	}
+
+	if result.File != nil {
+		if resp["file"], err = fileResp(result.File, db); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,72 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBatchHandler(t *testing.T) {
+	ctx, _, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+	db := ctx.MustGet("db").(*gorm.DB)
+	token := ctx.MustGet("token").(*models.Token)
+
+	ctx.Set("inputParam", &fileBatchInput{Operations: `[
+		{"op": "mkdir", "path": "/archive"},
+		{"op": "copy", "path": "/save/to/random.bytes", "to": "/archive/random.bytes"},
+		{"op": "set-hidden", "path": "/archive/random.bytes", "hidden": true},
+		{"op": "delete", "path": "/save", "force": true}
+	]`})
+	FileBatchHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	items := response.Data.([]interface{})
+	assert.Equal(t, 4, len(items))
+	for _, item := range items {
+		assert.True(t, item.(map[string]interface{})["success"].(bool))
+	}
+	file := items[2].(map[string]interface{})["file"].(map[string]interface{})
+	assert.Equal(t, float64(models.Hidden), file["hidden"].(float64))
+
+	_, err = models.FindFileByPath(&token.App, "/save/to/random.bytes", db)
+	assert.NotNil(t, err)
+	_, err = models.FindFileByPath(&token.App, "/archive/random.bytes", db)
+	assert.Nil(t, err)
+}
+
+func TestFileBatchHandler2(t *testing.T) {
+	ctx, _, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	ctx.Set("inputParam", &fileBatchInput{Operations: `{"op": "mkdir"}`})
+	FileBatchHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.NotEmpty(t, response.Errors["operations"])
+
+	writer.body.Reset()
+	ctx.Set("inputParam", &fileBatchInput{Operations: `[
+		{"op": "mkdir", "path": "/archive"},
+		{"op": "delete", "path": "/not/exist"}
+	]`})
+	FileBatchHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, service.ErrBatchAborted.Error(), response.Errors["system"][0])
+	items := response.Data.([]interface{})
+	assert.Equal(t, 2, len(items))
+	assert.False(t, items[1].(map[string]interface{})["success"].(bool))
+}
@@ -12,6 +12,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/bigfile/bigfile/databases/models"
 	"github.com/bigfile/bigfile/internal/util"
@@ -23,16 +24,17 @@ import (
 var testingChunkRootPath *string
 
 type fileCreateInput struct {
-	Token     string  `form:"token" binding:"required"`
-	Nonce     string  `form:"nonce" header:"X-Request-Nonce" binding:"required,min=32,max=48"`
-	Path      string  `form:"path" binding:"required,max=1000"`
-	Sign      *string `form:"sign" binding:"omitempty"`
-	Hash      *string `form:"hash" binding:"omitempty"`
-	Size      *int    `form:"size" binding:"omitempty"`
-	Overwrite *bool   `form:"overwrite,default=0" binding:"omitempty"`
-	Rename    *bool   `form:"rename,default=0" binding:"omitempty"`
-	Append    *bool   `form:"append,default=0" binding:"omitempty"`
-	Hidden    *bool   `form:"hidden,default=0" binding:"omitempty"`
+	Token     string     `form:"token" binding:"required"`
+	Nonce     string     `form:"nonce" header:"X-Request-Nonce" binding:"required,min=32,max=48"`
+	Path      string     `form:"path" binding:"required,max=1000"`
+	Sign      *string    `form:"sign" binding:"omitempty"`
+	Hash      *string    `form:"hash" binding:"omitempty"`
+	Size      *int       `form:"size" binding:"omitempty"`
+	Overwrite *bool      `form:"overwrite,default=0" binding:"omitempty"`
+	Rename    *bool      `form:"rename,default=0" binding:"omitempty"`
+	Append    *bool      `form:"append,default=0" binding:"omitempty"`
+	Hidden    *bool      `form:"hidden,default=0" binding:"omitempty"`
+	ExpiredAt *time.Time `form:"expiredAt" time_format:"unix" binding:"omitempty,gt"`
 }
 
 // FileCreateHandler is used to create file or directory
@@ -100,6 +102,7 @@ func FileCreateHandler(ctx *gin.Context) {
 	}
 
 	fileCreateSrv.Reader = reader
+	fileCreateSrv.IfMatch, fileCreateSrv.IfNoneMatch = preconditionHeaders(ctx)
 	setFileCreateSrv(input, fileCreateSrv)
 
 	if err := fileCreateSrv.Validate(); !reflect.ValueOf(err).IsNil() {
@@ -108,6 +111,7 @@ func FileCreateHandler(ctx *gin.Context) {
 	}
 
 	if fileCreateValue, err = fileCreateSrv.Execute(context.Background()); err != nil {
+		code = preconditionCode(err)
 		reErrors = generateErrors(err, "")
 		return
 	}
@@ -134,6 +138,7 @@ func setFileCreateSrv(input *fileCreateInput, fileCreateSrv *service.FileCreate)
 	if input.Rename != nil && *input.Rename {
 		fileCreateSrv.Rename = 1
 	}
+	fileCreateSrv.ExpiredAt = input.ExpiredAt
 
 	if isTesting {
 		fileCreateSrv.RootPath = testingChunkRootPath
@@ -17,7 +17,8 @@ import (
 type fileDeleteInput struct {
 	Token   string  `form:"token" binding:"required"`
 	Nonce   string  `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
-	FileUID string  `form:"fileUid" binding:"required"`
+	FileUID string  `form:"fileUid" binding:"omitempty"`
+	Path    *string `form:"path" binding:"omitempty,max=1000"`
 	Force   bool    `form:"force,default=0"  binding:"omitempty"`
 	Sign    *string `form:"sign" binding:"omitempty"`
 }
@@ -28,6 +29,7 @@ func FileDeleteHandler(ctx *gin.Context) {
 		ip                 = ctx.ClientIP()
 		db                 = ctx.MustGet("db").(*gorm.DB)
 		err                error
+		key                string
 		file               *models.File
 		token              = ctx.MustGet("token").(*models.Token)
 		input              = ctx.MustGet("inputParam").(*fileDeleteInput)
@@ -50,8 +52,8 @@ func FileDeleteHandler(ctx *gin.Context) {
 		})
 	}()
 
-	if file, err = models.FindFileByUID(input.FileUID, false, db); err != nil {
-		reErrors = generateErrors(err, "fileUid")
+	if file, key, err = findInputFile(token, input.FileUID, input.Path, db); err != nil {
+		reErrors = generateErrors(err, key)
 		return
 	}
 
@@ -64,6 +66,7 @@ func FileDeleteHandler(ctx *gin.Context) {
 		Force: &input.Force,
 		IP:    &ip,
 	}
+	fileDeleteSrv.IfMatch, fileDeleteSrv.IfNoneMatch = preconditionHeaders(ctx)
 
 	if err = fileDeleteSrv.Validate(); !reflect.ValueOf(err).IsNil() {
 		reErrors = generateErrors(err, "system")
@@ -71,6 +74,7 @@ func FileDeleteHandler(ctx *gin.Context) {
 	}
 
 	if fileDeleteSrvValue, err = fileDeleteSrv.Execute(context.Background()); err != nil {
+		code = preconditionCode(err)
 		reErrors = generateErrors(err, "system")
 		return
 	}
@@ -83,6 +83,31 @@ func TestFileDeleteHandler(t *testing.T) {
 	assert.Equal(t, "record not found", response.Errors["fileUid"][0])
 }
 
+func TestFileDeleteHandlerByPath(t *testing.T) {
+	ctx, down := newFileDeleteForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	var path = "/save/to/none.bytes"
+	input := ctx.MustGet("inputParam").(*fileDeleteInput)
+	input.FileUID = ""
+	input.Path = &path
+
+	FileDeleteHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, "record not found", response.Errors["path"][0])
+
+	writer.body.Reset()
+	path = "/save/to/random.bytes"
+	FileDeleteHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, path, response.Data.(map[string]interface{})["path"])
+}
+
 func TestFileDeleteHandler2(t *testing.T) {
 	ctx, down := newFileDeleteForTest(t)
 	defer down(t)
@@ -159,6 +184,20 @@ func TestFileDeleteHandler5(t *testing.T) {
 	assert.NotNil(t, responseData["deletedAt"])
 }
 
+func TestFileDeleteHandler7(t *testing.T) {
+	ctx, down := newFileDeleteForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	ctx.Request.Header.Set("If-Match", `"not the hash"`)
+	FileDeleteHandler(ctx)
+	assert.Equal(t, http.StatusPreconditionFailed, writer.Status())
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, "precondition failed", response.Errors["system"][0])
+}
+
 func TestFileDeleteHandler6(t *testing.T) {
 	var (
 		w       = httptest.NewRecorder()
@@ -0,0 +1,130 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// fileExtractInput represent the input of extracting an archive that has been
+// uploaded, format is detected by the name of archive if it's omitted
+type fileExtractInput struct {
+	Token      string  `form:"token" binding:"required"`
+	FileUID    string  `form:"fileUid" binding:"required"`
+	Nonce      string  `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign       *string `form:"sign" binding:"omitempty"`
+	Target     string  `form:"target" binding:"omitempty,max=1000"`
+	Format     string  `form:"format" binding:"omitempty,oneof=zip tar tar.gz"`
+	Overwrite  bool    `form:"overwrite,default=0" binding:"omitempty"`
+	MaxEntries int     `form:"maxEntries" binding:"omitempty,min=1,max=100000"`
+	MaxSize    int64   `form:"maxSize" binding:"omitempty,min=1,max=1073741824"`
+}
+
+// FileExtractHandler is used to unpack an archive into target directory
+func FileExtractHandler(ctx *gin.Context) {
+	var (
+		ip                 = ctx.ClientIP()
+		db                 = ctx.MustGet("db").(*gorm.DB)
+		err                error
+		file               *models.File
+		token              = ctx.MustGet("token").(*models.Token)
+		input              = ctx.MustGet("inputParam").(*fileExtractInput)
+		fileExtractSrv     *service.FileExtract
+		fileExtractSrvResp interface{}
+
+		code     = 400
+		reErrors map[string][]string
+		success  bool
+		data     interface{}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	if file, err = models.FindFileByUID(input.FileUID, false, db); err != nil {
+		reErrors = generateErrors(err, "fileUid")
+		return
+	}
+
+	fileExtractSrv = &service.FileExtract{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		File:        file,
+		IP:          &ip,
+		Target:      input.Target,
+		Format:      input.Format,
+		MaxEntries:  input.MaxEntries,
+		MaxSize:     input.MaxSize,
+	}
+
+	if input.Overwrite {
+		fileExtractSrv.Overwrite = 1
+	}
+
+	if isTesting {
+		fileExtractSrv.RootPath = testingChunkRootPath
+	}
+
+	if err = fileExtractSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	fileExtractSrvResp, err = fileExtractSrv.Execute(context.Background())
+	if results, ok := fileExtractSrvResp.([]*service.FileExtractResult); ok {
+		items := make([]map[string]interface{}, len(results))
+		for index, result := range results {
+			if items[index], err = fileExtractResultResp(result, db); err != nil {
+				reErrors = generateErrors(err, "")
+				return
+			}
+		}
+		data = items
+	}
+
+	if err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	code = 200
+	success = true
+}
+
+// fileExtractResultResp is used to generate the json response of an entry of archive
+func fileExtractResultResp(result *service.FileExtractResult, db *gorm.DB) (map[string]interface{}, error) {
+	var (
+		err  error
+		resp = map[string]interface{}{
+			"name":    result.Name,
+			"path":    result.Path,
+			"success": result.Error == nil,
+		}
+	)
+
+	if result.Error != nil {
+		resp["error"] = result.Error.Error()
+	}
+
+	if result.File != nil {
+		if resp["file"], err = fileResp(result.File, db); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
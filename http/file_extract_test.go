@@ -0,0 +1,64 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileExtractHandler(t *testing.T) {
+	ctx, _, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+	db := ctx.MustGet("db").(*gorm.DB)
+	token := ctx.MustGet("token").(*models.Token)
+
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+	entryWriter, err := zipWriter.Create("dir/a.txt")
+	assert.Nil(t, err)
+	_, err = entryWriter.Write([]byte("a"))
+	assert.Nil(t, err)
+	_, err = zipWriter.Create("../evil.txt")
+	assert.Nil(t, err)
+	assert.Nil(t, zipWriter.Close())
+	archive, err := models.CreateFileFromReader(
+		&token.App, "/upload/bundle.zip", buf, int8(0), testingChunkRootPath, db)
+	assert.Nil(t, err)
+
+	ctx.Set("inputParam", &fileExtractInput{FileUID: archive.UID, Target: "/out"})
+	FileExtractHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	results := response.Data.([]interface{})
+	assert.Equal(t, 2, len(results))
+	first := results[0].(map[string]interface{})
+	assert.True(t, first["success"].(bool))
+	assert.Equal(t, "/out/dir/a.txt", first["path"].(string))
+	assert.NotNil(t, first["file"])
+	second := results[1].(map[string]interface{})
+	assert.False(t, second["success"].(bool))
+	assert.Equal(t, "the path of entry is unsafe", second["error"].(string))
+}
+
+func TestFileExtractHandler2(t *testing.T) {
+	ctx, file, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	ctx.Set("inputParam", &fileExtractInput{FileUID: file.UID, Target: "/out"})
+	FileExtractHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, "unknown archive format", response.Errors["FileExtract.Format"][0])
+}
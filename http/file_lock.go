@@ -0,0 +1,215 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type fileLockAcquireInput struct {
+	Token     string  `form:"token" binding:"required"`
+	FileUID   string  `form:"fileUid" binding:"required"`
+	Nonce     string  `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign      *string `form:"sign" binding:"omitempty"`
+	Exclusive bool    `form:"exclusive,default=0" binding:"omitempty"`
+	TTL       int     `form:"ttl" binding:"required,min=1,max=86400"`
+}
+
+type fileLockRefreshInput struct {
+	Token   string  `form:"token" binding:"required"`
+	LockUID string  `form:"lockUid" binding:"required"`
+	Nonce   string  `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign    *string `form:"sign" binding:"omitempty"`
+	TTL     int     `form:"ttl" binding:"required,min=1,max=86400"`
+}
+
+type fileLockReleaseInput struct {
+	Token   string  `form:"token" binding:"required"`
+	LockUID string  `form:"lockUid" binding:"required"`
+	Nonce   string  `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign    *string `form:"sign" binding:"omitempty"`
+}
+
+// FileLockAcquireHandler is used to lock a file or a directory
+func FileLockAcquireHandler(ctx *gin.Context) {
+	var (
+		ip           = ctx.ClientIP()
+		db           = ctx.MustGet("db").(*gorm.DB)
+		err          error
+		file         *models.File
+		token        = ctx.MustGet("token").(*models.Token)
+		input        = ctx.MustGet("inputParam").(*fileLockAcquireInput)
+		lockSrv      *service.FileLockAcquire
+		lockSrvValue interface{}
+
+		code     = 400
+		reErrors map[string][]string
+		success  bool
+		data     interface{}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	if file, err = models.FindFileByUID(input.FileUID, false, db); err != nil {
+		reErrors = generateErrors(err, "fileUid")
+		return
+	}
+
+	lockSrv = &service.FileLockAcquire{
+		BaseService: service.BaseService{
+			DB: db,
+		},
+		Token: token,
+		File:  file,
+		IP:    &ip,
+		TTL:   input.TTL,
+	}
+
+	if input.Exclusive {
+		lockSrv.Exclusive = 1
+	}
+
+	if err = lockSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	if lockSrvValue, err = lockSrv.Execute(context.Background()); err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	lockResult := fileLockResp(lockSrvValue.(*models.FileLock))
+	lockResult["fileUid"] = file.UID
+	data = lockResult
+	code = 200
+	success = true
+}
+
+// FileLockRefreshHandler is used to extend the lease of lock
+func FileLockRefreshHandler(ctx *gin.Context) {
+	var (
+		ip           = ctx.ClientIP()
+		db           = ctx.MustGet("db").(*gorm.DB)
+		err          error
+		lock         *models.FileLock
+		token        = ctx.MustGet("token").(*models.Token)
+		input        = ctx.MustGet("inputParam").(*fileLockRefreshInput)
+		lockSrv      *service.FileLockRefresh
+		lockSrvValue interface{}
+
+		code     = 400
+		reErrors map[string][]string
+		success  bool
+		data     interface{}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	if lock, err = models.FindFileLockByUID(input.LockUID, db); err != nil {
+		reErrors = generateErrors(err, "lockUid")
+		return
+	}
+
+	lockSrv = &service.FileLockRefresh{
+		BaseService: service.BaseService{
+			DB: db,
+		},
+		Token: token,
+		Lock:  lock,
+		IP:    &ip,
+		TTL:   input.TTL,
+	}
+
+	if err = lockSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	if lockSrvValue, err = lockSrv.Execute(context.Background()); err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	data = fileLockResp(lockSrvValue.(*models.FileLock))
+	code = 200
+	success = true
+}
+
+// FileLockReleaseHandler is used to release the lock
+func FileLockReleaseHandler(ctx *gin.Context) {
+	var (
+		ip           = ctx.ClientIP()
+		db           = ctx.MustGet("db").(*gorm.DB)
+		err          error
+		lock         *models.FileLock
+		token        = ctx.MustGet("token").(*models.Token)
+		input        = ctx.MustGet("inputParam").(*fileLockReleaseInput)
+		lockSrv      *service.FileLockRelease
+		lockSrvValue interface{}
+
+		code     = 400
+		reErrors map[string][]string
+		success  bool
+		data     interface{}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	if lock, err = models.FindFileLockByUID(input.LockUID, db); err != nil {
+		reErrors = generateErrors(err, "lockUid")
+		return
+	}
+
+	lockSrv = &service.FileLockRelease{
+		BaseService: service.BaseService{
+			DB: db,
+		},
+		Token: token,
+		Lock:  lock,
+		IP:    &ip,
+	}
+
+	if err = lockSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	if lockSrvValue, err = lockSrv.Execute(context.Background()); err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	data = fileLockResp(lockSrvValue.(*models.FileLock))
+	code = 200
+	success = true
+}
@@ -0,0 +1,139 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFileLockForTest(t *testing.T) (*gin.Context, *models.File, func(*testing.T)) {
+	var (
+		ctx     *gin.Context
+		trx     *gorm.DB
+		err     error
+		token   *models.Token
+		down    func(*testing.T)
+		tempDir = models.NewTempDirForTest()
+	)
+
+	testingChunkRootPath = &tempDir
+	token, trx, down, err = models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	ctx, _ = gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Writer = &bodyWriter{ResponseWriter: ctx.Writer, body: bytes.NewBufferString("")}
+	ctx.Request, _ = http.NewRequest("POST", "http://bigfile.io", strings.NewReader(""))
+	ctx.Request.Header.Set("X-Forwarded-For", "192.168.0.1")
+	ctx.Set("db", trx)
+	ctx.Set("token", token)
+	reqRecord := models.MustNewRequestWithProtocol("http", trx)
+	ctx.Set("reqRecord", reqRecord)
+	ctx.Set("requestId", int64(reqRecord.ID))
+
+	file, err := models.CreateFileFromReader(
+		&token.App, "/save/to/random.bytes", bytes.NewReader(models.Random(128)), int8(0), testingChunkRootPath, trx)
+	assert.Nil(t, err)
+
+	return ctx, file, func(t *testing.T) {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}
+}
+
+func TestFileLockAcquireHandler(t *testing.T) {
+	ctx, file, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	ctx.Set("inputParam", &fileLockAcquireInput{FileUID: file.UID, Exclusive: true, TTL: 60})
+	FileLockAcquireHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	responseData := response.Data.(map[string]interface{})
+	assert.Equal(t, file.UID, responseData["fileUid"].(string))
+	assert.Equal(t, float64(1), responseData["exclusive"].(float64))
+
+	db := ctx.MustGet("db").(*gorm.DB)
+	result, err := fileResp(file, db)
+	assert.Nil(t, err)
+	locks := result["locks"].([]map[string]interface{})
+	assert.Equal(t, 1, len(locks))
+	assert.Equal(t, responseData["lockUid"].(string), locks[0]["lockUid"])
+}
+
+func TestFileLockAcquireHandler2(t *testing.T) {
+	ctx, _, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	ctx.Set("inputParam", &fileLockAcquireInput{FileUID: "not exist", TTL: 60})
+	FileLockAcquireHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, "record not found", response.Errors["fileUid"][0])
+}
+
+func TestFileLockRefreshHandler(t *testing.T) {
+	ctx, file, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+	db := ctx.MustGet("db").(*gorm.DB)
+	token := ctx.MustGet("token").(*models.Token)
+
+	lock, err := models.AcquireFileLock(file, token.UID, true, time.Minute, db)
+	assert.Nil(t, err)
+
+	ctx.Set("inputParam", &fileLockRefreshInput{LockUID: lock.UID, TTL: 3600})
+	FileLockRefreshHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	responseData := response.Data.(map[string]interface{})
+	assert.True(t, int64(responseData["expiredAt"].(float64)) > time.Now().Add(time.Minute).Unix())
+}
+
+func TestFileLockReleaseHandler(t *testing.T) {
+	ctx, file, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+	db := ctx.MustGet("db").(*gorm.DB)
+	token := ctx.MustGet("token").(*models.Token)
+
+	lock, err := models.AcquireFileLock(file, "another owner", true, time.Minute, db)
+	assert.Nil(t, err)
+
+	ctx.Set("inputParam", &fileLockReleaseInput{LockUID: lock.UID})
+	FileLockReleaseHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, models.ErrFileLockNotOwned.Error(), response.Errors["system"][0])
+
+	assert.Nil(t, lock.Release("another owner", db))
+	lock, err = models.AcquireFileLock(file, token.UID, true, time.Minute, db)
+	assert.Nil(t, err)
+
+	writer.body.Reset()
+	ctx.Set("inputParam", &fileLockReleaseInput{LockUID: lock.UID})
+	FileLockReleaseHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+}
@@ -6,17 +6,12 @@ package http
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"path"
 	"reflect"
-	"regexp"
-	"strconv"
-	"strings"
-	"time"
 
 	"github.com/bigfile/bigfile/databases/models"
 	"github.com/bigfile/bigfile/service"
@@ -24,17 +19,12 @@ import (
 	"github.com/jinzhu/gorm"
 )
 
-// ErrWrongRangeHeader represent the http range header format error
-var ErrWrongRangeHeader = errors.New("http range header format error")
-
-// ErrWrongHTTPRange represent wrong http range header
-var ErrWrongHTTPRange = errors.New("wrong http range header, start must be less than end")
-
 const binaryContentType = "application/octet-stream"
 
 type fileReadInput struct {
 	Token         string  `form:"token" binding:"required"`
-	FileUID       string  `form:"fileUid" binding:"required"`
+	FileUID       string  `form:"fileUid" binding:"omitempty"`
+	Path          *string `form:"path" binding:"omitempty,max=1000"`
 	Nonce         *string `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
 	Sign          *string `form:"sign" binding:"omitempty"`
 	OpenInBrowser bool    `form:"openInBrowser,default=0" binding:"omitempty"`
@@ -46,6 +36,7 @@ func FileReadHandler(ctx *gin.Context) {
 		ip               = ctx.ClientIP()
 		db               = ctx.MustGet("db").(*gorm.DB)
 		err              error
+		key              string
 		file             *models.File
 		token            = ctx.MustGet("token").(*models.Token)
 		input            = ctx.MustGet("inputParam").(*fileReadInput)
@@ -55,11 +46,11 @@ func FileReadHandler(ctx *gin.Context) {
 		fileReadSrvValue interface{}
 	)
 
-	if file, err = models.FindFileByUID(input.FileUID, false, db); err != nil {
+	if file, key, err = findInputFile(token, input.FileUID, input.Path, db); err != nil {
 		ctx.JSON(400, &Response{
 			RequestID: requestID,
 			Success:   false,
-			Errors:    generateErrors(err, "fileUid"),
+			Errors:    generateErrors(err, key),
 		})
 		return
 	}
@@ -93,78 +84,29 @@ func FileReadHandler(ctx *gin.Context) {
 		return
 	}
 	fileReaderSeeker = fileReadSrvValue.(io.ReadSeeker)
-	rangeHeader := ctx.Request.Header.Get("Range")
-	if rangeHeader == "" {
-		readAllContent(ctx, fileReaderSeeker, file, input)
-		return
-	}
-	rangeHeaderPattern := regexp.MustCompile(`^bytes=(?P<start>\d*)-(?P<end>\d*)$`)
-	if !rangeHeaderPattern.Match([]byte(rangeHeader)) {
-		ctx.JSON(400, &Response{
-			RequestID: requestID,
-			Success:   false,
-			Errors:    generateErrors(ErrWrongRangeHeader, ""),
-		})
-		return
-	}
-	rangePosition := strings.TrimPrefix(rangeHeader, "bytes=")
-	rangeStart := 0
-	rangeEnd := file.Size
-	if rangePosition == "-" {
-		readAllContent(ctx, fileReaderSeeker, file, input)
-		return
-	} else if strings.HasPrefix(rangePosition, "-") {
-		rangeEnd, _ = strconv.Atoi(strings.TrimPrefix(rangePosition, "-"))
-	} else if strings.HasSuffix(rangePosition, "-") {
-		rangeStart, _ = strconv.Atoi(strings.TrimSuffix(rangePosition, "-"))
-	} else {
-		rangePositionSplit := strings.Split(rangePosition, "-")
-		rangeStart, _ = strconv.Atoi(rangePositionSplit[0])
-		rangeEnd, _ = strconv.Atoi(rangePositionSplit[1])
-	}
-	if rangeStart > rangeEnd {
-		ctx.JSON(400, &Response{
-			RequestID: requestID,
-			Success:   false,
-			Errors:    generateErrors(ErrWrongHTTPRange, ""),
-		})
-		return
-	}
-	readRangeContent(ctx, fileReaderSeeker, file, input, rangeStart, rangeEnd)
+	readContent(ctx, fileReaderSeeker, file, input)
 }
 
-func readAllContent(ctx *gin.Context, readerSeeker io.ReadSeeker, file *models.File, input *fileReadInput) {
-	headers := map[string]string{
-		"ETag":                file.Object.Hash,
-		"Accept-Ranges":       "bytes",
-		"Content-Type":        binaryContentType,
-		"Last-Modified":       file.UpdatedAt.Format(time.RFC1123),
-		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, file.Name),
-	}
-	headers["Content-Length"] = strconv.Itoa(file.Size)
-	if contentType := mime.TypeByExtension(path.Ext(file.Name)); contentType != "" {
-		headers["Content-Type"] = contentType
+// readContent is used to respond the content of file. It's served by
+// http.ServeContent, so that the conditional requests of RFC 7232 and the
+// range requests of RFC 7233 are supported, including If-None-Match,
+// If-Modified-Since, If-Range, suffix ranges, multiple ranges and HEAD.
+// The entity tag of file is the quoted hash of its object.
+func readContent(ctx *gin.Context, fileReaderSeeker io.ReadSeeker, file *models.File, input *fileReadInput) {
+	var (
+		header      = ctx.Writer.Header()
+		contentType = mime.TypeByExtension(path.Ext(file.Name))
+		disposition = "attachment"
+	)
+	if contentType == "" {
+		contentType = binaryContentType
 	}
 	if input.OpenInBrowser {
-		headers["Content-Disposition"] = fmt.Sprintf(`inline; filename="%s"`, file.Name)
-	}
-	ctx.Set("ignoreRespBody", true)
-	ctx.DataFromReader(http.StatusOK, int64(file.Size), headers["Content-Type"], readerSeeker, headers)
-}
-
-func readRangeContent(ctx *gin.Context, readerSeeker io.ReadSeeker, file *models.File, input *fileReadInput, start, end int) {
-	if _, err := readerSeeker.Seek(int64(start), io.SeekStart); err != nil {
-		ctx.JSON(400, &Response{
-			RequestID: ctx.GetInt64("requestId"),
-			Success:   false,
-			Errors:    generateErrors(err, ""),
-		})
-		return
+		disposition = "inline"
 	}
-	limitSize := int64(end - start)
-	limitReader := io.LimitReader(readerSeeker, limitSize)
+	header.Set("ETag", `"`+file.Object.Hash+`"`)
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", fmt.Sprintf(`%s; filename="%s"`, disposition, file.Name))
 	ctx.Set("ignoreRespBody", true)
-	ctx.DataFromReader(http.StatusPartialContent, limitSize, binaryContentType, limitReader, map[string]string{
-		"Content-Range": fmt.Sprintf("%d-%d/%d", start, end, file.Size),
-	})
+	http.ServeContent(ctx.Writer, ctx.Request, file.Name, file.UpdatedAt, fileReaderSeeker)
 }
@@ -9,7 +9,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -21,6 +24,7 @@ import (
 	"github.com/bigfile/bigfile/databases"
 	"github.com/bigfile/bigfile/databases/models"
 	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/service"
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
@@ -85,6 +89,29 @@ func TestFileReadHandler(t *testing.T) {
 	assert.Equal(t, "record not found", response.Errors["fileUid"][0])
 }
 
+func TestFileReadHandlerByPath(t *testing.T) {
+	ctx, down := newFileReadForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	var path = "/random.png"
+	input := ctx.MustGet("inputParam").(*fileReadInput)
+	input.Path = &path
+
+	FileReadHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, service.ErrFileTarget.Error(), response.Errors["path"][0])
+
+	writer.body.Reset()
+	input.FileUID = ""
+	FileReadHandler(ctx)
+	bodyHash, err := util.Sha256Hash2String(writer.body.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, ctx.GetString("randomBytesHash"), bodyHash)
+}
+
 func TestFileReadHandler2(t *testing.T) {
 	ctx, down := newFileReadForTest(t)
 	defer down(t)
@@ -271,7 +298,6 @@ func TestFileReadHandler6(t *testing.T) {
 // TestFileReadHandler7 is used to test whether implement http range protocol
 func TestFileReadHandler7(t *testing.T) {
 	var (
-		ctx     *gin.Context
 		trx     *gorm.DB
 		err     error
 		token   *models.Token
@@ -288,14 +314,6 @@ func TestFileReadHandler7(t *testing.T) {
 			os.RemoveAll(tempDir)
 		}
 	}()
-	ctx, _ = gin.CreateTestContext(httptest.NewRecorder())
-	bw := &bodyWriter{ResponseWriter: ctx.Writer, body: bytes.NewBufferString("")}
-	ctx.Writer = bw
-	ctx.Request, _ = http.NewRequest("POST", "http://bigfile.io", strings.NewReader(""))
-	ctx.Request.Header.Set("X-Forwarded-For", "192.168.0.1")
-	ctx.Set("db", trx)
-	ctx.Set("token", token)
-	ctx.Set("requestId", rand.Int63())
 
 	randomBytes := []byte("hello world, this is a fantastic world")
 	randomBytesHash, err := util.Sha256Hash2String(randomBytes)
@@ -303,70 +321,108 @@ func TestFileReadHandler7(t *testing.T) {
 	randomBytesReader := bytes.NewReader(randomBytes)
 	file, err := models.CreateFileFromReader(&token.App, "/random.txt", randomBytesReader, int8(0), testingChunkRootPath, trx)
 	assert.Nil(t, err)
-	ctx.Set("inputParam", &fileReadInput{FileUID: file.UID})
+
+	read := func(method string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request, _ = http.NewRequest(method, "http://bigfile.io", nil)
+		ctx.Request.Header.Set("X-Forwarded-For", "192.168.0.1")
+		for key, value := range headers {
+			ctx.Request.Header.Set(key, value)
+		}
+		ctx.Set("db", trx)
+		ctx.Set("token", token)
+		ctx.Set("requestId", rand.Int63())
+		ctx.Set("inputParam", &fileReadInput{FileUID: file.UID})
+		FileReadHandler(ctx)
+		ctx.Writer.WriteHeaderNow()
+		return w
+	}
 
 	// set range is empty, is equal to download all content
-	ctx.Request.Header.Set("Range", "")
-	FileReadHandler(ctx)
-	assert.Equal(t, http.StatusOK, bw.Status())
-	assert.Equal(t, "bytes", bw.Header().Get("Accept-Ranges"))
-	assert.Equal(t, strconv.Itoa(len(randomBytes)), bw.Header().Get("Content-Length"))
-	assert.Equal(t, randomBytesHash, bw.Header().Get("Etag"))
-	bw.body.Reset()
-
-	// range header format error
-	ctx.Request.Header.Set("Range", "bytes=start-end")
-	FileReadHandler(ctx)
-	assert.Equal(t, http.StatusBadRequest, bw.Status())
-	response, _ := parseResponse(bw.body.String())
-	assert.Equal(t, ErrWrongRangeHeader.Error(), response.Errors["system"][0])
-	bw.body.Reset()
+	w := read("GET", map[string]string{"Range": ""})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+	assert.Equal(t, strconv.Itoa(len(randomBytes)), w.Header().Get("Content-Length"))
+	assert.Equal(t, `"`+randomBytesHash+`"`, w.Header().Get("Etag"))
+	assert.Equal(t, string(randomBytes), w.Body.String())
+	lastModified := w.Header().Get("Last-Modified")
 
-	// range start is greater than end
-	ctx.Request.Header.Set("Range", "bytes=10-9")
-	FileReadHandler(ctx)
-	assert.Equal(t, http.StatusBadRequest, bw.Status())
-	response, _ = parseResponse(bw.body.String())
-	assert.Equal(t, ErrWrongHTTPRange.Error(), response.Errors["system"][0])
-	bw.body.Reset()
+	// range header format error, the range is unsatisfiable
+	w = read("GET", map[string]string{"Range": "bytes=start-end"})
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+
+	// range start is greater than the size of file
+	w = read("GET", map[string]string{"Range": "bytes=38-"})
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+	assert.Equal(t, "bytes */38", w.Header().Get("Content-Range"))
 
 	var buf strings.Builder
 
-	// top ten bytes
-	ctx.Request.Header.Set("Range", "bytes=-10")
-	FileReadHandler(ctx)
-	assert.Equal(t, http.StatusPartialContent, bw.Status())
-	assert.Equal(t, 10, len(bw.body.String()))
-	_, _ = buf.WriteString(bw.body.String())
-	assert.Equal(t, "0-10/38", bw.Header().Get("Content-Range"))
-	bw.body.Reset()
-
-	// the middle 20 bytes
-	bw.Header().Del("Content-Range")
-	ctx.Request.Header.Set("Range", "bytes=10-20")
-	FileReadHandler(ctx)
-	assert.Equal(t, http.StatusPartialContent, bw.Status())
-	assert.Equal(t, 10, len(bw.body.String()))
-	_, _ = buf.WriteString(bw.body.String())
-	assert.Equal(t, "10-20/38", bw.Header().Get("Content-Range"))
-	bw.body.Reset()
+	// first ten bytes
+	w = read("GET", map[string]string{"Range": "bytes=0-9"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bytes 0-9/38", w.Header().Get("Content-Range"))
+	_, _ = buf.WriteString(w.Body.String())
+
+	// the middle ten bytes
+	w = read("GET", map[string]string{"Range": "bytes=10-19"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bytes 10-19/38", w.Header().Get("Content-Range"))
+	_, _ = buf.WriteString(w.Body.String())
 
 	// last 18 bytes
-	bw.Header().Del("Content-Range")
-	ctx.Request.Header.Set("Range", "bytes=20-")
-	FileReadHandler(ctx)
-	assert.Equal(t, http.StatusPartialContent, bw.Status())
-	assert.Equal(t, 18, len(bw.body.String()))
-	_, _ = buf.WriteString(bw.body.String())
-	assert.Equal(t, "20-38/38", bw.Header().Get("Content-Range"))
-	bw.body.Reset()
+	w = read("GET", map[string]string{"Range": "bytes=20-"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bytes 20-37/38", w.Header().Get("Content-Range"))
+	_, _ = buf.WriteString(w.Body.String())
 
 	assert.Equal(t, buf.String(), string(randomBytes))
 
-	// special range header, -
-	ctx.Request.Header.Set("Range", "bytes=-")
-	FileReadHandler(ctx)
-	assert.Equal(t, http.StatusOK, bw.Status())
-	assert.Equal(t, 38, len(bw.body.String()))
-	bw.body.Reset()
+	// suffix range, the last 5 bytes
+	w = read("GET", map[string]string{"Range": "bytes=-5"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bytes 33-37/38", w.Header().Get("Content-Range"))
+	assert.Equal(t, "world", w.Body.String())
+
+	// multiple ranges
+	w = read("GET", map[string]string{"Range": "bytes=0-4,-5"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	assert.Nil(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+	reader := multipart.NewReader(w.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		content, _ := ioutil.ReadAll(part)
+		assert.Equal(t, "text/plain; charset=utf-8", part.Header.Get("Content-Type"))
+		parts = append(parts, part.Header.Get("Content-Range")+":"+string(content))
+	}
+	assert.Equal(t, []string{"bytes 0-4/38:hello", "bytes 33-37/38:world"}, parts)
+
+	// conditional requests
+	w = read("GET", map[string]string{"If-None-Match": `"` + randomBytesHash + `"`})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, 0, w.Body.Len())
+
+	w = read("GET", map[string]string{"If-Modified-Since": lastModified})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = read("GET", map[string]string{"Range": "bytes=0-4", "If-Range": `"` + randomBytesHash + `"`})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+
+	w = read("GET", map[string]string{"Range": "bytes=0-4", "If-Range": `"changed"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(randomBytes), w.Body.String())
+
+	// HEAD doesn't respond the content
+	w = read("HEAD", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, strconv.Itoa(len(randomBytes)), w.Header().Get("Content-Length"))
+	assert.Equal(t, 0, w.Body.Len())
 }
@@ -0,0 +1,123 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// fileRetentionInput is used by token, mode is one of 0(none), 1(governance)
+// and 2(compliance)
+type fileRetentionInput struct {
+	Token       string     `form:"token" binding:"required"`
+	FileUID     string     `form:"fileUid" binding:"required"`
+	Nonce       string     `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign        *string    `form:"sign" binding:"omitempty"`
+	Mode        *int8      `form:"mode" binding:"omitempty,oneof=0 1 2"`
+	RetainUntil *time.Time `form:"retainUntil" time_format:"unix" binding:"omitempty,gt"`
+	LegalHold   *bool      `form:"legalHold" binding:"omitempty"`
+}
+
+// fileRetentionAdminInput is used by app, it's able to shorten the retention
+// in governance mode and release legal hold
+type fileRetentionAdminInput struct {
+	AppUID      string     `form:"appUid" binding:"required"`
+	FileUID     string     `form:"fileUid" binding:"required"`
+	Nonce       string     `form:"nonce" header:"X-Request-Nonce" binding:"required,min=32,max=48"`
+	Sign        string     `form:"sign" binding:"required"`
+	Mode        *int8      `form:"mode" binding:"omitempty,oneof=0 1 2"`
+	RetainUntil *time.Time `form:"retainUntil" time_format:"unix" binding:"omitempty,gt"`
+	LegalHold   *bool      `form:"legalHold" binding:"omitempty"`
+}
+
+// FileRetentionHandler is used to set the retention and legal hold of file by token
+func FileRetentionHandler(ctx *gin.Context) {
+	var (
+		ip    = ctx.ClientIP()
+		input = ctx.MustGet("inputParam").(*fileRetentionInput)
+	)
+	fileRetention(ctx, input.FileUID, &service.FileRetention{
+		Token:       ctx.MustGet("token").(*models.Token),
+		IP:          &ip,
+		Mode:        input.Mode,
+		RetainUntil: input.RetainUntil,
+		LegalHold:   legalHoldValue(input.LegalHold),
+	})
+}
+
+// FileRetentionAdminHandler is used to set the retention and legal hold of file by app
+func FileRetentionAdminHandler(ctx *gin.Context) {
+	var input = ctx.MustGet("inputParam").(*fileRetentionAdminInput)
+	fileRetention(ctx, input.FileUID, &service.FileRetention{
+		App:         ctx.MustGet("app").(*models.App),
+		Mode:        input.Mode,
+		RetainUntil: input.RetainUntil,
+		LegalHold:   legalHoldValue(input.LegalHold),
+	})
+}
+
+func legalHoldValue(legalHold *bool) *int8 {
+	if legalHold == nil {
+		return nil
+	}
+	var value int8
+	if *legalHold {
+		value = 1
+	}
+	return &value
+}
+
+func fileRetention(ctx *gin.Context, fileUID string, fileRetentionSrv *service.FileRetention) {
+	var (
+		db                    = ctx.MustGet("db").(*gorm.DB)
+		err                   error
+		fileRetentionSrvValue interface{}
+
+		code     = 400
+		reErrors map[string][]string
+		success  bool
+		data     interface{}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	fileRetentionSrv.DB = db
+	if fileRetentionSrv.File, err = models.FindFileByUID(fileUID, false, db); err != nil {
+		reErrors = generateErrors(err, "fileUid")
+		return
+	}
+
+	if err = fileRetentionSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	if fileRetentionSrvValue, err = fileRetentionSrv.Execute(context.Background()); err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	if data, err = fileResp(fileRetentionSrvValue.(*models.File), db); err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	code = 200
+	success = true
+}
@@ -0,0 +1,73 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileRetentionHandler(t *testing.T) {
+	ctx, file, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	var (
+		mode        = models.RetentionCompliance
+		retainUntil = time.Now().Add(time.Hour)
+		legalHold   = true
+	)
+	ctx.Set("inputParam", &fileRetentionInput{
+		FileUID: file.UID, Mode: &mode, RetainUntil: &retainUntil, LegalHold: &legalHold})
+	FileRetentionHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	responseData := response.Data.(map[string]interface{})
+	assert.Equal(t, float64(models.RetentionCompliance), responseData["retentionMode"].(float64))
+	assert.Equal(t, float64(retainUntil.Unix()), responseData["retainUntil"].(float64))
+	assert.Equal(t, float64(1), responseData["legalHold"].(float64))
+
+	legalHold = false
+	writer.body.Reset()
+	ctx.Set("inputParam", &fileRetentionInput{FileUID: file.UID, LegalHold: &legalHold})
+	FileRetentionHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, service.ErrReleaseLegalHold.Error(), response.Errors["FileRetention.LegalHold"][0])
+}
+
+func TestFileRetentionAdminHandler(t *testing.T) {
+	ctx, file, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+	db := ctx.MustGet("db").(*gorm.DB)
+	token := ctx.MustGet("token").(*models.Token)
+	ctx.Set("app", &token.App)
+
+	retainUntil := time.Now().Add(time.Hour)
+	assert.Nil(t, file.SetRetention(models.RetentionGovernance, &retainUntil, false, db))
+	assert.Nil(t, file.SetLegalHold(true, db))
+
+	var (
+		mode      = models.RetentionNone
+		legalHold = false
+	)
+	ctx.Set("inputParam", &fileRetentionAdminInput{FileUID: file.UID, Mode: &mode, LegalHold: &legalHold})
+	FileRetentionAdminHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	responseData := response.Data.(map[string]interface{})
+	assert.Equal(t, float64(models.RetentionNone), responseData["retentionMode"].(float64))
+	assert.Nil(t, responseData["retainUntil"])
+	assert.Equal(t, float64(0), responseData["legalHold"].(float64))
+}
@@ -0,0 +1,115 @@
+//   Copyright 2019 The bigfile Authors. All rights reserved.
+//   Use of this source code is governed by a MIT-style
+//   license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type fileStatInput struct {
+	Token   string  `form:"token" binding:"required"`
+	FileUID string  `form:"fileUid" binding:"omitempty"`
+	Path    *string `form:"path" binding:"omitempty,max=1000"`
+	Nonce   *string `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign    *string `form:"sign" binding:"omitempty"`
+}
+
+// findInputFile is used to find the file by the path of input, which is
+// relative to the path of token, or by the uid of input. The key of error
+// is also returned.
+func findInputFile(token *models.Token, fileUID string, path *string, db *gorm.DB) (*models.File, string, error) {
+	var key = "fileUid"
+	if path != nil {
+		key = "path"
+	}
+	file, err := service.FindFile(token, fileUID, path, db)
+	return file, key, err
+}
+
+// FileStatHandler is used to get the metadata of file or directory. The
+// metadata is also set in the headers, so that HEAD request can be used.
+func FileStatHandler(ctx *gin.Context) {
+	var (
+		ip               = ctx.ClientIP()
+		db               = ctx.MustGet("db").(*gorm.DB)
+		err              error
+		key              string
+		file             *models.File
+		token            = ctx.MustGet("token").(*models.Token)
+		input            = ctx.MustGet("inputParam").(*fileStatInput)
+		fileStatSrv      *service.FileStat
+		fileStatSrvValue interface{}
+		fileStatResponse *service.FileStatResponse
+		header           = ctx.Writer.Header()
+		code             = 400
+		reErrors         map[string][]string
+		success          bool
+		data             map[string]interface{}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	if file, key, err = findInputFile(token, input.FileUID, input.Path, db); err != nil {
+		reErrors = generateErrors(err, key)
+		return
+	}
+
+	fileStatSrv = &service.FileStat{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		File:        file,
+		IP:          &ip,
+	}
+
+	if err = fileStatSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	if fileStatSrvValue, err = fileStatSrv.Execute(context.Background()); err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+	fileStatResponse = fileStatSrvValue.(*service.FileStatResponse)
+
+	if data, err = fileResp(file, db); err != nil {
+		reErrors = generateErrors(err, "system")
+		return
+	}
+	data["createdAt"] = file.CreatedAt.Unix()
+	data["updatedAt"] = file.UpdatedAt.Unix()
+
+	header.Set("X-File-Size", strconv.Itoa(file.Size))
+	header.Set("X-File-Is-Dir", strconv.Itoa(int(file.IsDir)))
+	header.Set("X-File-Hidden", strconv.Itoa(int(file.Hidden)))
+	header.Set("X-File-Created-At", strconv.FormatInt(file.CreatedAt.Unix(), 10))
+	header.Set("Last-Modified", file.UpdatedAt.UTC().Format(http.TimeFormat))
+	if file.IsDir == 0 {
+		data["mimeType"] = fileStatResponse.MimeType
+		data["versionCount"] = fileStatResponse.VersionCount
+		header.Set("ETag", `"`+file.Object.Hash+`"`)
+		header.Set("X-File-Hash", file.Object.Hash)
+		header.Set("X-File-Mime-Type", fileStatResponse.MimeType)
+		header.Set("X-File-Version-Count", strconv.Itoa(fileStatResponse.VersionCount))
+	}
+
+	success = true
+	code = 200
+}
@@ -0,0 +1,90 @@
+//   Copyright 2019 The bigfile Authors. All rights reserved.
+//   Use of this source code is governed by a MIT-style
+//   license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStatHandler(t *testing.T) {
+	var (
+		api     = buildRoute(config.DefaultConfig.HTTP.APIPrefix, "/file/stat")
+		tempDir = models.NewTempDirForTest()
+	)
+
+	testingChunkRootPath = &tempDir
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDBConn = trx
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	token.Path = "/scope"
+	assert.Nil(t, trx.Save(token).Error)
+
+	file, err := models.CreateFileFromReader(&token.App, "/scope/a.txt", bytes.NewReader([]byte("a")), int8(0), testingChunkRootPath, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, file.OverWriteFromReader(bytes.NewReader([]byte("abc")), models.Hidden, testingChunkRootPath, trx))
+
+	stat := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, fmt.Sprintf("%s?token=%s&path=%s", api, token.UID, url.QueryEscape(path)), nil)
+		Routers().ServeHTTP(w, req)
+		return w
+	}
+
+	w := stat("GET", "/a.txt")
+	assert.Equal(t, http.StatusOK, w.Code)
+	response, err := parseResponse(w.Body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, "/scope/a.txt", data["path"])
+	assert.Equal(t, float64(3), data["size"])
+	assert.Equal(t, float64(models.Hidden), data["hidden"])
+	assert.Equal(t, file.Object.Hash, data["hash"])
+	assert.Equal(t, "text/plain; charset=utf-8", data["mimeType"])
+	assert.Equal(t, float64(1), data["versionCount"])
+	assert.NotNil(t, data["createdAt"])
+	assert.NotNil(t, data["updatedAt"])
+
+	w = stat("HEAD", "/a.txt")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, "3", w.Header().Get("X-File-Size"))
+	assert.Equal(t, file.Object.Hash, w.Header().Get("X-File-Hash"))
+	assert.Equal(t, `"`+file.Object.Hash+`"`, w.Header().Get("ETag"))
+	assert.Equal(t, "1", w.Header().Get("X-File-Hidden"))
+	assert.Equal(t, "1", w.Header().Get("X-File-Version-Count"))
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("X-File-Mime-Type"))
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+
+	w = stat("GET", "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	response, err = parseResponse(w.Body.String())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1), response.Data.(map[string]interface{})["isDir"])
+	assert.Nil(t, response.Data.(map[string]interface{})["mimeType"])
+
+	w = stat("GET", "/scope/a.txt")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	response, err = parseResponse(w.Body.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "record not found", response.Errors["path"][0])
+}
@@ -7,6 +7,7 @@ package http
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"github.com/bigfile/bigfile/databases/models"
 	"github.com/bigfile/bigfile/service"
@@ -21,6 +22,9 @@ type fileUpdateInput struct {
 	Sign    *string `form:"sign" binding:"omitempty"`
 	Hidden  *int8   `form:"hidden" binding:"omitempty"`
 	Path    *string `form:"path" binding:"required,max=1000"`
+
+	// ExpiredAt is a unix timestamp, 0 removes the expiry of file
+	ExpiredAt *int64 `form:"expiredAt" binding:"omitempty,min=0"`
 }
 
 // FileUpdateHandler is used to handle file update request
@@ -65,6 +69,15 @@ func FileUpdateHandler(ctx *gin.Context) {
 		Hidden: input.Hidden,
 		Path:   input.Path,
 	}
+	fileUpdateSrv.IfMatch, fileUpdateSrv.IfNoneMatch = preconditionHeaders(ctx)
+
+	if input.ExpiredAt != nil {
+		expiredAt := time.Time{}
+		if *input.ExpiredAt > 0 {
+			expiredAt = time.Unix(*input.ExpiredAt, 0)
+		}
+		fileUpdateSrv.ExpiredAt = &expiredAt
+	}
 
 	if isTesting {
 		fileUpdateSrv.RootPath = testingChunkRootPath
@@ -76,6 +89,7 @@ func FileUpdateHandler(ctx *gin.Context) {
 	}
 
 	if fileUpdateSrvValue, err = fileUpdateSrv.Execute(context.Background()); err != nil {
+		code = preconditionCode(err)
 		reErrors = generateErrors(err, "")
 		return
 	}
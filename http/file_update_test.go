@@ -14,6 +14,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bigfile/bigfile/config"
 	"github.com/bigfile/bigfile/databases"
@@ -213,3 +214,48 @@ func BenchmarkFileUpdateHandler(b *testing.B) {
 		}
 	}
 }
+
+func TestFileUpdateHandlerWithPrecondition(t *testing.T) {
+	ctx, down := newFileUpdateForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	ctx.Request.Header.Set("If-None-Match", ctx.GetString("randomBytesHash"))
+	FileUpdateHandler(ctx)
+	assert.Equal(t, http.StatusPreconditionFailed, writer.Status())
+
+	writer.body.Reset()
+	ctx.Request.Header.Del("If-None-Match")
+	ctx.Request.Header.Set("If-Match", fmt.Sprintf(`"%s"`, ctx.GetString("randomBytesHash")))
+	FileUpdateHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	responseData := response.Data.(map[string]interface{})
+	assert.Equal(t, ctx.GetString("path"), responseData["path"].(string))
+}
+
+func TestFileUpdateHandlerWithExpiredAt(t *testing.T) {
+	ctx, down := newFileUpdateForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+
+	expiredAt := time.Now().Add(time.Hour).Unix()
+	input := ctx.MustGet("inputParam").(*fileUpdateInput)
+	input.ExpiredAt = &expiredAt
+
+	FileUpdateHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, float64(expiredAt), response.Data.(map[string]interface{})["expiredAt"].(float64))
+
+	writer.body.Reset()
+	expiredAt = 0
+	FileUpdateHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	_, ok := response.Data.(map[string]interface{})["expiredAt"]
+	assert.False(t, ok)
+}
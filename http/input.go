@@ -4,6 +4,14 @@
 
 package http
 
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+)
+
 // AppUIDInput represent 'AppUid' request param
 type AppUIDInput struct {
 	AppUID string `form:"appUid" binding:"required"`
@@ -18,3 +26,26 @@ type NonceInput struct {
 type TokenInput struct {
 	Token string `form:"token" binding:"required"`
 }
+
+// preconditionHeaders is used to get the value of If-Match and If-None-Match
+// headers, nil represent that the header is absent
+func preconditionHeaders(ctx *gin.Context) (ifMatch, ifNoneMatch *string) {
+	if values, ok := ctx.Request.Header["If-Match"]; ok {
+		value := strings.Join(values, ",")
+		ifMatch = &value
+	}
+	if values, ok := ctx.Request.Header["If-None-Match"]; ok {
+		value := strings.Join(values, ",")
+		ifNoneMatch = &value
+	}
+	return ifMatch, ifNoneMatch
+}
+
+// preconditionCode is used to get the http status code of the error that
+// returned from mutation service
+func preconditionCode(err error) int {
+	if err == service.ErrPreconditionFailed {
+		return http.StatusPreconditionFailed
+	}
+	return 400
+}
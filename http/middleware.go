@@ -10,11 +10,13 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/bigfile/bigfile/config"
 	"github.com/bigfile/bigfile/databases"
 	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/gorm"
 	janitor "github.com/json-iterator/go"
@@ -163,7 +165,8 @@ func ConfigContextMiddleware(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// SignWithAppMiddleware will validate request signature of request.
+// SignWithAppMiddleware will validate request signature of request, both
+// the v1 sign param and the v2 Authorization header are accepted.
 // It's should be put behind ParseAppMiddleware
 func SignWithAppMiddleware(input interface{}) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -179,21 +182,16 @@ func SignWithAppMiddleware(input interface{}) gin.HandlerFunc {
 		} else {
 			ctx.Set("inputParam", input)
 			app := ctx.MustGet("app").(*models.App)
-			if !ValidateRequestSignature(ctx, app.Secret) {
-				ctx.AbortWithStatusJSON(400, &Response{
-					RequestID: ctx.GetInt64("requestId"),
-					Success:   false,
-					Errors: map[string][]string{
-						"sign": {"request param sign error"},
-					},
-				})
+			if err := validateSignature(ctx, app.UID, app.Secret); err != nil {
+				abortWithSignError(ctx, err)
 			}
 		}
 		ctx.Next()
 	}
 }
 
-// SignWithTokenMiddleware will validate request signature of request.
+// SignWithTokenMiddleware will validate request signature of request, both
+// the v1 sign param and the v2 Authorization header are accepted.
 // It's should be put behind SignWithTokenMiddleware
 func SignWithTokenMiddleware(input interface{}) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -208,15 +206,17 @@ func SignWithTokenMiddleware(input interface{}) gin.HandlerFunc {
 
 		} else {
 			ctx.Set("inputParam", input)
-			token := ctx.MustGet("token").(*models.Token)
-			if token.Secret != nil && !ValidateRequestSignature(ctx, *token.Secret) {
-				ctx.AbortWithStatusJSON(400, &Response{
-					RequestID: ctx.GetInt64("requestId"),
-					Success:   false,
-					Errors: map[string][]string{
-						"sign": {"request param sign error"},
-					},
-				})
+			var (
+				err   error
+				token = ctx.MustGet("token").(*models.Token)
+			)
+			if service.IsSignatureV2(ctx.GetHeader("Authorization")) {
+				err = validateSignature(ctx, token.UID, service.SigningSecret(token))
+			} else if token.Secret != nil {
+				err = validateSignature(ctx, token.UID, *token.Secret)
+			}
+			if err != nil {
+				abortWithSignError(ctx, err)
 			}
 		}
 		ctx.Next()
@@ -302,13 +302,65 @@ func RateLimitByIPMiddleware(interval time.Duration, maxNumber int) gin.HandlerF
 	}
 }
 
+// RateLimitMiddleware is used to limit the request rate of app and token, the
+// limits are configured by config.Limit. Retry-After is set when the limit is
+// exceeded. It should be put behind the middlewares that verify the signature,
+// such as SignWithAppMiddleware, SignWithTokenMiddleware and PresignedMiddleware,
+// so that the unauthenticated requests can't use up the limits of app and token.
+func RateLimitMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var (
+			app     = ctx.MustGet("app").(*models.App)
+			tokenID uint64
+		)
+		if token, ok := ctx.Get("token"); ok {
+			tokenID = token.(*models.Token).ID
+		}
+		if retryAfter, err := service.DefaultRateLimiter().AllowRequest(app.ID, tokenID); err != nil {
+			abortTooManyRequests(ctx, retryAfter, err)
+		}
+		ctx.Next()
+	}
+}
+
+// SharedRateLimitMiddleware is used to limit the requests of public share
+// links by share link and ip, the ip is also refused after too many wrong
+// passwords of the share link, see service.RateLimiter.FailSharePassword.
+func SharedRateLimitMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		retryAfter, err := service.DefaultRateLimiter().AllowShareRequest(ctx.Param("shareUid"), ctx.ClientIP())
+		if err != nil {
+			abortTooManyRequests(ctx, retryAfter, err)
+		}
+		ctx.Next()
+	}
+}
+
+// abortTooManyRequests is used to reject the request that exceeds the rate limit
+func abortTooManyRequests(ctx *gin.Context, retryAfter time.Duration, err error) {
+	ctx.Header("Retry-After", strconv.FormatInt(service.RetryAfterSeconds(retryAfter), 10))
+	ctx.AbortWithStatusJSON(429, &Response{
+		RequestID: ctx.GetInt64("requestId"),
+		Success:   false,
+		Errors: map[string][]string{
+			"limitRate": {err.Error()},
+		},
+	})
+}
+
 // ReplayAttackMiddleware is used to avoid request replay attack.
 // We recommend that you should provide a 'nonce' value for request
 // in all of 'UPDATE' request. But for 'QUERY' request, you should
 // not add this. Of course, if you want to do this, bigfile allow that.
 //
-// For developers, this middleware should be put behind ParseAppMiddleware
-// or ParseTokenMiddleware, we need appId to validate this.
+// For developers, this middleware should be put behind SignWithAppMiddleware
+// or SignWithTokenMiddleware, we need appId to validate this, and the nonce
+// is only recorded after the signature and its date have been verified.
+//
+// The used nonces are kept in the nonce store, see config.Nonce. For the
+// requests that are signed by v2 signature, the nonce is remembered until
+// the signed date is out of the allowed clock skew, otherwise it's
+// remembered forever.
 func ReplayAttackMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var (
@@ -316,16 +368,21 @@ func ReplayAttackMiddleware() gin.HandlerFunc {
 			app       = ctx.MustGet("app").(*models.App)
 			reqRecord = ctx.MustGet("reqRecord").(*models.Request)
 			input     NonceInput
+			date      string
 			err       error
 		)
 		if err = ctx.ShouldBind(&input); err == nil {
 			if input.Nonce != nil {
-				if t, err := models.FindRequestWithAppAndNonce(app, *input.Nonce, db); err == nil && t.ID > 0 {
+				if service.IsSignatureV2(ctx.GetHeader("Authorization")) {
+					date = ctx.GetHeader(service.SignatureV2DateHeader)
+				}
+				expiredAt := service.NonceExpiredAt(date, time.Now())
+				if err = service.NewNonceStore(nil, db).Use(app.ID, *input.Nonce, expiredAt); err != nil {
 					ctx.AbortWithStatusJSON(400, &Response{
 						RequestID: ctx.GetInt64("requestId"),
 						Success:   false,
 						Errors: map[string][]string{
-							"nonce": {"this request is being replayed"},
+							"nonce": {err.Error()},
 						},
 					})
 				}
@@ -15,6 +15,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bigfile/bigfile/config"
 	"github.com/bigfile/bigfile/databases/models"
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/gorm"
@@ -217,6 +218,35 @@ func TestRateLimitByIPMiddleware(t *testing.T) {
 	assert.Contains(t, bw.body.String(), "too many requests")
 }
 
+func TestRateLimitMiddleware(t *testing.T) {
+	defer func(limit config.Limit) { config.DefaultConfig.Limit = limit }(config.DefaultConfig.Limit)
+	config.DefaultConfig.Limit = config.Limit{LimitTokenRequests: 1}
+
+	token, _, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	limit := func() (*gin.Context, *bodyWriter) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		bw := &bodyWriter{ResponseWriter: ctx.Writer, body: bytes.NewBufferString("")}
+		ctx.Writer = bw
+		ctx.Request, _ = http.NewRequest("GET", "http://bigfile.io", nil)
+		ctx.Set("app", &token.App)
+		ctx.Set("token", token)
+		RateLimitMiddleware()(ctx)
+		return ctx, bw
+	}
+
+	ctx, bw := limit()
+	assert.False(t, ctx.IsAborted())
+	assert.Equal(t, 0, bw.body.Len())
+
+	ctx, bw = limit()
+	assert.Equal(t, 429, ctx.Writer.Status())
+	assert.Equal(t, "1", ctx.Writer.Header().Get("Retry-After"))
+	assert.Contains(t, bw.body.String(), "too many requests")
+}
+
 func TestReplayAttackMiddleware(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
 	bw := &bodyWriter{ResponseWriter: ctx.Writer, body: bytes.NewBufferString("")}
@@ -236,29 +266,64 @@ func TestReplayAttackMiddleware(t *testing.T) {
 }
 
 func TestReplayAttackMiddleware2(t *testing.T) {
-	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
-	bw := &bodyWriter{ResponseWriter: ctx.Writer, body: bytes.NewBufferString("")}
-	ctx.Writer = bw
 	nonce := models.RandomWithMD5(128)
-	ctx.Request, _ = http.NewRequest("POST", "http://bigfile.io", strings.NewReader("nonce="+nonce))
-	ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 	app, db, down, err := models.NewAppForTest(nil, t)
 	assert.Nil(t, err)
 	defer down(t)
-	reqRecord := models.MustNewRequestWithProtocol("http", db)
-	reqRecord.Nonce = &nonce
-	reqRecord.AppID = &app.ID
-	assert.Nil(t, reqRecord.Save(db))
 
-	ctx.Set("db", db)
-	ctx.Set("app", app)
-	ctx.Set("reqRecord", reqRecord)
+	replay := func() (*gin.Context, *bodyWriter) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		bw := &bodyWriter{ResponseWriter: ctx.Writer, body: bytes.NewBufferString("")}
+		ctx.Writer = bw
+		ctx.Request, _ = http.NewRequest("POST", "http://bigfile.io", strings.NewReader("nonce="+nonce))
+		ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+		ctx.Set("db", db)
+		ctx.Set("app", app)
+		ctx.Set("reqRecord", models.MustNewRequestWithProtocol("http", db))
+		ReplayAttackMiddleware()(ctx)
+		return ctx, bw
+	}
 
-	ReplayAttackMiddleware()(ctx)
+	ctx, bw := replay()
+	assert.False(t, ctx.IsAborted())
+	assert.Equal(t, 0, bw.body.Len())
+	assert.Equal(t, nonce, *ctx.MustGet("reqRecord").(*models.Request).Nonce)
+
+	ctx, bw = replay()
 	assert.Equal(t, 400, ctx.Writer.Status())
 	assert.Contains(t, bw.body.String(), "this request is being replayed")
 }
 
+func TestReplayAttackMiddlewareBehindSignature(t *testing.T) {
+	app, trx, down, err := models.NewAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+	testDBConn = trx
+	router := Routers()
+
+	api := buildRoute(config.DefaultConfig.HTTP.APIPrefix, "/token/create")
+	body := fmt.Sprintf("appUid=%s&nonce=%s", app.UID, models.RandomWithMD5(128))
+	create := func(sign string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", api, strings.NewReader(body+"&sign="+sign))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// the nonce of request with wrong signature isn't recorded
+	w := create(SignStrWithSecret(body, "wrong"))
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), ErrRequestSign.Error())
+
+	w = create(SignStrWithSecret(body, app.Secret))
+	assert.Equal(t, 200, w.Code)
+
+	w = create(SignStrWithSecret(body, app.Secret))
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "this request is being replayed")
+}
+
 func TestBodyWriter_Write(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
 	bw := &bodyWriter{ResponseWriter: ctx.Writer, body: bytes.NewBufferString("")}
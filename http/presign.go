@@ -0,0 +1,203 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// ErrPresignedContentLength represent that the uploaded file exceeds the
+	// content length of presigned url
+	ErrPresignedContentLength = errors.New("the size of file exceeds the content length of presigned url")
+
+	// ErrPresignedContentType represent that the content type of uploaded file
+	// isn't the content type of presigned url
+	ErrPresignedContentType = errors.New("the content type of file doesn't match the presigned url")
+)
+
+// presignedRoute is the route of presigned urls, GET is used to download and
+// POST is used to upload
+const presignedRoute = "/presigned"
+
+// presignedMultipartOverhead is the size allowed for the multipart boundaries
+// and headers besides the file, when the content length is presigned
+const presignedMultipartOverhead = 1 << 20
+
+type presignInput struct {
+	Token         string  `form:"token" binding:"required"`
+	Nonce         *string `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign          *string `form:"sign" binding:"omitempty"`
+	Method        string  `form:"method" binding:"required,oneof=GET POST"`
+	FileUID       *string `form:"fileUid" binding:"omitempty"`
+	Path          *string `form:"path" binding:"omitempty,max=1000"`
+	ExpiresIn     int     `form:"expiresIn,default=3600" binding:"min=1,max=604800"`
+	ContentLength *int    `form:"contentLength" binding:"omitempty,min=0"`
+	ContentType   *string `form:"contentType" binding:"omitempty,max=255"`
+}
+
+// PresignHandler is used to mint a presigned url, the url in response is
+// relative to the host of service
+func PresignHandler(ctx *gin.Context) {
+	var (
+		err   error
+		value interface{}
+
+		code     = 400
+		reErrors map[string][]string
+		success  bool
+		data     interface{}
+
+		db         = ctx.MustGet("db").(*gorm.DB)
+		ip         = ctx.ClientIP()
+		input      = ctx.MustGet("inputParam").(*presignInput)
+		presignSrv = &service.Presign{
+			BaseService:   service.BaseService{DB: db},
+			Token:         ctx.MustGet("token").(*models.Token),
+			IP:            &ip,
+			Method:        input.Method,
+			FileUID:       input.FileUID,
+			Path:          input.Path,
+			ExpiresIn:     input.ExpiresIn,
+			ContentLength: input.ContentLength,
+			ContentType:   input.ContentType,
+		}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	if err = presignSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	if value, err = presignSrv.Execute(context.Background()); err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	params := value.(url.Values)
+	code = 200
+	success = true
+	data = map[string]interface{}{
+		"url":       brw(presignedRoute) + "?" + params.Encode(),
+		"method":    input.Method,
+		"expiresAt": params.Get("expires"),
+	}
+}
+
+// PresignedMiddleware is used to verify the presigned url, and prepare the
+// input params for FileReadHandler and FileCreateHandler. The presigned
+// requests don't go through ParseTokenMiddleware and SignWithTokenMiddleware,
+// but the token is still validated by the services, and the requests are
+// recorded like the others. It should be put behind RecordRequestMiddleware.
+func PresignedMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var (
+			err       error
+			presigned *service.Presigned
+			method    = ctx.Request.Method
+			db        = ctx.MustGet("db").(*gorm.DB)
+			reqRecord = ctx.MustGet("reqRecord").(*models.Request)
+		)
+
+		// HEAD is allowed with the presigned url of download
+		if method == http.MethodHead {
+			method = service.PresignMethodRead
+		}
+		presigned, err = service.ParsePresigned(ctx.Request.URL.Query(), method, db)
+		if presigned != nil && presigned.Token != nil && presigned.Token.ID != 0 {
+			reqRecord.Token = &presigned.Token.UID
+			reqRecord.AppID = &presigned.Token.AppID
+		}
+		if err == nil {
+			ctx.Set("app", &presigned.Token.App)
+			ctx.Set("token", presigned.Token)
+			if method == service.PresignMethodRead {
+				err = setPresignedReadInput(ctx, presigned, db)
+			} else {
+				err = setPresignedCreateInput(ctx, presigned)
+			}
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(400, &Response{
+				RequestID: ctx.GetInt64("requestId"),
+				Success:   false,
+				Errors:    generateErrors(err, "presigned"),
+			})
+		}
+		ctx.Next()
+	}
+}
+
+// setPresignedReadInput is used to prepare the input param of FileReadHandler,
+// the path of presigned url is relative to the path of token
+func setPresignedReadInput(ctx *gin.Context, presigned *service.Presigned, db *gorm.DB) error {
+	input := &fileReadInput{Token: presigned.Token.UID}
+	if presigned.FileUID != nil {
+		input.FileUID = *presigned.FileUID
+	} else {
+		file, err := models.FindFileByPath(&presigned.Token.App, presigned.Token.PathWithScope(*presigned.Path), db)
+		if err != nil {
+			return err
+		}
+		input.FileUID = file.UID
+	}
+	ctx.Set("inputParam", input)
+	return nil
+}
+
+// setPresignedCreateInput is used to prepare the input param of FileCreateHandler,
+// the file is required, and it's limited by the content length and type of
+// presigned url. The existing file is overwritten.
+func setPresignedCreateInput(ctx *gin.Context, presigned *service.Presigned) error {
+	var overwrite = true
+	if presigned.Path == nil {
+		return service.ErrPresignTarget
+	}
+	if presigned.ContentLength != nil {
+		var limit = int64(*presigned.ContentLength) + presignedMultipartOverhead
+		if ctx.Request.ContentLength > limit {
+			return ErrPresignedContentLength
+		}
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit)
+	}
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		if presigned.ContentLength != nil && strings.Contains(err.Error(), "request body too large") {
+			return ErrPresignedContentLength
+		}
+		return err
+	}
+	if presigned.ContentLength != nil && fh.Size > int64(*presigned.ContentLength) {
+		return ErrPresignedContentLength
+	}
+	if presigned.ContentType != nil && fh.Header.Get("Content-Type") != *presigned.ContentType {
+		return ErrPresignedContentType
+	}
+	ctx.Set("inputParam", &fileCreateInput{
+		Token:     presigned.Token.UID,
+		Path:      *presigned.Path,
+		Overwrite: &overwrite,
+	})
+	return nil
+}
@@ -0,0 +1,106 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresignHandler(t *testing.T) {
+	ctx, down := newFileReadForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+	fileUID := ctx.MustGet("inputParam").(*fileReadInput).FileUID
+
+	ctx.Set("inputParam", &presignInput{Method: service.PresignMethodRead, FileUID: &fileUID, ExpiresIn: 60})
+	PresignHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	responseData := response.Data.(map[string]interface{})
+	assert.Equal(t, service.PresignMethodRead, responseData["method"])
+	assert.True(t, strings.HasPrefix(responseData["url"].(string), brw(presignedRoute)+"?"))
+
+	writer.body.Reset()
+	ctx.Set("inputParam", &presignInput{Method: service.PresignMethodCreate, FileUID: &fileUID, ExpiresIn: 60})
+	PresignHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.Contains(t, response.Errors["Presign.Path"][0], service.ErrPresignTarget.Error())
+}
+
+func TestPresignedMiddleware(t *testing.T) {
+	ctx, down := newFileReadForTest(t)
+	defer down(t)
+	var (
+		db      = ctx.MustGet("db").(*gorm.DB)
+		token   = ctx.MustGet("token").(*models.Token)
+		fileUID = ctx.MustGet("inputParam").(*fileReadInput).FileUID
+		path    = "/random.png"
+	)
+
+	value, err := (&service.Presign{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		Method:      service.PresignMethodRead,
+		Path:        &path,
+		ExpiresIn:   60,
+	}).Execute(context.Background())
+	assert.Nil(t, err)
+	params := value.(url.Values)
+
+	ctx.Request, _ = http.NewRequest("GET", "http://bigfile.io"+brw(presignedRoute)+"?"+params.Encode(), nil)
+	ctx.Set("inputParam", nil)
+	PresignedMiddleware()(ctx)
+	assert.False(t, ctx.IsAborted())
+	assert.Equal(t, fileUID, ctx.MustGet("inputParam").(*fileReadInput).FileUID)
+	assert.Equal(t, token.UID, *ctx.MustGet("reqRecord").(*models.Request).Token)
+
+	params.Set("path", "/another.png")
+	ctx.Request, _ = http.NewRequest("GET", "http://bigfile.io"+brw(presignedRoute)+"?"+params.Encode(), nil)
+	PresignedMiddleware()(ctx)
+	assert.True(t, ctx.IsAborted())
+	assert.Equal(t, 400, ctx.Writer.Status())
+}
+
+func TestSetPresignedCreateInput(t *testing.T) {
+	var (
+		path          = "/random.png"
+		contentLength = 10
+		presigned     = &service.Presigned{Token: &models.Token{UID: "token"}, Path: &path, ContentLength: &contentLength}
+		body          = &bytes.Buffer{}
+		writer        = multipart.NewWriter(body)
+	)
+
+	part, err := writer.CreateFormFile("file", "random.png")
+	assert.Nil(t, err)
+	_, err = part.Write(models.Random(uint(presignedMultipartOverhead + 100)))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest("POST", "http://bigfile.io"+brw(presignedRoute), bytes.NewReader(body.Bytes()))
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	assert.Equal(t, ErrPresignedContentLength, setPresignedCreateInput(ctx, presigned))
+
+	ctx.Request, _ = http.NewRequest("POST", "http://bigfile.io"+brw(presignedRoute), bytes.NewReader(body.Bytes()))
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx.Request.ContentLength = -1
+	assert.Equal(t, ErrPresignedContentLength, setPresignedCreateInput(ctx, presigned))
+}
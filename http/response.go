@@ -5,6 +5,8 @@
 package http
 
 import (
+	"encoding/json"
+
 	"github.com/bigfile/bigfile/databases/models"
 	"github.com/bigfile/bigfile/service"
 	"github.com/jinzhu/gorm"
@@ -65,9 +67,11 @@ func tokenResp(token *models.Token) map[string]interface{} {
 func fileResp(file *models.File, db *gorm.DB) (map[string]interface{}, error) {
 
 	var (
-		err    error
-		path   string
-		result map[string]interface{}
+		err         error
+		path        string
+		locks       []models.FileLock
+		lockResults []map[string]interface{}
+		result      map[string]interface{}
 	)
 
 	if path, err = file.Path(db.Unscoped()); err != nil {
@@ -97,5 +101,119 @@ func fileResp(file *models.File, db *gorm.DB) (map[string]interface{}, error) {
 		result["deletedAt"] = file.DeletedAt.Unix()
 	}
 
+	if file.ExpiredAt != nil {
+		result["expiredAt"] = file.ExpiredAt.Unix()
+	}
+
+	result["retentionMode"] = file.RetentionMode
+	result["legalHold"] = file.LegalHold
+	if file.RetainUntil != nil {
+		result["retainUntil"] = file.RetainUntil.Unix()
+	}
+
+	if locks, err = file.Locks(db); err != nil {
+		return nil, err
+	}
+	lockResults = make([]map[string]interface{}, 0, len(locks))
+	for index := range locks {
+		lockResults = append(lockResults, fileLockResp(&locks[index]))
+	}
+	result["locks"] = lockResults
+
 	return result, err
 }
+
+// fileLockResp is used to generate file lock json response, the owner is not
+// included, because it's the uid of token.
+func fileLockResp(lock *models.FileLock) map[string]interface{} {
+	return map[string]interface{}{
+		"lockUid":   lock.UID,
+		"exclusive": lock.Exclusive,
+		"expiredAt": lock.ExpiredAt.Unix(),
+	}
+}
+
+// journalResp is used to generate the json response of an event of file tree
+func journalResp(journal *models.Journal) map[string]interface{} {
+	var result = map[string]interface{}{
+		"sequence":  journal.Sequence,
+		"event":     journal.Event,
+		"fileUid":   journal.FileUID,
+		"path":      journal.Path,
+		"isDir":     journal.IsDir,
+		"hidden":    journal.Hidden,
+		"size":      journal.Size,
+		"createdAt": journal.CreatedAt.Unix(),
+	}
+	if journal.PreviousPath != nil {
+		result["previousPath"] = *journal.PreviousPath
+	}
+	return result
+}
+
+// webhookResp is used to generate the json response of webhook
+func webhookResp(webhook *models.Webhook) map[string]interface{} {
+	var result = map[string]interface{}{
+		"webhookUid": webhook.UID,
+		"url":        webhook.URL,
+		"events":     webhook.EventList(),
+		"pathPrefix": webhook.PathPrefix,
+		"createdAt":  webhook.CreatedAt.Unix(),
+	}
+	if webhook.DeletedAt != nil {
+		result["deletedAt"] = webhook.DeletedAt.Unix()
+	}
+	return result
+}
+
+// webhookDeliveryResp is used to generate the json response of webhook delivery,
+// the payload is embedded as it is sent
+func webhookDeliveryResp(delivery *models.WebhookDelivery) map[string]interface{} {
+	var result = map[string]interface{}{
+		"deliveryUid":   delivery.UID,
+		"event":         delivery.Event,
+		"sequence":      delivery.Sequence,
+		"status":        delivery.Status,
+		"attempts":      delivery.Attempts,
+		"nextAttemptAt": delivery.NextAttemptAt.Unix(),
+		"payload":       json.RawMessage(delivery.Payload),
+		"createdAt":     delivery.CreatedAt.Unix(),
+	}
+	if delivery.ResponseCode != nil {
+		result["responseCode"] = *delivery.ResponseCode
+	}
+	if delivery.LastError != nil {
+		result["lastError"] = *delivery.LastError
+	}
+	if delivery.DeliveredAt != nil {
+		result["deliveredAt"] = delivery.DeliveredAt.Unix()
+	}
+	return result
+}
+
+// shareResp is used to generate the json response of share link, the hash of
+// password is never responded
+func shareResp(share *models.Share, db *gorm.DB) map[string]interface{} {
+	var result = map[string]interface{}{
+		"shareUid":      share.UID,
+		"url":           sharedURL(share),
+		"fileUid":       share.File.UID,
+		"isDir":         share.File.IsDir,
+		"hasPassword":   share.Password != nil,
+		"downloadCount": share.DownloadCount,
+		"createdAt":     share.CreatedAt.Unix(),
+	}
+	if path, err := share.File.Path(db); err == nil {
+		result["path"] = path
+	}
+	if share.ExpiredAt != nil {
+		result["expiredAt"] = share.ExpiredAt.Unix()
+	}
+	if share.MaxDownloads != nil {
+		result["maxDownloads"] = *share.MaxDownloads
+	}
+	if share.DeletedAt != nil {
+		result["deletedAt"] = share.DeletedAt.Unix()
+	}
+	return result
+}
@@ -41,7 +41,7 @@ func Routers() *gin.Engine {
 		}))
 	}
 
-	r.Use(ConfigContextMiddleware(nil), RecordRequestMiddleware())
+	r.Use(ConfigContextMiddleware(nil), RecordRequestMiddleware(), ContentHashMiddleware())
 
 	if config.DefaultConfig.HTTP.LimitRateByIPEnable {
 		interval := time.Duration(config.DefaultConfig.HTTP.LimitRateByIPInterval * int64(time.Millisecond))
@@ -49,18 +49,46 @@ func Routers() *gin.Engine {
 		r.Use(RateLimitByIPMiddleware(interval, int(maxNumber)))
 	}
 
-	requestWithAppGroup := r.Group("", ParseAppMiddleware(), ReplayAttackMiddleware())
-	requestWithAppGroup.POST(brw("/token/create"), SignWithAppMiddleware(&tokenCreateInput{}), TokenCreateHandler)
-	requestWithAppGroup.PATCH(brw("/token/update"), SignWithAppMiddleware(&tokenUpdateInput{}), TokenUpdateHandler)
-	requestWithAppGroup.DELETE(brw("/token/delete"), SignWithAppMiddleware(&tokenDeleteInput{}), TokenDeleteHandler)
-
-	requestWithTokenGroup := r.Group("", ParseTokenMiddleware(), ReplayAttackMiddleware())
-	requestWithTokenGroup.POST(brw("/file/create"), SignWithTokenMiddleware(&fileCreateInput{}), FileCreateHandler)
-	requestWithTokenGroup.GET(brw("/file/read"), SignWithTokenMiddleware(&fileReadInput{}), FileReadHandler)
-	requestWithTokenGroup.GET(brw("/image/convert"), SignWithTokenMiddleware(&ImageConvertInput{}), ImageConvertHandler)
-	requestWithTokenGroup.PATCH(brw("/file/update"), SignWithTokenMiddleware(&fileUpdateInput{}), FileUpdateHandler)
-	requestWithTokenGroup.DELETE(brw("/file/delete"), SignWithTokenMiddleware(&fileDeleteInput{}), FileDeleteHandler)
-	requestWithTokenGroup.GET(brw("/directory/list"), SignWithTokenMiddleware(&directoryListInput{}), DirectoryListHandler)
+	requestWithAppGroup := r.Group("", ParseAppMiddleware())
+	requestWithAppGroup.POST(brw("/token/create"), SignWithAppMiddleware(&tokenCreateInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), TokenCreateHandler)
+	requestWithAppGroup.PATCH(brw("/token/update"), SignWithAppMiddleware(&tokenUpdateInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), TokenUpdateHandler)
+	requestWithAppGroup.DELETE(brw("/token/delete"), SignWithAppMiddleware(&tokenDeleteInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), TokenDeleteHandler)
+	requestWithAppGroup.PATCH(brw("/file/retention/admin"), SignWithAppMiddleware(&fileRetentionAdminInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileRetentionAdminHandler)
+	requestWithAppGroup.POST(brw("/webhook/create"), SignWithAppMiddleware(&webhookCreateInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), WebhookCreateHandler)
+	requestWithAppGroup.GET(brw("/webhook/list"), SignWithAppMiddleware(&webhookListInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), WebhookListHandler)
+	requestWithAppGroup.DELETE(brw("/webhook/delete"), SignWithAppMiddleware(&webhookDeleteInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), WebhookDeleteHandler)
+	requestWithAppGroup.GET(brw("/webhook/deliveries"), SignWithAppMiddleware(&webhookDeliveryListInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), WebhookDeliveryListHandler)
+
+	requestWithTokenGroup := r.Group("", ParseTokenMiddleware())
+	requestWithTokenGroup.POST(brw("/file/create"), SignWithTokenMiddleware(&fileCreateInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileCreateHandler)
+	requestWithTokenGroup.GET(brw("/file/read"), SignWithTokenMiddleware(&fileReadInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileReadHandler)
+	requestWithTokenGroup.HEAD(brw("/file/read"), SignWithTokenMiddleware(&fileReadInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileReadHandler)
+	requestWithTokenGroup.GET(brw("/file/stat"), SignWithTokenMiddleware(&fileStatInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileStatHandler)
+	requestWithTokenGroup.HEAD(brw("/file/stat"), SignWithTokenMiddleware(&fileStatInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileStatHandler)
+	requestWithTokenGroup.GET(brw("/image/convert"), SignWithTokenMiddleware(&ImageConvertInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), ImageConvertHandler)
+	requestWithTokenGroup.PATCH(brw("/file/update"), SignWithTokenMiddleware(&fileUpdateInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileUpdateHandler)
+	requestWithTokenGroup.DELETE(brw("/file/delete"), SignWithTokenMiddleware(&fileDeleteInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileDeleteHandler)
+	requestWithTokenGroup.GET(brw("/directory/list"), SignWithTokenMiddleware(&directoryListInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), DirectoryListHandler)
+	requestWithTokenGroup.GET(brw("/directory/archive"), SignWithTokenMiddleware(&fileArchiveInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileArchiveHandler)
+	requestWithTokenGroup.GET(brw("/file/changes"), SignWithTokenMiddleware(&changeFeedInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), ChangeFeedHandler)
+	requestWithTokenGroup.POST(brw("/file/lock/acquire"), SignWithTokenMiddleware(&fileLockAcquireInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileLockAcquireHandler)
+	requestWithTokenGroup.PATCH(brw("/file/lock/refresh"), SignWithTokenMiddleware(&fileLockRefreshInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileLockRefreshHandler)
+	requestWithTokenGroup.DELETE(brw("/file/lock/release"), SignWithTokenMiddleware(&fileLockReleaseInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileLockReleaseHandler)
+	requestWithTokenGroup.POST(brw("/file/batch"), SignWithTokenMiddleware(&fileBatchInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileBatchHandler)
+	requestWithTokenGroup.POST(brw("/file/extract"), SignWithTokenMiddleware(&fileExtractInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileExtractHandler)
+	requestWithTokenGroup.PATCH(brw("/file/retention"), SignWithTokenMiddleware(&fileRetentionInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), FileRetentionHandler)
+	requestWithTokenGroup.POST(brw("/presign"), SignWithTokenMiddleware(&presignInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), PresignHandler)
+	requestWithTokenGroup.POST(brw("/share/create"), SignWithTokenMiddleware(&shareCreateInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), ShareCreateHandler)
+	requestWithTokenGroup.GET(brw("/share/list"), SignWithTokenMiddleware(&shareListInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), ShareListHandler)
+	requestWithTokenGroup.DELETE(brw("/share/revoke"), SignWithTokenMiddleware(&shareRevokeInput{}), RateLimitMiddleware(), ReplayAttackMiddleware(), ShareRevokeHandler)
+
+	presignedGroup := r.Group("", PresignedMiddleware(), RateLimitMiddleware())
+	presignedGroup.GET(brw(presignedRoute), FileReadHandler)
+	presignedGroup.HEAD(brw(presignedRoute), FileReadHandler)
+	presignedGroup.POST(brw(presignedRoute), FileCreateHandler)
+
+	r.GET(brw(sharedRoute+"/:shareUid"), SharedRateLimitMiddleware(), SharedHandler)
+	r.HEAD(brw(sharedRoute+"/:shareUid"), SharedRateLimitMiddleware(), SharedHandler)
 
 	return r
 }
@@ -90,3 +90,31 @@ func TestRouters3(t *testing.T) {
 	}
 	config.DefaultConfig.AccessLogFile = ""
 }
+
+func TestRoutersRateLimitAfterSignature(t *testing.T) {
+	var (
+		api = buildRoute(config.DefaultConfig.HTTP.APIPrefix, "/token/delete")
+	)
+	defer func(limit config.Limit) { config.DefaultConfig.Limit = limit }(config.DefaultConfig.Limit)
+	config.DefaultConfig.Limit = config.Limit{LimitAppRequests: 1}
+
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+	testDBConn = trx
+
+	// the requests with wrong signature don't take the limit of app
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		params := map[string]interface{}{"appUid": token.App.UID, "token": token.UID}
+		req, _ := http.NewRequest("DELETE", fmt.Sprintf("%s?%s", api, getParamsSignBody(params, "wrong")), nil)
+		Routers().ServeHTTP(w, req)
+		assert.Equal(t, 400, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	params := map[string]interface{}{"appUid": token.App.UID, "token": token.UID}
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("%s?%s", api, getParamsSignBody(params, token.App.Secret)), nil)
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,346 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/log"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// sharedRoute is the public route of share links, it's followed by the uid
+// of share link
+const sharedRoute = "/shared"
+
+type shareCreateInput struct {
+	Token        string     `form:"token" binding:"required"`
+	Nonce        *string    `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign         *string    `form:"sign" binding:"omitempty"`
+	FileUID      string     `form:"fileUid" binding:"required"`
+	Password     *string    `form:"password" binding:"omitempty,min=1,max=72"`
+	ExpiredAt    *time.Time `form:"expiredAt" time_format:"unix" binding:"omitempty,gt"`
+	MaxDownloads *uint64    `form:"maxDownloads" binding:"omitempty,min=1"`
+}
+
+type shareListInput struct {
+	Token  string  `form:"token" binding:"required"`
+	Nonce  *string `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign   *string `form:"sign" binding:"omitempty"`
+	Offset int     `form:"offset,default=0" binding:"min=0"`
+	Limit  int     `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+type shareRevokeInput struct {
+	Token    string  `form:"token" binding:"required"`
+	Nonce    *string `form:"nonce" header:"X-Request-Nonce" binding:"omitempty,min=32,max=48"`
+	Sign     *string `form:"sign" binding:"omitempty"`
+	ShareUID string  `form:"shareUid" binding:"required"`
+}
+
+// sharedInput is the input of public route, the password can also be sent
+// by X-Share-Password header, so that it doesn't appear in the url. Path is
+// relative to the shared directory.
+type sharedInput struct {
+	Password      string `form:"password"`
+	Path          string `form:"path,default=/" binding:"max=1000"`
+	Archive       bool   `form:"archive,default=0"`
+	OpenInBrowser bool   `form:"openInBrowser,default=0"`
+	Offset        int    `form:"offset,default=0" binding:"min=0"`
+	Limit         int    `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+// ShareCreateHandler is used to create a share link of file or directory
+func ShareCreateHandler(ctx *gin.Context) {
+	var (
+		ip    = ctx.ClientIP()
+		db    = ctx.MustGet("db").(*gorm.DB)
+		input = ctx.MustGet("inputParam").(*shareCreateInput)
+	)
+	file, err := models.FindFileByUID(input.FileUID, false, db)
+	if err != nil {
+		ctx.JSON(400, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   false,
+			Errors:    generateErrors(err, "fileUid"),
+		})
+		return
+	}
+	executeService(ctx, &service.ShareCreate{
+		BaseService:  service.BaseService{DB: db},
+		Token:        ctx.MustGet("token").(*models.Token),
+		IP:           &ip,
+		File:         file,
+		Password:     input.Password,
+		ExpiredAt:    input.ExpiredAt,
+		MaxDownloads: input.MaxDownloads,
+	}, func(value interface{}) interface{} {
+		return shareResp(value.(*models.Share), db)
+	})
+}
+
+// ShareListHandler is used to list the share links in the path of token
+func ShareListHandler(ctx *gin.Context) {
+	var (
+		ip    = ctx.ClientIP()
+		db    = ctx.MustGet("db").(*gorm.DB)
+		input = ctx.MustGet("inputParam").(*shareListInput)
+	)
+	executeService(ctx, &service.ShareList{
+		BaseService: service.BaseService{DB: db},
+		Token:       ctx.MustGet("token").(*models.Token),
+		IP:          &ip,
+		Offset:      input.Offset,
+		Limit:       input.Limit,
+	}, func(value interface{}) interface{} {
+		response := value.(*service.ShareListResponse)
+		shares := make([]map[string]interface{}, 0, len(response.Shares))
+		for index := range response.Shares {
+			shares = append(shares, shareResp(&response.Shares[index], db))
+		}
+		return map[string]interface{}{
+			"total":  response.Total,
+			"pages":  response.Pages,
+			"shares": shares,
+		}
+	})
+}
+
+// ShareRevokeHandler is used to revoke a share link
+func ShareRevokeHandler(ctx *gin.Context) {
+	var (
+		ip    = ctx.ClientIP()
+		db    = ctx.MustGet("db").(*gorm.DB)
+		input = ctx.MustGet("inputParam").(*shareRevokeInput)
+	)
+	share, err := models.FindShareByUID(input.ShareUID, db)
+	if err != nil {
+		ctx.JSON(400, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   false,
+			Errors:    generateErrors(err, "shareUid"),
+		})
+		return
+	}
+	executeService(ctx, &service.ShareRevoke{
+		BaseService: service.BaseService{DB: db},
+		Token:       ctx.MustGet("token").(*models.Token),
+		IP:          &ip,
+		Share:       share,
+	}, func(value interface{}) interface{} {
+		share := value.(*models.Share)
+		db.Unscoped().First(share)
+		return shareResp(share, db)
+	})
+}
+
+// countedRange represent whether the request of range is counted as a
+// download. Only a single range that starts after the beginning of file isn't
+// counted, it's served as a part of file. http.ServeContent may serve the whole
+// file for the others, such as the ranges that overlap or sum to more than
+// the file, the suffix ranges that cover the file, and the stale If-Range.
+func countedRange(rangeHeader, ifRange string) bool {
+	if rangeHeader == "" || ifRange != "" || !strings.HasPrefix(rangeHeader, "bytes=") {
+		return true
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(rangeHeader, "bytes="))
+	if strings.Contains(spec, ",") {
+		return true
+	}
+	bounds := strings.SplitN(spec, "-", 2)
+	if len(bounds) != 2 {
+		return true
+	}
+	start, err := strconv.ParseInt(strings.TrimSpace(bounds[0]), 10, 64)
+	if err != nil || start <= 0 {
+		return true
+	}
+	if end := strings.TrimSpace(bounds[1]); end != "" {
+		if last, err := strconv.ParseInt(end, 10, 64); err != nil || last < start {
+			return true
+		}
+	}
+	return false
+}
+
+// SharedHandler is the public route of share links, it doesn't require the
+// credentials of api. A file is downloaded, and a directory is listed, or
+// downloaded as a zip archive if archive is true.
+func SharedHandler(ctx *gin.Context) {
+	var (
+		err       error
+		value     interface{}
+		share     *models.Share
+		db        = ctx.MustGet("db").(*gorm.DB)
+		reqRecord = ctx.MustGet("reqRecord").(*models.Request)
+		requestID = ctx.GetInt64("requestId")
+		input     = &sharedInput{}
+	)
+
+	respondError := func(err error, key string) {
+		ctx.JSON(400, &Response{
+			RequestID: requestID,
+			Success:   false,
+			Errors:    generateErrors(err, key),
+		})
+	}
+
+	if err = ctx.ShouldBind(input); err != nil {
+		respondError(err, "")
+		return
+	}
+	if password := ctx.GetHeader("X-Share-Password"); password != "" {
+		input.Password = password
+	}
+	// the password isn't recorded with the request
+	ctx.Request.Header.Del("X-Share-Password")
+	ctx.Request.Form.Del("password")
+
+	if share, err = models.FindShareByUID(ctx.Param("shareUid"), db); err != nil {
+		respondError(service.ErrInvalidShare, "shareUid")
+		return
+	}
+	reqRecord.AppID = &share.AppID
+
+	if input.Archive {
+		sharedArchive(ctx, share, input)
+		return
+	}
+
+	downloadSrv := &service.ShareDownload{
+		BaseService: service.BaseService{DB: db},
+		Share:       share,
+		Password:    input.Password,
+		Path:        input.Path,
+		Count:       ctx.Request.Method != http.MethodHead && countedRange(ctx.GetHeader("Range"), ctx.GetHeader("If-Range")),
+	}
+	if isTesting {
+		downloadSrv.RootPath = testingChunkRootPath
+	}
+	if err = downloadSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		failSharePassword(ctx, share, err)
+		respondError(err, "")
+		return
+	}
+	if value, err = downloadSrv.Execute(ctx.Request.Context()); err == models.ErrReadDir {
+		executeService(ctx, &service.ShareBrowse{
+			BaseService: service.BaseService{DB: db},
+			Share:       share,
+			Password:    input.Password,
+			Path:        input.Path,
+			Offset:      input.Offset,
+			Limit:       input.Limit,
+		}, func(value interface{}) interface{} {
+			return sharedListResp(value.(*service.DirectoryListResponse), share)
+		})
+		return
+	}
+	if err != nil {
+		respondError(err, "")
+		return
+	}
+	response := value.(*service.ShareDownloadResponse)
+	readContent(ctx, response.Reader, response.File, &fileReadInput{OpenInBrowser: input.OpenInBrowser})
+}
+
+// failSharePassword is used to count the wrong password of share link, so
+// that the password can't be guessed by brute force
+func failSharePassword(ctx *gin.Context, share *models.Share, err error) {
+	for _, validateErr := range err.(service.ValidateErrors) {
+		if validateErr.Exception == models.ErrSharePassword {
+			if err := service.DefaultRateLimiter().FailSharePassword(share.UID, ctx.ClientIP()); err != nil {
+				log.MustNewLogger(nil).Errorf("request %d: can't count wrong password: %s", ctx.GetInt64("requestId"), err)
+			}
+			return
+		}
+	}
+}
+
+// sharedArchive is used to download the directory of share link as a zip archive
+func sharedArchive(ctx *gin.Context, share *models.Share, input *sharedInput) {
+	var (
+		err       error
+		db        = ctx.MustGet("db").(*gorm.DB)
+		requestID = ctx.GetInt64("requestId")
+		name      = path.Base(path.Join(share.File.FullPath, input.Path))
+		writer    *archiveResponseWriter
+	)
+	if name == "/" {
+		name = "archive"
+	}
+	writer = &archiveResponseWriter{ctx: ctx, filename: name + "." + service.ArchiveZip}
+	archiveSrv := &service.ShareArchive{
+		BaseService: service.BaseService{DB: db},
+		Share:       share,
+		Password:    input.Password,
+		Path:        input.Path,
+		Writer:      writer,
+	}
+	if isTesting {
+		archiveSrv.RootPath = testingChunkRootPath
+	}
+	if err = archiveSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		failSharePassword(ctx, share, err)
+		ctx.JSON(400, &Response{
+			RequestID: requestID,
+			Success:   false,
+			Errors:    generateErrors(err, ""),
+		})
+		return
+	}
+	if _, err = archiveSrv.Execute(ctx.Request.Context()); err != nil {
+		if !writer.written {
+			ctx.JSON(400, &Response{
+				RequestID: requestID,
+				Success:   false,
+				Errors:    generateErrors(err, ""),
+			})
+			return
+		}
+		log.MustNewLogger(nil).Errorf("request %d: archive is interrupted: %s", requestID, err)
+		ctx.Abort()
+	}
+}
+
+// sharedListResp is used to generate the json response of shared directory,
+// the paths are relative to the shared directory, so that the real paths
+// aren't exposed
+func sharedListResp(response *service.DirectoryListResponse, share *models.Share) map[string]interface{} {
+	var items = make([]map[string]interface{}, 0, len(response.Files))
+	for index := range response.Files {
+		file := &response.Files[index]
+		item := map[string]interface{}{
+			"name":      file.Name,
+			"path":      "/" + strings.TrimPrefix(strings.TrimPrefix(file.FullPath, share.File.FullPath), "/"),
+			"size":      file.Size,
+			"isDir":     file.IsDir,
+			"updatedAt": file.UpdatedAt.Unix(),
+		}
+		if file.IsDir == 0 {
+			item["hash"] = file.Object.Hash
+			item["ext"] = file.Ext
+		}
+		items = append(items, item)
+	}
+	return map[string]interface{}{
+		"total": response.Total,
+		"pages": response.Pages,
+		"items": items,
+	}
+}
+
+// sharedURL return the public url of share link, it's relative to the host
+// of service
+func sharedURL(share *models.Share) string {
+	return brw(sharedRoute) + "/" + share.UID
+}
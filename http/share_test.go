@@ -0,0 +1,156 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountedRange(t *testing.T) {
+	assert.True(t, countedRange("", ""))
+	assert.True(t, countedRange("bytes=0-", ""))
+	assert.True(t, countedRange("bytes=0-100", ""))
+	assert.False(t, countedRange("bytes=100-", ""))
+	assert.False(t, countedRange("bytes=100-200", ""))
+	assert.True(t, countedRange("bytes=100-", `"etag"`))
+	assert.True(t, countedRange("bytes=-100", ""))
+	assert.True(t, countedRange("bytes=1-,0-", ""))
+	assert.True(t, countedRange("bytes=100-50", ""))
+	assert.True(t, countedRange("items=100-", ""))
+	assert.True(t, countedRange("bytes=a-", ""))
+}
+
+func TestSharedHandlerMultiRange(t *testing.T) {
+	var (
+		tempDir      = models.NewTempDirForTest()
+		maxDownloads = uint64(1)
+		api          = buildRoute(config.DefaultConfig.HTTP.APIPrefix, sharedRoute)
+	)
+	testingChunkRootPath = &tempDir
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDBConn = trx
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := models.CreateFileFromReader(&token.App, "/share/range.txt", bytes.NewReader([]byte("share")), 0, &tempDir, trx)
+	assert.Nil(t, err)
+	share, err := models.NewShare(token, file, nil, nil, &maxDownloads, trx)
+	assert.Nil(t, err)
+
+	// the ranges sum to more than the file, the whole file is served
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", api+"/"+share.UID, nil)
+	req.Header.Set("Range", "bytes=1-,0-")
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "share", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", api+"/"+share.UID, nil)
+	req.Header.Set("Range", "bytes=1-,0-")
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	response, err := parseResponse(w.Body.String())
+	assert.Nil(t, err)
+	assert.Equal(t, models.ErrShareDownloadsExhausted.Error(), response.Errors["ShareDownload.Share"][0])
+}
+
+func TestSharedHandler(t *testing.T) {
+	var (
+		tempDir  = models.NewTempDirForTest()
+		password = "password"
+		api      = buildRoute(config.DefaultConfig.HTTP.APIPrefix, sharedRoute)
+	)
+	testingChunkRootPath = &tempDir
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDBConn = trx
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := models.CreateFileFromReader(&token.App, "/share/a.txt", bytes.NewReader([]byte("share")), 0, &tempDir, trx)
+	assert.Nil(t, err)
+	dir, err := models.FindFileByPath(&token.App, "/share", trx)
+	assert.Nil(t, err)
+	share, err := models.NewShare(token, dir, &password, nil, nil, trx)
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", api+"/"+share.UID+"?path=/a.txt", nil)
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	response, err := parseResponse(w.Body.String())
+	assert.Nil(t, err)
+	assert.Equal(t, models.ErrSharePassword.Error(), response.Errors["ShareDownload.Share"][0])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", api+"/"+share.UID+"?path=/a.txt", nil)
+	req.Header.Set("X-Share-Password", password)
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "share", w.Body.String())
+	file, err = models.FindFileByUID(file.UID, false, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), file.DownloadCount)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", api+"/"+share.UID+"?password="+password, nil)
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	response, err = parseResponse(w.Body.String())
+	assert.Nil(t, err)
+	items := response.Data.(map[string]interface{})["items"].([]interface{})
+	assert.Equal(t, "/a.txt", items[0].(map[string]interface{})["path"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", api+"/"+share.UID+"?archive=1&password="+password, nil)
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	reader, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, "a.txt", reader.File[0].Name)
+
+	// the ip is refused after the wrong passwords are used up
+	defer func(limit config.Limit) { config.DefaultConfig.Limit = limit }(config.DefaultConfig.Limit)
+	config.DefaultConfig.LimitSharePasswordFailures = 1
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", api+"/"+share.UID+"?password=wrong", nil)
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", api+"/"+share.UID+"?password="+password, nil)
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, 429, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	assert.Nil(t, share.Revoke(trx))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", api+"/"+share.UID, nil)
+	Routers().ServeHTTP(w, req)
+	response, err = parseResponse(w.Body.String())
+	assert.Nil(t, err)
+	assert.Equal(t, service.ErrInvalidShare.Error(), response.Errors["shareUid"][0])
+}
@@ -0,0 +1,141 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrRequestSign represent that the v1 signature of request is wrong
+var ErrRequestSign = errors.New("request param sign error")
+
+// ErrSignedBodyTooLarge represent that the body of the request signed by v2
+// signature is larger than config.HTTP.SignedBodyMaxSize
+var ErrSignedBodyTooLarge = errors.New("the body of signed request is too large")
+
+// contentHashBody is used to hash the request body while it's read, so that
+// the body of upload isn't buffered for v2 signature. At most maxSize bytes
+// can be read, zero means unlimited.
+type contentHashBody struct {
+	io.ReadCloser
+	hash    hash.Hash
+	size    int64
+	maxSize int64
+}
+
+func (b *contentHashBody) Read(p []byte) (n int, err error) {
+	if b.maxSize > 0 {
+		if b.size > b.maxSize {
+			return 0, ErrSignedBodyTooLarge
+		}
+		// read one more byte than allowed, so the larger body can be found
+		if rest := b.maxSize - b.size + 1; int64(len(p)) > rest {
+			p = p[:rest]
+		}
+	}
+	n, err = b.ReadCloser.Read(p)
+	if b.size += int64(n); b.maxSize > 0 && b.size > b.maxSize {
+		return 0, ErrSignedBodyTooLarge
+	}
+	_, _ = b.hash.Write(p[:n])
+	return n, err
+}
+
+// sum is used to read the rest of body without buffering it, and return the
+// hex encoded sha256
+func (b *contentHashBody) sum() (string, error) {
+	if _, err := io.Copy(ioutil.Discard, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b.hash.Sum(nil)), nil
+}
+
+// ContentHashMiddleware is used to hash the body of the requests that are
+// signed by v2 signature. It's should be put before any middleware that
+// reads the body, such as ParseAppMiddleware and ParseTokenMiddleware.
+func ContentHashMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if service.IsSignatureV2(ctx.GetHeader("Authorization")) {
+			body := &contentHashBody{
+				ReadCloser: ctx.Request.Body,
+				hash:       sha256.New(),
+				maxSize:    config.DefaultConfig.HTTP.SignedBodyMaxSize,
+			}
+			if body.ReadCloser == nil {
+				body.ReadCloser = http.NoBody
+			}
+			ctx.Request.Body = body
+			ctx.Set("contentHashBody", body)
+		}
+		ctx.Next()
+	}
+}
+
+// validateSignature is used to validate the signature of request. The v2
+// signature in Authorization header is used if it's present, credential is
+// the uid of token or app that the request belongs to. Otherwise, it falls
+// back to the v1 signature in sign param.
+func validateSignature(ctx *gin.Context, credential, secret string) error {
+	var (
+		err         error
+		signature   *service.SignatureV2
+		contentHash string
+		header      = http.Header{}
+	)
+	if !service.IsSignatureV2(ctx.GetHeader("Authorization")) {
+		if ValidateRequestSignature(ctx, secret) {
+			return nil
+		}
+		return ErrRequestSign
+	}
+	if signature, err = service.ParseSignatureV2(ctx.GetHeader("Authorization")); err != nil {
+		return err
+	}
+	if signature.Credential != credential {
+		return service.ErrSignatureV2Credential
+	}
+	if body, ok := ctx.Get("contentHashBody"); ok {
+		if contentHash, err = body.(*contentHashBody).sum(); err != nil {
+			return err
+		}
+	} else {
+		contentHash = service.ContentHash(nil)
+	}
+	for key, values := range ctx.Request.Header {
+		header[key] = values
+	}
+	header.Set("Host", ctx.Request.Host)
+	return signature.Verify(&service.SignatureV2Request{
+		Method:      ctx.Request.Method,
+		Path:        ctx.Request.URL.EscapedPath(),
+		Query:       ctx.Request.URL.Query(),
+		Header:      header,
+		ContentHash: contentHash,
+	}, secret, time.Now())
+}
+
+// abortWithSignError is used to respond the error of signature, the accepted
+// schemes are advertised, so that clients can negotiate the version
+func abortWithSignError(ctx *gin.Context, err error) {
+	ctx.Header("WWW-Authenticate", service.SignatureV2Algorithm)
+	ctx.AbortWithStatusJSON(400, &Response{
+		RequestID: ctx.GetInt64("requestId"),
+		Success:   false,
+		Errors: map[string][]string{
+			"sign": {err.Error()},
+		},
+	})
+}
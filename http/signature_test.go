@@ -0,0 +1,161 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// signRequestV2 is used to sign request by v2 signature, the body is signed
+// by its hash, so that it can be replaced after signing
+func signRequestV2(req *http.Request, body []byte, credential, secret string) {
+	req.Header.Set(service.SignatureV2DateHeader, strconv.FormatInt(time.Now().Unix(), 10))
+	req.Header.Set(service.SignatureV2ContentHashHeader, service.ContentHash(body))
+	header := req.Header
+	header.Set("Host", req.Host)
+	signature := service.NewSignatureV2(&service.SignatureV2Request{
+		Method:      req.Method,
+		Path:        req.URL.EscapedPath(),
+		Query:       req.URL.Query(),
+		Header:      header,
+		ContentHash: service.ContentHash(body),
+	}, credential, []string{"host", "x-bigfile-content-sha256", "x-bigfile-date"}, secret)
+	header.Del("Host")
+	req.Header.Set("Authorization", signature.Authorization())
+}
+
+func TestContentHashBody(t *testing.T) {
+	body := &contentHashBody{ReadCloser: ioutil.NopCloser(strings.NewReader("hello world")), hash: sha256.New()}
+	p := make([]byte, 5)
+	_, err := body.Read(p)
+	assert.Nil(t, err)
+	sum, err := body.sum()
+	assert.Nil(t, err)
+	assert.Equal(t, service.ContentHash([]byte("hello world")), sum)
+
+	body = &contentHashBody{ReadCloser: ioutil.NopCloser(strings.NewReader("hello world")), hash: sha256.New(), maxSize: 11}
+	sum, err = body.sum()
+	assert.Nil(t, err)
+	assert.Equal(t, service.ContentHash([]byte("hello world")), sum)
+
+	body = &contentHashBody{ReadCloser: ioutil.NopCloser(strings.NewReader("hello world")), hash: sha256.New(), maxSize: 10}
+	_, err = body.sum()
+	assert.Equal(t, ErrSignedBodyTooLarge, err)
+	_, err = body.Read(p)
+	assert.Equal(t, ErrSignedBodyTooLarge, err)
+}
+
+func TestSignatureV2WithToken(t *testing.T) {
+	var (
+		api     = "http://bigfile.io" + buildRoute(config.DefaultConfig.HTTP.APIPrefix, "/file/create")
+		secret  = models.RandomWithMD5(122)
+		tempDir = models.NewTempDirForTest()
+	)
+	testingChunkRootPath = &tempDir
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDBConn = trx
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	token.Secret = &secret
+	assert.Nil(t, trx.Save(token).Error)
+
+	upload := func(content string, sign func(req *http.Request, body []byte)) (*httptest.ResponseRecorder, *Response) {
+		var (
+			w      = httptest.NewRecorder()
+			body   = &bytes.Buffer{}
+			writer = multipart.NewWriter(body)
+		)
+		fileWriter, err := writer.CreateFormFile("file", "random.bytes")
+		assert.Nil(t, err)
+		_, err = fileWriter.Write([]byte(content))
+		assert.Nil(t, err)
+		assert.Nil(t, writer.Close())
+
+		query := url.Values{"token": {token.UID}, "path": {"/v2/file.bytes"}, "overwrite": {"1"}}
+		req, _ := http.NewRequest("POST", api+"?"+query.Encode(), bytes.NewReader(body.Bytes()))
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		sign(req, body.Bytes())
+		Routers().ServeHTTP(w, req)
+		response, err := parseResponse(w.Body.String())
+		assert.Nil(t, err)
+		return w, response
+	}
+
+	w, response := upload("signed", func(req *http.Request, body []byte) {
+		signRequestV2(req, body, token.UID, secret)
+	})
+	assert.Equal(t, 200, w.Code)
+	assert.True(t, response.Success)
+	assert.Equal(t, float64(6), response.Data.(map[string]interface{})["size"])
+
+	// the body is replaced after signing
+	w, response = upload("tampered", func(req *http.Request, body []byte) {
+		signRequestV2(req, bytes.Replace(body, []byte("tampered"), []byte("signed"), 1), token.UID, secret)
+	})
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, service.ErrSignatureV2ContentHash.Error(), response.Errors["sign"][0])
+	assert.Equal(t, service.SignatureV2Algorithm, w.Header().Get("WWW-Authenticate"))
+
+	w, response = upload("signed", func(req *http.Request, body []byte) {
+		signRequestV2(req, body, token.App.UID, secret)
+	})
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, service.ErrSignatureV2Credential.Error(), response.Errors["sign"][0])
+
+	w, response = upload("signed", func(req *http.Request, body []byte) {
+		signRequestV2(req, body, token.UID, "wrong secret")
+	})
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, service.ErrSignatureV2Mismatch.Error(), response.Errors["sign"][0])
+
+	// v1 is still accepted, but the sign param is required
+	w, response = upload("signed", func(req *http.Request, body []byte) {})
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, ErrRequestSign.Error(), response.Errors["sign"][0])
+}
+
+func TestSignatureV2WithApp(t *testing.T) {
+	app, trx, down, err := models.NewAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+	testDBConn = trx
+
+	var (
+		w    = httptest.NewRecorder()
+		api  = buildRoute(config.DefaultConfig.HTTP.APIPrefix, "/token/create")
+		body = url.Values{"appUid": {app.UID}, "path": {"/v2"}}.Encode()
+	)
+	req, _ := http.NewRequest("POST", api, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	signRequestV2(req, []byte(body), app.UID, app.Secret)
+	Routers().ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	response, err := parseResponse(w.Body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, "/v2", response.Data.(map[string]interface{})["path"])
+}
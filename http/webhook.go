@@ -0,0 +1,197 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// webhookCreateInput is used to register a webhook, events is a comma
+// separated list, all events are subscribed if it's omitted
+type webhookCreateInput struct {
+	AppUID     string  `form:"appUid" binding:"required"`
+	Nonce      string  `form:"nonce" header:"X-Request-Nonce" binding:"required,min=32,max=48"`
+	Sign       string  `form:"sign" binding:"required"`
+	URL        string  `form:"url" binding:"required,max=1000"`
+	Events     *string `form:"events" binding:"omitempty"`
+	PathPrefix string  `form:"pathPrefix,default=/" binding:"max=1000"`
+}
+
+type webhookListInput struct {
+	AppUID string `form:"appUid" binding:"required"`
+	Nonce  string `form:"nonce" header:"X-Request-Nonce" binding:"required,min=32,max=48"`
+	Sign   string `form:"sign" binding:"required"`
+}
+
+type webhookDeleteInput struct {
+	AppUID     string `form:"appUid" binding:"required"`
+	Nonce      string `form:"nonce" header:"X-Request-Nonce" binding:"required,min=32,max=48"`
+	Sign       string `form:"sign" binding:"required"`
+	WebhookUID string `form:"webhookUid" binding:"required"`
+}
+
+type webhookDeliveryListInput struct {
+	AppUID     string  `form:"appUid" binding:"required"`
+	Nonce      string  `form:"nonce" header:"X-Request-Nonce" binding:"required,min=32,max=48"`
+	Sign       string  `form:"sign" binding:"required"`
+	WebhookUID string  `form:"webhookUid" binding:"required"`
+	Status     *string `form:"status" binding:"omitempty,oneof=pending succeeded failed"`
+	Offset     int     `form:"offset,default=0" binding:"min=0"`
+	Limit      int     `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+// splitWebhookEvents is used to split the comma separated events
+func splitWebhookEvents(events *string) []string {
+	var result []string
+	if events == nil {
+		return result
+	}
+	for _, event := range strings.Split(*events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// executeService is used to validate and execute a service that responds json,
+// respond is used to generate the data of response
+func executeService(ctx *gin.Context, srv service.Service, respond func(interface{}) interface{}) {
+	var (
+		err   error
+		value interface{}
+
+		code     = 400
+		reErrors map[string][]string
+		success  bool
+		data     interface{}
+	)
+
+	defer func() {
+		ctx.JSON(code, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   success,
+			Errors:    reErrors,
+			Data:      data,
+		})
+	}()
+
+	if err = srv.Validate(); !reflect.ValueOf(err).IsNil() {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	if value, err = srv.Execute(context.Background()); err != nil {
+		reErrors = generateErrors(err, "")
+		return
+	}
+
+	data = respond(value)
+	success = true
+	code = 200
+}
+
+// findWebhook is used to find the webhook of input, the error is responded
+// if it's not found
+func findWebhook(ctx *gin.Context, webhookUID string, db *gorm.DB) (*models.Webhook, bool) {
+	webhook, err := models.FindWebhookByUID(webhookUID, db)
+	if err != nil {
+		ctx.JSON(400, &Response{
+			RequestID: ctx.GetInt64("requestId"),
+			Success:   false,
+			Errors:    generateErrors(err, "webhookUid"),
+		})
+		return nil, false
+	}
+	return webhook, true
+}
+
+// WebhookCreateHandler is used to register a webhook for app
+func WebhookCreateHandler(ctx *gin.Context) {
+	var input = ctx.MustGet("inputParam").(*webhookCreateInput)
+	executeService(ctx, &service.WebhookCreate{
+		BaseService: service.BaseService{DB: ctx.MustGet("db").(*gorm.DB)},
+		App:         ctx.MustGet("app").(*models.App),
+		URL:         input.URL,
+		Events:      splitWebhookEvents(input.Events),
+		PathPrefix:  input.PathPrefix,
+	}, func(value interface{}) interface{} {
+		return webhookResp(value.(*models.Webhook))
+	})
+}
+
+// WebhookListHandler is used to list the webhooks of app
+func WebhookListHandler(ctx *gin.Context) {
+	executeService(ctx, &service.WebhookList{
+		BaseService: service.BaseService{DB: ctx.MustGet("db").(*gorm.DB)},
+		App:         ctx.MustGet("app").(*models.App),
+	}, func(value interface{}) interface{} {
+		webhooks := value.([]models.Webhook)
+		result := make([]map[string]interface{}, 0, len(webhooks))
+		for index := range webhooks {
+			result = append(result, webhookResp(&webhooks[index]))
+		}
+		return result
+	})
+}
+
+// WebhookDeleteHandler is used to delete a webhook of app
+func WebhookDeleteHandler(ctx *gin.Context) {
+	var (
+		db    = ctx.MustGet("db").(*gorm.DB)
+		input = ctx.MustGet("inputParam").(*webhookDeleteInput)
+	)
+	webhook, ok := findWebhook(ctx, input.WebhookUID, db)
+	if !ok {
+		return
+	}
+	executeService(ctx, &service.WebhookDelete{
+		BaseService: service.BaseService{DB: db},
+		App:         ctx.MustGet("app").(*models.App),
+		Webhook:     webhook,
+	}, func(value interface{}) interface{} {
+		webhook := value.(*models.Webhook)
+		db.Unscoped().First(webhook)
+		return webhookResp(webhook)
+	})
+}
+
+// WebhookDeliveryListHandler is used to inspect the delivery log of webhook
+func WebhookDeliveryListHandler(ctx *gin.Context) {
+	var (
+		db    = ctx.MustGet("db").(*gorm.DB)
+		input = ctx.MustGet("inputParam").(*webhookDeliveryListInput)
+	)
+	webhook, ok := findWebhook(ctx, input.WebhookUID, db)
+	if !ok {
+		return
+	}
+	executeService(ctx, &service.WebhookDeliveryList{
+		BaseService: service.BaseService{DB: db},
+		App:         ctx.MustGet("app").(*models.App),
+		Webhook:     webhook,
+		Status:      input.Status,
+		Offset:      input.Offset,
+		Limit:       input.Limit,
+	}, func(value interface{}) interface{} {
+		response := value.(*service.WebhookDeliveryListResponse)
+		deliveries := make([]map[string]interface{}, 0, len(response.Deliveries))
+		for index := range response.Deliveries {
+			deliveries = append(deliveries, webhookDeliveryResp(&response.Deliveries[index]))
+		}
+		return map[string]interface{}{
+			"total":      response.Total,
+			"pages":      response.Pages,
+			"deliveries": deliveries,
+		}
+	})
+}
@@ -0,0 +1,70 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"testing"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitWebhookEvents(t *testing.T) {
+	events := " created, ,deleted"
+	assert.Equal(t, []string{"created", "deleted"}, splitWebhookEvents(&events))
+	assert.Empty(t, splitWebhookEvents(nil))
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	defer func(webhook config.Webhook) { config.DefaultConfig.Webhook = webhook }(config.DefaultConfig.Webhook)
+	config.DefaultConfig.WebhookAllowedHosts = []string{"127.0.0.1"}
+	ctx, _, down := newFileLockForTest(t)
+	defer down(t)
+	writer := ctx.Writer.(*bodyWriter)
+	token := ctx.MustGet("token").(*models.Token)
+	ctx.Set("app", &token.App)
+
+	events := "created,deleted"
+	ctx.Set("inputParam", &webhookCreateInput{URL: "http://127.0.0.1/hook", Events: &events, PathPrefix: "/save"})
+	WebhookCreateHandler(ctx)
+	response, err := parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	webhookUID := response.Data.(map[string]interface{})["webhookUid"].(string)
+	assert.Equal(t, []interface{}{"created", "deleted"}, response.Data.(map[string]interface{})["events"])
+
+	writer.body.Reset()
+	ctx.Set("inputParam", &webhookListInput{})
+	WebhookListHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, 1, len(response.Data.([]interface{})))
+
+	writer.body.Reset()
+	ctx.Set("inputParam", &webhookDeliveryListInput{WebhookUID: webhookUID, Limit: 20})
+	WebhookDeliveryListHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, float64(0), response.Data.(map[string]interface{})["total"].(float64))
+
+	writer.body.Reset()
+	ctx.Set("inputParam", &webhookDeleteInput{WebhookUID: webhookUID})
+	WebhookDeleteHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.True(t, response.Success)
+	assert.NotNil(t, response.Data.(map[string]interface{})["deletedAt"])
+
+	writer.body.Reset()
+	ctx.Set("inputParam", &webhookDeleteInput{WebhookUID: webhookUID})
+	WebhookDeleteHandler(ctx)
+	response, err = parseResponse(writer.body.String())
+	assert.Nil(t, err)
+	assert.False(t, response.Success)
+	assert.NotNil(t, response.Errors["webhookUid"])
+}
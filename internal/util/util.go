@@ -8,8 +8,11 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"net"
+	"net/http"
 	"os"
 	"reflect"
+	"strings"
 
 	"github.com/jinzhu/gorm"
 )
@@ -96,3 +99,17 @@ func InTransaction(db *gorm.DB) bool {
 	}
 	return reflect.ValueOf(db).Elem().FieldByName("db").Elem().Type().String() == "*sql.Tx"
 }
+
+// ClientIP return the ip of http client, the headers set by proxy are respected
+func ClientIP(r *http.Request) string {
+	if forwarded := strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-For"), ",")[0]); forwarded != "" {
+		return forwarded
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-Ip")); realIP != "" {
+		return realIP
+	}
+	if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return ip
+	}
+	return r.RemoteAddr
+}
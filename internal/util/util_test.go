@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -111,3 +112,13 @@ func TestInTransaction(t *testing.T) {
 	assert.True(t, InTransaction(db))
 	fmt.Println(InTransaction(nil))
 }
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.168.0.1:52013"
+	assert.Equal(t, "192.168.0.1", ClientIP(r))
+	r.Header.Set("X-Real-Ip", "10.0.0.2")
+	assert.Equal(t, "10.0.0.2", ClientIP(r))
+	r.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+	assert.Equal(t, "10.0.0.1", ClientIP(r))
+}
@@ -0,0 +1,394 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: change_feed.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Change represent an event of file tree, event is one of create, overwrite,
+// append, move, hide, delete and restore. previous_path is only set by move.
+type Change struct {
+	Sequence             uint64                `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Event                string                `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	FileUid              string                `protobuf:"bytes,3,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	Path                 string                `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	PreviousPath         *wrappers.StringValue `protobuf:"bytes,5,opt,name=previous_path,json=previousPath,proto3" json:"previous_path,omitempty"`
+	IsDir                bool                  `protobuf:"varint,6,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	Hidden               bool                  `protobuf:"varint,7,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	Size                 uint64                `protobuf:"varint,8,opt,name=size,proto3" json:"size,omitempty"`
+	CreatedAt            *timestamp.Timestamp  `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *Change) Reset()         { *m = Change{} }
+func (m *Change) String() string { return proto.CompactTextString(m) }
+func (*Change) ProtoMessage()    {}
+func (*Change) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cbd8cd4c789956b4, []int{0}
+}
+
+func (m *Change) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Change.Unmarshal(m, b)
+}
+func (m *Change) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Change.Marshal(b, m, deterministic)
+}
+func (m *Change) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Change.Merge(m, src)
+}
+func (m *Change) XXX_Size() int {
+	return xxx_messageInfo_Change.Size(m)
+}
+func (m *Change) XXX_DiscardUnknown() {
+	xxx_messageInfo_Change.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Change proto.InternalMessageInfo
+
+func (m *Change) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Change) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *Change) GetFileUid() string {
+	if m != nil {
+		return m.FileUid
+	}
+	return ""
+}
+
+func (m *Change) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Change) GetPreviousPath() *wrappers.StringValue {
+	if m != nil {
+		return m.PreviousPath
+	}
+	return nil
+}
+
+func (m *Change) GetIsDir() bool {
+	if m != nil {
+		return m.IsDir
+	}
+	return false
+}
+
+func (m *Change) GetHidden() bool {
+	if m != nil {
+		return m.Hidden
+	}
+	return false
+}
+
+func (m *Change) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Change) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+// ChangeFeedRequest represent the request of fetching the changes after cursor
+type ChangeFeedRequest struct {
+	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Secret               *wrappers.StringValue `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	SubDir               *wrappers.StringValue `protobuf:"bytes,3,opt,name=sub_dir,json=subDir,proto3" json:"sub_dir,omitempty"`
+	Cursor               uint64                `protobuf:"varint,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                uint32                `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *ChangeFeedRequest) Reset()         { *m = ChangeFeedRequest{} }
+func (m *ChangeFeedRequest) String() string { return proto.CompactTextString(m) }
+func (*ChangeFeedRequest) ProtoMessage()    {}
+func (*ChangeFeedRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cbd8cd4c789956b4, []int{1}
+}
+
+func (m *ChangeFeedRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChangeFeedRequest.Unmarshal(m, b)
+}
+func (m *ChangeFeedRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChangeFeedRequest.Marshal(b, m, deterministic)
+}
+func (m *ChangeFeedRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChangeFeedRequest.Merge(m, src)
+}
+func (m *ChangeFeedRequest) XXX_Size() int {
+	return xxx_messageInfo_ChangeFeedRequest.Size(m)
+}
+func (m *ChangeFeedRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChangeFeedRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChangeFeedRequest proto.InternalMessageInfo
+
+func (m *ChangeFeedRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *ChangeFeedRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+func (m *ChangeFeedRequest) GetSubDir() *wrappers.StringValue {
+	if m != nil {
+		return m.SubDir
+	}
+	return nil
+}
+
+func (m *ChangeFeedRequest) GetCursor() uint64 {
+	if m != nil {
+		return m.Cursor
+	}
+	return 0
+}
+
+func (m *ChangeFeedRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// ChangeFeedResponse represent the changes, cursor is used by next request
+type ChangeFeedResponse struct {
+	RequestId            uint64    `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Cursor               uint64    `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	HasMore              bool      `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	Changes              []*Change `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ChangeFeedResponse) Reset()         { *m = ChangeFeedResponse{} }
+func (m *ChangeFeedResponse) String() string { return proto.CompactTextString(m) }
+func (*ChangeFeedResponse) ProtoMessage()    {}
+func (*ChangeFeedResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cbd8cd4c789956b4, []int{2}
+}
+
+func (m *ChangeFeedResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChangeFeedResponse.Unmarshal(m, b)
+}
+func (m *ChangeFeedResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChangeFeedResponse.Marshal(b, m, deterministic)
+}
+func (m *ChangeFeedResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChangeFeedResponse.Merge(m, src)
+}
+func (m *ChangeFeedResponse) XXX_Size() int {
+	return xxx_messageInfo_ChangeFeedResponse.Size(m)
+}
+func (m *ChangeFeedResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChangeFeedResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChangeFeedResponse proto.InternalMessageInfo
+
+func (m *ChangeFeedResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *ChangeFeedResponse) GetCursor() uint64 {
+	if m != nil {
+		return m.Cursor
+	}
+	return 0
+}
+
+func (m *ChangeFeedResponse) GetHasMore() bool {
+	if m != nil {
+		return m.HasMore
+	}
+	return false
+}
+
+func (m *ChangeFeedResponse) GetChanges() []*Change {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Change)(nil), "bigfile.change_feed.Change")
+	proto.RegisterType((*ChangeFeedRequest)(nil), "bigfile.change_feed.ChangeFeedRequest")
+	proto.RegisterType((*ChangeFeedResponse)(nil), "bigfile.change_feed.ChangeFeedResponse")
+}
+
+func init() { proto.RegisterFile("change_feed.proto", fileDescriptor_cbd8cd4c789956b4) }
+
+var fileDescriptor_cbd8cd4c789956b4 = []byte{
+	// 525 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0xc5, 0xf9, 0x70, 0x9c, 0x29, 0x15, 0xea, 0x52, 0x90, 0x1b, 0xa0, 0x8d, 0x72, 0x80, 0x9c,
+	0x1c, 0x29, 0xc0, 0x81, 0x63, 0x03, 0x42, 0x42, 0x08, 0xc9, 0x5a, 0x0a, 0x48, 0x5c, 0x2c, 0x7f,
+	0x4c, 0xec, 0x15, 0xb6, 0xd7, 0xec, 0xae, 0x5b, 0xc1, 0x7f, 0xe1, 0xc2, 0xb1, 0xbf, 0x83, 0x1f,
+	0xc5, 0x11, 0x79, 0xd7, 0x4e, 0xc2, 0x87, 0x68, 0x4f, 0xf6, 0x9b, 0x79, 0x3b, 0xfb, 0xde, 0xdb,
+	0x81, 0x83, 0x38, 0x0b, 0xcb, 0x14, 0x83, 0x35, 0x62, 0xe2, 0x55, 0x82, 0x2b, 0x4e, 0x6e, 0x47,
+	0x2c, 0x5d, 0xb3, 0x1c, 0xbd, 0x9d, 0xd6, 0xe4, 0x24, 0xe5, 0x3c, 0xcd, 0x71, 0xa1, 0x29, 0x51,
+	0xbd, 0x5e, 0x28, 0x56, 0xa0, 0x54, 0x61, 0x51, 0x99, 0x53, 0x93, 0xe3, 0x3f, 0x09, 0x17, 0x22,
+	0xac, 0x2a, 0x14, 0xd2, 0xf4, 0x67, 0x97, 0x3d, 0xb0, 0x9f, 0xeb, 0x81, 0x64, 0x02, 0x8e, 0xc4,
+	0xcf, 0x35, 0x96, 0x31, 0xba, 0xd6, 0xd4, 0x9a, 0x0f, 0xe8, 0x06, 0x93, 0x43, 0x18, 0xe2, 0x39,
+	0x96, 0xca, 0xed, 0x4d, 0xad, 0xf9, 0x98, 0x1a, 0x40, 0x8e, 0xc0, 0x69, 0x14, 0x05, 0x35, 0x4b,
+	0xdc, 0xbe, 0x6e, 0x8c, 0x1a, 0xfc, 0x8e, 0x25, 0x84, 0xc0, 0xa0, 0x0a, 0x55, 0xe6, 0x0e, 0x74,
+	0x59, 0xff, 0x93, 0x53, 0xd8, 0xaf, 0x04, 0x9e, 0x33, 0x5e, 0xcb, 0x40, 0x37, 0x87, 0x53, 0x6b,
+	0xbe, 0xb7, 0xbc, 0xef, 0x19, 0x8d, 0x5e, 0xa7, 0xd1, 0x7b, 0xab, 0x04, 0x2b, 0xd3, 0xf7, 0x61,
+	0x5e, 0x23, 0xbd, 0xd9, 0x1d, 0xf1, 0x9b, 0x11, 0x77, 0xc0, 0x66, 0x32, 0x48, 0x98, 0x70, 0xed,
+	0xa9, 0x35, 0x77, 0xe8, 0x90, 0xc9, 0x17, 0x4c, 0x90, 0xbb, 0x60, 0x67, 0x2c, 0x49, 0xb0, 0x74,
+	0x47, 0xba, 0xdc, 0xa2, 0x46, 0x85, 0x64, 0x5f, 0xd1, 0x75, 0xb4, 0x1d, 0xfd, 0x4f, 0x9e, 0x01,
+	0xc4, 0x02, 0x43, 0x85, 0x49, 0x10, 0x2a, 0x77, 0xac, 0x25, 0x4c, 0xfe, 0x92, 0x70, 0xd6, 0xe5,
+	0x48, 0xc7, 0x2d, 0xfb, 0x54, 0xcd, 0x7e, 0x58, 0x70, 0x60, 0xc2, 0x7a, 0x89, 0x98, 0xd0, 0x26,
+	0x1c, 0xa9, 0x9a, 0x6c, 0x14, 0xff, 0x84, 0xa5, 0x0e, 0x6d, 0x4c, 0x0d, 0x20, 0x4f, 0xc0, 0x96,
+	0x18, 0x0b, 0x34, 0x91, 0x5d, 0xe5, 0xb2, 0xe5, 0x92, 0xa7, 0x30, 0x92, 0x75, 0xa4, 0x0d, 0xf6,
+	0xaf, 0x75, 0xac, 0x8e, 0x5a, 0xff, 0x71, 0x2d, 0x24, 0x17, 0x3a, 0xef, 0x01, 0x6d, 0x51, 0x23,
+	0x2d, 0x67, 0x05, 0x53, 0x3a, 0xe9, 0x7d, 0x6a, 0xc0, 0xec, 0x9b, 0x05, 0x64, 0xd7, 0x86, 0xac,
+	0x78, 0x29, 0x91, 0x3c, 0x00, 0x10, 0xc6, 0x52, 0xc0, 0x92, 0x76, 0x03, 0xc6, 0x6d, 0xe5, 0x55,
+	0xb2, 0x73, 0x47, 0xef, 0xb7, 0x3b, 0x8e, 0xc0, 0xc9, 0x42, 0x19, 0x14, 0x5c, 0xa0, 0xd6, 0xec,
+	0xd0, 0x51, 0x16, 0xca, 0x37, 0x5c, 0x60, 0xe3, 0xc6, 0x2c, 0xab, 0x74, 0x07, 0xd3, 0xfe, 0x7c,
+	0x6f, 0x79, 0xcf, 0xfb, 0xc7, 0x12, 0x7b, 0x46, 0x0b, 0xed, 0xb8, 0xcb, 0x02, 0x60, 0x2b, 0x8f,
+	0x04, 0x00, 0xf1, 0x16, 0x3d, 0xfc, 0xcf, 0x84, 0x9d, 0x47, 0x99, 0x3c, 0xba, 0x92, 0x67, 0x5c,
+	0xcf, 0x6e, 0xac, 0x14, 0x1c, 0xc6, 0xbc, 0xd8, 0xf0, 0xbb, 0xa0, 0x57, 0xb7, 0xb6, 0x6c, 0xbf,
+	0xa9, 0xf9, 0xd6, 0xc7, 0xe3, 0x94, 0xa9, 0xac, 0x8e, 0xbc, 0x98, 0x17, 0x8b, 0x96, 0xbf, 0xf9,
+	0x8a, 0x2a, 0xfe, 0x69, 0x59, 0xdf, 0x7b, 0xfd, 0x95, 0x4f, 0x2f, 0x7b, 0x27, 0xab, 0x76, 0x9c,
+	0xdf, 0xbd, 0xdb, 0x07, 0xcc, 0xf3, 0xd7, 0x25, 0xbf, 0x28, 0xcf, 0xbe, 0x54, 0x28, 0x23, 0x5b,
+	0xdf, 0xf3, 0xf8, 0xd7, 0x00, 0xb8, 0x82, 0x18, 0x38, 0xea, 0x03, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ChangeFeedClient is the client API for ChangeFeed service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ChangeFeedClient interface {
+	ChangeFeed(ctx context.Context, in *ChangeFeedRequest, opts ...grpc.CallOption) (*ChangeFeedResponse, error)
+}
+
+type changeFeedClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewChangeFeedClient(cc *grpc.ClientConn) ChangeFeedClient {
+	return &changeFeedClient{cc}
+}
+
+func (c *changeFeedClient) ChangeFeed(ctx context.Context, in *ChangeFeedRequest, opts ...grpc.CallOption) (*ChangeFeedResponse, error) {
+	out := new(ChangeFeedResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.change_feed.ChangeFeed/changeFeed", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChangeFeedServer is the server API for ChangeFeed service.
+type ChangeFeedServer interface {
+	ChangeFeed(context.Context, *ChangeFeedRequest) (*ChangeFeedResponse, error)
+}
+
+// UnimplementedChangeFeedServer can be embedded to have forward compatible implementations.
+type UnimplementedChangeFeedServer struct {
+}
+
+func (*UnimplementedChangeFeedServer) ChangeFeed(ctx context.Context, req *ChangeFeedRequest) (*ChangeFeedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeFeed not implemented")
+}
+
+func RegisterChangeFeedServer(s *grpc.Server, srv ChangeFeedServer) {
+	s.RegisterService(&_ChangeFeed_serviceDesc, srv)
+}
+
+func _ChangeFeed_ChangeFeed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeFeedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChangeFeedServer).ChangeFeed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.change_feed.ChangeFeed/ChangeFeed",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChangeFeedServer).ChangeFeed(ctx, req.(*ChangeFeedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ChangeFeed_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bigfile.change_feed.ChangeFeed",
+	HandlerType: (*ChangeFeedServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "changeFeed",
+			Handler:    _ChangeFeed_ChangeFeed_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "change_feed.proto",
+}
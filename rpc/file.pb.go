@@ -25,17 +25,23 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 // File represent a file type
 type File struct {
-	Uid                  string                `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
-	Path                 string                `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	Size                 uint64                `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
-	IsDir                bool                  `protobuf:"varint,4,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
-	Hidden               bool                  `protobuf:"varint,5,opt,name=hidden,proto3" json:"hidden,omitempty"`
-	Hash                 *wrappers.StringValue `protobuf:"bytes,6,opt,name=hash,proto3" json:"hash,omitempty"`
-	Ext                  *wrappers.StringValue `protobuf:"bytes,7,opt,name=ext,proto3" json:"ext,omitempty"`
-	DeletedAt            *timestamp.Timestamp  `protobuf:"bytes,8,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
-	XXX_unrecognized     []byte                `json:"-"`
-	XXX_sizecache        int32                 `json:"-"`
+	Uid       string                `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Path      string                `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Size      uint64                `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	IsDir     bool                  `protobuf:"varint,4,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	Hidden    bool                  `protobuf:"varint,5,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	Hash      *wrappers.StringValue `protobuf:"bytes,6,opt,name=hash,proto3" json:"hash,omitempty"`
+	Ext       *wrappers.StringValue `protobuf:"bytes,7,opt,name=ext,proto3" json:"ext,omitempty"`
+	DeletedAt *timestamp.Timestamp  `protobuf:"bytes,8,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	Locks     []*FileLock           `protobuf:"bytes,9,rep,name=locks,proto3" json:"locks,omitempty"`
+	ExpiredAt *timestamp.Timestamp  `protobuf:"bytes,10,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	// retention_mode is one of 0(none), 1(governance) and 2(compliance)
+	RetentionMode        uint32               `protobuf:"varint,11,opt,name=retention_mode,json=retentionMode,proto3" json:"retention_mode,omitempty"`
+	RetainUntil          *timestamp.Timestamp `protobuf:"bytes,12,opt,name=retain_until,json=retainUntil,proto3" json:"retain_until,omitempty"`
+	LegalHold            bool                 `protobuf:"varint,13,opt,name=legal_hold,json=legalHold,proto3" json:"legal_hold,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *File) Reset()         { *m = File{} }
@@ -119,33 +125,133 @@ func (m *File) GetDeletedAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *File) GetLocks() []*FileLock {
+	if m != nil {
+		return m.Locks
+	}
+	return nil
+}
+
+func (m *File) GetExpiredAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiredAt
+	}
+	return nil
+}
+
+func (m *File) GetRetentionMode() uint32 {
+	if m != nil {
+		return m.RetentionMode
+	}
+	return 0
+}
+
+func (m *File) GetRetainUntil() *timestamp.Timestamp {
+	if m != nil {
+		return m.RetainUntil
+	}
+	return nil
+}
+
+func (m *File) GetLegalHold() bool {
+	if m != nil {
+		return m.LegalHold
+	}
+	return false
+}
+
+// FileLock represent an active lock on file
+type FileLock struct {
+	Uid                  string               `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Exclusive            bool                 `protobuf:"varint,2,opt,name=exclusive,proto3" json:"exclusive,omitempty"`
+	ExpiredAt            *timestamp.Timestamp `protobuf:"bytes,3,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *FileLock) Reset()         { *m = FileLock{} }
+func (m *FileLock) String() string { return proto.CompactTextString(m) }
+func (*FileLock) ProtoMessage()    {}
+func (*FileLock) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9188e3b7e55e1162, []int{1}
+}
+
+func (m *FileLock) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileLock.Unmarshal(m, b)
+}
+func (m *FileLock) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileLock.Marshal(b, m, deterministic)
+}
+func (m *FileLock) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileLock.Merge(m, src)
+}
+func (m *FileLock) XXX_Size() int {
+	return xxx_messageInfo_FileLock.Size(m)
+}
+func (m *FileLock) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileLock.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileLock proto.InternalMessageInfo
+
+func (m *FileLock) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *FileLock) GetExclusive() bool {
+	if m != nil {
+		return m.Exclusive
+	}
+	return false
+}
+
+func (m *FileLock) GetExpiredAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiredAt
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*File)(nil), "bigfile.file.File")
+	proto.RegisterType((*FileLock)(nil), "bigfile.file.FileLock")
 }
 
 func init() { proto.RegisterFile("file.proto", fileDescriptor_9188e3b7e55e1162) }
 
 var fileDescriptor_9188e3b7e55e1162 = []byte{
-	// 325 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x90, 0xb1, 0x4e, 0xeb, 0x30,
-	0x14, 0x86, 0xe5, 0x24, 0xcd, 0x6d, 0x7d, 0xef, 0x70, 0x65, 0xdd, 0x8b, 0xac, 0x0a, 0xb5, 0x11,
-	0x53, 0x26, 0x17, 0xc1, 0xc4, 0x48, 0x84, 0x58, 0x58, 0xa2, 0x50, 0x81, 0xc4, 0x52, 0x25, 0xcd,
-	0x69, 0x62, 0xc9, 0x89, 0x2d, 0xdb, 0x51, 0x81, 0x67, 0xe0, 0x29, 0x18, 0x79, 0x42, 0x46, 0x14,
-	0x37, 0x61, 0x80, 0x85, 0x25, 0x39, 0xe7, 0x3f, 0xdf, 0xef, 0x73, 0xf4, 0x63, 0xbc, 0xe3, 0x02,
-	0x98, 0xd2, 0xd2, 0x4a, 0xf2, 0xa7, 0xe0, 0x95, 0x6b, 0xfb, 0xcf, 0x7c, 0x51, 0x49, 0x59, 0x09,
-	0x58, 0xb9, 0x59, 0xd1, 0xed, 0x56, 0x7b, 0x9d, 0x2b, 0x05, 0xda, 0x1c, 0xe8, 0xf9, 0xf2, 0xeb,
-	0xdc, 0xf2, 0x06, 0x8c, 0xcd, 0x1b, 0x75, 0x00, 0x4e, 0x5e, 0x3c, 0x1c, 0x5c, 0x73, 0x01, 0xe4,
-	0x2f, 0xf6, 0x3b, 0x5e, 0x52, 0x14, 0xa1, 0x78, 0x96, 0xf5, 0x25, 0x21, 0x38, 0x50, 0xb9, 0xad,
-	0xa9, 0xe7, 0x24, 0x57, 0xf7, 0x9a, 0xe1, 0xcf, 0x40, 0xfd, 0x08, 0xc5, 0x41, 0xe6, 0x6a, 0xf2,
-	0x1f, 0x87, 0xdc, 0x6c, 0x4a, 0xae, 0x69, 0x10, 0xa1, 0x78, 0x9a, 0x4d, 0xb8, 0xb9, 0xe2, 0x9a,
-	0x1c, 0xe1, 0xb0, 0xe6, 0x65, 0x09, 0x2d, 0x9d, 0x38, 0x79, 0xe8, 0xc8, 0x29, 0x0e, 0xea, 0xdc,
-	0xd4, 0x34, 0x8c, 0x50, 0xfc, 0xfb, 0xec, 0x98, 0x1d, 0x2e, 0x64, 0xe3, 0x85, 0xec, 0xd6, 0x6a,
-	0xde, 0x56, 0x77, 0xb9, 0xe8, 0x20, 0x73, 0x24, 0x61, 0xd8, 0x87, 0x47, 0x4b, 0x7f, 0xfd, 0xc0,
-	0xd0, 0x83, 0xe4, 0x02, 0xe3, 0x12, 0x04, 0x58, 0x28, 0x37, 0xb9, 0xa5, 0x53, 0x67, 0x9b, 0x7f,
-	0xb3, 0xad, 0xc7, 0x24, 0xb2, 0xd9, 0x40, 0x5f, 0xda, 0xa4, 0xc5, 0xff, 0xb6, 0xb2, 0x61, 0x63,
-	0xc6, 0x23, 0x9c, 0xcc, 0xfa, 0x8c, 0xd2, 0xbe, 0x4b, 0xd1, 0xc3, 0xa2, 0xe2, 0xb6, 0xee, 0x0a,
-	0xb6, 0x95, 0xcd, 0x6a, 0x20, 0x3f, 0xff, 0x5a, 0x6d, 0xdf, 0x11, 0x7a, 0xf5, 0xfc, 0x24, 0xcd,
-	0xde, 0xbc, 0x65, 0x32, 0x3c, 0x94, 0x8e, 0x5b, 0xef, 0x41, 0x88, 0x9b, 0x56, 0xee, 0xdb, 0xf5,
-	0x93, 0x02, 0x53, 0x84, 0x6e, 0xc3, 0xf9, 0x47, 0x00, 0x00, 0x00, 0xff, 0xff, 0x4d, 0x65, 0xc7,
-	0xfa, 0xe2, 0x01, 0x00, 0x00,
+	// 458 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x92, 0x41, 0x6b, 0xd4, 0x40,
+	0x14, 0xc7, 0xc9, 0x26, 0xbb, 0x6e, 0xde, 0xee, 0x8a, 0x0c, 0x5a, 0x86, 0xa5, 0xb6, 0xa1, 0x20,
+	0xe4, 0x20, 0x59, 0xa9, 0x27, 0x0f, 0x1e, 0xba, 0x88, 0x08, 0x2a, 0x2c, 0xb1, 0x2a, 0x78, 0x09,
+	0xd9, 0xcc, 0x6b, 0x32, 0x74, 0x92, 0x09, 0x93, 0x89, 0x5d, 0xfd, 0x38, 0x1e, 0xfd, 0x84, 0xe2,
+	0x49, 0x66, 0x92, 0xac, 0x52, 0x85, 0xda, 0x4b, 0xf2, 0xde, 0x7f, 0x7e, 0x2f, 0xff, 0xf7, 0xde,
+	0x04, 0xe0, 0x82, 0x0b, 0x8c, 0x6a, 0x25, 0xb5, 0x24, 0xf3, 0x2d, 0xcf, 0x6d, 0x6a, 0x1e, 0xcb,
+	0xa3, 0x5c, 0xca, 0x5c, 0xe0, 0xca, 0x9e, 0x6d, 0xdb, 0x8b, 0xd5, 0x95, 0x4a, 0xeb, 0x1a, 0x55,
+	0xd3, 0xd1, 0xcb, 0xe3, 0xeb, 0xe7, 0x9a, 0x97, 0xd8, 0xe8, 0xb4, 0xac, 0x3b, 0xe0, 0xe4, 0xa7,
+	0x0b, 0xde, 0x4b, 0x2e, 0x90, 0xdc, 0x03, 0xb7, 0xe5, 0x8c, 0x3a, 0x81, 0x13, 0xfa, 0xb1, 0x09,
+	0x09, 0x01, 0xaf, 0x4e, 0x75, 0x41, 0x47, 0x56, 0xb2, 0xb1, 0xd1, 0x1a, 0xfe, 0x15, 0xa9, 0x1b,
+	0x38, 0xa1, 0x17, 0xdb, 0x98, 0x3c, 0x80, 0x09, 0x6f, 0x12, 0xc6, 0x15, 0xf5, 0x02, 0x27, 0x9c,
+	0xc6, 0x63, 0xde, 0xbc, 0xe0, 0x8a, 0x1c, 0xc0, 0xa4, 0xe0, 0x8c, 0x61, 0x45, 0xc7, 0x56, 0xee,
+	0x33, 0xf2, 0x04, 0xbc, 0x22, 0x6d, 0x0a, 0x3a, 0x09, 0x9c, 0x70, 0x76, 0x7a, 0x18, 0x75, 0x1d,
+	0x46, 0x43, 0x87, 0xd1, 0x3b, 0xad, 0x78, 0x95, 0x7f, 0x48, 0x45, 0x8b, 0xb1, 0x25, 0x49, 0x04,
+	0x2e, 0xee, 0x34, 0xbd, 0xf3, 0x1f, 0x05, 0x06, 0x24, 0xcf, 0x00, 0x18, 0x0a, 0xd4, 0xc8, 0x92,
+	0x54, 0xd3, 0xa9, 0x2d, 0x5b, 0xfe, 0x55, 0x76, 0x3e, 0x6c, 0x22, 0xf6, 0x7b, 0xfa, 0x4c, 0x93,
+	0xc7, 0x30, 0x16, 0x32, 0xbb, 0x6c, 0xa8, 0x1f, 0xb8, 0xe1, 0xec, 0xf4, 0x20, 0xfa, 0x73, 0xdb,
+	0x91, 0x59, 0xd4, 0x1b, 0x99, 0x5d, 0xc6, 0x1d, 0x64, 0x8c, 0x70, 0x57, 0x73, 0xd5, 0x19, 0xc1,
+	0xcd, 0x46, 0x3d, 0x7d, 0xa6, 0xc9, 0x23, 0xb8, 0xab, 0x50, 0x63, 0xa5, 0xb9, 0xac, 0x92, 0x52,
+	0x32, 0xa4, 0xb3, 0xc0, 0x09, 0x17, 0xf1, 0x62, 0xaf, 0xbe, 0x95, 0x0c, 0xc9, 0x73, 0x98, 0x2b,
+	0xd4, 0x29, 0xaf, 0x92, 0xb6, 0xd2, 0x5c, 0xd0, 0xf9, 0x8d, 0x1e, 0xb3, 0x8e, 0x7f, 0x6f, 0x70,
+	0xf2, 0x10, 0x40, 0x60, 0x9e, 0x8a, 0xa4, 0x90, 0x82, 0xd1, 0x85, 0xbd, 0x07, 0xdf, 0x2a, 0xaf,
+	0xa4, 0x60, 0x27, 0x2d, 0x4c, 0x87, 0x91, 0xfe, 0x71, 0xff, 0x87, 0xe0, 0xe3, 0x2e, 0x13, 0x6d,
+	0xc3, 0x3f, 0xa3, 0xfd, 0x09, 0xa6, 0xf1, 0x6f, 0xe1, 0xda, 0xec, 0xee, 0x2d, 0x66, 0x5f, 0x57,
+	0x70, 0x3f, 0x93, 0xe5, 0x7e, 0xb5, 0x03, 0xbc, 0xf6, 0x4d, 0x33, 0x1b, 0x93, 0x6d, 0x9c, 0x4f,
+	0x47, 0x39, 0xd7, 0x45, 0xbb, 0x8d, 0x32, 0x59, 0xae, 0x7a, 0x72, 0xff, 0x56, 0x75, 0xf6, 0xc3,
+	0x71, 0xbe, 0x8d, 0xdc, 0xf5, 0x26, 0xfe, 0x3e, 0x3a, 0x5e, 0xf7, 0x1f, 0xda, 0x0c, 0xae, 0x1f,
+	0x51, 0x88, 0xd7, 0x95, 0xbc, 0xaa, 0xce, 0xbf, 0xd4, 0xd8, 0x6c, 0x27, 0xd6, 0xe1, 0xe9, 0xaf,
+	0x01, 0x00, 0x7c, 0x92, 0x33, 0xbd, 0x47, 0x03, 0x00, 0x00,
 }
@@ -0,0 +1,308 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: file_archive.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type FileArchiveRequest_Format int32
+
+const (
+	FileArchiveRequest_Zip   FileArchiveRequest_Format = 0
+	FileArchiveRequest_TarGz FileArchiveRequest_Format = 1
+)
+
+var FileArchiveRequest_Format_name = map[int32]string{
+	0: "Zip",
+	1: "TarGz",
+}
+
+var FileArchiveRequest_Format_value = map[string]int32{
+	"Zip":   0,
+	"TarGz": 1,
+}
+
+func (x FileArchiveRequest_Format) String() string {
+	return proto.EnumName(FileArchiveRequest_Format_name, int32(x))
+}
+
+func (FileArchiveRequest_Format) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_e9812a60e3a17878, []int{0, 0}
+}
+
+// FileArchiveRequest represent the request of downloading a directory or some
+// files as archive, one of sub_dir and file_uids is required
+type FileArchiveRequest struct {
+	Token                string                    `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Secret               *wrappers.StringValue     `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	SubDir               *wrappers.StringValue     `protobuf:"bytes,3,opt,name=sub_dir,json=subDir,proto3" json:"sub_dir,omitempty"`
+	FileUids             []string                  `protobuf:"bytes,4,rep,name=file_uids,json=fileUids,proto3" json:"file_uids,omitempty"`
+	Format               FileArchiveRequest_Format `protobuf:"varint,5,opt,name=format,proto3,enum=bigfile.file_archive.FileArchiveRequest_Format" json:"format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *FileArchiveRequest) Reset()         { *m = FileArchiveRequest{} }
+func (m *FileArchiveRequest) String() string { return proto.CompactTextString(m) }
+func (*FileArchiveRequest) ProtoMessage()    {}
+func (*FileArchiveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e9812a60e3a17878, []int{0}
+}
+
+func (m *FileArchiveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileArchiveRequest.Unmarshal(m, b)
+}
+func (m *FileArchiveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileArchiveRequest.Marshal(b, m, deterministic)
+}
+func (m *FileArchiveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileArchiveRequest.Merge(m, src)
+}
+func (m *FileArchiveRequest) XXX_Size() int {
+	return xxx_messageInfo_FileArchiveRequest.Size(m)
+}
+func (m *FileArchiveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileArchiveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileArchiveRequest proto.InternalMessageInfo
+
+func (m *FileArchiveRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *FileArchiveRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+func (m *FileArchiveRequest) GetSubDir() *wrappers.StringValue {
+	if m != nil {
+		return m.SubDir
+	}
+	return nil
+}
+
+func (m *FileArchiveRequest) GetFileUids() []string {
+	if m != nil {
+		return m.FileUids
+	}
+	return nil
+}
+
+func (m *FileArchiveRequest) GetFormat() FileArchiveRequest_Format {
+	if m != nil {
+		return m.Format
+	}
+	return FileArchiveRequest_Zip
+}
+
+// FileArchiveResponse represent a piece of archive
+type FileArchiveResponse struct {
+	Content              []byte   `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FileArchiveResponse) Reset()         { *m = FileArchiveResponse{} }
+func (m *FileArchiveResponse) String() string { return proto.CompactTextString(m) }
+func (*FileArchiveResponse) ProtoMessage()    {}
+func (*FileArchiveResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e9812a60e3a17878, []int{1}
+}
+
+func (m *FileArchiveResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileArchiveResponse.Unmarshal(m, b)
+}
+func (m *FileArchiveResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileArchiveResponse.Marshal(b, m, deterministic)
+}
+func (m *FileArchiveResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileArchiveResponse.Merge(m, src)
+}
+func (m *FileArchiveResponse) XXX_Size() int {
+	return xxx_messageInfo_FileArchiveResponse.Size(m)
+}
+func (m *FileArchiveResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileArchiveResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileArchiveResponse proto.InternalMessageInfo
+
+func (m *FileArchiveResponse) GetContent() []byte {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("bigfile.file_archive.FileArchiveRequest_Format", FileArchiveRequest_Format_name, FileArchiveRequest_Format_value)
+	proto.RegisterType((*FileArchiveRequest)(nil), "bigfile.file_archive.FileArchiveRequest")
+	proto.RegisterType((*FileArchiveResponse)(nil), "bigfile.file_archive.FileArchiveResponse")
+}
+
+func init() { proto.RegisterFile("file_archive.proto", fileDescriptor_e9812a60e3a17878) }
+
+var fileDescriptor_e9812a60e3a17878 = []byte{
+	// 372 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x91, 0xbd, 0x6e, 0xdb, 0x30,
+	0x14, 0x85, 0x4d, 0xa9, 0x96, 0x2b, 0xba, 0x28, 0x0c, 0xd6, 0x83, 0xe0, 0x1a, 0xae, 0xa0, 0x49,
+	0x5d, 0xa8, 0xc2, 0x6d, 0x1f, 0xa0, 0x42, 0x61, 0x0f, 0x5d, 0x04, 0xd5, 0x49, 0x00, 0x2f, 0x86,
+	0x24, 0x53, 0x32, 0x11, 0x59, 0x54, 0xf8, 0x13, 0x23, 0x79, 0x95, 0x6c, 0x19, 0xf3, 0x84, 0x19,
+	0x03, 0xeb, 0xc7, 0x50, 0x90, 0x0c, 0x9e, 0x88, 0x7b, 0x78, 0xbe, 0xc3, 0x7b, 0x2f, 0x21, 0x4a,
+	0x69, 0x4e, 0x36, 0x11, 0x4f, 0x76, 0xf4, 0x96, 0xe0, 0x92, 0x33, 0xc9, 0xd0, 0x38, 0xa6, 0xd9,
+	0x51, 0xc6, 0xdd, 0xbb, 0xc9, 0x2c, 0x63, 0x2c, 0xcb, 0x89, 0x57, 0x79, 0x62, 0x95, 0x7a, 0x07,
+	0x1e, 0x95, 0x25, 0xe1, 0xa2, 0xa6, 0x9c, 0x07, 0x0d, 0xa2, 0x05, 0xcd, 0xc9, 0x9f, 0xda, 0x1f,
+	0x92, 0x1b, 0x45, 0x84, 0x44, 0x63, 0xd8, 0x97, 0xec, 0x9a, 0x14, 0x16, 0xb0, 0x81, 0x6b, 0x86,
+	0x75, 0x81, 0x7e, 0x41, 0x43, 0x90, 0x84, 0x13, 0x69, 0x69, 0x36, 0x70, 0x87, 0xf3, 0x29, 0xae,
+	0xd3, 0x71, 0x9b, 0x8e, 0xff, 0x4b, 0x4e, 0x8b, 0xec, 0x32, 0xca, 0x15, 0x09, 0x1b, 0x2f, 0xfa,
+	0x0d, 0x07, 0x42, 0xc5, 0x9b, 0x2d, 0xe5, 0x96, 0x7e, 0x16, 0xa6, 0xe2, 0xbf, 0x94, 0xa3, 0xaf,
+	0xd0, 0xac, 0x26, 0x51, 0x74, 0x2b, 0xac, 0x0f, 0xb6, 0xee, 0x9a, 0xe1, 0xc7, 0xa3, 0x70, 0x41,
+	0xb7, 0x02, 0x2d, 0xa1, 0x91, 0x32, 0xbe, 0x8f, 0xa4, 0xd5, 0xb7, 0x81, 0xfb, 0x79, 0xee, 0xe1,
+	0xf7, 0xa6, 0xc7, 0x6f, 0x27, 0xc3, 0x8b, 0x0a, 0x0b, 0x1b, 0xdc, 0x99, 0x42, 0xa3, 0x56, 0xd0,
+	0x00, 0xea, 0x6b, 0x5a, 0x8e, 0x7a, 0xc8, 0x84, 0xfd, 0x55, 0xc4, 0x97, 0xf7, 0x23, 0xe0, 0x78,
+	0xf0, 0xcb, 0xab, 0x08, 0x51, 0xb2, 0x42, 0x10, 0x64, 0xc1, 0x41, 0xc2, 0x0a, 0x49, 0x0a, 0x59,
+	0xed, 0xe7, 0x53, 0xd8, 0x96, 0x73, 0x05, 0x87, 0x1d, 0x00, 0xa5, 0x70, 0x98, 0x76, 0x4a, 0xf7,
+	0xdc, 0x2e, 0x27, 0xdf, 0xcf, 0x70, 0xd6, 0xcd, 0x38, 0xbd, 0x1f, 0xc0, 0x57, 0x70, 0x9c, 0xb0,
+	0xfd, 0x89, 0x69, 0xf7, 0xea, 0x8f, 0x3a, 0x40, 0x70, 0x14, 0x03, 0xb0, 0x9e, 0x65, 0x54, 0xee,
+	0x54, 0x8c, 0x13, 0xb6, 0xf7, 0x1a, 0xe0, 0x74, 0xf2, 0x32, 0x79, 0x06, 0xe0, 0x51, 0xd3, 0xfd,
+	0x20, 0x7c, 0xd2, 0xbe, 0xf9, 0x4d, 0x5e, 0xd0, 0xfe, 0xd3, 0x15, 0xc9, 0xf3, 0x7f, 0x05, 0x3b,
+	0x14, 0xab, 0xbb, 0x92, 0x88, 0xd8, 0xa8, 0x1e, 0xfa, 0xf9, 0x32, 0x00, 0x22, 0x66, 0x6d, 0x0d,
+	0x8f, 0x02, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// FileArchiveClient is the client API for FileArchive service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type FileArchiveClient interface {
+	FileArchive(ctx context.Context, in *FileArchiveRequest, opts ...grpc.CallOption) (FileArchive_FileArchiveClient, error)
+}
+
+type fileArchiveClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewFileArchiveClient(cc *grpc.ClientConn) FileArchiveClient {
+	return &fileArchiveClient{cc}
+}
+
+func (c *fileArchiveClient) FileArchive(ctx context.Context, in *FileArchiveRequest, opts ...grpc.CallOption) (FileArchive_FileArchiveClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_FileArchive_serviceDesc.Streams[0], "/bigfile.file_archive.FileArchive/fileArchive", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fileArchiveFileArchiveClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FileArchive_FileArchiveClient interface {
+	Recv() (*FileArchiveResponse, error)
+	grpc.ClientStream
+}
+
+type fileArchiveFileArchiveClient struct {
+	grpc.ClientStream
+}
+
+func (x *fileArchiveFileArchiveClient) Recv() (*FileArchiveResponse, error) {
+	m := new(FileArchiveResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FileArchiveServer is the server API for FileArchive service.
+type FileArchiveServer interface {
+	FileArchive(*FileArchiveRequest, FileArchive_FileArchiveServer) error
+}
+
+// UnimplementedFileArchiveServer can be embedded to have forward compatible implementations.
+type UnimplementedFileArchiveServer struct {
+}
+
+func (*UnimplementedFileArchiveServer) FileArchive(req *FileArchiveRequest, srv FileArchive_FileArchiveServer) error {
+	return status.Errorf(codes.Unimplemented, "method FileArchive not implemented")
+}
+
+func RegisterFileArchiveServer(s *grpc.Server, srv FileArchiveServer) {
+	s.RegisterService(&_FileArchive_serviceDesc, srv)
+}
+
+func _FileArchive_FileArchive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FileArchiveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileArchiveServer).FileArchive(m, &fileArchiveFileArchiveServer{stream})
+}
+
+type FileArchive_FileArchiveServer interface {
+	Send(*FileArchiveResponse) error
+	grpc.ServerStream
+}
+
+type fileArchiveFileArchiveServer struct {
+	grpc.ServerStream
+}
+
+func (x *fileArchiveFileArchiveServer) Send(m *FileArchiveResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _FileArchive_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bigfile.file_archive.FileArchive",
+	HandlerType: (*FileArchiveServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "fileArchive",
+			Handler:       _FileArchive_FileArchive_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "file_archive.proto",
+}
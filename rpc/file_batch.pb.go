@@ -0,0 +1,440 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: file_batch.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type FileBatchOperation_Op int32
+
+const (
+	FileBatchOperation_Mkdir     FileBatchOperation_Op = 0
+	FileBatchOperation_Move      FileBatchOperation_Op = 1
+	FileBatchOperation_Copy      FileBatchOperation_Op = 2
+	FileBatchOperation_Delete    FileBatchOperation_Op = 3
+	FileBatchOperation_SetHidden FileBatchOperation_Op = 4
+)
+
+var FileBatchOperation_Op_name = map[int32]string{
+	0: "Mkdir",
+	1: "Move",
+	2: "Copy",
+	3: "Delete",
+	4: "SetHidden",
+}
+
+var FileBatchOperation_Op_value = map[string]int32{
+	"Mkdir":     0,
+	"Move":      1,
+	"Copy":      2,
+	"Delete":    3,
+	"SetHidden": 4,
+}
+
+func (x FileBatchOperation_Op) String() string {
+	return proto.EnumName(FileBatchOperation_Op_name, int32(x))
+}
+
+func (FileBatchOperation_Op) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_3adbd66023da2f59, []int{0, 0}
+}
+
+// FileBatchOperation represent an operation in batch, to is the destination
+// of move and copy, hidden is only used by set-hidden, force is only used to
+// delete non-empty directory
+type FileBatchOperation struct {
+	Op                   FileBatchOperation_Op `protobuf:"varint,1,opt,name=op,proto3,enum=bigfile.file_batch.FileBatchOperation_Op" json:"op,omitempty"`
+	Path                 string                `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	To                   *wrappers.StringValue `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Hidden               *wrappers.BoolValue   `protobuf:"bytes,4,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	Force                bool                  `protobuf:"varint,5,opt,name=force,proto3" json:"force,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileBatchOperation) Reset()         { *m = FileBatchOperation{} }
+func (m *FileBatchOperation) String() string { return proto.CompactTextString(m) }
+func (*FileBatchOperation) ProtoMessage()    {}
+func (*FileBatchOperation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3adbd66023da2f59, []int{0}
+}
+
+func (m *FileBatchOperation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileBatchOperation.Unmarshal(m, b)
+}
+func (m *FileBatchOperation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileBatchOperation.Marshal(b, m, deterministic)
+}
+func (m *FileBatchOperation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileBatchOperation.Merge(m, src)
+}
+func (m *FileBatchOperation) XXX_Size() int {
+	return xxx_messageInfo_FileBatchOperation.Size(m)
+}
+func (m *FileBatchOperation) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileBatchOperation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileBatchOperation proto.InternalMessageInfo
+
+func (m *FileBatchOperation) GetOp() FileBatchOperation_Op {
+	if m != nil {
+		return m.Op
+	}
+	return FileBatchOperation_Mkdir
+}
+
+func (m *FileBatchOperation) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *FileBatchOperation) GetTo() *wrappers.StringValue {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *FileBatchOperation) GetHidden() *wrappers.BoolValue {
+	if m != nil {
+		return m.Hidden
+	}
+	return nil
+}
+
+func (m *FileBatchOperation) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
+}
+
+// FileBatchRequest represent the request of executing operations atomically
+type FileBatchRequest struct {
+	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Secret               *wrappers.StringValue `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	Operations           []*FileBatchOperation `protobuf:"bytes,3,rep,name=operations,proto3" json:"operations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileBatchRequest) Reset()         { *m = FileBatchRequest{} }
+func (m *FileBatchRequest) String() string { return proto.CompactTextString(m) }
+func (*FileBatchRequest) ProtoMessage()    {}
+func (*FileBatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3adbd66023da2f59, []int{1}
+}
+
+func (m *FileBatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileBatchRequest.Unmarshal(m, b)
+}
+func (m *FileBatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileBatchRequest.Marshal(b, m, deterministic)
+}
+func (m *FileBatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileBatchRequest.Merge(m, src)
+}
+func (m *FileBatchRequest) XXX_Size() int {
+	return xxx_messageInfo_FileBatchRequest.Size(m)
+}
+func (m *FileBatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileBatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileBatchRequest proto.InternalMessageInfo
+
+func (m *FileBatchRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *FileBatchRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+func (m *FileBatchRequest) GetOperations() []*FileBatchOperation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+// FileBatchResult represent the result of an operation in batch
+type FileBatchResult struct {
+	Index                uint32                `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Success              bool                  `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error                *wrappers.StringValue `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	File                 *File                 `protobuf:"bytes,4,opt,name=file,proto3" json:"file,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileBatchResult) Reset()         { *m = FileBatchResult{} }
+func (m *FileBatchResult) String() string { return proto.CompactTextString(m) }
+func (*FileBatchResult) ProtoMessage()    {}
+func (*FileBatchResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3adbd66023da2f59, []int{2}
+}
+
+func (m *FileBatchResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileBatchResult.Unmarshal(m, b)
+}
+func (m *FileBatchResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileBatchResult.Marshal(b, m, deterministic)
+}
+func (m *FileBatchResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileBatchResult.Merge(m, src)
+}
+func (m *FileBatchResult) XXX_Size() int {
+	return xxx_messageInfo_FileBatchResult.Size(m)
+}
+func (m *FileBatchResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileBatchResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileBatchResult proto.InternalMessageInfo
+
+func (m *FileBatchResult) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *FileBatchResult) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *FileBatchResult) GetError() *wrappers.StringValue {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func (m *FileBatchResult) GetFile() *File {
+	if m != nil {
+		return m.File
+	}
+	return nil
+}
+
+// FileBatchResponse represent the response of batch, all operations have
+// been rolled back if success is false
+type FileBatchResponse struct {
+	RequestId            uint64             `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Success              bool               `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Results              []*FileBatchResult `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *FileBatchResponse) Reset()         { *m = FileBatchResponse{} }
+func (m *FileBatchResponse) String() string { return proto.CompactTextString(m) }
+func (*FileBatchResponse) ProtoMessage()    {}
+func (*FileBatchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3adbd66023da2f59, []int{3}
+}
+
+func (m *FileBatchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileBatchResponse.Unmarshal(m, b)
+}
+func (m *FileBatchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileBatchResponse.Marshal(b, m, deterministic)
+}
+func (m *FileBatchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileBatchResponse.Merge(m, src)
+}
+func (m *FileBatchResponse) XXX_Size() int {
+	return xxx_messageInfo_FileBatchResponse.Size(m)
+}
+func (m *FileBatchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileBatchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileBatchResponse proto.InternalMessageInfo
+
+func (m *FileBatchResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *FileBatchResponse) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *FileBatchResponse) GetResults() []*FileBatchResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("bigfile.file_batch.FileBatchOperation_Op", FileBatchOperation_Op_name, FileBatchOperation_Op_value)
+	proto.RegisterType((*FileBatchOperation)(nil), "bigfile.file_batch.FileBatchOperation")
+	proto.RegisterType((*FileBatchRequest)(nil), "bigfile.file_batch.FileBatchRequest")
+	proto.RegisterType((*FileBatchResult)(nil), "bigfile.file_batch.FileBatchResult")
+	proto.RegisterType((*FileBatchResponse)(nil), "bigfile.file_batch.FileBatchResponse")
+}
+
+func init() { proto.RegisterFile("file_batch.proto", fileDescriptor_3adbd66023da2f59) }
+
+var fileDescriptor_3adbd66023da2f59 = []byte{
+	// 529 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x91, 0xcf, 0x6e, 0xd3, 0x40,
+	0x10, 0xc6, 0xbb, 0x8e, 0x93, 0xd6, 0x53, 0xb5, 0x98, 0x55, 0x0f, 0x56, 0x04, 0x25, 0x32, 0x50,
+	0x05, 0x09, 0xb9, 0x92, 0xe1, 0xc2, 0x01, 0x0e, 0x06, 0x55, 0x20, 0x54, 0x35, 0xda, 0x22, 0x90,
+	0x7a, 0xa9, 0x12, 0x7b, 0xe2, 0x58, 0x75, 0xbd, 0xcb, 0xee, 0x9a, 0xd2, 0x67, 0xe0, 0x11, 0x10,
+	0x17, 0x0e, 0x1c, 0x78, 0x42, 0x8e, 0xc8, 0xeb, 0x3f, 0x04, 0x45, 0x6a, 0x73, 0xb2, 0x67, 0xe6,
+	0x9b, 0x9d, 0xdf, 0x7c, 0x03, 0xee, 0x3c, 0xcb, 0xf1, 0x7c, 0x36, 0xd5, 0xf1, 0x22, 0x10, 0x92,
+	0x6b, 0x4e, 0xe9, 0x2c, 0x4b, 0xab, 0x64, 0xf0, 0xaf, 0x32, 0x04, 0x93, 0x30, 0xf5, 0xe1, 0x7e,
+	0xca, 0x79, 0x9a, 0xe3, 0xa1, 0x89, 0x66, 0xe5, 0xfc, 0xf0, 0x4a, 0x4e, 0x85, 0x40, 0xa9, 0xea,
+	0xba, 0xff, 0xdd, 0x02, 0x7a, 0x94, 0xe5, 0x18, 0x55, 0x9d, 0x27, 0x02, 0xe5, 0x54, 0x67, 0xbc,
+	0xa0, 0x2f, 0xc0, 0xe2, 0xc2, 0x23, 0x23, 0x32, 0xde, 0x0d, 0x9f, 0x04, 0xab, 0x33, 0x82, 0xd5,
+	0x9e, 0xe0, 0x44, 0x30, 0x8b, 0x0b, 0x4a, 0xc1, 0x16, 0x53, 0xbd, 0xf0, 0xac, 0x11, 0x19, 0x3b,
+	0xcc, 0xfc, 0xd3, 0xa7, 0x60, 0x69, 0xee, 0xf5, 0x46, 0x64, 0xbc, 0x1d, 0xde, 0x0b, 0x6a, 0xa4,
+	0xa0, 0x45, 0x0a, 0x4e, 0xb5, 0xcc, 0x8a, 0xf4, 0xe3, 0x34, 0x2f, 0x91, 0x59, 0x9a, 0xd3, 0x10,
+	0x06, 0x8b, 0x2c, 0x49, 0xb0, 0xf0, 0x6c, 0xd3, 0x31, 0x5c, 0xe9, 0x88, 0x38, 0xcf, 0x6b, 0x7d,
+	0xa3, 0xa4, 0x7b, 0xd0, 0x9f, 0x73, 0x19, 0xa3, 0xd7, 0x1f, 0x91, 0xf1, 0x16, 0xab, 0x03, 0xff,
+	0x15, 0x58, 0x27, 0x82, 0x3a, 0xd0, 0x3f, 0xbe, 0x48, 0x32, 0xe9, 0x6e, 0xd0, 0x2d, 0xb0, 0x8f,
+	0xf9, 0x17, 0x74, 0x49, 0xf5, 0xf7, 0x9a, 0x8b, 0x6b, 0xd7, 0xa2, 0x00, 0x83, 0x37, 0x98, 0xa3,
+	0x46, 0xb7, 0x47, 0x77, 0xc0, 0x39, 0x45, 0xfd, 0xd6, 0xbc, 0xe9, 0xda, 0xfe, 0x2f, 0x02, 0x6e,
+	0xb7, 0x29, 0xc3, 0xcf, 0x25, 0x2a, 0x5d, 0x8d, 0xd2, 0xfc, 0x02, 0x0b, 0x63, 0x8f, 0xc3, 0xea,
+	0x80, 0x3e, 0x87, 0x81, 0xc2, 0x58, 0xa2, 0xf6, 0xac, 0x35, 0xd6, 0x6c, 0xb4, 0xf4, 0x08, 0x80,
+	0xb7, 0x06, 0x2a, 0xaf, 0x37, 0xea, 0x8d, 0xb7, 0xc3, 0x83, 0xf5, 0xfc, 0x66, 0x4b, 0x9d, 0xfe,
+	0x0f, 0x02, 0x77, 0x96, 0x40, 0x55, 0x99, 0x1b, 0xce, 0xac, 0x48, 0xf0, 0xab, 0xe1, 0xdc, 0x61,
+	0x75, 0x40, 0x3d, 0xd8, 0x54, 0x65, 0x1c, 0xa3, 0x52, 0x06, 0x74, 0x8b, 0xb5, 0x21, 0x0d, 0xa1,
+	0x8f, 0x52, 0x72, 0xb9, 0xd6, 0x9d, 0x6a, 0x29, 0x3d, 0x00, 0xbb, 0x82, 0x6c, 0x0e, 0x45, 0xff,
+	0x23, 0x37, 0xcc, 0xcc, 0xd4, 0xfd, 0x6f, 0x04, 0xee, 0x2e, 0xf3, 0x09, 0x5e, 0x28, 0xa4, 0xf7,
+	0x01, 0x64, 0x6d, 0xea, 0x79, 0x96, 0x18, 0x4c, 0x9b, 0x39, 0x4d, 0xe6, 0x5d, 0x72, 0x03, 0xea,
+	0x4b, 0xd8, 0x94, 0x66, 0xc9, 0xd6, 0xb3, 0x87, 0x37, 0x7a, 0x56, 0x1b, 0xc2, 0xda, 0x9e, 0x30,
+	0x05, 0xa7, 0xab, 0xd1, 0x33, 0x70, 0xe6, 0x5d, 0xf0, 0xe8, 0x96, 0x77, 0x0c, 0xda, 0xf0, 0xf1,
+	0x6d, 0xd3, 0xcc, 0x7a, 0xfe, 0x46, 0xa4, 0x60, 0x2f, 0xe6, 0x97, 0x9d, 0xba, 0x75, 0x32, 0xda,
+	0xed, 0xc4, 0x93, 0x2a, 0x35, 0x21, 0x67, 0xfb, 0x69, 0xa6, 0x17, 0xe5, 0x2c, 0x88, 0xf9, 0xe5,
+	0x61, 0x23, 0xef, 0xbe, 0x52, 0xc4, 0x7f, 0x08, 0xf9, 0x69, 0xf5, 0xa2, 0x09, 0xfb, 0x6d, 0x3d,
+	0x88, 0x9a, 0xd7, 0x26, 0xed, 0x5d, 0x3e, 0x61, 0x9e, 0xbf, 0x2f, 0xf8, 0x55, 0xf1, 0xe1, 0x5a,
+	0xa0, 0x9a, 0x0d, 0xcc, 0x98, 0x67, 0x7f, 0x07, 0x00, 0x9d, 0xda, 0xb5, 0x50, 0x2d, 0x04, 0x00,
+	0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// FileBatchClient is the client API for FileBatch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type FileBatchClient interface {
+	FileBatch(ctx context.Context, in *FileBatchRequest, opts ...grpc.CallOption) (*FileBatchResponse, error)
+}
+
+type fileBatchClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewFileBatchClient(cc *grpc.ClientConn) FileBatchClient {
+	return &fileBatchClient{cc}
+}
+
+func (c *fileBatchClient) FileBatch(ctx context.Context, in *FileBatchRequest, opts ...grpc.CallOption) (*FileBatchResponse, error) {
+	out := new(FileBatchResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.file_batch.FileBatch/fileBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FileBatchServer is the server API for FileBatch service.
+type FileBatchServer interface {
+	FileBatch(context.Context, *FileBatchRequest) (*FileBatchResponse, error)
+}
+
+// UnimplementedFileBatchServer can be embedded to have forward compatible implementations.
+type UnimplementedFileBatchServer struct {
+}
+
+func (*UnimplementedFileBatchServer) FileBatch(ctx context.Context, req *FileBatchRequest) (*FileBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileBatch not implemented")
+}
+
+func RegisterFileBatchServer(s *grpc.Server, srv FileBatchServer) {
+	s.RegisterService(&_FileBatch_serviceDesc, srv)
+}
+
+func _FileBatch_FileBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileBatchServer).FileBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.file_batch.FileBatch/FileBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileBatchServer).FileBatch(ctx, req.(*FileBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _FileBatch_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bigfile.file_batch.FileBatch",
+	HandlerType: (*FileBatchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "fileBatch",
+			Handler:    _FileBatch_FileBatch_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "file_batch.proto",
+}
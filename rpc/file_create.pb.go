@@ -9,6 +9,7 @@ import (
 	math "math"
 
 	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -38,11 +39,16 @@ type FileCreateRequest struct {
 	//	*FileCreateRequest_Append
 	//	*FileCreateRequest_CreateDir
 	//	*FileCreateRequest_None
-	Operation            isFileCreateRequest_Operation `protobuf_oneof:"operation"`
-	Content              *wrappers.BytesValue          `protobuf:"bytes,12,opt,name=content,proto3" json:"content,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                      `json:"-"`
-	XXX_unrecognized     []byte                        `json:"-"`
-	XXX_sizecache        int32                         `json:"-"`
+	Operation isFileCreateRequest_Operation `protobuf_oneof:"operation"`
+	Content   *wrappers.BytesValue          `protobuf:"bytes,12,opt,name=content,proto3" json:"content,omitempty"`
+	// if_match and if_none_match are compared with the hash of the existing file
+	IfMatch     *wrappers.StringValue `protobuf:"bytes,13,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	IfNoneMatch *wrappers.StringValue `protobuf:"bytes,14,opt,name=if_none_match,json=ifNoneMatch,proto3" json:"if_none_match,omitempty"`
+	// the file will be deleted after expired_at, it's optional
+	ExpiredAt            *timestamp.Timestamp `protobuf:"bytes,15,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *FileCreateRequest) Reset()         { *m = FileCreateRequest{} }
@@ -181,6 +187,27 @@ func (m *FileCreateRequest) GetContent() *wrappers.BytesValue {
 	return nil
 }
 
+func (m *FileCreateRequest) GetIfMatch() *wrappers.StringValue {
+	if m != nil {
+		return m.IfMatch
+	}
+	return nil
+}
+
+func (m *FileCreateRequest) GetIfNoneMatch() *wrappers.StringValue {
+	if m != nil {
+		return m.IfNoneMatch
+	}
+	return nil
+}
+
+func (m *FileCreateRequest) GetExpiredAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiredAt
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*FileCreateRequest) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -248,35 +275,40 @@ func init() {
 func init() { proto.RegisterFile("file_create.proto", fileDescriptor_d8a75d4c3ddc50ae) }
 
 var fileDescriptor_d8a75d4c3ddc50ae = []byte{
-	// 443 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x52, 0xcd, 0x8e, 0xd3, 0x30,
-	0x10, 0x5e, 0x97, 0x90, 0xdd, 0x4c, 0x91, 0xd0, 0x9a, 0x1e, 0xac, 0x02, 0xdd, 0xaa, 0x87, 0xa5,
-	0x27, 0x17, 0x15, 0x78, 0x81, 0x80, 0x10, 0x88, 0x4b, 0x15, 0x10, 0x48, 0x70, 0xa8, 0xd2, 0x64,
-	0x36, 0xb5, 0x48, 0x6d, 0xaf, 0xe3, 0x52, 0xed, 0xeb, 0x70, 0xe4, 0x01, 0x11, 0x47, 0x14, 0xdb,
-	0x69, 0x2b, 0x15, 0x89, 0x53, 0xf2, 0xfd, 0x8d, 0xed, 0x99, 0x81, 0xcb, 0x1b, 0x51, 0xe3, 0xb2,
-	0x30, 0x98, 0x5b, 0xe4, 0xda, 0x28, 0xab, 0xe8, 0xa3, 0x95, 0xa8, 0x5a, 0x96, 0x1f, 0x49, 0x43,
-	0x70, 0x8c, 0x33, 0x0c, 0x47, 0x95, 0x52, 0x55, 0x8d, 0x33, 0x87, 0x56, 0xdb, 0x9b, 0xd9, 0xce,
-	0xe4, 0x5a, 0xa3, 0x69, 0xbc, 0x3e, 0xf9, 0xdd, 0x83, 0xcb, 0xb7, 0xa2, 0xc6, 0xd7, 0x2e, 0x9a,
-	0xe1, 0xed, 0x16, 0x1b, 0x4b, 0x07, 0x70, 0xdf, 0xaa, 0xef, 0x28, 0x19, 0x19, 0x93, 0x69, 0x92,
-	0x79, 0x40, 0x29, 0x44, 0x3a, 0xb7, 0x6b, 0xd6, 0x73, 0xa4, 0xfb, 0xa7, 0x2f, 0x21, 0x6e, 0xb0,
-	0x30, 0x68, 0x59, 0x34, 0x26, 0xd3, 0xfe, 0xfc, 0x09, 0xf7, 0x07, 0xf2, 0xee, 0x40, 0xfe, 0xd1,
-	0x1a, 0x21, 0xab, 0xcf, 0x79, 0xbd, 0xc5, 0x2c, 0x78, 0xe9, 0x1c, 0xe2, 0xb5, 0x28, 0x4b, 0x94,
-	0x2c, 0x76, 0xa9, 0xe1, 0x49, 0x2a, 0x55, 0xaa, 0x0e, 0x19, 0xef, 0xa4, 0x23, 0x48, 0xd4, 0x0f,
-	0x34, 0x3b, 0x23, 0x2c, 0xb2, 0xf3, 0x31, 0x99, 0x5e, 0xbc, 0x3b, 0xcb, 0x0e, 0x14, 0x65, 0x10,
-	0x1b, 0x94, 0xf9, 0x06, 0xd9, 0x45, 0x10, 0x03, 0x6e, 0x95, 0xf6, 0xcd, 0xb2, 0x64, 0x49, 0xa7,
-	0x78, 0x4c, 0xaf, 0x00, 0x7c, 0xcf, 0x96, 0xa5, 0x30, 0x0c, 0xba, 0xa2, 0x9e, 0x7b, 0x23, 0x0c,
-	0x1d, 0x40, 0x24, 0x95, 0x44, 0xd6, 0x0f, 0x92, 0x43, 0xf4, 0x15, 0x9c, 0x17, 0x4a, 0x5a, 0x94,
-	0x96, 0x3d, 0x70, 0xf7, 0x7f, 0x7c, 0x7a, 0xff, 0x3b, 0x8b, 0x8d, 0x7f, 0x40, 0xe7, 0x4d, 0xfb,
-	0x90, 0x28, 0x8d, 0x26, 0xb7, 0x42, 0xc9, 0xc9, 0x37, 0xa0, 0xc7, 0x7d, 0x6f, 0xb4, 0x92, 0x0d,
-	0xd2, 0xa7, 0x00, 0xc6, 0xcf, 0x60, 0x29, 0x4a, 0xd7, 0xfd, 0x28, 0x4b, 0x02, 0xf3, 0xbe, 0xa4,
-	0xd7, 0x10, 0xb5, 0xb3, 0x75, 0x13, 0xe8, 0xcf, 0x29, 0x3f, 0x9e, 0x3e, 0x6f, 0xcb, 0x65, 0x4e,
-	0x9f, 0xdf, 0x02, 0x1c, 0x8a, 0xd3, 0x02, 0xdc, 0x46, 0x04, 0x74, 0xcd, 0xff, 0xb1, 0x33, 0xfc,
-	0x64, 0x07, 0x86, 0xcf, 0xfe, 0xeb, 0xf3, 0x77, 0x9e, 0x9c, 0x4d, 0xc9, 0x73, 0x92, 0x5a, 0x18,
-	0x14, 0x6a, 0xb3, 0xcf, 0x74, 0x8d, 0x48, 0x1f, 0x1e, 0x12, 0x8b, 0x96, 0x5b, 0x90, 0xaf, 0xa3,
-	0x4a, 0xd8, 0xf5, 0x76, 0xc5, 0x0b, 0xb5, 0x99, 0x05, 0xff, 0xfe, 0x6b, 0x74, 0xf1, 0x87, 0x90,
-	0x9f, 0xbd, 0x7b, 0xe9, 0x22, 0xfb, 0xd5, 0xbb, 0x4a, 0x43, 0xb9, 0x45, 0xd7, 0xd7, 0x2f, 0x58,
-	0xd7, 0x1f, 0xa4, 0xda, 0xc9, 0x4f, 0x77, 0x1a, 0x9b, 0x55, 0xec, 0xce, 0x79, 0xf1, 0x37, 0x00,
-	0x00, 0xff, 0xff, 0x0b, 0x2a, 0x8c, 0x5e, 0x1b, 0x03, 0x00, 0x00,
+	// 516 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0x4f, 0x8f, 0xd2, 0x40,
+	0x14, 0xdf, 0xae, 0x15, 0xe8, 0xc3, 0x75, 0xb3, 0x23, 0x87, 0x09, 0x2a, 0x10, 0x0e, 0x2b, 0xa7,
+	0x62, 0x50, 0x63, 0xbc, 0x69, 0x35, 0x46, 0x63, 0x34, 0xa4, 0x6e, 0x34, 0xd1, 0x43, 0x53, 0xda,
+	0x57, 0x98, 0xd8, 0xce, 0x74, 0xa7, 0x83, 0xb8, 0x5f, 0xc7, 0xa3, 0xf1, 0x03, 0x7a, 0x34, 0x9d,
+	0x99, 0x2e, 0x44, 0x34, 0xee, 0x09, 0xde, 0xef, 0xcf, 0x7b, 0x6f, 0x5e, 0x7e, 0x85, 0x93, 0x8c,
+	0xe5, 0x18, 0x25, 0x12, 0x63, 0x85, 0x7e, 0x29, 0x85, 0x12, 0xe4, 0xd6, 0x82, 0x2d, 0x6b, 0xd4,
+	0xdf, 0xa1, 0xfa, 0xa0, 0x11, 0x2d, 0xe8, 0x0f, 0x97, 0x42, 0x2c, 0x73, 0x9c, 0xea, 0x6a, 0xb1,
+	0xce, 0xa6, 0x8a, 0x15, 0x58, 0xa9, 0xb8, 0x28, 0xad, 0x60, 0xf0, 0xa7, 0x60, 0x23, 0xe3, 0xb2,
+	0x44, 0x59, 0x19, 0x7e, 0xfc, 0xd3, 0x85, 0x93, 0x97, 0x2c, 0xc7, 0xe7, 0xba, 0x77, 0x88, 0xe7,
+	0x6b, 0xac, 0x14, 0xe9, 0xc1, 0x75, 0x25, 0xbe, 0x20, 0xa7, 0xce, 0xc8, 0x99, 0x78, 0xa1, 0x29,
+	0x08, 0x01, 0xb7, 0x8c, 0xd5, 0x8a, 0x1e, 0x6a, 0x50, 0xff, 0x27, 0x0f, 0xa1, 0x55, 0x61, 0x22,
+	0x51, 0x51, 0x77, 0xe4, 0x4c, 0xba, 0xb3, 0x3b, 0xbe, 0x19, 0xe8, 0x37, 0x03, 0xfd, 0xf7, 0x4a,
+	0x32, 0xbe, 0xfc, 0x10, 0xe7, 0x6b, 0x0c, 0xad, 0x96, 0xcc, 0xa0, 0xb5, 0x62, 0x69, 0x8a, 0x9c,
+	0xb6, 0xb4, 0xab, 0xbf, 0xe7, 0x0a, 0x84, 0xc8, 0xad, 0xc7, 0x28, 0xc9, 0x00, 0x3c, 0xf1, 0x15,
+	0xe5, 0x46, 0x32, 0x85, 0xb4, 0x3d, 0x72, 0x26, 0x9d, 0x57, 0x07, 0xe1, 0x16, 0x22, 0x14, 0x5a,
+	0x12, 0x79, 0x5c, 0x20, 0xed, 0x58, 0xd2, 0xd6, 0x35, 0x53, 0xbf, 0x99, 0xa7, 0xd4, 0x6b, 0x18,
+	0x53, 0x93, 0x21, 0x80, 0x39, 0x6a, 0x94, 0x32, 0x49, 0xa1, 0x69, 0x6a, 0xb0, 0x17, 0x4c, 0x92,
+	0x1e, 0xb8, 0x5c, 0x70, 0xa4, 0x5d, 0x4b, 0xe9, 0x8a, 0x3c, 0x82, 0x76, 0x22, 0xb8, 0x42, 0xae,
+	0xe8, 0x0d, 0xbd, 0xff, 0xed, 0xfd, 0xfd, 0x2f, 0x14, 0x56, 0xe6, 0x01, 0x8d, 0x96, 0x3c, 0x86,
+	0x0e, 0xcb, 0xa2, 0x22, 0x56, 0xc9, 0x8a, 0x1e, 0x5d, 0xe1, 0x5a, 0x6d, 0x96, 0xbd, 0xad, 0xc5,
+	0xe4, 0x29, 0x1c, 0xb1, 0x2c, 0xaa, 0x47, 0x5b, 0xf7, 0xcd, 0x2b, 0xb8, 0xbb, 0x2c, 0x7b, 0x27,
+	0x38, 0x9a, 0x0e, 0x4f, 0x00, 0xf0, 0x5b, 0xc9, 0x24, 0xa6, 0x51, 0xac, 0xe8, 0xf1, 0x3f, 0x8e,
+	0x7e, 0xd6, 0x84, 0x27, 0xf4, 0xac, 0xfa, 0x99, 0x0a, 0xba, 0xe0, 0x89, 0x12, 0x65, 0xac, 0x98,
+	0xe0, 0xe3, 0xcf, 0x40, 0x76, 0xd3, 0x52, 0x95, 0x82, 0x57, 0x48, 0xee, 0x02, 0x48, 0x93, 0x9c,
+	0x88, 0xa5, 0x3a, 0x33, 0x6e, 0xe8, 0x59, 0xe4, 0x75, 0x4a, 0x4e, 0xc1, 0xad, 0x23, 0xab, 0x73,
+	0xd3, 0x9d, 0x11, 0x7f, 0x37, 0xd4, 0x7e, 0xdd, 0x2e, 0xd4, 0xfc, 0xec, 0x1c, 0x60, 0xdb, 0x9c,
+	0x24, 0x00, 0xd9, 0xb6, 0x3a, 0xf5, 0xff, 0xf2, 0x29, 0xf8, 0x7b, 0xc9, 0xed, 0xdf, 0xfb, 0xaf,
+	0xce, 0xec, 0x3c, 0x3e, 0x98, 0x38, 0xf7, 0x9d, 0x40, 0x41, 0x2f, 0x11, 0xc5, 0xa5, 0xa7, 0xb9,
+	0x44, 0x70, 0xbc, 0x75, 0xcc, 0x6b, 0x6c, 0xee, 0x7c, 0x1a, 0x2c, 0x99, 0x5a, 0xad, 0x17, 0x7e,
+	0x22, 0x8a, 0xa9, 0xd5, 0x5f, 0xfe, 0xca, 0x32, 0xf9, 0xe5, 0x38, 0xdf, 0x0f, 0xaf, 0x05, 0xf3,
+	0xf0, 0xc7, 0xe1, 0x30, 0xb0, 0xed, 0xe6, 0xcd, 0x61, 0x3f, 0x62, 0x9e, 0xbf, 0xe1, 0x62, 0xc3,
+	0xcf, 0x2e, 0x4a, 0xac, 0x16, 0x2d, 0x3d, 0xe7, 0xc1, 0xef, 0x01, 0x00, 0x04, 0x9e, 0x3f, 0x76,
+	0xf2, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
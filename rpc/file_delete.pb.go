@@ -28,10 +28,15 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 // FileDeleteRequest represent the file delete request
 type FileDeleteRequest struct {
-	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
-	FileUid              string                `protobuf:"bytes,2,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
-	ForceDeleteIfDir     bool                  `protobuf:"varint,3,opt,name=force_delete_if_dir,json=forceDeleteIfDir,proto3" json:"force_delete_if_dir,omitempty"`
-	Secret               *wrappers.StringValue `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	Token            string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	FileUid          string                `protobuf:"bytes,2,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	ForceDeleteIfDir bool                  `protobuf:"varint,3,opt,name=force_delete_if_dir,json=forceDeleteIfDir,proto3" json:"force_delete_if_dir,omitempty"`
+	Secret           *wrappers.StringValue `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	// if_match and if_none_match are compared with the hash of file
+	IfMatch     *wrappers.StringValue `protobuf:"bytes,5,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	IfNoneMatch *wrappers.StringValue `protobuf:"bytes,6,opt,name=if_none_match,json=ifNoneMatch,proto3" json:"if_none_match,omitempty"`
+	// path is relative to the path of token, file_uid must be empty if it's given
+	Path                 *wrappers.StringValue `protobuf:"bytes,7,opt,name=path,proto3" json:"path,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
 	XXX_unrecognized     []byte                `json:"-"`
 	XXX_sizecache        int32                 `json:"-"`
@@ -90,6 +95,27 @@ func (m *FileDeleteRequest) GetSecret() *wrappers.StringValue {
 	return nil
 }
 
+func (m *FileDeleteRequest) GetIfMatch() *wrappers.StringValue {
+	if m != nil {
+		return m.IfMatch
+	}
+	return nil
+}
+
+func (m *FileDeleteRequest) GetIfNoneMatch() *wrappers.StringValue {
+	if m != nil {
+		return m.IfNoneMatch
+	}
+	return nil
+}
+
+func (m *FileDeleteRequest) GetPath() *wrappers.StringValue {
+	if m != nil {
+		return m.Path
+	}
+	return nil
+}
+
 // FileDeleteResponse represent the file delete response
 type FileDeleteResponse struct {
 	RequestId            uint64   `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
@@ -146,30 +172,33 @@ func init() {
 func init() { proto.RegisterFile("file_delete.proto", fileDescriptor_37676b62f991a3b0) }
 
 var fileDescriptor_37676b62f991a3b0 = []byte{
-	// 356 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x90, 0x4f, 0x4f, 0xea, 0x40,
-	0x14, 0xc5, 0xdf, 0x00, 0x8f, 0x07, 0x97, 0xc5, 0x93, 0x81, 0x45, 0x25, 0x8a, 0x84, 0x05, 0xb2,
-	0x71, 0x48, 0xd0, 0x4f, 0xd0, 0x10, 0x13, 0xe2, 0xa6, 0xa9, 0xff, 0x12, 0x5d, 0x34, 0xb4, 0xbd,
-	0xad, 0x13, 0x4b, 0xa7, 0x4e, 0xa7, 0x21, 0x7e, 0x1d, 0x77, 0xfa, 0x09, 0x5d, 0x9a, 0x4e, 0x5b,
-	0x20, 0xd1, 0xc4, 0x55, 0x73, 0xcf, 0x39, 0xbd, 0xf7, 0x37, 0x07, 0xba, 0x01, 0x8f, 0xd0, 0xf1,
-	0x31, 0x42, 0x85, 0x2c, 0x91, 0x42, 0x09, 0xda, 0x73, 0x79, 0x98, 0xab, 0x6c, 0xcf, 0x1a, 0x80,
-	0x56, 0x74, 0x60, 0x30, 0x0c, 0x85, 0x08, 0x23, 0x9c, 0xe9, 0xc9, 0xcd, 0x82, 0xd9, 0x46, 0xae,
-	0x92, 0x04, 0x65, 0x5a, 0xf8, 0xe3, 0x77, 0x02, 0xdd, 0x4b, 0x1e, 0xe1, 0x42, 0xff, 0x6a, 0xe3,
-	0x4b, 0x86, 0xa9, 0xa2, 0x7d, 0xf8, 0xab, 0xc4, 0x33, 0xc6, 0x06, 0x19, 0x91, 0x69, 0xdb, 0x2e,
-	0x06, 0x7a, 0x08, 0x2d, 0x7d, 0x26, 0xe3, 0xbe, 0x51, 0xd3, 0xc6, 0xbf, 0x7c, 0xbe, 0xe5, 0x3e,
-	0x3d, 0x83, 0x5e, 0x20, 0xa4, 0x57, 0x21, 0x38, 0x3c, 0x70, 0x7c, 0x2e, 0x8d, 0xfa, 0x88, 0x4c,
-	0x5b, 0xf6, 0x81, 0xb6, 0x8a, 0x0b, 0xcb, 0x60, 0xc1, 0x25, 0xbd, 0x80, 0x66, 0x8a, 0x9e, 0x44,
-	0x65, 0x34, 0x46, 0x64, 0xda, 0x99, 0x1f, 0xb1, 0x02, 0x93, 0x55, 0x98, 0xec, 0x5a, 0x49, 0x1e,
-	0x87, 0x77, 0xab, 0x28, 0x43, 0xbb, 0xcc, 0x8e, 0x1f, 0x81, 0xee, 0xa3, 0xa6, 0x89, 0x88, 0x53,
-	0xa4, 0xc7, 0x00, 0xb2, 0xc0, 0x76, 0xb8, 0xaf, 0x81, 0x1b, 0x76, 0xbb, 0x54, 0x96, 0x3e, 0x9d,
-	0x40, 0x23, 0x87, 0xd4, 0xc0, 0x9d, 0x39, 0x65, 0xfb, 0x85, 0xb1, 0x7c, 0x9d, 0xad, 0xfd, 0xf9,
-	0x1a, 0x60, 0xb7, 0x9c, 0x3a, 0xa0, 0x4b, 0x2c, 0xa7, 0x09, 0xfb, 0xa1, 0x66, 0xf6, 0xad, 0xb6,
-	0xc1, 0xe9, 0xaf, 0xb9, 0x82, 0x79, 0xfc, 0xc7, 0x54, 0xd0, 0xf7, 0xc4, 0x7a, 0x9b, 0xaf, 0xde,
-	0x6d, 0xfe, 0xdf, 0xa5, 0xad, 0x5c, 0xb3, 0xc8, 0xc3, 0x30, 0xe4, 0xea, 0x29, 0x73, 0x99, 0x27,
-	0xd6, 0xb3, 0x32, 0xbf, 0xfd, 0xca, 0xc4, 0xfb, 0x24, 0xe4, 0xad, 0x56, 0x37, 0x2d, 0xfb, 0xa3,
-	0x76, 0x62, 0x96, 0xeb, 0xac, 0xaa, 0xc6, 0x7b, 0x8c, 0xa2, 0xab, 0x58, 0x6c, 0xe2, 0x9b, 0xd7,
-	0x04, 0x53, 0xb7, 0xa9, 0xef, 0x9c, 0x7f, 0x05, 0x00, 0x00, 0xff, 0xff, 0x88, 0x89, 0xf8, 0x74,
-	0x4a, 0x02, 0x00, 0x00,
+	// 409 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0x4d, 0xab, 0xd3, 0x40,
+	0x14, 0x35, 0x79, 0x79, 0x6d, 0xdf, 0x2d, 0xa2, 0x6f, 0xde, 0x5b, 0xc4, 0xa2, 0xcf, 0xd2, 0x45,
+	0xed, 0xc6, 0xa9, 0x54, 0xc1, 0xad, 0x84, 0x22, 0x14, 0x51, 0x42, 0xfc, 0x02, 0x5d, 0x84, 0x7c,
+	0xdc, 0x49, 0x07, 0x93, 0x99, 0x38, 0x99, 0x50, 0xfc, 0x3b, 0x2e, 0xfd, 0x41, 0xfe, 0x16, 0x97,
+	0x92, 0x49, 0xd2, 0x16, 0x14, 0x5e, 0x57, 0xe1, 0xde, 0x73, 0xce, 0xbd, 0x27, 0xe7, 0x0e, 0x5c,
+	0x32, 0x9e, 0x63, 0x98, 0x62, 0x8e, 0x1a, 0x69, 0xa9, 0xa4, 0x96, 0xe4, 0x2a, 0xe6, 0x59, 0xd3,
+	0xa5, 0x47, 0xd0, 0x04, 0x4c, 0xc7, 0x10, 0x26, 0x37, 0x99, 0x94, 0x59, 0x8e, 0x4b, 0x53, 0xc5,
+	0x35, 0x5b, 0xee, 0x54, 0x54, 0x96, 0xa8, 0xaa, 0x16, 0x9f, 0xfd, 0xb6, 0xe1, 0xf2, 0x35, 0xcf,
+	0x71, 0x6d, 0xa4, 0x01, 0x7e, 0xaf, 0xb1, 0xd2, 0xe4, 0x1a, 0xce, 0xb5, 0xfc, 0x86, 0xc2, 0xb5,
+	0xa6, 0xd6, 0xe2, 0x22, 0x68, 0x0b, 0xf2, 0x00, 0x46, 0x66, 0x4d, 0xcd, 0x53, 0xd7, 0x36, 0xc0,
+	0xb0, 0xa9, 0x3f, 0xf2, 0x94, 0x3c, 0x85, 0x2b, 0x26, 0x55, 0xd2, 0x5b, 0x08, 0x39, 0x0b, 0x53,
+	0xae, 0xdc, 0xb3, 0xa9, 0xb5, 0x18, 0x05, 0xf7, 0x0d, 0xd4, 0x6e, 0xd8, 0xb0, 0x35, 0x57, 0xe4,
+	0x05, 0x0c, 0x2a, 0x4c, 0x14, 0x6a, 0xd7, 0x99, 0x5a, 0x8b, 0xf1, 0xea, 0x21, 0x6d, 0x6d, 0xd2,
+	0xde, 0x26, 0x7d, 0xaf, 0x15, 0x17, 0xd9, 0xa7, 0x28, 0xaf, 0x31, 0xe8, 0xb8, 0xe4, 0x25, 0x8c,
+	0x38, 0x0b, 0x8b, 0x48, 0x27, 0x5b, 0xf7, 0xfc, 0x04, 0xdd, 0x90, 0xb3, 0xb7, 0x0d, 0x99, 0xbc,
+	0x82, 0xbb, 0x9c, 0x85, 0x42, 0x0a, 0xec, 0xd4, 0x83, 0x13, 0xd4, 0x63, 0xce, 0xde, 0x49, 0x81,
+	0xed, 0x84, 0x67, 0xe0, 0x94, 0x91, 0xde, 0xba, 0xc3, 0x13, 0x84, 0x86, 0x39, 0xfb, 0x0a, 0xe4,
+	0x38, 0xd7, 0xaa, 0x94, 0xa2, 0x42, 0xf2, 0x08, 0x40, 0xb5, 0x19, 0x87, 0x3c, 0x35, 0xe9, 0x3a,
+	0xc1, 0x45, 0xd7, 0xd9, 0xa4, 0x64, 0x0e, 0x4e, 0x93, 0xa8, 0x49, 0x77, 0xbc, 0x22, 0xf4, 0xf8,
+	0xba, 0xb4, 0x19, 0x17, 0x18, 0x7c, 0x55, 0x00, 0x1c, 0x86, 0x93, 0x10, 0x80, 0x1d, 0xaa, 0x39,
+	0xfd, 0xcf, 0x9b, 0xa0, 0xff, 0xdc, 0x78, 0xf2, 0xe4, 0x56, 0x5e, 0xeb, 0x79, 0x76, 0xc7, 0xd3,
+	0x70, 0x9d, 0xc8, 0x62, 0xcf, 0xef, 0xff, 0xda, 0xbb, 0x77, 0x60, 0xfb, 0x4d, 0xcf, 0xb7, 0xbe,
+	0xdc, 0x64, 0x5c, 0x6f, 0xeb, 0x98, 0x26, 0xb2, 0x58, 0x76, 0xfc, 0xfd, 0x57, 0x95, 0xc9, 0x1f,
+	0xcb, 0xfa, 0x69, 0x9f, 0x79, 0x7e, 0xf0, 0xcb, 0x7e, 0xec, 0x75, 0xe3, 0xfc, 0x3e, 0xc4, 0xcf,
+	0x98, 0xe7, 0x6f, 0x84, 0xdc, 0x89, 0x0f, 0x3f, 0x4a, 0xac, 0xe2, 0x81, 0xd9, 0xf3, 0xfc, 0xef,
+	0x00, 0x18, 0xdf, 0xa6, 0x52, 0xf7, 0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -0,0 +1,367 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: file_extract.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// FileExtractRequest represent the request of unpacking an archive into
+// target directory, format is detected by the name of archive if it's omitted
+type FileExtractRequest struct {
+	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Secret               *wrappers.StringValue `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	FileUid              string                `protobuf:"bytes,3,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	Target               string                `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	Format               *wrappers.StringValue `protobuf:"bytes,5,opt,name=format,proto3" json:"format,omitempty"`
+	Overwrite            bool                  `protobuf:"varint,6,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	MaxEntries           uint32                `protobuf:"varint,7,opt,name=max_entries,json=maxEntries,proto3" json:"max_entries,omitempty"`
+	MaxSize              uint64                `protobuf:"varint,8,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileExtractRequest) Reset()         { *m = FileExtractRequest{} }
+func (m *FileExtractRequest) String() string { return proto.CompactTextString(m) }
+func (*FileExtractRequest) ProtoMessage()    {}
+func (*FileExtractRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fca1d823d5b515df, []int{0}
+}
+
+func (m *FileExtractRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileExtractRequest.Unmarshal(m, b)
+}
+func (m *FileExtractRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileExtractRequest.Marshal(b, m, deterministic)
+}
+func (m *FileExtractRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileExtractRequest.Merge(m, src)
+}
+func (m *FileExtractRequest) XXX_Size() int {
+	return xxx_messageInfo_FileExtractRequest.Size(m)
+}
+func (m *FileExtractRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileExtractRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileExtractRequest proto.InternalMessageInfo
+
+func (m *FileExtractRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *FileExtractRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+func (m *FileExtractRequest) GetFileUid() string {
+	if m != nil {
+		return m.FileUid
+	}
+	return ""
+}
+
+func (m *FileExtractRequest) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *FileExtractRequest) GetFormat() *wrappers.StringValue {
+	if m != nil {
+		return m.Format
+	}
+	return nil
+}
+
+func (m *FileExtractRequest) GetOverwrite() bool {
+	if m != nil {
+		return m.Overwrite
+	}
+	return false
+}
+
+func (m *FileExtractRequest) GetMaxEntries() uint32 {
+	if m != nil {
+		return m.MaxEntries
+	}
+	return 0
+}
+
+func (m *FileExtractRequest) GetMaxSize() uint64 {
+	if m != nil {
+		return m.MaxSize
+	}
+	return 0
+}
+
+// FileExtractResult represent the result of an entry of archive
+type FileExtractResult struct {
+	Name                 string                `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path                 string                `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Success              bool                  `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Error                *wrappers.StringValue `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	File                 *File                 `protobuf:"bytes,5,opt,name=file,proto3" json:"file,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileExtractResult) Reset()         { *m = FileExtractResult{} }
+func (m *FileExtractResult) String() string { return proto.CompactTextString(m) }
+func (*FileExtractResult) ProtoMessage()    {}
+func (*FileExtractResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fca1d823d5b515df, []int{1}
+}
+
+func (m *FileExtractResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileExtractResult.Unmarshal(m, b)
+}
+func (m *FileExtractResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileExtractResult.Marshal(b, m, deterministic)
+}
+func (m *FileExtractResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileExtractResult.Merge(m, src)
+}
+func (m *FileExtractResult) XXX_Size() int {
+	return xxx_messageInfo_FileExtractResult.Size(m)
+}
+func (m *FileExtractResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileExtractResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileExtractResult proto.InternalMessageInfo
+
+func (m *FileExtractResult) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *FileExtractResult) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *FileExtractResult) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *FileExtractResult) GetError() *wrappers.StringValue {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func (m *FileExtractResult) GetFile() *File {
+	if m != nil {
+		return m.File
+	}
+	return nil
+}
+
+// FileExtractResponse represent the response of extracting
+type FileExtractResponse struct {
+	RequestId            uint64               `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Results              []*FileExtractResult `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *FileExtractResponse) Reset()         { *m = FileExtractResponse{} }
+func (m *FileExtractResponse) String() string { return proto.CompactTextString(m) }
+func (*FileExtractResponse) ProtoMessage()    {}
+func (*FileExtractResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fca1d823d5b515df, []int{2}
+}
+
+func (m *FileExtractResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileExtractResponse.Unmarshal(m, b)
+}
+func (m *FileExtractResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileExtractResponse.Marshal(b, m, deterministic)
+}
+func (m *FileExtractResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileExtractResponse.Merge(m, src)
+}
+func (m *FileExtractResponse) XXX_Size() int {
+	return xxx_messageInfo_FileExtractResponse.Size(m)
+}
+func (m *FileExtractResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileExtractResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileExtractResponse proto.InternalMessageInfo
+
+func (m *FileExtractResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *FileExtractResponse) GetResults() []*FileExtractResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*FileExtractRequest)(nil), "bigfile.file_extract.FileExtractRequest")
+	proto.RegisterType((*FileExtractResult)(nil), "bigfile.file_extract.FileExtractResult")
+	proto.RegisterType((*FileExtractResponse)(nil), "bigfile.file_extract.FileExtractResponse")
+}
+
+func init() { proto.RegisterFile("file_extract.proto", fileDescriptor_fca1d823d5b515df) }
+
+var fileDescriptor_fca1d823d5b515df = []byte{
+	// 485 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0x4f, 0x8f, 0xd3, 0x3e,
+	0x10, 0xfd, 0xb9, 0x4d, 0xff, 0x4d, 0xf5, 0x93, 0xc0, 0x54, 0xc8, 0x54, 0xcb, 0x6e, 0xd4, 0x03,
+	0x84, 0x8b, 0x2b, 0x15, 0xbe, 0x00, 0x95, 0x16, 0x09, 0x71, 0xa9, 0xbc, 0xfc, 0x91, 0xb8, 0x54,
+	0x6e, 0x3a, 0xcd, 0x5a, 0x24, 0x71, 0xb0, 0x1d, 0x5a, 0xf6, 0xe3, 0xc0, 0x8d, 0x1b, 0xdf, 0x8e,
+	0x23, 0x8a, 0x93, 0x2e, 0x59, 0xc1, 0xa1, 0x27, 0x7b, 0xde, 0xbc, 0x99, 0x79, 0xf3, 0x6c, 0xa0,
+	0x3b, 0x95, 0xe2, 0x1a, 0x0f, 0xce, 0xc8, 0xd8, 0xf1, 0xc2, 0x68, 0xa7, 0xe9, 0x64, 0xa3, 0x92,
+	0x0a, 0xe6, 0xed, 0xdc, 0x14, 0x3c, 0xe4, 0x19, 0xd3, 0xf3, 0x44, 0xeb, 0x24, 0xc5, 0xb9, 0x8f,
+	0x36, 0xe5, 0x6e, 0xbe, 0x37, 0xb2, 0x28, 0xd0, 0xd8, 0x3a, 0x3f, 0xfb, 0xde, 0x01, 0xfa, 0x4a,
+	0xa5, 0x78, 0x59, 0xd7, 0x0a, 0xfc, 0x5c, 0xa2, 0x75, 0x74, 0x02, 0x3d, 0xa7, 0x3f, 0x61, 0xce,
+	0x48, 0x48, 0xa2, 0x91, 0xa8, 0x03, 0xfa, 0x02, 0xfa, 0x16, 0x63, 0x83, 0x8e, 0x75, 0x42, 0x12,
+	0x8d, 0x17, 0x67, 0xbc, 0xee, 0xce, 0x8f, 0xdd, 0xf9, 0x95, 0x33, 0x2a, 0x4f, 0xde, 0xcb, 0xb4,
+	0x44, 0xd1, 0x70, 0xe9, 0x23, 0x18, 0x7a, 0x79, 0xa5, 0xda, 0xb2, 0xae, 0x6f, 0x37, 0xa8, 0xe2,
+	0x77, 0x6a, 0x4b, 0x1f, 0x42, 0xdf, 0x49, 0x93, 0xa0, 0x63, 0x81, 0x4f, 0x34, 0x51, 0x35, 0x68,
+	0xa7, 0x4d, 0x26, 0x1d, 0xeb, 0x9d, 0x32, 0xa8, 0xe6, 0xd2, 0x33, 0x18, 0xe9, 0x2f, 0x68, 0xf6,
+	0x46, 0x39, 0x64, 0xfd, 0x90, 0x44, 0x43, 0xf1, 0x07, 0xa0, 0x17, 0x30, 0xce, 0xe4, 0x61, 0x8d,
+	0xb9, 0x33, 0x0a, 0x2d, 0x1b, 0x84, 0x24, 0xfa, 0x5f, 0x40, 0x26, 0x0f, 0x97, 0x35, 0x52, 0xe9,
+	0xac, 0x08, 0x56, 0xdd, 0x20, 0x1b, 0x86, 0x24, 0x0a, 0xc4, 0x20, 0x93, 0x87, 0x2b, 0x75, 0x83,
+	0xb3, 0x9f, 0x04, 0xee, 0xdf, 0x71, 0xc9, 0x96, 0xa9, 0xa3, 0x14, 0x82, 0x5c, 0x66, 0xd8, 0x78,
+	0xe4, 0xef, 0x15, 0x56, 0x48, 0x77, 0xed, 0x0d, 0x1a, 0x09, 0x7f, 0xa7, 0x0c, 0x06, 0xb6, 0x8c,
+	0x63, 0xb4, 0xd6, 0xef, 0x3f, 0x14, 0xc7, 0x90, 0x2e, 0xa0, 0x87, 0xc6, 0x68, 0xc3, 0x82, 0x13,
+	0xd6, 0xac, 0xa9, 0xf4, 0x09, 0x04, 0x95, 0x7d, 0x8d, 0x33, 0x94, 0xb7, 0xbf, 0x00, 0xaf, 0x44,
+	0x0a, 0x9f, 0x9f, 0xed, 0xe1, 0xc1, 0x5d, 0xc9, 0x85, 0xce, 0x2d, 0xd2, 0xc7, 0x00, 0xa6, 0x7e,
+	0xe4, 0xb5, 0xda, 0x7a, 0xe9, 0x81, 0x18, 0x35, 0xc8, 0xeb, 0x2d, 0x7d, 0x09, 0x03, 0xe3, 0xb7,
+	0xb3, 0xac, 0x13, 0x76, 0xa3, 0xf1, 0xe2, 0x29, 0xff, 0xd7, 0x1f, 0xe3, 0x7f, 0xb9, 0x21, 0x8e,
+	0x75, 0x0b, 0x0b, 0xe3, 0x56, 0x96, 0x6e, 0x61, 0xbc, 0x6b, 0x85, 0xd1, 0x09, 0xfd, 0xbc, 0x98,
+	0xe9, 0xb3, 0x53, 0x26, 0xfb, 0xa5, 0x66, 0xff, 0x2d, 0x4b, 0x98, 0xc4, 0x3a, 0xbb, 0xad, 0x38,
+	0x1a, 0xb8, 0xbc, 0xd7, 0xa2, 0xaf, 0x2a, 0x70, 0x45, 0x3e, 0x9e, 0x27, 0xca, 0x5d, 0x97, 0x1b,
+	0x1e, 0xeb, 0x6c, 0xde, 0x14, 0xdc, 0x9e, 0xa6, 0x88, 0x7f, 0x11, 0xf2, 0xad, 0xd3, 0x5d, 0xae,
+	0xc4, 0x8f, 0xce, 0xc5, 0xb2, 0xe9, 0xb7, 0x3a, 0x3e, 0xc8, 0x07, 0x4c, 0xd3, 0x37, 0xb9, 0xde,
+	0xe7, 0x6f, 0xbf, 0x16, 0x68, 0x37, 0x7d, 0x3f, 0xe8, 0xf9, 0xef, 0x01, 0x00, 0xce, 0xea, 0x57,
+	0xc1, 0x9d, 0x03, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// FileExtractClient is the client API for FileExtract service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type FileExtractClient interface {
+	FileExtract(ctx context.Context, in *FileExtractRequest, opts ...grpc.CallOption) (*FileExtractResponse, error)
+}
+
+type fileExtractClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewFileExtractClient(cc *grpc.ClientConn) FileExtractClient {
+	return &fileExtractClient{cc}
+}
+
+func (c *fileExtractClient) FileExtract(ctx context.Context, in *FileExtractRequest, opts ...grpc.CallOption) (*FileExtractResponse, error) {
+	out := new(FileExtractResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.file_extract.FileExtract/fileExtract", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FileExtractServer is the server API for FileExtract service.
+type FileExtractServer interface {
+	FileExtract(context.Context, *FileExtractRequest) (*FileExtractResponse, error)
+}
+
+// UnimplementedFileExtractServer can be embedded to have forward compatible implementations.
+type UnimplementedFileExtractServer struct {
+}
+
+func (*UnimplementedFileExtractServer) FileExtract(ctx context.Context, req *FileExtractRequest) (*FileExtractResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileExtract not implemented")
+}
+
+func RegisterFileExtractServer(s *grpc.Server, srv FileExtractServer) {
+	s.RegisterService(&_FileExtract_serviceDesc, srv)
+}
+
+func _FileExtract_FileExtract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileExtractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileExtractServer).FileExtract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.file_extract.FileExtract/FileExtract",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileExtractServer).FileExtract(ctx, req.(*FileExtractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _FileExtract_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bigfile.file_extract.FileExtract",
+	HandlerType: (*FileExtractServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "fileExtract",
+			Handler:    _FileExtract_FileExtract_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "file_extract.proto",
+}
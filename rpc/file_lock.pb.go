@@ -0,0 +1,569 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: file_lock.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// FileLockAcquireRequest represent the request of acquiring a file lock,
+// ttl is the lease of lock in seconds
+type FileLockAcquireRequest struct {
+	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	FileUid              string                `protobuf:"bytes,2,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	Exclusive            bool                  `protobuf:"varint,3,opt,name=exclusive,proto3" json:"exclusive,omitempty"`
+	Ttl                  uint32                `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Secret               *wrappers.StringValue `protobuf:"bytes,5,opt,name=secret,proto3" json:"secret,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileLockAcquireRequest) Reset()         { *m = FileLockAcquireRequest{} }
+func (m *FileLockAcquireRequest) String() string { return proto.CompactTextString(m) }
+func (*FileLockAcquireRequest) ProtoMessage()    {}
+func (*FileLockAcquireRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ada2ce082547a62f, []int{0}
+}
+
+func (m *FileLockAcquireRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileLockAcquireRequest.Unmarshal(m, b)
+}
+func (m *FileLockAcquireRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileLockAcquireRequest.Marshal(b, m, deterministic)
+}
+func (m *FileLockAcquireRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileLockAcquireRequest.Merge(m, src)
+}
+func (m *FileLockAcquireRequest) XXX_Size() int {
+	return xxx_messageInfo_FileLockAcquireRequest.Size(m)
+}
+func (m *FileLockAcquireRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileLockAcquireRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileLockAcquireRequest proto.InternalMessageInfo
+
+func (m *FileLockAcquireRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *FileLockAcquireRequest) GetFileUid() string {
+	if m != nil {
+		return m.FileUid
+	}
+	return ""
+}
+
+func (m *FileLockAcquireRequest) GetExclusive() bool {
+	if m != nil {
+		return m.Exclusive
+	}
+	return false
+}
+
+func (m *FileLockAcquireRequest) GetTtl() uint32 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+func (m *FileLockAcquireRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+// FileLockAcquireResponse represent the response of acquiring a file lock
+type FileLockAcquireResponse struct {
+	RequestId            uint64    `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	FileUid              string    `protobuf:"bytes,2,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	Lock                 *FileLock `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *FileLockAcquireResponse) Reset()         { *m = FileLockAcquireResponse{} }
+func (m *FileLockAcquireResponse) String() string { return proto.CompactTextString(m) }
+func (*FileLockAcquireResponse) ProtoMessage()    {}
+func (*FileLockAcquireResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ada2ce082547a62f, []int{1}
+}
+
+func (m *FileLockAcquireResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileLockAcquireResponse.Unmarshal(m, b)
+}
+func (m *FileLockAcquireResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileLockAcquireResponse.Marshal(b, m, deterministic)
+}
+func (m *FileLockAcquireResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileLockAcquireResponse.Merge(m, src)
+}
+func (m *FileLockAcquireResponse) XXX_Size() int {
+	return xxx_messageInfo_FileLockAcquireResponse.Size(m)
+}
+func (m *FileLockAcquireResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileLockAcquireResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileLockAcquireResponse proto.InternalMessageInfo
+
+func (m *FileLockAcquireResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *FileLockAcquireResponse) GetFileUid() string {
+	if m != nil {
+		return m.FileUid
+	}
+	return ""
+}
+
+func (m *FileLockAcquireResponse) GetLock() *FileLock {
+	if m != nil {
+		return m.Lock
+	}
+	return nil
+}
+
+// FileLockRefreshRequest represent the request of refreshing a file lock
+type FileLockRefreshRequest struct {
+	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	LockUid              string                `protobuf:"bytes,2,opt,name=lock_uid,json=lockUid,proto3" json:"lock_uid,omitempty"`
+	Ttl                  uint32                `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Secret               *wrappers.StringValue `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileLockRefreshRequest) Reset()         { *m = FileLockRefreshRequest{} }
+func (m *FileLockRefreshRequest) String() string { return proto.CompactTextString(m) }
+func (*FileLockRefreshRequest) ProtoMessage()    {}
+func (*FileLockRefreshRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ada2ce082547a62f, []int{2}
+}
+
+func (m *FileLockRefreshRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileLockRefreshRequest.Unmarshal(m, b)
+}
+func (m *FileLockRefreshRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileLockRefreshRequest.Marshal(b, m, deterministic)
+}
+func (m *FileLockRefreshRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileLockRefreshRequest.Merge(m, src)
+}
+func (m *FileLockRefreshRequest) XXX_Size() int {
+	return xxx_messageInfo_FileLockRefreshRequest.Size(m)
+}
+func (m *FileLockRefreshRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileLockRefreshRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileLockRefreshRequest proto.InternalMessageInfo
+
+func (m *FileLockRefreshRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *FileLockRefreshRequest) GetLockUid() string {
+	if m != nil {
+		return m.LockUid
+	}
+	return ""
+}
+
+func (m *FileLockRefreshRequest) GetTtl() uint32 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+func (m *FileLockRefreshRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+// FileLockRefreshResponse represent the response of refreshing a file lock
+type FileLockRefreshResponse struct {
+	RequestId            uint64    `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Lock                 *FileLock `protobuf:"bytes,2,opt,name=lock,proto3" json:"lock,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *FileLockRefreshResponse) Reset()         { *m = FileLockRefreshResponse{} }
+func (m *FileLockRefreshResponse) String() string { return proto.CompactTextString(m) }
+func (*FileLockRefreshResponse) ProtoMessage()    {}
+func (*FileLockRefreshResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ada2ce082547a62f, []int{3}
+}
+
+func (m *FileLockRefreshResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileLockRefreshResponse.Unmarshal(m, b)
+}
+func (m *FileLockRefreshResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileLockRefreshResponse.Marshal(b, m, deterministic)
+}
+func (m *FileLockRefreshResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileLockRefreshResponse.Merge(m, src)
+}
+func (m *FileLockRefreshResponse) XXX_Size() int {
+	return xxx_messageInfo_FileLockRefreshResponse.Size(m)
+}
+func (m *FileLockRefreshResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileLockRefreshResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileLockRefreshResponse proto.InternalMessageInfo
+
+func (m *FileLockRefreshResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *FileLockRefreshResponse) GetLock() *FileLock {
+	if m != nil {
+		return m.Lock
+	}
+	return nil
+}
+
+// FileLockReleaseRequest represent the request of releasing a file lock
+type FileLockReleaseRequest struct {
+	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	LockUid              string                `protobuf:"bytes,2,opt,name=lock_uid,json=lockUid,proto3" json:"lock_uid,omitempty"`
+	Secret               *wrappers.StringValue `protobuf:"bytes,3,opt,name=secret,proto3" json:"secret,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileLockReleaseRequest) Reset()         { *m = FileLockReleaseRequest{} }
+func (m *FileLockReleaseRequest) String() string { return proto.CompactTextString(m) }
+func (*FileLockReleaseRequest) ProtoMessage()    {}
+func (*FileLockReleaseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ada2ce082547a62f, []int{4}
+}
+
+func (m *FileLockReleaseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileLockReleaseRequest.Unmarshal(m, b)
+}
+func (m *FileLockReleaseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileLockReleaseRequest.Marshal(b, m, deterministic)
+}
+func (m *FileLockReleaseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileLockReleaseRequest.Merge(m, src)
+}
+func (m *FileLockReleaseRequest) XXX_Size() int {
+	return xxx_messageInfo_FileLockReleaseRequest.Size(m)
+}
+func (m *FileLockReleaseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileLockReleaseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileLockReleaseRequest proto.InternalMessageInfo
+
+func (m *FileLockReleaseRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *FileLockReleaseRequest) GetLockUid() string {
+	if m != nil {
+		return m.LockUid
+	}
+	return ""
+}
+
+func (m *FileLockReleaseRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+// FileLockReleaseResponse represent the response of releasing a file lock
+type FileLockReleaseResponse struct {
+	RequestId            uint64    `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Lock                 *FileLock `protobuf:"bytes,2,opt,name=lock,proto3" json:"lock,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *FileLockReleaseResponse) Reset()         { *m = FileLockReleaseResponse{} }
+func (m *FileLockReleaseResponse) String() string { return proto.CompactTextString(m) }
+func (*FileLockReleaseResponse) ProtoMessage()    {}
+func (*FileLockReleaseResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ada2ce082547a62f, []int{5}
+}
+
+func (m *FileLockReleaseResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileLockReleaseResponse.Unmarshal(m, b)
+}
+func (m *FileLockReleaseResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileLockReleaseResponse.Marshal(b, m, deterministic)
+}
+func (m *FileLockReleaseResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileLockReleaseResponse.Merge(m, src)
+}
+func (m *FileLockReleaseResponse) XXX_Size() int {
+	return xxx_messageInfo_FileLockReleaseResponse.Size(m)
+}
+func (m *FileLockReleaseResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileLockReleaseResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileLockReleaseResponse proto.InternalMessageInfo
+
+func (m *FileLockReleaseResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *FileLockReleaseResponse) GetLock() *FileLock {
+	if m != nil {
+		return m.Lock
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*FileLockAcquireRequest)(nil), "bigfile.file_lock.FileLockAcquireRequest")
+	proto.RegisterType((*FileLockAcquireResponse)(nil), "bigfile.file_lock.FileLockAcquireResponse")
+	proto.RegisterType((*FileLockRefreshRequest)(nil), "bigfile.file_lock.FileLockRefreshRequest")
+	proto.RegisterType((*FileLockRefreshResponse)(nil), "bigfile.file_lock.FileLockRefreshResponse")
+	proto.RegisterType((*FileLockReleaseRequest)(nil), "bigfile.file_lock.FileLockReleaseRequest")
+	proto.RegisterType((*FileLockReleaseResponse)(nil), "bigfile.file_lock.FileLockReleaseResponse")
+}
+
+func init() { proto.RegisterFile("file_lock.proto", fileDescriptor_ada2ce082547a62f) }
+
+var fileDescriptor_ada2ce082547a62f = []byte{
+	// 466 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x53, 0xcf, 0x6e, 0xd3, 0x30,
+	0x18, 0xc7, 0x49, 0x37, 0x5a, 0x4f, 0x13, 0x60, 0x4d, 0x5b, 0x88, 0xc6, 0xa8, 0x7a, 0x2a, 0x3d,
+	0xb8, 0x52, 0xe1, 0x05, 0xe8, 0x01, 0x09, 0xc1, 0xa1, 0x32, 0xff, 0x24, 0x2e, 0x53, 0x93, 0x7c,
+	0xcd, 0x4c, 0xbd, 0x38, 0xb3, 0x13, 0x06, 0xa7, 0x3d, 0x03, 0xaf, 0xc0, 0x91, 0x0b, 0x6f, 0xc2,
+	0xf3, 0x70, 0x44, 0x76, 0xe2, 0x2e, 0xa8, 0xd5, 0x16, 0x2a, 0x4e, 0xed, 0xf7, 0x7d, 0x3f, 0xe7,
+	0xf7, 0xc7, 0xfe, 0xf0, 0xbd, 0x05, 0x17, 0x70, 0x2a, 0x64, 0xbc, 0xa4, 0xb9, 0x92, 0x85, 0x24,
+	0x0f, 0x22, 0x9e, 0x9a, 0x1e, 0x5d, 0x0d, 0x42, 0x6c, 0x6b, 0x3b, 0x0e, 0x4f, 0x52, 0x29, 0x53,
+	0x01, 0x63, 0x5b, 0x45, 0xe5, 0x62, 0x7c, 0xa9, 0xe6, 0x79, 0x0e, 0x4a, 0x57, 0xf3, 0xc1, 0x4f,
+	0x84, 0x0f, 0x5f, 0x70, 0x01, 0xaf, 0x65, 0xbc, 0x7c, 0x1e, 0x5f, 0x94, 0x5c, 0x01, 0x83, 0x8b,
+	0x12, 0x74, 0x41, 0x0e, 0xf0, 0x4e, 0x21, 0x97, 0x90, 0x05, 0xa8, 0x8f, 0x86, 0x3d, 0x56, 0x15,
+	0xe4, 0x21, 0xee, 0x5a, 0xa6, 0x92, 0x27, 0x81, 0x67, 0x07, 0x77, 0x4d, 0xfd, 0x8e, 0x27, 0xe4,
+	0x18, 0xf7, 0xe0, 0x4b, 0x2c, 0x4a, 0xcd, 0x3f, 0x43, 0xe0, 0xf7, 0xd1, 0xb0, 0xcb, 0xae, 0x1b,
+	0xe4, 0x3e, 0xf6, 0x8b, 0x42, 0x04, 0x9d, 0x3e, 0x1a, 0xee, 0x33, 0xf3, 0x97, 0x3c, 0xc3, 0xbb,
+	0x1a, 0x62, 0x05, 0x45, 0xb0, 0xd3, 0x47, 0xc3, 0xbd, 0xc9, 0x31, 0xad, 0xc4, 0x52, 0x27, 0x96,
+	0xbe, 0x29, 0x14, 0xcf, 0xd2, 0xf7, 0x73, 0x51, 0x02, 0xab, 0xb1, 0x83, 0x2b, 0x7c, 0xb4, 0x26,
+	0x58, 0xe7, 0x32, 0xd3, 0x40, 0x1e, 0x61, 0xac, 0x2a, 0xf1, 0xa7, 0x3c, 0xb1, 0xb2, 0x3b, 0xac,
+	0x57, 0x77, 0x5e, 0x26, 0x37, 0x49, 0x1f, 0xe1, 0x8e, 0x89, 0xce, 0xaa, 0xde, 0x9b, 0x1c, 0xd2,
+	0x66, 0xa8, 0xd4, 0xd1, 0x31, 0x8b, 0x19, 0x7c, 0x6b, 0x44, 0xc6, 0x60, 0xa1, 0x40, 0x9f, 0xdd,
+	0x1a, 0x99, 0x39, 0xd8, 0xe4, 0x35, 0xb5, 0xe1, 0xad, 0x43, 0xf1, 0x37, 0x85, 0xd2, 0xf9, 0x87,
+	0x50, 0x12, 0x7c, 0xb4, 0x26, 0xa9, 0x5d, 0x28, 0xce, 0xb9, 0xd7, 0xc2, 0xf9, 0x55, 0xd3, 0xb8,
+	0x80, 0xb9, 0x86, 0xad, 0x8d, 0x5f, 0xdb, 0xf4, 0xb7, 0xb5, 0x59, 0x0b, 0xf8, 0xef, 0x36, 0x27,
+	0xbf, 0x3c, 0xdc, 0x75, 0x2d, 0xf2, 0xa9, 0x5a, 0xb9, 0xc6, 0x73, 0x23, 0x4f, 0xe8, 0xda, 0xce,
+	0xd1, 0xcd, 0x3b, 0x14, 0x8e, 0xda, 0x40, 0x2b, 0x07, 0x83, 0x3b, 0x4d, 0xae, 0xfa, 0x16, 0x6f,
+	0xe4, 0xfa, 0xfb, 0xf1, 0x85, 0xa3, 0x36, 0xd0, 0xcd, 0x5c, 0x36, 0xca, 0x5b, 0xb8, 0x9a, 0xf7,
+	0x1d, 0x8e, 0xda, 0x40, 0x1d, 0xd7, 0x54, 0xe1, 0x83, 0x58, 0x9e, 0xaf, 0x8e, 0xb8, 0x2b, 0x9e,
+	0xee, 0xbb, 0x23, 0x33, 0xd3, 0x99, 0xa1, 0x8f, 0x27, 0x29, 0x2f, 0xce, 0xca, 0x88, 0xc6, 0xf2,
+	0x7c, 0x5c, 0xa3, 0x57, 0xbf, 0x2a, 0x8f, 0x7f, 0x23, 0xf4, 0xdd, 0xf3, 0xa7, 0x33, 0xf6, 0xc3,
+	0x7b, 0x3c, 0xad, 0x3f, 0x36, 0x73, 0xef, 0xe5, 0x03, 0x08, 0xf1, 0x2a, 0x93, 0x97, 0xd9, 0xdb,
+	0xaf, 0x39, 0xe8, 0x68, 0xd7, 0xb2, 0x3c, 0xfd, 0x33, 0x00, 0xa9, 0xaa, 0xec, 0xb5, 0x31, 0x05,
+	0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// FileLockClient is the client API for FileLock service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type FileLockClient interface {
+	FileLockAcquire(ctx context.Context, in *FileLockAcquireRequest, opts ...grpc.CallOption) (*FileLockAcquireResponse, error)
+	FileLockRefresh(ctx context.Context, in *FileLockRefreshRequest, opts ...grpc.CallOption) (*FileLockRefreshResponse, error)
+	FileLockRelease(ctx context.Context, in *FileLockReleaseRequest, opts ...grpc.CallOption) (*FileLockReleaseResponse, error)
+}
+
+type fileLockClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewFileLockClient(cc *grpc.ClientConn) FileLockClient {
+	return &fileLockClient{cc}
+}
+
+func (c *fileLockClient) FileLockAcquire(ctx context.Context, in *FileLockAcquireRequest, opts ...grpc.CallOption) (*FileLockAcquireResponse, error) {
+	out := new(FileLockAcquireResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.file_lock.FileLock/fileLockAcquire", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileLockClient) FileLockRefresh(ctx context.Context, in *FileLockRefreshRequest, opts ...grpc.CallOption) (*FileLockRefreshResponse, error) {
+	out := new(FileLockRefreshResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.file_lock.FileLock/fileLockRefresh", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileLockClient) FileLockRelease(ctx context.Context, in *FileLockReleaseRequest, opts ...grpc.CallOption) (*FileLockReleaseResponse, error) {
+	out := new(FileLockReleaseResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.file_lock.FileLock/fileLockRelease", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FileLockServer is the server API for FileLock service.
+type FileLockServer interface {
+	FileLockAcquire(context.Context, *FileLockAcquireRequest) (*FileLockAcquireResponse, error)
+	FileLockRefresh(context.Context, *FileLockRefreshRequest) (*FileLockRefreshResponse, error)
+	FileLockRelease(context.Context, *FileLockReleaseRequest) (*FileLockReleaseResponse, error)
+}
+
+// UnimplementedFileLockServer can be embedded to have forward compatible implementations.
+type UnimplementedFileLockServer struct {
+}
+
+func (*UnimplementedFileLockServer) FileLockAcquire(ctx context.Context, req *FileLockAcquireRequest) (*FileLockAcquireResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileLockAcquire not implemented")
+}
+func (*UnimplementedFileLockServer) FileLockRefresh(ctx context.Context, req *FileLockRefreshRequest) (*FileLockRefreshResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileLockRefresh not implemented")
+}
+func (*UnimplementedFileLockServer) FileLockRelease(ctx context.Context, req *FileLockReleaseRequest) (*FileLockReleaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileLockRelease not implemented")
+}
+
+func RegisterFileLockServer(s *grpc.Server, srv FileLockServer) {
+	s.RegisterService(&_FileLock_serviceDesc, srv)
+}
+
+func _FileLock_FileLockAcquire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileLockAcquireRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileLockServer).FileLockAcquire(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.file_lock.FileLock/FileLockAcquire",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileLockServer).FileLockAcquire(ctx, req.(*FileLockAcquireRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileLock_FileLockRefresh_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileLockRefreshRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileLockServer).FileLockRefresh(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.file_lock.FileLock/FileLockRefresh",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileLockServer).FileLockRefresh(ctx, req.(*FileLockRefreshRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileLock_FileLockRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileLockReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileLockServer).FileLockRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.file_lock.FileLock/FileLockRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileLockServer).FileLockRelease(ctx, req.(*FileLockReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _FileLock_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bigfile.file_lock.FileLock",
+	HandlerType: (*FileLockServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "fileLockAcquire",
+			Handler:    _FileLock_FileLockAcquire_Handler,
+		},
+		{
+			MethodName: "fileLockRefresh",
+			Handler:    _FileLock_FileLockRefresh_Handler,
+		},
+		{
+			MethodName: "fileLockRelease",
+			Handler:    _FileLock_FileLockRelease_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "file_lock.proto",
+}
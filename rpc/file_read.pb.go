@@ -26,11 +26,13 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
-// FileReadRequest represent the token delete request
+// FileReadRequest represent the file read request
 type FileReadRequest struct {
-	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
-	FileUid              string                `protobuf:"bytes,2,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
-	Secret               *wrappers.StringValue `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	Token   string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	FileUid string                `protobuf:"bytes,2,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	Secret  *wrappers.StringValue `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	// path is relative to the path of token, file_uid must be empty if it's given
+	Path                 *wrappers.StringValue `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
 	XXX_unrecognized     []byte                `json:"-"`
 	XXX_sizecache        int32                 `json:"-"`
@@ -82,7 +84,14 @@ func (m *FileReadRequest) GetSecret() *wrappers.StringValue {
 	return nil
 }
 
-// FileReadResponse represent the token delete response
+func (m *FileReadRequest) GetPath() *wrappers.StringValue {
+	if m != nil {
+		return m.Path
+	}
+	return nil
+}
+
+// FileReadResponse represent the file read response
 type FileReadResponse struct {
 	Content              []byte   `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -130,26 +139,27 @@ func init() {
 func init() { proto.RegisterFile("file_read.proto", fileDescriptor_1151b28b7b17b6ad) }
 
 var fileDescriptor_1151b28b7b17b6ad = []byte{
-	// 301 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x90, 0xbf, 0x4e, 0xf3, 0x30,
-	0x10, 0xc0, 0x3f, 0xf7, 0x83, 0xb6, 0x18, 0x50, 0x21, 0xea, 0x10, 0x2a, 0x54, 0xaa, 0xb0, 0x74,
-	0x40, 0x2e, 0x2a, 0x3c, 0x41, 0x06, 0x16, 0x96, 0xc8, 0xfc, 0xa9, 0xc4, 0x82, 0x12, 0xe7, 0x1a,
-	0x2c, 0x5c, 0xdb, 0xd8, 0x8e, 0x0a, 0xaf, 0xc3, 0xc8, 0x13, 0x32, 0xa2, 0x3a, 0x75, 0x91, 0x40,
-	0x62, 0xb2, 0xef, 0xee, 0xe7, 0xfb, 0xdd, 0x19, 0xf7, 0xe6, 0x5c, 0xc0, 0xa3, 0x81, 0xbc, 0x24,
-	0xda, 0x28, 0xa7, 0xa2, 0xc3, 0x82, 0x57, 0xab, 0x1c, 0xd9, 0x14, 0x06, 0xc3, 0x4a, 0xa9, 0x4a,
-	0xc0, 0xc4, 0x03, 0x45, 0x3d, 0x9f, 0x2c, 0x4d, 0xae, 0x35, 0x18, 0xdb, 0x3c, 0x49, 0x5e, 0x71,
-	0xef, 0x8a, 0x0b, 0xa0, 0x90, 0x97, 0x14, 0x5e, 0x6a, 0xb0, 0x2e, 0xea, 0xe3, 0x6d, 0xa7, 0x9e,
-	0x41, 0xc6, 0x68, 0x84, 0xc6, 0x3b, 0xb4, 0x09, 0xa2, 0x23, 0xdc, 0xf5, 0x5d, 0x6b, 0x5e, 0xc6,
-	0x2d, 0x5f, 0xe8, 0xac, 0xe2, 0x3b, 0x5e, 0x46, 0x97, 0xb8, 0x6d, 0x81, 0x19, 0x70, 0xf1, 0xd6,
-	0x08, 0x8d, 0x77, 0xa7, 0xc7, 0xa4, 0x91, 0x92, 0x20, 0x25, 0x37, 0xce, 0x70, 0x59, 0xdd, 0xe7,
-	0xa2, 0x06, 0xba, 0x66, 0x93, 0x33, 0x7c, 0xf0, 0x6d, 0xb6, 0x5a, 0x49, 0x0b, 0x51, 0x8c, 0x3b,
-	0x4c, 0x49, 0x07, 0xd2, 0x79, 0xf9, 0x1e, 0x0d, 0xe1, 0x94, 0xe1, 0x6e, 0xa0, 0xa3, 0x59, 0x33,
-	0x8a, 0xbf, 0x27, 0xe4, 0xd7, 0xce, 0xe4, 0xc7, 0x42, 0x83, 0xd3, 0x3f, 0x99, 0x46, 0x9d, 0xfc,
-	0x3b, 0x47, 0xa9, 0xc1, 0x7d, 0xa6, 0x16, 0x1b, 0x3a, 0x8c, 0x9f, 0xee, 0x07, 0x3a, 0x5b, 0x65,
-	0x32, 0xf4, 0x30, 0xac, 0xb8, 0x7b, 0xaa, 0x0b, 0xc2, 0xd4, 0x62, 0xb2, 0xa6, 0x37, 0xa7, 0xd1,
-	0xec, 0x13, 0xa1, 0xf7, 0xd6, 0xff, 0x34, 0xa3, 0x1f, 0xad, 0x93, 0x74, 0xdd, 0x2c, 0x0b, 0x7f,
-	0x31, 0x03, 0x21, 0xae, 0xa5, 0x5a, 0xca, 0xdb, 0x37, 0x0d, 0xb6, 0x68, 0x7b, 0xcb, 0xc5, 0x57,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0x0b, 0x43, 0x49, 0xbb, 0xcd, 0x01, 0x00, 0x00,
+	// 310 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x90, 0x4d, 0x4b, 0x03, 0x31,
+	0x10, 0x86, 0x4d, 0xed, 0x97, 0x51, 0xa9, 0x86, 0x1e, 0xd6, 0x22, 0xb5, 0xac, 0x97, 0x1e, 0x24,
+	0x2d, 0xd5, 0x5f, 0xb0, 0x07, 0x2f, 0x5e, 0x96, 0xf5, 0xa3, 0xe0, 0x45, 0xf6, 0x63, 0xba, 0x0d,
+	0x6e, 0x93, 0x98, 0x64, 0x29, 0xfe, 0x1d, 0x8f, 0xfd, 0x85, 0x1e, 0xa5, 0xd9, 0x66, 0x05, 0x05,
+	0xf1, 0x94, 0xcc, 0xcc, 0x33, 0xf3, 0xbe, 0xbc, 0xb8, 0xb7, 0x60, 0x05, 0xbc, 0x28, 0x88, 0x33,
+	0x2a, 0x95, 0x30, 0x82, 0x9c, 0x26, 0x2c, 0xdf, 0xf6, 0x68, 0x3d, 0x18, 0x0c, 0x73, 0x21, 0xf2,
+	0x02, 0x26, 0x16, 0x48, 0xca, 0xc5, 0x64, 0xad, 0x62, 0x29, 0x41, 0xe9, 0x6a, 0xc5, 0xdf, 0x20,
+	0xdc, 0xbb, 0x65, 0x05, 0x44, 0x10, 0x67, 0x11, 0xbc, 0x95, 0xa0, 0x0d, 0xe9, 0xe3, 0x96, 0x11,
+	0xaf, 0xc0, 0x3d, 0x34, 0x42, 0xe3, 0x83, 0xa8, 0x2a, 0xc8, 0x19, 0xee, 0xda, 0xb3, 0x25, 0xcb,
+	0xbc, 0x86, 0x1d, 0x74, 0xb6, 0xf5, 0x23, 0xcb, 0xc8, 0x0d, 0x6e, 0x6b, 0x48, 0x15, 0x18, 0xaf,
+	0x39, 0x42, 0xe3, 0xc3, 0xd9, 0x39, 0xad, 0x54, 0xa9, 0x53, 0xa5, 0xf7, 0x46, 0x31, 0x9e, 0x3f,
+	0xc5, 0x45, 0x09, 0xd1, 0x8e, 0x25, 0x53, 0xdc, 0x94, 0xb1, 0x59, 0x7a, 0xad, 0x7f, 0xec, 0x58,
+	0xd2, 0xbf, 0xc2, 0x27, 0xdf, 0x5e, 0xb5, 0x14, 0x5c, 0x03, 0xf1, 0x70, 0x27, 0x15, 0xdc, 0x00,
+	0x37, 0xd6, 0xee, 0x51, 0xe4, 0xca, 0x59, 0x8a, 0xbb, 0x8e, 0x26, 0xf3, 0xca, 0xbc, 0xfd, 0xfb,
+	0xf4, 0x57, 0x4c, 0xf4, 0x47, 0x04, 0x83, 0xcb, 0x3f, 0x99, 0x4a, 0xda, 0xdf, 0x9b, 0xa2, 0x40,
+	0xe1, 0x7e, 0x2a, 0x56, 0x35, 0xed, 0xcc, 0x07, 0xc7, 0x8e, 0x0e, 0xb7, 0x9d, 0x10, 0x3d, 0x0f,
+	0x73, 0x66, 0x96, 0x65, 0x42, 0x53, 0xb1, 0x9a, 0xec, 0xe8, 0xfa, 0x55, 0x32, 0xfd, 0x44, 0xe8,
+	0xa3, 0xb1, 0x1f, 0x84, 0xd1, 0xa6, 0x71, 0x11, 0xec, 0x8e, 0x85, 0x2e, 0x89, 0x39, 0x14, 0xc5,
+	0x1d, 0x17, 0x6b, 0xfe, 0xf0, 0x2e, 0x41, 0x27, 0x6d, 0xab, 0x72, 0xfd, 0x35, 0x00, 0x16, 0x26,
+	0x00, 0x2e, 0x00, 0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -0,0 +1,397 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: file_retention.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// FileRetentionRequest represent the request of changing retention and legal
+// hold by token, mode is one of 0(none), 1(governance) and 2(compliance). The
+// token is only able to extend the retention and place legal hold.
+type FileRetentionRequest struct {
+	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Secret               *wrappers.StringValue `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	FileUid              string                `protobuf:"bytes,3,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	Mode                 *wrappers.UInt32Value `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	RetainUntil          *timestamp.Timestamp  `protobuf:"bytes,5,opt,name=retain_until,json=retainUntil,proto3" json:"retain_until,omitempty"`
+	LegalHold            *wrappers.BoolValue   `protobuf:"bytes,6,opt,name=legal_hold,json=legalHold,proto3" json:"legal_hold,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileRetentionRequest) Reset()         { *m = FileRetentionRequest{} }
+func (m *FileRetentionRequest) String() string { return proto.CompactTextString(m) }
+func (*FileRetentionRequest) ProtoMessage()    {}
+func (*FileRetentionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0281cb27a1aa88df, []int{0}
+}
+
+func (m *FileRetentionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileRetentionRequest.Unmarshal(m, b)
+}
+func (m *FileRetentionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileRetentionRequest.Marshal(b, m, deterministic)
+}
+func (m *FileRetentionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileRetentionRequest.Merge(m, src)
+}
+func (m *FileRetentionRequest) XXX_Size() int {
+	return xxx_messageInfo_FileRetentionRequest.Size(m)
+}
+func (m *FileRetentionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileRetentionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileRetentionRequest proto.InternalMessageInfo
+
+func (m *FileRetentionRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *FileRetentionRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+func (m *FileRetentionRequest) GetFileUid() string {
+	if m != nil {
+		return m.FileUid
+	}
+	return ""
+}
+
+func (m *FileRetentionRequest) GetMode() *wrappers.UInt32Value {
+	if m != nil {
+		return m.Mode
+	}
+	return nil
+}
+
+func (m *FileRetentionRequest) GetRetainUntil() *timestamp.Timestamp {
+	if m != nil {
+		return m.RetainUntil
+	}
+	return nil
+}
+
+func (m *FileRetentionRequest) GetLegalHold() *wrappers.BoolValue {
+	if m != nil {
+		return m.LegalHold
+	}
+	return nil
+}
+
+// FileRetentionAdminRequest represent the request of changing retention and
+// legal hold by app, the retention in governance mode can be shortened.
+type FileRetentionAdminRequest struct {
+	AppUid               string                `protobuf:"bytes,1,opt,name=app_uid,json=appUid,proto3" json:"app_uid,omitempty"`
+	AppSecret            string                `protobuf:"bytes,2,opt,name=app_secret,json=appSecret,proto3" json:"app_secret,omitempty"`
+	FileUid              string                `protobuf:"bytes,3,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	Mode                 *wrappers.UInt32Value `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	RetainUntil          *timestamp.Timestamp  `protobuf:"bytes,5,opt,name=retain_until,json=retainUntil,proto3" json:"retain_until,omitempty"`
+	LegalHold            *wrappers.BoolValue   `protobuf:"bytes,6,opt,name=legal_hold,json=legalHold,proto3" json:"legal_hold,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileRetentionAdminRequest) Reset()         { *m = FileRetentionAdminRequest{} }
+func (m *FileRetentionAdminRequest) String() string { return proto.CompactTextString(m) }
+func (*FileRetentionAdminRequest) ProtoMessage()    {}
+func (*FileRetentionAdminRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0281cb27a1aa88df, []int{1}
+}
+
+func (m *FileRetentionAdminRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileRetentionAdminRequest.Unmarshal(m, b)
+}
+func (m *FileRetentionAdminRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileRetentionAdminRequest.Marshal(b, m, deterministic)
+}
+func (m *FileRetentionAdminRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileRetentionAdminRequest.Merge(m, src)
+}
+func (m *FileRetentionAdminRequest) XXX_Size() int {
+	return xxx_messageInfo_FileRetentionAdminRequest.Size(m)
+}
+func (m *FileRetentionAdminRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileRetentionAdminRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileRetentionAdminRequest proto.InternalMessageInfo
+
+func (m *FileRetentionAdminRequest) GetAppUid() string {
+	if m != nil {
+		return m.AppUid
+	}
+	return ""
+}
+
+func (m *FileRetentionAdminRequest) GetAppSecret() string {
+	if m != nil {
+		return m.AppSecret
+	}
+	return ""
+}
+
+func (m *FileRetentionAdminRequest) GetFileUid() string {
+	if m != nil {
+		return m.FileUid
+	}
+	return ""
+}
+
+func (m *FileRetentionAdminRequest) GetMode() *wrappers.UInt32Value {
+	if m != nil {
+		return m.Mode
+	}
+	return nil
+}
+
+func (m *FileRetentionAdminRequest) GetRetainUntil() *timestamp.Timestamp {
+	if m != nil {
+		return m.RetainUntil
+	}
+	return nil
+}
+
+func (m *FileRetentionAdminRequest) GetLegalHold() *wrappers.BoolValue {
+	if m != nil {
+		return m.LegalHold
+	}
+	return nil
+}
+
+// FileRetentionResponse represent the response of changing retention
+type FileRetentionResponse struct {
+	RequestId            uint64   `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	File                 *File    `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FileRetentionResponse) Reset()         { *m = FileRetentionResponse{} }
+func (m *FileRetentionResponse) String() string { return proto.CompactTextString(m) }
+func (*FileRetentionResponse) ProtoMessage()    {}
+func (*FileRetentionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0281cb27a1aa88df, []int{2}
+}
+
+func (m *FileRetentionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileRetentionResponse.Unmarshal(m, b)
+}
+func (m *FileRetentionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileRetentionResponse.Marshal(b, m, deterministic)
+}
+func (m *FileRetentionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileRetentionResponse.Merge(m, src)
+}
+func (m *FileRetentionResponse) XXX_Size() int {
+	return xxx_messageInfo_FileRetentionResponse.Size(m)
+}
+func (m *FileRetentionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileRetentionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileRetentionResponse proto.InternalMessageInfo
+
+func (m *FileRetentionResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *FileRetentionResponse) GetFile() *File {
+	if m != nil {
+		return m.File
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*FileRetentionRequest)(nil), "bigfile.file_retention.FileRetentionRequest")
+	proto.RegisterType((*FileRetentionAdminRequest)(nil), "bigfile.file_retention.FileRetentionAdminRequest")
+	proto.RegisterType((*FileRetentionResponse)(nil), "bigfile.file_retention.FileRetentionResponse")
+}
+
+func init() { proto.RegisterFile("file_retention.proto", fileDescriptor_0281cb27a1aa88df) }
+
+var fileDescriptor_0281cb27a1aa88df = []byte{
+	// 471 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0xc5, 0x6e, 0x9a, 0xe2, 0x29, 0xbd, 0xac, 0x02, 0xb8, 0x11, 0xb4, 0x55, 0x0e, 0xa8, 0x07,
+	0xd8, 0x40, 0xca, 0x85, 0x03, 0x07, 0x7c, 0x40, 0x54, 0x5c, 0x22, 0xb7, 0x01, 0x89, 0x03, 0x91,
+	0x13, 0x4f, 0xdc, 0x15, 0xeb, 0xdd, 0x65, 0xbd, 0x56, 0xe0, 0xaf, 0x70, 0xe4, 0x06, 0xbf, 0x10,
+	0x6e, 0xc8, 0xbb, 0x76, 0x14, 0xf7, 0x43, 0x0a, 0xd7, 0x9e, 0xac, 0x9d, 0x79, 0xef, 0x8d, 0xde,
+	0x9b, 0x31, 0xf4, 0x16, 0x8c, 0xe3, 0x54, 0xa3, 0x41, 0x61, 0x98, 0x14, 0x54, 0x69, 0x69, 0x24,
+	0x79, 0x30, 0x63, 0x59, 0xd5, 0xa0, 0xed, 0x6e, 0x1f, 0x6c, 0xd1, 0x62, 0xfa, 0x87, 0x99, 0x94,
+	0x19, 0xc7, 0xa1, 0x7d, 0xcd, 0xca, 0xc5, 0xd0, 0xb0, 0x1c, 0x0b, 0x93, 0xe4, 0xaa, 0x06, 0x1c,
+	0x5c, 0x06, 0x2c, 0x75, 0xa2, 0x14, 0xea, 0xc2, 0xf5, 0x07, 0xbf, 0x7c, 0xe8, 0xbd, 0x65, 0x1c,
+	0xe3, 0x46, 0x3e, 0xc6, 0xaf, 0x25, 0x16, 0x86, 0xf4, 0x60, 0xdb, 0xc8, 0x2f, 0x28, 0x42, 0xef,
+	0xc8, 0x3b, 0x0e, 0x62, 0xf7, 0x20, 0x2f, 0xa1, 0x5b, 0xe0, 0x5c, 0xa3, 0x09, 0xfd, 0x23, 0xef,
+	0x78, 0x77, 0xf4, 0x88, 0x3a, 0x7d, 0xda, 0xe8, 0xd3, 0x33, 0xa3, 0x99, 0xc8, 0x3e, 0x24, 0xbc,
+	0xc4, 0xb8, 0xc6, 0x92, 0x7d, 0xb8, 0x6b, 0x3d, 0x94, 0x2c, 0x0d, 0xb7, 0xac, 0xdc, 0x4e, 0xf5,
+	0x9e, 0xb0, 0x94, 0x3c, 0x87, 0x4e, 0x2e, 0x53, 0x0c, 0x3b, 0x37, 0xc8, 0x4d, 0x4e, 0x85, 0x39,
+	0x19, 0x39, 0x39, 0x8b, 0x24, 0xaf, 0xe1, 0x9e, 0x46, 0x93, 0x30, 0x31, 0x2d, 0x85, 0x61, 0x3c,
+	0xdc, 0xb6, 0xcc, 0xfe, 0x15, 0xe6, 0x79, 0x93, 0x44, 0xbc, 0xeb, 0xf0, 0x93, 0x0a, 0x4e, 0x5e,
+	0x01, 0x70, 0xcc, 0x12, 0x3e, 0xbd, 0x90, 0x3c, 0x0d, 0xbb, 0x37, 0x90, 0x23, 0x29, 0xb9, 0x1b,
+	0x1a, 0x58, 0xf4, 0x3b, 0xc9, 0xd3, 0xc1, 0x0f, 0x1f, 0xf6, 0x5b, 0x59, 0xbd, 0x49, 0x73, 0xb6,
+	0x0a, 0xec, 0x21, 0xec, 0x24, 0x4a, 0x59, 0x8f, 0x2e, 0xb2, 0x6e, 0xa2, 0x54, 0x65, 0xf1, 0x31,
+	0x40, 0xd5, 0x58, 0xcb, 0x2d, 0x88, 0x83, 0x44, 0xa9, 0xb3, 0x5b, 0x15, 0xce, 0x67, 0xb8, 0x7f,
+	0xe9, 0x8e, 0x0a, 0x25, 0x45, 0x81, 0x95, 0x7d, 0xed, 0x22, 0x9a, 0xd6, 0xd1, 0x74, 0xe2, 0xa0,
+	0xae, 0x9c, 0xa6, 0xe4, 0x09, 0x74, 0x2a, 0xbb, 0xf5, 0x3d, 0x11, 0xba, 0x7e, 0xf4, 0xd4, 0x2a,
+	0xda, 0xfe, 0xe8, 0xaf, 0x07, 0x7b, 0xad, 0x01, 0x44, 0xc0, 0xde, 0xa2, 0x55, 0x78, 0x4a, 0xaf,
+	0xff, 0x63, 0xe8, 0x75, 0x07, 0xde, 0x7f, 0xb6, 0x21, 0xda, 0xd9, 0x18, 0xdc, 0x21, 0xdf, 0x80,
+	0x2c, 0xae, 0x6c, 0x9f, 0xbc, 0xd8, 0x48, 0x66, 0xfd, 0x52, 0xfe, 0x7b, 0x72, 0xb4, 0x84, 0xde,
+	0x5c, 0xe6, 0x2b, 0x56, 0xb3, 0x88, 0x88, 0xb4, 0x08, 0xe3, 0xaa, 0x3c, 0xf6, 0x3e, 0x1d, 0x64,
+	0xcc, 0x5c, 0x94, 0x33, 0x3a, 0x97, 0xf9, 0xb0, 0xa6, 0xac, 0xbe, 0x5a, 0xcd, 0xff, 0x78, 0xde,
+	0x4f, 0x7f, 0x2b, 0x1a, 0xc7, 0xbf, 0xfd, 0xc3, 0xa8, 0x56, 0x1c, 0x37, 0xab, 0xfd, 0x88, 0x9c,
+	0xbf, 0x17, 0x72, 0x29, 0xce, 0xbf, 0x2b, 0x2c, 0x66, 0x5d, 0x3b, 0xea, 0xe4, 0xdf, 0x00, 0x7d,
+	0xa7, 0xf6, 0xd8, 0xa1, 0x04, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// FileRetentionClient is the client API for FileRetention service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type FileRetentionClient interface {
+	FileRetention(ctx context.Context, in *FileRetentionRequest, opts ...grpc.CallOption) (*FileRetentionResponse, error)
+	FileRetentionAdmin(ctx context.Context, in *FileRetentionAdminRequest, opts ...grpc.CallOption) (*FileRetentionResponse, error)
+}
+
+type fileRetentionClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewFileRetentionClient(cc *grpc.ClientConn) FileRetentionClient {
+	return &fileRetentionClient{cc}
+}
+
+func (c *fileRetentionClient) FileRetention(ctx context.Context, in *FileRetentionRequest, opts ...grpc.CallOption) (*FileRetentionResponse, error) {
+	out := new(FileRetentionResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.file_retention.FileRetention/fileRetention", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileRetentionClient) FileRetentionAdmin(ctx context.Context, in *FileRetentionAdminRequest, opts ...grpc.CallOption) (*FileRetentionResponse, error) {
+	out := new(FileRetentionResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.file_retention.FileRetention/fileRetentionAdmin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FileRetentionServer is the server API for FileRetention service.
+type FileRetentionServer interface {
+	FileRetention(context.Context, *FileRetentionRequest) (*FileRetentionResponse, error)
+	FileRetentionAdmin(context.Context, *FileRetentionAdminRequest) (*FileRetentionResponse, error)
+}
+
+// UnimplementedFileRetentionServer can be embedded to have forward compatible implementations.
+type UnimplementedFileRetentionServer struct {
+}
+
+func (*UnimplementedFileRetentionServer) FileRetention(ctx context.Context, req *FileRetentionRequest) (*FileRetentionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileRetention not implemented")
+}
+func (*UnimplementedFileRetentionServer) FileRetentionAdmin(ctx context.Context, req *FileRetentionAdminRequest) (*FileRetentionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileRetentionAdmin not implemented")
+}
+
+func RegisterFileRetentionServer(s *grpc.Server, srv FileRetentionServer) {
+	s.RegisterService(&_FileRetention_serviceDesc, srv)
+}
+
+func _FileRetention_FileRetention_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRetentionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileRetentionServer).FileRetention(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.file_retention.FileRetention/FileRetention",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileRetentionServer).FileRetention(ctx, req.(*FileRetentionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileRetention_FileRetentionAdmin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRetentionAdminRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileRetentionServer).FileRetentionAdmin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.file_retention.FileRetention/FileRetentionAdmin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileRetentionServer).FileRetentionAdmin(ctx, req.(*FileRetentionAdminRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _FileRetention_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bigfile.file_retention.FileRetention",
+	HandlerType: (*FileRetentionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "fileRetention",
+			Handler:    _FileRetention_FileRetention_Handler,
+		},
+		{
+			MethodName: "fileRetentionAdmin",
+			Handler:    _FileRetention_FileRetentionAdmin_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "file_retention.proto",
+}
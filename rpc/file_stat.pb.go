@@ -0,0 +1,292 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: file_stat.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// FileStatRequest represent the file stat request, the file is found by
+// path if it's given, which is relative to the path of token
+type FileStatRequest struct {
+	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	FileUid              string                `protobuf:"bytes,2,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	Secret               *wrappers.StringValue `protobuf:"bytes,3,opt,name=secret,proto3" json:"secret,omitempty"`
+	Path                 *wrappers.StringValue `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *FileStatRequest) Reset()         { *m = FileStatRequest{} }
+func (m *FileStatRequest) String() string { return proto.CompactTextString(m) }
+func (*FileStatRequest) ProtoMessage()    {}
+func (*FileStatRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2b229d938a53d7d6, []int{0}
+}
+
+func (m *FileStatRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileStatRequest.Unmarshal(m, b)
+}
+func (m *FileStatRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileStatRequest.Marshal(b, m, deterministic)
+}
+func (m *FileStatRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileStatRequest.Merge(m, src)
+}
+func (m *FileStatRequest) XXX_Size() int {
+	return xxx_messageInfo_FileStatRequest.Size(m)
+}
+func (m *FileStatRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileStatRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileStatRequest proto.InternalMessageInfo
+
+func (m *FileStatRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *FileStatRequest) GetFileUid() string {
+	if m != nil {
+		return m.FileUid
+	}
+	return ""
+}
+
+func (m *FileStatRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+func (m *FileStatRequest) GetPath() *wrappers.StringValue {
+	if m != nil {
+		return m.Path
+	}
+	return nil
+}
+
+// FileStatResponse represent the file stat response, mime_type is empty
+// for directory, and version_count is the number of previous versions
+type FileStatResponse struct {
+	RequestId            uint64               `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	File                 *File                `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	MimeType             string               `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	VersionCount         uint32               `protobuf:"varint,4,opt,name=version_count,json=versionCount,proto3" json:"version_count,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *FileStatResponse) Reset()         { *m = FileStatResponse{} }
+func (m *FileStatResponse) String() string { return proto.CompactTextString(m) }
+func (*FileStatResponse) ProtoMessage()    {}
+func (*FileStatResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2b229d938a53d7d6, []int{1}
+}
+
+func (m *FileStatResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileStatResponse.Unmarshal(m, b)
+}
+func (m *FileStatResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileStatResponse.Marshal(b, m, deterministic)
+}
+func (m *FileStatResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileStatResponse.Merge(m, src)
+}
+func (m *FileStatResponse) XXX_Size() int {
+	return xxx_messageInfo_FileStatResponse.Size(m)
+}
+func (m *FileStatResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileStatResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileStatResponse proto.InternalMessageInfo
+
+func (m *FileStatResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *FileStatResponse) GetFile() *File {
+	if m != nil {
+		return m.File
+	}
+	return nil
+}
+
+func (m *FileStatResponse) GetMimeType() string {
+	if m != nil {
+		return m.MimeType
+	}
+	return ""
+}
+
+func (m *FileStatResponse) GetVersionCount() uint32 {
+	if m != nil {
+		return m.VersionCount
+	}
+	return 0
+}
+
+func (m *FileStatResponse) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *FileStatResponse) GetUpdatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*FileStatRequest)(nil), "bigfile.file_stat.FileStatRequest")
+	proto.RegisterType((*FileStatResponse)(nil), "bigfile.file_stat.FileStatResponse")
+}
+
+func init() { proto.RegisterFile("file_stat.proto", fileDescriptor_2b229d938a53d7d6) }
+
+var fileDescriptor_2b229d938a53d7d6 = []byte{
+	// 429 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xc1, 0x6e, 0xd3, 0x40,
+	0x10, 0x86, 0x71, 0x9a, 0x86, 0x78, 0x4a, 0x54, 0x58, 0xf5, 0x60, 0x02, 0xb4, 0x55, 0x2a, 0xa1,
+	0x9e, 0x36, 0x28, 0x70, 0xe1, 0xd8, 0x20, 0x21, 0x21, 0x2e, 0xd1, 0xb6, 0x05, 0x89, 0x8b, 0xb5,
+	0xb1, 0xa7, 0xee, 0x0a, 0xdb, 0xbb, 0xec, 0x8e, 0xa9, 0xfa, 0x18, 0xbc, 0x02, 0xc7, 0x3e, 0x21,
+	0x47, 0xe4, 0xf5, 0x3a, 0xa0, 0x56, 0x82, 0x9e, 0x92, 0xf9, 0xe7, 0x9b, 0x9d, 0x7f, 0x66, 0x0c,
+	0xbb, 0x17, 0xaa, 0xc4, 0xd4, 0x91, 0x24, 0x6e, 0xac, 0x26, 0xcd, 0x9e, 0xac, 0x55, 0xd1, 0x6a,
+	0x7c, 0x93, 0x98, 0x82, 0x8f, 0x7d, 0x7a, 0x7a, 0x50, 0x68, 0x5d, 0x94, 0x38, 0xf7, 0xd1, 0xba,
+	0xb9, 0x98, 0x93, 0xaa, 0xd0, 0x91, 0xac, 0x4c, 0x00, 0xf6, 0x6f, 0x03, 0x57, 0x56, 0x1a, 0x83,
+	0xd6, 0x75, 0xf9, 0xd9, 0x4d, 0x04, 0xbb, 0xef, 0x55, 0x89, 0xa7, 0x24, 0x49, 0xe0, 0xb7, 0x06,
+	0x1d, 0xb1, 0x3d, 0xd8, 0x26, 0xfd, 0x15, 0xeb, 0x24, 0x3a, 0x8c, 0x8e, 0x63, 0xd1, 0x05, 0xec,
+	0x29, 0x8c, 0xbd, 0x87, 0x46, 0xe5, 0xc9, 0xc0, 0x27, 0x1e, 0xb6, 0xf1, 0xb9, 0xca, 0xd9, 0x1b,
+	0x18, 0x39, 0xcc, 0x2c, 0x52, 0xb2, 0x75, 0x18, 0x1d, 0xef, 0x2c, 0x9e, 0xf3, 0xae, 0x2b, 0xef,
+	0xbb, 0xf2, 0x53, 0xb2, 0xaa, 0x2e, 0x3e, 0xc9, 0xb2, 0x41, 0x11, 0x58, 0xf6, 0x0a, 0x86, 0x46,
+	0xd2, 0x65, 0x32, 0xbc, 0x47, 0x8d, 0x27, 0x67, 0x3f, 0x06, 0xf0, 0xf8, 0x8f, 0x59, 0x67, 0x74,
+	0xed, 0x90, 0xbd, 0x00, 0xb0, 0x9d, 0xf1, 0x54, 0xe5, 0xde, 0xf2, 0x50, 0xc4, 0x41, 0xf9, 0x90,
+	0xb3, 0x97, 0x30, 0x6c, 0x6d, 0x7a, 0xcb, 0x3b, 0x0b, 0xc6, 0xff, 0xde, 0x27, 0x6f, 0x1f, 0x13,
+	0x3e, 0xcf, 0x9e, 0x41, 0x5c, 0xa9, 0x0a, 0x53, 0xba, 0x36, 0xe8, 0xc7, 0x88, 0xc5, 0xb8, 0x15,
+	0xce, 0xae, 0x0d, 0xb2, 0x23, 0x98, 0x7c, 0x47, 0xeb, 0x94, 0xae, 0xd3, 0x4c, 0x37, 0x35, 0x79,
+	0xcf, 0x13, 0xf1, 0x28, 0x88, 0xef, 0x5a, 0x8d, 0xbd, 0x05, 0xc8, 0x2c, 0x4a, 0xc2, 0x3c, 0x95,
+	0x94, 0x6c, 0xfb, 0x7e, 0xd3, 0x3b, 0x53, 0x9d, 0xf5, 0x07, 0x12, 0x71, 0xa0, 0x4f, 0x7c, 0x69,
+	0x63, 0xf2, 0xbe, 0x74, 0xf4, 0xff, 0xd2, 0x40, 0x9f, 0xd0, 0x42, 0xc2, 0xb8, 0x5f, 0x09, 0x3b,
+	0xef, 0x4e, 0xe4, 0xff, 0xcf, 0xf8, 0x9d, 0x2f, 0x87, 0xdf, 0x3a, 0xf4, 0xf4, 0xe8, 0x9f, 0x4c,
+	0xb7, 0xdf, 0xd9, 0x83, 0xa5, 0x85, 0xbd, 0x4c, 0x57, 0x1b, 0xb6, 0xf7, 0xb3, 0x9c, 0xf4, 0xec,
+	0xaa, 0x55, 0x56, 0xd1, 0x97, 0xfd, 0x42, 0xd1, 0x65, 0xb3, 0xe6, 0x99, 0xae, 0xe6, 0x81, 0xde,
+	0xfc, 0x5a, 0x93, 0xfd, 0x8a, 0xa2, 0x9f, 0x83, 0xad, 0xe5, 0x4a, 0xdc, 0x0c, 0x0e, 0x96, 0xe1,
+	0xb1, 0x55, 0x3f, 0xdc, 0x67, 0x2c, 0xcb, 0x8f, 0xb5, 0xbe, 0xaa, 0xdb, 0x85, 0xbb, 0xf5, 0xc8,
+	0x77, 0x79, 0xfd, 0x7b, 0x00, 0x57, 0x04, 0xb1, 0x37, 0x11, 0x03, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// FileStatClient is the client API for FileStat service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type FileStatClient interface {
+	FileStat(ctx context.Context, in *FileStatRequest, opts ...grpc.CallOption) (*FileStatResponse, error)
+}
+
+type fileStatClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewFileStatClient(cc *grpc.ClientConn) FileStatClient {
+	return &fileStatClient{cc}
+}
+
+func (c *fileStatClient) FileStat(ctx context.Context, in *FileStatRequest, opts ...grpc.CallOption) (*FileStatResponse, error) {
+	out := new(FileStatResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.file_stat.FileStat/fileStat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FileStatServer is the server API for FileStat service.
+type FileStatServer interface {
+	FileStat(context.Context, *FileStatRequest) (*FileStatResponse, error)
+}
+
+// UnimplementedFileStatServer can be embedded to have forward compatible implementations.
+type UnimplementedFileStatServer struct {
+}
+
+func (*UnimplementedFileStatServer) FileStat(ctx context.Context, req *FileStatRequest) (*FileStatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileStat not implemented")
+}
+
+func RegisterFileStatServer(s *grpc.Server, srv FileStatServer) {
+	s.RegisterService(&_FileStat_serviceDesc, srv)
+}
+
+func _FileStat_FileStat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileStatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileStatServer).FileStat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.file_stat.FileStat/FileStat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileStatServer).FileStat(ctx, req.(*FileStatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _FileStat_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bigfile.file_stat.FileStat",
+	HandlerType: (*FileStatServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "fileStat",
+			Handler:    _FileStat_FileStat_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "file_stat.proto",
+}
@@ -9,6 +9,7 @@ import (
 	math "math"
 
 	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -28,14 +29,19 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 // FileUpdateRequest represent the file update request
 type FileUpdateRequest struct {
-	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
-	FileUid              string                `protobuf:"bytes,2,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
-	Path                 string                `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
-	Secret               *wrappers.StringValue `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
-	Hidden               *wrappers.BoolValue   `protobuf:"bytes,5,opt,name=hidden,proto3" json:"hidden,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
-	XXX_unrecognized     []byte                `json:"-"`
-	XXX_sizecache        int32                 `json:"-"`
+	Token   string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	FileUid string                `protobuf:"bytes,2,opt,name=file_uid,json=fileUid,proto3" json:"file_uid,omitempty"`
+	Path    string                `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Secret  *wrappers.StringValue `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	Hidden  *wrappers.BoolValue   `protobuf:"bytes,5,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	// if_match and if_none_match are compared with the hash of file
+	IfMatch     *wrappers.StringValue `protobuf:"bytes,6,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	IfNoneMatch *wrappers.StringValue `protobuf:"bytes,7,opt,name=if_none_match,json=ifNoneMatch,proto3" json:"if_none_match,omitempty"`
+	// expired_at with zero seconds removes the expiry of file
+	ExpiredAt            *timestamp.Timestamp `protobuf:"bytes,8,opt,name=expired_at,json=expiredAt,proto3" json:"expired_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *FileUpdateRequest) Reset()         { *m = FileUpdateRequest{} }
@@ -98,7 +104,28 @@ func (m *FileUpdateRequest) GetHidden() *wrappers.BoolValue {
 	return nil
 }
 
-// FileUpdateResponse represent the response from creating file
+func (m *FileUpdateRequest) GetIfMatch() *wrappers.StringValue {
+	if m != nil {
+		return m.IfMatch
+	}
+	return nil
+}
+
+func (m *FileUpdateRequest) GetIfNoneMatch() *wrappers.StringValue {
+	if m != nil {
+		return m.IfNoneMatch
+	}
+	return nil
+}
+
+func (m *FileUpdateRequest) GetExpiredAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiredAt
+	}
+	return nil
+}
+
+// FileUpdateResponse represent the response from updating file
 type FileUpdateResponse struct {
 	RequestId            uint64   `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	File                 *File    `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
@@ -154,30 +181,35 @@ func init() {
 func init() { proto.RegisterFile("file_update.proto", fileDescriptor_7bb90a24ce583932) }
 
 var fileDescriptor_7bb90a24ce583932 = []byte{
-	// 359 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x50, 0xcd, 0x4e, 0xf2, 0x40,
-	0x14, 0xfd, 0x0a, 0x85, 0x4f, 0x2e, 0x0b, 0xc3, 0xc8, 0xa2, 0x36, 0x8a, 0x84, 0x05, 0xb2, 0x1a,
-	0x92, 0xea, 0x13, 0x74, 0x61, 0x62, 0xdc, 0x34, 0x55, 0x34, 0xd1, 0x05, 0xe9, 0xcf, 0xa5, 0x4c,
-	0x2c, 0x9d, 0xb1, 0x9d, 0x86, 0xf8, 0x3a, 0x2e, 0x7d, 0x0c, 0x9f, 0xca, 0xa5, 0xe9, 0x4c, 0xf9,
-	0x49, 0x30, 0x71, 0xd5, 0xde, 0x73, 0xcf, 0xb9, 0x73, 0xce, 0x81, 0xde, 0x82, 0xa5, 0x38, 0x2f,
-	0x45, 0x1c, 0x48, 0xa4, 0x22, 0xe7, 0x92, 0x93, 0x93, 0x90, 0x25, 0x15, 0x4a, 0xf7, 0x56, 0x36,
-	0x28, 0x44, 0x11, 0xec, 0x41, 0xc2, 0x79, 0x92, 0xe2, 0x54, 0x4d, 0x61, 0xb9, 0x98, 0xae, 0xf3,
-	0x40, 0x08, 0xcc, 0x0b, 0xbd, 0x1f, 0x7d, 0x19, 0xd0, 0xbb, 0x61, 0x29, 0xce, 0x94, 0xd4, 0xc7,
-	0xb7, 0x12, 0x0b, 0x49, 0xfa, 0xd0, 0x92, 0xfc, 0x15, 0x33, 0xcb, 0x18, 0x1a, 0x93, 0x8e, 0xaf,
-	0x07, 0x72, 0x0a, 0x47, 0xfa, 0x19, 0x16, 0x5b, 0x0d, 0xb5, 0xf8, 0x5f, 0xcd, 0x33, 0x16, 0x13,
-	0x02, 0xa6, 0x08, 0xe4, 0xd2, 0x6a, 0x2a, 0x58, 0xfd, 0x93, 0x6b, 0x68, 0x17, 0x18, 0xe5, 0x28,
-	0x2d, 0x73, 0x68, 0x4c, 0xba, 0xce, 0x19, 0xd5, 0x5e, 0xe8, 0xc6, 0x0b, 0xbd, 0x97, 0x39, 0xcb,
-	0x92, 0xc7, 0x20, 0x2d, 0xd1, 0xaf, 0xb9, 0xc4, 0x81, 0xf6, 0x92, 0xc5, 0x31, 0x66, 0x56, 0x4b,
-	0xa9, 0xec, 0x03, 0x95, 0xcb, 0x79, 0x5a, 0x6b, 0x34, 0x73, 0xf4, 0x02, 0x64, 0x3f, 0x43, 0x21,
-	0x78, 0x56, 0x20, 0x39, 0x07, 0xc8, 0x75, 0x9e, 0x39, 0x8b, 0x55, 0x12, 0xd3, 0xef, 0xd4, 0xc8,
-	0x6d, 0x4c, 0xc6, 0x60, 0x56, 0xee, 0x55, 0x92, 0xae, 0x43, 0xe8, 0x7e, 0x93, 0xb4, 0x3a, 0xe7,
-	0xab, 0xbd, 0xb3, 0x02, 0xd8, 0x1d, 0x27, 0x73, 0x50, 0xed, 0xd6, 0xd3, 0x98, 0xfe, 0xd2, 0x3f,
-	0x3d, 0xe8, 0xd3, 0xbe, 0xfc, 0x93, 0xa7, 0x3d, 0x8f, 0xfe, 0xb9, 0x12, 0xfa, 0x11, 0x5f, 0x6d,
-	0xf9, 0x9b, 0xd4, 0xee, 0xf1, 0x8e, 0xed, 0x55, 0x98, 0x67, 0x3c, 0x0f, 0x12, 0x26, 0x97, 0x65,
-	0x48, 0x23, 0xbe, 0x9a, 0xd6, 0xfc, 0xed, 0x37, 0x17, 0xd1, 0xb7, 0x61, 0x7c, 0x34, 0x9a, 0xae,
-	0xe7, 0x7f, 0x36, 0x2e, 0xdc, 0xfa, 0x9c, 0xb7, 0x29, 0xf1, 0x09, 0xd3, 0xf4, 0x2e, 0xe3, 0xeb,
-	0xec, 0xe1, 0x5d, 0x60, 0x11, 0xb6, 0xd5, 0x3b, 0x57, 0x3f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x1d,
-	0xd0, 0x74, 0x2b, 0x63, 0x02, 0x00, 0x00,
+	// 434 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xcf, 0x6e, 0xd4, 0x30,
+	0x10, 0xc6, 0xc9, 0x76, 0xbb, 0x7f, 0x66, 0x85, 0x50, 0x4d, 0x0f, 0x61, 0x05, 0x6d, 0xb5, 0x87,
+	0xd2, 0x93, 0x57, 0x5a, 0x90, 0x10, 0x37, 0xc8, 0x01, 0x09, 0x21, 0xd0, 0x2a, 0xb4, 0x20, 0xc1,
+	0x21, 0xca, 0xc6, 0x93, 0xc4, 0x22, 0xb1, 0x8d, 0xe3, 0xa8, 0xf0, 0x3a, 0x1c, 0x79, 0xc2, 0x1e,
+	0x51, 0x6c, 0xa7, 0xbb, 0xa2, 0xa0, 0xf6, 0x94, 0xcc, 0xcc, 0x6f, 0xc6, 0x9f, 0x3f, 0x0f, 0x1c,
+	0xe4, 0xbc, 0xc2, 0xa4, 0x55, 0x2c, 0x35, 0x48, 0x95, 0x96, 0x46, 0x92, 0x87, 0x1b, 0x5e, 0x74,
+	0x59, 0xba, 0x53, 0x9a, 0x83, 0xcd, 0x58, 0x60, 0x7e, 0x5c, 0x48, 0x59, 0x54, 0xb8, 0xb4, 0xd1,
+	0xa6, 0xcd, 0x97, 0x86, 0xd7, 0xd8, 0x98, 0xb4, 0x56, 0x1e, 0x38, 0xfa, 0x1b, 0xb8, 0xd4, 0xa9,
+	0x52, 0xa8, 0x1b, 0x57, 0x5f, 0x5c, 0x0d, 0xe0, 0xe0, 0x0d, 0xaf, 0xf0, 0xc2, 0xce, 0x8e, 0xf1,
+	0x7b, 0x8b, 0x8d, 0x21, 0x87, 0xb0, 0x6f, 0xe4, 0x37, 0x14, 0x61, 0x70, 0x12, 0x9c, 0x4d, 0x63,
+	0x17, 0x90, 0x47, 0x30, 0x71, 0x3a, 0x38, 0x0b, 0x07, 0xb6, 0x30, 0xee, 0xe2, 0x0b, 0xce, 0x08,
+	0x81, 0xa1, 0x4a, 0x4d, 0x19, 0xee, 0xd9, 0xb4, 0xfd, 0x27, 0xcf, 0x61, 0xd4, 0x60, 0xa6, 0xd1,
+	0x84, 0xc3, 0x93, 0xe0, 0x6c, 0xb6, 0x7a, 0x4c, 0x9d, 0x16, 0xda, 0x6b, 0xa1, 0x1f, 0x8d, 0xe6,
+	0xa2, 0xf8, 0x94, 0x56, 0x2d, 0xc6, 0x9e, 0x25, 0x2b, 0x18, 0x95, 0x9c, 0x31, 0x14, 0xe1, 0xbe,
+	0xed, 0x9a, 0xdf, 0xe8, 0x8a, 0xa4, 0xac, 0x7c, 0x8f, 0x23, 0xc9, 0x0b, 0x98, 0xf0, 0x3c, 0xa9,
+	0x53, 0x93, 0x95, 0xe1, 0xe8, 0x0e, 0x67, 0x8d, 0x79, 0xfe, 0xbe, 0x83, 0xc9, 0x2b, 0xb8, 0xcf,
+	0xf3, 0x44, 0x48, 0x81, 0xbe, 0x7b, 0x7c, 0x87, 0xee, 0x19, 0xcf, 0x3f, 0x48, 0x81, 0x6e, 0xc2,
+	0x4b, 0x00, 0xfc, 0xa1, 0xb8, 0x46, 0x96, 0xa4, 0x26, 0x9c, 0xfc, 0x47, 0xf2, 0x79, 0xff, 0x2a,
+	0xf1, 0xd4, 0xd3, 0xaf, 0xcd, 0xe2, 0x2b, 0x90, 0x5d, 0xe7, 0x1b, 0x25, 0x45, 0x83, 0xe4, 0x09,
+	0x80, 0x76, 0xaf, 0x90, 0x70, 0x66, 0xfd, 0x1f, 0xc6, 0x53, 0x9f, 0x79, 0xcb, 0xc8, 0x29, 0x0c,
+	0x3b, 0xcf, 0xad, 0xff, 0xb3, 0x15, 0xa1, 0xbb, 0x0b, 0x42, 0xbb, 0x71, 0xb1, 0xad, 0xaf, 0x6a,
+	0x80, 0xed, 0x70, 0x92, 0x00, 0xe4, 0xdb, 0xe8, 0x94, 0xfe, 0x63, 0xad, 0xe8, 0x8d, 0x2d, 0x98,
+	0x3f, 0xbd, 0x95, 0x73, 0x9a, 0x17, 0xf7, 0x22, 0x03, 0x87, 0x99, 0xac, 0xaf, 0xf9, 0xfe, 0xe2,
+	0xd1, 0x83, 0x2d, 0xbd, 0xee, 0x72, 0xeb, 0xe0, 0xcb, 0x51, 0xc1, 0x4d, 0xd9, 0x6e, 0x68, 0x26,
+	0xeb, 0xa5, 0xe7, 0xaf, 0xbf, 0x5a, 0x65, 0x57, 0x41, 0xf0, 0x6b, 0xb0, 0x17, 0xad, 0xe3, 0xdf,
+	0x83, 0xe3, 0xc8, 0x8f, 0x5b, 0xf7, 0x3e, 0x7e, 0xc6, 0xaa, 0x7a, 0x27, 0xe4, 0xa5, 0x38, 0xff,
+	0xa9, 0xb0, 0xd9, 0x8c, 0xec, 0x39, 0xcf, 0xfe, 0x0c, 0x00, 0x9f, 0xc6, 0x08, 0x2d, 0x3a, 0x03,
+	0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
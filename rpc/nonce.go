@@ -0,0 +1,166 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/jinzhu/gorm"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// nonceMetadataKey is the metadata that carries the nonce of request, it's
+// the same as X-Request-Nonce header of http
+const nonceMetadataKey = "x-request-nonce"
+
+// metadataNonce return the nonce of request, it's empty if it isn't given
+func metadataNonce(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get(nonceMetadataKey)) == 0 {
+		return "", nil
+	}
+	if nonce := md.Get(nonceMetadataKey)[0]; len(nonce) >= 32 && len(nonce) <= 48 {
+		return nonce, nil
+	}
+	return "", status.Error(
+		codes.InvalidArgument, "nonce is optional, but the min length of nonce is 32, the max length is 48")
+}
+
+// signedDate return the signed date of request that has been verified by v2
+// signature of uid, it's empty if the request isn't signed by uid
+func signedDate(ctx context.Context, uid string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || !signedBy(ctx, uid) || len(md.Get(service.SignatureV2DateHeader)) == 0 {
+		return ""
+	}
+	return md.Get(service.SignatureV2DateHeader)[0]
+}
+
+// messageCredential return the app and token that the request message belongs
+// to, they're found by appUid or token of message, token is nil if the message
+// is sent with appUid. app is nil if it can't be found, the request is
+// rejected by the server then.
+func messageCredential(req interface{}, db *gorm.DB) (*models.App, *models.Token, error) {
+	if m, ok := req.(interface{ GetAppUid() string }); ok && m.GetAppUid() != "" {
+		app, err := models.FindAppByUID(m.GetAppUid(), db)
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil, nil
+		}
+		return app, nil, err
+	}
+	if m, ok := req.(interface{ GetToken() string }); ok && m.GetToken() != "" {
+		token, err := models.FindTokenByUID(m.GetToken(), db)
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil, nil
+		}
+		return &token.App, token, err
+	}
+	return nil, nil, nil
+}
+
+// messageAuthenticated return whether the request has been authenticated by
+// v2 signature or the secret in message, the others are rejected by server
+// later, so their nonces aren't recorded.
+func messageAuthenticated(ctx context.Context, req interface{}, app *models.App, token *models.Token) bool {
+	if token != nil {
+		if token.Secret == nil || signedBy(ctx, token.UID) {
+			return true
+		}
+		m, ok := req.(interface{ GetSecret() *wrappers.StringValue })
+		return ok && m.GetSecret() != nil && m.GetSecret().GetValue() == *token.Secret
+	}
+	if signedBy(ctx, app.UID) {
+		return true
+	}
+	m, ok := req.(interface{ GetAppSecret() string })
+	return ok && m.GetAppSecret() == app.Secret
+}
+
+// useNonce is used to record the nonce of request in the nonce store, it's
+// only recorded after the request has been authenticated
+func useNonce(ctx context.Context, nonce string, req interface{}) error {
+	var (
+		db    = getDbConn()
+		app   *models.App
+		token *models.Token
+		uid   string
+		err   error
+	)
+	if app, token, err = messageCredential(req, db); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if app == nil || !messageAuthenticated(ctx, req, app, token) {
+		return nil
+	}
+	if uid = app.UID; token != nil {
+		uid = token.UID
+	}
+	expiredAt := service.NonceExpiredAt(signedDate(ctx, uid), time.Now())
+	if err = service.NewNonceStore(nil, db).Use(app.ID, nonce, expiredAt); err != nil {
+		if err == service.ErrNonceReplayed {
+			return status.Error(codes.AlreadyExists, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// NonceUnaryServerInterceptor is used to protect unary rpc from replay attack
+// with the nonce in x-request-nonce metadata, it's optional as http. It should
+// be put behind SignatureUnaryServerInterceptor, then the nonce is recorded
+// after the v2 signature has been verified, and the nonce of signed request is
+// only remembered until the signed date is out of the allowed clock skew.
+func NonceUnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	nonce, err := metadataNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if nonce != "" {
+		if err = useNonce(ctx, nonce, req); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+// nonceServerStream is used to record the nonce of stream rpc with the first
+// message, the metadata is sent once for a stream
+type nonceServerStream struct {
+	grpc.ServerStream
+	nonce    string
+	received bool
+}
+
+func (s *nonceServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !s.received {
+		s.received = true
+		return useNonce(s.ServerStream.Context(), s.nonce, m)
+	}
+	return nil
+}
+
+// NonceStreamServerInterceptor is used to protect stream rpc from replay attack
+// with the nonce in x-request-nonce metadata, it should be put behind
+// SignatureStreamServerInterceptor.
+func NonceStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	nonce, err := metadataNonce(ss.Context())
+	if err != nil {
+		return err
+	}
+	if nonce == "" {
+		return handler(srv, ss)
+	}
+	return handler(srv, &nonceServerStream{ServerStream: ss, nonce: nonce})
+}
@@ -0,0 +1,103 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestNonceInterceptor(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	tempDir := models.NewTempDirForTest()
+	testRootPath = &tempDir
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	file, err := models.CreateFileFromReader(&token.App, "/nonce.txt", bytes.NewReader([]byte("nonce")), int8(0), testRootPath, trx)
+	assert.Nil(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(NonceUnaryServerInterceptor),
+		grpc.StreamInterceptor(NonceStreamServerInterceptor),
+	)
+	RegisterFileStatServer(s, &Server{})
+	RegisterFileReadServer(s, &Server{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	var (
+		statClient = NewFileStatClient(conn)
+		statReq    = &FileStatRequest{Token: token.UID, FileUid: file.UID}
+		nonce      = models.RandomWithMD5(128)
+	)
+
+	// nonce is optional
+	_, err = statClient.FileStat(ctx, statReq)
+	assert.Nil(t, err)
+	_, err = statClient.FileStat(ctx, statReq)
+	assert.Nil(t, err)
+
+	_, err = statClient.FileStat(metadata.AppendToOutgoingContext(ctx, nonceMetadataKey, "short"), statReq)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = statClient.FileStat(metadata.AppendToOutgoingContext(ctx, nonceMetadataKey, nonce), statReq)
+	assert.Nil(t, err)
+	_, err = statClient.FileStat(metadata.AppendToOutgoingContext(ctx, nonceMetadataKey, nonce), statReq)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	assert.Contains(t, err.Error(), "this request is being replayed")
+
+	nonce = models.RandomWithMD5(128)
+	readReq := &FileReadRequest{Token: token.UID, FileUid: file.UID}
+	readClient, err := NewFileReadClient(conn).FileRead(metadata.AppendToOutgoingContext(ctx, nonceMetadataKey, nonce), readReq)
+	assert.Nil(t, err)
+	readResp, err := readClient.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, "nonce", string(readResp.Content))
+
+	readClient, err = NewFileReadClient(conn).FileRead(metadata.AppendToOutgoingContext(ctx, nonceMetadataKey, nonce), readReq)
+	assert.Nil(t, err)
+	_, err = readClient.Recv()
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// the nonce isn't recorded until the request is authenticated
+	secret := models.RandomWithMD5(128)
+	assert.Nil(t, trx.Model(token).Update("secret", secret).Error)
+	nonce = models.RandomWithMD5(128)
+	_, err = statClient.FileStat(metadata.AppendToOutgoingContext(ctx, nonceMetadataKey, nonce), statReq)
+	assert.NotNil(t, err)
+	assert.NotEqual(t, codes.AlreadyExists, status.Code(err))
+	statReq.Secret = &wrappers.StringValue{Value: secret}
+	_, err = statClient.FileStat(metadata.AppendToOutgoingContext(ctx, nonceMetadataKey, nonce), statReq)
+	assert.Nil(t, err)
+	_, err = statClient.FileStat(metadata.AppendToOutgoingContext(ctx, nonceMetadataKey, nonce), statReq)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
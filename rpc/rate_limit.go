@@ -0,0 +1,84 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// retryAfterMetadataKey is the header metadata that carries how many seconds
+// to wait before retrying, it's the same as Retry-After header of http
+const retryAfterMetadataKey = "retry-after"
+
+// allowRequest is used to take a request of the app and token of message from
+// the rate limits, ResourceExhausted is returned with the retry-after header if
+// the limit is exceeded
+func allowRequest(req interface{}, setHeader func(metadata.MD) error) error {
+	var (
+		app     *models.App
+		token   *models.Token
+		tokenID uint64
+		err     error
+	)
+	if app, token, err = messageCredential(req, getDbConn()); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if app == nil {
+		return nil
+	}
+	if token != nil {
+		tokenID = token.ID
+	}
+	retryAfter, err := service.DefaultRateLimiter().AllowRequest(app.ID, tokenID)
+	if err == service.ErrTooManyRequests {
+		_ = setHeader(metadata.Pairs(retryAfterMetadataKey, strconv.FormatInt(service.RetryAfterSeconds(retryAfter), 10)))
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// RateLimitUnaryServerInterceptor is used to limit the request rate of app and
+// token of unary rpc, the limits are configured by config.Limit
+func RateLimitUnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := allowRequest(req, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// rateLimitServerStream is used to limit the request rate of stream rpc with
+// the first message, a stream is counted as one request
+type rateLimitServerStream struct {
+	grpc.ServerStream
+	received bool
+}
+
+func (s *rateLimitServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !s.received {
+		s.received = true
+		return allowRequest(m, s.ServerStream.SetHeader)
+	}
+	return nil
+}
+
+// RateLimitStreamServerInterceptor is used to limit the request rate of app
+// and token of stream rpc
+func RateLimitStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &rateLimitServerStream{ServerStream: ss})
+}
@@ -0,0 +1,82 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	defer func(limit config.Limit) { config.DefaultConfig.Limit = limit }(config.DefaultConfig.Limit)
+	config.DefaultConfig.Limit = config.Limit{LimitTokenRequests: 1}
+
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	tempDir := models.NewTempDirForTest()
+	testRootPath = &tempDir
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	file, err := models.CreateFileFromReader(&token.App, "/limit.txt", bytes.NewReader([]byte("limit")), int8(0), testRootPath, trx)
+	assert.Nil(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(RateLimitUnaryServerInterceptor),
+		grpc.StreamInterceptor(RateLimitStreamServerInterceptor),
+	)
+	RegisterFileStatServer(s, &Server{})
+	RegisterFileReadServer(s, &Server{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	var (
+		statClient = NewFileStatClient(conn)
+		statReq    = &FileStatRequest{Token: token.UID, FileUid: file.UID}
+		header     metadata.MD
+	)
+
+	_, err = statClient.FileStat(ctx, statReq)
+	assert.Nil(t, err)
+
+	_, err = statClient.FileStat(ctx, statReq, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "too many requests")
+	assert.Equal(t, []string{"1"}, header.Get(retryAfterMetadataKey))
+
+	readClient, err := NewFileReadClient(conn).FileRead(ctx, &FileReadRequest{Token: token.UID, FileUid: file.UID})
+	assert.Nil(t, err)
+	_, err = readClient.Recv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// the unknown tokens are rejected by server
+	_, err = statClient.FileStat(ctx, &FileStatRequest{Token: "unknown", FileUid: file.UID})
+	assert.NotEqual(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -6,6 +6,7 @@
 package rpc
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -23,6 +24,7 @@ import (
 	"github.com/bigfile/bigfile/log"
 	"github.com/bigfile/bigfile/service"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/jinzhu/gorm"
 	jsoniter "github.com/json-iterator/go"
@@ -79,10 +81,15 @@ func (s *Server) getClientIP(ctx context.Context) (string, error) {
 	return pr.Addr.String(), nil
 }
 
-// fetchAPP is used to generate *models.APP by app?UID and APPSecret
-func fetchAPP(appUID, APPSecret string, db *gorm.DB) (app *models.App, err error) {
+// fetchAPP is used to generate *models.APP by app?UID and APPSecret, the
+// secret isn't required if the request is signed by v2 signature of app
+func fetchAPP(ctx context.Context, appUID, APPSecret string, db *gorm.DB) (app *models.App, err error) {
 	app = &models.App{}
-	err = db.Where("uid = ? and secret = ?", appUID, APPSecret).First(app).Error
+	if signedBy(ctx, appUID) {
+		err = db.Where("uid = ?", appUID).First(app).Error
+	} else {
+		err = db.Where("uid = ? and secret = ?", appUID, APPSecret).First(app).Error
+	}
 	if gorm.IsRecordNotFoundError(err) {
 		err = ErrAppSecret
 	}
@@ -170,9 +177,40 @@ func (s *Server) fileResp(file *models.File, db *gorm.DB) (f *File, err error) {
 			return f, err
 		}
 	}
+	if file.ExpiredAt != nil {
+		if f.ExpiredAt, err = ptypes.TimestampProto(*file.ExpiredAt); err != nil {
+			return f, err
+		}
+	}
+	f.RetentionMode = uint32(file.RetentionMode)
+	f.LegalHold = file.LegalHold == 1
+	if file.RetainUntil != nil {
+		if f.RetainUntil, err = ptypes.TimestampProto(*file.RetainUntil); err != nil {
+			return f, err
+		}
+	}
+	var locks []models.FileLock
+	if locks, err = file.Locks(db); err != nil {
+		return f, err
+	}
+	for index := range locks {
+		var lock *FileLock
+		if lock, err = s.fileLockResp(&locks[index]); err != nil {
+			return f, err
+		}
+		f.Locks = append(f.Locks, lock)
+	}
 	return f, err
 }
 
+// fileLockResp is used to generate file lock response, the owner is not
+// included, because it's the uid of token.
+func (s *Server) fileLockResp(lock *models.FileLock) (l *FileLock, err error) {
+	l = &FileLock{Uid: lock.UID, Exclusive: lock.Exclusive == 1}
+	l.ExpiredAt, err = ptypes.TimestampProto(lock.ExpiredAt)
+	return l, err
+}
+
 func (s *Server) updateRequestRecord(ctx context.Context, request *models.Request, resp interface{}, err error, db *gorm.DB) {
 	var responseBody string
 
@@ -192,6 +230,90 @@ func (s *Server) updateRequestRecord(ctx context.Context, request *models.Reques
 	}
 }
 
+// stringValue is used to get the pointer of string from StringValue
+func stringValue(value *wrappers.StringValue) *string {
+	if value == nil {
+		return nil
+	}
+	v := value.GetValue()
+	return &v
+}
+
+// errorStatus is used to convert error of mutation service to status error
+func errorStatus(err error) error {
+	if err == service.ErrPreconditionFailed {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// changeResp is used to convert the journal to the change of rpc response
+func (s *Server) changeResp(journal *models.Journal) (c *Change, err error) {
+	c = &Change{
+		Sequence: journal.Sequence,
+		Event:    journal.Event,
+		FileUid:  journal.FileUID,
+		Path:     journal.Path,
+		IsDir:    journal.IsDir == models.IsDir,
+		Hidden:   journal.Hidden == models.Hidden,
+		Size:     uint64(journal.Size),
+	}
+	if journal.PreviousPath != nil {
+		c.PreviousPath = &wrappers.StringValue{Value: *journal.PreviousPath}
+	}
+	c.CreatedAt, err = ptypes.TimestampProto(journal.CreatedAt)
+	return c, err
+}
+
+// webhookResp is used to convert the webhook to rpc response
+func (s *Server) webhookResp(webhook *models.Webhook) (w *Webhook, err error) {
+	w = &Webhook{
+		Uid:        webhook.UID,
+		Url:        webhook.URL,
+		Events:     webhook.EventList(),
+		PathPrefix: webhook.PathPrefix,
+	}
+	if w.CreatedAt, err = ptypes.TimestampProto(webhook.CreatedAt); err != nil {
+		return nil, err
+	}
+	if webhook.DeletedAt != nil {
+		if w.DeletedAt, err = ptypes.TimestampProto(*webhook.DeletedAt); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// webhookDeliveryResp is used to convert the webhook delivery to rpc response
+func (s *Server) webhookDeliveryResp(delivery *models.WebhookDelivery) (d *WebhookDelivery, err error) {
+	d = &WebhookDelivery{
+		Uid:      delivery.UID,
+		Event:    delivery.Event,
+		Sequence: delivery.Sequence,
+		Status:   delivery.Status,
+		Attempts: uint32(delivery.Attempts),
+		Payload:  delivery.Payload,
+	}
+	if delivery.ResponseCode != nil {
+		d.ResponseCode = &wrappers.Int32Value{Value: int32(*delivery.ResponseCode)}
+	}
+	if delivery.LastError != nil {
+		d.LastError = &wrappers.StringValue{Value: *delivery.LastError}
+	}
+	if d.NextAttemptAt, err = ptypes.TimestampProto(delivery.NextAttemptAt); err != nil {
+		return nil, err
+	}
+	if d.CreatedAt, err = ptypes.TimestampProto(delivery.CreatedAt); err != nil {
+		return nil, err
+	}
+	if delivery.DeliveredAt != nil {
+		if d.DeliveredAt, err = ptypes.TimestampProto(*delivery.DeliveredAt); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
 func getDbConn() (db *gorm.DB) {
 	if isTesting {
 		db = testDbConn
@@ -226,7 +348,7 @@ func (s *Server) TokenCreate(ctx context.Context, req *TokenCreateRequest) (resp
 	}
 	resp = &TokenCreateResponse{RequestId: record.ID}
 	defer func() { s.updateRequestRecord(ctx, record, resp, err, db) }()
-	if app, err = fetchAPP(req.AppUid, req.AppSecret, db); err != nil {
+	if app, err = fetchAPP(ctx, req.AppUid, req.AppSecret, db); err != nil {
 		return
 	}
 	record.AppID = &app.ID
@@ -349,7 +471,7 @@ func (s *Server) TokenUpdate(ctx context.Context, req *TokenUpdateRequest) (resp
 	}
 	resp = &TokenUpdateResponse{RequestId: record.ID}
 	defer func() { s.updateRequestRecord(ctx, record, resp, err, db) }()
-	if app, err = fetchAPP(req.AppUid, req.AppSecret, db); err != nil {
+	if app, err = fetchAPP(ctx, req.AppUid, req.AppSecret, db); err != nil {
 		return
 	}
 	record.AppID = &app.ID
@@ -387,7 +509,7 @@ func (s *Server) TokenDelete(ctx context.Context, req *TokenDeleteRequest) (resp
 		return resp, err
 	}
 	resp = &TokenDeleteResponse{RequestId: record.ID}
-	if app, err = fetchAPP(req.AppUid, req.AppSecret, db); err != nil {
+	if app, err = fetchAPP(ctx, req.AppUid, req.AppSecret, db); err != nil {
 		return
 	}
 	record.AppID = &app.ID
@@ -406,11 +528,11 @@ func (s *Server) TokenDelete(ctx context.Context, req *TokenDeleteRequest) (resp
 	return
 }
 
-func (s *Server) fetchToken(t string, secret *wrappers.StringValue, db *gorm.DB) (token *models.Token, err error) {
+func (s *Server) fetchToken(ctx context.Context, t string, secret *wrappers.StringValue, db *gorm.DB) (token *models.Token, err error) {
 	if token, err = models.FindTokenByUID(t, db); err != nil {
 		return nil, err
 	}
-	if token.Secret != nil {
+	if token.Secret != nil && !signedBy(ctx, token.UID) {
 		if secret == nil || secret.GetValue() != *token.Secret {
 			return nil, ErrTokenSecretWrong
 		}
@@ -442,6 +564,15 @@ func createFileCreateSrv(req *FileCreateRequest, fileCreateSrv *service.FileCrea
 	if req.Hidden != nil && req.Hidden.GetValue() {
 		fileCreateSrv.Hidden = 1
 	}
+	fileCreateSrv.IfMatch = stringValue(req.IfMatch)
+	fileCreateSrv.IfNoneMatch = stringValue(req.IfNoneMatch)
+	if req.ExpiredAt != nil {
+		expiredAt, err := ptypes.Timestamp(req.ExpiredAt)
+		if err != nil {
+			return err
+		}
+		fileCreateSrv.ExpiredAt = &expiredAt
+	}
 	return nil
 }
 
@@ -461,7 +592,7 @@ func (s *Server) FileCreate(stream FileCreate_FileCreateServer) (err error) {
 	)
 	defer func() {
 		if err != nil {
-			err = status.Error(codes.InvalidArgument, err.Error())
+			err = errorStatus(err)
 		}
 	}()
 	for {
@@ -477,7 +608,7 @@ func (s *Server) FileCreate(stream FileCreate_FileCreateServer) (err error) {
 			req.Content = content
 			resp = &FileCreateResponse{RequestId: record.ID}
 			if !tokenHasChecked || previousToken != req.Token {
-				if token, err = s.fetchToken(req.Token, req.Secret, db); err != nil {
+				if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
 					return
 				}
 			}
@@ -521,14 +652,14 @@ func (s *Server) FileUpdate(ctx context.Context, req *FileUpdateRequest) (resp *
 	)
 	defer func() {
 		if err != nil {
-			err = status.Error(codes.InvalidArgument, err.Error())
+			err = errorStatus(err)
 		}
 	}()
 	if record, err = s.generateRequestRecord(ctx, "FileUpdate", req, db); err != nil {
 		return
 	}
 	resp = &FileUpdateResponse{RequestId: record.ID}
-	if token, err = s.fetchToken(req.Token, req.Secret, db); err != nil {
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
 		return
 	}
 	record.AppID = &token.App.ID
@@ -547,6 +678,17 @@ func (s *Server) FileUpdate(ctx context.Context, req *FileUpdateRequest) (resp *
 		IP:          record.IP,
 		Hidden:      &hidden,
 		Path:        &req.Path,
+		IfMatch:     stringValue(req.IfMatch),
+		IfNoneMatch: stringValue(req.IfNoneMatch),
+	}
+	if req.ExpiredAt != nil {
+		expiredAt := time.Time{}
+		if req.ExpiredAt.GetSeconds() != 0 || req.ExpiredAt.GetNanos() != 0 {
+			if expiredAt, err = ptypes.Timestamp(req.ExpiredAt); err != nil {
+				return
+			}
+		}
+		fileUpdateSrv.ExpiredAt = &expiredAt
 	}
 	if err = fileUpdateSrv.Validate(); !reflect.ValueOf(err).IsNil() {
 		return
@@ -572,19 +714,19 @@ func (s *Server) FileDelete(ctx context.Context, req *FileDeleteRequest) (resp *
 	)
 	defer func() {
 		if err != nil {
-			err = status.Error(codes.InvalidArgument, err.Error())
+			err = errorStatus(err)
 		}
 	}()
 	if record, err = s.generateRequestRecord(ctx, "FileDelete", req, db); err != nil {
 		return
 	}
 	resp = &FileDeleteResponse{RequestId: record.ID}
-	if token, err = s.fetchToken(req.Token, req.Secret, db); err != nil {
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
 		return
 	}
 	record.AppID = &token.App.ID
 	record.Token = &token.UID
-	if file, err = models.FindFileByUID(req.FileUid, false, db); err != nil {
+	if file, err = service.FindFile(token, req.FileUid, stringValue(req.Path), db); err != nil {
 		return
 	}
 
@@ -594,6 +736,8 @@ func (s *Server) FileDelete(ctx context.Context, req *FileDeleteRequest) (resp *
 		File:        file,
 		IP:          record.IP,
 		Force:       &req.ForceDeleteIfDir,
+		IfMatch:     stringValue(req.IfMatch),
+		IfNoneMatch: stringValue(req.IfNoneMatch),
 	}
 
 	if err = fileDeleteSrv.Validate(); !reflect.ValueOf(err).IsNil() {
@@ -609,6 +753,62 @@ func (s *Server) FileDelete(ctx context.Context, req *FileDeleteRequest) (resp *
 	return
 }
 
+// FileStat is used to get the metadata of file or directory
+func (s *Server) FileStat(ctx context.Context, req *FileStatRequest) (resp *FileStatResponse, err error) {
+	var (
+		db           = getDbConn()
+		file         *models.File
+		token        *models.Token
+		record       *models.Request
+		fileStatSrv  *service.FileStat
+		fileStatVal  interface{}
+		fileStatResp *service.FileStatResponse
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "FileStat", req, db); err != nil {
+		return
+	}
+	resp = &FileStatResponse{RequestId: record.ID}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+	if file, err = service.FindFile(token, req.FileUid, stringValue(req.Path), db); err != nil {
+		return
+	}
+
+	fileStatSrv = &service.FileStat{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		File:        file,
+		IP:          record.IP,
+	}
+
+	if err = fileStatSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+
+	if fileStatVal, err = fileStatSrv.Execute(ctx); err != nil {
+		return
+	}
+	fileStatResp = fileStatVal.(*service.FileStatResponse)
+	resp.MimeType = fileStatResp.MimeType
+	resp.VersionCount = uint32(fileStatResp.VersionCount)
+	if resp.CreatedAt, err = ptypes.TimestampProto(file.CreatedAt); err != nil {
+		return
+	}
+	if resp.UpdatedAt, err = ptypes.TimestampProto(file.UpdatedAt); err != nil {
+		return
+	}
+	resp.File, err = s.fileResp(file, db)
+	return
+}
+
 // ImageConvert is used to convert image
 func (s *Server) ImageConvert(req *ImageConvertRequest, resp ImageConvert_ImageConvertServer) (err error) {
 	var (
@@ -628,7 +828,7 @@ func (s *Server) ImageConvert(req *ImageConvertRequest, resp ImageConvert_ImageC
 	if record, err = s.generateRequestRecord(ctx, "ImageConvert", req, db); err != nil {
 		return
 	}
-	if token, err = s.fetchToken(req.Token, req.Secret, db); err != nil {
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
 		return
 	}
 	record.AppID = &token.App.ID
@@ -693,12 +893,12 @@ func (s *Server) FileRead(req *FileReadRequest, resp FileRead_FileReadServer) (e
 	if record, err = s.generateRequestRecord(ctx, "FileRead", req, db); err != nil {
 		return
 	}
-	if token, err = s.fetchToken(req.Token, req.Secret, db); err != nil {
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
 		return
 	}
 	record.AppID = &token.App.ID
 	record.Token = &token.UID
-	if file, err = models.FindFileByUID(req.FileUid, false, db); err != nil {
+	if file, err = service.FindFile(token, req.FileUid, stringValue(req.Path), db); err != nil {
 		return
 	}
 	if err = db.Model(record).Updates(map[string]interface{}{"appId": record.AppID, "token": record.Token}).Error; err != nil {
@@ -744,6 +944,75 @@ func (s *Server) FileRead(req *FileReadRequest, resp FileRead_FileReadServer) (e
 	}
 }
 
+// archiveSender is used to send archive to client piece by piece, Send blocks
+// until the client has consumed enough data, so the archive is built no faster
+// than it's downloaded
+type archiveSender struct {
+	resp FileArchive_FileArchiveServer
+}
+
+func (a *archiveSender) Write(p []byte) (int, error) {
+	if err := a.resp.Send(&FileArchiveResponse{Content: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// FileArchive is used to download a directory or some files as archive
+func (s *Server) FileArchive(req *FileArchiveRequest, resp FileArchive_FileArchiveServer) (err error) {
+	var (
+		db             = getDbConn()
+		ctx            = resp.Context()
+		file           *models.File
+		token          *models.Token
+		record         *models.Request
+		writer         = bufio.NewWriterSize(&archiveSender{resp: resp}, models.ChunkSize)
+		fileArchiveSrv *service.FileArchive
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "FileArchive", req, db); err != nil {
+		return
+	}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+	if err = db.Model(record).Updates(map[string]interface{}{"appId": record.AppID, "token": record.Token}).Error; err != nil {
+		return
+	}
+	fileArchiveSrv = &service.FileArchive{
+		BaseService: service.BaseService{DB: db, RootPath: testRootPath},
+		Token:       token,
+		IP:          record.IP,
+		Format:      service.ArchiveZip,
+		Writer:      writer,
+	}
+	if req.Format == FileArchiveRequest_TarGz {
+		fileArchiveSrv.Format = service.ArchiveTarGz
+	}
+	if req.SubDir != nil {
+		fileArchiveSrv.SubDir = &req.SubDir.Value
+	}
+	for _, fileUID := range req.FileUids {
+		if file, err = models.FindFileByUID(fileUID, false, db); err != nil {
+			return
+		}
+		fileArchiveSrv.Files = append(fileArchiveSrv.Files, file)
+	}
+	if err = fileArchiveSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+	if _, err = fileArchiveSrv.Execute(ctx); err != nil {
+		return
+	}
+	return writer.Flush()
+}
+
 // DirectoryList is used to list a directory
 func (s *Server) DirectoryList(ctx context.Context, req *DirectoryListRequest) (resp *DirectoryListResponse, err error) {
 	var (
@@ -763,7 +1032,7 @@ func (s *Server) DirectoryList(ctx context.Context, req *DirectoryListRequest) (
 		return
 	}
 	resp = &DirectoryListResponse{RequestId: record.ID}
-	if token, err = s.fetchToken(req.Token, req.Secret, db); err != nil {
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
 		return
 	}
 	record.AppID = &token.App.ID
@@ -811,3 +1080,728 @@ func (s *Server) DirectoryList(ctx context.Context, req *DirectoryListRequest) (
 	}
 	return
 }
+
+// ChangeFeed is used to fetch the changes of file tree after cursor
+func (s *Server) ChangeFeed(ctx context.Context, req *ChangeFeedRequest) (resp *ChangeFeedResponse, err error) {
+	var (
+		db             = getDbConn()
+		token          *models.Token
+		record         *models.Request
+		changeFeedSrv  *service.ChangeFeed
+		changeFeedVal  interface{}
+		changeFeedResp *service.ChangeFeedResponse
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "ChangeFeed", req, db); err != nil {
+		return
+	}
+	resp = &ChangeFeedResponse{RequestId: record.ID}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+
+	changeFeedSrv = &service.ChangeFeed{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		IP:          record.IP,
+		SubDir:      "/",
+		Cursor:      req.GetCursor(),
+		Limit:       100,
+	}
+	if req.GetSubDir() != nil {
+		changeFeedSrv.SubDir = req.GetSubDir().GetValue()
+	}
+	if req.GetLimit() != 0 {
+		changeFeedSrv.Limit = int(req.GetLimit())
+	}
+
+	if err = changeFeedSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+	if changeFeedVal, err = changeFeedSrv.Execute(ctx); err != nil {
+		return
+	}
+	changeFeedResp = changeFeedVal.(*service.ChangeFeedResponse)
+	resp.Cursor = changeFeedResp.Cursor
+	resp.HasMore = changeFeedResp.HasMore
+	resp.Changes = make([]*Change, len(changeFeedResp.Changes))
+	for i := range changeFeedResp.Changes {
+		if resp.Changes[i], err = s.changeResp(&changeFeedResp.Changes[i]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// FileLockAcquire is used to lock a file or a directory
+func (s *Server) FileLockAcquire(ctx context.Context, req *FileLockAcquireRequest) (resp *FileLockAcquireResponse, err error) {
+	var (
+		db      = getDbConn()
+		file    *models.File
+		token   *models.Token
+		record  *models.Request
+		lockSrv *service.FileLockAcquire
+		lockVal interface{}
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "FileLockAcquire", req, db); err != nil {
+		return
+	}
+	resp = &FileLockAcquireResponse{RequestId: record.ID, FileUid: req.FileUid}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+	if file, err = models.FindFileByUID(req.FileUid, false, db); err != nil {
+		return
+	}
+
+	lockSrv = &service.FileLockAcquire{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		File:        file,
+		IP:          record.IP,
+		TTL:         int(req.Ttl),
+	}
+	if req.Exclusive {
+		lockSrv.Exclusive = 1
+	}
+
+	if err = lockSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+
+	if lockVal, err = lockSrv.Execute(ctx); err != nil {
+		return
+	}
+	resp.Lock, err = s.fileLockResp(lockVal.(*models.FileLock))
+	return
+}
+
+// FileLockRefresh is used to extend the lease of lock
+func (s *Server) FileLockRefresh(ctx context.Context, req *FileLockRefreshRequest) (resp *FileLockRefreshResponse, err error) {
+	var (
+		db      = getDbConn()
+		lock    *models.FileLock
+		token   *models.Token
+		record  *models.Request
+		lockSrv *service.FileLockRefresh
+		lockVal interface{}
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "FileLockRefresh", req, db); err != nil {
+		return
+	}
+	resp = &FileLockRefreshResponse{RequestId: record.ID}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+	if lock, err = models.FindFileLockByUID(req.LockUid, db); err != nil {
+		return
+	}
+
+	lockSrv = &service.FileLockRefresh{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		Lock:        lock,
+		IP:          record.IP,
+		TTL:         int(req.Ttl),
+	}
+
+	if err = lockSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+
+	if lockVal, err = lockSrv.Execute(ctx); err != nil {
+		return
+	}
+	resp.Lock, err = s.fileLockResp(lockVal.(*models.FileLock))
+	return
+}
+
+// FileLockRelease is used to release the lock
+func (s *Server) FileLockRelease(ctx context.Context, req *FileLockReleaseRequest) (resp *FileLockReleaseResponse, err error) {
+	var (
+		db      = getDbConn()
+		lock    *models.FileLock
+		token   *models.Token
+		record  *models.Request
+		lockSrv *service.FileLockRelease
+		lockVal interface{}
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "FileLockRelease", req, db); err != nil {
+		return
+	}
+	resp = &FileLockReleaseResponse{RequestId: record.ID}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+	if lock, err = models.FindFileLockByUID(req.LockUid, db); err != nil {
+		return
+	}
+
+	lockSrv = &service.FileLockRelease{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		Lock:        lock,
+		IP:          record.IP,
+	}
+
+	if err = lockSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+
+	if lockVal, err = lockSrv.Execute(ctx); err != nil {
+		return
+	}
+	resp.Lock, err = s.fileLockResp(lockVal.(*models.FileLock))
+	return
+}
+
+// fileBatchOps is used to map the operation of rpc to the operation of service
+var fileBatchOps = map[FileBatchOperation_Op]string{
+	FileBatchOperation_Mkdir:     service.FileBatchMkdir,
+	FileBatchOperation_Move:      service.FileBatchMove,
+	FileBatchOperation_Copy:      service.FileBatchCopy,
+	FileBatchOperation_Delete:    service.FileBatchDelete,
+	FileBatchOperation_SetHidden: service.FileBatchSetHidden,
+}
+
+// FileBatch is used to execute a list of file operations atomically. When one
+// operation fails, all of them are rolled back, and the response carries the
+// error of the failed one, success of response is false.
+func (s *Server) FileBatch(ctx context.Context, req *FileBatchRequest) (resp *FileBatchResponse, err error) {
+	var (
+		db           = getDbConn()
+		token        *models.Token
+		record       *models.Request
+		operations   []*service.FileBatchOperation
+		fileBatchSrv *service.FileBatch
+		fileBatchVal interface{}
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "FileBatch", req, db); err != nil {
+		return
+	}
+	resp = &FileBatchResponse{RequestId: record.ID}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+
+	for _, op := range req.Operations {
+		operation := &service.FileBatchOperation{
+			Op:    fileBatchOps[op.GetOp()],
+			Path:  op.GetPath(),
+			To:    stringValue(op.GetTo()),
+			Force: op.GetForce(),
+		}
+		if op.GetHidden() != nil {
+			hidden := op.GetHidden().GetValue()
+			operation.Hidden = &hidden
+		}
+		operations = append(operations, operation)
+	}
+
+	fileBatchSrv = &service.FileBatch{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		IP:          record.IP,
+		Operations:  operations,
+	}
+
+	if err = fileBatchSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+
+	fileBatchVal, err = fileBatchSrv.Execute(ctx)
+	if err != nil && err != service.ErrBatchAborted {
+		return
+	}
+	resp.Success = err == nil
+	for _, result := range fileBatchVal.([]*service.FileBatchResult) {
+		item := &FileBatchResult{Index: uint32(result.Index), Success: result.Error == nil}
+		if result.Error != nil {
+			item.Error = &wrappers.StringValue{Value: result.Error.Error()}
+		}
+		if result.File != nil {
+			if item.File, err = s.fileResp(result.File, db); err != nil {
+				return
+			}
+		}
+		resp.Results = append(resp.Results, item)
+	}
+	return resp, nil
+}
+
+// FileExtract is used to unpack an archive that has been uploaded into target
+// directory, the result of every entry is returned
+func (s *Server) FileExtract(ctx context.Context, req *FileExtractRequest) (resp *FileExtractResponse, err error) {
+	var (
+		db             = getDbConn()
+		token          *models.Token
+		record         *models.Request
+		fileExtractSrv *service.FileExtract
+		fileExtractVal interface{}
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "FileExtract", req, db); err != nil {
+		return
+	}
+	resp = &FileExtractResponse{RequestId: record.ID}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+
+	fileExtractSrv = &service.FileExtract{
+		BaseService: service.BaseService{DB: db, RootPath: testRootPath},
+		Token:       token,
+		IP:          record.IP,
+		Target:      req.Target,
+		MaxEntries:  int(req.MaxEntries),
+		MaxSize:     int64(req.MaxSize),
+	}
+	if fileExtractSrv.File, err = models.FindFileByUID(req.FileUid, false, db); err != nil {
+		return
+	}
+	if req.Format != nil {
+		fileExtractSrv.Format = req.Format.GetValue()
+	}
+	if req.Overwrite {
+		fileExtractSrv.Overwrite = 1
+	}
+
+	if err = fileExtractSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+
+	if fileExtractVal, err = fileExtractSrv.Execute(ctx); err != nil {
+		return
+	}
+	for _, result := range fileExtractVal.([]*service.FileExtractResult) {
+		item := &FileExtractResult{Name: result.Name, Path: result.Path, Success: result.Error == nil}
+		if result.Error != nil {
+			item.Error = &wrappers.StringValue{Value: result.Error.Error()}
+		}
+		if result.File != nil {
+			if item.File, err = s.fileResp(result.File, db); err != nil {
+				return
+			}
+		}
+		resp.Results = append(resp.Results, item)
+	}
+	return resp, nil
+}
+
+// setFileRetentionSrv is used to convert the params of rpc request to service
+func setFileRetentionSrv(
+	srv *service.FileRetention, mode *wrappers.UInt32Value, retainUntil *timestamp.Timestamp, legalHold *wrappers.BoolValue) error {
+	if mode != nil {
+		m := int8(mode.GetValue())
+		srv.Mode = &m
+	}
+	if retainUntil != nil {
+		until, err := ptypes.Timestamp(retainUntil)
+		if err != nil {
+			return err
+		}
+		srv.RetainUntil = &until
+	}
+	if legalHold != nil {
+		var hold int8
+		if legalHold.GetValue() {
+			hold = 1
+		}
+		srv.LegalHold = &hold
+	}
+	return nil
+}
+
+// FileRetention is used to extend the retention or place legal hold by token
+func (s *Server) FileRetention(ctx context.Context, req *FileRetentionRequest) (resp *FileRetentionResponse, err error) {
+	var (
+		db               = getDbConn()
+		token            *models.Token
+		record           *models.Request
+		fileRetentionSrv *service.FileRetention
+		fileRetentionVal interface{}
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "FileRetention", req, db); err != nil {
+		return
+	}
+	resp = &FileRetentionResponse{RequestId: record.ID}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+
+	fileRetentionSrv = &service.FileRetention{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		IP:          record.IP,
+	}
+	if fileRetentionSrv.File, err = models.FindFileByUID(req.FileUid, false, db); err != nil {
+		return
+	}
+	if err = setFileRetentionSrv(fileRetentionSrv, req.Mode, req.RetainUntil, req.LegalHold); err != nil {
+		return
+	}
+	if err = fileRetentionSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+	if fileRetentionVal, err = fileRetentionSrv.Execute(ctx); err != nil {
+		return
+	}
+	resp.File, err = s.fileResp(fileRetentionVal.(*models.File), db)
+	return
+}
+
+// FileRetentionAdmin is used to change the retention or legal hold by app, the
+// retention in governance mode can be shortened or removed
+func (s *Server) FileRetentionAdmin(ctx context.Context, req *FileRetentionAdminRequest) (resp *FileRetentionResponse, err error) {
+	var (
+		db               = getDbConn()
+		app              *models.App
+		record           *models.Request
+		fileRetentionSrv *service.FileRetention
+		fileRetentionVal interface{}
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "FileRetentionAdmin", req, db); err != nil {
+		return
+	}
+	resp = &FileRetentionResponse{RequestId: record.ID}
+	defer func() { s.updateRequestRecord(ctx, record, resp, err, db) }()
+	if app, err = fetchAPP(ctx, req.AppUid, req.AppSecret, db); err != nil {
+		return
+	}
+	record.AppID = &app.ID
+
+	fileRetentionSrv = &service.FileRetention{
+		BaseService: service.BaseService{DB: db},
+		App:         app,
+		IP:          record.IP,
+	}
+	if fileRetentionSrv.File, err = models.FindFileByUID(req.FileUid, false, db); err != nil {
+		return
+	}
+	if err = setFileRetentionSrv(fileRetentionSrv, req.Mode, req.RetainUntil, req.LegalHold); err != nil {
+		return
+	}
+	if err = fileRetentionSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+	if fileRetentionVal, err = fileRetentionSrv.Execute(ctx); err != nil {
+		return
+	}
+	resp.File, err = s.fileResp(fileRetentionVal.(*models.File), db)
+	return
+}
+
+// fetchWebhook is used to find the webhook of app
+func fetchWebhook(app *models.App, webhookUID string, db *gorm.DB) (*models.Webhook, error) {
+	webhook, err := models.FindWebhookByUID(webhookUID, db)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.AppID != app.ID {
+		return nil, service.ErrWebhookNotMatchApp
+	}
+	return webhook, nil
+}
+
+// WebhookCreate is used to register a webhook for app
+func (s *Server) WebhookCreate(ctx context.Context, req *WebhookCreateRequest) (resp *WebhookCreateResponse, err error) {
+	var (
+		db               = getDbConn()
+		app              *models.App
+		record           *models.Request
+		webhookCreateSrv *service.WebhookCreate
+		webhookCreateVal interface{}
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "WebhookCreate", req, db); err != nil {
+		return
+	}
+	resp = &WebhookCreateResponse{RequestId: record.ID}
+	defer func() { s.updateRequestRecord(ctx, record, resp, err, db) }()
+	if app, err = fetchAPP(ctx, req.AppUid, req.AppSecret, db); err != nil {
+		return
+	}
+	record.AppID = &app.ID
+
+	webhookCreateSrv = &service.WebhookCreate{
+		BaseService: service.BaseService{DB: db},
+		App:         app,
+		URL:         req.GetUrl(),
+		Events:      req.GetEvents(),
+		PathPrefix:  req.GetPathPrefix().GetValue(),
+	}
+	if err = webhookCreateSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+	if webhookCreateVal, err = webhookCreateSrv.Execute(ctx); err != nil {
+		return
+	}
+	resp.Webhook, err = s.webhookResp(webhookCreateVal.(*models.Webhook))
+	return
+}
+
+// WebhookList is used to list the webhooks of app
+func (s *Server) WebhookList(ctx context.Context, req *WebhookListRequest) (resp *WebhookListResponse, err error) {
+	var (
+		db             = getDbConn()
+		app            *models.App
+		record         *models.Request
+		webhooks       []models.Webhook
+		webhookListSrv *service.WebhookList
+		webhookListVal interface{}
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "WebhookList", req, db); err != nil {
+		return
+	}
+	resp = &WebhookListResponse{RequestId: record.ID}
+	defer func() { s.updateRequestRecord(ctx, record, resp, err, db) }()
+	if app, err = fetchAPP(ctx, req.AppUid, req.AppSecret, db); err != nil {
+		return
+	}
+	record.AppID = &app.ID
+
+	webhookListSrv = &service.WebhookList{BaseService: service.BaseService{DB: db}, App: app}
+	if err = webhookListSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+	if webhookListVal, err = webhookListSrv.Execute(ctx); err != nil {
+		return
+	}
+	webhooks = webhookListVal.([]models.Webhook)
+	resp.Webhooks = make([]*Webhook, len(webhooks))
+	for i := range webhooks {
+		if resp.Webhooks[i], err = s.webhookResp(&webhooks[i]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// WebhookDelete is used to delete a webhook of app
+func (s *Server) WebhookDelete(ctx context.Context, req *WebhookDeleteRequest) (resp *WebhookDeleteResponse, err error) {
+	var (
+		db               = getDbConn()
+		app              *models.App
+		record           *models.Request
+		webhook          *models.Webhook
+		webhookDeleteSrv *service.WebhookDelete
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "WebhookDelete", req, db); err != nil {
+		return
+	}
+	resp = &WebhookDeleteResponse{RequestId: record.ID}
+	defer func() { s.updateRequestRecord(ctx, record, resp, err, db) }()
+	if app, err = fetchAPP(ctx, req.AppUid, req.AppSecret, db); err != nil {
+		return
+	}
+	record.AppID = &app.ID
+	if webhook, err = fetchWebhook(app, req.WebhookUid, db); err != nil {
+		return
+	}
+
+	webhookDeleteSrv = &service.WebhookDelete{BaseService: service.BaseService{DB: db}, App: app, Webhook: webhook}
+	if err = webhookDeleteSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+	if _, err = webhookDeleteSrv.Execute(ctx); err != nil {
+		return
+	}
+	db.Unscoped().First(webhook)
+	resp.Webhook, err = s.webhookResp(webhook)
+	return
+}
+
+// WebhookDeliveryList is used to inspect the delivery log of webhook
+func (s *Server) WebhookDeliveryList(ctx context.Context, req *WebhookDeliveryListRequest) (resp *WebhookDeliveryListResponse, err error) {
+	var (
+		db                     = getDbConn()
+		app                    *models.App
+		record                 *models.Request
+		webhook                *models.Webhook
+		webhookDeliveryListSrv *service.WebhookDeliveryList
+		webhookDeliveryListVal interface{}
+		deliveryListResp       *service.WebhookDeliveryListResponse
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "WebhookDeliveryList", req, db); err != nil {
+		return
+	}
+	resp = &WebhookDeliveryListResponse{RequestId: record.ID}
+	defer func() { s.updateRequestRecord(ctx, record, resp, err, db) }()
+	if app, err = fetchAPP(ctx, req.AppUid, req.AppSecret, db); err != nil {
+		return
+	}
+	record.AppID = &app.ID
+	if webhook, err = fetchWebhook(app, req.WebhookUid, db); err != nil {
+		return
+	}
+
+	webhookDeliveryListSrv = &service.WebhookDeliveryList{
+		BaseService: service.BaseService{DB: db},
+		App:         app,
+		Webhook:     webhook,
+		Offset:      int(req.GetOffset()),
+		Limit:       20,
+	}
+	if req.GetStatus() != nil {
+		deliveryStatus := req.GetStatus().GetValue()
+		webhookDeliveryListSrv.Status = &deliveryStatus
+	}
+	if req.GetLimit() != 0 {
+		webhookDeliveryListSrv.Limit = int(req.GetLimit())
+	}
+	if err = webhookDeliveryListSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+	if webhookDeliveryListVal, err = webhookDeliveryListSrv.Execute(ctx); err != nil {
+		return
+	}
+	deliveryListResp = webhookDeliveryListVal.(*service.WebhookDeliveryListResponse)
+	resp.Total = uint64(deliveryListResp.Total)
+	resp.Pages = uint64(deliveryListResp.Pages)
+	resp.Deliveries = make([]*WebhookDelivery, len(deliveryListResp.Deliveries))
+	for i := range deliveryListResp.Deliveries {
+		if resp.Deliveries[i], err = s.webhookDeliveryResp(&deliveryListResp.Deliveries[i]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Watch is used to push the changes of file tree continuously, until the
+// client cancels the stream
+func (s *Server) Watch(req *WatchRequest, resp Watch_WatchServer) (err error) {
+	var (
+		db       = getDbConn()
+		ctx      = resp.Context()
+		token    *models.Token
+		record   *models.Request
+		watchSrv *service.Watch
+	)
+	defer func() {
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+		}
+	}()
+	if record, err = s.generateRequestRecord(ctx, "Watch", req, db); err != nil {
+		return
+	}
+	if token, err = s.fetchToken(ctx, req.Token, req.Secret, db); err != nil {
+		return
+	}
+	record.AppID = &token.App.ID
+	record.Token = &token.UID
+	if err = db.Model(record).Updates(map[string]interface{}{"appId": record.AppID, "token": record.Token}).Error; err != nil {
+		return
+	}
+
+	watchSrv = &service.Watch{
+		BaseService: service.BaseService{DB: db},
+		Token:       token,
+		IP:          record.IP,
+		SubDir:      "/",
+		Limit:       100,
+		Send: func(changes *service.ChangeFeedResponse) (err error) {
+			response := &WatchResponse{
+				RequestId: record.ID,
+				Cursor:    changes.Cursor,
+				Changes:   make([]*Change, len(changes.Changes)),
+			}
+			for i := range changes.Changes {
+				if response.Changes[i], err = s.changeResp(&changes.Changes[i]); err != nil {
+					return err
+				}
+			}
+			// Send blocks while the flow control window of stream is full
+			return resp.Send(response)
+		},
+	}
+	if req.GetSubDir() != nil {
+		watchSrv.SubDir = req.GetSubDir().GetValue()
+	}
+	if req.GetCursor() != nil {
+		cursor := req.GetCursor().GetValue()
+		watchSrv.Cursor = &cursor
+	}
+	if req.GetLimit() != 0 {
+		watchSrv.Limit = int(req.GetLimit())
+	}
+	if isTesting {
+		watchSrv.Interval = 10 * time.Millisecond
+	}
+	if err = watchSrv.Validate(); !reflect.ValueOf(err).IsNil() {
+		return
+	}
+	_, err = watchSrv.Execute(ctx)
+	return
+}
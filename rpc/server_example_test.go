@@ -43,8 +43,17 @@ func init() {
 	RegisterFileReadServer(s, server)
 	RegisterImageConvertServer(s, server)
 	RegisterFileDeleteServer(s, server)
+	RegisterFileStatServer(s, server)
 	RegisterFileUpdateServer(s, server)
 	RegisterDirectoryListServer(s, server)
+	RegisterFileLockServer(s, server)
+	RegisterFileBatchServer(s, server)
+	RegisterFileRetentionServer(s, server)
+	RegisterFileArchiveServer(s, server)
+	RegisterFileExtractServer(s, server)
+	RegisterChangeFeedServer(s, server)
+	RegisterWebhookServer(s, server)
+	RegisterWatchServer(s, server)
 	go func() { _ = s.Serve(lis) }()
 }
 
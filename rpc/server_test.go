@@ -5,9 +5,11 @@
 package rpc
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"io"
+	"io/ioutil"
 	"net"
 	"os"
 	"path"
@@ -75,7 +77,7 @@ func TestServer_fetchApp(t *testing.T) {
 	app, trx, down, err := models.NewAppForTest(nil, t)
 	assert.Nil(t, err)
 	defer down(t)
-	_, err = fetchAPP(app.UID, "", trx)
+	_, err = fetchAPP(context.Background(), app.UID, "", trx)
 	assert.Equal(t, err, ErrAppSecret)
 }
 
@@ -373,6 +375,17 @@ func TestServer_FileUpdate(t *testing.T) {
 	statusError, ok := status.FromError(err)
 	assert.True(t, ok)
 	assert.Contains(t, statusError.Message(), service.ErrInvalidPath.Error())
+
+	req.Path = "/new/random.bytes"
+	req.ExpiredAt = &timestamp.Timestamp{Seconds: time.Now().Add(time.Hour).Unix()}
+	resp, err = s.FileUpdate(newContext(context.Background()), req)
+	assert.Nil(t, err)
+	assert.Equal(t, req.ExpiredAt.Seconds, resp.File.ExpiredAt.Seconds)
+
+	req.ExpiredAt = &timestamp.Timestamp{}
+	resp, err = s.FileUpdate(newContext(context.Background()), req)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.File.ExpiredAt)
 }
 
 func TestServer_FileDelete(t *testing.T) {
@@ -397,9 +410,15 @@ func TestServer_FileDelete(t *testing.T) {
 	req := &FileDeleteRequest{
 		Token:   token.UID,
 		FileUid: file.UID,
+		IfMatch: &wrappers.StringValue{Value: "not the hash"},
 	}
 
 	s := Server{}
+	_, err = s.FileDelete(newContext(context.Background()), req)
+	assert.NotNil(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	req.IfMatch = &wrappers.StringValue{Value: randomBytesHash}
 	resp, err := s.FileDelete(newContext(context.Background()), req)
 	assert.Nil(t, err)
 	assert.Equal(t, p, resp.File.Path)
@@ -425,6 +444,58 @@ func TestServer_FileDelete(t *testing.T) {
 	assert.Contains(t, statusError.Message(), models.ErrAccessDenied.Error())
 }
 
+func TestServer_FileStat(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	tempDir := models.NewTempDirForTest()
+	testRootPath = &tempDir
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	assert.Nil(t, trx.Model(token).Update("path", "/scope").Error)
+	file, err := models.CreateFileFromReader(&token.App, "/scope/a.txt", bytes.NewReader([]byte("a")), int8(0), testRootPath, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, file.OverWriteFromReader(bytes.NewReader([]byte("abc")), models.Hidden, testRootPath, trx))
+
+	req := &FileStatRequest{
+		Token:   token.UID,
+		FileUid: file.UID,
+		Path:    &wrappers.StringValue{Value: "/a.txt"},
+	}
+
+	s := Server{}
+	_, err = s.FileStat(newContext(context.Background()), req)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), service.ErrFileTarget.Error())
+
+	req.FileUid = ""
+	resp, err := s.FileStat(newContext(context.Background()), req)
+	assert.Nil(t, err)
+	assert.Equal(t, "/scope/a.txt", resp.File.Path)
+	assert.Equal(t, uint64(3), resp.File.Size)
+	assert.True(t, resp.File.Hidden)
+	assert.Equal(t, file.Object.Hash, resp.File.Hash.GetValue())
+	assert.Equal(t, "text/plain; charset=utf-8", resp.MimeType)
+	assert.Equal(t, uint32(1), resp.VersionCount)
+	assert.NotNil(t, resp.CreatedAt)
+	assert.NotNil(t, resp.UpdatedAt)
+
+	deleteResp, err := s.FileDelete(newContext(context.Background()), &FileDeleteRequest{
+		Token: token.UID,
+		Path:  &wrappers.StringValue{Value: "/a.txt"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, file.UID, deleteResp.File.Uid)
+
+	_, err = s.FileStat(newContext(context.Background()), req)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "record not found")
+}
+
 func TestServer_FileRead(t *testing.T) {
 	// create token
 	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
@@ -649,3 +720,416 @@ func TestServer_DirectoryList(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "the min value of limit is 10, and max of limit 20")
 }
+
+func TestServer_FileLock(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	tempDir := models.NewTempDirForTest()
+	testRootPath = &tempDir
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	p := "/" + path.Join("", models.RandomWithMD5(22), "r.bytes")
+	file, err := models.CreateFileFromReader(&token.App, p, bytes.NewReader(models.Random(222)), int8(0), testRootPath, trx)
+	assert.Nil(t, err)
+
+	s := Server{}
+	acquireResp, err := s.FileLockAcquire(newContext(context.Background()), &FileLockAcquireRequest{
+		Token:     token.UID,
+		FileUid:   file.UID,
+		Exclusive: true,
+		Ttl:       60,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, file.UID, acquireResp.FileUid)
+	assert.True(t, acquireResp.Lock.Exclusive)
+
+	fileResp, err := s.fileResp(file, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(fileResp.Locks))
+	assert.Equal(t, acquireResp.Lock.Uid, fileResp.Locks[0].Uid)
+
+	refreshResp, err := s.FileLockRefresh(newContext(context.Background()), &FileLockRefreshRequest{
+		Token:   token.UID,
+		LockUid: acquireResp.Lock.Uid,
+		Ttl:     3600,
+	})
+	assert.Nil(t, err)
+	assert.True(t, refreshResp.Lock.ExpiredAt.Seconds > acquireResp.Lock.ExpiredAt.Seconds)
+
+	_, err = s.FileLockRefresh(newContext(context.Background()), &FileLockRefreshRequest{
+		Token:   token.UID,
+		LockUid: acquireResp.Lock.Uid,
+	})
+	assert.NotNil(t, err)
+
+	_, err = s.FileLockRelease(newContext(context.Background()), &FileLockReleaseRequest{
+		Token:   token.UID,
+		LockUid: acquireResp.Lock.Uid,
+	})
+	assert.Nil(t, err)
+
+	_, err = s.FileLockRelease(newContext(context.Background()), &FileLockReleaseRequest{
+		Token:   token.UID,
+		LockUid: acquireResp.Lock.Uid,
+	})
+	assert.NotNil(t, err)
+}
+
+func TestServer_FileArchive(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	rootPath := models.NewTempDirForTest()
+	defer func() {
+		down(t)
+		if util.IsDir(rootPath) {
+			os.RemoveAll(rootPath)
+		}
+	}()
+	testDbConn = trx
+	testRootPath = &rootPath
+
+	randomBytes := models.Random(uint(models.ChunkSize + 222))
+	file, err := models.CreateFileFromReader(&token.App, "/random/r.bytes", bytes.NewReader(randomBytes), int8(0), testRootPath, trx)
+	assert.Nil(t, err)
+
+	const bufSize = 1024 * 1024
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	RegisterFileArchiveServer(s, &Server{})
+	go func() { _ = s.Serve(lis) }()
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	ctx := newContext(context.Background())
+
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	assert.Nil(t, err)
+	client := NewFileArchiveClient(conn)
+	streamClient, err := client.FileArchive(ctx, &FileArchiveRequest{Token: token.UID, FileUids: []string{file.UID}})
+	assert.Nil(t, err)
+	dataBuffer := new(bytes.Buffer)
+	for {
+		resp, err := streamClient.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		dataBuffer.Write(resp.Content)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(dataBuffer.Bytes()), int64(dataBuffer.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(reader.File))
+	assert.Equal(t, "r.bytes", reader.File[0].Name)
+	content, err := reader.File[0].Open()
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(content)
+	assert.Nil(t, err)
+	assert.Equal(t, randomBytes, data)
+
+	streamClient, err = client.FileArchive(ctx, &FileArchiveRequest{Token: token.UID})
+	assert.Nil(t, err)
+	_, err = streamClient.Recv()
+	assert.NotNil(t, err)
+}
+
+func TestServer_FileExtract(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	tempDir := models.NewTempDirForTest()
+	testRootPath = &tempDir
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+	entryWriter, err := zipWriter.Create("dir/a.txt")
+	assert.Nil(t, err)
+	_, err = entryWriter.Write([]byte("a"))
+	assert.Nil(t, err)
+	assert.Nil(t, zipWriter.Close())
+	archive, err := models.CreateFileFromReader(&token.App, "/upload/bundle.zip", buf, int8(0), testRootPath, trx)
+	assert.Nil(t, err)
+
+	s := Server{}
+	resp, err := s.FileExtract(newContext(context.Background()), &FileExtractRequest{
+		Token:   token.UID,
+		FileUid: archive.UID,
+		Target:  "/out",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Results))
+	assert.True(t, resp.Results[0].Success)
+	assert.Equal(t, "/out/dir/a.txt", resp.Results[0].Path)
+	assert.Equal(t, uint64(1), resp.Results[0].File.Size)
+
+	_, err = s.FileExtract(newContext(context.Background()), &FileExtractRequest{
+		Token:      token.UID,
+		FileUid:    archive.UID,
+		Target:     "/out",
+		MaxEntries: 100001,
+	})
+	assert.NotNil(t, err)
+}
+
+func TestServer_Watch(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	defer down(t)
+
+	_, err = models.CreateOrGetLastDirectory(&token.App, "/watch/a", trx)
+	assert.Nil(t, err)
+
+	const bufSize = 1024 * 1024
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	RegisterWatchServer(s, &Server{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	ctx, cancel := context.WithCancel(newContext(context.Background()))
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	assert.Nil(t, err)
+	client := NewWatchClient(conn)
+	streamClient, err := client.Watch(ctx, &WatchRequest{
+		Token:  token.UID,
+		SubDir: &wrappers.StringValue{Value: "/watch"},
+		Cursor: &wrappers.UInt64Value{Value: 0},
+		Limit:  1,
+	})
+	assert.Nil(t, err)
+	resp, err := streamClient.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Changes))
+	assert.Equal(t, "/watch", resp.Changes[0].Path)
+	resp, err = streamClient.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, "/watch/a", resp.Changes[0].Path)
+	assert.Equal(t, resp.Changes[0].Sequence, resp.Cursor)
+	cancel()
+
+	streamClient, err = client.Watch(newContext(context.Background()), &WatchRequest{
+		Token:  token.UID,
+		SubDir: &wrappers.StringValue{Value: "/!@#"},
+	})
+	assert.Nil(t, err)
+	_, err = streamClient.Recv()
+	assert.NotNil(t, err)
+}
+
+func TestServer_Webhook(t *testing.T) {
+	defer func(webhook config.Webhook) { config.DefaultConfig.Webhook = webhook }(config.DefaultConfig.Webhook)
+	config.DefaultConfig.WebhookAllowedHosts = []string{"127.0.0.1"}
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	defer down(t)
+
+	s := Server{}
+	createResp, err := s.WebhookCreate(newContext(context.Background()), &WebhookCreateRequest{
+		AppUid:     token.App.UID,
+		AppSecret:  token.App.Secret,
+		Url:        "http://127.0.0.1/hook",
+		Events:     []string{models.WebhookEventCreated},
+		PathPrefix: &wrappers.StringValue{Value: "/save"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "/save", createResp.Webhook.PathPrefix)
+	assert.Equal(t, []string{models.WebhookEventCreated}, createResp.Webhook.Events)
+
+	_, err = s.WebhookCreate(newContext(context.Background()), &WebhookCreateRequest{
+		AppUid:    token.App.UID,
+		AppSecret: token.App.Secret,
+		Url:       "127.0.0.1/hook",
+	})
+	assert.NotNil(t, err)
+
+	listResp, err := s.WebhookList(newContext(context.Background()), &WebhookListRequest{
+		AppUid:    token.App.UID,
+		AppSecret: token.App.Secret,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(listResp.Webhooks))
+
+	_, err = models.CreateOrGetLastDirectory(&token.App, "/save/to", trx)
+	assert.Nil(t, err)
+	deliveryListResp, err := s.WebhookDeliveryList(newContext(context.Background()), &WebhookDeliveryListRequest{
+		AppUid:     token.App.UID,
+		AppSecret:  token.App.Secret,
+		WebhookUid: createResp.Webhook.Uid,
+		Status:     &wrappers.StringValue{Value: models.WebhookDeliveryPending},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), deliveryListResp.Total)
+	assert.Equal(t, models.WebhookEventCreated, deliveryListResp.Deliveries[0].Event)
+	assert.Contains(t, deliveryListResp.Deliveries[0].Payload, `"path":"/save/to"`)
+
+	other := newToken(t, trx)
+	_, err = s.WebhookDelete(newContext(context.Background()), &WebhookDeleteRequest{
+		AppUid:     other.App.UID,
+		AppSecret:  other.App.Secret,
+		WebhookUid: createResp.Webhook.Uid,
+	})
+	assert.NotNil(t, err)
+
+	deleteResp, err := s.WebhookDelete(newContext(context.Background()), &WebhookDeleteRequest{
+		AppUid:     token.App.UID,
+		AppSecret:  token.App.Secret,
+		WebhookUid: createResp.Webhook.Uid,
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, deleteResp.Webhook.DeletedAt)
+}
+
+func TestServer_ChangeFeed(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	defer down(t)
+
+	dir, err := models.CreateOrGetLastDirectory(&token.App, "/save/to", trx)
+	assert.Nil(t, err)
+	assert.Nil(t, dir.MoveTo("/save/moved", trx))
+
+	s := Server{}
+	resp, err := s.ChangeFeed(newContext(context.Background()), &ChangeFeedRequest{
+		Token:  token.UID,
+		SubDir: &wrappers.StringValue{Value: "/save"},
+		Limit:  2,
+	})
+	assert.Nil(t, err)
+	assert.True(t, resp.HasMore)
+	assert.Equal(t, 2, len(resp.Changes))
+	assert.Equal(t, models.JournalCreate, resp.Changes[0].Event)
+
+	resp, err = s.ChangeFeed(newContext(context.Background()), &ChangeFeedRequest{
+		Token:  token.UID,
+		SubDir: &wrappers.StringValue{Value: "/save"},
+		Cursor: resp.Cursor,
+	})
+	assert.Nil(t, err)
+	assert.False(t, resp.HasMore)
+	assert.Equal(t, 1, len(resp.Changes))
+	assert.Equal(t, models.JournalMove, resp.Changes[0].Event)
+	assert.Equal(t, "/save/to", resp.Changes[0].PreviousPath.Value)
+
+	_, err = s.ChangeFeed(newContext(context.Background()), &ChangeFeedRequest{Token: token.UID, Limit: 1001})
+	assert.NotNil(t, err)
+}
+
+func TestServer_FileRetention(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	tempDir := models.NewTempDirForTest()
+	testRootPath = &tempDir
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	p := "/" + path.Join("", models.RandomWithMD5(22), "r.bytes")
+	file, err := models.CreateFileFromReader(&token.App, p, bytes.NewReader(models.Random(222)), int8(0), testRootPath, trx)
+	assert.Nil(t, err)
+
+	s := Server{}
+	retainUntil := time.Now().Add(time.Hour)
+	resp, err := s.FileRetention(newContext(context.Background()), &FileRetentionRequest{
+		Token:       token.UID,
+		FileUid:     file.UID,
+		Mode:        &wrappers.UInt32Value{Value: uint32(models.RetentionGovernance)},
+		RetainUntil: &timestamp.Timestamp{Seconds: retainUntil.Unix()},
+		LegalHold:   &wrappers.BoolValue{Value: true},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(models.RetentionGovernance), resp.File.RetentionMode)
+	assert.Equal(t, retainUntil.Unix(), resp.File.RetainUntil.Seconds)
+	assert.True(t, resp.File.LegalHold)
+
+	_, err = s.FileRetention(newContext(context.Background()), &FileRetentionRequest{
+		Token:     token.UID,
+		FileUid:   file.UID,
+		LegalHold: &wrappers.BoolValue{Value: false},
+	})
+	assert.NotNil(t, err)
+
+	resp, err = s.FileRetentionAdmin(newContext(context.Background()), &FileRetentionAdminRequest{
+		AppUid:    token.App.UID,
+		AppSecret: token.App.Secret,
+		FileUid:   file.UID,
+		Mode:      &wrappers.UInt32Value{Value: uint32(models.RetentionNone)},
+		LegalHold: &wrappers.BoolValue{Value: false},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(models.RetentionNone), resp.File.RetentionMode)
+	assert.Nil(t, resp.File.RetainUntil)
+	assert.False(t, resp.File.LegalHold)
+}
+
+func TestServer_FileBatch(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	tempDir := models.NewTempDirForTest()
+	testRootPath = &tempDir
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	_, err = models.CreateFileFromReader(
+		&token.App, "/save/to/r.bytes", bytes.NewReader(models.Random(222)), int8(0), testRootPath, trx)
+	assert.Nil(t, err)
+
+	s := Server{}
+	resp, err := s.FileBatch(newContext(context.Background()), &FileBatchRequest{
+		Token: token.UID,
+		Operations: []*FileBatchOperation{
+			{Op: FileBatchOperation_Mkdir, Path: "/archive"},
+			{Op: FileBatchOperation_Move, Path: "/save/to/r.bytes", To: &wrappers.StringValue{Value: "/archive/r.bytes"}},
+			{Op: FileBatchOperation_SetHidden, Path: "/archive/r.bytes", Hidden: &wrappers.BoolValue{Value: true}},
+			{Op: FileBatchOperation_Delete, Path: "/save", Force: true},
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, 4, len(resp.Results))
+	assert.True(t, resp.Results[2].File.Hidden)
+	assert.Equal(t, "/archive/r.bytes", resp.Results[1].File.Path)
+
+	resp, err = s.FileBatch(newContext(context.Background()), &FileBatchRequest{
+		Token: token.UID,
+		Operations: []*FileBatchOperation{
+			{Op: FileBatchOperation_Copy, Path: "/archive/r.bytes", To: &wrappers.StringValue{Value: "/copy/r.bytes"}},
+			{Op: FileBatchOperation_Delete, Path: "/not/exist"},
+		},
+	})
+	assert.Nil(t, err)
+	assert.False(t, resp.Success)
+	assert.Equal(t, 2, len(resp.Results))
+	assert.False(t, resp.Results[1].Success)
+	assert.NotNil(t, resp.Results[1].Error)
+
+	_, err = s.FileBatch(newContext(context.Background()), &FileBatchRequest{
+		Token:      token.UID,
+		Operations: []*FileBatchOperation{{Op: FileBatchOperation_Move, Path: "/archive"}},
+	})
+	assert.NotNil(t, err)
+}
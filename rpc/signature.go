@@ -0,0 +1,172 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/golang/protobuf/proto"
+	"github.com/jinzhu/gorm"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// signedCredentialKey is the key of context, the value is the pointer of the
+// credential that request is signed with
+type signedCredentialKey struct{}
+
+// signedBy represent whether the request is signed by v2 signature with the
+// secret of uid, then the secret in request message isn't required
+func signedBy(ctx context.Context, uid string) bool {
+	credential, ok := ctx.Value(signedCredentialKey{}).(*string)
+	return ok && *credential != "" && *credential == uid
+}
+
+// metadataSignature is used to parse the v2 signature in the authorization
+// metadata, nil is returned if the request isn't signed by v2 signature
+func metadataSignature(ctx context.Context) (*service.SignatureV2, metadata.MD, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get("authorization")) == 0 || !service.IsSignatureV2(md.Get("authorization")[0]) {
+		return nil, md, nil
+	}
+	signature, err := service.ParseSignatureV2(md.Get("authorization")[0])
+	return signature, md, err
+}
+
+// credentialSecret return the secret of credential, credential is the uid of
+// token or app
+func credentialSecret(credential string, db *gorm.DB) (string, error) {
+	if token, err := models.FindTokenByUID(credential, db); err == nil {
+		return service.SigningSecret(token), nil
+	} else if !gorm.IsRecordNotFoundError(err) {
+		return "", err
+	}
+	app, err := models.FindAppByUID(credential, db)
+	if err != nil {
+		return "", service.ErrSignatureV2Credential
+	}
+	return app.Secret, nil
+}
+
+// verifySignature is used to verify the v2 signature of rpc request, the path
+// is the full method name that is called, such as /bigfile.file_read.FileRead/fileRead,
+// and the content hash is the sha256 of serialized request message
+func verifySignature(signature *service.SignatureV2, md metadata.MD, fullMethod string, req interface{}) error {
+	var (
+		err     error
+		body    []byte
+		secret  string
+		header  = http.Header{}
+		message proto.Message
+		ok      bool
+	)
+	if message, ok = req.(proto.Message); !ok {
+		return service.ErrSignatureV2ContentHash
+	}
+	if body, err = proto.Marshal(message); err != nil {
+		return err
+	}
+	if secret, err = credentialSecret(signature.Credential, getDbConn()); err != nil {
+		return err
+	}
+	for key, values := range md {
+		header[http.CanonicalHeaderKey(key)] = values
+	}
+	return signature.Verify(&service.SignatureV2Request{
+		Method:      http.MethodPost,
+		Path:        fullMethod,
+		Header:      header,
+		ContentHash: service.ContentHash(body),
+	}, secret, time.Now())
+}
+
+// unauthenticated is used to convert the error of signature to status error
+func unauthenticated(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Unauthenticated, err.Error())
+}
+
+// SignatureUnaryServerInterceptor is used to verify the v2 signature in the
+// metadata of unary rpc. The requests without it are passed through, they're
+// authenticated by the secret in request message as before.
+func SignatureUnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	signature, md, err := metadataSignature(ctx)
+	if err != nil {
+		return nil, unauthenticated(err)
+	}
+	if signature == nil {
+		return handler(ctx, req)
+	}
+	fullMethod, ok := grpc.Method(ctx)
+	if !ok {
+		fullMethod = info.FullMethod
+	}
+	if err = verifySignature(signature, md, fullMethod, req); err != nil {
+		return nil, unauthenticated(err)
+	}
+	return handler(context.WithValue(ctx, signedCredentialKey{}, &signature.Credential), req)
+}
+
+// signedServerStream is used to verify the v2 signature of stream rpc with
+// the first message, the credential is set after verifying
+type signedServerStream struct {
+	grpc.ServerStream
+	ctx        context.Context
+	md         metadata.MD
+	fullMethod string
+	signature  *service.SignatureV2
+	credential *string
+}
+
+func (s *signedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *signedServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if *s.credential == "" {
+		if err := verifySignature(s.signature, s.md, s.fullMethod, m); err != nil {
+			return unauthenticated(err)
+		}
+		*s.credential = s.signature.Credential
+	}
+	return nil
+}
+
+// SignatureStreamServerInterceptor is used to verify the v2 signature in the
+// metadata of stream rpc. The metadata is sent once for a stream, so only the
+// first message is covered by the content hash.
+func SignatureStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	signature, md, err := metadataSignature(ss.Context())
+	if err != nil {
+		return unauthenticated(err)
+	}
+	if signature == nil {
+		return handler(srv, ss)
+	}
+	fullMethod, ok := grpc.MethodFromServerStream(ss)
+	if !ok {
+		fullMethod = info.FullMethod
+	}
+	credential := new(string)
+	return handler(srv, &signedServerStream{
+		ServerStream: ss,
+		ctx:          context.WithValue(ss.Context(), signedCredentialKey{}, credential),
+		md:           md,
+		fullMethod:   fullMethod,
+		signature:    signature,
+		credential:   credential,
+	})
+}
@@ -0,0 +1,123 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/service"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// signContext is used to sign the request message by v2 signature, and put
+// the signature in the outgoing metadata
+func signContext(ctx context.Context, fullMethod string, req proto.Message, credential, secret string) context.Context {
+	body, _ := proto.Marshal(req)
+	header := http.Header{}
+	header.Set(service.SignatureV2DateHeader, strconv.FormatInt(time.Now().Unix(), 10))
+	header.Set(service.SignatureV2ContentHashHeader, service.ContentHash(body))
+	signature := service.NewSignatureV2(&service.SignatureV2Request{
+		Method:      http.MethodPost,
+		Path:        fullMethod,
+		Header:      header,
+		ContentHash: service.ContentHash(body),
+	}, credential, []string{"x-bigfile-content-sha256", "x-bigfile-date"}, secret)
+	return metadata.AppendToOutgoingContext(ctx,
+		"authorization", signature.Authorization(),
+		"x-bigfile-date", header.Get(service.SignatureV2DateHeader),
+		"x-bigfile-content-sha256", header.Get(service.SignatureV2ContentHashHeader),
+	)
+}
+
+func TestSignatureInterceptor(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	testDbConn = trx
+	tempDir := models.NewTempDirForTest()
+	testRootPath = &tempDir
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	secret := models.NewSecret()
+	assert.Nil(t, trx.Model(token).Update("secret", secret).Error)
+	file, err := models.CreateFileFromReader(&token.App, "/signed.txt", bytes.NewReader([]byte("signed")), int8(0), testRootPath, trx)
+	assert.Nil(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(SignatureUnaryServerInterceptor),
+		grpc.StreamInterceptor(SignatureStreamServerInterceptor),
+	)
+	RegisterFileStatServer(s, &Server{})
+	RegisterFileReadServer(s, &Server{})
+	RegisterTokenCreateServer(s, &Server{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	ctx := context.Background()
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	var (
+		statMethod = "/bigfile.file_stat.FileStat/fileStat"
+		statClient = NewFileStatClient(conn)
+		statReq    = &FileStatRequest{Token: token.UID, FileUid: file.UID}
+	)
+
+	// v1, the secret is required in message
+	_, err = statClient.FileStat(ctx, statReq)
+	assert.Contains(t, err.Error(), ErrTokenSecretWrong.Error())
+
+	resp, err := statClient.FileStat(signContext(ctx, statMethod, statReq, token.UID, secret), statReq)
+	assert.Nil(t, err)
+	assert.Equal(t, "/signed.txt", resp.File.Path)
+
+	_, err = statClient.FileStat(signContext(ctx, statMethod, statReq, token.UID, "wrong secret"), statReq)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Contains(t, err.Error(), service.ErrSignatureV2Mismatch.Error())
+
+	tampered := &FileStatRequest{Token: token.UID, Path: &wrappers.StringValue{Value: "/signed.txt"}}
+	_, err = statClient.FileStat(signContext(ctx, statMethod, statReq, token.UID, secret), tampered)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Contains(t, err.Error(), service.ErrSignatureV2ContentHash.Error())
+
+	_, err = statClient.FileStat(signContext(ctx, statMethod, statReq, "unknown", secret), statReq)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Contains(t, err.Error(), service.ErrSignatureV2Credential.Error())
+
+	readReq := &FileReadRequest{Token: token.UID, FileUid: file.UID}
+	readClient, err := NewFileReadClient(conn).FileRead(signContext(ctx, "/bigfile.file_read.FileRead/fileRead", readReq, token.UID, secret), readReq)
+	assert.Nil(t, err)
+	readResp, err := readClient.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, "signed", string(readResp.Content))
+
+	createReq := &TokenCreateRequest{AppUid: token.App.UID, Path: &wrappers.StringValue{Value: "/signed"}}
+	createResp, err := NewTokenCreateClient(conn).TokenCreate(signContext(ctx, "/bigfile.token_create.TokenCreate/tokenCreate", createReq, token.App.UID, token.App.Secret), createReq)
+	assert.Nil(t, err)
+	assert.Equal(t, "/signed", createResp.Token.Path)
+}
@@ -0,0 +1,302 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: watch.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// WatchRequest represent the request of watching the changes in sub_dir. The
+// watch resumes after cursor, which is the cursor of the last received
+// response, or starts from now on if cursor is omitted. limit is the max
+// number of changes in a response, the default value is 100.
+type WatchRequest struct {
+	Token                string                `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Secret               *wrappers.StringValue `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	SubDir               *wrappers.StringValue `protobuf:"bytes,3,opt,name=sub_dir,json=subDir,proto3" json:"sub_dir,omitempty"`
+	Cursor               *wrappers.UInt64Value `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                uint32                `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c826da73fff4a2c7, []int{0}
+}
+
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
+}
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(m, src)
+}
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
+}
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
+
+func (m *WatchRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *WatchRequest) GetSecret() *wrappers.StringValue {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+func (m *WatchRequest) GetSubDir() *wrappers.StringValue {
+	if m != nil {
+		return m.SubDir
+	}
+	return nil
+}
+
+func (m *WatchRequest) GetCursor() *wrappers.UInt64Value {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
+func (m *WatchRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// WatchResponse represent a batch of changes. A response without changes is
+// a heartbeat, it's sent when nothing happens for a while. The client should
+// save cursor, and resume from it after reconnecting.
+type WatchResponse struct {
+	RequestId            uint64    `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Cursor               uint64    `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Changes              []*Change `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *WatchResponse) Reset()         { *m = WatchResponse{} }
+func (m *WatchResponse) String() string { return proto.CompactTextString(m) }
+func (*WatchResponse) ProtoMessage()    {}
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c826da73fff4a2c7, []int{1}
+}
+
+func (m *WatchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchResponse.Unmarshal(m, b)
+}
+func (m *WatchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchResponse.Marshal(b, m, deterministic)
+}
+func (m *WatchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchResponse.Merge(m, src)
+}
+func (m *WatchResponse) XXX_Size() int {
+	return xxx_messageInfo_WatchResponse.Size(m)
+}
+func (m *WatchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchResponse proto.InternalMessageInfo
+
+func (m *WatchResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *WatchResponse) GetCursor() uint64 {
+	if m != nil {
+		return m.Cursor
+	}
+	return 0
+}
+
+func (m *WatchResponse) GetChanges() []*Change {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*WatchRequest)(nil), "bigfile.watch.WatchRequest")
+	proto.RegisterType((*WatchResponse)(nil), "bigfile.watch.WatchResponse")
+}
+
+func init() { proto.RegisterFile("watch.proto", fileDescriptor_c826da73fff4a2c7) }
+
+var fileDescriptor_c826da73fff4a2c7 = []byte{
+	// 375 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x51, 0x4d, 0x4f, 0xfa, 0x30,
+	0x1c, 0xfe, 0x77, 0x63, 0x10, 0xca, 0x9f, 0x83, 0x0d, 0x31, 0x0b, 0x22, 0x2e, 0x9c, 0x76, 0x1a,
+	0x06, 0xc1, 0x0f, 0x30, 0x8d, 0x09, 0xf1, 0xe0, 0x32, 0x5f, 0x48, 0xbc, 0x90, 0xbd, 0x94, 0xd1,
+	0x38, 0xd6, 0xd9, 0x76, 0x21, 0x1e, 0xfc, 0x32, 0x1e, 0xfd, 0x4e, 0x7e, 0x0f, 0x8f, 0x66, 0xed,
+	0x86, 0x1c, 0x34, 0xf1, 0xb4, 0x3d, 0xfd, 0x3d, 0x2f, 0xfd, 0x3d, 0x85, 0x9d, 0x6d, 0x20, 0xa2,
+	0xb5, 0x93, 0x33, 0x2a, 0x28, 0xea, 0x86, 0x24, 0x59, 0x91, 0x14, 0x3b, 0xf2, 0xb0, 0x7f, 0x10,
+	0xad, 0x83, 0x2c, 0xc1, 0xcb, 0x15, 0xc6, 0xb1, 0x62, 0xf4, 0x87, 0x09, 0xa5, 0x49, 0x8a, 0xc7,
+	0x12, 0x85, 0xc5, 0x6a, 0xbc, 0x65, 0x41, 0x9e, 0x63, 0xc6, 0xd5, 0x7c, 0xf4, 0x01, 0xe0, 0xff,
+	0x45, 0x29, 0xf6, 0xf1, 0x73, 0x81, 0xb9, 0x40, 0x3d, 0x68, 0x08, 0xfa, 0x84, 0x33, 0x13, 0x58,
+	0xc0, 0x6e, 0xfb, 0x0a, 0xa0, 0x29, 0x6c, 0x72, 0x1c, 0x31, 0x2c, 0x4c, 0xcd, 0x02, 0x76, 0x67,
+	0x32, 0x70, 0x94, 0xaf, 0x53, 0xfb, 0x3a, 0xb7, 0x82, 0x91, 0x2c, 0x79, 0x08, 0xd2, 0x02, 0xfb,
+	0x15, 0x17, 0xcd, 0x60, 0x8b, 0x17, 0xe1, 0x32, 0x26, 0xcc, 0xd4, 0xff, 0x24, 0x2b, 0xc2, 0x4b,
+	0xc2, 0xca, 0xb0, 0xa8, 0x60, 0x9c, 0x32, 0xb3, 0xf1, 0x8b, 0xea, 0x7e, 0x9e, 0x89, 0xf3, 0x69,
+	0xa5, 0x52, 0xdc, 0xf2, 0xe2, 0x29, 0xd9, 0x10, 0x61, 0x1a, 0x16, 0xb0, 0xbb, 0xbe, 0x02, 0xa3,
+	0x57, 0xd8, 0xad, 0xd6, 0xe3, 0x39, 0xcd, 0x38, 0x46, 0xc7, 0x10, 0x32, 0xb5, 0xea, 0x92, 0xc4,
+	0x72, 0xc9, 0x86, 0xdf, 0xae, 0x4e, 0xe6, 0x31, 0x3a, 0xdc, 0x65, 0x6b, 0x72, 0x54, 0xbb, 0xcf,
+	0x60, 0x4b, 0x95, 0xcb, 0x4d, 0xdd, 0xd2, 0xed, 0xce, 0xe4, 0xc8, 0xa9, 0xbb, 0xdf, 0x2f, 0xfd,
+	0x42, 0xfe, 0xfb, 0x35, 0x77, 0x72, 0x03, 0x0d, 0x19, 0x8f, 0xae, 0xa0, 0x21, 0xdf, 0x08, 0x7d,
+	0xeb, 0xd4, 0x43, 0xee, 0x97, 0xdf, 0x1f, 0xfc, 0x3c, 0x54, 0x57, 0x1f, 0xfd, 0x3b, 0x05, 0x2e,
+	0x85, 0xbd, 0x88, 0x6e, 0x76, 0xb4, 0xba, 0x11, 0x17, 0x4a, 0xaa, 0x57, 0x42, 0x0f, 0x3c, 0x0e,
+	0x13, 0x22, 0xd6, 0x45, 0xe8, 0x44, 0x74, 0x33, 0xae, 0xa8, 0xbb, 0x2f, 0xcb, 0xa3, 0x4f, 0x00,
+	0xde, 0x34, 0xdd, 0xf5, 0xfc, 0x77, 0xed, 0xc4, 0xad, 0x9c, 0xbc, 0xba, 0xdb, 0x05, 0x4e, 0xd3,
+	0xeb, 0x8c, 0x6e, 0xb3, 0xbb, 0x97, 0x1c, 0xf3, 0xb0, 0x29, 0x23, 0xce, 0xbe, 0x06, 0x00, 0x7d,
+	0x52, 0x70, 0x97, 0x78, 0x02, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// WatchClient is the client API for Watch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type WatchClient interface {
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Watch_WatchClient, error)
+}
+
+type watchClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewWatchClient(cc *grpc.ClientConn) WatchClient {
+	return &watchClient{cc}
+}
+
+func (c *watchClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Watch_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Watch_serviceDesc.Streams[0], "/bigfile.watch.Watch/watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &watchWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Watch_WatchClient interface {
+	Recv() (*WatchResponse, error)
+	grpc.ClientStream
+}
+
+type watchWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *watchWatchClient) Recv() (*WatchResponse, error) {
+	m := new(WatchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WatchServer is the server API for Watch service.
+type WatchServer interface {
+	Watch(*WatchRequest, Watch_WatchServer) error
+}
+
+// UnimplementedWatchServer can be embedded to have forward compatible implementations.
+type UnimplementedWatchServer struct {
+}
+
+func (*UnimplementedWatchServer) Watch(req *WatchRequest, srv Watch_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+
+func RegisterWatchServer(s *grpc.Server, srv WatchServer) {
+	s.RegisterService(&_Watch_serviceDesc, srv)
+}
+
+func _Watch_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WatchServer).Watch(m, &watchWatchServer{stream})
+}
+
+type Watch_WatchServer interface {
+	Send(*WatchResponse) error
+	grpc.ServerStream
+}
+
+type watchWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *watchWatchServer) Send(m *WatchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Watch_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bigfile.watch.Watch",
+	HandlerType: (*WatchServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "watch",
+			Handler:       _Watch_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "watch.proto",
+}
@@ -0,0 +1,955 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: webhook.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Webhook represent an endpoint of app that is notified when files change
+type Webhook struct {
+	Uid                  string               `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Url                  string               `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Events               []string             `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	PathPrefix           string               `protobuf:"bytes,4,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	DeletedAt            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Webhook) Reset()         { *m = Webhook{} }
+func (m *Webhook) String() string { return proto.CompactTextString(m) }
+func (*Webhook) ProtoMessage()    {}
+func (*Webhook) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{0}
+}
+
+func (m *Webhook) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Webhook.Unmarshal(m, b)
+}
+func (m *Webhook) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Webhook.Marshal(b, m, deterministic)
+}
+func (m *Webhook) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Webhook.Merge(m, src)
+}
+func (m *Webhook) XXX_Size() int {
+	return xxx_messageInfo_Webhook.Size(m)
+}
+func (m *Webhook) XXX_DiscardUnknown() {
+	xxx_messageInfo_Webhook.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Webhook proto.InternalMessageInfo
+
+func (m *Webhook) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *Webhook) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Webhook) GetEvents() []string {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *Webhook) GetPathPrefix() string {
+	if m != nil {
+		return m.PathPrefix
+	}
+	return ""
+}
+
+func (m *Webhook) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *Webhook) GetDeletedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeletedAt
+	}
+	return nil
+}
+
+// WebhookDelivery represent a notification of webhook, payload is the json
+// body that is sent
+type WebhookDelivery struct {
+	Uid                  string                `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Event                string                `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	Sequence             uint64                `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Status               string                `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Attempts             uint32                `protobuf:"varint,5,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	NextAttemptAt        *timestamp.Timestamp  `protobuf:"bytes,6,opt,name=next_attempt_at,json=nextAttemptAt,proto3" json:"next_attempt_at,omitempty"`
+	ResponseCode         *wrappers.Int32Value  `protobuf:"bytes,7,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"`
+	LastError            *wrappers.StringValue `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	DeliveredAt          *timestamp.Timestamp  `protobuf:"bytes,9,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	CreatedAt            *timestamp.Timestamp  `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Payload              string                `protobuf:"bytes,11,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *WebhookDelivery) Reset()         { *m = WebhookDelivery{} }
+func (m *WebhookDelivery) String() string { return proto.CompactTextString(m) }
+func (*WebhookDelivery) ProtoMessage()    {}
+func (*WebhookDelivery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{1}
+}
+
+func (m *WebhookDelivery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookDelivery.Unmarshal(m, b)
+}
+func (m *WebhookDelivery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookDelivery.Marshal(b, m, deterministic)
+}
+func (m *WebhookDelivery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookDelivery.Merge(m, src)
+}
+func (m *WebhookDelivery) XXX_Size() int {
+	return xxx_messageInfo_WebhookDelivery.Size(m)
+}
+func (m *WebhookDelivery) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookDelivery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookDelivery proto.InternalMessageInfo
+
+func (m *WebhookDelivery) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *WebhookDelivery) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *WebhookDelivery) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *WebhookDelivery) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *WebhookDelivery) GetAttempts() uint32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *WebhookDelivery) GetNextAttemptAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.NextAttemptAt
+	}
+	return nil
+}
+
+func (m *WebhookDelivery) GetResponseCode() *wrappers.Int32Value {
+	if m != nil {
+		return m.ResponseCode
+	}
+	return nil
+}
+
+func (m *WebhookDelivery) GetLastError() *wrappers.StringValue {
+	if m != nil {
+		return m.LastError
+	}
+	return nil
+}
+
+func (m *WebhookDelivery) GetDeliveredAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeliveredAt
+	}
+	return nil
+}
+
+func (m *WebhookDelivery) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *WebhookDelivery) GetPayload() string {
+	if m != nil {
+		return m.Payload
+	}
+	return ""
+}
+
+// WebhookCreateRequest represent the request of registering a webhook, all
+// events are subscribed if events is empty
+type WebhookCreateRequest struct {
+	AppUid               string                `protobuf:"bytes,1,opt,name=app_uid,json=appUid,proto3" json:"app_uid,omitempty"`
+	AppSecret            string                `protobuf:"bytes,2,opt,name=app_secret,json=appSecret,proto3" json:"app_secret,omitempty"`
+	Url                  string                `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Events               []string              `protobuf:"bytes,4,rep,name=events,proto3" json:"events,omitempty"`
+	PathPrefix           *wrappers.StringValue `protobuf:"bytes,5,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *WebhookCreateRequest) Reset()         { *m = WebhookCreateRequest{} }
+func (m *WebhookCreateRequest) String() string { return proto.CompactTextString(m) }
+func (*WebhookCreateRequest) ProtoMessage()    {}
+func (*WebhookCreateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{2}
+}
+
+func (m *WebhookCreateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookCreateRequest.Unmarshal(m, b)
+}
+func (m *WebhookCreateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookCreateRequest.Marshal(b, m, deterministic)
+}
+func (m *WebhookCreateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookCreateRequest.Merge(m, src)
+}
+func (m *WebhookCreateRequest) XXX_Size() int {
+	return xxx_messageInfo_WebhookCreateRequest.Size(m)
+}
+func (m *WebhookCreateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookCreateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookCreateRequest proto.InternalMessageInfo
+
+func (m *WebhookCreateRequest) GetAppUid() string {
+	if m != nil {
+		return m.AppUid
+	}
+	return ""
+}
+
+func (m *WebhookCreateRequest) GetAppSecret() string {
+	if m != nil {
+		return m.AppSecret
+	}
+	return ""
+}
+
+func (m *WebhookCreateRequest) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *WebhookCreateRequest) GetEvents() []string {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *WebhookCreateRequest) GetPathPrefix() *wrappers.StringValue {
+	if m != nil {
+		return m.PathPrefix
+	}
+	return nil
+}
+
+// WebhookCreateResponse represent the response of registering a webhook
+type WebhookCreateResponse struct {
+	RequestId            uint64   `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Webhook              *Webhook `protobuf:"bytes,2,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WebhookCreateResponse) Reset()         { *m = WebhookCreateResponse{} }
+func (m *WebhookCreateResponse) String() string { return proto.CompactTextString(m) }
+func (*WebhookCreateResponse) ProtoMessage()    {}
+func (*WebhookCreateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{3}
+}
+
+func (m *WebhookCreateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookCreateResponse.Unmarshal(m, b)
+}
+func (m *WebhookCreateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookCreateResponse.Marshal(b, m, deterministic)
+}
+func (m *WebhookCreateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookCreateResponse.Merge(m, src)
+}
+func (m *WebhookCreateResponse) XXX_Size() int {
+	return xxx_messageInfo_WebhookCreateResponse.Size(m)
+}
+func (m *WebhookCreateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookCreateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookCreateResponse proto.InternalMessageInfo
+
+func (m *WebhookCreateResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *WebhookCreateResponse) GetWebhook() *Webhook {
+	if m != nil {
+		return m.Webhook
+	}
+	return nil
+}
+
+// WebhookListRequest represent the request of listing the webhooks of app
+type WebhookListRequest struct {
+	AppUid               string   `protobuf:"bytes,1,opt,name=app_uid,json=appUid,proto3" json:"app_uid,omitempty"`
+	AppSecret            string   `protobuf:"bytes,2,opt,name=app_secret,json=appSecret,proto3" json:"app_secret,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WebhookListRequest) Reset()         { *m = WebhookListRequest{} }
+func (m *WebhookListRequest) String() string { return proto.CompactTextString(m) }
+func (*WebhookListRequest) ProtoMessage()    {}
+func (*WebhookListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{4}
+}
+
+func (m *WebhookListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookListRequest.Unmarshal(m, b)
+}
+func (m *WebhookListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookListRequest.Marshal(b, m, deterministic)
+}
+func (m *WebhookListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookListRequest.Merge(m, src)
+}
+func (m *WebhookListRequest) XXX_Size() int {
+	return xxx_messageInfo_WebhookListRequest.Size(m)
+}
+func (m *WebhookListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookListRequest proto.InternalMessageInfo
+
+func (m *WebhookListRequest) GetAppUid() string {
+	if m != nil {
+		return m.AppUid
+	}
+	return ""
+}
+
+func (m *WebhookListRequest) GetAppSecret() string {
+	if m != nil {
+		return m.AppSecret
+	}
+	return ""
+}
+
+// WebhookListResponse represent the response of listing the webhooks of app
+type WebhookListResponse struct {
+	RequestId            uint64     `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Webhooks             []*Webhook `protobuf:"bytes,2,rep,name=webhooks,proto3" json:"webhooks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *WebhookListResponse) Reset()         { *m = WebhookListResponse{} }
+func (m *WebhookListResponse) String() string { return proto.CompactTextString(m) }
+func (*WebhookListResponse) ProtoMessage()    {}
+func (*WebhookListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{5}
+}
+
+func (m *WebhookListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookListResponse.Unmarshal(m, b)
+}
+func (m *WebhookListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookListResponse.Marshal(b, m, deterministic)
+}
+func (m *WebhookListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookListResponse.Merge(m, src)
+}
+func (m *WebhookListResponse) XXX_Size() int {
+	return xxx_messageInfo_WebhookListResponse.Size(m)
+}
+func (m *WebhookListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookListResponse proto.InternalMessageInfo
+
+func (m *WebhookListResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *WebhookListResponse) GetWebhooks() []*Webhook {
+	if m != nil {
+		return m.Webhooks
+	}
+	return nil
+}
+
+// WebhookDeleteRequest represent the request of deleting a webhook
+type WebhookDeleteRequest struct {
+	AppUid               string   `protobuf:"bytes,1,opt,name=app_uid,json=appUid,proto3" json:"app_uid,omitempty"`
+	AppSecret            string   `protobuf:"bytes,2,opt,name=app_secret,json=appSecret,proto3" json:"app_secret,omitempty"`
+	WebhookUid           string   `protobuf:"bytes,3,opt,name=webhook_uid,json=webhookUid,proto3" json:"webhook_uid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WebhookDeleteRequest) Reset()         { *m = WebhookDeleteRequest{} }
+func (m *WebhookDeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*WebhookDeleteRequest) ProtoMessage()    {}
+func (*WebhookDeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{6}
+}
+
+func (m *WebhookDeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookDeleteRequest.Unmarshal(m, b)
+}
+func (m *WebhookDeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookDeleteRequest.Marshal(b, m, deterministic)
+}
+func (m *WebhookDeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookDeleteRequest.Merge(m, src)
+}
+func (m *WebhookDeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_WebhookDeleteRequest.Size(m)
+}
+func (m *WebhookDeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookDeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookDeleteRequest proto.InternalMessageInfo
+
+func (m *WebhookDeleteRequest) GetAppUid() string {
+	if m != nil {
+		return m.AppUid
+	}
+	return ""
+}
+
+func (m *WebhookDeleteRequest) GetAppSecret() string {
+	if m != nil {
+		return m.AppSecret
+	}
+	return ""
+}
+
+func (m *WebhookDeleteRequest) GetWebhookUid() string {
+	if m != nil {
+		return m.WebhookUid
+	}
+	return ""
+}
+
+// WebhookDeleteResponse represent the response of deleting a webhook
+type WebhookDeleteResponse struct {
+	RequestId            uint64   `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Webhook              *Webhook `protobuf:"bytes,2,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WebhookDeleteResponse) Reset()         { *m = WebhookDeleteResponse{} }
+func (m *WebhookDeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*WebhookDeleteResponse) ProtoMessage()    {}
+func (*WebhookDeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{7}
+}
+
+func (m *WebhookDeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookDeleteResponse.Unmarshal(m, b)
+}
+func (m *WebhookDeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookDeleteResponse.Marshal(b, m, deterministic)
+}
+func (m *WebhookDeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookDeleteResponse.Merge(m, src)
+}
+func (m *WebhookDeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_WebhookDeleteResponse.Size(m)
+}
+func (m *WebhookDeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookDeleteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookDeleteResponse proto.InternalMessageInfo
+
+func (m *WebhookDeleteResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *WebhookDeleteResponse) GetWebhook() *Webhook {
+	if m != nil {
+		return m.Webhook
+	}
+	return nil
+}
+
+// WebhookDeliveryListRequest represent the request of inspecting the delivery
+// log of webhook, status is one of pending, succeeded and failed
+type WebhookDeliveryListRequest struct {
+	AppUid               string                `protobuf:"bytes,1,opt,name=app_uid,json=appUid,proto3" json:"app_uid,omitempty"`
+	AppSecret            string                `protobuf:"bytes,2,opt,name=app_secret,json=appSecret,proto3" json:"app_secret,omitempty"`
+	WebhookUid           string                `protobuf:"bytes,3,opt,name=webhook_uid,json=webhookUid,proto3" json:"webhook_uid,omitempty"`
+	Status               *wrappers.StringValue `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Offset               uint32                `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit                uint32                `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *WebhookDeliveryListRequest) Reset()         { *m = WebhookDeliveryListRequest{} }
+func (m *WebhookDeliveryListRequest) String() string { return proto.CompactTextString(m) }
+func (*WebhookDeliveryListRequest) ProtoMessage()    {}
+func (*WebhookDeliveryListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{8}
+}
+
+func (m *WebhookDeliveryListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookDeliveryListRequest.Unmarshal(m, b)
+}
+func (m *WebhookDeliveryListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookDeliveryListRequest.Marshal(b, m, deterministic)
+}
+func (m *WebhookDeliveryListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookDeliveryListRequest.Merge(m, src)
+}
+func (m *WebhookDeliveryListRequest) XXX_Size() int {
+	return xxx_messageInfo_WebhookDeliveryListRequest.Size(m)
+}
+func (m *WebhookDeliveryListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookDeliveryListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookDeliveryListRequest proto.InternalMessageInfo
+
+func (m *WebhookDeliveryListRequest) GetAppUid() string {
+	if m != nil {
+		return m.AppUid
+	}
+	return ""
+}
+
+func (m *WebhookDeliveryListRequest) GetAppSecret() string {
+	if m != nil {
+		return m.AppSecret
+	}
+	return ""
+}
+
+func (m *WebhookDeliveryListRequest) GetWebhookUid() string {
+	if m != nil {
+		return m.WebhookUid
+	}
+	return ""
+}
+
+func (m *WebhookDeliveryListRequest) GetStatus() *wrappers.StringValue {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *WebhookDeliveryListRequest) GetOffset() uint32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *WebhookDeliveryListRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// WebhookDeliveryListResponse represent the response of inspecting the
+// delivery log of webhook
+type WebhookDeliveryListResponse struct {
+	RequestId            uint64             `protobuf:"varint,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Total                uint64             `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Pages                uint64             `protobuf:"varint,3,opt,name=pages,proto3" json:"pages,omitempty"`
+	Deliveries           []*WebhookDelivery `protobuf:"bytes,4,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *WebhookDeliveryListResponse) Reset()         { *m = WebhookDeliveryListResponse{} }
+func (m *WebhookDeliveryListResponse) String() string { return proto.CompactTextString(m) }
+func (*WebhookDeliveryListResponse) ProtoMessage()    {}
+func (*WebhookDeliveryListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{9}
+}
+
+func (m *WebhookDeliveryListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookDeliveryListResponse.Unmarshal(m, b)
+}
+func (m *WebhookDeliveryListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookDeliveryListResponse.Marshal(b, m, deterministic)
+}
+func (m *WebhookDeliveryListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookDeliveryListResponse.Merge(m, src)
+}
+func (m *WebhookDeliveryListResponse) XXX_Size() int {
+	return xxx_messageInfo_WebhookDeliveryListResponse.Size(m)
+}
+func (m *WebhookDeliveryListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookDeliveryListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookDeliveryListResponse proto.InternalMessageInfo
+
+func (m *WebhookDeliveryListResponse) GetRequestId() uint64 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *WebhookDeliveryListResponse) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *WebhookDeliveryListResponse) GetPages() uint64 {
+	if m != nil {
+		return m.Pages
+	}
+	return 0
+}
+
+func (m *WebhookDeliveryListResponse) GetDeliveries() []*WebhookDelivery {
+	if m != nil {
+		return m.Deliveries
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Webhook)(nil), "bigfile.webhook.Webhook")
+	proto.RegisterType((*WebhookDelivery)(nil), "bigfile.webhook.WebhookDelivery")
+	proto.RegisterType((*WebhookCreateRequest)(nil), "bigfile.webhook.WebhookCreateRequest")
+	proto.RegisterType((*WebhookCreateResponse)(nil), "bigfile.webhook.WebhookCreateResponse")
+	proto.RegisterType((*WebhookListRequest)(nil), "bigfile.webhook.WebhookListRequest")
+	proto.RegisterType((*WebhookListResponse)(nil), "bigfile.webhook.WebhookListResponse")
+	proto.RegisterType((*WebhookDeleteRequest)(nil), "bigfile.webhook.WebhookDeleteRequest")
+	proto.RegisterType((*WebhookDeleteResponse)(nil), "bigfile.webhook.WebhookDeleteResponse")
+	proto.RegisterType((*WebhookDeliveryListRequest)(nil), "bigfile.webhook.WebhookDeliveryListRequest")
+	proto.RegisterType((*WebhookDeliveryListResponse)(nil), "bigfile.webhook.WebhookDeliveryListResponse")
+}
+
+func init() { proto.RegisterFile("webhook.proto", fileDescriptor_4a0479a603100288) }
+
+var fileDescriptor_4a0479a603100288 = []byte{
+	// 821 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0xcd, 0x8e, 0x1b, 0x45,
+	0x10, 0x66, 0x76, 0xbc, 0xf6, 0xba, 0xbc, 0xd6, 0xa2, 0xce, 0x12, 0x46, 0x13, 0xc8, 0x5a, 0x03,
+	0x44, 0x2b, 0x81, 0x66, 0x25, 0x27, 0x17, 0x84, 0x22, 0xc5, 0x4e, 0x38, 0x44, 0xe4, 0x60, 0x4d,
+	0x12, 0x22, 0xe5, 0x62, 0xda, 0x9e, 0xb2, 0x77, 0xc2, 0x78, 0xba, 0xd3, 0xdd, 0x8e, 0xb3, 0x0f,
+	0xc3, 0x85, 0x63, 0x5e, 0x80, 0x67, 0x41, 0xe2, 0xc0, 0x63, 0x70, 0x44, 0xfd, 0x33, 0xb3, 0xb6,
+	0x77, 0x1d, 0x1b, 0x85, 0x9c, 0xec, 0xaa, 0xa9, 0xaf, 0xfa, 0xab, 0xfa, 0xaa, 0xab, 0xa1, 0xbd,
+	0xc0, 0xd1, 0x39, 0x63, 0xbf, 0xc6, 0x5c, 0x30, 0xc5, 0xc8, 0xd1, 0x28, 0x9b, 0x4e, 0xb2, 0x1c,
+	0x63, 0xe7, 0x0e, 0x6f, 0x4f, 0x19, 0x9b, 0xe6, 0x78, 0x66, 0x3e, 0x8f, 0xe6, 0x93, 0xb3, 0x85,
+	0xa0, 0x9c, 0xa3, 0x90, 0x16, 0x10, 0x9e, 0xac, 0x7f, 0x57, 0xd9, 0x0c, 0xa5, 0xa2, 0x33, 0x6e,
+	0x03, 0xa2, 0xbf, 0x3c, 0x68, 0xbc, 0xb0, 0xc9, 0xc8, 0xa7, 0xe0, 0xcf, 0xb3, 0x34, 0xf0, 0x3a,
+	0xde, 0x69, 0x33, 0xd1, 0x7f, 0x8d, 0x47, 0xe4, 0xc1, 0x9e, 0xf3, 0x88, 0x9c, 0xdc, 0x84, 0x3a,
+	0xbe, 0xc1, 0x42, 0xc9, 0xc0, 0xef, 0xf8, 0xa7, 0xcd, 0xc4, 0x59, 0xe4, 0x04, 0x5a, 0x9c, 0xaa,
+	0xf3, 0x21, 0x17, 0x38, 0xc9, 0xde, 0x06, 0x35, 0x83, 0x00, 0xed, 0x1a, 0x18, 0x0f, 0xf9, 0x1e,
+	0x60, 0x2c, 0x90, 0x2a, 0x4c, 0x87, 0x54, 0x05, 0xfb, 0x1d, 0xef, 0xb4, 0xd5, 0x0d, 0x63, 0x4b,
+	0x2f, 0x2e, 0xe9, 0xc5, 0xcf, 0x4a, 0x7a, 0x49, 0xd3, 0x45, 0xf7, 0x94, 0x86, 0xa6, 0x98, 0xa3,
+	0x83, 0xd6, 0xb7, 0x43, 0x5d, 0x74, 0x4f, 0x45, 0x7f, 0xfb, 0x70, 0xe4, 0xca, 0x7b, 0x84, 0x79,
+	0xf6, 0x06, 0xc5, 0xc5, 0x35, 0x65, 0x1e, 0xc3, 0xbe, 0x29, 0xc3, 0x15, 0x6a, 0x0d, 0x12, 0xc2,
+	0x81, 0xc4, 0xd7, 0x73, 0x2c, 0xc6, 0x18, 0xf8, 0x1d, 0xef, 0xb4, 0x96, 0x54, 0xb6, 0x6e, 0x83,
+	0x54, 0x54, 0xcd, 0xa5, 0xab, 0xd4, 0x59, 0x1a, 0x43, 0x95, 0xc2, 0x19, 0x57, 0xd2, 0xd4, 0xd8,
+	0x4e, 0x2a, 0x9b, 0xf4, 0xe1, 0xa8, 0xc0, 0xb7, 0x6a, 0xe8, 0x1c, 0xbb, 0xd5, 0xd2, 0xd6, 0x90,
+	0x9e, 0x45, 0xf4, 0x14, 0x79, 0x00, 0x6d, 0x81, 0x92, 0xb3, 0x42, 0xe2, 0x70, 0xcc, 0x52, 0x0c,
+	0x1a, 0x26, 0xc3, 0xad, 0x2b, 0x19, 0x1e, 0x17, 0xea, 0x6e, 0xf7, 0x67, 0x9a, 0xcf, 0x31, 0x39,
+	0x2c, 0x11, 0x0f, 0x59, 0x8a, 0xe4, 0x07, 0x80, 0x9c, 0x4a, 0x35, 0x44, 0x21, 0x98, 0x08, 0x0e,
+	0x0c, 0xfc, 0x8b, 0x2b, 0xf0, 0xa7, 0x4a, 0x64, 0xc5, 0xd4, 0xe2, 0x9b, 0x3a, 0xfe, 0x47, 0x1d,
+	0x4e, 0xee, 0xc3, 0x61, 0x6a, 0xdb, 0x68, 0xb5, 0x68, 0x6e, 0xe5, 0xdf, 0xaa, 0xe2, 0xad, 0x90,
+	0x4b, 0x33, 0x00, 0xff, 0x65, 0x06, 0x02, 0x68, 0x70, 0x7a, 0x91, 0x33, 0x9a, 0x06, 0x2d, 0xd3,
+	0xf1, 0xd2, 0x8c, 0xfe, 0xf0, 0xe0, 0xd8, 0x49, 0xfc, 0xd0, 0x84, 0x27, 0x5a, 0x24, 0xa9, 0xc8,
+	0xe7, 0xd0, 0xa0, 0x9c, 0x0f, 0x2f, 0xb5, 0xae, 0x53, 0xce, 0x9f, 0x67, 0x29, 0xf9, 0x12, 0x40,
+	0x7f, 0x90, 0x38, 0x16, 0x58, 0x6a, 0xde, 0xa4, 0x9c, 0x3f, 0x35, 0x8e, 0x72, 0xe8, 0xfd, 0xeb,
+	0x86, 0xbe, 0xb6, 0x32, 0xf4, 0xf7, 0x57, 0x87, 0x7e, 0x7f, 0x87, 0x66, 0x2e, 0x5d, 0x89, 0xe8,
+	0x15, 0x7c, 0xb6, 0x46, 0xdc, 0xea, 0xa4, 0x09, 0x0a, 0x5b, 0xc4, 0xd0, 0x91, 0xaf, 0x25, 0x4d,
+	0xe7, 0x79, 0x9c, 0x92, 0x2e, 0x34, 0xdc, 0xfd, 0x37, 0xe4, 0x5b, 0xdd, 0x20, 0x5e, 0xdb, 0x0b,
+	0xb1, 0xcb, 0x9b, 0x94, 0x81, 0xd1, 0x13, 0x20, 0xce, 0xf7, 0x24, 0x93, 0xea, 0x03, 0x5b, 0x14,
+	0xbd, 0x82, 0x1b, 0x2b, 0xd9, 0x76, 0xe3, 0x7d, 0x0f, 0x0e, 0x1c, 0x1d, 0x19, 0xec, 0x75, 0xfc,
+	0xf7, 0x12, 0xaf, 0x22, 0x23, 0x56, 0xc9, 0xfb, 0xc8, 0x5c, 0xeb, 0x0f, 0x95, 0xf7, 0x04, 0x5a,
+	0x2e, 0xb7, 0xc1, 0x5a, 0x99, 0xc1, 0xb9, 0x9e, 0x67, 0xe9, 0x92, 0x2c, 0xe5, 0x81, 0x1f, 0x4f,
+	0x96, 0x3f, 0x3d, 0x08, 0xd7, 0xf6, 0xd3, 0xff, 0xa0, 0xcf, 0xd6, 0x1a, 0xc9, 0xbd, 0x95, 0xfd,
+	0xb5, 0x6d, 0x68, 0xcb, 0xed, 0x76, 0x13, 0xea, 0x6c, 0x32, 0x91, 0xa8, 0xdc, 0x6e, 0x73, 0x96,
+	0xde, 0x9f, 0x79, 0x36, 0xcb, 0xec, 0x3e, 0x6b, 0x27, 0xd6, 0x88, 0xde, 0x79, 0x70, 0xeb, 0xda,
+	0xda, 0x76, 0x6b, 0xe7, 0x31, 0xec, 0x2b, 0xa6, 0xa8, 0x7d, 0x7d, 0x6a, 0x89, 0x35, 0xb4, 0x97,
+	0xd3, 0x29, 0x4a, 0xb7, 0x91, 0xad, 0x41, 0x1e, 0x98, 0x17, 0x42, 0x1f, 0x91, 0xa1, 0xbd, 0xa4,
+	0xad, 0x6e, 0x67, 0x53, 0xf7, 0x4b, 0x32, 0xc9, 0x12, 0xa6, 0xfb, 0x9b, 0x7f, 0xf9, 0x0e, 0xfe,
+	0x52, 0x3d, 0xbb, 0xf6, 0x5e, 0x92, 0x6f, 0x36, 0xa5, 0x5a, 0x59, 0x38, 0xe1, 0x9d, 0x6d, 0x61,
+	0xb6, 0xf0, 0xe8, 0x13, 0xf2, 0xb2, 0xd2, 0x47, 0x77, 0x84, 0x7c, 0xb5, 0x09, 0xb8, 0x34, 0x0b,
+	0xe1, 0xd7, 0xef, 0x0f, 0xaa, 0x72, 0x5f, 0xb2, 0xb7, 0xe3, 0xbb, 0x99, 0xfd, 0xca, 0x7d, 0x0a,
+	0xef, 0x6c, 0x0b, 0xab, 0x4e, 0x10, 0x70, 0x63, 0x71, 0x55, 0x57, 0xf2, 0xed, 0xb6, 0x86, 0x2f,
+	0x57, 0xf3, 0xdd, 0x6e, 0xc1, 0xe5, 0x99, 0xfd, 0xd7, 0x70, 0x3c, 0x66, 0xb3, 0x0a, 0x54, 0x8e,
+	0x69, 0xff, 0xd0, 0xc1, 0x06, 0xda, 0x31, 0xf0, 0x5e, 0xde, 0x9e, 0x66, 0xea, 0x7c, 0x3e, 0x8a,
+	0xc7, 0x6c, 0x76, 0xe6, 0x82, 0xab, 0x5f, 0xc1, 0xc7, 0xff, 0x78, 0xde, 0xef, 0x7b, 0x7e, 0x7f,
+	0x90, 0xbc, 0xdb, 0x3b, 0xe9, 0xbb, 0x5c, 0x83, 0x72, 0xe4, 0x5f, 0x60, 0x9e, 0xff, 0x54, 0xb0,
+	0x45, 0xf1, 0xec, 0x82, 0xa3, 0x1c, 0xd5, 0xcd, 0x21, 0x77, 0xff, 0x1d, 0x00, 0x62, 0xaa, 0x71,
+	0x80, 0x84, 0x09, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// WebhookClient is the client API for Webhook service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type WebhookClient interface {
+	WebhookCreate(ctx context.Context, in *WebhookCreateRequest, opts ...grpc.CallOption) (*WebhookCreateResponse, error)
+	WebhookList(ctx context.Context, in *WebhookListRequest, opts ...grpc.CallOption) (*WebhookListResponse, error)
+	WebhookDelete(ctx context.Context, in *WebhookDeleteRequest, opts ...grpc.CallOption) (*WebhookDeleteResponse, error)
+	WebhookDeliveryList(ctx context.Context, in *WebhookDeliveryListRequest, opts ...grpc.CallOption) (*WebhookDeliveryListResponse, error)
+}
+
+type webhookClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewWebhookClient(cc *grpc.ClientConn) WebhookClient {
+	return &webhookClient{cc}
+}
+
+func (c *webhookClient) WebhookCreate(ctx context.Context, in *WebhookCreateRequest, opts ...grpc.CallOption) (*WebhookCreateResponse, error) {
+	out := new(WebhookCreateResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.webhook.Webhook/webhookCreate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookClient) WebhookList(ctx context.Context, in *WebhookListRequest, opts ...grpc.CallOption) (*WebhookListResponse, error) {
+	out := new(WebhookListResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.webhook.Webhook/webhookList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookClient) WebhookDelete(ctx context.Context, in *WebhookDeleteRequest, opts ...grpc.CallOption) (*WebhookDeleteResponse, error) {
+	out := new(WebhookDeleteResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.webhook.Webhook/webhookDelete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookClient) WebhookDeliveryList(ctx context.Context, in *WebhookDeliveryListRequest, opts ...grpc.CallOption) (*WebhookDeliveryListResponse, error) {
+	out := new(WebhookDeliveryListResponse)
+	err := c.cc.Invoke(ctx, "/bigfile.webhook.Webhook/webhookDeliveryList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WebhookServer is the server API for Webhook service.
+type WebhookServer interface {
+	WebhookCreate(context.Context, *WebhookCreateRequest) (*WebhookCreateResponse, error)
+	WebhookList(context.Context, *WebhookListRequest) (*WebhookListResponse, error)
+	WebhookDelete(context.Context, *WebhookDeleteRequest) (*WebhookDeleteResponse, error)
+	WebhookDeliveryList(context.Context, *WebhookDeliveryListRequest) (*WebhookDeliveryListResponse, error)
+}
+
+// UnimplementedWebhookServer can be embedded to have forward compatible implementations.
+type UnimplementedWebhookServer struct {
+}
+
+func (*UnimplementedWebhookServer) WebhookCreate(ctx context.Context, req *WebhookCreateRequest) (*WebhookCreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WebhookCreate not implemented")
+}
+func (*UnimplementedWebhookServer) WebhookList(ctx context.Context, req *WebhookListRequest) (*WebhookListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WebhookList not implemented")
+}
+func (*UnimplementedWebhookServer) WebhookDelete(ctx context.Context, req *WebhookDeleteRequest) (*WebhookDeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WebhookDelete not implemented")
+}
+func (*UnimplementedWebhookServer) WebhookDeliveryList(ctx context.Context, req *WebhookDeliveryListRequest) (*WebhookDeliveryListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WebhookDeliveryList not implemented")
+}
+
+func RegisterWebhookServer(s *grpc.Server, srv WebhookServer) {
+	s.RegisterService(&_Webhook_serviceDesc, srv)
+}
+
+func _Webhook_WebhookCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServer).WebhookCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.webhook.Webhook/WebhookCreate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServer).WebhookCreate(ctx, req.(*WebhookCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Webhook_WebhookList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServer).WebhookList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.webhook.Webhook/WebhookList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServer).WebhookList(ctx, req.(*WebhookListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Webhook_WebhookDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServer).WebhookDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.webhook.Webhook/WebhookDelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServer).WebhookDelete(ctx, req.(*WebhookDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Webhook_WebhookDeliveryList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookDeliveryListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServer).WebhookDeliveryList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bigfile.webhook.Webhook/WebhookDeliveryList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServer).WebhookDeliveryList(ctx, req.(*WebhookDeliveryListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Webhook_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bigfile.webhook.Webhook",
+	HandlerType: (*WebhookServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "webhookCreate",
+			Handler:    _Webhook_WebhookCreate_Handler,
+		},
+		{
+			MethodName: "webhookList",
+			Handler:    _Webhook_WebhookList_Handler,
+		},
+		{
+			MethodName: "webhookDelete",
+			Handler:    _Webhook_WebhookDelete_Handler,
+		},
+		{
+			MethodName: "webhookDeliveryList",
+			Handler:    _Webhook_WebhookDeliveryList_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "webhook.proto",
+}
@@ -0,0 +1,139 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package s3
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/service"
+	"github.com/jinzhu/gorm"
+)
+
+// Error represent an error of s3 api, it's responded as the xml document of
+// s3, so that the s3 clients are able to understand it
+type Error struct {
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+// Error implement the error interface
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// withMessage return a copy of e with another message
+func (e *Error) withMessage(message string) *Error {
+	return &Error{Code: e.Code, Message: message, StatusCode: e.StatusCode}
+}
+
+var (
+	// ErrAccessDenied represent that the request isn't allowed
+	ErrAccessDenied = &Error{"AccessDenied", "Access Denied.", http.StatusForbidden}
+	// ErrAuthorizationHeaderMalformed represent that the authorization header can't be parsed
+	ErrAuthorizationHeaderMalformed = &Error{
+		"AuthorizationHeaderMalformed", "The authorization header is malformed.", http.StatusBadRequest}
+	// ErrAuthorizationQueryParametersError represent that the presigned query can't be parsed
+	ErrAuthorizationQueryParametersError = &Error{
+		"AuthorizationQueryParametersError", "The authorization query parameters are malformed.", http.StatusBadRequest}
+	// ErrBadDigest represent that the content doesn't match Content-MD5
+	ErrBadDigest = &Error{
+		"BadDigest", "The Content-MD5 you specified did not match what we received.", http.StatusBadRequest}
+	// ErrBucketAlreadyOwnedByYou represent that try to create an existing bucket
+	ErrBucketAlreadyOwnedByYou = &Error{
+		"BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it.",
+		http.StatusConflict}
+	// ErrContentSHA256Mismatch represent that the content doesn't match x-amz-content-sha256
+	ErrContentSHA256Mismatch = &Error{
+		"XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.",
+		http.StatusBadRequest}
+	// ErrEntityTooSmall represent that a part except the last one is smaller than the minimum size
+	ErrEntityTooSmall = &Error{
+		"EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size.", http.StatusBadRequest}
+	// ErrExpiredPresignRequest represent that the presigned url has expired
+	ErrExpiredPresignRequest = &Error{"AccessDenied", "Request has expired.", http.StatusForbidden}
+	// ErrIncompleteBody represent that the body is shorter than declared
+	ErrIncompleteBody = &Error{
+		"IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.",
+		http.StatusBadRequest}
+	// ErrInternalError represent an unexpected error
+	ErrInternalError = &Error{"InternalError", "We encountered an internal error. Please try again.",
+		http.StatusInternalServerError}
+	// ErrInvalidAccessKeyID represent that neither an app nor a token matches the access key
+	ErrInvalidAccessKeyID = &Error{
+		"InvalidAccessKeyId", "The access key Id you provided does not exist in our records.", http.StatusForbidden}
+	// ErrInvalidArgument represent that some argument is invalid
+	ErrInvalidArgument = &Error{"InvalidArgument", "Invalid Argument.", http.StatusBadRequest}
+	// ErrInvalidDigest represent that Content-MD5 isn't valid
+	ErrInvalidDigest = &Error{"InvalidDigest", "The Content-MD5 you specified is not valid.", http.StatusBadRequest}
+	// ErrInvalidPart represent that some part of the completed upload can't be found
+	ErrInvalidPart = &Error{
+		"InvalidPart", "One or more of the specified parts could not be found.", http.StatusBadRequest}
+	// ErrInvalidPartOrder represent that the parts of the completed upload aren't in ascending order
+	ErrInvalidPartOrder = &Error{
+		"InvalidPartOrder", "The list of parts was not in ascending order.", http.StatusBadRequest}
+	// ErrInvalidRequest represent that the request can't be handled
+	ErrInvalidRequest = &Error{"InvalidRequest", "Invalid Request.", http.StatusBadRequest}
+	// ErrMalformedXML represent that the xml of request body can't be parsed
+	ErrMalformedXML = &Error{
+		"MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema.",
+		http.StatusBadRequest}
+	// ErrMethodNotAllowed represent that the method isn't allowed against the resource
+	ErrMethodNotAllowed = &Error{
+		"MethodNotAllowed", "The specified method is not allowed against this resource.", http.StatusMethodNotAllowed}
+	// ErrMissingSecurityHeader represent that a required header is missing
+	ErrMissingSecurityHeader = &Error{
+		"MissingSecurityHeader", "Your request was missing a required header.", http.StatusBadRequest}
+	// ErrNoSuchBucket represent that the bucket doesn't exist
+	ErrNoSuchBucket = &Error{"NoSuchBucket", "The specified bucket does not exist.", http.StatusNotFound}
+	// ErrNoSuchKey represent that the key doesn't exist
+	ErrNoSuchKey = &Error{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	// ErrNoSuchUpload represent that the multipart upload doesn't exist
+	ErrNoSuchUpload = &Error{"NoSuchUpload", "The specified multipart upload does not exist.", http.StatusNotFound}
+	// ErrNotImplemented represent that the operation isn't supported by gateway
+	ErrNotImplemented = &Error{
+		"NotImplemented", "A header you provided implies functionality that is not implemented.",
+		http.StatusNotImplemented}
+	// ErrRequestTimeTooSkewed represent that the time of request is too far from the server time
+	ErrRequestTimeTooSkewed = &Error{
+		"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.",
+		http.StatusForbidden}
+	// ErrSignatureDoesNotMatch represent that the signature is wrong
+	ErrSignatureDoesNotMatch = &Error{
+		"SignatureDoesNotMatch",
+		"The request signature we calculated does not match the signature you provided.", http.StatusForbidden}
+)
+
+// errorResponse is the xml document of error
+type errorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource"`
+	RequestID string   `xml:"RequestId"`
+}
+
+// toError is used to translate the error of models and service to the error of
+// s3, unknown errors are regarded as internal errors
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	switch err {
+	case gorm.ErrRecordNotFound:
+		return ErrNoSuchKey
+	case models.ErrFileLocked, models.ErrFileUnderRetention, models.ErrFileUnderLegalHold,
+		service.ErrTokenReadOnly, service.ErrTokenIP, service.ErrTokenExpired,
+		service.ErrTokenAvailableTimesExhausted:
+		return ErrAccessDenied.withMessage(err.Error())
+	case models.ErrFileExisted, models.ErrOverwriteDir, models.ErrReadDir, models.ErrDeleteNonEmptyDir:
+		return ErrInvalidRequest.withMessage(err.Error())
+	case models.ErrFileExpired:
+		return ErrNoSuchKey
+	}
+	return ErrInternalError
+}
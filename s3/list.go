@@ -0,0 +1,199 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package s3
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases/models"
+)
+
+const (
+	// maxListKeys is the max number of keys in one list response
+	maxListKeys = 1000
+	// listBatchSize is the number of files that are read by one query
+	listBatchSize = 1000
+	// maxPathCharacter is greater than all characters that can be stored in
+	// the path of file, it's used to skip all keys under a common prefix
+	maxPathCharacter = "\uffff"
+)
+
+// listing is the result of listing objects
+type listing struct {
+	objects   []models.File
+	prefixes  []string
+	truncated bool
+	// next is the last key or common prefix of result, the next page starts after it
+	next string
+}
+
+// list is used to list the objects whose key starts with prefix and is greater
+// than marker. If delimiter isn't empty, the keys that contain delimiter after
+// prefix are rolled up into common prefixes. At most maxKeys objects and common
+// prefixes are returned.
+func (s *Server) list(b *bucket, prefix, delimiter, marker string, maxKeys int) (*listing, error) {
+	var (
+		skip   string
+		after  string
+		result = &listing{}
+		base   = b.base()
+	)
+
+	if maxKeys == 0 {
+		return result, nil
+	}
+
+	if marker != "" {
+		after = base + marker
+		// the marker of previous page is a common prefix, all keys under it are skipped
+		if delimiter != "" && strings.HasPrefix(marker, prefix) && strings.HasSuffix(marker, delimiter) {
+			skip = marker
+		}
+	}
+
+	for {
+		if skip != "" && strings.HasPrefix(b.key(after), skip) {
+			after = base + skip + maxPathCharacter
+		}
+		files, err := models.FindFilesByPathPrefix(b.app, base+prefix, after, listBatchSize, s.db)
+		if err != nil {
+			return nil, err
+		}
+		for index := range files {
+			var (
+				key      = b.key(files[index].FullPath)
+				entry    = key
+				isPrefix = false
+			)
+			after = files[index].FullPath
+			if skip != "" && strings.HasPrefix(key, skip) {
+				continue
+			}
+			if delimiter != "" {
+				if pos := strings.Index(key[len(prefix):], delimiter); pos >= 0 {
+					entry, isPrefix = key[:len(prefix)+pos+len(delimiter)], true
+				}
+			}
+			if len(result.objects)+len(result.prefixes) == maxKeys {
+				result.truncated = true
+				return result, nil
+			}
+			if isPrefix {
+				result.prefixes = append(result.prefixes, entry)
+				skip = entry
+			} else {
+				result.objects = append(result.objects, files[index])
+			}
+			result.next = entry
+		}
+		if len(files) < listBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// listObjects is used to handle ListObjects and ListObjectsV2
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, b *bucket) error {
+	var (
+		err       error
+		maxKeys   = maxListKeys
+		query     = r.URL.Query()
+		prefix    = query.Get("prefix")
+		delimiter = query.Get("delimiter")
+		encoding  = query.Get("encoding-type")
+		marker    = query.Get("marker")
+		v2        = query.Get("list-type") == "2"
+		result    *listing
+	)
+
+	if value := query.Get("max-keys"); value != "" {
+		if maxKeys, err = strconv.Atoi(value); err != nil || maxKeys < 0 {
+			return ErrInvalidArgument.withMessage("max-keys is invalid.")
+		}
+		if maxKeys > maxListKeys {
+			maxKeys = maxListKeys
+		}
+	}
+	if encoding != "" && encoding != "url" {
+		return ErrInvalidArgument.withMessage("encoding-type is invalid.")
+	}
+
+	if v2 {
+		marker = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			decoded, err := base64.StdEncoding.DecodeString(token)
+			if err != nil {
+				return ErrInvalidArgument.withMessage("continuation-token is invalid.")
+			}
+			marker = string(decoded)
+		}
+	}
+
+	if result, err = s.list(b, prefix, delimiter, marker, maxKeys); err != nil {
+		return err
+	}
+
+	encode := func(s string) string {
+		if encoding == "url" {
+			return uriEncode(s, true)
+		}
+		return s
+	}
+	contents := make([]objectEntry, 0, len(result.objects))
+	for _, file := range result.objects {
+		contents = append(contents, objectEntry{
+			Key:          encode(b.key(file.FullPath)),
+			LastModified: formatTimestamp(file.UpdatedAt),
+			ETag:         etag(file.Object.Hash),
+			Size:         file.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+	prefixes := make([]commonPrefix, 0, len(result.prefixes))
+	for _, p := range result.prefixes {
+		prefixes = append(prefixes, commonPrefix{Prefix: encode(p)})
+	}
+
+	if v2 {
+		response := &listBucketResultV2{
+			Xmlns:             xmlNamespace,
+			Name:              b.name,
+			Prefix:            encode(prefix),
+			StartAfter:        encode(query.Get("start-after")),
+			ContinuationToken: query.Get("continuation-token"),
+			KeyCount:          len(contents) + len(prefixes),
+			MaxKeys:           maxKeys,
+			Delimiter:         encode(delimiter),
+			IsTruncated:       result.truncated,
+			EncodingType:      encoding,
+			Contents:          contents,
+			CommonPrefixes:    prefixes,
+		}
+		if result.truncated {
+			response.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(result.next))
+		}
+		return writeXML(w, http.StatusOK, response)
+	}
+
+	response := &listBucketResult{
+		Xmlns:          xmlNamespace,
+		Name:           b.name,
+		Prefix:         encode(prefix),
+		Marker:         encode(marker),
+		MaxKeys:        maxKeys,
+		Delimiter:      encode(delimiter),
+		IsTruncated:    result.truncated,
+		EncodingType:   encoding,
+		Contents:       contents,
+		CommonPrefixes: prefixes,
+	}
+	if result.truncated && delimiter != "" {
+		response.NextMarker = encode(result.next)
+	}
+	return writeXML(w, http.StatusOK, response)
+}
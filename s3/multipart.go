@@ -0,0 +1,263 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package s3
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+)
+
+// maxPartNumber is the max number of part of multipart upload
+const maxPartNumber = 10000
+
+// minPartSize is the min size of all parts except the last one
+var minPartSize = 5 << 20
+
+// partsReader read the objects of parts one by one, the reader of part is opened
+// only when the previous one has been read up
+type partsReader struct {
+	db       *gorm.DB
+	rootPath *string
+	parts    []models.MultipartPart
+	current  io.Reader
+}
+
+// Read implement io.Reader
+func (p *partsReader) Read(b []byte) (n int, err error) {
+	for {
+		if p.current == nil {
+			if len(p.parts) == 0 {
+				return 0, io.EOF
+			}
+			if p.current, err = p.parts[0].Object.Reader(p.rootPath, p.db); err != nil {
+				return 0, err
+			}
+			p.parts = p.parts[1:]
+		}
+		if n, err = p.current.Read(b); err == io.EOF {
+			p.current, err = nil, nil
+			if n == 0 {
+				continue
+			}
+		}
+		return n, err
+	}
+}
+
+// findUpload is used to find the multipart upload of request, it must be
+// started for the same path
+func (s *Server) findUpload(r *http.Request, b *bucket, key string) (*models.MultipartUpload, error) {
+	upload, err := models.FindMultipartUploadByUID(r.URL.Query().Get("uploadId"), s.db)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrNoSuchUpload
+		}
+		return nil, err
+	}
+	if upload.AppID != b.app.ID || upload.Path != b.path(key) {
+		return nil, ErrNoSuchUpload
+	}
+	return upload, nil
+}
+
+// createMultipartUpload is used to start a multipart upload
+func (s *Server) createMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, key string, p *principal) error {
+	if strings.HasSuffix(key, "/") {
+		return ErrInvalidArgument.withMessage("The key of multipart upload can't end with '/'.")
+	}
+	if err := models.CheckPathLock(b.app, b.path(key), p.owner(), s.db); err != nil {
+		return err
+	}
+	upload, err := models.NewMultipartUpload(b.app, b.path(key), p.owner(), s.db)
+	if err != nil {
+		return err
+	}
+	return writeXML(w, http.StatusOK, &initiateMultipartUploadResult{
+		Xmlns:    xmlNamespace,
+		Bucket:   b.name,
+		Key:      key,
+		UploadID: upload.UID,
+	})
+}
+
+// uploadPart is used to upload a part of multipart upload
+func (s *Server) uploadPart(
+	w http.ResponseWriter, r *http.Request, b *bucket, key string, sig *signature, secret string,
+) error {
+	var (
+		err    error
+		number int
+		reader io.Reader
+		upload *models.MultipartUpload
+		part   *models.MultipartPart
+	)
+
+	if r.Header.Get("X-Amz-Copy-Source") != "" {
+		return ErrNotImplemented.withMessage("UploadPartCopy is not supported.")
+	}
+	if number, err = strconv.Atoi(r.URL.Query().Get("partNumber")); err != nil || number < 1 || number > maxPartNumber {
+		return ErrInvalidArgument.withMessage("Part number must be an integer between 1 and 10000, inclusive.")
+	}
+	if upload, err = s.findUpload(r, b, key); err != nil {
+		return err
+	}
+	if reader, err = payloadReader(r, sig, secret); err != nil {
+		return err
+	}
+
+	if err = s.transaction(r.Context(), b.app, func(db *gorm.DB) (err error) {
+		part, err = upload.SavePart(number, reader, s.rootPath, db)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", etag(part.Object.Hash))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// completeMultipartUpload is used to concatenate the parts into the file, the
+// parts must be listed in ascending order, and their entity tags must match
+func (s *Server) completeMultipartUpload(
+	w http.ResponseWriter, r *http.Request, b *bucket, key string, p *principal, sig *signature, secret string,
+) error {
+	var (
+		err      error
+		body     []byte
+		reader   io.Reader
+		file     *models.File
+		upload   *models.MultipartUpload
+		uploaded []models.MultipartPart
+		parts    []models.MultipartPart
+		request  = &completeMultipartUploadRequest{}
+		numbers  = make(map[int]*models.MultipartPart)
+	)
+
+	if upload, err = s.findUpload(r, b, key); err != nil {
+		return err
+	}
+	if reader, err = payloadReader(r, sig, secret); err != nil {
+		return err
+	}
+	if body, err = ioutil.ReadAll(io.LimitReader(reader, 2<<20)); err != nil {
+		return err
+	}
+	if err = xml.Unmarshal(body, request); err != nil || len(request.Parts) == 0 {
+		return ErrMalformedXML
+	}
+	if uploaded, err = upload.Parts(0, -1, s.db); err != nil {
+		return err
+	}
+	for index := range uploaded {
+		numbers[uploaded[index].Number] = &uploaded[index]
+	}
+
+	for index, requested := range request.Parts {
+		if index > 0 && requested.PartNumber <= request.Parts[index-1].PartNumber {
+			return ErrInvalidPartOrder
+		}
+		part, ok := numbers[requested.PartNumber]
+		if !ok || strings.Trim(requested.ETag, `"`) != part.Object.Hash {
+			return ErrInvalidPart
+		}
+		if index < len(request.Parts)-1 && part.Object.Size < minPartSize {
+			return ErrEntityTooSmall
+		}
+		parts = append(parts, *part)
+	}
+
+	if err = s.transaction(r.Context(), b.app, func(db *gorm.DB) (err error) {
+		content := &partsReader{db: db, rootPath: s.rootPath, parts: parts}
+		if file, err = s.writeObject(b, upload.Path, p.owner(), content, db); err != nil {
+			return err
+		}
+		return upload.Delete(db)
+	}); err != nil {
+		return err
+	}
+
+	return writeXML(w, http.StatusOK, &completeMultipartUploadResult{
+		Xmlns:    xmlNamespace,
+		Location: r.URL.Path,
+		Bucket:   b.name,
+		Key:      key,
+		ETag:     etag(file.Object.Hash),
+	})
+}
+
+// abortMultipartUpload is used to give up a multipart upload
+func (s *Server) abortMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, key string) error {
+	upload, err := s.findUpload(r, b, key)
+	if err != nil {
+		return err
+	}
+	if err = upload.Delete(s.db); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// listParts is used to list the uploaded parts of multipart upload
+func (s *Server) listParts(w http.ResponseWriter, r *http.Request, b *bucket, key string) error {
+	var (
+		err      error
+		marker   int
+		maxParts = 1000
+		parts    []models.MultipartPart
+		upload   *models.MultipartUpload
+		query    = r.URL.Query()
+	)
+
+	if value := query.Get("part-number-marker"); value != "" {
+		if marker, err = strconv.Atoi(value); err != nil || marker < 0 {
+			return ErrInvalidArgument.withMessage("part-number-marker is invalid.")
+		}
+	}
+	if value := query.Get("max-parts"); value != "" {
+		if maxParts, err = strconv.Atoi(value); err != nil || maxParts < 0 {
+			return ErrInvalidArgument.withMessage("max-parts is invalid.")
+		}
+		if maxParts > 1000 {
+			maxParts = 1000
+		}
+	}
+	if upload, err = s.findUpload(r, b, key); err != nil {
+		return err
+	}
+	if parts, err = upload.Parts(marker, maxParts+1, s.db); err != nil {
+		return err
+	}
+
+	result := &listPartsResult{
+		Xmlns:            xmlNamespace,
+		Bucket:           b.name,
+		Key:              key,
+		UploadID:         upload.UID,
+		PartNumberMarker: marker,
+		MaxParts:         maxParts,
+	}
+	if len(parts) > maxParts {
+		parts, result.IsTruncated = parts[:maxParts], true
+	}
+	for _, part := range parts {
+		result.Parts = append(result.Parts, partEntry{
+			PartNumber:   part.Number,
+			LastModified: formatTimestamp(part.UpdatedAt),
+			ETag:         etag(part.Object.Hash),
+			Size:         part.Object.Size,
+		})
+		result.NextPartNumberMarker = part.Number
+	}
+	return writeXML(w, http.StatusOK, result)
+}
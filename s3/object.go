@@ -0,0 +1,332 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+)
+
+// maxDeleteObjects is the max number of keys in one DeleteObjects request
+const maxDeleteObjects = 1000
+
+// responseHeaders are the query parameters that override the headers of
+// GetObject response, they are usually used by presigned urls
+var responseHeaders = map[string]string{
+	"response-cache-control":       "Cache-Control",
+	"response-content-disposition": "Content-Disposition",
+	"response-content-encoding":    "Content-Encoding",
+	"response-content-language":    "Content-Language",
+	"response-content-type":        "Content-Type",
+	"response-expires":             "Expires",
+}
+
+// etag return the entity tag of object, it's the quoted sha256 hash of content
+func etag(hash string) string {
+	return `"` + hash + `"`
+}
+
+// contentType return the mime type of file by its extension
+func contentType(file *models.File) string {
+	if file.Ext != "" {
+		if mimeType := mime.TypeByExtension("." + file.Ext); mimeType != "" {
+			return mimeType
+		}
+	}
+	return "application/octet-stream"
+}
+
+// findObject is used to find the file of key, the object of file is loaded. A
+// key ending with '/' represent a directory. Hidden and expired files can't be
+// found by s3.
+func (s *Server) findObject(b *bucket, key string) (*models.File, error) {
+	var (
+		err     error
+		expired bool
+		file    *models.File
+	)
+	if file, err = models.FindFileByPath(b.app, b.path(key), s.db); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrNoSuchKey
+		}
+		return nil, err
+	}
+	if (file.IsDir == models.IsDir) != strings.HasSuffix(key, "/") || file.Hidden == models.Hidden {
+		return nil, ErrNoSuchKey
+	}
+	if expired, err = file.IsExpired(s.db); err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, ErrNoSuchKey
+	}
+	if file.IsDir == 0 {
+		if err = s.db.Where("id = ?", file.ObjectID).First(&file.Object).Error; err != nil {
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// writeObject is used to save the content of reader to the file at fullPath. An
+// existing file is overwritten, and its previous content is kept in histories.
+// A deleted file is restored before it's overwritten.
+func (s *Server) writeObject(b *bucket, fullPath, owner string, reader io.Reader, db *gorm.DB) (*models.File, error) {
+	var (
+		err  error
+		file *models.File
+	)
+
+	if err = models.CheckPathLock(b.app, fullPath, owner, db); err != nil {
+		return nil, err
+	}
+
+	if file, err = models.FindFileByPathWithTrashed(b.app, fullPath, db); err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return nil, err
+		}
+		return models.CreateFileFromReader(b.app, fullPath, reader, 0, s.rootPath, db)
+	}
+
+	if file.DeletedAt != nil {
+		if err = file.Restore(db); err != nil {
+			return nil, err
+		}
+	}
+
+	if file.ExpiredAt != nil && !file.ExpiredAt.After(s.now()) {
+		if err = file.SetExpiredAt(nil, db); err != nil {
+			return nil, err
+		}
+	}
+
+	return file, file.OverWriteFromReader(reader, file.Hidden, s.rootPath, db)
+}
+
+// putObject is used to upload an object, a key ending with '/' creates a directory
+func (s *Server) putObject(
+	w http.ResponseWriter, r *http.Request, b *bucket, key string, p *principal, sig *signature, secret string,
+) error {
+	var (
+		err    error
+		file   *models.File
+		reader io.Reader
+	)
+
+	if reader, err = payloadReader(r, sig, secret); err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(key, "/") {
+		var size int64
+		if size, err = io.Copy(ioutil.Discard, reader); err != nil {
+			return err
+		}
+		if size > 0 {
+			return ErrInvalidRequest.withMessage("The directory can't have content.")
+		}
+		if err = s.transaction(r.Context(), b.app, func(db *gorm.DB) error {
+			if err := models.CheckPathLock(b.app, b.path(key), p.owner(), db); err != nil {
+				return err
+			}
+			_, err := models.CreateOrGetLastDirectory(b.app, b.path(key), db)
+			return err
+		}); err != nil {
+			return err
+		}
+		w.Header().Set("ETag", etag(emptySHA256))
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+
+	if err = s.transaction(r.Context(), b.app, func(db *gorm.DB) (err error) {
+		file, err = s.writeObject(b, b.path(key), p.owner(), reader, db)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", etag(file.Object.Hash))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// getObject is used to download an object or read its metadata, range and
+// conditional requests are supported
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) error {
+	var (
+		err     error
+		file    *models.File
+		hash    = emptySHA256
+		content io.ReadSeeker
+	)
+
+	if file, err = s.findObject(b, key); err != nil {
+		return err
+	}
+
+	if file.IsDir == models.IsDir {
+		content = bytes.NewReader(nil)
+	} else {
+		hash = file.Object.Hash
+		if content, err = file.Reader(s.rootPath, s.db); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("ETag", etag(hash))
+	w.Header().Set("Content-Type", contentType(file))
+	query := r.URL.Query()
+	for name, header := range responseHeaders {
+		if value := query.Get(name); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	http.ServeContent(w, r, "", file.UpdatedAt, content)
+	return nil
+}
+
+// copyObject is used to copy the object of x-amz-copy-source to key, the
+// source must be in a bucket that can be accessed by the principal
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, b *bucket, key string, p *principal) error {
+	var (
+		err       error
+		source    string
+		src, dest *models.File
+		srcBucket *bucket
+		destPath  = b.path(key)
+	)
+
+	if source, err = url.PathUnescape(r.Header.Get("X-Amz-Copy-Source")); err != nil {
+		return ErrInvalidArgument.withMessage("x-amz-copy-source is invalid.")
+	}
+	if index := strings.Index(source, "?"); index >= 0 {
+		if version := strings.TrimPrefix(source[index+1:], "versionId="); version != "" && version != "null" {
+			return ErrNotImplemented.withMessage("Copying a version is not supported.")
+		}
+		source = source[:index]
+	}
+	parts := strings.SplitN(strings.TrimPrefix(source, "/"), "/", 2)
+	if len(parts) != 2 || strings.HasSuffix(key, "/") {
+		return ErrInvalidArgument.withMessage("x-amz-copy-source is invalid.")
+	}
+	if err = validateKey(parts[1]); err != nil {
+		return err
+	}
+	if srcBucket, err = s.findBucket(p, parts[0]); err != nil {
+		return err
+	}
+	if src, err = s.findObject(srcBucket, parts[1]); err != nil {
+		return err
+	}
+	if src.IsDir == models.IsDir {
+		return ErrInvalidRequest.withMessage("The directory can't be copied.")
+	}
+
+	if err = s.transaction(r.Context(), b.app, func(db *gorm.DB) error {
+		if src.FullPath == destPath {
+			dest = src
+			return nil
+		}
+		if err := models.CheckPathLock(b.app, destPath, p.owner(), db); err != nil {
+			return err
+		}
+		if _, err := models.FindFileByPathWithTrashed(b.app, destPath, db); err != nil {
+			if !gorm.IsRecordNotFoundError(err) {
+				return err
+			}
+			dest, err = src.CopyTo(destPath, db)
+			return err
+		}
+		reader, err := src.Reader(s.rootPath, db)
+		if err != nil {
+			return err
+		}
+		dest, err = s.writeObject(b, destPath, p.owner(), reader, db)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return writeXML(w, http.StatusOK, &copyObjectResult{
+		Xmlns:        xmlNamespace,
+		LastModified: formatTimestamp(dest.UpdatedAt),
+		ETag:         etag(src.Object.Hash),
+	})
+}
+
+// deleteObject is used to delete the object of key, it succeeds even if the key
+// doesn't exist. A key ending with '/' deletes an empty directory, a non-empty
+// directory is kept, as the keys in it still exist.
+func (s *Server) deleteObject(ctx context.Context, b *bucket, key string, p *principal) error {
+	file, err := models.FindFileByPath(b.app, b.path(key), s.db)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	if (file.IsDir == models.IsDir) != strings.HasSuffix(key, "/") {
+		return nil
+	}
+	return s.transaction(ctx, b.app, func(db *gorm.DB) error {
+		if err := file.CheckLock(p.owner(), db); err != nil {
+			return err
+		}
+		if err := file.Delete(false, db); err != nil && err != models.ErrDeleteNonEmptyDir {
+			return err
+		}
+		return nil
+	})
+}
+
+// deleteObjects is used to delete multiple objects in one request, every key
+// is deleted independently
+func (s *Server) deleteObjects(
+	w http.ResponseWriter, r *http.Request, b *bucket, p *principal, sig *signature, secret string,
+) error {
+	var (
+		err     error
+		body    []byte
+		reader  io.Reader
+		request = &deleteObjectsRequest{}
+		result  = &deleteResult{Xmlns: xmlNamespace}
+	)
+
+	if reader, err = payloadReader(r, sig, secret); err != nil {
+		return err
+	}
+	if body, err = ioutil.ReadAll(io.LimitReader(reader, 2<<20)); err != nil {
+		return err
+	}
+	if err = xml.Unmarshal(body, request); err != nil || len(request.Objects) > maxDeleteObjects {
+		return ErrMalformedXML
+	}
+
+	for _, object := range request.Objects {
+		if err = validateKey(object.Key); err == nil {
+			err = s.deleteObject(r.Context(), b, object.Key, p)
+		}
+		if err != nil {
+			apiErr := toError(err)
+			result.Errors = append(result.Errors, deleteErrorEntry{
+				Key: object.Key, Code: apiErr.Code, Message: apiErr.Message})
+		} else if !request.Quiet {
+			result.Deleted = append(result.Deleted, deletedEntry{Key: object.Key})
+		}
+	}
+
+	return writeXML(w, http.StatusOK, result)
+}
@@ -0,0 +1,212 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxChunkSize is the max size of a chunk of aws-chunked payload, the whole
+// chunk is buffered to verify its signature before it's read
+const maxChunkSize = 16 << 20
+
+// digestReader verify the digest of content when the underlying reader reaches
+// io.EOF, err is returned instead of io.EOF if the digest doesn't match
+type digestReader struct {
+	reader   io.Reader
+	hash     hash.Hash
+	expected []byte
+	err      error
+}
+
+// Read implement io.Reader
+func (d *digestReader) Read(p []byte) (n int, err error) {
+	n, err = d.reader.Read(p)
+	d.hash.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(d.hash.Sum(nil), d.expected) {
+		return n, d.err
+	}
+	return n, err
+}
+
+// chunkedReader decode the aws-chunked payload, the format of every chunk is:
+// <hex size>[;chunk-signature=<signature>]\r\n<data>\r\n, and the last chunk is
+// empty. The signature of chunk is chained with the previous one, starting
+// from the signature of request. If the payload is unsigned, the trailing
+// headers after the last chunk are skipped.
+type chunkedReader struct {
+	reader        *bufio.Reader
+	signature     *signature
+	signingKey    []byte
+	prevSignature string
+	signed        bool
+	buffer        []byte
+	offset        int
+	decodedLength int64
+	readLength    int64
+	done          bool
+}
+
+// readLine return a line without "\r\n"
+func (c *chunkedReader) readLine() (string, error) {
+	line, err := c.reader.ReadSlice('\n')
+	if err != nil {
+		if err == io.EOF || err == bufio.ErrBufferFull {
+			return "", ErrIncompleteBody
+		}
+		return "", err
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return "", ErrIncompleteBody
+	}
+	return string(line[:len(line)-2]), nil
+}
+
+// chunkSignature return the expected signature of chunk data
+func (c *chunkedReader) chunkSignature(data []byte) string {
+	stringToSign := strings.Join([]string{
+		signV4ChunkAlgorithm,
+		c.signature.date.Format(iso8601Format),
+		c.signature.scope(),
+		c.prevSignature,
+		emptySHA256,
+		sha256Hex(data),
+	}, "\n")
+	return hex.EncodeToString(hmacSHA256(c.signingKey, stringToSign))
+}
+
+// nextChunk is used to read and verify the next chunk
+func (c *chunkedReader) nextChunk() error {
+	var (
+		err       error
+		line      string
+		size      int64
+		signature string
+	)
+
+	if line, err = c.readLine(); err != nil {
+		return err
+	}
+	header := strings.SplitN(line, ";", 2)
+	if size, err = strconv.ParseInt(header[0], 16, 64); err != nil || size < 0 || size > maxChunkSize {
+		return ErrIncompleteBody
+	}
+	if c.signed {
+		if len(header) != 2 || !strings.HasPrefix(header[1], "chunk-signature=") {
+			return ErrSignatureDoesNotMatch
+		}
+		signature = strings.TrimPrefix(header[1], "chunk-signature=")
+	}
+
+	if int64(cap(c.buffer)) < size {
+		c.buffer = make([]byte, size)
+	}
+	c.buffer, c.offset = c.buffer[:size], 0
+	if _, err = io.ReadFull(c.reader, c.buffer); err != nil {
+		return ErrIncompleteBody
+	}
+
+	if c.signed {
+		if !hmac.Equal([]byte(c.chunkSignature(c.buffer)), []byte(signature)) {
+			return ErrSignatureDoesNotMatch
+		}
+		c.prevSignature = signature
+	}
+
+	if size > 0 || c.signed {
+		if line, err = c.readLine(); err != nil || line != "" {
+			return ErrIncompleteBody
+		}
+	} else {
+		// the trailing headers end with an empty line
+		for line = "-"; line != ""; {
+			if line, err = c.readLine(); err != nil {
+				return err
+			}
+		}
+	}
+
+	c.readLength += size
+	if size == 0 {
+		c.done = true
+		if c.decodedLength >= 0 && c.readLength != c.decodedLength {
+			return ErrIncompleteBody
+		}
+	}
+	return nil
+}
+
+// Read implement io.Reader
+func (c *chunkedReader) Read(p []byte) (n int, err error) {
+	for c.offset >= len(c.buffer) {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err = c.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, c.buffer[c.offset:])
+	c.offset += n
+	return n, nil
+}
+
+// payloadReader return the reader of request body, the body is decoded if it's
+// aws-chunked, and it's verified with the signature, x-amz-content-sha256 and
+// Content-MD5. The errors of verification are returned by the reader, so the
+// content must not be committed until the reader reaches io.EOF.
+func payloadReader(r *http.Request, s *signature, secret string) (io.Reader, error) {
+	var reader io.Reader = r.Body
+
+	switch s.payloadHash {
+	case unsignedPayload:
+	case streamingPayload, streamingUnsignedPayloadTrailer:
+		decodedLength := int64(-1)
+		if value := r.Header.Get("X-Amz-Decoded-Content-Length"); value != "" {
+			var err error
+			if decodedLength, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, ErrInvalidArgument.withMessage("X-Amz-Decoded-Content-Length is invalid.")
+			}
+		}
+		reader = &chunkedReader{
+			reader:        bufio.NewReader(r.Body),
+			signature:     s,
+			signingKey:    s.signingKey(secret),
+			prevSignature: s.signature,
+			signed:        s.payloadHash == streamingPayload,
+			decodedLength: decodedLength,
+		}
+	default:
+		expected, err := hex.DecodeString(s.payloadHash)
+		if err != nil || len(expected) != sha256.Size {
+			if strings.HasPrefix(s.payloadHash, "STREAMING-") {
+				return nil, ErrNotImplemented.withMessage(s.payloadHash + " is not supported.")
+			}
+			return nil, ErrInvalidArgument.withMessage("X-Amz-Content-Sha256 is invalid.")
+		}
+		reader = &digestReader{reader: reader, hash: sha256.New(), expected: expected, err: ErrContentSHA256Mismatch}
+	}
+
+	if value := r.Header.Get("Content-MD5"); value != "" {
+		expected, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(expected) != md5.Size {
+			return nil, ErrInvalidDigest
+		}
+		reader = &digestReader{reader: reader, hash: md5.New(), expected: expected, err: ErrBadDigest}
+	}
+
+	return reader, nil
+}
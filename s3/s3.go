@@ -0,0 +1,394 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+// Package s3 implement an s3 compatible gateway of the file tree, so that the
+// tools that speak s3 are able to use bigfile. The requests are authenticated
+// by signature version 4, the access key is the uid of app or token, and the
+// secret key is the secret of app or token. Every app is a bucket named with
+// its uid, and every token is a bucket named with its uid too, which is scoped
+// to the path of token. Only path-style requests are supported, such as
+// https://host/<bucket>/<key>.
+package s3
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bigfile/bigfile/databases"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/log"
+	"github.com/bigfile/bigfile/service"
+	"github.com/jinzhu/gorm"
+)
+
+// DefaultRegion is the region of gateway if it isn't specified
+const DefaultRegion = "us-east-1"
+
+// Server is the http handler of s3 gateway
+type Server struct {
+	// Region is reported by GetBucketLocation, the signatures of all regions are accepted
+	Region string
+
+	db       *gorm.DB
+	rootPath *string
+	now      func() time.Time
+}
+
+// NewServer return a gateway that uses db to save files, the default connection
+// is used if db is nil
+func NewServer(region string, db *gorm.DB) *Server {
+	if db == nil {
+		db = databases.MustNewConnection(nil)
+	}
+	if region == "" {
+		region = DefaultRegion
+	}
+	return &Server{Region: region, db: db, now: time.Now}
+}
+
+// principal represent the owner of access key that signs the request
+type principal struct {
+	app   *models.App
+	token *models.Token
+}
+
+// owner return the uid of principal, it's used as the owner of file locks
+func (p *principal) owner() string {
+	if p.token != nil {
+		return p.token.UID
+	}
+	return p.app.UID
+}
+
+// bucket represent a bucket, it's an app, or the scope of a token
+type bucket struct {
+	name      string
+	app       *models.App
+	root      string
+	createdAt time.Time
+}
+
+// base return the prefix of full paths of all files in bucket
+func (b *bucket) base() string {
+	return strings.TrimSuffix(b.root, "/") + "/"
+}
+
+// path return the full path of key
+func (b *bucket) path(key string) string {
+	return b.base() + strings.TrimSuffix(key, "/")
+}
+
+// key return the key of file by its full path
+func (b *bucket) key(fullPath string) string {
+	return strings.TrimPrefix(fullPath, b.base())
+}
+
+// statusRecorder is used to record the status code of response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implement http.ResponseWriter
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// ServeHTTP implement http.Handler, every request is recorded in the requests
+// table with protocol s3
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		ip       = util.ClientIP(r)
+		method   = r.Method
+		resource = r.URL.Path
+		recorder = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		record   = &models.Request{Protocol: "s3", IP: &ip, Method: &method, Service: &resource}
+	)
+
+	s.db.Create(record)
+	recorder.Header().Set("Server", "bigfile")
+	recorder.Header().Set("X-Amz-Request-Id", strconv.FormatUint(record.ID, 10))
+
+	defer func() {
+		if reErr := recover(); reErr != nil {
+			log.MustNewLogger(nil).Errorf("s3 request %d panic: %v", record.ID, reErr)
+			s.writeError(recorder, r, ErrInternalError)
+		}
+		s.db.Model(record).Updates(map[string]interface{}{
+			"appId":        record.AppID,
+			"token":        record.Token,
+			"responseCode": recorder.status,
+		})
+	}()
+
+	if err := s.serve(recorder, r, record); err != nil {
+		apiErr := toError(err)
+		if apiErr == ErrInternalError {
+			log.MustNewLogger(nil).Errorf("s3 request %d failed: %s", record.ID, err)
+		}
+		s.writeError(recorder, r, apiErr)
+	}
+}
+
+// authenticate is used to verify the signature of request, the principal
+// and its secret are returned
+func (s *Server) authenticate(r *http.Request) (*principal, *signature, string, error) {
+	var (
+		err    error
+		sig    *signature
+		secret string
+		p      = &principal{}
+	)
+
+	if r.Header.Get("Authorization") == "" && r.URL.Query().Get("X-Amz-Algorithm") == "" {
+		return nil, nil, "", ErrAccessDenied
+	}
+
+	if sig, err = parseSignature(r); err != nil {
+		return nil, nil, "", err
+	}
+
+	if p.token, err = models.FindTokenByUID(sig.accessKey, s.db); err == nil {
+		if p.token.Secret == nil {
+			return nil, nil, "", ErrInvalidAccessKeyID.withMessage("The token has no secret.")
+		}
+		p.app, secret = &p.token.App, *p.token.Secret
+	} else if p.app, err = models.FindAppByUID(sig.accessKey, s.db); err == nil {
+		p.token, secret = nil, p.app.Secret
+	} else {
+		return nil, nil, "", ErrInvalidAccessKeyID
+	}
+
+	if err = sig.verify(r, secret, s.now()); err != nil {
+		return nil, nil, "", err
+	}
+	return p, sig, secret, nil
+}
+
+// findBucket is used to find the bucket that can be accessed by principal, the
+// principal of app can access the buckets of its tokens too
+func (s *Server) findBucket(p *principal, name string) (*bucket, error) {
+	if p.token != nil {
+		if name != p.token.UID {
+			return nil, ErrAccessDenied
+		}
+		return &bucket{name: name, app: p.app, root: p.token.Path, createdAt: p.token.CreatedAt}, nil
+	}
+	if name == p.app.UID {
+		return &bucket{name: name, app: p.app, root: "/", createdAt: p.app.CreatedAt}, nil
+	}
+	token, err := models.FindTokenByUID(name, s.db)
+	if err != nil {
+		return nil, ErrNoSuchBucket
+	}
+	if token.AppID != p.app.ID {
+		return nil, ErrAccessDenied
+	}
+	return &bucket{name: name, app: p.app, root: token.Path, createdAt: token.CreatedAt}, nil
+}
+
+// checkTokenPolicy is used to apply the restrictions of token to the request,
+// and charge one available times
+func (s *Server) checkTokenPolicy(r *http.Request, p *principal) error {
+	if p.token == nil {
+		return nil
+	}
+	var (
+		ip       = util.ClientIP(r)
+		readOnly = r.Method == http.MethodGet || r.Method == http.MethodHead
+	)
+	if err := service.ValidateToken(s.db, &ip, readOnly, p.token); err != nil {
+		return err
+	}
+	return p.token.UpdateAvailableTimes(-1, s.db)
+}
+
+// validateKey is used to validate the key of object, the key must be a valid
+// path, and mustn't contain relative segments
+func validateKey(key string) error {
+	if len(key) > 1000 || !service.ValidatePath(strings.TrimSuffix(key, "/")) {
+		return ErrInvalidArgument.withMessage("The key is invalid.")
+	}
+	for _, segment := range strings.Split(strings.TrimSuffix(key, "/"), "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidArgument.withMessage("The key is invalid.")
+		}
+	}
+	return nil
+}
+
+// serve is used to authenticate and route the request
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, record *models.Request) error {
+	p, sig, secret, err := s.authenticate(r)
+	if err != nil {
+		return err
+	}
+	record.AppID = &p.app.ID
+	if p.token != nil {
+		record.Token = &p.token.UID
+	}
+
+	if err = s.checkTokenPolicy(r, p); err != nil {
+		return err
+	}
+
+	name, key := strings.TrimPrefix(r.URL.Path, "/"), ""
+	if index := strings.Index(name, "/"); index >= 0 {
+		name, key = name[:index], name[index+1:]
+	}
+
+	if name == "" {
+		if r.Method != http.MethodGet {
+			return ErrMethodNotAllowed
+		}
+		return s.listBuckets(w, p)
+	}
+
+	b, err := s.findBucket(p, name)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	if key == "" {
+		switch {
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+			return nil
+		case r.Method == http.MethodGet && hasQuery(query, "location"):
+			return writeXML(w, http.StatusOK, &locationConstraint{Xmlns: xmlNamespace, Location: s.Region})
+		case r.Method == http.MethodGet && hasQuery(query, "uploads", "versions", "acl", "policy", "lifecycle"):
+			return ErrNotImplemented
+		case r.Method == http.MethodGet:
+			return s.listObjects(w, r, b)
+		case r.Method == http.MethodPost && hasQuery(query, "delete"):
+			return s.deleteObjects(w, r, b, p, sig, secret)
+		case r.Method == http.MethodPut:
+			return ErrBucketAlreadyOwnedByYou
+		}
+		return ErrMethodNotAllowed
+	}
+
+	if err = validateKey(key); err != nil {
+		return err
+	}
+
+	switch {
+	case r.Method == http.MethodPut && hasQuery(query, "uploadId"):
+		return s.uploadPart(w, r, b, key, sig, secret)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		return s.copyObject(w, r, b, key, p)
+	case r.Method == http.MethodPut:
+		return s.putObject(w, r, b, key, p, sig, secret)
+	case r.Method == http.MethodGet && hasQuery(query, "uploadId"):
+		return s.listParts(w, r, b, key)
+	case r.Method == http.MethodGet && hasQuery(query, "acl", "tagging", "retention", "legal-hold", "torrent"):
+		return ErrNotImplemented
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return s.getObject(w, r, b, key)
+	case r.Method == http.MethodPost && hasQuery(query, "uploads"):
+		return s.createMultipartUpload(w, r, b, key, p)
+	case r.Method == http.MethodPost && hasQuery(query, "uploadId"):
+		return s.completeMultipartUpload(w, r, b, key, p, sig, secret)
+	case r.Method == http.MethodDelete && hasQuery(query, "uploadId"):
+		return s.abortMultipartUpload(w, r, b, key)
+	case r.Method == http.MethodDelete:
+		if err = s.deleteObject(r.Context(), b, key, p); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return ErrMethodNotAllowed
+}
+
+// hasQuery represent whether one of the names is in query
+func hasQuery(query map[string][]string, names ...string) bool {
+	for _, name := range names {
+		if _, ok := query[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// transaction is used to execute fn in a transaction of app, it's committed
+// only if fn returns nil. The journal sequence of app is locked first.
+func (s *Server) transaction(ctx context.Context, app *models.App, fn func(db *gorm.DB) error) (err error) {
+	if util.InTransaction(s.db) {
+		if err = models.LockJournalSequence(app.ID, s.db); err != nil {
+			return err
+		}
+		return fn(s.db)
+	}
+	trx := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	defer func() {
+		if reErr := recover(); reErr != nil {
+			trx.Rollback()
+			panic(reErr)
+		}
+	}()
+	if err = models.LockJournalSequence(app.ID, trx); err == nil {
+		err = fn(trx)
+	}
+	if err != nil {
+		trx.Rollback()
+		return err
+	}
+	if err = trx.Commit().Error; err == nil {
+		models.NotifyJournal(app.ID)
+	}
+	return err
+}
+
+// writeXML is used to respond an xml document
+func writeXML(w http.ResponseWriter, status int, value interface{}) error {
+	body, err := xml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(xml.Header)+len(body)))
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(body)
+	return nil
+}
+
+// writeError is used to respond the error, the body is omitted for HEAD
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, apiErr *Error) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(apiErr.StatusCode)
+		return
+	}
+	_ = writeXML(w, apiErr.StatusCode, &errorResponse{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Resource:  r.URL.Path,
+		RequestID: w.Header().Get("X-Amz-Request-Id"),
+	})
+}
+
+// listBuckets is used to list the buckets of principal
+func (s *Server) listBuckets(w http.ResponseWriter, p *principal) error {
+	result := &listAllMyBucketsResult{
+		Xmlns: xmlNamespace,
+		Owner: owner{ID: p.app.UID, DisplayName: p.app.Name},
+	}
+	if p.token != nil {
+		result.Buckets = append(result.Buckets, bucketEntry{
+			Name: p.token.UID, CreationDate: formatTimestamp(p.token.CreatedAt)})
+	} else {
+		result.Buckets = append(result.Buckets, bucketEntry{
+			Name: p.app.UID, CreationDate: formatTimestamp(p.app.CreatedAt)})
+	}
+	return writeXML(w, http.StatusOK, result)
+}
@@ -0,0 +1,261 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package s3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newServerForTest(t *testing.T) (*Server, *models.App, *gorm.DB, func(*testing.T)) {
+	app, trx, down, err := models.NewAppForTest(nil, t)
+	assert.Nil(t, err)
+	tempDir := models.NewTempDirForTest()
+	server := &Server{Region: DefaultRegion, db: trx, rootPath: &tempDir, now: time.Now}
+	return server, app, trx, func(t *testing.T) {
+		down(t)
+		_ = os.RemoveAll(tempDir)
+	}
+}
+
+// doRequest send a signed request to server
+func doRequest(
+	s *Server, method, target string, body []byte, accessKey, secret string, header map[string]string,
+) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, bytes.NewReader(body))
+	for name, value := range header {
+		r.Header.Set(name, value)
+	}
+	signRequest(r, accessKey, secret, sha256Hex(body), time.Now())
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func assertErrorCode(t *testing.T, w *httptest.ResponseRecorder, apiErr *Error) {
+	response := &errorResponse{}
+	assert.Equal(t, apiErr.StatusCode, w.Code)
+	assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), response))
+	assert.Equal(t, apiErr.Code, response.Code)
+}
+
+func TestServer_Authenticate(t *testing.T) {
+	server, app, trx, down := newServerForTest(t)
+	defer down(t)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertErrorCode(t, w, ErrAccessDenied)
+
+	w = doRequest(server, http.MethodGet, "/", nil, "unknown", app.Secret, nil)
+	assertErrorCode(t, w, ErrInvalidAccessKeyID)
+
+	w = doRequest(server, http.MethodGet, "/", nil, app.UID, "wrong secret", nil)
+	assertErrorCode(t, w, ErrSignatureDoesNotMatch)
+
+	w = doRequest(server, http.MethodGet, "/", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	result := &listAllMyBucketsResult{}
+	assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), result))
+	assert.Equal(t, app.UID, result.Buckets[0].Name)
+
+	record := &models.Request{}
+	assert.Nil(t, trx.Where("protocol = ?", "s3").Order("id desc").First(record).Error)
+	assert.Equal(t, app.ID, *record.AppID)
+	assert.Equal(t, http.StatusOK, record.ResponseCode)
+}
+
+func TestServer_Object(t *testing.T) {
+	server, app, _, down := newServerForTest(t)
+	defer down(t)
+
+	bucketPath := "/" + app.UID
+	w := doRequest(server, http.MethodPut, bucketPath+"/a/b.txt", []byte("hello world"), app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, etag(sha256Hex([]byte("hello world"))), w.Header().Get("ETag"))
+
+	w = doRequest(server, http.MethodHead, bucketPath+"/a/b.txt", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "11", w.Header().Get("Content-Length"))
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = doRequest(server, http.MethodGet, bucketPath+"/a/b.txt", nil, app.UID, app.Secret,
+		map[string]string{"Range": "bytes=6-"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "world", w.Body.String())
+
+	w = doRequest(server, http.MethodPut, bucketPath+"/a/b.txt", []byte("overwritten"), app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doRequest(server, http.MethodGet, bucketPath+"/a/b.txt", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, "overwritten", w.Body.String())
+
+	w = doRequest(server, http.MethodPut, bucketPath+"/c.txt", nil, app.UID, app.Secret,
+		map[string]string{"X-Amz-Copy-Source": bucketPath + "/a/b.txt"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doRequest(server, http.MethodGet, bucketPath+"/c.txt", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, "overwritten", w.Body.String())
+
+	w = doRequest(server, http.MethodGet, bucketPath+"/a/", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doRequest(server, http.MethodGet, bucketPath+"/a", nil, app.UID, app.Secret, nil)
+	assertErrorCode(t, w, ErrNoSuchKey)
+	w = doRequest(server, http.MethodGet, bucketPath+"/a/../c.txt", nil, app.UID, app.Secret, nil)
+	assertErrorCode(t, w, ErrInvalidArgument)
+
+	w = doRequest(server, http.MethodDelete, bucketPath+"/a/b.txt", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(server, http.MethodGet, bucketPath+"/a/b.txt", nil, app.UID, app.Secret, nil)
+	assertErrorCode(t, w, ErrNoSuchKey)
+	w = doRequest(server, http.MethodDelete, bucketPath+"/a/b.txt", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// the deleted file is restored by uploading
+	w = doRequest(server, http.MethodPut, bucketPath+"/a/b.txt", []byte("restored"), app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doRequest(server, http.MethodGet, bucketPath+"/a/b.txt", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, "restored", w.Body.String())
+
+	w = doRequest(server, http.MethodPut, bucketPath+"/d/", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body := []byte(`<Delete><Object><Key>c.txt</Key></Object><Object><Key>d/</Key></Object></Delete>`)
+	w = doRequest(server, http.MethodPost, bucketPath+"?delete", body, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	result := &deleteResult{}
+	assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), result))
+	assert.Equal(t, 2, len(result.Deleted))
+	w = doRequest(server, http.MethodHead, bucketPath+"/c.txt", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestServer_ListObjects(t *testing.T) {
+	server, app, _, down := newServerForTest(t)
+	defer down(t)
+
+	bucketPath := "/" + app.UID
+	for _, key := range []string{"a.txt", "b/1.txt", "b/2.txt", "c/d/3.txt", "e.txt"} {
+		w := doRequest(server, http.MethodPut, bucketPath+"/"+key, []byte(key), app.UID, app.Secret, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := doRequest(server, http.MethodGet, bucketPath+"?list-type=2&delimiter=/", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	result := &listBucketResultV2{}
+	assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), result))
+	assert.Equal(t, 4, result.KeyCount)
+	assert.Equal(t, "a.txt", result.Contents[0].Key)
+	assert.Equal(t, "e.txt", result.Contents[1].Key)
+	assert.Equal(t, []commonPrefix{{Prefix: "b/"}, {Prefix: "c/"}}, result.CommonPrefixes)
+
+	var keys []string
+	token := ""
+	for {
+		target := bucketPath + "?list-type=2&delimiter=/&max-keys=1"
+		if token != "" {
+			target += "&continuation-token=" + uriEncode(token, false)
+		}
+		w = doRequest(server, http.MethodGet, target, nil, app.UID, app.Secret, nil)
+		result = &listBucketResultV2{}
+		assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), result))
+		for _, content := range result.Contents {
+			keys = append(keys, content.Key)
+		}
+		for _, prefix := range result.CommonPrefixes {
+			keys = append(keys, prefix.Prefix)
+		}
+		if !result.IsTruncated {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	assert.Equal(t, []string{"a.txt", "b/", "c/", "e.txt"}, keys)
+
+	w = doRequest(server, http.MethodGet, bucketPath+"?prefix=b/&marker=b/1.txt", nil, app.UID, app.Secret, nil)
+	v1 := &listBucketResult{}
+	assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), v1))
+	assert.Equal(t, 1, len(v1.Contents))
+	assert.Equal(t, "b/2.txt", v1.Contents[0].Key)
+}
+
+func TestServer_Multipart(t *testing.T) {
+	server, app, _, down := newServerForTest(t)
+	defer down(t)
+
+	defer func(size int) { minPartSize = size }(minPartSize)
+	minPartSize = 5
+
+	object := "/" + app.UID + "/multipart.txt"
+	w := doRequest(server, http.MethodPost, object+"?uploads", nil, app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	initiated := &initiateMultipartUploadResult{}
+	assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), initiated))
+
+	var etags []string
+	for index, content := range []string{"hello", " world"} {
+		target := fmt.Sprintf("%s?partNumber=%d&uploadId=%s", object, index+1, initiated.UploadID)
+		w = doRequest(server, http.MethodPut, target, []byte(content), app.UID, app.Secret, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		etags = append(etags, w.Header().Get("ETag"))
+	}
+
+	w = doRequest(server, http.MethodGet, object+"?uploadId="+initiated.UploadID, nil, app.UID, app.Secret, nil)
+	parts := &listPartsResult{}
+	assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), parts))
+	assert.Equal(t, 2, len(parts.Parts))
+
+	body := fmt.Sprintf("<CompleteMultipartUpload><Part><PartNumber>2</PartNumber><ETag>%s</ETag></Part>"+
+		"<Part><PartNumber>1</PartNumber><ETag>%s</ETag></Part></CompleteMultipartUpload>", etags[1], etags[0])
+	w = doRequest(server, http.MethodPost, object+"?uploadId="+initiated.UploadID, []byte(body), app.UID, app.Secret, nil)
+	assertErrorCode(t, w, ErrInvalidPartOrder)
+
+	body = fmt.Sprintf("<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>%s</ETag></Part>"+
+		"<Part><PartNumber>2</PartNumber><ETag>%s</ETag></Part></CompleteMultipartUpload>", etags[0], etags[1])
+	w = doRequest(server, http.MethodPost, object+"?uploadId="+initiated.UploadID, []byte(body), app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doRequest(server, http.MethodGet, object, nil, app.UID, app.Secret, nil)
+	assert.Equal(t, "hello world", w.Body.String())
+
+	w = doRequest(server, http.MethodGet, object+"?uploadId="+initiated.UploadID, nil, app.UID, app.Secret, nil)
+	assertErrorCode(t, w, ErrNoSuchUpload)
+}
+
+func TestServer_TokenBucket(t *testing.T) {
+	secret := models.RandomWithMD5(32)
+	token, trx, down, err := models.NewTokenForTest(nil, t, "/scope", nil, nil, &secret, -1, 1)
+	assert.Nil(t, err)
+	defer down(t)
+	tempDir := models.NewTempDirForTest()
+	defer os.RemoveAll(tempDir)
+	server := &Server{Region: DefaultRegion, db: trx, rootPath: &tempDir, now: time.Now}
+
+	w := doRequest(server, http.MethodPut, "/"+token.App.UID+"/scope/a.txt", []byte("hello"),
+		token.App.UID, token.App.Secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doRequest(server, http.MethodGet, "/"+token.UID+"/a.txt", nil, token.UID, secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+
+	w = doRequest(server, http.MethodPut, "/"+token.UID+"/b.txt", []byte("hello"), token.UID, secret, nil)
+	assertErrorCode(t, w, ErrAccessDenied)
+
+	w = doRequest(server, http.MethodGet, "/"+token.App.UID+"/scope/a.txt", nil, token.UID, secret, nil)
+	assertErrorCode(t, w, ErrAccessDenied)
+
+	// the app is able to access the buckets of its tokens
+	w = doRequest(server, http.MethodGet, "/"+token.UID+"/a.txt", nil, token.App.UID, token.App.Secret, nil)
+	assert.Equal(t, "hello", w.Body.String())
+}
@@ -0,0 +1,268 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signV4Algorithm      = "AWS4-HMAC-SHA256"
+	signV4ChunkAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+	signV4Terminator     = "aws4_request"
+	iso8601Format        = "20060102T150405Z"
+	yyyymmdd             = "20060102"
+
+	unsignedPayload                 = "UNSIGNED-PAYLOAD"
+	streamingPayload                = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	emptySHA256                     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	amzContentSHA256 = "X-Amz-Content-Sha256"
+	amzDate          = "X-Amz-Date"
+
+	// maxClockSkew is the max difference between the time of request and
+	// the time of server
+	maxClockSkew = 15 * time.Minute
+	// maxPresignExpires is the max lifetime of presigned url
+	maxPresignExpires = 7 * 24 * time.Hour
+)
+
+// signature represent the signature version 4 of request, it's carried by the
+// Authorization header, or by the query of presigned url
+type signature struct {
+	accessKey     string
+	date          time.Time
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+	payloadHash   string
+	presigned     bool
+	expires       time.Duration
+}
+
+// scope return the credential scope of signature
+func (s *signature) scope() string {
+	return strings.Join([]string{s.date.Format(yyyymmdd), s.region, s.service, signV4Terminator}, "/")
+}
+
+// parseSignature is used to parse the signature of request, the Authorization
+// header takes precedence over the query
+func parseSignature(r *http.Request) (*signature, error) {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		return parseAuthorizationHeader(r, auth)
+	}
+	return parsePresignedQuery(r)
+}
+
+// parseCredential is used to parse "<access key>/<date>/<region>/<service>/aws4_request"
+func parseCredential(s *signature, credential string) bool {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[0] == "" || parts[4] != signV4Terminator {
+		return false
+	}
+	s.accessKey, s.region, s.service = parts[0], parts[2], parts[3]
+	return s.date.Format(yyyymmdd) == parts[1]
+}
+
+// parseAuthorizationHeader is used to parse the header like:
+// AWS4-HMAC-SHA256 Credential=<credential>, SignedHeaders=<headers>, Signature=<signature>
+func parseAuthorizationHeader(r *http.Request, auth string) (*signature, error) {
+	var (
+		err    error
+		s      = &signature{}
+		fields = make(map[string]string)
+	)
+
+	if !strings.HasPrefix(auth, signV4Algorithm+" ") {
+		return nil, ErrAuthorizationHeaderMalformed
+	}
+	for _, field := range strings.Split(strings.TrimPrefix(auth, signV4Algorithm+" "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return nil, ErrAuthorizationHeaderMalformed
+		}
+		fields[kv[0]] = kv[1]
+	}
+
+	if date := r.Header.Get(amzDate); date != "" {
+		s.date, err = time.Parse(iso8601Format, date)
+	} else {
+		s.date, err = http.ParseTime(r.Header.Get("Date"))
+	}
+	if err != nil {
+		return nil, ErrMissingSecurityHeader.withMessage("X-Amz-Date or Date header is missing or malformed.")
+	}
+	s.date = s.date.UTC()
+
+	if !parseCredential(s, fields["Credential"]) || fields["SignedHeaders"] == "" || fields["Signature"] == "" {
+		return nil, ErrAuthorizationHeaderMalformed
+	}
+	s.signedHeaders = strings.Split(fields["SignedHeaders"], ";")
+	s.signature = fields["Signature"]
+
+	if s.payloadHash = r.Header.Get(amzContentSHA256); s.payloadHash == "" {
+		return nil, ErrMissingSecurityHeader.withMessage("X-Amz-Content-Sha256 header is missing.")
+	}
+	return s, nil
+}
+
+// parsePresignedQuery is used to parse the signature in the query of presigned url
+func parsePresignedQuery(r *http.Request) (*signature, error) {
+	var (
+		err     error
+		expires int64
+		s       = &signature{presigned: true, payloadHash: unsignedPayload}
+		query   = r.URL.Query()
+	)
+
+	if query.Get("X-Amz-Algorithm") != signV4Algorithm {
+		return nil, ErrAuthorizationQueryParametersError
+	}
+	if s.date, err = time.Parse(iso8601Format, query.Get("X-Amz-Date")); err != nil {
+		return nil, ErrAuthorizationQueryParametersError
+	}
+	if expires, err = strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64); err != nil || expires < 0 {
+		return nil, ErrAuthorizationQueryParametersError
+	}
+	if s.expires = time.Duration(expires) * time.Second; s.expires > maxPresignExpires {
+		return nil, ErrAuthorizationQueryParametersError.withMessage("X-Amz-Expires must be less than a week.")
+	}
+	if !parseCredential(s, query.Get("X-Amz-Credential")) ||
+		query.Get("X-Amz-SignedHeaders") == "" || query.Get("X-Amz-Signature") == "" {
+		return nil, ErrAuthorizationQueryParametersError
+	}
+	s.signedHeaders = strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+	s.signature = query.Get("X-Amz-Signature")
+	if hash := query.Get(amzContentSHA256); hash != "" {
+		s.payloadHash = hash
+	}
+	return s, nil
+}
+
+// uriEncode encode s as the canonical request of aws requires, every byte except
+// the unreserved characters is encoded, '/' is kept if it's a path
+func uriEncode(s string, path bool) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (path && c == '/') {
+			builder.WriteByte(c)
+		} else {
+			builder.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+	return builder.String()
+}
+
+// canonicalQuery return the sorted and encoded query of request, the signature
+// itself is excluded
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for key, values := range query {
+		if key == "X-Amz-Signature" {
+			continue
+		}
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key, false)+"="+uriEncode(value, false))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// canonicalHeaders return the signed headers with their trimmed values
+func canonicalHeaders(r *http.Request, signedHeaders []string) string {
+	var builder strings.Builder
+	for _, name := range signedHeaders {
+		var values []string
+		switch {
+		case name == "host":
+			values = []string{r.Host}
+		case name == "content-length" && r.Header.Get("Content-Length") == "":
+			values = []string{strconv.FormatInt(r.ContentLength, 10)}
+		default:
+			for _, value := range r.Header[http.CanonicalHeaderKey(name)] {
+				values = append(values, strings.Join(strings.Fields(value), " "))
+			}
+		}
+		builder.WriteString(name + ":" + strings.Join(values, ",") + "\n")
+	}
+	return builder.String()
+}
+
+// canonicalRequest return the canonical request of version 4
+func canonicalRequest(r *http.Request, signedHeaders []string, payloadHash string) string {
+	return strings.Join([]string{
+		r.Method,
+		uriEncode(r.URL.Path, true),
+		canonicalQuery(r.URL.Query()),
+		canonicalHeaders(r, signedHeaders),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+// hmacSHA256 return the HMAC-SHA256 of data
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sha256Hex return the hex encoded sha256 hash of data
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// signingKey derive the signing key from secret and the scope of signature
+func (s *signature) signingKey(secret string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), s.date.Format(yyyymmdd))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	return hmacSHA256(key, signV4Terminator)
+}
+
+// compute return the expected signature of request
+func (s *signature) compute(r *http.Request, secret string) string {
+	stringToSign := strings.Join([]string{
+		signV4Algorithm,
+		s.date.Format(iso8601Format),
+		s.scope(),
+		sha256Hex([]byte(canonicalRequest(r, s.signedHeaders, s.payloadHash))),
+	}, "\n")
+	return hex.EncodeToString(hmacSHA256(s.signingKey(secret), stringToSign))
+}
+
+// verify is used to check the signature of request with the secret, now is the
+// time of server
+func (s *signature) verify(r *http.Request, secret string, now time.Time) error {
+	if s.presigned {
+		if now.Before(s.date.Add(-maxClockSkew)) {
+			return ErrRequestTimeTooSkewed
+		}
+		if now.After(s.date.Add(s.expires)) {
+			return ErrExpiredPresignRequest
+		}
+	} else if diff := now.Sub(s.date); diff > maxClockSkew || diff < -maxClockSkew {
+		return ErrRequestTimeTooSkewed
+	}
+
+	if !hmac.Equal([]byte(s.compute(r, secret)), []byte(s.signature)) {
+		return ErrSignatureDoesNotMatch
+	}
+	return nil
+}
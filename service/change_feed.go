@@ -0,0 +1,102 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// ChangeFeedResponse represent the response value of ChangeFeed service. Cursor
+// is used to fetch the following changes, HasMore represent that there are
+// more changes after Cursor at the moment.
+type ChangeFeedResponse struct {
+	Changes []models.Journal
+	Cursor  uint64
+	HasMore bool
+}
+
+// ChangeFeed is used to fetch the changes of file tree after cursor, only the
+// changes in the scope of token are included
+type ChangeFeed struct {
+	BaseService
+
+	Token  *models.Token `validate:"required"`
+	IP     *string       `validate:"omitempty"`
+	SubDir string        `validate:"omitempty"`
+	Cursor uint64        `validate:"omitempty"`
+	Limit  int           `validate:"required,min=1,max=1000"`
+}
+
+// Validate is used to validate params
+func (cf *ChangeFeed) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+
+	if err = Validate.Struct(cf); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(cf.DB, cf.IP, true, cf.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("ChangeFeed.Token", err))
+	}
+
+	if !ValidatePath(cf.SubDir) {
+		validateErrors = append(validateErrors, generateErrorByField("ChangeFeed.SubDir", ErrInvalidPath))
+	}
+
+	return validateErrors
+}
+
+// fetchChanges is used to fetch at most limit changes in scope after cursor.
+// The latest sequence is read before the changes, so that the cursor can skip
+// the changes out of scope safely.
+func fetchChanges(app *models.App, scope string, cursor uint64, limit int, db *gorm.DB) (*ChangeFeedResponse, error) {
+	var (
+		err      error
+		latest   uint64
+		journals []models.Journal
+		response = &ChangeFeedResponse{Cursor: cursor}
+	)
+
+	if latest, err = models.LatestJournalSequence(app, db); err != nil {
+		return nil, err
+	}
+
+	if latest <= cursor {
+		return response, nil
+	}
+
+	if journals, err = models.FindJournals(app, cursor, latest, scope, limit+1, db); err != nil {
+		return nil, err
+	}
+
+	if len(journals) > limit {
+		response.Changes = journals[:limit]
+		response.Cursor = journals[limit-1].Sequence
+		response.HasMore = true
+	} else {
+		response.Changes = journals
+		response.Cursor = latest
+	}
+
+	return response, nil
+}
+
+// Execute is used to fetch the changes
+func (cf *ChangeFeed) Execute(ctx context.Context) (interface{}, error) {
+	if err := cf.Token.UpdateAvailableTimes(-1, cf.DB); err != nil {
+		return nil, err
+	}
+
+	return fetchChanges(&cf.Token.App, cf.Token.PathWithScope(cf.SubDir), cf.Cursor, cf.Limit, cf.DB)
+}
@@ -0,0 +1,82 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeFeed_Validate(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		srv     = &ChangeFeed{SubDir: "/!@#"}
+	)
+	_, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+	srv.DB = trx
+
+	errValidate := srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10072))
+	confirm.True(errValidate.ContainsErrCode(10073))
+	confirm.True(errValidate.ContainsErrCode(10074))
+}
+
+func TestChangeFeed_Execute(t *testing.T) {
+	var confirm = assert.New(t)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+
+	token.Path = "/scope"
+	confirm.Nil(trx.Model(token).Update("path", token.Path).Error)
+	_, err = models.CreateOrGetLastDirectory(&token.App, "/scope/a/b", trx)
+	confirm.Nil(err)
+	_, err = models.CreateOrGetLastDirectory(&token.App, "/other/c", trx)
+	confirm.Nil(err)
+	_, err = models.CreateOrGetLastDirectory(&token.App, "/scope/d", trx)
+	confirm.Nil(err)
+
+	srv := &ChangeFeed{
+		BaseService: BaseService{DB: trx},
+		Token:       token,
+		SubDir:      "/",
+		Limit:       2,
+	}
+	confirm.Nil(srv.Validate())
+	value, err := srv.Execute(context.TODO())
+	confirm.Nil(err)
+	response := value.(*ChangeFeedResponse)
+	confirm.True(response.HasMore)
+	confirm.Equal(2, len(response.Changes))
+	confirm.Equal("/scope", response.Changes[0].Path)
+	confirm.Equal("/scope/a", response.Changes[1].Path)
+
+	srv.Cursor = response.Cursor
+	value, err = srv.Execute(context.TODO())
+	confirm.Nil(err)
+	response = value.(*ChangeFeedResponse)
+	confirm.False(response.HasMore)
+	confirm.Equal(2, len(response.Changes))
+	confirm.Equal("/scope/a/b", response.Changes[0].Path)
+	confirm.Equal("/scope/d", response.Changes[1].Path)
+
+	// the cursor is up to date, even though the last change is out of scope
+	_, err = models.CreateOrGetLastDirectory(&token.App, "/other/e", trx)
+	confirm.Nil(err)
+	srv.Cursor = response.Cursor
+	value, err = srv.Execute(context.TODO())
+	confirm.Nil(err)
+	response = value.(*ChangeFeedResponse)
+	confirm.Empty(response.Changes)
+	latest, err := models.LatestJournalSequence(&token.App, trx)
+	confirm.Nil(err)
+	confirm.Equal(latest, response.Cursor)
+}
@@ -50,7 +50,7 @@ func (dl *DirectoryList) Validate() ValidateErrors {
 		}
 	}
 
-	if err = ValidateToken(dl.DB, dl.IP, false, dl.Token); err != nil {
+	if err = ValidateToken(dl.DB, dl.IP, true, dl.Token); err != nil {
 		validateErrors = append(validateErrors, generateErrorByField("DirectoryList.Token", err))
 	}
 
@@ -68,6 +68,7 @@ func (dl *DirectoryList) Execute(ctx context.Context) (interface{}, error) {
 		dir     *models.File
 		total   int
 		pages   int
+		expired bool
 		dirPath = dl.Token.PathWithScope(dl.SubDir)
 	)
 
@@ -75,7 +76,7 @@ func (dl *DirectoryList) Execute(ctx context.Context) (interface{}, error) {
 		return nil, err
 	}
 
-	if dir, err = models.FindFileByPath(&dl.Token.App, dirPath, dl.DB, false); err != nil {
+	if dir, err = models.FindFileByPath(&dl.Token.App, dirPath, dl.DB); err != nil {
 		return nil, err
 	}
 
@@ -83,7 +84,17 @@ func (dl *DirectoryList) Execute(ctx context.Context) (interface{}, error) {
 		return nil, ErrListFile
 	}
 
-	total = dl.DB.Model(dir).Association("Children").Count()
+	if expired, err = dir.IsExpired(dl.DB); err != nil {
+		return nil, err
+	}
+
+	if expired {
+		return nil, models.ErrFileExpired
+	}
+
+	if err = dl.DB.Model(&models.File{}).Scopes(models.NotExpired).Where("pid = ?", dir.ID).Count(&total).Error; err != nil {
+		return nil, err
+	}
 	pages = int(math.Ceil(float64(total) / float64(dl.Limit)))
 
 	if err = dl.DB.Preload("Children", func(db *gorm.DB) *gorm.DB {
@@ -102,7 +113,7 @@ func (dl *DirectoryList) Execute(ctx context.Context) (interface{}, error) {
 		case "time":
 			key = "updatedAt"
 		}
-		return db.Order(key + " " + order).Offset(dl.Offset).Limit(dl.Limit)
+		return db.Scopes(models.NotExpired).Order(key + " " + order).Offset(dl.Offset).Limit(dl.Limit)
 	}).First(dir).Error; err != nil {
 		return nil, err
 	}
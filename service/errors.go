@@ -290,6 +290,432 @@ var (
 			Field: "ImageConvert.Height",
 			Msg:   "height is required and the minimum is 0",
 		},
+		// FileLockAcquire Field error
+		"FileLockAcquire.Token": {
+			Code:  10041,
+			Field: "FileLockAcquire.Token",
+			Msg:   "token is required",
+		},
+		"FileLockAcquire.File": {
+			Code:  10042,
+			Field: "FileLockAcquire.File",
+			Msg:   "file is required",
+		},
+		"FileLockAcquire.Exclusive": {
+			Code:  10043,
+			Field: "FileLockAcquire.Exclusive",
+			Msg:   "exclusive is only allowed to be one of 0 and 1",
+		},
+		"FileLockAcquire.TTL": {
+			Code:  10044,
+			Field: "FileLockAcquire.TTL",
+			Msg:   "ttl is required, the min value is 1 and the max value is 86400",
+		},
+		// FileLockRefresh Field error
+		"FileLockRefresh.Token": {
+			Code:  10045,
+			Field: "FileLockRefresh.Token",
+			Msg:   "token is required",
+		},
+		"FileLockRefresh.Lock": {
+			Code:  10046,
+			Field: "FileLockRefresh.Lock",
+			Msg:   "lock is required",
+		},
+		"FileLockRefresh.TTL": {
+			Code:  10047,
+			Field: "FileLockRefresh.TTL",
+			Msg:   "ttl is required, the min value is 1 and the max value is 86400",
+		},
+		// FileLockRelease Field error
+		"FileLockRelease.Token": {
+			Code:  10048,
+			Field: "FileLockRelease.Token",
+			Msg:   "token is required",
+		},
+		"FileLockRelease.Lock": {
+			Code:  10049,
+			Field: "FileLockRelease.Lock",
+			Msg:   "lock is required",
+		},
+		// FileBatch Field error
+		"FileBatch.Token": {
+			Code:  10050,
+			Field: "FileBatch.Token",
+			Msg:   "token is required",
+		},
+		"FileBatch.Operations": {
+			Code:  10051,
+			Field: "FileBatch.Operations",
+			Msg:   "operations is required, and the max number of operations is 100",
+		},
+		// file expiry Field error
+		"FileCreate.ExpiredAt": {
+			Code:  10052,
+			Field: "FileCreate.ExpiredAt",
+			Msg:   "expiredAt must be greater than now, it's optional",
+		},
+		"FileUpdate.ExpiredAt": {
+			Code:  10053,
+			Field: "FileUpdate.ExpiredAt",
+			Msg:   "expiredAt must be greater than now, zero value removes the expiry, it's optional",
+		},
+		"FileExpireSweep.Limit": {
+			Code:  10054,
+			Field: "FileExpireSweep.Limit",
+			Msg:   "limit is required, the min value is 1 and the max value is 1000",
+		},
+		// FileRetention Field error
+		"FileRetention.Token": {
+			Code:  10055,
+			Field: "FileRetention.Token",
+			Msg:   "one of token and app is required",
+		},
+		"FileRetention.File": {
+			Code:  10056,
+			Field: "FileRetention.File",
+			Msg:   "file is required",
+		},
+		"FileRetention.Mode": {
+			Code:  10057,
+			Field: "FileRetention.Mode",
+			Msg:   "mode is one of 0, 1 and 2, it's optional",
+		},
+		"FileRetention.RetainUntil": {
+			Code:  10058,
+			Field: "FileRetention.RetainUntil",
+			Msg:   "retainUntil must be greater than now, it's optional",
+		},
+		"FileRetention.LegalHold": {
+			Code:  10059,
+			Field: "FileRetention.LegalHold",
+			Msg:   "legalHold is 1 or 0, it's optional",
+		},
+		// FileArchive Field error
+		"FileArchive.Token": {
+			Code:  10060,
+			Field: "FileArchive.Token",
+			Msg:   "token is required",
+		},
+		"FileArchive.SubDir": {
+			Code:  10061,
+			Field: "FileArchive.SubDir",
+			Msg:   "one of subDir and files is required",
+		},
+		"FileArchive.Files": {
+			Code:  10062,
+			Field: "FileArchive.Files",
+			Msg:   "files are invalid, at most 1000 files can be selected",
+		},
+		"FileArchive.Format": {
+			Code:  10063,
+			Field: "FileArchive.Format",
+			Msg:   "format is one of zip and tar.gz",
+		},
+		"FileArchive.Writer": {
+			Code:  10064,
+			Field: "FileArchive.Writer",
+			Msg:   "writer is required",
+		},
+		// FileExtract Field error
+		"FileExtract.Token": {
+			Code:  10065,
+			Field: "FileExtract.Token",
+			Msg:   "token is required",
+		},
+		"FileExtract.File": {
+			Code:  10066,
+			Field: "FileExtract.File",
+			Msg:   "file is required, and it must be an archive",
+		},
+		"FileExtract.Target": {
+			Code:  10067,
+			Field: "FileExtract.Target",
+			Msg:   "target is a legal directory path, the max length is 1000",
+		},
+		"FileExtract.Format": {
+			Code:  10068,
+			Field: "FileExtract.Format",
+			Msg:   "format is one of zip, tar and tar.gz, it's detected by the name of file if it's omitted",
+		},
+		"FileExtract.Overwrite": {
+			Code:  10069,
+			Field: "FileExtract.Overwrite",
+			Msg:   "overwrite is 1 or 0, it's optional",
+		},
+		"FileExtract.MaxEntries": {
+			Code:  10070,
+			Field: "FileExtract.MaxEntries",
+			Msg:   "the min value of maxEntries is 1 and the max value is 100000, it's optional",
+		},
+		"FileExtract.MaxSize": {
+			Code:  10071,
+			Field: "FileExtract.MaxSize",
+			Msg:   "the min value of maxSize is 1 and the max value is 1073741824, it's optional",
+		},
+		// ChangeFeed Field error
+		"ChangeFeed.Token": {
+			Code:  10072,
+			Field: "ChangeFeed.Token",
+			Msg:   "token is required",
+		},
+		"ChangeFeed.SubDir": {
+			Code:  10073,
+			Field: "ChangeFeed.SubDir",
+			Msg:   "subDir must be a legal path, it's optional",
+		},
+		"ChangeFeed.Limit": {
+			Code:  10074,
+			Field: "ChangeFeed.Limit",
+			Msg:   "limit is required, the min value is 1 and the max value is 1000",
+		},
+		// WebhookCreate Field error
+		"WebhookCreate.App": {
+			Code:  10075,
+			Field: "WebhookCreate.App",
+			Msg:   "app is required",
+		},
+		"WebhookCreate.URL": {
+			Code:  10076,
+			Field: "WebhookCreate.URL",
+			Msg:   "url is required, it must be an absolute http or https url, the max length is 1000",
+		},
+		"WebhookCreate.Events": {
+			Code:  10077,
+			Field: "WebhookCreate.Events",
+			Msg:   "events must be some of created, updated, deleted and restored, all events are subscribed if it's empty",
+		},
+		"WebhookCreate.PathPrefix": {
+			Code:  10078,
+			Field: "WebhookCreate.PathPrefix",
+			Msg:   "pathPrefix must be a legal path, the max length is 1000, it's optional",
+		},
+		// WebhookList Field error
+		"WebhookList.App": {
+			Code:  10079,
+			Field: "WebhookList.App",
+			Msg:   "app is required",
+		},
+		// WebhookDelete Field error
+		"WebhookDelete.App": {
+			Code:  10080,
+			Field: "WebhookDelete.App",
+			Msg:   "app is required",
+		},
+		"WebhookDelete.Webhook": {
+			Code:  10081,
+			Field: "WebhookDelete.Webhook",
+			Msg:   "webhook is required, and it must belong to the app",
+		},
+		// WebhookDeliveryList Field error
+		"WebhookDeliveryList.App": {
+			Code:  10082,
+			Field: "WebhookDeliveryList.App",
+			Msg:   "app is required",
+		},
+		"WebhookDeliveryList.Webhook": {
+			Code:  10083,
+			Field: "WebhookDeliveryList.Webhook",
+			Msg:   "webhook is required, and it must belong to the app",
+		},
+		"WebhookDeliveryList.Status": {
+			Code:  10084,
+			Field: "WebhookDeliveryList.Status",
+			Msg:   "status is one of pending, succeeded and failed, it's optional",
+		},
+		"WebhookDeliveryList.Offset": {
+			Code:  10085,
+			Field: "WebhookDeliveryList.Offset",
+			Msg:   "the min value of offset is 0",
+		},
+		"WebhookDeliveryList.Limit": {
+			Code:  10086,
+			Field: "WebhookDeliveryList.Limit",
+			Msg:   "limit is required, the min value is 1 and the max value is 100",
+		},
+		// WebhookDispatch Field error
+		"WebhookDispatch.Limit": {
+			Code:  10087,
+			Field: "WebhookDispatch.Limit",
+			Msg:   "limit is required, the min value is 1 and the max value is 1000",
+		},
+		// Watch Field error
+		"Watch.Token": {
+			Code:  10088,
+			Field: "Watch.Token",
+			Msg:   "token is required",
+		},
+		"Watch.SubDir": {
+			Code:  10089,
+			Field: "Watch.SubDir",
+			Msg:   "subDir must be a legal path, it's optional",
+		},
+		"Watch.Limit": {
+			Code:  10090,
+			Field: "Watch.Limit",
+			Msg:   "limit is required, the min value is 1 and the max value is 1000",
+		},
+		"Watch.Send": {
+			Code:  10091,
+			Field: "Watch.Send",
+			Msg:   "send is required",
+		},
+		// Presign Field error
+		"Presign.Token": {
+			Code:  10092,
+			Field: "Presign.Token",
+			Msg:   "token is required",
+		},
+		"Presign.Method": {
+			Code:  10093,
+			Field: "Presign.Method",
+			Msg:   "method is required and is one of GET and POST",
+		},
+		"Presign.Path": {
+			Code:  10094,
+			Field: "Presign.Path",
+			Msg:   "exactly one of fileUid and path is required, path is required for upload, the max length of path is 1000",
+		},
+		"Presign.ExpiresIn": {
+			Code:  10095,
+			Field: "Presign.ExpiresIn",
+			Msg:   "expiresIn is required, the min value is 1 and the max value is 604800",
+		},
+		"Presign.ContentLength": {
+			Code:  10096,
+			Field: "Presign.ContentLength",
+			Msg:   "the min value of contentLength is 0, it's only allowed for upload",
+		},
+		"Presign.ContentType": {
+			Code:  10097,
+			Field: "Presign.ContentType",
+			Msg:   "the max length of contentType is 255, it's only allowed for upload",
+		},
+		// Share Field error
+		"ShareCreate.Token": {
+			Code:  10098,
+			Field: "ShareCreate.Token",
+			Msg:   "token is required",
+		},
+		"ShareCreate.File": {
+			Code:  10099,
+			Field: "ShareCreate.File",
+			Msg:   "file is required",
+		},
+		"ShareCreate.Password": {
+			Code:  10100,
+			Field: "ShareCreate.Password",
+			Msg:   "the min length of password is 1 and the max length of password is 72",
+		},
+		"ShareCreate.ExpiredAt": {
+			Code:  10101,
+			Field: "ShareCreate.ExpiredAt",
+			Msg:   "expiredAt must be after now",
+		},
+		"ShareCreate.MaxDownloads": {
+			Code:  10102,
+			Field: "ShareCreate.MaxDownloads",
+			Msg:   "the min value of maxDownloads is 1",
+		},
+		"ShareList.Token": {
+			Code:  10103,
+			Field: "ShareList.Token",
+			Msg:   "token is required",
+		},
+		"ShareList.Offset": {
+			Code:  10104,
+			Field: "ShareList.Offset",
+			Msg:   "the min value of offset is 0",
+		},
+		"ShareList.Limit": {
+			Code:  10105,
+			Field: "ShareList.Limit",
+			Msg:   "limit is required, the min value is 1 and the max value is 100",
+		},
+		"ShareRevoke.Token": {
+			Code:  10106,
+			Field: "ShareRevoke.Token",
+			Msg:   "token is required",
+		},
+		"ShareRevoke.Share": {
+			Code:  10107,
+			Field: "ShareRevoke.Share",
+			Msg:   "share is required",
+		},
+		"ShareDownload.Share": {
+			Code:  10108,
+			Field: "ShareDownload.Share",
+			Msg:   "share is required",
+		},
+		"ShareDownload.Path": {
+			Code:  10109,
+			Field: "ShareDownload.Path",
+			Msg:   "the max length of path is 1000",
+		},
+		"ShareBrowse.Share": {
+			Code:  10110,
+			Field: "ShareBrowse.Share",
+			Msg:   "share is required",
+		},
+		"ShareBrowse.Path": {
+			Code:  10111,
+			Field: "ShareBrowse.Path",
+			Msg:   "the max length of path is 1000",
+		},
+		"ShareBrowse.Offset": {
+			Code:  10112,
+			Field: "ShareBrowse.Offset",
+			Msg:   "the min value of offset is 0",
+		},
+		"ShareBrowse.Limit": {
+			Code:  10113,
+			Field: "ShareBrowse.Limit",
+			Msg:   "limit is required, the min value is 1 and the max value is 100",
+		},
+		"ShareArchive.Share": {
+			Code:  10114,
+			Field: "ShareArchive.Share",
+			Msg:   "share is required",
+		},
+		"ShareArchive.Path": {
+			Code:  10115,
+			Field: "ShareArchive.Path",
+			Msg:   "the max length of path is 1000",
+		},
+		"ShareArchive.Writer": {
+			Code:  10116,
+			Field: "ShareArchive.Writer",
+			Msg:   "writer is required",
+		},
+
+		// FileStat Field error
+		"FileStat.Token": {
+			Code:  10117,
+			Field: "FileStat.Token",
+			Msg:   "token is required",
+		},
+		"FileStat.File": {
+			Code:  10118,
+			Field: "FileStat.File",
+			Msg:   "file is required",
+		},
+
+		// FileRestore Field error
+		"FileRestore.Token": {
+			Code:  10119,
+			Field: "FileRestore.Token",
+			Msg:   "one of token and app is required",
+		},
+		"FileRestore.File": {
+			Code:  10120,
+			Field: "FileRestore.File",
+			Msg:   "file is required",
+		},
+		"FileRestore.Path": {
+			Code:  10121,
+			Field: "FileRestore.Path",
+			Msg:   "the max length of path is 1000",
+		},
 	}
 )
 
@@ -0,0 +1,348 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const (
+	// ArchiveZip represent the zip archive format
+	ArchiveZip = "zip"
+	// ArchiveTarGz represent the gzip compressed tar archive format
+	ArchiveTarGz = "tar.gz"
+
+	// archiveWalkPageSize is the count of children loaded at a time while
+	// walking a directory, so that the memory is independent of directory size
+	archiveWalkPageSize = 100
+)
+
+// ErrArchiveSource represent that neither or both of the directory and the
+// files are provided
+var ErrArchiveSource = errors.New("one of directory and files is required")
+
+// archiveEntryWriter is implemented by the writers of all archive formats
+type archiveEntryWriter interface {
+	writeDir(name string, file *models.File) error
+	writeFile(name string, file *models.File, reader io.Reader) error
+	Close() error
+}
+
+type zipEntryWriter struct {
+	*zip.Writer
+}
+
+func (z *zipEntryWriter) writeDir(name string, file *models.File) error {
+	_, err := z.CreateHeader(&zip.FileHeader{Name: name + "/", Modified: file.UpdatedAt})
+	return err
+}
+
+// writeFile writes the entry with data descriptor, the archive switches to
+// Zip64 automatically once the size or the count of entries exceeds the
+// limits of zip format
+func (z *zipEntryWriter) writeFile(name string, file *models.File, reader io.Reader) error {
+	var (
+		err    error
+		writer io.Writer
+	)
+	if writer, err = z.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: file.UpdatedAt,
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, reader)
+	return err
+}
+
+type tarGzEntryWriter struct {
+	*tar.Writer
+	gzipWriter *gzip.Writer
+}
+
+func (t *tarGzEntryWriter) writeDir(name string, file *models.File) error {
+	return t.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name + "/",
+		Mode:     0755,
+		ModTime:  file.UpdatedAt,
+	})
+}
+
+func (t *tarGzEntryWriter) writeFile(name string, file *models.File, reader io.Reader) error {
+	if err := t.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(file.Size),
+		ModTime:  file.UpdatedAt,
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(t.Writer, reader)
+	return err
+}
+
+func (t *tarGzEntryWriter) Close() error {
+	if err := t.Writer.Close(); err != nil {
+		return err
+	}
+	return t.gzipWriter.Close()
+}
+
+func newArchiveEntryWriter(format string, writer io.Writer) archiveEntryWriter {
+	if format == ArchiveTarGz {
+		gzipWriter := gzip.NewWriter(writer)
+		return &tarGzEntryWriter{Writer: tar.NewWriter(gzipWriter), gzipWriter: gzipWriter}
+	}
+	return &zipEntryWriter{Writer: zip.NewWriter(writer)}
+}
+
+// FileArchive is used to pack a directory or some selected files into an
+// archive, the archive is written to Writer while it's being built. Hidden
+// and expired files are skipped, every file in archive costs one available
+// times of token.
+type FileArchive struct {
+	BaseService
+
+	Token  *models.Token  `validate:"required"`
+	IP     *string        `validate:"omitempty"`
+	SubDir *string        `validate:"omitempty"`
+	Files  []*models.File `validate:"omitempty,max=1000"`
+	Format string         `validate:"required,oneof=zip tar.gz"`
+	Writer io.Writer      `validate:"required"`
+}
+
+// Validate is used to validate service params
+func (fa *FileArchive) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(fa); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(fa.DB, fa.IP, true, fa.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileArchive.Token", err))
+	}
+
+	if (fa.SubDir == nil) == (len(fa.Files) == 0) {
+		validateErrors = append(validateErrors, generateErrorByField("FileArchive.SubDir", ErrArchiveSource))
+	} else if fa.SubDir != nil && !ValidatePath(*fa.SubDir) {
+		validateErrors = append(validateErrors, generateErrorByField("FileArchive.SubDir", ErrInvalidPath))
+	}
+
+	for _, file := range fa.Files {
+		if err = ValidateFile(fa.DB, file); err != nil {
+			validateErrors = append(validateErrors, generateErrorByField("FileArchive.Files", err))
+			break
+		}
+		if fa.Token != nil {
+			if err = file.CanBeAccessedByToken(fa.Token, fa.DB); err != nil {
+				validateErrors = append(validateErrors, generateErrorByField("FileArchive.Token", err))
+				break
+			}
+		}
+	}
+
+	return validateErrors
+}
+
+// walkArchiveDir calls fn for every visible descendant of dir in depth-first
+// order, name is the path of descendant relative to the parent of dir. Only
+// one page of children is held for every level of directory.
+func walkArchiveDir(dir *models.File, name string, db *gorm.DB, fn func(string, *models.File) error) error {
+	var lastID uint64
+	for {
+		var children []*models.File
+		if err := db.Scopes(models.NotExpired).
+			Where("pid = ? AND hidden = 0 AND id > ?", dir.ID, lastID).
+			Order("id ASC").Limit(archiveWalkPageSize).Find(&children).Error; err != nil {
+			return err
+		}
+		for _, child := range children {
+			childName := path.Join(name, child.Name)
+			if err := fn(childName, child); err != nil {
+				return err
+			}
+			if child.IsDir == models.IsDir {
+				if err := walkArchiveDir(child, childName, db, fn); err != nil {
+					return err
+				}
+			}
+		}
+		if len(children) < archiveWalkPageSize {
+			return nil
+		}
+		lastID = children[len(children)-1].ID
+	}
+}
+
+// uniqueEntryName return name if it isn't used by other entries at the top of
+// archive, otherwise a number is added before the extension, e.g. "a (1).txt"
+func uniqueEntryName(name string, isDir bool, used map[string]bool) string {
+	var (
+		unique = name
+		ext    = path.Ext(name)
+	)
+	if isDir {
+		ext = ""
+	}
+	for index := 1; used[unique]; index++ {
+		unique = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), index, ext)
+	}
+	used[unique] = true
+	return unique
+}
+
+// walk calls fn for every entry of archive, the selected files may have the
+// same name, they are renamed by uniqueEntryName in the order of selection
+func (fa *FileArchive) walk(roots []*models.File, fn func(string, *models.File) error) error {
+	var used = make(map[string]bool, len(roots))
+	for _, root := range roots {
+		name := uniqueEntryName(root.Name, root.IsDir == models.IsDir, used)
+		if root.IsDir == 0 {
+			if err := fn(name, root); err != nil {
+				return err
+			}
+			continue
+		}
+		if fa.SubDir != nil {
+			// the directory itself isn't included, its children are at the top
+			name = ""
+		} else if err := fn(name, root); err != nil {
+			return err
+		}
+		if err := walkArchiveDir(root, name, fa.DB, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roots return the directory or the selected files, hidden or expired ones
+// can't be packed into archive
+func (fa *FileArchive) roots() ([]*models.File, error) {
+	var (
+		err     error
+		dir     *models.File
+		roots   = fa.Files
+		expired bool
+	)
+
+	if fa.SubDir != nil {
+		if dir, err = models.FindFileByPath(&fa.Token.App, fa.Token.PathWithScope(*fa.SubDir), fa.DB); err != nil {
+			return nil, err
+		}
+		if dir.IsDir == 0 {
+			return nil, ErrListFile
+		}
+		roots = []*models.File{dir}
+	}
+
+	for _, root := range roots {
+		if root.Hidden == models.Hidden {
+			return nil, ErrReadHiddenFile
+		}
+		if expired, err = root.IsExpired(fa.DB); err != nil {
+			return nil, err
+		}
+		if expired {
+			return nil, models.ErrFileExpired
+		}
+	}
+
+	return roots, nil
+}
+
+// charge is used to take n available times of token atomically
+func (fa *FileArchive) charge(n int) error {
+	charged, err := fa.Token.ChargeAvailableTimes(n, fa.DB)
+	if err == nil && !charged {
+		err = ErrTokenAvailableTimesExhausted
+	}
+	return err
+}
+
+// Execute is used to write the archive. Nothing is written to Writer if some
+// error occurs before the archive is built, the count of files is returned.
+// The files are counted and charged before writing, the tree may change in
+// the meantime, so the files written more are charged one by one and the
+// ones written less are given back.
+func (fa *FileArchive) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err     error
+		roots   []*models.File
+		count   int
+		written int
+		writer  archiveEntryWriter
+	)
+
+	if roots, err = fa.roots(); err != nil {
+		return nil, err
+	}
+
+	if err = fa.walk(roots, func(name string, file *models.File) error {
+		if file.IsDir == 0 {
+			count++
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err = fa.charge(count); err != nil {
+		return nil, err
+	}
+
+	writer = newArchiveEntryWriter(fa.Format, fa.Writer)
+	if err = fa.walk(roots, func(name string, file *models.File) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if file.IsDir == models.IsDir {
+			return writer.writeDir(name, file)
+		}
+		if written++; written > count {
+			if err := fa.charge(1); err != nil {
+				return err
+			}
+		}
+		reader, err := file.Reader(fa.RootPath, fa.DB)
+		if err != nil {
+			return err
+		}
+		if err = file.IncreaseDownloadCount(fa.DB); err != nil {
+			return err
+		}
+		return writer.writeFile(name, file, reader)
+	}); err != nil {
+		return nil, err
+	}
+
+	if written < count {
+		if err = fa.charge(written - count); err != nil {
+			return nil, err
+		}
+	}
+
+	return written, writer.Close()
+}
@@ -0,0 +1,213 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileArchive_Validate(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		srv     = &FileArchive{Format: "rar"}
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+	srv.DB = trx
+
+	errValidate := srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10060))
+	confirm.True(errValidate.ContainsErrCode(10061))
+	confirm.True(errValidate.ContainsErrCode(10063))
+	confirm.True(errValidate.ContainsErrCode(10064))
+
+	subDir := "/"
+	srv.Token = token
+	srv.Format = ArchiveZip
+	srv.Writer = new(bytes.Buffer)
+	srv.SubDir = &subDir
+	srv.Files = []*models.File{{}}
+	errValidate = srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.Contains(errValidate.Error(), ErrArchiveSource.Error())
+
+	srv.Files = nil
+	confirm.Nil(srv.Validate())
+}
+
+func newFileArchiveForTest(t *testing.T) (*models.Token, *FileArchive, func(*testing.T)) {
+	var tempDir = models.NewTempDirForTest()
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+
+	for _, p := range []string{"/save/to/a.txt", "/save/to/sub/b.txt", "/save/to/.hidden/c.txt", "/save/d.txt"} {
+		var hidden int8
+		if p == "/save/to/.hidden/c.txt" {
+			hidden = models.Hidden
+		}
+		_, err = models.CreateFileFromReader(&token.App, p, bytes.NewReader([]byte(p)), hidden, &tempDir, trx)
+		assert.Nil(t, err)
+	}
+	hiddenDir, err := models.FindFileByPath(&token.App, "/save/to/.hidden", trx)
+	assert.Nil(t, err)
+	assert.Nil(t, trx.Model(hiddenDir).Update("hidden", models.Hidden).Error)
+	_, err = models.CreateOrGetLastDirectory(&token.App, "/save/to/empty", trx)
+	assert.Nil(t, err)
+
+	return token, &FileArchive{
+		BaseService: BaseService{DB: trx, RootPath: &tempDir},
+		Token:       token,
+	}, func(t *testing.T) {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}
+}
+
+func TestFileArchive_Execute(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		subDir  = "/save/to"
+		buf     = new(bytes.Buffer)
+	)
+	token, srv, down := newFileArchiveForTest(t)
+	defer down(t)
+	token.AvailableTimes = 10
+	confirm.Nil(srv.DB.Save(token).Error)
+
+	srv.SubDir = &subDir
+	srv.Format = ArchiveZip
+	srv.Writer = buf
+	confirm.Nil(srv.Validate())
+	count, err := srv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(2, count)
+	confirm.Equal(8, token.AvailableTimes)
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	confirm.Nil(err)
+	var names []string
+	for _, entry := range reader.File {
+		names = append(names, entry.Name)
+		if entry.Name == "sub/b.txt" {
+			content, err := entry.Open()
+			confirm.Nil(err)
+			data, err := ioutil.ReadAll(content)
+			confirm.Nil(err)
+			confirm.Equal("/save/to/sub/b.txt", string(data))
+		}
+	}
+	sort.Strings(names)
+	confirm.Equal([]string{"a.txt", "empty/", "sub/", "sub/b.txt"}, names)
+
+	// not enough available times
+	token.AvailableTimes = 1
+	confirm.Nil(srv.DB.Save(token).Error)
+	buf.Reset()
+	_, err = srv.Execute(context.TODO())
+	confirm.Equal(ErrTokenAvailableTimesExhausted, err)
+	confirm.Equal(0, buf.Len())
+}
+
+func TestFileArchive_Execute2(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		buf     = new(bytes.Buffer)
+	)
+	token, srv, down := newFileArchiveForTest(t)
+	defer down(t)
+
+	dir, err := models.FindFileByPath(&token.App, "/save/to/sub", srv.DB)
+	confirm.Nil(err)
+	file, err := models.FindFileByPath(&token.App, "/save/d.txt", srv.DB)
+	confirm.Nil(err)
+
+	srv.Files = []*models.File{dir, file}
+	srv.Format = ArchiveTarGz
+	srv.Writer = buf
+	confirm.Nil(srv.Validate())
+	_, err = srv.Execute(context.TODO())
+	confirm.Nil(err)
+
+	gzipReader, err := gzip.NewReader(buf)
+	confirm.Nil(err)
+	tarReader := tar.NewReader(gzipReader)
+	var names []string
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		confirm.Nil(err)
+		names = append(names, header.Name)
+	}
+	confirm.Equal([]string{"sub/", "sub/b.txt", "d.txt"}, names)
+
+	// hidden file can't be selected
+	hidden, err := models.FindFileByPath(&token.App, "/save/to/.hidden", srv.DB)
+	confirm.Nil(err)
+	srv.Files = []*models.File{hidden}
+	_, err = srv.Execute(context.TODO())
+	confirm.Equal(ErrReadHiddenFile, err)
+}
+
+func TestUniqueEntryName(t *testing.T) {
+	var used = make(map[string]bool)
+	assert.Equal(t, "a.txt", uniqueEntryName("a.txt", false, used))
+	assert.Equal(t, "a (1).txt", uniqueEntryName("a.txt", false, used))
+	assert.Equal(t, "a (2).txt", uniqueEntryName("a.txt", false, used))
+	assert.Equal(t, "v1.2", uniqueEntryName("v1.2", true, used))
+	assert.Equal(t, "v1.2 (1)", uniqueEntryName("v1.2", true, used))
+}
+
+func TestFileArchive_ExecuteSameName(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		buf     = new(bytes.Buffer)
+	)
+	token, srv, down := newFileArchiveForTest(t)
+	defer down(t)
+	token.AvailableTimes = 10
+	confirm.Nil(srv.DB.Save(token).Error)
+
+	first, err := models.FindFileByPath(&token.App, "/save/to/a.txt", srv.DB)
+	confirm.Nil(err)
+	second, err := models.CreateFileFromReader(
+		&token.App, "/save/a.txt", bytes.NewReader([]byte("/save/a.txt")), 0, srv.RootPath, srv.DB)
+	confirm.Nil(err)
+
+	srv.Files = []*models.File{first, second}
+	srv.Format = ArchiveZip
+	srv.Writer = buf
+	confirm.Nil(srv.Validate())
+	count, err := srv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(2, count)
+	confirm.Equal(8, token.AvailableTimes)
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	confirm.Nil(err)
+	var names []string
+	for _, entry := range reader.File {
+		names = append(names, entry.Name)
+	}
+	confirm.Equal([]string{"a.txt", "a (1).txt"}, names)
+}
@@ -0,0 +1,232 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const (
+	// FileBatchMkdir represent creating a directory
+	FileBatchMkdir = "mkdir"
+	// FileBatchMove represent moving a file or a directory to another path
+	FileBatchMove = "move"
+	// FileBatchCopy represent copying a file or a directory to another path
+	FileBatchCopy = "copy"
+	// FileBatchDelete represent deleting a file or a directory
+	FileBatchDelete = "delete"
+	// FileBatchSetHidden represent changing the hidden attribute of file
+	FileBatchSetHidden = "set-hidden"
+)
+
+var (
+	// ErrInvalidBatchOperation represent the operation of batch is invalid
+	ErrInvalidBatchOperation = errors.New("invalid batch operation")
+
+	// ErrBatchAborted represent that some operation failed, and the whole batch
+	// has been rolled back
+	ErrBatchAborted = errors.New("batch is aborted, all operations have been rolled back")
+)
+
+// FileBatchOperation represent an operation in batch. Path is the path of the
+// target file, To is the destination of move and copy, Hidden is only used by
+// set-hidden and Force is only used to delete non-empty directory. All paths
+// are relative to the scope of token.
+type FileBatchOperation struct {
+	Op     string  `json:"op"`
+	Path   string  `json:"path"`
+	To     *string `json:"to,omitempty"`
+	Hidden *bool   `json:"hidden,omitempty"`
+	Force  bool    `json:"force,omitempty"`
+}
+
+// FileBatchResult represent the result of an operation in batch. File is nil
+// when the operation failed or the batch has been rolled back.
+type FileBatchResult struct {
+	Index int
+	Op    string
+	File  *models.File
+	Error error
+}
+
+// FileBatch is used to execute a list of operations in order and atomically,
+// either all operations are applied, or none of them
+type FileBatch struct {
+	BaseService
+
+	Token      *models.Token         `validate:"required"`
+	IP         *string               `validate:"omitempty"`
+	Operations []*FileBatchOperation `validate:"required,min=1,max=100"`
+}
+
+// validateOperation is used to validate a single operation
+func validateOperation(op *FileBatchOperation) error {
+	if op == nil {
+		return ErrInvalidBatchOperation
+	}
+	switch op.Op {
+	case FileBatchMkdir, FileBatchDelete:
+	case FileBatchMove, FileBatchCopy:
+		if op.To == nil || !ValidatePath(*op.To) {
+			return ErrInvalidPath
+		}
+	case FileBatchSetHidden:
+		if op.Hidden == nil {
+			return ErrInvalidBatchOperation
+		}
+	default:
+		return ErrInvalidBatchOperation
+	}
+	if !ValidatePath(op.Path) {
+		return ErrInvalidPath
+	}
+	return nil
+}
+
+// Validate is used to validate service params
+func (fb *FileBatch) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(fb); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(fb.DB, fb.IP, false, fb.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileBatch.Token", err))
+	} else if fb.Token.AvailableTimes != -1 && fb.Token.AvailableTimes < len(fb.Operations) {
+		validateErrors = append(
+			validateErrors, generateErrorByField("FileBatch.Token", ErrTokenAvailableTimesExhausted))
+	}
+
+	for index, op := range fb.Operations {
+		if err = validateOperation(op); err != nil {
+			validateErrors = append(validateErrors, generateErrorByField(
+				"FileBatch.Operations", fmt.Errorf("operation %d: %s", index, err)))
+		}
+	}
+
+	return validateErrors
+}
+
+// executeOperation is used to execute a single operation in transaction
+func (fb *FileBatch) executeOperation(op *FileBatchOperation, db *gorm.DB) (*models.File, error) {
+	var (
+		err   error
+		file  *models.File
+		app   = &fb.Token.App
+		owner = fb.Token.UID
+		path  = fb.Token.PathWithScope(op.Path)
+	)
+
+	if op.Op == FileBatchMkdir {
+		if err = models.CheckPathLock(app, path, owner, db); err != nil {
+			return nil, err
+		}
+		return models.CreateOrGetLastDirectory(app, path, db)
+	}
+
+	if file, err = models.FindFileByPath(app, path, db); err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case FileBatchMove:
+		to := fb.Token.PathWithScope(*op.To)
+		if err = file.CheckLock(owner, db); err != nil {
+			return nil, err
+		}
+		if err = models.CheckPathLock(app, to, owner, db); err != nil {
+			return nil, err
+		}
+		return file, file.MoveTo(to, db)
+	case FileBatchCopy:
+		to := fb.Token.PathWithScope(*op.To)
+		if err = models.CheckPathLock(app, to, owner, db); err != nil {
+			return nil, err
+		}
+		return file.CopyTo(to, db)
+	case FileBatchDelete:
+		if err = file.CheckLock(owner, db); err != nil {
+			return nil, err
+		}
+		return file, file.Delete(op.Force, db)
+	case FileBatchSetHidden:
+		if err = file.CheckLock(owner, db); err != nil {
+			return nil, err
+		}
+		var hidden int8
+		if *op.Hidden {
+			hidden = models.Hidden
+		}
+		return file, file.SetHidden(hidden, db)
+	}
+
+	return nil, ErrInvalidBatchOperation
+}
+
+// Execute is used to execute all operations in one transaction. The results
+// are returned even if the batch is aborted, the failed one carries the error.
+func (fb *FileBatch) Execute(ctx context.Context) (result interface{}, err error) {
+	var (
+		file    *models.File
+		results = make([]*FileBatchResult, 0, len(fb.Operations))
+		inTrx   = util.InTransaction(fb.DB)
+	)
+
+	if !inTrx {
+		fb.DB = fb.DB.BeginTx(ctx, &sql.TxOptions{
+			Isolation: sql.LevelReadCommitted,
+			ReadOnly:  false,
+		})
+		defer func() {
+			if reErr := recover(); reErr != nil {
+				fb.DB.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				fb.DB.Rollback()
+				for _, result := range results {
+					result.File = nil
+				}
+				return
+			}
+			if err = fb.DB.Commit().Error; err == nil {
+				models.NotifyJournal(fb.Token.AppID)
+			}
+		}()
+	}
+
+	if err = models.LockJournalSequence(fb.Token.AppID, fb.DB); err != nil {
+		return results, err
+	}
+
+	if err = fb.Token.UpdateAvailableTimes(-len(fb.Operations), fb.DB); err != nil {
+		return results, err
+	}
+
+	for index, op := range fb.Operations {
+		result := &FileBatchResult{Index: index, Op: op.Op}
+		results = append(results, result)
+		if file, err = fb.executeOperation(op, fb.DB); err != nil {
+			result.Error = err
+			return results, ErrBatchAborted
+		}
+		result.File = file
+	}
+
+	return results, nil
+}
@@ -0,0 +1,115 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBatch_Validate(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		to      = "invalid:path"
+		srv     = &FileBatch{}
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+	srv.DB = trx
+
+	errValidate := srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10050))
+	confirm.True(errValidate.ContainsErrCode(10051))
+
+	srv.Token = token
+	srv.Operations = []*FileBatchOperation{
+		{Op: FileBatchMkdir, Path: "/save/to"},
+		{Op: "unknown", Path: "/save/to"},
+		{Op: FileBatchMove, Path: "/save/to", To: &to},
+		{Op: FileBatchSetHidden, Path: "/save/to"},
+	}
+	errValidate = srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.Equal(3, len(errValidate))
+	confirm.Contains(errValidate.Error(), "operation 1")
+	confirm.Contains(errValidate.Error(), "operation 2")
+	confirm.Contains(errValidate.Error(), "operation 3")
+
+	token.AvailableTimes = 1
+	srv.Operations = srv.Operations[:2]
+	errValidate = srv.Validate()
+	confirm.Contains(errValidate.Error(), ErrTokenAvailableTimesExhausted.Error())
+}
+
+func TestFileBatch_Execute(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		tempDir = models.NewTempDirForTest()
+		hidden  = true
+		moveTo  = "/archive/a"
+		copyTo  = "/copy/a"
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	_, err = models.CreateFileFromReader(
+		&token.App, "/a/1.bytes", bytes.NewReader(models.Random(100)), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+
+	srv := &FileBatch{
+		BaseService: BaseService{DB: trx},
+		Token:       token,
+		Operations: []*FileBatchOperation{
+			{Op: FileBatchMkdir, Path: "/archive"},
+			{Op: FileBatchMove, Path: "/a", To: &moveTo},
+			{Op: FileBatchCopy, Path: "/archive/a", To: &copyTo},
+			{Op: FileBatchSetHidden, Path: "/copy/a/1.bytes", Hidden: &hidden},
+			{Op: FileBatchDelete, Path: "/archive", Force: true},
+		},
+	}
+	confirm.Nil(srv.Validate())
+	value, err := srv.Execute(context.TODO())
+	confirm.Nil(err)
+	results := value.([]*FileBatchResult)
+	confirm.Equal(5, len(results))
+	for _, result := range results {
+		confirm.Nil(result.Error)
+		confirm.NotNil(result.File)
+	}
+	confirm.Equal(models.Hidden, results[3].File.Hidden)
+
+	copied, err := models.FindFileByPath(&token.App, "/copy/a/1.bytes", trx)
+	confirm.Nil(err)
+	confirm.Equal(models.Hidden, copied.Hidden)
+	_, err = models.FindFileByPath(&token.App, "/archive/a/1.bytes", trx)
+	confirm.NotNil(err)
+
+	root, err := models.CreateOrGetRootPath(&token.App, trx)
+	confirm.Nil(err)
+	confirm.Equal(100, root.Size)
+
+	srv.Operations = []*FileBatchOperation{
+		{Op: FileBatchMkdir, Path: "/second"},
+		{Op: FileBatchDelete, Path: "/not/exist"},
+	}
+	value, err = srv.Execute(context.TODO())
+	confirm.Equal(ErrBatchAborted, err)
+	results = value.([]*FileBatchResult)
+	confirm.Equal(2, len(results))
+	confirm.NotNil(results[1].Error)
+}
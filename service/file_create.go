@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	libPath "path"
+	"time"
 
 	"github.com/bigfile/bigfile/databases/models"
 	"github.com/bigfile/bigfile/internal/util"
@@ -39,6 +40,13 @@ type FileCreate struct {
 	Overwrite int8          `validate:"oneof=0 1"`
 	Rename    int8          `validate:"oneof=0 1"`
 	Append    int8          `validate:"oneof=0 1"`
+	ExpiredAt *time.Time    `validate:"omitempty,gt"`
+
+	// IfMatch and IfNoneMatch are compared with the hash of the existing file
+	IfMatch     *string `validate:"omitempty"`
+	IfNoneMatch *string `validate:"omitempty"`
+
+	object *models.Object
 }
 
 // Validate is used to validate params
@@ -73,15 +81,19 @@ func (fc *FileCreate) Validate() ValidateErrors {
 }
 
 // Execute is used to upload file or create directory
-func (fc *FileCreate) Execute(ctx context.Context) (interface{}, error) {
+func (fc *FileCreate) Execute(ctx context.Context) (result interface{}, err error) {
 
 	var (
-		err   error
-		path  = fc.Token.PathWithScope(fc.Path)
 		file  *models.File
 		inTrx = util.InTransaction(fc.DB)
 	)
 
+	if fc.Reader != nil {
+		if fc.object, err = fc.storeReader(); err != nil {
+			return nil, err
+		}
+	}
+
 	if !inTrx {
 		fc.DB = fc.DB.BeginTx(ctx, &sql.TxOptions{
 			Isolation: sql.LevelReadCommitted,
@@ -90,16 +102,66 @@ func (fc *FileCreate) Execute(ctx context.Context) (interface{}, error) {
 		defer func() {
 			if reErr := recover(); reErr != nil {
 				fc.DB.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				fc.DB.Rollback()
+				return
+			}
+			if err = fc.DB.Commit().Error; err == nil {
+				models.NotifyJournal(fc.Token.AppID)
 			}
 		}()
-		defer func() { err = fc.DB.Commit().Error }()
+	}
+
+	if err = models.LockJournalSequence(fc.Token.AppID, fc.DB); err != nil {
+		return nil, err
 	}
 
 	if err = fc.Token.UpdateAvailableTimes(-1, fc.DB); err != nil {
 		return nil, err
 	}
 
-	if fc.Reader == nil {
+	if file, err = fc.execute(); err != nil {
+		return nil, err
+	}
+
+	if fc.ExpiredAt != nil {
+		if err = file.SetExpiredAt(fc.ExpiredAt, fc.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	return file, nil
+}
+
+// storeReader is used to store the upload as an object before the transaction
+// is opened, so the journal sequence lock isn't held while the upload is
+// streaming. Only the stored object is attached to the file in the transaction.
+func (fc *FileCreate) storeReader() (*models.Object, error) {
+	var reader = fc.Reader
+
+	if DefaultRateLimiter().throttles(fc.Token.AppID, fc.Token.ID) {
+		reader = DefaultRateLimiter().ThrottleReader(fc.Reader, fc.Token.AppID, fc.Token.ID)
+	}
+
+	return models.CreateObjectFromReader(reader, fc.RootPath, fc.DB)
+}
+
+// execute is used to create directory, or create and update file by the
+// content of reader
+func (fc *FileCreate) execute() (*models.File, error) {
+	var (
+		err  error
+		file *models.File
+		path = fc.Token.PathWithScope(fc.Path)
+	)
+
+	if err = models.CheckPathLock(&fc.Token.App, path, fc.Token.UID, fc.DB); err != nil {
+		return nil, err
+	}
+
+	if fc.object == nil {
 		return models.CreateOrGetLastDirectory(&fc.Token.App, path, fc.DB)
 	}
 
@@ -107,8 +169,12 @@ func (fc *FileCreate) Execute(ctx context.Context) (interface{}, error) {
 		return nil, err
 	}
 
+	if err = checkPrecondition(file, fc.IfMatch, fc.IfNoneMatch, fc.DB); err != nil {
+		return nil, err
+	}
+
 	if file == nil || file.ID == 0 {
-		return models.CreateFileFromReader(&fc.Token.App, path, fc.Reader, fc.Hidden, fc.RootPath, fc.DB)
+		return models.CreateFileFromObject(&fc.Token.App, path, fc.object, fc.Hidden, fc.DB)
 	}
 
 	if file.DeletedAt != nil && (fc.Append == 1 || fc.Overwrite == 1) {
@@ -116,11 +182,15 @@ func (fc *FileCreate) Execute(ctx context.Context) (interface{}, error) {
 	}
 
 	if fc.Overwrite == 1 {
-		return file, file.OverWriteFromReader(fc.Reader, fc.Hidden, fc.RootPath, fc.DB)
+		return file, file.OverWriteFromObject(fc.object, fc.Hidden, fc.DB)
 	}
 
 	if fc.Append == 1 {
-		return file, file.AppendFromReader(fc.Reader, fc.Hidden, fc.RootPath, fc.DB)
+		var reader io.ReadSeeker
+		if reader, err = fc.object.Reader(fc.RootPath, fc.DB); err != nil {
+			return nil, err
+		}
+		return file, file.AppendFromReader(reader, fc.Hidden, fc.RootPath, fc.DB)
 	}
 
 	if fc.Rename == 1 {
@@ -129,7 +199,7 @@ func (fc *FileCreate) Execute(ctx context.Context) (interface{}, error) {
 			basename = libPath.Base(path)
 		)
 		path = fmt.Sprintf("%s/%s_%s", dir, models.RandomWithMD5(256), basename)
-		return models.CreateFileFromReader(&fc.Token.App, path, fc.Reader, fc.Hidden, fc.RootPath, fc.DB)
+		return models.CreateFileFromObject(&fc.Token.App, path, fc.object, fc.Hidden, fc.DB)
 	}
 
 	return nil, ErrPathExisted
@@ -10,11 +10,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"hash"
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/bigfile/bigfile/config"
 	"github.com/bigfile/bigfile/databases"
 
 	"github.com/bigfile/bigfile/databases/models"
@@ -307,3 +309,65 @@ func TestFileCreate_Execute9(t *testing.T) {
 	_, err = fileCreate.Execute(context.TODO())
 	assert.Equal(t, err, ErrPathExisted)
 }
+
+func TestFileCreate_ExecuteWithExpiredAt(t *testing.T) {
+	var (
+		past   = time.Now().Add(-time.Hour)
+		future = time.Now().Add(time.Hour)
+	)
+	tempDir := models.NewTempDirForTest()
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	fileCreate := &FileCreate{
+		BaseService: BaseService{DB: trx, RootPath: &tempDir},
+		Token:       token,
+		Path:        "/create/a/file.bytes",
+		Reader:      bytes.NewReader(models.Random(333)),
+		ExpiredAt:   &past,
+	}
+	errValidate := fileCreate.Validate()
+	assert.NotNil(t, errValidate)
+	assert.True(t, errValidate.ContainsErrCode(10052))
+
+	fileCreate.ExpiredAt = &future
+	assert.Nil(t, fileCreate.Validate())
+	value, err := fileCreate.Execute(context.TODO())
+	assert.Nil(t, err)
+	file := value.(*models.File)
+	assert.Equal(t, future.Unix(), file.ExpiredAt.Unix())
+}
+
+func TestFileCreate_ExecuteWithBandwidthLimit(t *testing.T) {
+	defer func(limit config.Limit) { config.DefaultConfig.Limit = limit }(config.DefaultConfig.Limit)
+	config.DefaultConfig.Limit = config.Limit{LimitTokenBandwidth: 1 << 20}
+
+	fileCreate, down := newFileCreateForTest(t, "/test")
+	defer down(t)
+	content := models.Random(333)
+	fileCreate.Path = "/create/a/throttled.bytes"
+	fileCreate.Reader = bytes.NewReader(content)
+	assert.Nil(t, fileCreate.Validate())
+	value, err := fileCreate.Execute(context.TODO())
+	assert.Nil(t, err)
+	file := value.(*models.File)
+	assert.Equal(t, len(content), file.Size)
+
+	fileCreate.Append = 1
+	fileCreate.Reader = bytes.NewReader(content)
+	value, err = fileCreate.Execute(context.TODO())
+	assert.Nil(t, err)
+	file = value.(*models.File)
+	assert.Equal(t, 2*len(content), file.Size)
+	reader, err := file.Reader(fileCreate.RootPath, fileCreate.DB)
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, append(content, content...), data)
+}
@@ -21,6 +21,10 @@ type FileDelete struct {
 	File  *models.File  `validate:"required"`
 	Force *bool         `validate:"omitempty"`
 	IP    *string       `validate:"omitempty"`
+
+	// IfMatch and IfNoneMatch are compared with the hash of file
+	IfMatch     *string `validate:"omitempty"`
+	IfNoneMatch *string `validate:"omitempty"`
 }
 
 // Validate is used to validate service params
@@ -35,7 +39,7 @@ func (fd *FileDelete) Validate() ValidateErrors {
 		}
 	}
 
-	if err = ValidateToken(fd.DB, fd.IP, true, fd.Token); err != nil {
+	if err = ValidateToken(fd.DB, fd.IP, false, fd.Token); err != nil {
 		validateErrors = append(validateErrors, generateErrorByField("FileDelete.Token", err))
 	}
 
@@ -51,10 +55,9 @@ func (fd *FileDelete) Validate() ValidateErrors {
 }
 
 // Execute is used to provide file delete service
-func (fd *FileDelete) Execute(ctx context.Context) (interface{}, error) {
+func (fd *FileDelete) Execute(ctx context.Context) (result interface{}, err error) {
 	var (
 		falseValue = false
-		err        error
 		inTrx      = util.InTransaction(fd.DB)
 	)
 
@@ -66,15 +69,34 @@ func (fd *FileDelete) Execute(ctx context.Context) (interface{}, error) {
 		defer func() {
 			if reErr := recover(); reErr != nil {
 				fd.DB.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				fd.DB.Rollback()
+				return
+			}
+			if err = fd.DB.Commit().Error; err == nil {
+				models.NotifyJournal(fd.Token.AppID)
 			}
 		}()
-		defer func() { err = fd.DB.Commit().Error }()
+	}
+
+	if err = models.LockJournalSequence(fd.Token.AppID, fd.DB); err != nil {
+		return nil, err
 	}
 
 	if err = fd.Token.UpdateAvailableTimes(-1, fd.DB); err != nil {
 		return nil, err
 	}
 
+	if err = fd.File.CheckLock(fd.Token.UID, fd.DB); err != nil {
+		return nil, err
+	}
+
+	if err = checkPrecondition(fd.File, fd.IfMatch, fd.IfNoneMatch, fd.DB); err != nil {
+		return nil, err
+	}
+
 	if fd.Force == nil {
 		fd.Force = &falseValue
 	}
@@ -0,0 +1,157 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/log"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// FileExpireSweep is used to delete the files that have expired. It's called
+// periodically by the background sweeper, not by the users.
+type FileExpireSweep struct {
+	BaseService
+
+	Limit int `validate:"required,min=1,max=1000"`
+}
+
+// Validate is used to validate service params
+func (fes *FileExpireSweep) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(fes); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	return validateErrors
+}
+
+// deleteExpiredFile is used to delete an expired file in its own transaction,
+// so that a failed one doesn't roll back the others. The file may have been
+// deleted along with its ancestor directory, be locked exclusively, or be
+// protected by retention or legal hold, it's skipped then.
+func (fes *FileExpireSweep) deleteExpiredFile(ctx context.Context, expired *models.File) (deleted bool, err error) {
+	var (
+		db    = fes.DB
+		file  *models.File
+		inTrx = util.InTransaction(db)
+	)
+
+	if !inTrx {
+		db = db.BeginTx(ctx, &sql.TxOptions{
+			Isolation: sql.LevelReadCommitted,
+			ReadOnly:  false,
+		})
+		defer func() {
+			if reErr := recover(); reErr != nil {
+				db.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				db.Rollback()
+				return
+			}
+			if err = db.Commit().Error; err == nil {
+				models.NotifyJournal(expired.AppID)
+			}
+		}()
+	}
+
+	if err = models.LockJournalSequence(expired.AppID, db); err != nil {
+		return false, err
+	}
+
+	if file, err = models.FindFileByUID(expired.UID, false, db.Set("gorm:query_option", "FOR UPDATE")); err != nil {
+		if util.IsRecordNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// the file that is leased by a lock isn't deleted until the lock expires
+	if err = file.CheckLock("", db); err != nil {
+		if err == models.ErrFileLocked {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err = file.Delete(true, db); err == models.ErrFileUnderRetention || err == models.ErrFileUnderLegalHold {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Execute is used to delete the expired files, at most Limit files are
+// deleted, the number of deleted files is returned. The expired files are
+// paged by id, so the skipped ones don't take the place of the others.
+func (fes *FileExpireSweep) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err     error
+		deleted bool
+		count   int
+		afterID uint64
+		files   []models.File
+	)
+
+	for count < fes.Limit {
+		if files, err = models.FindExpiredFiles(afterID, fes.Limit, fes.DB); err != nil {
+			return count, err
+		}
+
+		for index := 0; index < len(files) && count < fes.Limit; index++ {
+			if deleted, err = fes.deleteExpiredFile(ctx, &files[index]); err != nil {
+				return count, err
+			}
+			if deleted {
+				count++
+			}
+		}
+
+		if len(files) < fes.Limit {
+			break
+		}
+		afterID = files[len(files)-1].ID
+	}
+
+	return count, nil
+}
+
+// SweepExpiredFiles is used to delete expired files every interval until stop
+// is closed, at most limit files are deleted in one round.
+func SweepExpiredFiles(db *gorm.DB, interval time.Duration, limit int, stop <-chan struct{}) {
+	var (
+		ticker = time.NewTicker(interval)
+		logger = log.MustNewLogger(nil)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			sweepSrv := &FileExpireSweep{BaseService: BaseService{DB: db}, Limit: limit}
+			if err := sweepSrv.Validate(); err != nil {
+				logger.Error(err)
+				return
+			}
+			count, err := sweepSrv.Execute(context.Background())
+			if err != nil {
+				logger.Errorf("sweep expired files failed: %s", err)
+			}
+			if count.(int) > 0 {
+				logger.Debugf("%d expired files have been deleted", count)
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileExpireSweep_Validate(t *testing.T) {
+	srv := &FileExpireSweep{Limit: 0}
+	err := srv.Validate()
+	assert.NotNil(t, err)
+	assert.True(t, err.ContainsErrCode(10054))
+
+	srv.Limit = 100
+	assert.Nil(t, srv.Validate())
+}
+
+func TestFileExpireSweep_Execute(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		tempDir = models.NewTempDirForTest()
+		past    = time.Now().Add(-time.Second)
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	for _, p := range []string{"/save/a.bytes", "/save/to/b.bytes", "/keep/c.bytes"} {
+		_, err = models.CreateFileFromReader(
+			&token.App, p, bytes.NewReader(models.Random(100)), int8(0), &tempDir, trx)
+		confirm.Nil(err)
+	}
+	save, err := models.FindFileByPath(&token.App, "/save", trx)
+	confirm.Nil(err)
+	confirm.Nil(save.SetExpiredAt(&past, trx))
+	nested, err := models.FindFileByPath(&token.App, "/save/to/b.bytes", trx)
+	confirm.Nil(err)
+	confirm.Nil(nested.SetExpiredAt(&past, trx))
+
+	srv := &FileExpireSweep{BaseService: BaseService{DB: trx}, Limit: 100}
+	confirm.Nil(srv.Validate())
+	count, err := srv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(1, count.(int))
+
+	_, err = models.FindFileByPath(&token.App, "/save/to/b.bytes", trx)
+	confirm.True(util.IsRecordNotFound(err))
+	root, err := models.CreateOrGetRootPath(&token.App, trx)
+	confirm.Nil(err)
+	confirm.Equal(100, root.Size)
+
+	count, err = srv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(0, count.(int))
+}
+
+func TestFileExpireSweep_ExecuteSkipProtected(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		tempDir = models.NewTempDirForTest()
+		past    = time.Now().Add(-time.Second)
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	for _, p := range []string{"/held/a.bytes", "/held/b.bytes", "/free/c.bytes"} {
+		file, err := models.CreateFileFromReader(
+			&token.App, p, bytes.NewReader(models.Random(100)), int8(0), &tempDir, trx)
+		confirm.Nil(err)
+		confirm.Nil(file.SetExpiredAt(&past, trx))
+	}
+	held, err := models.FindFileByPath(&token.App, "/held", trx)
+	confirm.Nil(err)
+	confirm.Nil(held.SetLegalHold(true, trx))
+
+	// the files under the held directory are skipped in every round, the
+	// later ones are still deleted
+	srv := &FileExpireSweep{BaseService: BaseService{DB: trx}, Limit: 1}
+	confirm.Nil(srv.Validate())
+	count, err := srv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(1, count.(int))
+	_, err = models.FindFileByPath(&token.App, "/free/c.bytes", trx)
+	confirm.True(util.IsRecordNotFound(err))
+	_, err = models.FindFileByPath(&token.App, "/held/a.bytes", trx)
+	confirm.Nil(err)
+
+	count, err = srv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(0, count.(int))
+}
+
+func TestFileExpireSweep_ExecuteSkipLeased(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		tempDir = models.NewTempDirForTest()
+		past    = time.Now().Add(-time.Second)
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := models.CreateFileFromReader(
+		&token.App, "/leased/a.bytes", bytes.NewReader(models.Random(100)), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+	confirm.Nil(file.SetExpiredAt(&past, trx))
+	_, err = models.AcquireFileLock(file, token.UID, true, time.Minute, trx)
+	confirm.Nil(err)
+
+	srv := &FileExpireSweep{BaseService: BaseService{DB: trx}, Limit: 10}
+	confirm.Nil(srv.Validate())
+	count, err := srv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(0, count.(int))
+	_, err = models.FindFileByPath(&token.App, "/leased/a.bytes", trx)
+	confirm.Nil(err)
+}
+
+func TestFileExpire_Hidden(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		tempDir = models.NewTempDirForTest()
+		past    = time.Now().Add(-time.Second)
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	expired, err := models.CreateFileFromReader(
+		&token.App, "/save/a.bytes", bytes.NewReader(models.Random(100)), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+	_, err = models.CreateFileFromReader(
+		&token.App, "/save/b.bytes", bytes.NewReader(models.Random(100)), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+	confirm.Nil(expired.SetExpiredAt(&past, trx))
+
+	listSrv := &DirectoryList{
+		BaseService: BaseService{DB: trx},
+		Token:       token,
+		SubDir:      "/save",
+		Sort:        "-type",
+		Limit:       10,
+	}
+	value, err := listSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	listResp := value.(*DirectoryListResponse)
+	confirm.Equal(1, listResp.Total)
+	confirm.Equal("b.bytes", listResp.Files[0].Name)
+
+	readSrv := &FileRead{
+		BaseService: BaseService{DB: trx, RootPath: &tempDir},
+		Token:       token,
+		File:        expired,
+	}
+	_, err = readSrv.Execute(context.TODO())
+	confirm.Equal(models.ErrFileExpired, err)
+}
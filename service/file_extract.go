@@ -0,0 +1,430 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const (
+	// ArchiveTar represent the uncompressed tar archive format
+	ArchiveTar = "tar"
+
+	// DefaultExtractMaxEntries is the default max count of entries of archive
+	DefaultExtractMaxEntries = 10000
+	// DefaultExtractMaxSize is the default max total size of extracted files,
+	// it's also the max value of FileExtract.MaxSize
+	DefaultExtractMaxSize = int64(1 << 30)
+)
+
+var (
+	// ErrUnknownArchiveFormat represent that the format of archive can't be
+	// detected by the name of file
+	ErrUnknownArchiveFormat = errors.New("unknown archive format")
+	// ErrUnsafeArchiveEntry represent that the entry of archive points to
+	// somewhere outside of the target directory
+	ErrUnsafeArchiveEntry = errors.New("the path of entry is unsafe")
+	// ErrUnsupportedArchiveEntry represent that the entry is neither a regular
+	// file nor a directory, such as symbolic link and device
+	ErrUnsupportedArchiveEntry = errors.New("only regular file and directory are supported")
+	// ErrArchiveTooManyEntries represent that the count of entries exceeds the limit
+	ErrArchiveTooManyEntries = errors.New("the count of entries exceeds the limit")
+	// ErrArchiveTooLarge represent that the total size of extracted files
+	// exceeds the limit
+	ErrArchiveTooLarge = errors.New("the total size of extracted files exceeds the limit")
+)
+
+// FileExtractResult represent the result of an entry of archive. File is nil
+// when the entry is skipped or the extraction has been rolled back.
+type FileExtractResult struct {
+	Name  string
+	Path  string
+	File  *models.File
+	Error error
+}
+
+// FileExtract is used to unpack an archive that has been stored into target
+// directory. The entries that are unsafe or conflicted are skipped and
+// reported, but exceeding the limits aborts the whole extraction. Every file
+// extracted costs one available times of token.
+type FileExtract struct {
+	BaseService
+
+	Token      *models.Token `validate:"required"`
+	File       *models.File  `validate:"required"`
+	IP         *string       `validate:"omitempty"`
+	Target     string        `validate:"omitempty,max=1000"`
+	Format     string        `validate:"omitempty,oneof=zip tar tar.gz"`
+	Overwrite  int8          `validate:"oneof=0 1"`
+	MaxEntries int           `validate:"omitempty,min=1,max=100000"`
+	MaxSize    int64         `validate:"omitempty,min=1,max=1073741824"`
+}
+
+// Validate is used to validate service params
+func (fe *FileExtract) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(fe); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(fe.DB, fe.IP, false, fe.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileExtract.Token", err))
+	}
+
+	if err = ValidateFile(fe.DB, fe.File); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileExtract.File", err))
+	} else {
+		if fe.Token != nil {
+			if err = fe.File.CanBeAccessedByToken(fe.Token, fe.DB); err != nil {
+				validateErrors = append(validateErrors, generateErrorByField("FileExtract.Token", err))
+			}
+		}
+		if fe.File.IsDir == models.IsDir {
+			validateErrors = append(validateErrors, generateErrorByField("FileExtract.File", models.ErrReadDir))
+		} else if fe.Format == "" && detectArchiveFormat(fe.File.Name) == "" {
+			validateErrors = append(validateErrors, generateErrorByField("FileExtract.Format", ErrUnknownArchiveFormat))
+		}
+	}
+
+	if !ValidatePath(fe.Target) {
+		validateErrors = append(validateErrors, generateErrorByField("FileExtract.Target", ErrInvalidPath))
+	}
+
+	return validateErrors
+}
+
+// detectArchiveFormat is used to detect the format of archive by its name
+func detectArchiveFormat(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return ArchiveZip
+	case strings.HasSuffix(name, ".tar"):
+		return ArchiveTar
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ArchiveTarGz
+	}
+	return ""
+}
+
+// cleanArchiveEntryName is used to convert the name of entry to a relative
+// path, the names that try to escape the target directory are refused
+func cleanArchiveEntryName(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, `\`) {
+		return "", ErrUnsafeArchiveEntry
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(name, "/"), "/") {
+		if part == ".." {
+			return "", ErrUnsafeArchiveEntry
+		}
+	}
+	name = path.Clean(name)
+	if name == "." || !ValidatePath(name) {
+		return "", ErrUnsafeArchiveEntry
+	}
+	return name, nil
+}
+
+// archiveEntry represent an entry of archive while extracting
+type archiveEntry struct {
+	name  string
+	isDir bool
+	// unsupported is true when the entry is neither a file nor a directory
+	unsupported bool
+	open        func() (io.ReadCloser, error)
+}
+
+// readerAt is used to adapt the reader of file to io.ReaderAt, which is
+// required by zip reader
+type readerAt struct {
+	sync.Mutex
+	reader io.ReadSeeker
+}
+
+func (r *readerAt) ReadAt(p []byte, off int64) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+	if _, err := r.reader.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(r.reader, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// walkZip calls fn for every entry of zip archive
+func walkZip(reader io.ReadSeeker, size int64, maxEntries int, fn func(*archiveEntry) error) error {
+	zipReader, err := zip.NewReader(&readerAt{reader: reader}, size)
+	if err != nil {
+		return err
+	}
+	if len(zipReader.File) > maxEntries {
+		return ErrArchiveTooManyEntries
+	}
+	for _, zipFile := range zipReader.File {
+		mode := zipFile.Mode()
+		if err = fn(&archiveEntry{
+			name:        zipFile.Name,
+			isDir:       mode.IsDir(),
+			unsupported: !mode.IsDir() && !mode.IsRegular(),
+			open:        zipFile.Open,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkTar calls fn for every entry of tar archive
+func walkTar(reader io.Reader, gzipped bool, fn func(*archiveEntry) error) error {
+	if gzipped {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(&archiveEntry{
+			name:        header.Name,
+			isDir:       header.Typeflag == tar.TypeDir,
+			unsupported: header.Typeflag != tar.TypeDir && header.Typeflag != tar.TypeReg,
+			open: func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(tarReader), nil
+			},
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// limitedReader returns ErrArchiveTooLarge once the total size read exceeds
+// the limit, the size declared in archive isn't trusted
+type limitedReader struct {
+	reader    io.Reader
+	remaining *int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	if *l.remaining -= int64(n); *l.remaining < 0 {
+		return n, ErrArchiveTooLarge
+	}
+	return n, err
+}
+
+// extractedEntry represent an entry that has been read from archive, the
+// content of file is stored as object before the transaction is opened
+type extractedEntry struct {
+	result *FileExtractResult
+	isDir  bool
+	object *models.Object
+}
+
+// storeEntry is used to store the content of file entry as an object
+func (fe *FileExtract) storeEntry(entry *archiveEntry, remaining *int64) (*models.Object, error) {
+	content, err := entry.open()
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+	return models.CreateObjectFromReader(&limitedReader{reader: content, remaining: remaining}, fe.RootPath, fe.DB)
+}
+
+// extractEntry is used to save an entry into target directory
+func (fe *FileExtract) extractEntry(entry *extractedEntry) (*models.File, error) {
+	var (
+		err   error
+		file  *models.File
+		app   = &fe.Token.App
+		owner = fe.Token.UID
+		p     = entry.result.Path
+	)
+
+	if err = models.CheckPathLock(app, p, owner, fe.DB); err != nil {
+		return nil, err
+	}
+
+	if entry.isDir {
+		return models.CreateOrGetLastDirectory(app, p, fe.DB)
+	}
+
+	if file, err = models.FindFileByPathWithTrashed(app, p, fe.DB); err != nil && !util.IsRecordNotFound(err) {
+		return nil, err
+	}
+	if file != nil && file.ID > 0 && (fe.Overwrite == 0 || file.DeletedAt != nil || file.IsDir == models.IsDir) {
+		return nil, ErrPathExisted
+	}
+
+	if file == nil || file.ID == 0 {
+		return models.CreateFileFromObject(app, p, entry.object, int8(0), fe.DB)
+	}
+	return file, file.OverWriteFromObject(entry.object, file.Hidden, fe.DB)
+}
+
+// read is used to walk the archive and store the content of files, it runs
+// before the transaction so that the journal sequence lock isn't held while
+// the archive is being read
+func (fe *FileExtract) read(ctx context.Context, results *[]*FileExtractResult) (entries []*extractedEntry, err error) {
+	var (
+		reader     io.ReadSeeker
+		expired    bool
+		format     = fe.Format
+		target     = fe.Token.PathWithScope(fe.Target)
+		maxEntries = fe.MaxEntries
+		remaining  = fe.MaxSize
+		files      int
+	)
+
+	if format == "" {
+		format = detectArchiveFormat(fe.File.Name)
+	}
+	if maxEntries == 0 {
+		maxEntries = DefaultExtractMaxEntries
+	}
+	if remaining == 0 {
+		remaining = DefaultExtractMaxSize
+	}
+
+	if fe.File.Hidden == models.Hidden {
+		return nil, ErrReadHiddenFile
+	}
+	if expired, err = fe.File.IsExpired(fe.DB); err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, models.ErrFileExpired
+	}
+	if reader, err = fe.File.Reader(fe.RootPath, fe.DB); err != nil {
+		return nil, err
+	}
+
+	extract := func(entry *archiveEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(*results) >= maxEntries {
+			return ErrArchiveTooManyEntries
+		}
+		result := &FileExtractResult{Name: entry.name}
+		*results = append(*results, result)
+		name, err := cleanArchiveEntryName(entry.name)
+		if err != nil {
+			result.Error = err
+			return nil
+		}
+		result.Path = path.Join(target, name)
+		if entry.unsupported {
+			result.Error = ErrUnsupportedArchiveEntry
+			return nil
+		}
+		extracted := &extractedEntry{result: result, isDir: entry.isDir}
+		if !entry.isDir {
+			if fe.Token.AvailableTimes != -1 && fe.Token.AvailableTimes <= files {
+				return ErrTokenAvailableTimesExhausted
+			}
+			if extracted.object, result.Error = fe.storeEntry(entry, &remaining); result.Error != nil {
+				if result.Error == ErrArchiveTooLarge {
+					return ErrArchiveTooLarge
+				}
+				return nil
+			}
+			files++
+		}
+		entries = append(entries, extracted)
+		return nil
+	}
+
+	if format == ArchiveZip {
+		err = walkZip(reader, int64(fe.File.Size), maxEntries, extract)
+	} else {
+		err = walkTar(reader, format == ArchiveTarGz, extract)
+	}
+
+	return entries, err
+}
+
+// Execute is used to extract the archive in one transaction, the results are
+// returned even if the extraction is aborted
+func (fe *FileExtract) Execute(ctx context.Context) (result interface{}, err error) {
+	var (
+		count   int
+		entries []*extractedEntry
+		results []*FileExtractResult
+		inTrx   = util.InTransaction(fe.DB)
+	)
+
+	if entries, err = fe.read(ctx, &results); err != nil {
+		return results, err
+	}
+
+	if !inTrx {
+		fe.DB = fe.DB.BeginTx(ctx, &sql.TxOptions{
+			Isolation: sql.LevelReadCommitted,
+			ReadOnly:  false,
+		})
+		defer func() {
+			if reErr := recover(); reErr != nil {
+				fe.DB.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				fe.DB.Rollback()
+				for _, result := range results {
+					result.File = nil
+				}
+				return
+			}
+			if err = fe.DB.Commit().Error; err == nil {
+				models.NotifyJournal(fe.Token.AppID)
+			}
+		}()
+	}
+
+	if err = models.LockJournalSequence(fe.Token.AppID, fe.DB); err != nil {
+		return results, err
+	}
+
+	for _, entry := range entries {
+		if !entry.isDir && fe.Token.AvailableTimes != -1 && fe.Token.AvailableTimes <= count {
+			return results, ErrTokenAvailableTimesExhausted
+		}
+		if entry.result.File, entry.result.Error = fe.extractEntry(entry); !entry.isDir && entry.result.Error == nil {
+			count++
+		}
+	}
+
+	return results, fe.Token.UpdateAvailableTimes(-count, fe.DB)
+}
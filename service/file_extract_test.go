@@ -0,0 +1,220 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanArchiveEntryName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"a.txt":           "a.txt",
+		"dir/":            "dir",
+		"dir/./a.txt":     "dir/a.txt",
+		"../a.txt":        "",
+		"dir/../../a.txt": "",
+		"/etc/passwd":     "",
+		`dir\a.txt`:       "",
+		"":                "",
+	} {
+		cleaned, err := cleanArchiveEntryName(name)
+		assert.Equal(t, expected, cleaned)
+		if expected == "" {
+			assert.Equal(t, ErrUnsafeArchiveEntry, err)
+		}
+	}
+}
+
+func TestDetectArchiveFormat(t *testing.T) {
+	assert.Equal(t, ArchiveZip, detectArchiveFormat("a.ZIP"))
+	assert.Equal(t, ArchiveTar, detectArchiveFormat("a.tar"))
+	assert.Equal(t, ArchiveTarGz, detectArchiveFormat("a.tar.gz"))
+	assert.Equal(t, ArchiveTarGz, detectArchiveFormat("a.tgz"))
+	assert.Equal(t, "", detectArchiveFormat("a.rar"))
+}
+
+func newZipForTest(t *testing.T, entries map[string]string) []byte {
+	var (
+		buf    = new(bytes.Buffer)
+		writer = zip.NewWriter(buf)
+	)
+	for name, content := range entries {
+		w, err := writer.Create(name)
+		assert.Nil(t, err)
+		_, err = w.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestFileExtract_Validate(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		srv     = &FileExtract{Format: "rar", Overwrite: 2, MaxEntries: -1}
+		tempDir = models.NewTempDirForTest()
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	srv.DB = trx
+
+	errValidate := srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10065))
+	confirm.True(errValidate.ContainsErrCode(10066))
+	confirm.True(errValidate.ContainsErrCode(10068))
+	confirm.True(errValidate.ContainsErrCode(10069))
+	confirm.True(errValidate.ContainsErrCode(10070))
+
+	file, err := models.CreateFileFromReader(
+		&token.App, "/upload/bundle.rar", bytes.NewReader(models.Random(16)), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+	srv = &FileExtract{BaseService: BaseService{DB: trx}, Token: token, File: file}
+	errValidate = srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.Contains(errValidate.Error(), ErrUnknownArchiveFormat.Error())
+
+	srv.Format = ArchiveZip
+	confirm.Nil(srv.Validate())
+}
+
+func TestFileExtract_Execute(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		tempDir = models.NewTempDirForTest()
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	archive := newZipForTest(t, map[string]string{
+		"dir/":        "",
+		"dir/a.txt":   "a",
+		"b.txt":       "b",
+		"../evil.txt": "evil",
+	})
+	file, err := models.CreateFileFromReader(
+		&token.App, "/upload/bundle.zip", bytes.NewReader(archive), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+	_, err = models.CreateFileFromReader(&token.App, "/out/b.txt", bytes.NewReader([]byte("old")), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+
+	srv := &FileExtract{
+		BaseService: BaseService{DB: trx, RootPath: &tempDir},
+		Token:       token,
+		File:        file,
+		Target:      "/out",
+	}
+	confirm.Nil(srv.Validate())
+	resultsValue, err := srv.Execute(context.TODO())
+	confirm.Nil(err)
+	results := map[string]*FileExtractResult{}
+	for _, result := range resultsValue.([]*FileExtractResult) {
+		results[result.Name] = result
+	}
+	confirm.Equal(4, len(results))
+	confirm.Nil(results["dir/"].Error)
+	confirm.Nil(results["dir/a.txt"].Error)
+	confirm.Equal("/out/dir/a.txt", results["dir/a.txt"].Path)
+	confirm.Equal(ErrPathExisted, results["b.txt"].Error)
+	confirm.Equal(ErrUnsafeArchiveEntry, results["../evil.txt"].Error)
+
+	extracted, err := models.FindFileByPath(&token.App, "/out/dir/a.txt", trx)
+	confirm.Nil(err)
+	reader, err := extracted.Reader(&tempDir, trx)
+	confirm.Nil(err)
+	content := new(bytes.Buffer)
+	_, err = content.ReadFrom(reader)
+	confirm.Nil(err)
+	confirm.Equal("a", content.String())
+
+	srv.Overwrite = 1
+	resultsValue, err = srv.Execute(context.TODO())
+	confirm.Nil(err)
+	for _, result := range resultsValue.([]*FileExtractResult) {
+		if result.Name == "b.txt" {
+			confirm.Nil(result.Error)
+			confirm.Equal(1, result.File.Size)
+		}
+	}
+
+	srv.MaxEntries = 2
+	_, err = srv.Execute(context.TODO())
+	confirm.Equal(ErrArchiveTooManyEntries, err)
+}
+
+func TestFileExtract_Execute2(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		tempDir = models.NewTempDirForTest()
+		buf     = new(bytes.Buffer)
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	confirm.Nil(tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "link", Linkname: "/etc/passwd"}))
+	confirm.Nil(tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "big.bytes", Size: 1024, Mode: 0644}))
+	_, err = tarWriter.Write(models.Random(1024))
+	confirm.Nil(err)
+	confirm.Nil(tarWriter.Close())
+	confirm.Nil(gzipWriter.Close())
+
+	file, err := models.CreateFileFromReader(
+		&token.App, "/upload/bundle.tgz", bytes.NewReader(buf.Bytes()), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+
+	srv := &FileExtract{
+		BaseService: BaseService{DB: trx, RootPath: &tempDir},
+		Token:       token,
+		File:        file,
+		Target:      "/out",
+		MaxSize:     DefaultExtractMaxSize + 1,
+	}
+	confirm.True(srv.Validate().ContainsErrCode(10071))
+
+	srv.MaxSize = 512
+	confirm.Nil(srv.Validate())
+	resultsValue, err := srv.Execute(context.TODO())
+	confirm.Equal(ErrArchiveTooLarge, err)
+	results := resultsValue.([]*FileExtractResult)
+	confirm.Equal(2, len(results))
+	confirm.Equal(ErrUnsupportedArchiveEntry, results[0].Error)
+
+	srv.MaxSize = 0
+	resultsValue, err = srv.Execute(context.TODO())
+	confirm.Nil(err)
+	results = resultsValue.([]*FileExtractResult)
+	confirm.Nil(results[1].Error)
+	confirm.Equal(1024, results[1].File.Size)
+}
@@ -0,0 +1,187 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// ErrInvalidFileLock represent the file lock is invalid
+var ErrInvalidFileLock = errors.New("invalid file lock")
+
+// validateFileLock is used to validate whether the lock is valid and can be used by token
+func validateFileLock(lock *models.FileLock, token *models.Token) error {
+	if lock == nil || lock.ID == 0 {
+		return ErrInvalidFileLock
+	}
+	if lock.AppID != token.AppID {
+		return models.ErrAccessDenied
+	}
+	return nil
+}
+
+// FileLockAcquire is used to lock a file or a directory for a while
+type FileLockAcquire struct {
+	BaseService
+
+	Token     *models.Token `validate:"required"`
+	File      *models.File  `validate:"required"`
+	IP        *string       `validate:"omitempty"`
+	Exclusive int8          `validate:"oneof=0 1"`
+	TTL       int           `validate:"required,min=1,max=86400"`
+}
+
+// Validate is used to validate service params
+func (fla *FileLockAcquire) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(fla); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(fla.DB, fla.IP, false, fla.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileLockAcquire.Token", err))
+	}
+
+	if err = ValidateFile(fla.DB, fla.File); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileLockAcquire.File", err))
+	} else {
+		if err = fla.File.CanBeAccessedByToken(fla.Token, fla.DB); err != nil {
+			validateErrors = append(validateErrors, generateErrorByField("FileLockAcquire.Token", err))
+		}
+	}
+
+	return validateErrors
+}
+
+// Execute is used to acquire the lock, the owner of lock is the token
+func (fla *FileLockAcquire) Execute(ctx context.Context) (result interface{}, err error) {
+	var (
+		inTrx = util.InTransaction(fla.DB)
+	)
+
+	if !inTrx {
+		fla.DB = fla.DB.BeginTx(ctx, &sql.TxOptions{
+			Isolation: sql.LevelReadCommitted,
+			ReadOnly:  false,
+		})
+		defer func() {
+			if reErr := recover(); reErr != nil {
+				fla.DB.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				fla.DB.Rollback()
+				return
+			}
+			err = fla.DB.Commit().Error
+		}()
+	}
+
+	if err = models.LockJournalSequence(fla.Token.AppID, fla.DB); err != nil {
+		return nil, err
+	}
+
+	if err = fla.Token.UpdateAvailableTimes(-1, fla.DB); err != nil {
+		return nil, err
+	}
+
+	return models.AcquireFileLock(
+		fla.File, fla.Token.UID, fla.Exclusive == 1, time.Duration(fla.TTL)*time.Second, fla.DB)
+}
+
+// FileLockRefresh is used to extend the lease of lock
+type FileLockRefresh struct {
+	BaseService
+
+	Token *models.Token    `validate:"required"`
+	Lock  *models.FileLock `validate:"required"`
+	IP    *string          `validate:"omitempty"`
+	TTL   int              `validate:"required,min=1,max=86400"`
+}
+
+// Validate is used to validate service params
+func (flr *FileLockRefresh) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(flr); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(flr.DB, flr.IP, false, flr.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileLockRefresh.Token", err))
+	} else if err = validateFileLock(flr.Lock, flr.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileLockRefresh.Lock", err))
+	}
+
+	return validateErrors
+}
+
+// Execute is used to refresh the lock
+func (flr *FileLockRefresh) Execute(ctx context.Context) (interface{}, error) {
+	var err error
+
+	if err = flr.Token.UpdateAvailableTimes(-1, flr.DB); err != nil {
+		return nil, err
+	}
+
+	return flr.Lock, flr.Lock.Refresh(flr.Token.UID, time.Duration(flr.TTL)*time.Second, flr.DB)
+}
+
+// FileLockRelease is used to release the lock
+type FileLockRelease struct {
+	BaseService
+
+	Token *models.Token    `validate:"required"`
+	Lock  *models.FileLock `validate:"required"`
+	IP    *string          `validate:"omitempty"`
+}
+
+// Validate is used to validate service params
+func (flr *FileLockRelease) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(flr); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(flr.DB, flr.IP, false, flr.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileLockRelease.Token", err))
+	} else if err = validateFileLock(flr.Lock, flr.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileLockRelease.Lock", err))
+	}
+
+	return validateErrors
+}
+
+// Execute is used to release the lock
+func (flr *FileLockRelease) Execute(ctx context.Context) (interface{}, error) {
+	var err error
+
+	if err = flr.Token.UpdateAvailableTimes(-1, flr.DB); err != nil {
+		return nil, err
+	}
+
+	return flr.Lock, flr.Lock.Release(flr.Token.UID, flr.DB)
+}
@@ -0,0 +1,159 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLockAcquire_Validate(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		srv     = &FileLockAcquire{Exclusive: 2}
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+	srv.DB = trx
+
+	errValidate := srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10041))
+	confirm.True(errValidate.ContainsErrCode(10042))
+	confirm.True(errValidate.ContainsErrCode(10043))
+	confirm.True(errValidate.ContainsErrCode(10044))
+
+	token.Path = "/test"
+	confirm.Nil(trx.Save(token).Error)
+	dir, err := models.CreateOrGetLastDirectory(&token.App, "/save/to", trx)
+	confirm.Nil(err)
+
+	srv.Token = token
+	srv.File = dir
+	srv.Exclusive = 1
+	srv.TTL = 60
+	errValidate = srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.Contains(errValidate.Error(), models.ErrAccessDenied.Error())
+}
+
+func TestFileLock_Execute(t *testing.T) {
+	var confirm = assert.New(t)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+
+	dir, err := models.CreateOrGetLastDirectory(&token.App, "/save/to", trx)
+	confirm.Nil(err)
+
+	acquireSrv := &FileLockAcquire{
+		BaseService: BaseService{DB: trx},
+		Token:       token,
+		File:        dir,
+		Exclusive:   1,
+		TTL:         60,
+	}
+	confirm.Nil(acquireSrv.Validate())
+	lockValue, err := acquireSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	lock := lockValue.(*models.FileLock)
+	confirm.Equal(token.UID, lock.Owner)
+	confirm.Equal(int8(1), lock.Exclusive)
+
+	refreshSrv := &FileLockRefresh{
+		BaseService: BaseService{DB: trx},
+		Token:       token,
+		Lock:        lock,
+		TTL:         3600,
+	}
+	confirm.Nil(refreshSrv.Validate())
+	_, err = refreshSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.True(lock.ExpiredAt.After(time.Now().Add(time.Minute)))
+
+	releaseSrv := &FileLockRelease{
+		BaseService: BaseService{DB: trx},
+		Token:       token,
+		Lock:        lock,
+	}
+	confirm.Nil(releaseSrv.Validate())
+	_, err = releaseSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	locks, err := dir.Locks(trx)
+	confirm.Nil(err)
+	confirm.Empty(locks)
+
+	releaseSrv.Lock = &models.FileLock{}
+	errValidate := releaseSrv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10049))
+}
+
+// TestFileLock_Honoured is used to test that the exclusive lock of one token
+// forbids other tokens to modify the locked file
+func TestFileLock_Honoured(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		tempDir = models.NewTempDirForTest()
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	other, err := models.NewToken(&token.App, "/", nil, nil, nil, -1, int8(0), trx)
+	confirm.Nil(err)
+
+	file, err := models.CreateFileFromReader(
+		&token.App, "/save/to/random.bytes", bytes.NewReader(models.Random(256)), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+	_, err = models.AcquireFileLock(file, token.UID, true, time.Minute, trx)
+	confirm.Nil(err)
+
+	createSrv := &FileCreate{
+		BaseService: BaseService{DB: trx, RootPath: &tempDir},
+		Token:       other,
+		Path:        "/save/to/random.bytes",
+		Reader:      bytes.NewReader(models.Random(256)),
+		Overwrite:   1,
+	}
+	_, err = createSrv.Execute(context.TODO())
+	confirm.Equal(models.ErrFileLocked, err)
+
+	path := "/save/to/moved.bytes"
+	updateSrv := &FileUpdate{
+		BaseService: BaseService{DB: trx},
+		Token:       other,
+		File:        file,
+		Path:        &path,
+	}
+	_, err = updateSrv.Execute(context.TODO())
+	confirm.Equal(models.ErrFileLocked, err)
+
+	deleteSrv := &FileDelete{
+		BaseService: BaseService{DB: trx},
+		Token:       other,
+		File:        file,
+	}
+	_, err = deleteSrv.Execute(context.TODO())
+	confirm.Equal(models.ErrFileLocked, err)
+
+	// the owner of lock is still able to modify the file
+	createSrv.Token = token
+	createSrv.Reader = bytes.NewReader(models.Random(256))
+	_, err = createSrv.Execute(context.TODO())
+	confirm.Nil(err)
+}
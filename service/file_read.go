@@ -7,6 +7,7 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
 
 	"github.com/bigfile/bigfile/databases/models"
 	"gopkg.in/go-playground/validator.v9"
@@ -53,7 +54,11 @@ func (fr *FileRead) Validate() ValidateErrors {
 
 // Execute is used to read file
 func (fr *FileRead) Execute(ctx context.Context) (interface{}, error) {
-	var err error
+	var (
+		err     error
+		expired bool
+		reader  io.ReadSeeker
+	)
 
 	if err = fr.Token.UpdateAvailableTimes(-1, fr.DB); err != nil {
 		return nil, err
@@ -63,5 +68,21 @@ func (fr *FileRead) Execute(ctx context.Context) (interface{}, error) {
 		return nil, ErrReadHiddenFile
 	}
 
-	return fr.File.Reader(fr.RootPath, fr.DB)
+	if expired, err = fr.File.IsExpired(fr.DB); err != nil {
+		return nil, err
+	}
+
+	if expired {
+		return nil, models.ErrFileExpired
+	}
+
+	if err = fr.File.IncreaseDownloadCount(fr.DB); err != nil {
+		return nil, err
+	}
+
+	if reader, err = fr.File.Reader(fr.RootPath, fr.DB); err != nil {
+		return nil, err
+	}
+
+	return DefaultRateLimiter().ThrottleReadSeeker(reader, fr.Token.AppID, fr.Token.ID), nil
 }
@@ -0,0 +1,157 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// ErrFileNotDeleted represent that the file to restore hasn't been deleted
+var ErrFileNotDeleted = errors.New("the file hasn't been deleted")
+
+// FileRestore is used to restore a deleted file, it's moved to Path if Path
+// isn't its original path, both are done in one transaction. It can be called
+// with token or app, Path is relative to the path of token, and the locks are
+// checked against Owner, which is the uid of token by default.
+type FileRestore struct {
+	BaseService
+
+	Token *models.Token `validate:"omitempty"`
+	App   *models.App   `validate:"omitempty"`
+	File  *models.File  `validate:"required"`
+	IP    *string       `validate:"omitempty"`
+	Path  *string       `validate:"omitempty,max=1000"`
+	Owner string        `validate:"omitempty"`
+}
+
+// Validate is used to validate service params
+func (fr *FileRestore) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(fr); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if fr.Token == nil && fr.App == nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileRestore.Token", ErrTokenOrAppRequired))
+	} else if fr.Token != nil {
+		if err = ValidateToken(fr.DB, fr.IP, false, fr.Token); err != nil {
+			validateErrors = append(validateErrors, generateErrorByField("FileRestore.Token", err))
+		}
+	}
+
+	if fr.File == nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileRestore.File", ErrInvalidFile))
+	} else if err = fr.DB.Unscoped().Where("id = ?", fr.File.ID).Find(fr.File).Error; err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileRestore.File", err))
+	} else if fr.Token != nil {
+		if err = fr.File.CanBeAccessedByToken(fr.Token, fr.DB); err != nil {
+			validateErrors = append(validateErrors, generateErrorByField("FileRestore.Token", err))
+		}
+		if fr.File.Hidden == models.Hidden {
+			validateErrors = append(validateErrors, generateErrorByField("FileRestore.File", ErrReadHiddenFile))
+		}
+	} else if fr.App != nil && fr.File.AppID != fr.App.ID {
+		validateErrors = append(validateErrors, generateErrorByField("FileRestore.File", models.ErrAccessDenied))
+	}
+
+	if fr.Path != nil && !ValidatePath(*fr.Path) {
+		validateErrors = append(validateErrors, generateErrorByField("FileRestore.Path", ErrInvalidPath))
+	}
+
+	return validateErrors
+}
+
+// Execute is used to restore the file, the file is returned
+func (fr *FileRestore) Execute(ctx context.Context) (result interface{}, err error) {
+	var (
+		file       = &models.File{}
+		app        = fr.App
+		owner      = fr.Owner
+		targetPath string
+		inTrx      = util.InTransaction(fr.DB)
+	)
+
+	if fr.Token != nil {
+		app = &fr.Token.App
+		if owner == "" {
+			owner = fr.Token.UID
+		}
+	}
+
+	if !inTrx {
+		fr.DB = fr.DB.BeginTx(ctx, &sql.TxOptions{
+			Isolation: sql.LevelReadCommitted,
+			ReadOnly:  false,
+		})
+		defer func() {
+			if reErr := recover(); reErr != nil {
+				fr.DB.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				fr.DB.Rollback()
+				return
+			}
+			if err = fr.DB.Commit().Error; err == nil {
+				models.NotifyJournal(app.ID)
+			}
+		}()
+	}
+
+	if err = models.LockJournalSequence(app.ID, fr.DB); err != nil {
+		return nil, err
+	}
+
+	if fr.Token != nil {
+		if err = fr.Token.UpdateAvailableTimes(-1, fr.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	// the file may have been restored by another one before it's locked
+	if err = fr.DB.Unscoped().Set("gorm:query_option", "FOR UPDATE").
+		Preload("App").Where("id = ?", fr.File.ID).Find(file).Error; err != nil {
+		return nil, err
+	}
+	if file.DeletedAt == nil {
+		return nil, ErrFileNotDeleted
+	}
+
+	if targetPath = file.FullPath; fr.Path != nil {
+		if targetPath = *fr.Path; fr.Token != nil {
+			targetPath = fr.Token.PathWithScope(*fr.Path)
+		}
+	}
+
+	if err = models.CheckPathLock(app, file.FullPath, owner, fr.DB); err != nil {
+		return nil, err
+	}
+	if err = models.CheckPathLock(app, targetPath, owner, fr.DB); err != nil {
+		return nil, err
+	}
+
+	if err = file.Restore(fr.DB); err != nil {
+		return nil, err
+	}
+	if targetPath != file.FullPath {
+		if err = file.MoveTo(targetPath, fr.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	fr.File = file
+	return file, nil
+}
@@ -0,0 +1,81 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileRestore_Validate(t *testing.T) {
+	tempDir := models.NewTempDirForTest()
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	srv := &FileRestore{BaseService: BaseService{DB: trx}}
+	errValidate := srv.Validate()
+	assert.True(t, errValidate.ContainsErrCode(10119))
+	assert.True(t, errValidate.ContainsErrCode(10120))
+
+	file, err := models.CreateFileFromReader(&token.App, "/restore/.hidden", bytes.NewReader([]byte("hidden")), models.Hidden, &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Delete(true, trx))
+	srv.Token = token
+	srv.File = file
+	assert.Contains(t, srv.Validate().Error(), ErrReadHiddenFile.Error())
+
+	srv.Token = nil
+	srv.App = &models.App{ID: token.AppID + 1}
+	assert.Contains(t, srv.Validate().Error(), models.ErrAccessDenied.Error())
+}
+
+func TestFileRestore_Execute(t *testing.T) {
+	tempDir := models.NewTempDirForTest()
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := models.CreateFileFromReader(&token.App, "/restore/a.txt", bytes.NewReader([]byte("a")), 0, &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Delete(true, trx))
+
+	target := "/restored/b.txt"
+	srv := &FileRestore{BaseService: BaseService{DB: trx, RootPath: &tempDir}, Token: token, File: file, Path: &target}
+	assert.Nil(t, srv.Validate())
+	value, err := srv.Execute(context.Background())
+	assert.Nil(t, err)
+	restored := value.(*models.File)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, "/restored/b.txt", restored.FullPath)
+
+	_, err = srv.Execute(context.Background())
+	assert.Equal(t, ErrFileNotDeleted, err)
+
+	// the target is occupied by the restored file
+	another, err := models.CreateFileFromReader(&token.App, "/restore/c.txt", bytes.NewReader([]byte("c")), 0, &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, another.Delete(true, trx))
+	srv = &FileRestore{BaseService: BaseService{DB: trx, RootPath: &tempDir}, App: &token.App, File: another, Path: &target}
+	assert.Nil(t, srv.Validate())
+	_, err = srv.Execute(context.Background())
+	assert.NotNil(t, err)
+}
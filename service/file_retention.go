@@ -0,0 +1,124 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+var (
+	// ErrInvalidRetention represent that mode and retainUntil don't match,
+	// governance and compliance mode require retainUntil, none mode doesn't
+	ErrInvalidRetention = errors.New("retainUntil is required by governance and compliance mode only")
+	// ErrReleaseLegalHold represent that the token try to release legal hold
+	ErrReleaseLegalHold = errors.New("legal hold can only be released by app")
+	// ErrTokenOrAppRequired represent that neither token nor app is provided
+	ErrTokenOrAppRequired = errors.New("one of token and app is required")
+)
+
+// FileRetention is used to change the retention and legal hold of file. It can
+// be called with token or app. Only the app is allowed to shorten or remove the
+// retention in governance mode, and to release legal hold.
+type FileRetention struct {
+	BaseService
+
+	Token       *models.Token `validate:"omitempty"`
+	App         *models.App   `validate:"omitempty"`
+	File        *models.File  `validate:"required"`
+	IP          *string       `validate:"omitempty"`
+	Mode        *int8         `validate:"omitempty,oneof=0 1 2"`
+	RetainUntil *time.Time    `validate:"omitempty,gt"`
+	LegalHold   *int8         `validate:"omitempty,oneof=0 1"`
+}
+
+// Validate is used to validate service params
+func (fr *FileRetention) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(fr); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if fr.Token == nil && fr.App == nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileRetention.Token", ErrTokenOrAppRequired))
+	} else if fr.Token != nil {
+		if err = ValidateToken(fr.DB, fr.IP, false, fr.Token); err != nil {
+			validateErrors = append(validateErrors, generateErrorByField("FileRetention.Token", err))
+		}
+		if fr.LegalHold != nil && *fr.LegalHold == 0 {
+			validateErrors = append(validateErrors, generateErrorByField("FileRetention.LegalHold", ErrReleaseLegalHold))
+		}
+	}
+
+	if err = ValidateFile(fr.DB, fr.File); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileRetention.File", err))
+	} else if fr.Token != nil {
+		if err = fr.File.CanBeAccessedByToken(fr.Token, fr.DB); err != nil {
+			validateErrors = append(validateErrors, generateErrorByField("FileRetention.Token", err))
+		}
+	} else if fr.App != nil && fr.File.AppID != fr.App.ID {
+		validateErrors = append(validateErrors, generateErrorByField("FileRetention.File", models.ErrAccessDenied))
+	}
+
+	if (fr.Mode != nil && *fr.Mode != models.RetentionNone) != (fr.RetainUntil != nil) {
+		validateErrors = append(validateErrors, generateErrorByField("FileRetention.Mode", ErrInvalidRetention))
+	}
+
+	return validateErrors
+}
+
+// Execute is used to change the retention and legal hold of file
+func (fr *FileRetention) Execute(ctx context.Context) (result interface{}, err error) {
+	var inTrx = util.InTransaction(fr.DB)
+
+	if !inTrx {
+		fr.DB = fr.DB.BeginTx(ctx, &sql.TxOptions{
+			Isolation: sql.LevelReadCommitted,
+			ReadOnly:  false,
+		})
+		defer func() {
+			if reErr := recover(); reErr != nil {
+				fr.DB.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				fr.DB.Rollback()
+				return
+			}
+			err = fr.DB.Commit().Error
+		}()
+	}
+
+	if fr.Token != nil {
+		if err = fr.Token.UpdateAvailableTimes(-1, fr.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	if fr.Mode != nil {
+		if err = fr.File.SetRetention(*fr.Mode, fr.RetainUntil, fr.App != nil, fr.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	if fr.LegalHold != nil {
+		if err = fr.File.SetLegalHold(*fr.LegalHold == 1, fr.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	return fr.File, nil
+}
@@ -0,0 +1,102 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileRetention_Validate(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		mode    = models.RetentionGovernance
+		release = int8(0)
+		srv     = &FileRetention{Mode: &mode}
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+	srv.DB = trx
+
+	errValidate := srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10055))
+	confirm.True(errValidate.ContainsErrCode(10056))
+	confirm.True(errValidate.ContainsErrCode(10057))
+
+	dir, err := models.CreateOrGetLastDirectory(&token.App, "/save/to", trx)
+	confirm.Nil(err)
+	srv.Token = token
+	srv.File = dir
+	srv.LegalHold = &release
+	errValidate = srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10057))
+	confirm.True(errValidate.ContainsErrCode(10059))
+	confirm.Contains(errValidate.Error(), ErrReleaseLegalHold.Error())
+
+	srv.Token = nil
+	srv.App = &models.App{ID: token.AppID + 1}
+	srv.LegalHold = nil
+	retainUntil := time.Now().Add(time.Hour)
+	srv.RetainUntil = &retainUntil
+	errValidate = srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10056))
+	confirm.Contains(errValidate.Error(), models.ErrAccessDenied.Error())
+}
+
+func TestFileRetention_Execute(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		mode    = models.RetentionGovernance
+		hold    = int8(1)
+		later   = time.Now().Add(2 * time.Hour)
+		soon    = time.Now().Add(time.Hour)
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+
+	dir, err := models.CreateOrGetLastDirectory(&token.App, "/save/to", trx)
+	confirm.Nil(err)
+
+	srv := &FileRetention{
+		BaseService: BaseService{DB: trx},
+		Token:       token,
+		File:        dir,
+		Mode:        &mode,
+		RetainUntil: &later,
+		LegalHold:   &hold,
+	}
+	confirm.Nil(srv.Validate())
+	_, err = srv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.True(dir.IsRetained())
+	confirm.Equal(int8(1), dir.LegalHold)
+
+	// token isn't allowed to shorten the retention
+	srv.RetainUntil = &soon
+	srv.LegalHold = nil
+	confirm.Nil(srv.Validate())
+	_, err = srv.Execute(context.TODO())
+	confirm.Equal(models.ErrRetentionLocked, err)
+
+	// but app can shorten the retention in governance mode, and release legal hold
+	release := int8(0)
+	srv.Token = nil
+	srv.App = &token.App
+	srv.LegalHold = &release
+	confirm.Nil(srv.Validate())
+	_, err = srv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(soon.Unix(), dir.RetainUntil.Unix())
+	confirm.Equal(int8(0), dir.LegalHold)
+}
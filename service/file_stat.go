@@ -0,0 +1,95 @@
+//   Copyright 2019 The bigfile Authors. All rights reserved.
+//   Use of this source code is governed by a MIT-style
+//   license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"mime"
+	"path"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// FileStat is used to provide the metadata of file or directory, unlike
+// FileRead, the hidden file can be stat.
+type FileStat struct {
+	BaseService
+
+	Token *models.Token `validate:"required"`
+	File  *models.File  `validate:"required"`
+	IP    *string       `validate:"omitempty"`
+}
+
+// FileStatResponse represent the metadata of file, MimeType is guessed by
+// the extension of file, and it's empty for directory. VersionCount is the
+// number of previous versions.
+type FileStatResponse struct {
+	File         *models.File
+	MimeType     string
+	VersionCount int
+}
+
+// Validate is used to validate service params
+func (fs *FileStat) Validate() ValidateErrors {
+	var (
+		validateErrors ValidateErrors
+		errs           error
+	)
+	if errs = Validate.Struct(fs); errs != nil {
+		for _, err := range errs.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err := ValidateToken(fs.DB, fs.IP, true, fs.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileStat.Token", err))
+	}
+
+	if err := ValidateFile(fs.DB, fs.File); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("FileStat.File", err))
+	} else {
+		if err := fs.File.CanBeAccessedByToken(fs.Token, fs.DB); err != nil {
+			validateErrors = append(validateErrors, generateErrorByField("FileStat.Token", err))
+		}
+	}
+
+	return validateErrors
+}
+
+// Execute is used to stat file
+func (fs *FileStat) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err      error
+		expired  bool
+		response = &FileStatResponse{File: fs.File}
+	)
+
+	if err = fs.Token.UpdateAvailableTimes(-1, fs.DB); err != nil {
+		return nil, err
+	}
+
+	if expired, err = fs.File.IsExpired(fs.DB); err != nil {
+		return nil, err
+	}
+
+	if expired {
+		return nil, models.ErrFileExpired
+	}
+
+	if fs.File.IsDir == 0 {
+		if err = fs.DB.Preload("Object").Find(fs.File).Error; err != nil {
+			return nil, err
+		}
+		if response.MimeType = mime.TypeByExtension(path.Ext(fs.File.Name)); response.MimeType == "" {
+			response.MimeType = "application/octet-stream"
+		}
+		if response.VersionCount, err = fs.File.CountHistories(fs.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,66 @@
+//   Copyright 2019 The bigfile Authors. All rights reserved.
+//   Use of this source code is governed by a MIT-style
+//   license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStat(t *testing.T) {
+	var tempDir = models.NewTempDirForTest()
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	srv := &FileStat{BaseService: BaseService{DB: trx}}
+	errValidate := srv.Validate()
+	assert.True(t, errValidate.ContainsErrCode(10117))
+	assert.True(t, errValidate.ContainsErrCode(10118))
+
+	file, err := models.CreateFileFromReader(&token.App, "/stat/.a.txt", bytes.NewReader([]byte("a")), models.Hidden, &tempDir, trx)
+	assert.Nil(t, err)
+	assert.Nil(t, file.OverWriteFromReader(bytes.NewReader([]byte("ab")), models.Hidden, &tempDir, trx))
+
+	token.Path = "/another"
+	srv.Token = token
+	srv.File = file
+	assert.Contains(t, srv.Validate().Error(), models.ErrAccessDenied.Error())
+
+	token.Path = "/"
+	assert.Nil(t, srv.Validate())
+	value, err := srv.Execute(context.Background())
+	assert.Nil(t, err)
+	response := value.(*FileStatResponse)
+	assert.Equal(t, 2, response.File.Size)
+	assert.Equal(t, models.Hidden, response.File.Hidden)
+	assert.NotEmpty(t, response.File.Object.Hash)
+	assert.Equal(t, "text/plain; charset=utf-8", response.MimeType)
+	assert.Equal(t, 1, response.VersionCount)
+
+	srv.File, err = models.FindFileByPath(&token.App, "/stat", trx)
+	assert.Nil(t, err)
+	value, err = srv.Execute(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "", value.(*FileStatResponse).MimeType)
+
+	expiredAt := time.Now().Add(-time.Hour)
+	srv.File = file
+	assert.Nil(t, file.SetExpiredAt(&expiredAt, trx))
+	_, err = srv.Execute(context.Background())
+	assert.Equal(t, models.ErrFileExpired, err)
+}
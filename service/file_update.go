@@ -7,12 +7,17 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/bigfile/bigfile/databases/models"
 	"github.com/bigfile/bigfile/internal/util"
 	"gopkg.in/go-playground/validator.v9"
 )
 
+// ErrInvalidExpiredAt represent that the expiry isn't in the future
+var ErrInvalidExpiredAt = errors.New("expiredAt must be greater than now")
+
 // FileUpdate is used uo update a file, such as move file to another path,
 // or rename file, hide file.
 type FileUpdate struct {
@@ -23,6 +28,13 @@ type FileUpdate struct {
 	IP     *string       `validate:"omitempty"`
 	Hidden *int8         `validate:"omitempty,oneof=0 1"`
 	Path   *string       `validate:"omitempty,max=1000"`
+
+	// ExpiredAt set the expiry of file, the zero time removes the expiry
+	ExpiredAt *time.Time `validate:"omitempty"`
+
+	// IfMatch and IfNoneMatch are compared with the hash of file
+	IfMatch     *string `validate:"omitempty"`
+	IfNoneMatch *string `validate:"omitempty"`
 }
 
 // Validate is used to validate service params
@@ -37,7 +49,7 @@ func (fu *FileUpdate) Validate() ValidateErrors {
 		}
 	}
 
-	if err := ValidateToken(fu.DB, fu.IP, true, fu.Token); err != nil {
+	if err := ValidateToken(fu.DB, fu.IP, false, fu.Token); err != nil {
 		validateErrors = append(validateErrors, generateErrorByField("FileUpdate.Token", err))
 	}
 
@@ -55,13 +67,16 @@ func (fu *FileUpdate) Validate() ValidateErrors {
 		}
 	}
 
+	if fu.ExpiredAt != nil && !fu.ExpiredAt.IsZero() && !fu.ExpiredAt.After(time.Now()) {
+		validateErrors = append(validateErrors, generateErrorByField("FileUpdate.ExpiredAt", ErrInvalidExpiredAt))
+	}
+
 	return validateErrors
 }
 
 // Execute is used to update file
-func (fu *FileUpdate) Execute(ctx context.Context) (interface{}, error) {
+func (fu *FileUpdate) Execute(ctx context.Context) (result interface{}, err error) {
 	var (
-		err   error
 		inTrx = util.InTransaction(fu.DB)
 	)
 
@@ -73,23 +88,58 @@ func (fu *FileUpdate) Execute(ctx context.Context) (interface{}, error) {
 		defer func() {
 			if reErr := recover(); reErr != nil {
 				fu.DB.Rollback()
+				panic(reErr)
+			}
+			if err != nil {
+				fu.DB.Rollback()
+				return
+			}
+			if err = fu.DB.Commit().Error; err == nil {
+				models.NotifyJournal(fu.Token.AppID)
 			}
 		}()
-		defer func() { err = fu.DB.Commit().Error }()
+	}
+
+	if err = models.LockJournalSequence(fu.Token.AppID, fu.DB); err != nil {
+		return nil, err
 	}
 
 	if err = fu.Token.UpdateAvailableTimes(-1, fu.DB); err != nil {
 		return nil, err
 	}
 
+	if err = fu.File.CheckLock(fu.Token.UID, fu.DB); err != nil {
+		return nil, err
+	}
+
+	if err = checkPrecondition(fu.File, fu.IfMatch, fu.IfNoneMatch, fu.DB); err != nil {
+		return nil, err
+	}
+
 	if fu.Path != nil {
+		if err = models.CheckPathLock(
+			&models.App{ID: fu.File.AppID}, fu.Token.PathWithScope(*fu.Path), fu.Token.UID, fu.DB); err != nil {
+			return nil, err
+		}
 		if err := fu.File.MoveTo(fu.Token.PathWithScope(*fu.Path), fu.DB); err != nil {
 			return nil, err
 		}
 	}
 
 	if fu.Hidden != nil {
-		fu.File.Hidden = *fu.Hidden
+		if err = fu.File.SetHidden(*fu.Hidden, fu.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	if fu.ExpiredAt != nil {
+		expiredAt := fu.ExpiredAt
+		if expiredAt.IsZero() {
+			expiredAt = nil
+		}
+		if err = fu.File.SetExpiredAt(expiredAt, fu.DB); err != nil {
+			return nil, err
+		}
 	}
 
 	return fu.File, fu.DB.Save(fu.File).Error
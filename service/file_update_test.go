@@ -9,6 +9,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/bigfile/bigfile/databases"
 
@@ -151,3 +152,59 @@ func TestFileUpdate_Execute2(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, file.ID, fileUpdated.ID)
 }
+
+func TestFileUpdate_ExecuteWithExpiredAt(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		tempDir = models.NewTempDirForTest()
+		past    = time.Now().Add(-time.Hour)
+		future  = time.Now().Add(time.Hour)
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	file, err := models.CreateFileFromReader(
+		&token.App, "/test/random.bytes", bytes.NewReader(models.Random(100)), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+
+	fileUpdateSrv := &FileUpdate{
+		BaseService: BaseService{DB: trx},
+		Token:       token,
+		File:        file,
+		ExpiredAt:   &past,
+	}
+	errValidate := fileUpdateSrv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10053))
+
+	fileUpdateSrv.ExpiredAt = &future
+	confirm.Nil(fileUpdateSrv.Validate())
+	_, err = fileUpdateSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	file, err = models.FindFileByUID(file.UID, false, trx)
+	confirm.Nil(err)
+	confirm.NotNil(file.ExpiredAt)
+	confirm.Equal(future.Unix(), file.ExpiredAt.Unix())
+
+	fileUpdateSrv.File = file
+	fileUpdateSrv.ExpiredAt = &time.Time{}
+	confirm.Nil(fileUpdateSrv.Validate())
+	_, err = fileUpdateSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	file, err = models.FindFileByUID(file.UID, false, trx)
+	confirm.Nil(err)
+	confirm.Nil(file.ExpiredAt)
+
+	// the file under legal hold can't be given an expiry
+	confirm.Nil(file.SetLegalHold(true, trx))
+	fileUpdateSrv.File = file
+	fileUpdateSrv.ExpiredAt = &future
+	confirm.Nil(fileUpdateSrv.Validate())
+	_, err = fileUpdateSrv.Execute(context.TODO())
+	confirm.Equal(models.ErrFileUnderLegalHold, err)
+}
@@ -0,0 +1,172 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"container/list"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/log"
+	"github.com/jinzhu/gorm"
+)
+
+// ErrNonceReplayed represent that the nonce has been used by the app, the
+// request is being replayed
+var ErrNonceReplayed = errors.New("this request is being replayed")
+
+// NonceStore is used to remember the nonces that have been used. Use records
+// that nonce is used by app until expiredAt, ErrNonceReplayed is returned if
+// it has been used and it hasn't expired. An expired nonce can be used again.
+type NonceStore interface {
+	Use(appID uint64, nonce string, expiredAt time.Time) error
+}
+
+type nonceKey struct {
+	appID uint64
+	nonce string
+}
+
+type nonceEntry struct {
+	key       nonceKey
+	expiredAt time.Time
+}
+
+// MemoryNonceStore is a NonceStore that keeps the nonces in memory, it's only
+// seen by the instance itself. When it's full, the least recently used nonce
+// is evicted even if it hasn't expired, so capacity should be larger than the
+// number of requests with nonce in ttl.
+type MemoryNonceStore struct {
+	capacity int
+	lock     sync.Mutex
+	entries  *list.List
+	index    map[nonceKey]*list.Element
+}
+
+// NewMemoryNonceStore create a MemoryNonceStore that keeps at most capacity nonces
+func NewMemoryNonceStore(capacity int) *MemoryNonceStore {
+	return &MemoryNonceStore{
+		capacity: capacity,
+		entries:  list.New(),
+		index:    make(map[nonceKey]*list.Element),
+	}
+}
+
+// Use implements NonceStore
+func (m *MemoryNonceStore) Use(appID uint64, nonce string, expiredAt time.Time) error {
+	var key = nonceKey{appID: appID, nonce: nonce}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if element, ok := m.index[key]; ok {
+		entry := element.Value.(*nonceEntry)
+		m.entries.MoveToFront(element)
+		if time.Now().Before(entry.expiredAt) {
+			return ErrNonceReplayed
+		}
+		entry.expiredAt = expiredAt
+		return nil
+	}
+
+	m.index[key] = m.entries.PushFront(&nonceEntry{key: key, expiredAt: expiredAt})
+	for m.capacity > 0 && m.entries.Len() > m.capacity {
+		oldest := m.entries.Back()
+		m.entries.Remove(oldest)
+		delete(m.index, oldest.Value.(*nonceEntry).key)
+	}
+	return nil
+}
+
+// Len return the number of nonces in store
+func (m *MemoryNonceStore) Len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.entries.Len()
+}
+
+// DBNonceStore is a NonceStore that keeps the nonces in database, it's shared
+// by all instances. The expired nonces are deleted by SweepExpiredNonces.
+type DBNonceStore struct {
+	DB *gorm.DB
+}
+
+// Use implements NonceStore
+func (d *DBNonceStore) Use(appID uint64, nonce string, expiredAt time.Time) error {
+	if err := models.UseNonce(appID, nonce, expiredAt, d.DB); err != nil {
+		if err == models.ErrNonceUsed {
+			return ErrNonceReplayed
+		}
+		return err
+	}
+	return nil
+}
+
+var (
+	memoryNonceStore     *MemoryNonceStore
+	memoryNonceStoreOnce sync.Once
+)
+
+// NewNonceStore return the NonceStore that is configured by cfg, the memory
+// store is shared by the process, the database store uses db. If cfg is nil,
+// config.DefaultConfig.Nonce is used.
+func NewNonceStore(cfg *config.Nonce, db *gorm.DB) NonceStore {
+	if cfg == nil {
+		cfg = &config.DefaultConfig.Nonce
+	}
+	if cfg.NonceStore == config.NonceStoreMemory {
+		memoryNonceStoreOnce.Do(func() {
+			memoryNonceStore = NewMemoryNonceStore(cfg.NonceCapacity)
+		})
+		return memoryNonceStore
+	}
+	return &DBNonceStore{DB: db}
+}
+
+// NonceExpiredAt return when the nonce of request can be forgotten. date is
+// the signed unix timestamp of request, the request is rejected by signature
+// once it's out of the allowed clock skew, so the nonce isn't needed to be
+// remembered longer than that, and it's never remembered beyond the allowed
+// clock skew from now. For the requests without signed timestamp, date is
+// empty, nothing stops them from being replayed later, so the nonce never
+// expires.
+func NonceExpiredAt(date string, now time.Time) time.Time {
+	if timestamp, err := strconv.ParseInt(date, 10, 64); err == nil {
+		expiredAt, maxExpiredAt := time.Unix(timestamp, 0).Add(SignatureV2MaxSkew), now.Add(SignatureV2MaxSkew)
+		if expiredAt.After(maxExpiredAt) {
+			return maxExpiredAt
+		}
+		return expiredAt
+	}
+	return models.NonceNeverExpire
+}
+
+// SweepExpiredNonces is used to delete the expired nonces in database every
+// interval until stop is closed, at most limit nonces are deleted in one round.
+func SweepExpiredNonces(db *gorm.DB, interval time.Duration, limit int, stop <-chan struct{}) {
+	var (
+		ticker = time.NewTicker(interval)
+		logger = log.MustNewLogger(nil)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			count, err := models.DeleteExpiredNonces(limit, db)
+			if err != nil {
+				logger.Errorf("sweep expired nonces failed: %s", err)
+			}
+			if count > 0 {
+				logger.Debugf("%d expired nonces have been deleted", count)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryNonceStore_Use(t *testing.T) {
+	var (
+		store  = NewMemoryNonceStore(2)
+		future = time.Now().Add(time.Hour)
+	)
+	assert.Nil(t, store.Use(1, "a", future))
+	assert.Equal(t, ErrNonceReplayed, store.Use(1, "a", future))
+	assert.Nil(t, store.Use(2, "a", future))
+	assert.Equal(t, 2, store.Len())
+
+	// the expired nonce can be used again
+	store.index[nonceKey{appID: 2, nonce: "a"}].Value.(*nonceEntry).expiredAt = time.Now().Add(-time.Second)
+	assert.Nil(t, store.Use(2, "a", future))
+	assert.Equal(t, ErrNonceReplayed, store.Use(2, "a", future))
+
+	// the least recently used one is evicted when it's full
+	assert.Equal(t, ErrNonceReplayed, store.Use(1, "a", future))
+	assert.Nil(t, store.Use(1, "b", future))
+	assert.Equal(t, 2, store.Len())
+	assert.Equal(t, ErrNonceReplayed, store.Use(1, "a", future))
+	assert.Nil(t, store.Use(2, "a", future))
+}
+
+func TestDBNonceStore_Use(t *testing.T) {
+	app, trx, down, err := models.NewAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	var (
+		store  = &DBNonceStore{DB: trx}
+		nonce  = models.RandomWithMD5(128)
+		future = time.Now().Add(time.Hour)
+	)
+	assert.Nil(t, store.Use(app.ID, nonce, future))
+	assert.Equal(t, ErrNonceReplayed, store.Use(app.ID, nonce, future))
+}
+
+func TestNewNonceStore(t *testing.T) {
+	_, ok := NewNonceStore(nil, nil).(*DBNonceStore)
+	assert.True(t, ok)
+
+	cfg := &config.Nonce{NonceStore: config.NonceStoreMemory, NonceCapacity: 10}
+	store, ok := NewNonceStore(cfg, nil).(*MemoryNonceStore)
+	assert.True(t, ok)
+	assert.Equal(t, store, NewNonceStore(cfg, nil))
+}
+
+func TestNonceExpiredAt(t *testing.T) {
+	var now = time.Now()
+	assert.Equal(t, models.NonceNeverExpire, NonceExpiredAt("", now))
+	assert.Equal(t, models.NonceNeverExpire, NonceExpiredAt("invalid", now))
+	assert.Equal(
+		t,
+		time.Unix(now.Unix(), 0).Add(SignatureV2MaxSkew),
+		NonceExpiredAt(strconv.FormatInt(now.Unix(), 10), now),
+	)
+	future := now.Add(24 * time.Hour)
+	assert.Equal(t, now.Add(SignatureV2MaxSkew), NonceExpiredAt(strconv.FormatInt(future.Unix(), 10), now))
+}
@@ -0,0 +1,80 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+)
+
+// ErrPreconditionFailed represent that the current version of file doesn't
+// match If-Match or If-None-Match
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// matchETags represent whether the hash matches one of the entity tags in list,
+// the list is the value of If-Match or If-None-Match, such as `"a", W/"b"` or `*`.
+// '*' matches any existing file.
+func matchETags(list, hash string, exists bool) bool {
+	if !exists {
+		return false
+	}
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		tag = strings.Trim(strings.TrimPrefix(tag, "W/"), `"`)
+		if tag != "" && tag == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPrecondition is used to check whether the current version of file satisfies
+// If-Match and If-None-Match, the entity tag of file is the hash of its object.
+// file may be nil or trashed when the path doesn't exist. The row of file will be
+// locked until the transaction ends, so the check and the following mutation are
+// atomic.
+func checkPrecondition(file *models.File, ifMatch, ifNoneMatch *string, db *gorm.DB) error {
+	if ifMatch == nil && ifNoneMatch == nil {
+		return nil
+	}
+
+	var (
+		hash   string
+		exists = file != nil && file.ID != 0 && file.DeletedAt == nil
+	)
+
+	if exists {
+		var (
+			current = &models.File{}
+			object  = &models.Object{}
+		)
+		if err := db.Set("gorm:query_option", "FOR UPDATE").
+			Where("id = ?", file.ID).First(current).Error; err != nil {
+			return err
+		}
+		if current.IsDir == 0 {
+			if err := db.Where("id = ?", current.ObjectID).First(object).Error; err != nil {
+				return err
+			}
+			hash = object.Hash
+		}
+	}
+
+	if ifMatch != nil && !matchETags(*ifMatch, hash, exists) {
+		return ErrPreconditionFailed
+	}
+
+	if ifNoneMatch != nil && matchETags(*ifNoneMatch, hash, exists) {
+		return ErrPreconditionFailed
+	}
+
+	return nil
+}
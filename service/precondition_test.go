@@ -0,0 +1,58 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchETags(t *testing.T) {
+	assert.True(t, matchETags("*", "", true))
+	assert.False(t, matchETags("*", "", false))
+	assert.True(t, matchETags("abc", "abc", true))
+	assert.True(t, matchETags(`"xyz", "abc"`, "abc", true))
+	assert.True(t, matchETags(`W/"abc"`, "abc", true))
+	assert.False(t, matchETags(`"xyz"`, "abc", true))
+	assert.False(t, matchETags(`""`, "", true))
+	assert.False(t, matchETags("abc", "abc", false))
+}
+
+func TestCheckPrecondition(t *testing.T) {
+	var (
+		confirm   = assert.New(t)
+		tempDir   = models.NewTempDirForTest()
+		wrongHash = "wrong hash"
+		star      = "*"
+	)
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer func() {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	file, err := models.CreateFileFromReader(
+		&token.App, "/save/to/random.bytes", bytes.NewReader(models.Random(256)), int8(0), &tempDir, trx)
+	confirm.Nil(err)
+	hash := file.Object.Hash
+
+	confirm.Nil(checkPrecondition(file, nil, nil, trx))
+	confirm.Nil(checkPrecondition(file, &hash, nil, trx))
+	confirm.Nil(checkPrecondition(file, &star, &wrongHash, trx))
+	confirm.Equal(ErrPreconditionFailed, checkPrecondition(file, &wrongHash, nil, trx))
+	confirm.Equal(ErrPreconditionFailed, checkPrecondition(file, nil, &hash, trx))
+	confirm.Equal(ErrPreconditionFailed, checkPrecondition(file, nil, &star, trx))
+
+	confirm.Equal(ErrPreconditionFailed, checkPrecondition(nil, &star, nil, trx))
+	confirm.Nil(checkPrecondition(nil, nil, &star, trx))
+}
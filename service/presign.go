@@ -0,0 +1,208 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const (
+	// PresignMethodRead represent that the presigned url is used to download file
+	PresignMethodRead = "GET"
+
+	// PresignMethodCreate represent that the presigned url is used to upload file
+	PresignMethodCreate = "POST"
+
+	// PresignSignatureParam is the name of the query param that carries signature
+	PresignSignatureParam = "signature"
+)
+
+var (
+	// ErrPresignTarget represent that neither or both of fileUid and path are provided
+	ErrPresignTarget = errors.New("exactly one of fileUid and path is required")
+
+	// ErrPresignLimits represent that the limits of upload are used with download
+	ErrPresignLimits = errors.New("contentLength and contentType are only allowed for upload")
+
+	// ErrPresignedSignature represent that the signature of presigned url is wrong
+	ErrPresignedSignature = errors.New("presigned url signature validate failed")
+
+	// ErrPresignedExpired represent that the presigned url has expired
+	ErrPresignedExpired = errors.New("presigned url has expired")
+
+	// ErrPresignedMethod represent that the presigned url is used with another method
+	ErrPresignedMethod = errors.New("presigned url is used with wrong method")
+)
+
+// SigningSecret return the secret that presigned urls and v2 signatures of
+// token are signed with, it's the secret of token, or the secret of app if
+// token has no secret
+func SigningSecret(token *models.Token) string {
+	if token.Secret != nil && *token.Secret != "" {
+		return *token.Secret
+	}
+	return token.App.Secret
+}
+
+// SignPresignedParams return the HMAC-SHA256 signature of params, it's computed
+// over the encoded params that are sorted by key, signature itself is excluded.
+func SignPresignedParams(params url.Values, secret string) string {
+	var canonical = url.Values{}
+	for key, values := range params {
+		if key != PresignSignatureParam {
+			canonical[key] = values
+		}
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(canonical.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Presign is used to mint a time-limited url that is bound to a method and a
+// file. The url can be used without token secret, so it can be handed out to
+// browsers. Only path can be used to upload, because the file doesn't exist.
+type Presign struct {
+	BaseService
+
+	Token         *models.Token `validate:"required"`
+	IP            *string       `validate:"omitempty"`
+	Method        string        `validate:"required,oneof=GET POST"`
+	FileUID       *string       `validate:"omitempty"`
+	Path          *string       `validate:"omitempty,max=1000"`
+	ExpiresIn     int           `validate:"required,min=1,max=604800"`
+	ContentLength *int          `validate:"omitempty,min=0"`
+	ContentType   *string       `validate:"omitempty,max=255"`
+}
+
+// Validate is used to validate service params
+func (p *Presign) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(p); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(p.DB, p.IP, p.Method == PresignMethodRead, p.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("Presign.Token", err))
+	}
+
+	if (p.FileUID == nil) == (p.Path == nil) || (p.Method == PresignMethodCreate && p.Path == nil) {
+		validateErrors = append(validateErrors, generateErrorByField("Presign.Path", ErrPresignTarget))
+	} else if p.Path != nil && !ValidatePath(*p.Path) {
+		validateErrors = append(validateErrors, generateErrorByField("Presign.Path", ErrInvalidPath))
+	}
+
+	if p.Method == PresignMethodRead && (p.ContentLength != nil || p.ContentType != nil) {
+		validateErrors = append(validateErrors, generateErrorByField("Presign.ContentLength", ErrPresignLimits))
+	}
+
+	return validateErrors
+}
+
+// Execute is used to sign the params of url, it returns url.Values that should
+// be used as the query of presigned url. Minting url doesn't consume the
+// available times of token, but every request of url does.
+func (p *Presign) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		file   *models.File
+		err    error
+		params = url.Values{}
+	)
+
+	if p.FileUID != nil {
+		if file, err = models.FindFileByUID(*p.FileUID, false, p.DB); err != nil {
+			return nil, err
+		}
+		if err = file.CanBeAccessedByToken(p.Token, p.DB); err != nil {
+			return nil, err
+		}
+		params.Set("fileUid", *p.FileUID)
+	} else {
+		params.Set("path", *p.Path)
+	}
+
+	params.Set("token", p.Token.UID)
+	params.Set("method", p.Method)
+	params.Set("expires", strconv.FormatInt(time.Now().Add(time.Duration(p.ExpiresIn)*time.Second).Unix(), 10))
+	if p.ContentLength != nil {
+		params.Set("contentLength", strconv.Itoa(*p.ContentLength))
+	}
+	if p.ContentType != nil {
+		params.Set("contentType", *p.ContentType)
+	}
+	params.Set(PresignSignatureParam, SignPresignedParams(params, SigningSecret(p.Token)))
+	return params, nil
+}
+
+// Presigned represent a request of presigned url that has been verified
+type Presigned struct {
+	Token         *models.Token
+	Method        string
+	FileUID       *string
+	Path          *string
+	ExpiresAt     time.Time
+	ContentLength *int
+	ContentType   *string
+}
+
+// ParsePresigned is used to verify the query of presigned url that is requested
+// with method. Only the signature is verified here, the token should still be
+// validated by the services that are executed with it.
+func ParsePresigned(params url.Values, method string, db *gorm.DB) (presigned *Presigned, err error) {
+	var (
+		expires       int64
+		contentLength int
+	)
+	presigned = &Presigned{Method: params.Get("method")}
+	if presigned.Token, err = models.FindTokenByUID(params.Get("token"), db); err != nil {
+		return nil, ErrPresignedSignature
+	}
+	if !hmac.Equal(
+		[]byte(params.Get(PresignSignatureParam)),
+		[]byte(SignPresignedParams(params, SigningSecret(presigned.Token))),
+	) {
+		return presigned, ErrPresignedSignature
+	}
+	if expires, err = strconv.ParseInt(params.Get("expires"), 10, 64); err != nil {
+		return presigned, ErrPresignedSignature
+	}
+	if presigned.ExpiresAt = time.Unix(expires, 0); time.Now().After(presigned.ExpiresAt) {
+		return presigned, ErrPresignedExpired
+	}
+	if presigned.Method != method {
+		return presigned, ErrPresignedMethod
+	}
+	if values, ok := params["fileUid"]; ok {
+		presigned.FileUID = &values[0]
+	}
+	if values, ok := params["path"]; ok {
+		presigned.Path = &values[0]
+	}
+	if values, ok := params["contentLength"]; ok {
+		if contentLength, err = strconv.Atoi(values[0]); err != nil {
+			return presigned, ErrPresignedSignature
+		}
+		presigned.ContentLength = &contentLength
+	}
+	if values, ok := params["contentType"]; ok {
+		presigned.ContentType = &values[0]
+	}
+	return presigned, nil
+}
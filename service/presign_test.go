@@ -0,0 +1,117 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignPresignedParams(t *testing.T) {
+	params := url.Values{}
+	params.Set("token", "token")
+	params.Set("path", "/a.txt")
+	sign := SignPresignedParams(params, "secret")
+	assert.Len(t, sign, 64)
+
+	params.Set(PresignSignatureParam, sign)
+	assert.Equal(t, sign, SignPresignedParams(params, "secret"))
+	assert.NotEqual(t, sign, SignPresignedParams(params, "another"))
+
+	params.Set("path", "/b.txt")
+	assert.NotEqual(t, sign, SignPresignedParams(params, "secret"))
+}
+
+func TestPresign_Validate(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	var (
+		path          = "/a.txt"
+		fileUID       = "uid"
+		contentLength = 10
+		presignSrv    = &Presign{
+			BaseService: BaseService{DB: trx},
+			Token:       token,
+			Method:      PresignMethodCreate,
+			ExpiresIn:   60,
+		}
+	)
+
+	errValidate := presignSrv.Validate()
+	assert.True(t, errValidate.ContainsErrCode(10094))
+	assert.Contains(t, errValidate.Error(), ErrPresignTarget.Error())
+
+	presignSrv.FileUID = &fileUID
+	assert.True(t, presignSrv.Validate().ContainsErrCode(10094))
+
+	presignSrv.FileUID = nil
+	presignSrv.Path = &path
+	presignSrv.ContentLength = &contentLength
+	assert.Nil(t, presignSrv.Validate())
+
+	presignSrv.Method = PresignMethodRead
+	errValidate = presignSrv.Validate()
+	assert.True(t, errValidate.ContainsErrCode(10096))
+	assert.Contains(t, errValidate.Error(), ErrPresignLimits.Error())
+
+	presignSrv.ContentLength = nil
+	presignSrv.ExpiresIn = 0
+	assert.True(t, presignSrv.Validate().ContainsErrCode(10095))
+}
+
+func TestPresign_Execute(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	var (
+		path        = "/a.txt"
+		contentType = "text/plain"
+		presignSrv  = &Presign{
+			BaseService: BaseService{DB: trx},
+			Token:       token,
+			Method:      PresignMethodCreate,
+			Path:        &path,
+			ExpiresIn:   60,
+			ContentType: &contentType,
+		}
+	)
+	assert.Nil(t, presignSrv.Validate())
+	value, err := presignSrv.Execute(context.Background())
+	assert.Nil(t, err)
+	params := value.(url.Values)
+	assert.Equal(t, path, params.Get("path"))
+	assert.Equal(t, token.UID, params.Get("token"))
+	assert.Equal(t, contentType, params.Get("contentType"))
+
+	presigned, err := ParsePresigned(params, PresignMethodCreate, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, token.ID, presigned.Token.ID)
+	assert.Equal(t, path, *presigned.Path)
+	assert.Equal(t, contentType, *presigned.ContentType)
+	assert.Nil(t, presigned.FileUID)
+	assert.Nil(t, presigned.ContentLength)
+
+	_, err = ParsePresigned(params, PresignMethodRead, trx)
+	assert.Equal(t, ErrPresignedMethod, err)
+
+	params.Set("path", "/b.txt")
+	_, err = ParsePresigned(params, PresignMethodCreate, trx)
+	assert.Equal(t, ErrPresignedSignature, err)
+
+	params.Set("path", path)
+	params.Set("expires", strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+	params.Set(PresignSignatureParam, SignPresignedParams(params, SigningSecret(token)))
+	_, err = ParsePresigned(params, PresignMethodCreate, trx)
+	assert.Equal(t, ErrPresignedExpired, err)
+}
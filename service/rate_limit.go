@@ -0,0 +1,311 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+)
+
+// ErrTooManyRequests represent that the request rate limit of app or token
+// is exceeded
+var ErrTooManyRequests = errors.New("too many requests")
+
+// RateLimitStore is used to keep the token buckets of rate limits. Take takes
+// n tokens from the bucket of key, the bucket holds burst tokens at most, and
+// it's refilled with rate tokens per second. If there aren't enough tokens,
+// nothing is taken, and how long to wait before retrying is returned. If n is
+// zero, it only checks whether a token is available. The stores that are
+// shared by instances should take tokens atomically.
+type RateLimitStore interface {
+	Take(key string, rate float64, burst int64, n int64, now time.Time) (retryAfter time.Duration, err error)
+}
+
+type tokenBucket struct {
+	tokens float64
+	rate   float64
+	burst  float64
+	last   time.Time
+}
+
+// refill is used to refill the bucket to now
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// MemoryRateLimitStore is a RateLimitStore that keeps the buckets in memory,
+// the limits are only applied to the instance itself. The buckets that have
+// been refilled are purged periodically.
+type MemoryRateLimitStore struct {
+	lock      sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPurge time.Time
+}
+
+// NewMemoryRateLimitStore create a MemoryRateLimitStore
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*tokenBucket), lastPurge: time.Now()}
+}
+
+// Take implements RateLimitStore
+func (m *MemoryRateLimitStore) Take(key string, rate float64, burst int64, n int64, now time.Time) (time.Duration, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if now.Sub(m.lastPurge) > time.Minute {
+		for k, b := range m.buckets {
+			if b.refill(now); b.tokens >= b.burst {
+				delete(m.buckets, k)
+			}
+		}
+		m.lastPurge = now
+	}
+
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(burst), last: now}
+		m.buckets[key] = bucket
+	}
+	bucket.rate, bucket.burst = rate, float64(burst)
+	bucket.refill(now)
+
+	need := math.Max(float64(n), 1)
+	if bucket.tokens >= need {
+		bucket.tokens -= float64(n)
+		return 0, nil
+	}
+	return time.Duration((need - bucket.tokens) / rate * float64(time.Second)), nil
+}
+
+// RateLimiter is used to limit the request rate and bandwidth of apps and
+// tokens with the limits of Config. If Config is nil, config.DefaultConfig.Limit
+// is used.
+type RateLimiter struct {
+	Config *config.Limit
+	Store  RateLimitStore
+}
+
+var defaultRateLimiter = &RateLimiter{Store: NewMemoryRateLimitStore()}
+
+// DefaultRateLimiter return the rate limiter that is shared by http, rpc and ftp
+func DefaultRateLimiter() *RateLimiter {
+	return defaultRateLimiter
+}
+
+func (l *RateLimiter) config() *config.Limit {
+	if l.Config == nil {
+		return &config.DefaultConfig.Limit
+	}
+	return l.Config
+}
+
+// requestBurst return the burst of request rate, it's the ceil of rate if it
+// isn't configured
+func requestBurst(rate float64, burst int64) int64 {
+	if burst > 0 {
+		return burst
+	}
+	return int64(math.Ceil(rate))
+}
+
+// AllowRequest is used to take a request from the limits of token and app,
+// tokenID is zero if the request isn't made with token. The limit of app is
+// only taken after the request passes the limit of token, so the requests
+// that are rejected by token don't use up the limit of app. If the limit is
+// exceeded, ErrTooManyRequests is returned with how long to wait before
+// retrying.
+func (l *RateLimiter) AllowRequest(appID, tokenID uint64) (time.Duration, error) {
+	var (
+		cfg = l.config()
+		now = time.Now()
+	)
+	if cfg.LimitTokenRequests > 0 && tokenID > 0 {
+		retryAfter, err := l.Store.Take(fmt.Sprintf("request:token:%d", tokenID),
+			cfg.LimitTokenRequests, requestBurst(cfg.LimitTokenRequests, cfg.LimitTokenBurst), 1, now)
+		if err != nil || retryAfter > 0 {
+			return retryAfter, tooManyRequests(err)
+		}
+	}
+	if cfg.LimitAppRequests > 0 {
+		retryAfter, err := l.Store.Take(fmt.Sprintf("request:app:%d", appID),
+			cfg.LimitAppRequests, requestBurst(cfg.LimitAppRequests, cfg.LimitAppBurst), 1, now)
+		if err != nil || retryAfter > 0 {
+			return retryAfter, tooManyRequests(err)
+		}
+	}
+	return 0, nil
+}
+
+// sharePasswordKey return the key of the bucket of wrong passwords
+func sharePasswordKey(shareUID, ip string) string {
+	return fmt.Sprintf("password:share:%s:%s", shareUID, ip)
+}
+
+// AllowShareRequest is used to take a request of the public share link from
+// ip. The request is also rejected if too many wrong passwords of the share
+// link have been tried from ip, see FailSharePassword.
+func (l *RateLimiter) AllowShareRequest(shareUID, ip string) (time.Duration, error) {
+	var (
+		cfg = l.config()
+		now = time.Now()
+	)
+	if cfg.LimitShareRequests > 0 {
+		retryAfter, err := l.Store.Take(fmt.Sprintf("request:share:%s:%s", shareUID, ip),
+			cfg.LimitShareRequests, requestBurst(cfg.LimitShareRequests, cfg.LimitShareBurst), 1, now)
+		if err != nil || retryAfter > 0 {
+			return retryAfter, tooManyRequests(err)
+		}
+	}
+	if cfg.LimitSharePasswordFailures > 0 {
+		retryAfter, err := l.Store.Take(sharePasswordKey(shareUID, ip),
+			float64(cfg.LimitSharePasswordFailures)/3600, cfg.LimitSharePasswordFailures, 0, now)
+		if err != nil || retryAfter > 0 {
+			return retryAfter, tooManyRequests(err)
+		}
+	}
+	return 0, nil
+}
+
+// FailSharePassword is used to count a wrong password of the share link from
+// ip, the requests are rejected after the limit of wrong passwords is used up
+func (l *RateLimiter) FailSharePassword(shareUID, ip string) error {
+	var cfg = l.config()
+	if cfg.LimitSharePasswordFailures <= 0 {
+		return nil
+	}
+	_, err := l.Store.Take(sharePasswordKey(shareUID, ip),
+		float64(cfg.LimitSharePasswordFailures)/3600, cfg.LimitSharePasswordFailures, 1, time.Now())
+	return err
+}
+
+func tooManyRequests(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrTooManyRequests
+}
+
+// bandwidthLimit represent the bandwidth bucket of app or token, the burst is
+// the bytes of one second
+type bandwidthLimit struct {
+	key  string
+	rate int64
+}
+
+// bandwidthLimits return the bandwidth limits of app and token
+func (l *RateLimiter) bandwidthLimits(appID, tokenID uint64) []bandwidthLimit {
+	var (
+		cfg    = l.config()
+		limits []bandwidthLimit
+	)
+	if cfg.LimitAppBandwidth > 0 {
+		limits = append(limits, bandwidthLimit{key: fmt.Sprintf("bandwidth:app:%d", appID), rate: cfg.LimitAppBandwidth})
+	}
+	if cfg.LimitTokenBandwidth > 0 && tokenID > 0 {
+		limits = append(limits, bandwidthLimit{key: fmt.Sprintf("bandwidth:token:%d", tokenID), rate: cfg.LimitTokenBandwidth})
+	}
+	return limits
+}
+
+// throttles return whether the bandwidth of app or token is limited
+func (l *RateLimiter) throttles(appID, tokenID uint64) bool {
+	return len(l.bandwidthLimits(appID, tokenID)) > 0
+}
+
+// throttledReader is used to limit the bytes per second that are read, the
+// read is blocked until the bytes are available in all the limits
+type throttledReader struct {
+	reader  io.Reader
+	store   RateLimitStore
+	limits  []bandwidthLimit
+	maxRead int64
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > t.maxRead {
+		p = p[:t.maxRead]
+	}
+	n, err := t.reader.Read(p)
+	if n > 0 {
+		for _, limit := range t.limits {
+			if waitErr := t.wait(limit, int64(n)); waitErr != nil {
+				return n, waitErr
+			}
+		}
+	}
+	return n, err
+}
+
+// wait is used to wait until n bytes are taken from limit
+func (t *throttledReader) wait(limit bandwidthLimit, n int64) error {
+	for {
+		retryAfter, err := t.store.Take(limit.key, float64(limit.rate), limit.rate, n, time.Now())
+		if err != nil || retryAfter == 0 {
+			return err
+		}
+		time.Sleep(retryAfter)
+	}
+}
+
+// throttledReadSeeker is a throttledReader that can seek, seeking isn't limited
+type throttledReadSeeker struct {
+	*throttledReader
+	seeker io.Seeker
+}
+
+func (t *throttledReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return t.seeker.Seek(offset, whence)
+}
+
+// newThrottledReader return nil if there isn't any bandwidth limit
+func (l *RateLimiter) newThrottledReader(reader io.Reader, appID, tokenID uint64) *throttledReader {
+	limits := l.bandwidthLimits(appID, tokenID)
+	if len(limits) == 0 {
+		return nil
+	}
+	throttled := &throttledReader{reader: reader, store: l.Store, limits: limits, maxRead: limits[0].rate}
+	for _, limit := range limits {
+		if limit.rate < throttled.maxRead {
+			throttled.maxRead = limit.rate
+		}
+	}
+	return throttled
+}
+
+// ThrottleReader is used to limit the bandwidth of the uploads or downloads of
+// app and token, tokenID is zero if it isn't made with token. reader is
+// returned as is if there isn't any bandwidth limit.
+func (l *RateLimiter) ThrottleReader(reader io.Reader, appID, tokenID uint64) io.Reader {
+	if throttled := l.newThrottledReader(reader, appID, tokenID); throttled != nil {
+		return throttled
+	}
+	return reader
+}
+
+// ThrottleReadSeeker is the same as ThrottleReader, but seeking is kept
+func (l *RateLimiter) ThrottleReadSeeker(reader io.ReadSeeker, appID, tokenID uint64) io.ReadSeeker {
+	if throttled := l.newThrottledReader(reader, appID, tokenID); throttled != nil {
+		return &throttledReadSeeker{throttledReader: throttled, seeker: reader}
+	}
+	return reader
+}
+
+// RetryAfterSeconds return the value of Retry-After, it's rounded up to
+// seconds, and it's one at least
+func RetryAfterSeconds(retryAfter time.Duration) int64 {
+	if seconds := int64(math.Ceil(retryAfter.Seconds())); seconds > 1 {
+		return seconds
+	}
+	return 1
+}
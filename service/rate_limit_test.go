@@ -0,0 +1,149 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRateLimitStore_Take(t *testing.T) {
+	var (
+		store = NewMemoryRateLimitStore()
+		now   = time.Now()
+	)
+	retryAfter, err := store.Take("key", 2, 2, 1, now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), retryAfter)
+	retryAfter, err = store.Take("key", 2, 2, 1, now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), retryAfter)
+
+	retryAfter, err = store.Take("key", 2, 2, 1, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	retryAfter, err = store.Take("key", 2, 2, 1, now.Add(500*time.Millisecond))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), retryAfter)
+
+	// the other keys aren't affected
+	retryAfter, err = store.Take("other", 2, 2, 2, now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), retryAfter)
+
+	// the refilled buckets are purged
+	_, err = store.Take("key", 2, 2, 1, now.Add(2*time.Minute))
+	assert.Nil(t, err)
+	assert.Len(t, store.buckets, 1)
+}
+
+func TestRateLimiter_AllowRequest(t *testing.T) {
+	limiter := &RateLimiter{
+		Config: &config.Limit{LimitAppRequests: 0.5, LimitAppBurst: 2, LimitTokenRequests: 1, LimitTokenBurst: 1},
+		Store:  NewMemoryRateLimitStore(),
+	}
+
+	retryAfter, err := limiter.AllowRequest(1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), retryAfter)
+
+	// the request rejected by token doesn't take the limit of app
+	retryAfter, err = limiter.AllowRequest(1, 1)
+	assert.Equal(t, ErrTooManyRequests, err)
+	assert.True(t, retryAfter > 0 && retryAfter <= time.Second)
+	_, err = limiter.AllowRequest(1, 2)
+	assert.Nil(t, err)
+
+	retryAfter, err = limiter.AllowRequest(1, 3)
+	assert.Equal(t, ErrTooManyRequests, err)
+	assert.True(t, retryAfter > time.Second)
+	assert.Equal(t, int64(2), RetryAfterSeconds(retryAfter))
+
+	limiter.Config.LimitAppRequests = 0
+	_, err = limiter.AllowRequest(1, 4)
+	assert.Nil(t, err)
+	_, err = limiter.AllowRequest(1, 4)
+	assert.Equal(t, ErrTooManyRequests, err)
+
+	// the requests without token are only limited by app
+	_, err = limiter.AllowRequest(1, 0)
+	assert.Nil(t, err)
+}
+
+func TestRateLimiter_AllowShareRequest(t *testing.T) {
+	limiter := &RateLimiter{
+		Config: &config.Limit{LimitShareRequests: 1, LimitShareBurst: 3, LimitSharePasswordFailures: 2},
+		Store:  NewMemoryRateLimitStore(),
+	}
+
+	_, err := limiter.AllowShareRequest("share", "127.0.0.1")
+	assert.Nil(t, err)
+	assert.Nil(t, limiter.FailSharePassword("share", "127.0.0.1"))
+	_, err = limiter.AllowShareRequest("share", "127.0.0.1")
+	assert.Nil(t, err)
+	assert.Nil(t, limiter.FailSharePassword("share", "127.0.0.1"))
+
+	// the wrong passwords are used up, the share link is refused for the ip
+	retryAfter, err := limiter.AllowShareRequest("share", "127.0.0.1")
+	assert.Equal(t, ErrTooManyRequests, err)
+	assert.True(t, retryAfter > time.Minute)
+	_, err = limiter.AllowShareRequest("share", "127.0.0.2")
+	assert.Nil(t, err)
+
+	// the requests of share link from an ip are limited
+	_, err = limiter.AllowShareRequest("another", "127.0.0.2")
+	assert.Nil(t, err)
+	_, err = limiter.AllowShareRequest("another", "127.0.0.2")
+	assert.Nil(t, err)
+	_, err = limiter.AllowShareRequest("another", "127.0.0.2")
+	assert.Nil(t, err)
+	_, err = limiter.AllowShareRequest("another", "127.0.0.2")
+	assert.Equal(t, ErrTooManyRequests, err)
+}
+
+func TestRateLimiter_ThrottleReader(t *testing.T) {
+	var (
+		content = bytes.Repeat([]byte("a"), 30)
+		limiter = &RateLimiter{Config: &config.Limit{}, Store: NewMemoryRateLimitStore()}
+		reader  io.Reader
+	)
+
+	reader = bytes.NewReader(content)
+	assert.False(t, limiter.throttles(1, 1))
+	assert.Equal(t, reader, limiter.ThrottleReader(reader, 1, 1))
+
+	limiter.Config.LimitAppBandwidth = 100
+	limiter.Config.LimitTokenBandwidth = 10
+	assert.True(t, limiter.throttles(1, 1))
+	throttled := limiter.ThrottleReader(bytes.NewReader(content), 1, 1)
+	assert.Equal(t, int64(10), throttled.(*throttledReader).maxRead)
+
+	start := time.Now()
+	data, err := ioutil.ReadAll(throttled)
+	assert.Nil(t, err)
+	assert.Equal(t, content, data)
+	assert.True(t, time.Since(start) >= 1900*time.Millisecond)
+
+	readSeeker := limiter.ThrottleReadSeeker(bytes.NewReader(content), 2, 0)
+	offset, err := readSeeker.Seek(20, io.SeekStart)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20), offset)
+	data, err = ioutil.ReadAll(readSeeker)
+	assert.Nil(t, err)
+	assert.Equal(t, content[20:], data)
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, int64(1), RetryAfterSeconds(0))
+	assert.Equal(t, int64(1), RetryAfterSeconds(100*time.Millisecond))
+	assert.Equal(t, int64(3), RetryAfterSeconds(2100*time.Millisecond))
+}
@@ -0,0 +1,412 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"path"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+var (
+	// ErrShareNotMatchToken represent that the share link can't be managed by the token
+	ErrShareNotMatchToken = errors.New("the share link can't be managed by this token")
+	// ErrInvalidShare represent that the share link doesn't exist or has been revoked
+	ErrInvalidShare = errors.New("invalid share link")
+)
+
+// validateShareOwner is used to validate whether the share link is in the path
+// of token
+func validateShareOwner(token *models.Token, share *models.Share, db *gorm.DB) error {
+	if share == nil || token == nil || share.AppID != token.AppID {
+		return ErrShareNotMatchToken
+	}
+	if share.File.CanBeAccessedByToken(token, db) != nil {
+		return ErrShareNotMatchToken
+	}
+	return nil
+}
+
+// validateShare is used to validate whether the share link can be used with
+// password, the file of share link mustn't be deleted. The token that creates
+// the share link is validated as well, the share link can't be used after the
+// token is deleted, expired or exhausted.
+func validateShare(share *models.Share, password string, db *gorm.DB) error {
+	var token = &models.Token{}
+	if share == nil || share.ID == 0 || share.File.ID == 0 {
+		return ErrInvalidShare
+	}
+	if err := share.Available(); err != nil {
+		return err
+	}
+	if err := db.Where("id = ?", share.TokenID).First(token).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return ErrInvalidShare
+		}
+		return err
+	}
+	if err := ValidateToken(db, nil, true, token); err != nil {
+		return err
+	}
+	return share.CheckPassword(password)
+}
+
+// shareTarget is used to find the file in the share link by the path relative
+// to the shared directory, only "/" can be used to access a shared file
+func shareTarget(share *models.Share, subPath string, db *gorm.DB) (file *models.File, err error) {
+	var (
+		sharedPath string
+		expired    bool
+	)
+	if sharedPath, err = share.File.Path(db); err != nil {
+		return nil, err
+	}
+	if subPath = path.Clean("/" + subPath); subPath == "/" {
+		file = &share.File
+	} else if share.File.IsDir == 0 {
+		return nil, gorm.ErrRecordNotFound
+	} else if file, err = models.FindFileByPath(&share.App, path.Join(sharedPath, subPath), db); err != nil {
+		return nil, err
+	}
+	if file.Hidden == models.Hidden {
+		return nil, ErrReadHiddenFile
+	}
+	if expired, err = file.IsExpired(db); err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, models.ErrFileExpired
+	}
+	return file, nil
+}
+
+// ShareCreate is used to share a file or a directory by a public link. The
+// link can be protected by a password, and it can expire or be limited by
+// the count of downloads.
+type ShareCreate struct {
+	BaseService
+
+	Token        *models.Token `validate:"required"`
+	IP           *string       `validate:"omitempty"`
+	File         *models.File  `validate:"required"`
+	Password     *string       `validate:"omitempty,min=1,max=72"`
+	ExpiredAt    *time.Time    `validate:"omitempty,gt"`
+	MaxDownloads *uint64       `validate:"omitempty,min=1"`
+}
+
+// Validate is used to validate service params
+func (sc *ShareCreate) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(sc); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(sc.DB, sc.IP, false, sc.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("ShareCreate.Token", err))
+	}
+
+	if err = ValidateFile(sc.DB, sc.File); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("ShareCreate.File", err))
+	} else if sc.Token != nil {
+		if err = sc.File.CanBeAccessedByToken(sc.Token, sc.DB); err != nil {
+			validateErrors = append(validateErrors, generateErrorByField("ShareCreate.Token", err))
+		} else if sc.File.Hidden == models.Hidden {
+			validateErrors = append(validateErrors, generateErrorByField("ShareCreate.File", ErrReadHiddenFile))
+		}
+	}
+
+	return validateErrors
+}
+
+// Execute is used to create the share link
+func (sc *ShareCreate) Execute(ctx context.Context) (interface{}, error) {
+	if err := sc.Token.UpdateAvailableTimes(-1, sc.DB); err != nil {
+		return nil, err
+	}
+	return models.NewShare(sc.Token, sc.File, sc.Password, sc.ExpiredAt, sc.MaxDownloads, sc.DB)
+}
+
+// ShareListResponse represent the response value of ShareList
+type ShareListResponse struct {
+	Total  int
+	Pages  int
+	Shares []models.Share
+}
+
+// ShareList is used to list the share links of the files in the path of token,
+// from the newest one. The revoked links are excluded.
+type ShareList struct {
+	BaseService
+
+	Token  *models.Token `validate:"required"`
+	IP     *string       `validate:"omitempty"`
+	Offset int           `validate:"omitempty,min=0"`
+	Limit  int           `validate:"required,min=1,max=100"`
+}
+
+// Validate is used to validate service params
+func (sl *ShareList) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(sl); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	if err := ValidateToken(sl.DB, sl.IP, true, sl.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("ShareList.Token", err))
+	}
+	return validateErrors
+}
+
+// Execute is used to find the share links
+func (sl *ShareList) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err      error
+		response = &ShareListResponse{}
+	)
+	if err = sl.Token.UpdateAvailableTimes(-1, sl.DB); err != nil {
+		return nil, err
+	}
+	if response.Total, response.Shares, err = models.FindSharesInPath(
+		&sl.Token.App, sl.Token.Path, sl.Offset, sl.Limit, sl.DB); err != nil {
+		return nil, err
+	}
+	response.Pages = (response.Total + sl.Limit - 1) / sl.Limit
+	return response, nil
+}
+
+// ShareRevoke is used to revoke a share link, it can't be used any more
+type ShareRevoke struct {
+	BaseService
+
+	Token *models.Token `validate:"required"`
+	IP    *string       `validate:"omitempty"`
+	Share *models.Share `validate:"required"`
+}
+
+// Validate is used to validate service params
+func (sr *ShareRevoke) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(sr); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	if err := ValidateToken(sr.DB, sr.IP, false, sr.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("ShareRevoke.Token", err))
+	}
+	if err := validateShareOwner(sr.Token, sr.Share, sr.DB); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("ShareRevoke.Share", err))
+	}
+	return validateErrors
+}
+
+// Execute is used to revoke the share link
+func (sr *ShareRevoke) Execute(ctx context.Context) (interface{}, error) {
+	if err := sr.Token.UpdateAvailableTimes(-1, sr.DB); err != nil {
+		return nil, err
+	}
+	return sr.Share, sr.Share.Revoke(sr.DB)
+}
+
+// ShareDownloadResponse represent the response value of ShareDownload
+type ShareDownloadResponse struct {
+	File   *models.File
+	Reader io.ReadSeeker
+}
+
+// ShareDownload is used to download a file of share link, Path is relative to
+// the shared directory. Count represent whether the request is counted as a
+// download, so that the continued range requests don't exhaust the downloads.
+type ShareDownload struct {
+	BaseService
+
+	Share    *models.Share `validate:"required"`
+	Password string        `validate:"omitempty"`
+	Path     string        `validate:"omitempty,max=1000"`
+	Count    bool          `validate:"omitempty"`
+}
+
+// Validate is used to validate service params
+func (sd *ShareDownload) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(sd); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	if err := validateShare(sd.Share, sd.Password, sd.DB); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("ShareDownload.Share", err))
+	}
+	if !ValidatePath(sd.Path) {
+		validateErrors = append(validateErrors, generateErrorByField("ShareDownload.Path", ErrInvalidPath))
+	}
+	return validateErrors
+}
+
+// Execute is used to open the file, the downloads of share link and file are
+// counted if Count is true
+func (sd *ShareDownload) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err      error
+		response = &ShareDownloadResponse{}
+	)
+	if response.File, err = shareTarget(sd.Share, sd.Path, sd.DB); err != nil {
+		return nil, err
+	}
+	if response.File.IsDir == models.IsDir {
+		return nil, models.ErrReadDir
+	}
+	if sd.Count {
+		if err = sd.Share.IncreaseDownloadCount(sd.DB); err != nil {
+			return nil, err
+		}
+		if err = response.File.IncreaseDownloadCount(sd.DB); err != nil {
+			return nil, err
+		}
+	}
+	if response.Reader, err = response.File.Reader(sd.RootPath, sd.DB); err != nil {
+		return response, err
+	}
+	response.Reader = DefaultRateLimiter().ThrottleReadSeeker(response.Reader, sd.Share.AppID, sd.Share.TokenID)
+	return response, nil
+}
+
+// ShareBrowse is used to list a directory of share link, Path is relative to
+// the shared directory. Hidden and expired files are excluded, directories
+// are listed before files.
+type ShareBrowse struct {
+	BaseService
+
+	Share    *models.Share `validate:"required"`
+	Password string        `validate:"omitempty"`
+	Path     string        `validate:"omitempty,max=1000"`
+	Offset   int           `validate:"omitempty,min=0"`
+	Limit    int           `validate:"required,min=1,max=100"`
+}
+
+// Validate is used to validate service params
+func (sb *ShareBrowse) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(sb); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	if err := validateShare(sb.Share, sb.Password, sb.DB); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("ShareBrowse.Share", err))
+	}
+	if !ValidatePath(sb.Path) {
+		validateErrors = append(validateErrors, generateErrorByField("ShareBrowse.Path", ErrInvalidPath))
+	}
+	return validateErrors
+}
+
+// Execute is used to list the directory
+func (sb *ShareBrowse) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err      error
+		dir      *models.File
+		response = &DirectoryListResponse{}
+		query    *gorm.DB
+	)
+	if dir, err = shareTarget(sb.Share, sb.Path, sb.DB); err != nil {
+		return nil, err
+	}
+	if dir.IsDir == 0 {
+		return nil, ErrListFile
+	}
+	query = sb.DB.Model(&models.File{}).Scopes(models.NotExpired).Where("pid = ? AND hidden = 0", dir.ID)
+	if err = query.Count(&response.Total).Error; err != nil {
+		return nil, err
+	}
+	response.Pages = int(math.Ceil(float64(response.Total) / float64(sb.Limit)))
+	if err = query.Preload("Object").Order("isDir DESC").Order("name ASC").
+		Offset(sb.Offset).Limit(sb.Limit).Find(&response.Files).Error; err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ShareArchive is used to download a directory of share link as a zip archive,
+// Path is relative to the shared directory. The archive is counted as one
+// download of share link, and one download of every file in it.
+type ShareArchive struct {
+	BaseService
+
+	Share    *models.Share `validate:"required"`
+	Password string        `validate:"omitempty"`
+	Path     string        `validate:"omitempty,max=1000"`
+	Writer   io.Writer     `validate:"required"`
+}
+
+// Validate is used to validate service params
+func (sa *ShareArchive) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(sa); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	if err := validateShare(sa.Share, sa.Password, sa.DB); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("ShareArchive.Share", err))
+	}
+	if !ValidatePath(sa.Path) {
+		validateErrors = append(validateErrors, generateErrorByField("ShareArchive.Path", ErrInvalidPath))
+	}
+	return validateErrors
+}
+
+// Execute is used to write the archive, the count of files is returned
+func (sa *ShareArchive) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err    error
+		dir    *models.File
+		count  int
+		writer archiveEntryWriter
+	)
+	if dir, err = shareTarget(sa.Share, sa.Path, sa.DB); err != nil {
+		return nil, err
+	}
+	if dir.IsDir == 0 {
+		return nil, ErrListFile
+	}
+	if err = sa.Share.IncreaseDownloadCount(sa.DB); err != nil {
+		return nil, err
+	}
+	writer = newArchiveEntryWriter(ArchiveZip, sa.Writer)
+	if err = walkArchiveDir(dir, "", sa.DB, func(name string, file *models.File) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if file.IsDir == models.IsDir {
+			return writer.writeDir(name, file)
+		}
+		reader, err := file.Reader(sa.RootPath, sa.DB)
+		if err != nil {
+			return err
+		}
+		if err = file.IncreaseDownloadCount(sa.DB); err != nil {
+			return err
+		}
+		count++
+		return writer.writeFile(name, file, reader)
+	}); err != nil {
+		return nil, err
+	}
+	return count, writer.Close()
+}
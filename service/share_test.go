@@ -0,0 +1,211 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newShareForTest(t *testing.T, password *string, maxDownloads *uint64) (*models.Token, *models.Share, BaseService, func(*testing.T)) {
+	var tempDir = models.NewTempDirForTest()
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+
+	for _, p := range []string{"/share/a.txt", "/share/sub/b.txt", "/share/.c.txt"} {
+		var hidden int8
+		if p == "/share/.c.txt" {
+			hidden = models.Hidden
+		}
+		_, err = models.CreateFileFromReader(&token.App, p, bytes.NewReader([]byte(p)), hidden, &tempDir, trx)
+		assert.Nil(t, err)
+	}
+	dir, err := models.FindFileByPath(&token.App, "/share", trx)
+	assert.Nil(t, err)
+	share, err := models.NewShare(token, dir, password, nil, maxDownloads, trx)
+	assert.Nil(t, err)
+	share, err = models.FindShareByUID(share.UID, trx)
+	assert.Nil(t, err)
+
+	return token, share, BaseService{DB: trx, RootPath: &tempDir}, func(t *testing.T) {
+		down(t)
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}
+}
+
+func TestShareCreate(t *testing.T) {
+	token, share, base, down := newShareForTest(t, nil, nil)
+	defer down(t)
+
+	srv := &ShareCreate{BaseService: base}
+	errValidate := srv.Validate()
+	assert.True(t, errValidate.ContainsErrCode(10098))
+	assert.True(t, errValidate.ContainsErrCode(10099))
+
+	hidden, err := models.FindFileByPath(&token.App, "/share/.c.txt", base.DB)
+	assert.Nil(t, err)
+	srv.Token = token
+	srv.File = hidden
+	assert.Contains(t, srv.Validate().Error(), ErrReadHiddenFile.Error())
+
+	token.Path = "/another"
+	srv.File = &share.File
+	assert.Contains(t, srv.Validate().Error(), models.ErrAccessDenied.Error())
+
+	token.Path = "/"
+	password := "password"
+	srv.Password = &password
+	assert.Nil(t, srv.Validate())
+	value, err := srv.Execute(context.Background())
+	assert.Nil(t, err)
+	created := value.(*models.Share)
+	assert.Nil(t, created.CheckPassword(password))
+	assert.Equal(t, share.FileID, created.FileID)
+}
+
+func TestShareListAndRevoke(t *testing.T) {
+	token, share, base, down := newShareForTest(t, nil, nil)
+	defer down(t)
+
+	listSrv := &ShareList{BaseService: base, Token: token, Limit: 10}
+	assert.Nil(t, listSrv.Validate())
+	value, err := listSrv.Execute(context.Background())
+	assert.Nil(t, err)
+	response := value.(*ShareListResponse)
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, 1, response.Pages)
+	assert.Equal(t, share.UID, response.Shares[0].UID)
+
+	token.Path = "/another"
+	revokeSrv := &ShareRevoke{BaseService: base, Token: token, Share: share}
+	errValidate := revokeSrv.Validate()
+	assert.True(t, errValidate.ContainsErrCode(10107))
+	assert.Contains(t, errValidate.Error(), ErrShareNotMatchToken.Error())
+
+	token.Path = "/"
+	assert.Nil(t, revokeSrv.Validate())
+	_, err = revokeSrv.Execute(context.Background())
+	assert.Nil(t, err)
+
+	value, err = listSrv.Execute(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, value.(*ShareListResponse).Total)
+}
+
+func TestShareDownload(t *testing.T) {
+	var (
+		password     = "password"
+		maxDownloads = uint64(2)
+	)
+	_, share, base, down := newShareForTest(t, &password, &maxDownloads)
+	defer down(t)
+
+	srv := &ShareDownload{BaseService: base, Share: share, Path: "/a.txt", Count: true}
+	errValidate := srv.Validate()
+	assert.True(t, errValidate.ContainsErrCode(10108))
+	assert.Contains(t, errValidate.Error(), models.ErrSharePassword.Error())
+
+	srv.Password = password
+	assert.Nil(t, srv.Validate())
+	value, err := srv.Execute(context.Background())
+	assert.Nil(t, err)
+	response := value.(*ShareDownloadResponse)
+	content, err := ioutil.ReadAll(response.Reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "/share/a.txt", string(content))
+	assert.Equal(t, uint64(1), response.File.DownloadCount)
+	assert.Equal(t, uint64(1), share.DownloadCount)
+
+	srv.Count = false
+	_, err = srv.Execute(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), share.DownloadCount)
+
+	srv.Path = "/"
+	_, err = srv.Execute(context.Background())
+	assert.Equal(t, models.ErrReadDir, err)
+
+	srv.Path = "/.c.txt"
+	_, err = srv.Execute(context.Background())
+	assert.Equal(t, ErrReadHiddenFile, err)
+
+	srv.Path = "/none.txt"
+	_, err = srv.Execute(context.Background())
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+
+	srv.Path = "/sub/b.txt"
+	srv.Count = true
+	_, err = srv.Execute(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, models.ErrShareDownloadsExhausted, srv.Validate()[0].Exception)
+}
+
+func TestValidateShare(t *testing.T) {
+	token, share, base, down := newShareForTest(t, nil, nil)
+	defer down(t)
+
+	assert.Nil(t, validateShare(share, "", base.DB))
+
+	assert.Nil(t, base.DB.Model(token).Update("availableTimes", 0).Error)
+	assert.Equal(t, ErrTokenAvailableTimesExhausted, validateShare(share, "", base.DB))
+
+	expiredAt := time.Now().Add(-time.Hour)
+	assert.Nil(t, base.DB.Model(token).Updates(map[string]interface{}{"availableTimes": -1, "expiredAt": &expiredAt}).Error)
+	assert.Equal(t, ErrTokenExpired, validateShare(share, "", base.DB))
+
+	assert.Nil(t, base.DB.Delete(token).Error)
+	assert.Equal(t, ErrInvalidShare, validateShare(share, "", base.DB))
+}
+
+func TestShareBrowse(t *testing.T) {
+	_, share, base, down := newShareForTest(t, nil, nil)
+	defer down(t)
+
+	srv := &ShareBrowse{BaseService: base, Share: share, Path: "/", Limit: 10}
+	assert.Nil(t, srv.Validate())
+	value, err := srv.Execute(context.Background())
+	assert.Nil(t, err)
+	response := value.(*DirectoryListResponse)
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, "sub", response.Files[0].Name)
+	assert.Equal(t, "a.txt", response.Files[1].Name)
+
+	srv.Path = "/a.txt"
+	_, err = srv.Execute(context.Background())
+	assert.Equal(t, ErrListFile, err)
+}
+
+func TestShareArchive(t *testing.T) {
+	_, share, base, down := newShareForTest(t, nil, nil)
+	defer down(t)
+
+	buf := new(bytes.Buffer)
+	srv := &ShareArchive{BaseService: base, Share: share, Writer: buf}
+	assert.Nil(t, srv.Validate())
+	value, err := srv.Execute(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, value.(int))
+	assert.Equal(t, uint64(1), share.DownloadCount)
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	assert.ElementsMatch(t, []string{"a.txt", "sub/", "sub/b.txt"}, names)
+}
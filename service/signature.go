@@ -0,0 +1,219 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureV2Algorithm is the scheme of Authorization header that carries
+	// the v2 signature, the header looks like:
+	//
+	//   BIGFILE-HMAC-SHA256 Credential=<uid>, SignedHeaders=x-bigfile-content-sha256;x-bigfile-date, Signature=<hex>
+	//
+	// Credential is the uid of token, or the uid of app for the requests that
+	// are signed with the secret of app.
+	SignatureV2Algorithm = "BIGFILE-HMAC-SHA256"
+
+	// SignatureV2DateHeader is the header that carries the unix timestamp of
+	// request, it must be signed
+	SignatureV2DateHeader = "X-Bigfile-Date"
+
+	// SignatureV2ContentHashHeader is the header that carries the hex encoded
+	// sha256 of request body, it must be signed
+	SignatureV2ContentHashHeader = "X-Bigfile-Content-Sha256"
+)
+
+// SignatureV2MaxSkew is the max difference between the date of request and
+// the clock of server, the requests out of it are rejected
+var SignatureV2MaxSkew = 5 * time.Minute
+
+var (
+	// ErrSignatureV2Header represent that the Authorization header is malformed
+	ErrSignatureV2Header = errors.New("authorization header of signature v2 is malformed")
+
+	// ErrSignatureV2Credential represent that the credential of signature doesn't
+	// match the token or app of request
+	ErrSignatureV2Credential = errors.New("credential of signature v2 doesn't match the request")
+
+	// ErrSignatureV2Date represent that the date of request is missing, or it's
+	// out of the allowed clock skew
+	ErrSignatureV2Date = errors.New("date of request is missing or out of the allowed clock skew")
+
+	// ErrSignatureV2ContentHash represent that the signed content hash doesn't
+	// match the body of request
+	ErrSignatureV2ContentHash = errors.New("content hash doesn't match the body of request")
+
+	// ErrSignatureV2Mismatch represent that the signature is wrong
+	ErrSignatureV2Mismatch = errors.New("request signature v2 validate failed")
+)
+
+// SignatureV2 represent the parsed Authorization header of v2 signature,
+// SignedHeaders are lower case
+type SignatureV2 struct {
+	Credential    string
+	SignedHeaders []string
+	Signature     string
+}
+
+// SignatureV2Request represent the parts of request that are signed. For rpc,
+// Method is POST, Path is the full method name, and Header is the metadata.
+// ContentHash is computed by the receiver from the body that it receives.
+type SignatureV2Request struct {
+	Method      string
+	Path        string
+	Query       url.Values
+	Header      http.Header
+	ContentHash string
+}
+
+// IsSignatureV2 represent whether the Authorization header carries the v2
+// signature, the requests without it fall back to the v1 signature
+func IsSignatureV2(authorization string) bool {
+	return strings.HasPrefix(authorization, SignatureV2Algorithm+" ")
+}
+
+// ContentHash return the hex encoded sha256 of body
+func ContentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseSignatureV2 is used to parse the Authorization header of v2 signature
+func ParseSignatureV2(authorization string) (*SignatureV2, error) {
+	if !IsSignatureV2(authorization) {
+		return nil, ErrSignatureV2Header
+	}
+	var signature = &SignatureV2{}
+	for _, part := range strings.Split(strings.TrimPrefix(authorization, SignatureV2Algorithm+" "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return nil, ErrSignatureV2Header
+		}
+		switch kv[0] {
+		case "Credential":
+			signature.Credential = kv[1]
+		case "SignedHeaders":
+			signature.SignedHeaders = strings.Split(strings.ToLower(kv[1]), ";")
+		case "Signature":
+			signature.Signature = kv[1]
+		default:
+			return nil, ErrSignatureV2Header
+		}
+	}
+	if signature.Credential == "" || signature.Signature == "" || len(signature.SignedHeaders) == 0 {
+		return nil, ErrSignatureV2Header
+	}
+	return signature, nil
+}
+
+// canonical return the canonical form of request, it's composed of method,
+// path, the query sorted by key and value, the signed headers and the hash
+// of body, separated by new lines
+func (r *SignatureV2Request) canonical(signedHeaders []string) string {
+	var (
+		builder = new(strings.Builder)
+		keys    = make([]string, 0, len(r.Query))
+		query   = make([]string, 0, len(r.Query))
+	)
+	builder.WriteString(strings.ToUpper(r.Method) + "\n")
+	builder.WriteString(r.Path + "\n")
+	for key := range r.Query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := append([]string{}, r.Query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			query = append(query, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	builder.WriteString(strings.Join(query, "&") + "\n")
+	for _, name := range signedHeaders {
+		var values []string
+		for _, value := range r.Header[http.CanonicalHeaderKey(name)] {
+			values = append(values, strings.TrimSpace(value))
+		}
+		builder.WriteString(name + ":" + strings.Join(values, ",") + "\n")
+	}
+	builder.WriteString("\n" + strings.Join(signedHeaders, ";") + "\n")
+	builder.WriteString(r.ContentHash)
+	return builder.String()
+}
+
+// NewSignatureV2 is used to sign request with the secret of credential, the
+// names of signed headers are lower case
+func NewSignatureV2(r *SignatureV2Request, credential string, signedHeaders []string, secret string) *SignatureV2 {
+	return &SignatureV2{
+		Credential:    credential,
+		SignedHeaders: signedHeaders,
+		Signature:     SignV2(r, signedHeaders, secret),
+	}
+}
+
+// SignV2 return the hex encoded v2 signature of request, it's the HMAC-SHA256
+// of "<algorithm>\n<date>\n<hex sha256 of canonical request>" with secret
+func SignV2(r *SignatureV2Request, signedHeaders []string, secret string) string {
+	canonical := sha256.Sum256([]byte(r.canonical(signedHeaders)))
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(SignatureV2Algorithm + "\n"))
+	_, _ = mac.Write([]byte(r.Header.Get(SignatureV2DateHeader) + "\n"))
+	_, _ = mac.Write([]byte(hex.EncodeToString(canonical[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify is used to verify the signature of request. The date and the content
+// hash headers must be signed, the date must be in the allowed clock skew, and
+// the content hash must match the body that is received. The signatures are
+// compared in constant time.
+func (s *SignatureV2) Verify(r *SignatureV2Request, secret string, now time.Time) error {
+	var signedDate, signedContentHash bool
+	for _, name := range s.SignedHeaders {
+		switch http.CanonicalHeaderKey(name) {
+		case SignatureV2DateHeader:
+			signedDate = true
+		case SignatureV2ContentHashHeader:
+			signedContentHash = true
+		}
+	}
+	if !signedDate || !signedContentHash {
+		return ErrSignatureV2Header
+	}
+
+	timestamp, err := strconv.ParseInt(r.Header.Get(SignatureV2DateHeader), 10, 64)
+	if err != nil {
+		return ErrSignatureV2Date
+	}
+	if skew := now.Sub(time.Unix(timestamp, 0)); skew > SignatureV2MaxSkew || skew < -SignatureV2MaxSkew {
+		return ErrSignatureV2Date
+	}
+
+	if !hmac.Equal([]byte(r.Header.Get(SignatureV2ContentHashHeader)), []byte(r.ContentHash)) {
+		return ErrSignatureV2ContentHash
+	}
+
+	if !hmac.Equal([]byte(SignV2(r, s.SignedHeaders, secret)), []byte(s.Signature)) {
+		return ErrSignatureV2Mismatch
+	}
+	return nil
+}
+
+// Authorization return the value of Authorization header of signature
+func (s *SignatureV2) Authorization() string {
+	return SignatureV2Algorithm + " Credential=" + s.Credential +
+		", SignedHeaders=" + strings.Join(s.SignedHeaders, ";") +
+		", Signature=" + s.Signature
+}
@@ -0,0 +1,92 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSignatureV2RequestForTest(now time.Time, body []byte) *SignatureV2Request {
+	header := http.Header{}
+	header.Set(SignatureV2DateHeader, strconv.FormatInt(now.Unix(), 10))
+	header.Set(SignatureV2ContentHashHeader, ContentHash(body))
+	return &SignatureV2Request{
+		Method:      "POST",
+		Path:        "/api/bigfile/file/create",
+		Query:       url.Values{"token": {"token"}, "path": {"/a b.txt", "/a.txt"}},
+		Header:      header,
+		ContentHash: ContentHash(body),
+	}
+}
+
+func TestParseSignatureV2(t *testing.T) {
+	for _, authorization := range []string{
+		"",
+		"Bearer token",
+		SignatureV2Algorithm + " Credential=uid",
+		SignatureV2Algorithm + " Credential=uid, SignedHeaders=x-bigfile-date, Unknown=a, Signature=b",
+		SignatureV2Algorithm + " Credential=uid, SignedHeaders, Signature=b",
+	} {
+		_, err := ParseSignatureV2(authorization)
+		assert.Equal(t, ErrSignatureV2Header, err, authorization)
+	}
+
+	signature := &SignatureV2{
+		Credential:    "uid",
+		SignedHeaders: []string{"x-bigfile-content-sha256", "x-bigfile-date"},
+		Signature:     "abc",
+	}
+	parsed, err := ParseSignatureV2(signature.Authorization())
+	assert.Nil(t, err)
+	assert.Equal(t, signature, parsed)
+}
+
+func TestSignatureV2_Verify(t *testing.T) {
+	var (
+		now           = time.Now()
+		secret        = "secret"
+		body          = []byte("body")
+		signedHeaders = []string{"x-bigfile-content-sha256", "x-bigfile-date"}
+	)
+
+	request := newSignatureV2RequestForTest(now, body)
+	signature := NewSignatureV2(request, "uid", signedHeaders, secret)
+	assert.Nil(t, signature.Verify(request, secret, now))
+	assert.Equal(t, ErrSignatureV2Mismatch, signature.Verify(request, "another", now))
+
+	// the order of query values doesn't matter
+	request.Query["path"] = []string{"/a.txt", "/a b.txt"}
+	assert.Nil(t, signature.Verify(request, secret, now))
+
+	for _, modify := range []func(r *SignatureV2Request){
+		func(r *SignatureV2Request) { r.Method = "GET" },
+		func(r *SignatureV2Request) { r.Path = "/api/bigfile/file/delete" },
+		func(r *SignatureV2Request) { r.Query.Set("token", "another") },
+	} {
+		request = newSignatureV2RequestForTest(now, body)
+		modify(request)
+		assert.Equal(t, ErrSignatureV2Mismatch, signature.Verify(request, secret, now))
+	}
+
+	request = newSignatureV2RequestForTest(now, body)
+	request.ContentHash = ContentHash([]byte("another body"))
+	assert.Equal(t, ErrSignatureV2ContentHash, signature.Verify(request, secret, now))
+
+	request = newSignatureV2RequestForTest(now, body)
+	assert.Equal(t, ErrSignatureV2Date, signature.Verify(request, secret, now.Add(SignatureV2MaxSkew+time.Second)))
+	assert.Equal(t, ErrSignatureV2Date, signature.Verify(request, secret, now.Add(-SignatureV2MaxSkew-time.Second)))
+	request.Header.Del(SignatureV2DateHeader)
+	assert.Equal(t, ErrSignatureV2Date, signature.Verify(request, secret, now))
+
+	request = newSignatureV2RequestForTest(now, body)
+	signature = NewSignatureV2(request, "uid", []string{"x-bigfile-date"}, secret)
+	assert.Equal(t, ErrSignatureV2Header, signature.Verify(request, secret, now))
+}
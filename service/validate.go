@@ -7,6 +7,7 @@ package service
 import (
 	"errors"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/bigfile/bigfile/databases/models"
@@ -39,6 +40,9 @@ var (
 
 	// ErrInvalidFile represent the file is invalid
 	ErrInvalidFile = errors.New("invalid file")
+
+	// ErrFileTarget represent that both the uid and the path of file are given
+	ErrFileTarget = errors.New("only one of fileUid and path can be given")
 )
 
 // ValidateFile is used to validate whether a file is valid
@@ -49,6 +53,21 @@ func ValidateFile(db *gorm.DB, file *models.File) error {
 	return db.Where("id = ?", file.ID).Find(file).Error
 }
 
+// FindFile is used to find the file by path if it's given, the path is relative
+// to the path of token. Otherwise, the file is found by uid.
+func FindFile(token *models.Token, uid string, path *string, db *gorm.DB) (*models.File, error) {
+	if path == nil {
+		return models.FindFileByUID(uid, false, db)
+	}
+	if uid != "" {
+		return nil, ErrFileTarget
+	}
+	if !ValidatePath(*path) {
+		return nil, ErrInvalidPath
+	}
+	return models.FindFileByPath(&token.App, token.PathWithScope(*path), db)
+}
+
 // ValidateApp is used to validate whether app is valid
 func ValidateApp(db *gorm.DB, app *models.App) error {
 	if app == nil {
@@ -102,6 +121,12 @@ func ValidatePath(path string) bool {
 		}
 	)
 
+	for _, segment := range strings.Split(path, "/") {
+		if segment == ".." {
+			return false
+		}
+	}
+
 	for _, regex := range regexps {
 		if regex.MatchString(path) {
 			return true
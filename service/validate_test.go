@@ -44,6 +44,10 @@ func TestValidatePath(t *testing.T) {
 	assert.True(t, ValidatePath("/test/"))
 	assert.True(t, ValidatePath("/test/hello"))
 	assert.False(t, ValidatePath("/test//"))
+	assert.False(t, ValidatePath("/test/../../etc"))
+	assert.False(t, ValidatePath("../test"))
+	assert.False(t, ValidatePath("/test/.."))
+	assert.True(t, ValidatePath("/test/..hidden"))
 	name := strings.Repeat("s", 255)
 	assert.True(t, ValidatePath("/test/"+name+"/"))
 	assert.False(t, ValidatePath("/test/"+name+"1222/"))
@@ -116,3 +120,33 @@ func TestValidateFile(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Nil(t, ValidateFile(trx, file))
 }
+
+func TestFindFile(t *testing.T) {
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+
+	token.Path = "/scope"
+	dir, err := models.CreateOrGetLastDirectory(&token.App, "/scope/dir", trx)
+	assert.Nil(t, err)
+
+	var path = "/dir"
+	file, err := FindFile(token, "", &path, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, dir.ID, file.ID)
+
+	file, err = FindFile(token, dir.UID, nil, trx)
+	assert.Nil(t, err)
+	assert.Equal(t, dir.ID, file.ID)
+
+	_, err = FindFile(token, dir.UID, &path, trx)
+	assert.Equal(t, ErrFileTarget, err)
+
+	path = "/dir?"
+	_, err = FindFile(token, "", &path, trx)
+	assert.Equal(t, ErrInvalidPath, err)
+
+	path = "/scope/dir"
+	_, err = FindFile(token, "", &path, trx)
+	assert.True(t, util.IsRecordNotFound(err))
+}
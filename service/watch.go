@@ -0,0 +1,163 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const (
+	// DefaultWatchInterval is the interval of checking new changes when the
+	// watcher has caught up with the journal, if it isn't configured by
+	// config.Watch. The watchers are woken immediately by the changes that are
+	// committed in the same process, the interval is only the fallback for the
+	// changes of other instances.
+	DefaultWatchInterval = 5 * time.Second
+	// DefaultWatchHeartbeat is the max idle time of watch, an empty batch with
+	// the latest cursor is sent after that, so that the client is able to save
+	// the cursor, and find the broken connection
+	DefaultWatchHeartbeat = 30 * time.Second
+)
+
+// Watch is used to push the changes in the scope of token continuously, until
+// ctx is done or Send fails. It starts from Cursor, or from now on if Cursor is
+// nil. The journal is checked when the changes of app are committed in the same
+// process, and every Interval for the changes of other instances, config.Watch
+// is used if it's zero. Every batch contains at most Limit changes, and the next batch is read
+// only after Send returns, so a slow consumer is never buffered for: the
+// backpressure of transport, such as the flow control of grpc, slows down the
+// watch, and the consumer catches up from the journal later.
+type Watch struct {
+	BaseService
+
+	Token     *models.Token                   `validate:"required"`
+	IP        *string                         `validate:"omitempty"`
+	SubDir    string                          `validate:"omitempty"`
+	Cursor    *uint64                         `validate:"omitempty"`
+	Limit     int                             `validate:"required,min=1,max=1000"`
+	Interval  time.Duration                   `validate:"omitempty"`
+	Heartbeat time.Duration                   `validate:"omitempty"`
+	Send      func(*ChangeFeedResponse) error `validate:"required"`
+}
+
+// Validate is used to validate params
+func (w *Watch) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+
+	if err = Validate.Struct(w); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateToken(w.DB, w.IP, true, w.Token); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("Watch.Token", err))
+	}
+
+	if !ValidatePath(w.SubDir) {
+		validateErrors = append(validateErrors, generateErrorByField("Watch.SubDir", ErrInvalidPath))
+	}
+
+	return validateErrors
+}
+
+// revalidateToken is used to check the token again before pushing changes, the
+// token may be deleted or expire during a long watch. The available times isn't
+// checked, because it's charged only once at the beginning of watch.
+func (w *Watch) revalidateToken() error {
+	token, err := models.FindTokenByUID(w.Token.UID, w.DB)
+	if err != nil {
+		return err
+	}
+	if w.IP != nil && !token.AllowIPAccess(*w.IP) {
+		return ErrTokenIP
+	}
+	if token.ExpiredAt != nil && token.ExpiredAt.Before(time.Now()) {
+		return ErrTokenExpired
+	}
+	return nil
+}
+
+// Execute is used to watch the changes, it blocks until ctx is done, the last
+// cursor is returned then. One available times of token is charged for the
+// whole watch.
+func (w *Watch) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err       error
+		cursor    uint64
+		changes   *ChangeFeedResponse
+		interval  = w.Interval
+		heartbeat = w.Heartbeat
+		lastSent  = time.Now()
+		scope     = w.Token.PathWithScope(w.SubDir)
+	)
+
+	if interval <= 0 {
+		interval = time.Duration(config.DefaultConfig.WatchInterval) * time.Millisecond
+	}
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	if heartbeat <= 0 {
+		heartbeat = DefaultWatchHeartbeat
+	}
+
+	if err = w.Token.UpdateAvailableTimes(-1, w.DB); err != nil {
+		return nil, err
+	}
+
+	if w.Cursor != nil {
+		cursor = *w.Cursor
+	} else if cursor, err = models.LatestJournalSequence(&w.Token.App, w.DB); err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// the notification is taken before reading, so the changes committed
+		// after reading aren't missed
+		notified := models.JournalNotification(w.Token.AppID)
+
+		if changes, err = fetchChanges(&w.Token.App, scope, cursor, w.Limit, w.DB); err != nil {
+			return nil, err
+		}
+		cursor = changes.Cursor
+
+		if len(changes.Changes) > 0 || time.Since(lastSent) >= heartbeat {
+			if err = w.revalidateToken(); err != nil {
+				return nil, err
+			}
+			if err = w.Send(changes); err != nil {
+				return nil, err
+			}
+			lastSent = time.Now()
+		}
+
+		// read the following batch immediately if the watcher is behind
+		if changes.HasMore {
+			if err = ctx.Err(); err != nil {
+				return cursor, nil
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return cursor, nil
+		case <-notified:
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,106 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch_Validate(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		srv     = &Watch{SubDir: "/!@#"}
+	)
+	_, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+	srv.DB = trx
+
+	errValidate := srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10088))
+	confirm.True(errValidate.ContainsErrCode(10089))
+	confirm.True(errValidate.ContainsErrCode(10090))
+	confirm.True(errValidate.ContainsErrCode(10091))
+}
+
+func TestWatch_Execute(t *testing.T) {
+	var (
+		confirm     = assert.New(t)
+		cursor      uint64
+		batches     []*ChangeFeedResponse
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+	defer cancel()
+	token, trx, down, err := models.NewArbitrarilyTokenForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+
+	_, err = models.CreateOrGetLastDirectory(&token.App, "/watch/a", trx)
+	confirm.Nil(err)
+	_, err = models.CreateOrGetLastDirectory(&token.App, "/other", trx)
+	confirm.Nil(err)
+
+	srv := &Watch{
+		BaseService: BaseService{DB: trx},
+		Token:       token,
+		SubDir:      "/watch",
+		Cursor:      &cursor,
+		Limit:       1,
+		Interval:    time.Millisecond,
+		Send: func(changes *ChangeFeedResponse) error {
+			batches = append(batches, changes)
+			switch len(batches) {
+			case 2:
+				// the change happens while watching
+				_, err := models.CreateOrGetLastDirectory(&token.App, "/watch/b", trx)
+				confirm.Nil(err)
+			case 3:
+				cancel()
+			}
+			return nil
+		},
+	}
+	confirm.Nil(srv.Validate())
+	value, err := srv.Execute(ctx)
+	confirm.Nil(err)
+	confirm.Equal(3, len(batches))
+	confirm.Equal("/watch", batches[0].Changes[0].Path)
+	confirm.True(batches[0].HasMore)
+	confirm.Equal("/watch/a", batches[1].Changes[0].Path)
+	confirm.Equal("/watch/b", batches[2].Changes[0].Path)
+	confirm.Equal(batches[2].Cursor, value.(uint64))
+
+	// resume from the last cursor, only heartbeats are sent
+	lastCursor := value.(uint64)
+	batches = nil
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	srv.Cursor = &lastCursor
+	srv.Heartbeat = time.Nanosecond
+	srv.Send = func(changes *ChangeFeedResponse) error {
+		batches = append(batches, changes)
+		cancel()
+		return nil
+	}
+	_, err = srv.Execute(ctx)
+	confirm.Nil(err)
+	confirm.Equal(1, len(batches))
+	confirm.Empty(batches[0].Changes)
+	confirm.Equal(lastCursor, batches[0].Cursor)
+
+	// the error of send stops watching
+	srv.Send = func(changes *ChangeFeedResponse) error {
+		return errors.New("broken stream")
+	}
+	_, err = srv.Execute(context.Background())
+	confirm.NotNil(err)
+}
@@ -0,0 +1,278 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+var (
+	// ErrInvalidWebhookURL represent that the url of webhook isn't an absolute http or https url
+	ErrInvalidWebhookURL = errors.New("webhook url must be an absolute http or https url")
+	// ErrWebhookHostUnresolved represent that the host of webhook url can't be resolved
+	ErrWebhookHostUnresolved = errors.New("the host of webhook url can't be resolved")
+	// ErrWebhookHostNotAllowed represent that the host of webhook url is a loopback,
+	// private or other reserved address, and it isn't allowed by config.Webhook
+	ErrWebhookHostNotAllowed = errors.New("webhook url can't point to a loopback, private or reserved address")
+	// ErrInvalidWebhookEvent represent that the event can't be subscribed
+	ErrInvalidWebhookEvent = errors.New("webhook event must be one of created, updated, deleted and restored")
+	// ErrWebhookNotMatchApp represent that the webhook doesn't belong to the app
+	ErrWebhookNotMatchApp = errors.New("the webhook doesn't belong to this app")
+)
+
+// reservedNetworks are the networks that webhooks can't be delivered to unless
+// they're allowed by config, such as loopback, private, link-local, which the
+// metadata service of clouds is in, and multicast networks
+var reservedNetworks = parseNetworks(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+	"192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+	"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// webhookAddressAllowed return whether webhooks can be delivered to the ip
+// that host is resolved to, see config.Webhook
+func webhookAddressAllowed(host string, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, allowed := range config.DefaultConfig.WebhookAllowedHosts {
+		if strings.EqualFold(allowed, host) {
+			return true
+		}
+		if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(allowed); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateWebhookURL is used to validate the url of webhook, the host is
+// resolved, and all of its addresses should be allowed
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	ips, err := net.LookupIP(u.Hostname())
+	if err != nil || len(ips) == 0 {
+		return ErrWebhookHostUnresolved
+	}
+	for _, ip := range ips {
+		if !webhookAddressAllowed(u.Hostname(), ip) {
+			return ErrWebhookHostNotAllowed
+		}
+	}
+	return nil
+}
+
+// validateWebhookEvents is used to validate the subscribed events
+func validateWebhookEvents(events []string) error {
+	for _, event := range events {
+		valid := false
+		for _, webhookEvent := range models.WebhookEvents {
+			if event == webhookEvent {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return ErrInvalidWebhookEvent
+		}
+	}
+	return nil
+}
+
+// validateWebhookOwner is used to validate whether the webhook belongs to app
+func validateWebhookOwner(app *models.App, webhook *models.Webhook) error {
+	if webhook == nil || app == nil || webhook.AppID != app.ID {
+		return ErrWebhookNotMatchApp
+	}
+	return nil
+}
+
+// WebhookCreate is used to register a webhook for app. Events are the
+// subscribed events, all events are subscribed if it's empty.
+type WebhookCreate struct {
+	BaseService
+
+	App        *models.App `validate:"required"`
+	URL        string      `validate:"required,max=1000"`
+	Events     []string    `validate:"omitempty,max=4"`
+	PathPrefix string      `validate:"omitempty,max=1000"`
+}
+
+// Validate is used to validate service params
+func (wc *WebhookCreate) Validate() ValidateErrors {
+	var (
+		err            error
+		validateErrors ValidateErrors
+	)
+	if err = Validate.Struct(wc); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+
+	if err = ValidateApp(wc.DB, wc.App); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("WebhookCreate.App", err))
+	}
+
+	if err = validateWebhookURL(wc.URL); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("WebhookCreate.URL", err))
+	}
+
+	if err = validateWebhookEvents(wc.Events); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("WebhookCreate.Events", err))
+	}
+
+	if wc.PathPrefix != "" && !ValidatePath(wc.PathPrefix) {
+		validateErrors = append(validateErrors, generateErrorByField("WebhookCreate.PathPrefix", ErrInvalidPath))
+	}
+
+	return validateErrors
+}
+
+// Execute is used to create the webhook
+func (wc *WebhookCreate) Execute(ctx context.Context) (interface{}, error) {
+	var pathPrefix = wc.PathPrefix
+	if pathPrefix == "" {
+		pathPrefix = "/"
+	}
+	return models.NewWebhook(wc.App, wc.URL, wc.Events, pathPrefix, wc.DB)
+}
+
+// WebhookList is used to list all webhooks of app
+type WebhookList struct {
+	BaseService
+
+	App *models.App `validate:"required"`
+}
+
+// Validate is used to validate service params
+func (wl *WebhookList) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(wl); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	if err := ValidateApp(wl.DB, wl.App); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("WebhookList.App", err))
+	}
+	return validateErrors
+}
+
+// Execute is used to find the webhooks
+func (wl *WebhookList) Execute(ctx context.Context) (interface{}, error) {
+	return models.FindWebhooksByApp(wl.App, wl.DB)
+}
+
+// WebhookDelete is used to delete a webhook, the pending deliveries are given up
+type WebhookDelete struct {
+	BaseService
+
+	App     *models.App     `validate:"required"`
+	Webhook *models.Webhook `validate:"required"`
+}
+
+// Validate is used to validate service params
+func (wd *WebhookDelete) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(wd); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	if err := ValidateApp(wd.DB, wd.App); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("WebhookDelete.App", err))
+	}
+	if err := validateWebhookOwner(wd.App, wd.Webhook); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("WebhookDelete.Webhook", err))
+	}
+	return validateErrors
+}
+
+// Execute is used to delete the webhook
+func (wd *WebhookDelete) Execute(ctx context.Context) (interface{}, error) {
+	return wd.Webhook, wd.Webhook.Delete(wd.DB)
+}
+
+// WebhookDeliveryListResponse represent the response value of WebhookDeliveryList
+type WebhookDeliveryListResponse struct {
+	Total      int
+	Pages      int
+	Deliveries []models.WebhookDelivery
+}
+
+// WebhookDeliveryList is used to inspect the delivery log of webhook, from
+// the newest one. Status is optional, it's used to filter the deliveries.
+type WebhookDeliveryList struct {
+	BaseService
+
+	App     *models.App     `validate:"required"`
+	Webhook *models.Webhook `validate:"required"`
+	Status  *string         `validate:"omitempty,oneof=pending succeeded failed"`
+	Offset  int             `validate:"omitempty,min=0"`
+	Limit   int             `validate:"required,min=1,max=100"`
+}
+
+// Validate is used to validate service params
+func (wdl *WebhookDeliveryList) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(wdl); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	if err := ValidateApp(wdl.DB, wdl.App); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("WebhookDeliveryList.App", err))
+	}
+	if err := validateWebhookOwner(wdl.App, wdl.Webhook); err != nil {
+		validateErrors = append(validateErrors, generateErrorByField("WebhookDeliveryList.Webhook", err))
+	}
+	return validateErrors
+}
+
+// Execute is used to find the deliveries
+func (wdl *WebhookDeliveryList) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err      error
+		response = &WebhookDeliveryListResponse{}
+	)
+	if response.Total, response.Deliveries, err = models.FindWebhookDeliveries(
+		wdl.Webhook, wdl.Status, wdl.Offset, wdl.Limit, wdl.DB); err != nil {
+		return nil, err
+	}
+	response.Pages = (response.Total + wdl.Limit - 1) / wdl.Limit
+	return response, nil
+}
@@ -0,0 +1,220 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/log"
+	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const (
+	// WebhookEventHeader is the header that carries the event of delivery
+	WebhookEventHeader = "X-Bigfile-Event"
+	// WebhookDeliveryHeader is the header that carries the uid of delivery, it's
+	// the same in all attempts, so that the receiver can ignore duplicates
+	WebhookDeliveryHeader = "X-Bigfile-Delivery"
+	// WebhookTimestampHeader is the header that carries the unix time of attempt
+	WebhookTimestampHeader = "X-Bigfile-Timestamp"
+	// WebhookSignatureHeader is the header that carries the signature of delivery
+	WebhookSignatureHeader = "X-Bigfile-Signature"
+
+	// webhookDeliveryTimeout is the timeout of every attempt
+	webhookDeliveryTimeout = 10 * time.Second
+	// webhookDeliveryLease is the time that a claimed delivery is hidden from
+	// other dispatchers, it must be longer than the timeout of attempt
+	webhookDeliveryLease = time.Minute
+)
+
+// ErrWebhookDeleted represent that the webhook of delivery has been deleted
+var ErrWebhookDeleted = errors.New("webhook is deleted")
+
+// SignWebhookPayload return the signature of delivery, it's the hex encoded
+// HMAC-SHA256 of "<timestamp>.<payload>" with the secret of app. The receiver
+// should recompute it, and reject the deliveries with an old timestamp.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookClient is the default client of deliveries, it refuses to connect
+// to the addresses that aren't allowed after the host is resolved, so that the
+// redirects and the hosts that are resolved to other addresses later are
+// checked too. The proxies of environment aren't used.
+var webhookClient = &http.Client{
+	Timeout: webhookDeliveryTimeout,
+	Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			dialer := &net.Dialer{
+				Timeout: webhookDeliveryTimeout,
+				Control: func(_, address string, _ syscall.RawConn) error {
+					ip, _, err := net.SplitHostPort(address)
+					if err != nil {
+						return err
+					}
+					if !webhookAddressAllowed(host, net.ParseIP(ip)) {
+						return ErrWebhookHostNotAllowed
+					}
+					return nil
+				},
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	},
+}
+
+// WebhookDispatch is used to send the due deliveries of webhooks. It's called
+// periodically by the background dispatcher, not by the users. If Client is
+// nil, webhookClient is used.
+type WebhookDispatch struct {
+	BaseService
+
+	Limit  int `validate:"required,min=1,max=1000"`
+	Client *http.Client
+}
+
+// Validate is used to validate service params
+func (wd *WebhookDispatch) Validate() ValidateErrors {
+	var validateErrors ValidateErrors
+	if err := Validate.Struct(wd); err != nil {
+		for _, err := range err.(validator.ValidationErrors) {
+			validateErrors = append(validateErrors, PreDefinedValidateErrors[err.Namespace()])
+		}
+	}
+	return validateErrors
+}
+
+// send is used to make an attempt of delivery, a response with 2xx status code
+// means the delivery is accepted
+func (wd *WebhookDispatch) send(ctx context.Context, delivery *models.WebhookDelivery) (*int, error) {
+	var (
+		err       error
+		req       *http.Request
+		resp      *http.Response
+		client    = wd.Client
+		payload   = []byte(delivery.Payload)
+		timestamp = time.Now().Unix()
+	)
+
+	if delivery.Webhook.ID == 0 {
+		return nil, ErrWebhookDeleted
+	}
+
+	if client == nil {
+		client = webhookClient
+	}
+
+	if req, err = http.NewRequest(http.MethodPost, delivery.Webhook.URL, bytes.NewReader(payload)); err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bigfile-webhook")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.UID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Webhook.App.Secret, timestamp, payload))
+
+	if resp, err = client.Do(req); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return &resp.StatusCode, nil
+}
+
+// Execute is used to send the due deliveries one by one, the number of
+// succeeded deliveries is returned. A failed delivery is retried later with
+// backoff, it doesn't stop the others.
+func (wd *WebhookDispatch) Execute(ctx context.Context) (interface{}, error) {
+	var (
+		err        error
+		count      int
+		claimed    bool
+		deliveries []models.WebhookDelivery
+	)
+
+	if deliveries, err = models.FindDueWebhookDeliveries(wd.Limit, wd.DB); err != nil {
+		return count, err
+	}
+
+	for index := range deliveries {
+		delivery := &deliveries[index]
+		if err = ctx.Err(); err != nil {
+			return count, err
+		}
+		if claimed, err = delivery.Claim(webhookDeliveryLease, wd.DB); err != nil {
+			return count, err
+		}
+		if !claimed {
+			continue
+		}
+		responseCode, sendErr := wd.send(ctx, delivery)
+		if err = delivery.RecordAttempt(responseCode, sendErr, wd.DB); err != nil {
+			return count, err
+		}
+		if sendErr == nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// DispatchWebhooks is used to send the due deliveries every interval until stop
+// is closed, at most limit deliveries are sent in one round.
+func DispatchWebhooks(db *gorm.DB, interval time.Duration, limit int, stop <-chan struct{}) {
+	var (
+		ticker = time.NewTicker(interval)
+		logger = log.MustNewLogger(nil)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			dispatchSrv := &WebhookDispatch{BaseService: BaseService{DB: db}, Limit: limit}
+			if err := dispatchSrv.Validate(); err != nil {
+				logger.Error(err)
+				return
+			}
+			count, err := dispatchSrv.Execute(context.Background())
+			if err != nil {
+				logger.Errorf("dispatch webhooks failed: %s", err)
+			}
+			if count.(int) > 0 {
+				logger.Debugf("%d webhook deliveries have been sent", count)
+			}
+		}
+	}
+}
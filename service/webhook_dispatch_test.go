@@ -0,0 +1,113 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	signature := SignWebhookPayload("secret", 1568160000, []byte(`{"event":"created"}`))
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Equal(t, 7+64, len(signature))
+	assert.Equal(t, signature, SignWebhookPayload("secret", 1568160000, []byte(`{"event":"created"}`)))
+	assert.NotEqual(t, signature, SignWebhookPayload("secret", 1568160001, []byte(`{"event":"created"}`)))
+	assert.NotEqual(t, signature, SignWebhookPayload("other", 1568160000, []byte(`{"event":"created"}`)))
+}
+
+func TestWebhookClient(t *testing.T) {
+	defer func(webhook config.Webhook) { config.DefaultConfig.Webhook = webhook }(config.DefaultConfig.Webhook)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	_, err := webhookClient.Post(receiver.URL, "application/json", nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrWebhookHostNotAllowed.Error())
+
+	config.DefaultConfig.WebhookAllowedHosts = []string{"127.0.0.1"}
+	resp, err := webhookClient.Post(receiver.URL, "application/json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestWebhookDispatch_Validate(t *testing.T) {
+	errValidate := (&WebhookDispatch{}).Validate()
+	assert.NotNil(t, errValidate)
+	assert.True(t, errValidate.ContainsErrCode(10087))
+}
+
+func TestWebhookDispatch_Execute(t *testing.T) {
+	var (
+		confirm  = assert.New(t)
+		failed   = true
+		received []models.WebhookPayload
+	)
+	app, trx, down, err := models.NewAppForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		confirm.Nil(err)
+		timestamp, err := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+		confirm.Nil(err)
+		confirm.Equal(SignWebhookPayload(app.Secret, timestamp, body), r.Header.Get(WebhookSignatureHeader))
+		if failed {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var payload models.WebhookPayload
+		confirm.Nil(json.Unmarshal(body, &payload))
+		confirm.Equal(payload.Event, r.Header.Get(WebhookEventHeader))
+		confirm.Equal(payload.DeliveryUID, r.Header.Get(WebhookDeliveryHeader))
+		received = append(received, payload)
+	}))
+	defer receiver.Close()
+
+	webhook, err := models.NewWebhook(app, receiver.URL, nil, "/save", trx)
+	confirm.Nil(err)
+	_, err = models.CreateOrGetLastDirectory(app, "/save", trx)
+	confirm.Nil(err)
+
+	dispatchSrv := &WebhookDispatch{BaseService: BaseService{DB: trx}, Limit: 10, Client: receiver.Client()}
+	confirm.Nil(dispatchSrv.Validate())
+	count, err := dispatchSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(0, count)
+
+	_, deliveries, err := models.FindWebhookDeliveries(webhook, nil, 0, 10, trx)
+	confirm.Nil(err)
+	confirm.Equal(1, deliveries[0].Attempts)
+	confirm.Equal(500, *deliveries[0].ResponseCode)
+	confirm.Equal(models.WebhookDeliveryPending, deliveries[0].Status)
+
+	// the delivery is retried after backoff
+	failed = false
+	count, err = dispatchSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(0, count)
+	confirm.Nil(trx.Model(&deliveries[0]).Update("nextAttemptAt", deliveries[0].CreatedAt).Error)
+	count, err = dispatchSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(1, count)
+	confirm.Equal(1, len(received))
+	confirm.Equal("/save", received[0].File.Path)
+
+	_, deliveries, err = models.FindWebhookDeliveries(webhook, nil, 0, 10, trx)
+	confirm.Nil(err)
+	confirm.Equal(2, deliveries[0].Attempts)
+	confirm.Equal(models.WebhookDeliverySucceeded, deliveries[0].Status)
+}
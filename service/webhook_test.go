@@ -0,0 +1,140 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bigfile/bigfile/config"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookCreate_Validate(t *testing.T) {
+	var (
+		confirm = assert.New(t)
+		srv     = &WebhookCreate{
+			URL:        "ftp://127.0.0.1/hook",
+			Events:     []string{models.WebhookEventCreated, "moved"},
+			PathPrefix: "/!@#",
+		}
+	)
+	trx, down := models.SetUpTestCaseWithTrx(nil, t)
+	defer down(t)
+	srv.DB = trx
+
+	errValidate := srv.Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10075))
+	confirm.True(errValidate.ContainsErrCode(10076))
+	confirm.True(errValidate.ContainsErrCode(10077))
+	confirm.True(errValidate.ContainsErrCode(10078))
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	defer func(webhook config.Webhook) { config.DefaultConfig.Webhook = webhook }(config.DefaultConfig.Webhook)
+	config.DefaultConfig.WebhookAllowedHosts = nil
+
+	assert.Equal(t, ErrInvalidWebhookURL, validateWebhookURL("/hook"))
+	assert.Nil(t, validateWebhookURL("https://93.184.216.34/hook"))
+	for _, rawURL := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://10.0.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://[fd00::1]/hook",
+	} {
+		assert.Equal(t, ErrWebhookHostNotAllowed, validateWebhookURL(rawURL), rawURL)
+	}
+
+	config.DefaultConfig.WebhookAllowedHosts = []string{"localhost", "10.0.0.0/8", "::1"}
+	assert.Nil(t, validateWebhookURL("http://localhost:8080/hook"))
+	assert.Nil(t, validateWebhookURL("http://10.0.0.1/hook"))
+	assert.Nil(t, validateWebhookURL("http://[::1]/hook"))
+	assert.Equal(t, ErrWebhookHostNotAllowed, validateWebhookURL("http://192.168.0.1/hook"))
+}
+
+func TestWebhookDelete_Validate(t *testing.T) {
+	var confirm = assert.New(t)
+	app, trx, down, err := models.NewAppForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+	other, err := models.NewApp("other", nil, trx)
+	confirm.Nil(err)
+	webhook, err := models.NewWebhook(other, "http://127.0.0.1/hook", nil, "/", trx)
+	confirm.Nil(err)
+
+	errValidate := (&WebhookDelete{BaseService: BaseService{DB: trx}, App: app, Webhook: webhook}).Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10081))
+
+	errValidate = (&WebhookDeliveryList{
+		BaseService: BaseService{DB: trx},
+		App:         app,
+		Webhook:     webhook,
+		Status:      &webhook.URL,
+	}).Validate()
+	confirm.NotNil(errValidate)
+	confirm.True(errValidate.ContainsErrCode(10083))
+	confirm.True(errValidate.ContainsErrCode(10084))
+	confirm.True(errValidate.ContainsErrCode(10086))
+}
+
+func TestWebhook_Execute(t *testing.T) {
+	defer func(webhook config.Webhook) { config.DefaultConfig.Webhook = webhook }(config.DefaultConfig.Webhook)
+	config.DefaultConfig.WebhookAllowedHosts = []string{"127.0.0.1"}
+	var confirm = assert.New(t)
+	app, trx, down, err := models.NewAppForTest(nil, t)
+	confirm.Nil(err)
+	defer down(t)
+
+	createSrv := &WebhookCreate{
+		BaseService: BaseService{DB: trx},
+		App:         app,
+		URL:         "https://127.0.0.1/hook",
+		Events:      []string{models.WebhookEventDeleted},
+	}
+	confirm.Nil(createSrv.Validate())
+	value, err := createSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	webhook := value.(*models.Webhook)
+	confirm.Equal("/", webhook.PathPrefix)
+	confirm.Equal("deleted", webhook.Events)
+
+	listSrv := &WebhookList{BaseService: BaseService{DB: trx}, App: app}
+	confirm.Nil(listSrv.Validate())
+	value, err = listSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Equal(1, len(value.([]models.Webhook)))
+
+	dir, err := models.CreateOrGetLastDirectory(app, "/save", trx)
+	confirm.Nil(err)
+	confirm.Nil(dir.Delete(false, trx))
+
+	deliveryListSrv := &WebhookDeliveryList{
+		BaseService: BaseService{DB: trx},
+		App:         app,
+		Webhook:     webhook,
+		Limit:       10,
+	}
+	confirm.Nil(deliveryListSrv.Validate())
+	value, err = deliveryListSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	response := value.(*WebhookDeliveryListResponse)
+	confirm.Equal(1, response.Total)
+	confirm.Equal(1, response.Pages)
+	confirm.Equal(models.WebhookEventDeleted, response.Deliveries[0].Event)
+
+	deleteSrv := &WebhookDelete{BaseService: BaseService{DB: trx}, App: app, Webhook: webhook}
+	confirm.Nil(deleteSrv.Validate())
+	_, err = deleteSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	value, err = listSrv.Execute(context.TODO())
+	confirm.Nil(err)
+	confirm.Empty(value.([]models.Webhook))
+}
@@ -0,0 +1,282 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package sftp
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/ftp"
+	"github.com/jinzhu/gorm"
+	libSFTP "github.com/pkg/sftp"
+	"goftp.io/server"
+)
+
+// maxPendingBytes is the max size of the data that is written ahead of the
+// current offset, sftp clients send several writes simultaneously, so they
+// may arrive out of order
+const maxPendingBytes = 16 << 20
+
+var (
+	// errNonSequentialWrite represent that the client writes randomly, but files
+	// can only be written sequentially
+	errNonSequentialWrite = errors.New("file can only be written sequentially")
+
+	// errUploadAborted represent that the connection is broken during uploading
+	errUploadAborted = errors.New("upload is aborted")
+)
+
+// convertError convert the errors of models to the errors that are recognized
+// by sftp server, so that the right status codes are sent to clients
+func convertError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case gorm.IsRecordNotFoundError(err):
+		return os.ErrNotExist
+	case err == models.ErrAccessDenied:
+		return libSFTP.ErrSSHFxPermissionDenied
+	}
+	return err
+}
+
+// handler implement the handlers of sftp request server with the ftp driver, so
+// that both protocols share the same semantics
+type handler struct {
+	driver *ftp.Driver
+}
+
+// newHandlers return the sftp handlers of driver
+func newHandlers(driver *ftp.Driver) libSFTP.Handlers {
+	h := &handler{driver: driver}
+	return libSFTP.Handlers{FileGet: h, FilePut: h, FileCmd: h, FileList: h}
+}
+
+// Fileread implement sftp.FileReader
+func (h *handler) Fileread(r *libSFTP.Request) (io.ReaderAt, error) {
+	reader := &readerAt{driver: h.driver, path: r.Filepath}
+	if err := reader.open(0); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// Filewrite implement sftp.FileWriter, file is appended if the client opens it
+// with append flag, otherwise, a new file is created
+func (h *handler) Filewrite(r *libSFTP.Request) (io.WriterAt, error) {
+	var (
+		offset int64
+		flags  = r.Pflags()
+	)
+	if flags.Append {
+		info, err := h.driver.Stat(r.Filepath)
+		if err != nil {
+			return nil, convertError(err)
+		}
+		offset = info.Size()
+	}
+	return newWriterAt(offset, func(reader io.Reader) error {
+		_, err := h.driver.PutFile(r.Filepath, reader, flags.Append)
+		return convertError(err)
+	}), nil
+}
+
+// Filecmd implement sftp.FileCmder, attributes can't be changed, but Setstat
+// is accepted silently, because most clients set the modification time after
+// uploading
+func (h *handler) Filecmd(r *libSFTP.Request) error {
+	switch r.Method {
+	case "Setstat":
+		return nil
+	case "Rename":
+		return convertError(h.driver.Rename(r.Filepath, r.Target))
+	case "Rmdir":
+		return convertError(h.driver.DeleteDir(r.Filepath))
+	case "Remove":
+		return convertError(h.driver.DeleteFile(r.Filepath))
+	case "Mkdir":
+		return convertError(h.driver.MakeDir(r.Filepath))
+	}
+	return libSFTP.ErrSSHFxOpUnsupported
+}
+
+// Filelist implement sftp.FileLister
+func (h *handler) Filelist(r *libSFTP.Request) (libSFTP.ListerAt, error) {
+	switch r.Method {
+	case "List":
+		var infos listerAt
+		if err := h.driver.ListDir(r.Filepath, func(info server.FileInfo) error {
+			infos = append(infos, info)
+			return nil
+		}); err != nil {
+			return nil, convertError(err)
+		}
+		return infos, nil
+	case "Stat":
+		info, err := h.driver.Stat(r.Filepath)
+		if err != nil {
+			return nil, convertError(err)
+		}
+		return listerAt{info}, nil
+	}
+	return nil, libSFTP.ErrSSHFxOpUnsupported
+}
+
+// listerAt implement sftp.ListerAt with a slice of file information
+type listerAt []os.FileInfo
+
+// ListAt implement sftp.ListerAt
+func (l listerAt) ListAt(infos []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(infos, l[offset:])
+	if n < len(infos) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readerAt implement io.ReaderAt with the reader of ftp driver, the reader is
+// reopened when the reads aren't sequential
+type readerAt struct {
+	mu     sync.Mutex
+	driver *ftp.Driver
+	path   string
+	reader io.ReadCloser
+	offset int64
+}
+
+// open is used to open the reader at offset
+func (r *readerAt) open(offset int64) (err error) {
+	if r.reader != nil {
+		r.reader.Close()
+	}
+	if _, r.reader, err = r.driver.GetFile(r.path, offset); err != nil {
+		r.reader = nil
+		return convertError(err)
+	}
+	r.offset = offset
+	return nil
+}
+
+// ReadAt implement io.ReaderAt
+func (r *readerAt) ReadAt(p []byte, offset int64) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reader == nil || r.offset != offset {
+		if err = r.open(offset); err != nil {
+			return 0, err
+		}
+	}
+	n, err = io.ReadFull(r.reader, p)
+	r.offset += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Close implement io.Closer
+func (r *readerAt) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reader == nil {
+		return nil
+	}
+	return r.reader.Close()
+}
+
+// writerAt implement io.WriterAt by piping the data to put, the data that is
+// written ahead of the current offset is buffered until the gap is filled
+type writerAt struct {
+	mu           sync.Mutex
+	writer       *io.PipeWriter
+	offset       int64
+	pending      map[int64][]byte
+	pendingBytes int
+	done         chan error
+	err          error
+	closed       bool
+}
+
+// newWriterAt return a writerAt that starts at offset, put is called in
+// another goroutine to consume the data
+func newWriterAt(offset int64, put func(io.Reader) error) *writerAt {
+	reader, writer := io.Pipe()
+	w := &writerAt{writer: writer, offset: offset, pending: make(map[int64][]byte), done: make(chan error, 1)}
+	go func() {
+		err := put(reader)
+		reader.CloseWithError(err)
+		w.done <- err
+	}()
+	return w
+}
+
+// WriteAt implement io.WriterAt
+func (w *writerAt) WriteAt(p []byte, offset int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if offset < w.offset {
+		return 0, errNonSequentialWrite
+	}
+	if offset > w.offset {
+		if _, ok := w.pending[offset]; ok || w.pendingBytes+len(p) > maxPendingBytes {
+			return 0, errNonSequentialWrite
+		}
+		w.pending[offset] = append([]byte(nil), p...)
+		w.pendingBytes += len(p)
+		return len(p), nil
+	}
+	if err := w.write(p); err != nil {
+		return 0, err
+	}
+	for {
+		data, ok := w.pending[w.offset]
+		if !ok {
+			break
+		}
+		delete(w.pending, w.offset)
+		w.pendingBytes -= len(data)
+		if err := w.write(data); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// write is used to write the data at the current offset into the pipe
+func (w *writerAt) write(p []byte) error {
+	n, err := w.writer.Write(p)
+	w.offset += int64(n)
+	return err
+}
+
+// TransferError implement sftp.TransferError, the upload is aborted when the
+// connection is broken
+func (w *writerAt) TransferError(err error) {
+	w.writer.CloseWithError(errUploadAborted)
+}
+
+// Close implement io.Closer, it waits until the data is saved, the upload is
+// aborted if there is a gap in the data
+func (w *writerAt) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if len(w.pending) > 0 {
+		w.writer.CloseWithError(errNonSequentialWrite)
+	} else {
+		w.writer.Close()
+	}
+	w.err = <-w.done
+	return w.err
+}
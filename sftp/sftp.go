@@ -0,0 +1,311 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+// Package sftp implement the sftp subsystem over ssh, the files are operated by
+// the ftp driver, so both protocols share the same semantics. Users log in like
+// ftp, the username is the uid of app, or the uid of token with prefix "token:",
+// and the password is the secret. The public keys that are authorized for the
+// app or token can be used instead of password. The session of token is scoped
+// to the path of token.
+package sftp
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/bigfile/bigfile/databases"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/ftp"
+	"github.com/bigfile/bigfile/log"
+	"github.com/bigfile/bigfile/service"
+	"github.com/jinzhu/gorm"
+	libSFTP "github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	// ErrTokenPassword represent the password of token is wrong
+	ErrTokenPassword = errors.New("token password validate failed")
+
+	// ErrAppPassword represent the password of app is wrong
+	ErrAppPassword = errors.New("app password validate failed")
+
+	// ErrTokenNotFound represent that wrong token is being used
+	ErrTokenNotFound = errors.New("token not found")
+
+	// ErrPublicKeyNotAuthorized represent that the public key isn't authorized
+	// for the app or token
+	ErrPublicKeyNotAuthorized = errors.New("public key isn't authorized")
+
+	// ErrServerClosed is returned by Serve after the server is closed
+	ErrServerClosed = errors.New("sftp: server closed")
+
+	tokenPrefix = "token:"
+)
+
+const (
+	// appExtension is the key of app uid in the permissions of ssh connection
+	appExtension = "bigfile-app"
+	// tokenExtension is the key of token uid in the permissions of ssh connection
+	tokenExtension = "bigfile-token"
+)
+
+// Server is the ssh server that serves the sftp subsystem
+type Server struct {
+	db       *gorm.DB
+	rootPath *string
+	config   *ssh.ServerConfig
+
+	mu        sync.Mutex
+	closed    bool
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+}
+
+// LoadHostKey is used to load the private key of server from file
+func LoadHostKey(file string) (ssh.Signer, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(content)
+}
+
+// NewServer return a sftp server that is identified by hostKeys, and uses db to
+// save files, the default connection is used if db is nil
+func NewServer(hostKeys []ssh.Signer, db *gorm.DB) *Server {
+	if db == nil {
+		db = databases.MustNewConnection(nil)
+	}
+	s := &Server{
+		db:        db,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	s.config = &ssh.ServerConfig{
+		PasswordCallback:  s.passwordCallback,
+		PublicKeyCallback: s.publicKeyCallback,
+		ServerVersion:     "SSH-2.0-bigfile",
+	}
+	for _, hostKey := range hostKeys {
+		s.config.AddHostKey(hostKey)
+	}
+	return s
+}
+
+// remoteIP return the ip of client
+func remoteIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// findUser is used to find the app and token that the user logs in with, token
+// is nil when logging in with app
+func (s *Server) findUser(conn ssh.ConnMetadata) (app *models.App, token *models.Token, err error) {
+	name := conn.User()
+	if strings.HasPrefix(name, tokenPrefix) {
+		if token, err = models.FindTokenByUID(strings.TrimPrefix(name, tokenPrefix), s.db); err != nil {
+			return nil, nil, ErrTokenNotFound
+		}
+		return &token.App, token, nil
+	}
+	if app, err = models.FindAppByUID(name, s.db); err != nil {
+		return nil, nil, ErrAppPassword
+	}
+	return app, nil, nil
+}
+
+// permissions validate the token, and save the app and token in the permissions
+// of connection
+func (s *Server) permissions(conn ssh.ConnMetadata, app *models.App, token *models.Token) (*ssh.Permissions, error) {
+	permissions := &ssh.Permissions{Extensions: map[string]string{appExtension: app.UID}}
+	if token != nil {
+		ip := remoteIP(conn.RemoteAddr())
+		if err := service.ValidateToken(s.db, &ip, true, token); err != nil {
+			return nil, err
+		}
+		permissions.Extensions[tokenExtension] = token.UID
+	}
+	return permissions, nil
+}
+
+// passwordCallback implement the password authentication, a token without
+// secret accepts any password, just like ftp
+func (s *Server) passwordCallback(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	app, token, err := s.findUser(conn)
+	if err != nil {
+		return nil, err
+	}
+	if token != nil {
+		if token.Secret != nil && subtle.ConstantTimeCompare(password, []byte(*token.Secret)) != 1 {
+			return nil, ErrTokenPassword
+		}
+	} else if subtle.ConstantTimeCompare(password, []byte(app.Secret)) != 1 {
+		return nil, ErrAppPassword
+	}
+	return s.permissions(conn, app, token)
+}
+
+// publicKeyCallback implement the public key authentication
+func (s *Server) publicKeyCallback(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	app, token, err := s.findUser(conn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = models.FindAuthorizedPublicKey(app, token, key, s.db); err != nil {
+		return nil, ErrPublicKeyNotAuthorized
+	}
+	return s.permissions(conn, app, token)
+}
+
+// track is used to add or remove the listener or connection that is closed
+// when the server is closed, it returns false if the server has been closed
+func (s *Server) track(listener net.Listener, conn net.Conn, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if add && s.closed {
+		return false
+	}
+	if listener != nil {
+		if add {
+			s.listeners[listener] = struct{}{}
+		} else {
+			delete(s.listeners, listener)
+		}
+	}
+	if conn != nil {
+		if add {
+			s.conns[conn] = struct{}{}
+		} else {
+			delete(s.conns, conn)
+		}
+	}
+	return true
+}
+
+// ListenAndServe listens on addr and then calls Serve
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts the connections on listener, it always returns a non-nil
+// error, ErrServerClosed is returned after Close
+func (s *Server) Serve(listener net.Listener) error {
+	defer listener.Close()
+	if !s.track(listener, nil, true) {
+		return ErrServerClosed
+	}
+	defer s.track(listener, nil, false)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Close closes all listeners and connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+// handleConn is used to handshake with client and accept the session channels
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	if !s.track(nil, conn, true) {
+		return
+	}
+	defer s.track(nil, conn, false)
+
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		log.MustNewLogger(nil).Debugf("sftp handshake with %s failed: %s", conn.RemoteAddr(), err)
+		return
+	}
+	defer serverConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			log.MustNewLogger(nil).Debugf("sftp accept channel failed: %s", err)
+			continue
+		}
+		go s.handleSession(serverConn, channel, requests)
+	}
+}
+
+// handleSession serves the sftp subsystem, the other requests are rejected
+func (s *Server) handleSession(conn *ssh.ServerConn, channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for request := range requests {
+		var payload struct{ Name string }
+		ok := request.Type == "subsystem" &&
+			ssh.Unmarshal(request.Payload, &payload) == nil && payload.Name == "sftp"
+		_ = request.Reply(ok, nil)
+		if ok {
+			go ssh.DiscardRequests(requests)
+			if err := s.serveSFTP(conn, channel); err != nil && err != io.EOF {
+				log.MustNewLogger(nil).Debugf("sftp session of %s exits: %s", conn.User(), err)
+			}
+			return
+		}
+	}
+}
+
+// serveSFTP serves the sftp requests with the driver of app or token
+func (s *Server) serveSFTP(conn *ssh.ServerConn, channel ssh.Channel) (err error) {
+	var (
+		app    *models.App
+		token  *models.Token
+		driver *ftp.Driver
+		ip     = remoteIP(conn.RemoteAddr())
+	)
+	if app, err = models.FindAppByUID(conn.Permissions.Extensions[appExtension], s.db); err != nil {
+		return err
+	}
+	if tokenUID, ok := conn.Permissions.Extensions[tokenExtension]; ok {
+		if token, err = models.FindTokenByUID(tokenUID, s.db); err != nil {
+			return err
+		}
+	}
+	if driver, err = ftp.NewDriver(app, token, &ip, s.rootPath, s.db); err != nil {
+		return err
+	}
+	server := libSFTP.NewRequestServer(channel, newHandlers(driver))
+	defer server.Close()
+	return server.Serve()
+}
@@ -0,0 +1,278 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package sftp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/jinzhu/gorm"
+	libSFTP "github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func newSignerForTest(t *testing.T) ssh.Signer {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	assert.Nil(t, err)
+	return signer
+}
+
+func newServerForTest(t *testing.T, db *gorm.DB) (addr string, down func()) {
+	server := NewServer([]ssh.Signer{newSignerForTest(t)}, db)
+	tempDir := models.NewTempDirForTest()
+	server.rootPath = &tempDir
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go server.Serve(listener)
+	return listener.Addr().String(), func() {
+		assert.Nil(t, server.Close())
+		if util.IsDir(tempDir) {
+			os.RemoveAll(tempDir)
+		}
+	}
+}
+
+func dialForTest(addr, user string, auth ssh.AuthMethod) (*libSFTP.Client, func(), error) {
+	conn, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := libSFTP.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return client, func() {
+		client.Close()
+		conn.Close()
+	}, nil
+}
+
+func TestRemoteIP(t *testing.T) {
+	assert.Equal(t, "127.0.0.1", remoteIP(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 22}))
+	assert.Equal(t, "::1", remoteIP(&net.TCPAddr{IP: net.ParseIP("::1"), Port: 22}))
+}
+
+func TestConvertError(t *testing.T) {
+	assert.Nil(t, convertError(nil))
+	assert.Equal(t, os.ErrNotExist, convertError(gorm.ErrRecordNotFound))
+	assert.Equal(t, libSFTP.ErrSSHFxPermissionDenied, convertError(models.ErrAccessDenied))
+	assert.Equal(t, models.ErrFileExisted, convertError(models.ErrFileExisted))
+}
+
+func TestListerAt(t *testing.T) {
+	var (
+		lister = listerAt{nil, nil, nil}
+		infos  = make([]os.FileInfo, 2)
+	)
+	n, err := lister.ListAt(infos, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	n, err = lister.ListAt(infos, 2)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 1, n)
+	n, err = lister.ListAt(infos, 3)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestWriterAt(t *testing.T) {
+	var (
+		content []byte
+		put     = func(reader io.Reader) (err error) {
+			content, err = ioutil.ReadAll(reader)
+			return err
+		}
+	)
+
+	// the writes out of order are buffered
+	writer := newWriterAt(0, put)
+	n, err := writer.WriteAt([]byte("world"), 6)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, n)
+	_, err = writer.WriteAt([]byte("!"), 11)
+	assert.Nil(t, err)
+	_, err = writer.WriteAt([]byte("hello "), 0)
+	assert.Nil(t, err)
+	_, err = writer.WriteAt([]byte("again"), 3)
+	assert.Equal(t, errNonSequentialWrite, err)
+	assert.Nil(t, writer.Close())
+	assert.Nil(t, writer.Close())
+	assert.Equal(t, "hello world!", string(content))
+
+	// the upload is aborted if there is a gap
+	writer = newWriterAt(3, put)
+	_, err = writer.WriteAt([]byte("world"), 6)
+	assert.Nil(t, err)
+	assert.Equal(t, errNonSequentialWrite, writer.Close())
+
+	writer = newWriterAt(0, put)
+	_, err = writer.WriteAt(make([]byte, maxPendingBytes+1), 1)
+	assert.Equal(t, errNonSequentialWrite, err)
+	writer.TransferError(io.ErrUnexpectedEOF)
+	assert.Equal(t, errUploadAborted, writer.Close())
+
+	// the error of put is returned
+	putErr := errors.New("put failed")
+	writer = newWriterAt(0, func(reader io.Reader) error { return putErr })
+	assert.Equal(t, putErr, writer.Close())
+}
+
+func TestServer_Authenticate(t *testing.T) {
+	var (
+		secret      = models.RandomWithMD5(32)
+		expiredAt   = time.Now().Add(-time.Hour)
+		tokenSigner = newSignerForTest(t)
+		appSigner   = newSignerForTest(t)
+	)
+	token, trx, down, err := models.NewTokenForTest(nil, t, "/", nil, nil, &secret, -1, 0)
+	assert.Nil(t, err)
+	defer down(t)
+	addr, closeServer := newServerForTest(t, trx)
+	defer closeServer()
+
+	_, closeClient, err := dialForTest(addr, token.App.UID, ssh.Password(token.App.Secret))
+	assert.Nil(t, err)
+	closeClient()
+	_, _, err = dialForTest(addr, token.App.UID, ssh.Password("wrong"))
+	assert.NotNil(t, err)
+
+	_, closeClient, err = dialForTest(addr, tokenPrefix+token.UID, ssh.Password(secret))
+	assert.Nil(t, err)
+	closeClient()
+	_, _, err = dialForTest(addr, tokenPrefix+token.UID, ssh.Password("wrong"))
+	assert.NotNil(t, err)
+	_, _, err = dialForTest(addr, tokenPrefix+"not-exist", ssh.Password(secret))
+	assert.NotNil(t, err)
+
+	// public key must be authorized for the app or token
+	_, _, err = dialForTest(addr, tokenPrefix+token.UID, ssh.PublicKeys(tokenSigner))
+	assert.NotNil(t, err)
+	_, err = models.NewPublicKey(&token.App, token, string(ssh.MarshalAuthorizedKey(tokenSigner.PublicKey())), trx)
+	assert.Nil(t, err)
+	_, err = models.NewPublicKey(&token.App, nil, string(ssh.MarshalAuthorizedKey(appSigner.PublicKey())), trx)
+	assert.Nil(t, err)
+	_, closeClient, err = dialForTest(addr, tokenPrefix+token.UID, ssh.PublicKeys(tokenSigner))
+	assert.Nil(t, err)
+	closeClient()
+	_, closeClient, err = dialForTest(addr, token.App.UID, ssh.PublicKeys(appSigner))
+	assert.Nil(t, err)
+	closeClient()
+	_, _, err = dialForTest(addr, token.App.UID, ssh.PublicKeys(tokenSigner))
+	assert.NotNil(t, err)
+	_, _, err = dialForTest(addr, tokenPrefix+token.UID, ssh.PublicKeys(appSigner))
+	assert.NotNil(t, err)
+
+	// expired token can't log in
+	assert.Nil(t, trx.Model(token).Update("expiredAt", &expiredAt).Error)
+	_, _, err = dialForTest(addr, tokenPrefix+token.UID, ssh.Password(secret))
+	assert.NotNil(t, err)
+}
+
+func TestServer_Files(t *testing.T) {
+	app, trx, down, err := models.NewAppForTest(nil, t)
+	assert.Nil(t, err)
+	defer down(t)
+	addr, closeServer := newServerForTest(t, trx)
+	defer closeServer()
+
+	client, closeClient, err := dialForTest(addr, app.UID, ssh.Password(app.Secret))
+	assert.Nil(t, err)
+	defer closeClient()
+
+	assert.Nil(t, client.Mkdir("/dir"))
+	info, err := client.Stat("/dir")
+	assert.Nil(t, err)
+	assert.True(t, info.IsDir())
+
+	content := models.Random(1024)
+	file, err := client.Create("/dir/file.bytes")
+	assert.Nil(t, err)
+	_, err = file.Write(content)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	info, err = client.Stat("/dir/file.bytes")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1024), info.Size())
+	infos, err := client.ReadDir("/dir")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(infos))
+	assert.Equal(t, "file.bytes", infos[0].Name())
+
+	file, err = client.Open("/dir/file.bytes")
+	assert.Nil(t, err)
+	readContent, err := ioutil.ReadAll(file)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+	assert.True(t, bytes.Equal(content, readContent))
+
+	// file is appended with append flag
+	file, err = client.OpenFile("/dir/file.bytes", os.O_WRONLY|os.O_APPEND)
+	assert.Nil(t, err)
+	_, err = file.Write([]byte("appended"))
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+	info, err = client.Stat("/dir/file.bytes")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1032), info.Size())
+
+	_, err = client.Open("/dir/not-exist.bytes")
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, client.Rename("/dir/file.bytes", "/dir/renamed.bytes"))
+	_, err = models.FindFileByPath(app, "/dir/renamed.bytes", trx)
+	assert.Nil(t, err)
+	assert.Nil(t, client.Remove("/dir/renamed.bytes"))
+	_, err = client.Stat("/dir/renamed.bytes")
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, client.RemoveDirectory("/dir"))
+	_, err = client.Stat("/dir")
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NotNil(t, client.Symlink("/a", "/b"))
+}
+
+func TestServer_Token(t *testing.T) {
+	token, trx, down, err := models.NewTokenForTest(nil, t, "/scope", nil, nil, nil, -1, 0)
+	assert.Nil(t, err)
+	defer down(t)
+	addr, closeServer := newServerForTest(t, trx)
+	defer closeServer()
+
+	client, closeClient, err := dialForTest(addr, tokenPrefix+token.UID, ssh.Password("any"))
+	assert.Nil(t, err)
+	defer closeClient()
+
+	file, err := client.Create("/file.bytes")
+	assert.Nil(t, err)
+	_, err = file.Write(models.Random(22))
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	_, err = models.FindFileByPath(&token.App, "/scope/file.bytes", trx)
+	assert.Nil(t, err)
+	info, err := client.Stat("/file.bytes")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(22), info.Size())
+}
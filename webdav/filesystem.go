@@ -0,0 +1,484 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package webdav
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/jinzhu/gorm"
+	"golang.org/x/net/webdav"
+)
+
+var (
+	// errIsDir represent that a directory is opened for writing
+	errIsDir = errors.New("file is a directory")
+	// errNotDir represent that a file is read as a directory
+	errNotDir = errors.New("file is not a directory")
+	// errUploadAborted represent that the content of upload is incomplete
+	errUploadAborted = errors.New("upload is aborted")
+)
+
+// fileSystem implement webdav.FileSystem with the file tree of app, the names
+// of files are relative to root, it's the path of token, or "/" for app.
+// A fileSystem is created for every request.
+type fileSystem struct {
+	db       *gorm.DB
+	app      *models.App
+	root     string
+	owner    string
+	rootPath *string
+	// readErr is the first error of reading request body or files, the uploads
+	// are discarded if it isn't nil
+	readErr error
+}
+
+// recordError is used to record the error of reading, io.EOF is ignored
+func (fs *fileSystem) recordError(err error) {
+	if err != nil && err != io.EOF && fs.readErr == nil {
+		fs.readErr = err
+	}
+}
+
+// path return the full path of name, name can't go beyond root
+func (fs *fileSystem) path(name string) string {
+	return path.Join(fs.root, path.Clean("/"+name))
+}
+
+// name return the name of file by its full path
+func (fs *fileSystem) name(fullPath string) string {
+	return path.Clean("/" + strings.TrimPrefix(fullPath, strings.TrimSuffix(fs.root, "/")))
+}
+
+// contains represent whether the full path is in the scope of file system
+func (fs *fileSystem) contains(fullPath string) bool {
+	return fs.root == "/" || fullPath == fs.root || strings.HasPrefix(fullPath, fs.root+"/")
+}
+
+// transaction is used to execute fn in a transaction, it's committed only if
+// fn returns nil. The journal sequence of app is locked first.
+func (fs *fileSystem) transaction(ctx context.Context, fn func(db *gorm.DB) error) (err error) {
+	if util.InTransaction(fs.db) {
+		if err = models.LockJournalSequence(fs.app.ID, fs.db); err != nil {
+			return err
+		}
+		return fn(fs.db)
+	}
+	trx := fs.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	defer func() {
+		if reErr := recover(); reErr != nil {
+			trx.Rollback()
+			panic(reErr)
+		}
+	}()
+	if err = models.LockJournalSequence(fs.app.ID, trx); err == nil {
+		err = fn(trx)
+	}
+	if err != nil {
+		trx.Rollback()
+		return err
+	}
+	if err = trx.Commit().Error; err == nil {
+		models.NotifyJournal(fs.app.ID)
+	}
+	return err
+}
+
+// find is used to find the file of full path, os.ErrNotExist is returned if
+// it doesn't exist or has expired. The root of token is created if it's missing.
+func (fs *fileSystem) find(fullPath string) (file *models.File, err error) {
+	if fullPath == fs.root {
+		return models.CreateOrGetLastDirectory(fs.app, fullPath, fs.db)
+	}
+	if file, err = models.FindFileByPath(fs.app, fullPath, fs.db); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	expired, err := file.IsExpired(fs.db)
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, os.ErrNotExist
+	}
+	return file, nil
+}
+
+// findDir is used to find the parent directory of full path
+func (fs *fileSystem) findDir(fullPath string) (*models.File, error) {
+	dir, err := fs.find(path.Dir(fullPath))
+	if err != nil {
+		return nil, err
+	}
+	if dir.IsDir != models.IsDir {
+		return nil, os.ErrNotExist
+	}
+	return dir, nil
+}
+
+// stat return the information of file, the object of file is loaded
+func (fs *fileSystem) stat(file *models.File) (*fileInfo, error) {
+	if file.IsDir == 0 && file.Object.ID == 0 {
+		if err := fs.db.Where("id = ?", file.ObjectID).First(&file.Object).Error; err != nil {
+			return nil, err
+		}
+	}
+	return newFileInfo(file), nil
+}
+
+// writeFile is used to save the content of reader to the file at full path. An
+// existing file is overwritten, and its previous content is kept in histories.
+// A deleted file is restored before it's overwritten.
+func (fs *fileSystem) writeFile(fullPath string, reader io.Reader, db *gorm.DB) (*models.File, error) {
+	file, err := models.FindFileByPathWithTrashed(fs.app, fullPath, db)
+	if err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return nil, err
+		}
+		return models.CreateFileFromReader(fs.app, fullPath, reader, 0, fs.rootPath, db)
+	}
+	if file.DeletedAt != nil {
+		if err = file.Restore(db); err != nil {
+			return nil, err
+		}
+	}
+	return file, file.OverWriteFromReader(reader, file.Hidden, fs.rootPath, db)
+}
+
+// Mkdir implement webdav.FileSystem, the parent directory must exist
+func (fs *fileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	fullPath := fs.path(name)
+	if _, err := fs.find(fullPath); err == nil {
+		return os.ErrExist
+	}
+	if _, err := fs.findDir(fullPath); err != nil {
+		return err
+	}
+	return fs.transaction(ctx, func(db *gorm.DB) error {
+		if err := models.CheckPathLock(fs.app, fullPath, fs.owner, db); err != nil {
+			return err
+		}
+		_, err := models.CreateOrGetLastDirectory(fs.app, fullPath, db)
+		return err
+	})
+}
+
+// OpenFile implement webdav.FileSystem. A file can be opened for reading, or
+// for writing from scratch with os.O_TRUNC, random writing isn't supported.
+// The content that is written is streamed into the object of file, and it's
+// committed when the file is closed.
+func (fs *fileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	var (
+		err      error
+		file     *models.File
+		fullPath = fs.path(name)
+	)
+
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		if file, err = fs.find(fullPath); err != nil {
+			return nil, err
+		}
+		info, err := fs.stat(file)
+		if err != nil {
+			return nil, err
+		}
+		return &davFile{fs: fs, file: file, info: info}, nil
+	}
+
+	if flag&os.O_TRUNC == 0 {
+		return nil, os.ErrPermission
+	}
+	if file, err = fs.find(fullPath); err == nil {
+		if flag&os.O_EXCL != 0 {
+			return nil, os.ErrExist
+		}
+		if file.IsDir == models.IsDir {
+			return nil, errIsDir
+		}
+	} else if err != os.ErrNotExist || flag&os.O_CREATE == 0 {
+		return nil, err
+	}
+	if _, err = fs.findDir(fullPath); err != nil {
+		return nil, err
+	}
+	if err = models.CheckPathLock(fs.app, fullPath, fs.owner, fs.db); err != nil {
+		return nil, err
+	}
+
+	reader, writer := io.Pipe()
+	f := &davFile{
+		fs:     fs,
+		info:   &fileInfo{name: path.Base(fullPath), modTime: time.Now()},
+		writer: writer,
+		done:   make(chan error, 1),
+	}
+	go func() {
+		err := fs.transaction(ctx, func(db *gorm.DB) (err error) {
+			f.file, err = fs.writeFile(fullPath, reader, db)
+			return err
+		})
+		// unblock the writer if the content isn't read up
+		reader.CloseWithError(err)
+		f.done <- err
+	}()
+	return f, nil
+}
+
+// RemoveAll implement webdav.FileSystem, the root can't be removed
+func (fs *fileSystem) RemoveAll(ctx context.Context, name string) error {
+	fullPath := fs.path(name)
+	if fullPath == fs.root {
+		return os.ErrPermission
+	}
+	file, err := fs.find(fullPath)
+	if err != nil {
+		if err == os.ErrNotExist {
+			return nil
+		}
+		return err
+	}
+	return fs.transaction(ctx, func(db *gorm.DB) error {
+		if err := file.CheckLock(fs.owner, db); err != nil {
+			return err
+		}
+		return file.Delete(true, db)
+	})
+}
+
+// Rename implement webdav.FileSystem, the parent of new name must exist
+func (fs *fileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	var (
+		oldPath = fs.path(oldName)
+		newPath = fs.path(newName)
+	)
+	if oldPath == fs.root || newPath == fs.root {
+		return os.ErrPermission
+	}
+	file, err := fs.find(oldPath)
+	if err != nil {
+		return err
+	}
+	if _, err = fs.findDir(newPath); err != nil {
+		return err
+	}
+	return fs.transaction(ctx, func(db *gorm.DB) error {
+		if err := file.CheckLock(fs.owner, db); err != nil {
+			return err
+		}
+		if err := models.CheckPathLock(fs.app, newPath, fs.owner, db); err != nil {
+			return err
+		}
+		return file.MoveTo(newPath, db)
+	})
+}
+
+// Stat implement webdav.FileSystem
+func (fs *fileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	file, err := fs.find(fs.path(name))
+	if err != nil {
+		return nil, err
+	}
+	return fs.stat(file)
+}
+
+// davFile implement webdav.File. A file that is opened for reading is read
+// lazily, and a file that is opened for writing pipes the content to the
+// goroutine that saves it.
+type davFile struct {
+	fs       *fileSystem
+	file     *models.File
+	info     *fileInfo
+	reader   io.ReadSeeker
+	children []os.FileInfo
+	offset   int
+	writer   *io.PipeWriter
+	written  int64
+	done     chan error
+}
+
+// open is used to open the reader of file
+func (f *davFile) open() (err error) {
+	if f.writer != nil || f.file.IsDir == models.IsDir {
+		return errIsDir
+	}
+	if f.reader == nil {
+		f.reader, err = f.file.Reader(f.fs.rootPath, f.fs.db)
+	}
+	return err
+}
+
+// Read implement io.Reader
+func (f *davFile) Read(p []byte) (n int, err error) {
+	if err = f.open(); err != nil {
+		return 0, err
+	}
+	n, err = f.reader.Read(p)
+	f.fs.recordError(err)
+	return n, err
+}
+
+// Seek implement io.Seeker
+func (f *davFile) Seek(offset int64, whence int) (int64, error) {
+	if err := f.open(); err != nil {
+		return 0, err
+	}
+	return f.reader.Seek(offset, whence)
+}
+
+// Write implement io.Writer
+func (f *davFile) Write(p []byte) (n int, err error) {
+	if f.writer == nil {
+		return 0, os.ErrPermission
+	}
+	n, err = f.writer.Write(p)
+	f.written += int64(n)
+	return n, err
+}
+
+// Readdir implement http.File, the children are sorted by name, directories
+// come first
+func (f *davFile) Readdir(count int) ([]os.FileInfo, error) {
+	if f.file == nil || f.file.IsDir != models.IsDir {
+		return nil, errNotDir
+	}
+	if f.children == nil {
+		var children []models.File
+		if err := f.fs.db.Preload("Object").Scopes(models.NotExpired).
+			Where("pid = ?", f.file.ID).Order("isDir DESC, name").Find(&children).Error; err != nil {
+			return nil, err
+		}
+		f.children = make([]os.FileInfo, 0, len(children))
+		for index := range children {
+			f.children = append(f.children, newFileInfo(&children[index]))
+		}
+	}
+
+	rest := f.children[f.offset:]
+	if count <= 0 {
+		f.offset = len(f.children)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	f.offset += count
+	return rest[:count], nil
+}
+
+// Stat implement http.File. The information of file that is being written is
+// updated after it's closed.
+func (f *davFile) Stat() (os.FileInfo, error) {
+	if f.writer != nil {
+		f.info.size = f.written
+	}
+	return f.info, nil
+}
+
+// Close implement io.Closer, the content that is written is committed, unless
+// reading the source of content has failed
+func (f *davFile) Close() error {
+	if f.writer == nil {
+		return nil
+	}
+	if f.fs.readErr != nil {
+		_ = f.writer.CloseWithError(errUploadAborted)
+	} else {
+		_ = f.writer.Close()
+	}
+	err := <-f.done
+	f.writer = nil
+	if err != nil {
+		return err
+	}
+	*f.info = *newFileInfo(f.file)
+	return nil
+}
+
+// fileInfo implement os.FileInfo, webdav.ETager and webdav.ContentTyper
+type fileInfo struct {
+	name    string
+	size    int64
+	isDir   bool
+	modTime time.Time
+	hash    string
+}
+
+// newFileInfo return the information of file, the object must be loaded
+func newFileInfo(file *models.File) *fileInfo {
+	return &fileInfo{
+		name:    file.Name,
+		size:    int64(file.Size),
+		isDir:   file.IsDir == models.IsDir,
+		modTime: file.UpdatedAt,
+		hash:    file.Object.Hash,
+	}
+}
+
+// Name return the name of file or directory
+func (f *fileInfo) Name() string {
+	if f.name == "" {
+		return "/"
+	}
+	return f.name
+}
+
+// Size return the size of file or directory
+func (f *fileInfo) Size() int64 {
+	return f.size
+}
+
+// Mode returns a file's mode and permission bits.
+func (f *fileInfo) Mode() os.FileMode {
+	if f.isDir {
+		return os.ModePerm | os.ModeDir
+	}
+	return os.ModePerm
+}
+
+// ModTime is used to return the modify time of file
+func (f *fileInfo) ModTime() time.Time {
+	return f.modTime
+}
+
+// IsDir represent whether the object is a directory
+func (f *fileInfo) IsDir() bool {
+	return f.isDir
+}
+
+// Sys always return nil
+func (f *fileInfo) Sys() interface{} {
+	return nil
+}
+
+// ETag return the quoted sha256 hash of content, the default entity tag is
+// used for directories
+func (f *fileInfo) ETag(ctx context.Context) (string, error) {
+	if f.isDir || f.hash == "" {
+		return "", webdav.ErrNotImplemented
+	}
+	return `"` + f.hash + `"`, nil
+}
+
+// ContentType return the mime type of file by its extension
+func (f *fileInfo) ContentType(ctx context.Context) (string, error) {
+	if mimeType := mime.TypeByExtension(path.Ext(f.name)); mimeType != "" {
+		return mimeType, nil
+	}
+	return "application/octet-stream", nil
+}
@@ -0,0 +1,182 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package webdav
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	"golang.org/x/net/webdav"
+)
+
+const (
+	// lockTokenPrefix is the scheme of lock tokens, the uid of file lock follows it
+	lockTokenPrefix = "opaquelocktoken:"
+	// maxLockDuration is the max lease of lock, infinite locks are limited to it
+	maxLockDuration = 24 * time.Hour
+)
+
+// lockSystem implement webdav.LockSystem with file locks, so the locks of WebDAV
+// clients are respected by other protocols, and vice versa. Only the LOCK
+// request creates file locks, which are exclusive and have infinite depth. A
+// lock must be attached to a file, so locking a missing resource creates an
+// empty file. The other requests create temporary locks to check that the
+// resources aren't locked by others, they don't need to be saved, because the
+// locks are owned by the app or token, rather than by the request.
+type lockSystem struct {
+	fs       *fileSystem
+	explicit bool
+}
+
+// lockDuration return the lease of lock, negative duration means infinite
+func lockDuration(duration time.Duration) time.Duration {
+	if duration < 0 || duration > maxLockDuration {
+		return maxLockDuration
+	}
+	return duration
+}
+
+// find is used to find the lock of token and the locked file, the lock must be
+// in the scope of file system
+func (l *lockSystem) find(token string) (*models.FileLock, *models.File, error) {
+	var (
+		err  error
+		lock *models.FileLock
+		file = &models.File{}
+	)
+	if !strings.HasPrefix(token, lockTokenPrefix) {
+		return nil, nil, webdav.ErrNoSuchLock
+	}
+	if lock, err = models.FindFileLockByUID(strings.TrimPrefix(token, lockTokenPrefix), l.fs.db); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil, webdav.ErrNoSuchLock
+		}
+		return nil, nil, err
+	}
+	if lock.AppID != l.fs.app.ID {
+		return nil, nil, webdav.ErrNoSuchLock
+	}
+	if err = l.fs.db.Where("id = ?", lock.FileID).First(file).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil, webdav.ErrNoSuchLock
+		}
+		return nil, nil, err
+	}
+	if !l.fs.contains(file.FullPath) {
+		return nil, nil, webdav.ErrNoSuchLock
+	}
+	return lock, file, nil
+}
+
+// Confirm implement webdav.LockSystem, the names must be covered by the locks
+// of conditions, which are held by the owner of file system
+func (l *lockSystem) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (func(), error) {
+	var paths []string
+	for _, condition := range conditions {
+		if condition.Not || condition.Token == "" {
+			continue
+		}
+		lock, file, err := l.find(condition.Token)
+		if err != nil {
+			if err == webdav.ErrNoSuchLock {
+				continue
+			}
+			return nil, err
+		}
+		if lock.Owner == l.fs.owner && !lock.IsExpired() {
+			paths = append(paths, file.FullPath)
+		}
+	}
+
+	for _, name := range []string{name0, name1} {
+		if name == "" {
+			continue
+		}
+		fullPath, covered := l.fs.path(name), false
+		for _, locked := range paths {
+			if fullPath == locked || locked == "/" || strings.HasPrefix(fullPath, locked+"/") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return nil, webdav.ErrConfirmationFailed
+		}
+	}
+	return func() {}, nil
+}
+
+// Create implement webdav.LockSystem
+func (l *lockSystem) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	var (
+		err      error
+		lock     *models.FileLock
+		fullPath = l.fs.path(details.Root)
+	)
+
+	if !l.explicit {
+		if err = models.CheckPathLock(l.fs.app, fullPath, l.fs.owner, l.fs.db); err == models.ErrFileLocked {
+			return "", webdav.ErrLocked
+		}
+		return "", err
+	}
+
+	if err = l.fs.transaction(context.Background(), func(db *gorm.DB) error {
+		file, err := models.FindFileByPath(l.fs.app, fullPath, db)
+		if err != nil {
+			if !gorm.IsRecordNotFoundError(err) {
+				return err
+			}
+			if err = models.CheckPathLock(l.fs.app, fullPath, l.fs.owner, db); err != nil {
+				return err
+			}
+			if file, err = models.CreateFileFromReader(
+				l.fs.app, fullPath, strings.NewReader(""), 0, l.fs.rootPath, db); err != nil {
+				return err
+			}
+		}
+		lock, err = models.AcquireFileLock(file, l.fs.owner, true, lockDuration(details.Duration), db)
+		return err
+	}); err != nil {
+		if err == models.ErrFileLocked {
+			return "", webdav.ErrLocked
+		}
+		return "", err
+	}
+	return lockTokenPrefix + lock.UID, nil
+}
+
+// Refresh implement webdav.LockSystem
+func (l *lockSystem) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	lock, file, err := l.find(token)
+	if err != nil {
+		return webdav.LockDetails{}, err
+	}
+	switch err = lock.Refresh(l.fs.owner, lockDuration(duration), l.fs.db); err {
+	case nil:
+	case models.ErrFileLockNotOwned:
+		return webdav.LockDetails{}, webdav.ErrLocked
+	case models.ErrFileLockExpired:
+		return webdav.LockDetails{}, webdav.ErrNoSuchLock
+	default:
+		return webdav.LockDetails{}, err
+	}
+	return webdav.LockDetails{Root: l.fs.name(file.FullPath), Duration: duration}, nil
+}
+
+// Unlock implement webdav.LockSystem
+func (l *lockSystem) Unlock(now time.Time, token string) error {
+	lock, _, err := l.find(token)
+	if err != nil {
+		return err
+	}
+	if err = lock.Release(l.fs.owner, l.fs.db); err == models.ErrFileLockNotOwned {
+		return webdav.ErrForbidden
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+// Package webdav implement a WebDAV server of the file tree, so that it can be
+// mounted as a network drive. The requests are authenticated by basic auth like
+// ftp, the username is the uid of app, or the uid of token with prefix "token:",
+// and the password is the secret. The session of token is scoped to the path
+// of token.
+package webdav
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/bigfile/bigfile/databases"
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/bigfile/bigfile/internal/util"
+	"github.com/bigfile/bigfile/log"
+	"github.com/bigfile/bigfile/service"
+	"github.com/jinzhu/gorm"
+	"golang.org/x/net/webdav"
+)
+
+var (
+	// ErrTokenPassword represent the password of token is wrong
+	ErrTokenPassword = errors.New("token password validate failed")
+
+	// ErrAppPassword represent the password of app is wrong
+	ErrAppPassword = errors.New("app password validate failed")
+
+	// ErrTokenNotFound represent that wrong token is being used
+	ErrTokenNotFound = errors.New("token not found")
+
+	// errNoCredentials represent that the request isn't authenticated
+	errNoCredentials = errors.New("authentication is required")
+
+	tokenPrefix = "token:"
+)
+
+// readOnlyMethods are the methods that don't modify files
+var readOnlyMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	"PROPFIND":         true,
+}
+
+// Server is the http handler of WebDAV server
+type Server struct {
+	// Prefix is the url path prefix that is stripped from the names of files
+	Prefix string
+
+	db       *gorm.DB
+	rootPath *string
+}
+
+// NewServer return a WebDAV server that uses db to save files, the default
+// connection is used if db is nil
+func NewServer(prefix string, db *gorm.DB) *Server {
+	if db == nil {
+		db = databases.MustNewConnection(nil)
+	}
+	return &Server{Prefix: strings.TrimSuffix(prefix, "/"), db: db}
+}
+
+// statusRecorder is used to record the status code of response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implement http.ResponseWriter
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// bodyReader record the error of reading request body, so that an interrupted
+// upload can be discarded instead of being saved partially
+type bodyReader struct {
+	body io.ReadCloser
+	fs   *fileSystem
+}
+
+// Read implement io.Reader
+func (b *bodyReader) Read(p []byte) (n int, err error) {
+	n, err = b.body.Read(p)
+	b.fs.recordError(err)
+	return n, err
+}
+
+// Close implement io.Closer
+func (b *bodyReader) Close() error {
+	return b.body.Close()
+}
+
+// authenticate is used to find the app and token of basic auth, token is nil
+// when logging in with app
+func (s *Server) authenticate(r *http.Request) (app *models.App, token *models.Token, err error) {
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, nil, errNoCredentials
+	}
+	if strings.HasPrefix(name, tokenPrefix) {
+		if token, err = models.FindTokenByUID(strings.TrimPrefix(name, tokenPrefix), s.db); err != nil {
+			return nil, nil, ErrTokenNotFound
+		}
+		if token.Secret != nil && subtle.ConstantTimeCompare([]byte(password), []byte(*token.Secret)) != 1 {
+			return nil, nil, ErrTokenPassword
+		}
+		return &token.App, token, nil
+	}
+	if app, err = models.FindAppByUID(name, s.db); err != nil ||
+		subtle.ConstantTimeCompare([]byte(password), []byte(app.Secret)) != 1 {
+		return nil, nil, ErrAppPassword
+	}
+	return app, nil, nil
+}
+
+// ServeHTTP implement http.Handler, every request is recorded in the requests
+// table with protocol webdav
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		err      error
+		app      *models.App
+		token    *models.Token
+		ip       = util.ClientIP(r)
+		method   = r.Method
+		resource = r.URL.Path
+		recorder = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		record   = &models.Request{Protocol: "webdav", IP: &ip, Method: &method, Service: &resource}
+	)
+
+	s.db.Create(record)
+	defer func() {
+		s.db.Model(record).Updates(map[string]interface{}{
+			"appId":        record.AppID,
+			"token":        record.Token,
+			"responseCode": recorder.status,
+		})
+	}()
+
+	if app, token, err = s.authenticate(r); err != nil {
+		recorder.Header().Set("WWW-Authenticate", `Basic realm="bigfile"`)
+		http.Error(recorder, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	fs := &fileSystem{db: s.db, app: app, root: "/", owner: app.UID, rootPath: s.rootPath}
+	record.AppID = &app.ID
+	if token != nil {
+		if err = service.ValidateToken(s.db, &ip, readOnlyMethods[r.Method], token); err == nil {
+			err = token.UpdateAvailableTimes(-1, s.db)
+		}
+		if err != nil {
+			http.Error(recorder, err.Error(), http.StatusForbidden)
+			return
+		}
+		record.Token = &token.UID
+		fs.root, fs.owner = path.Clean("/"+token.Path), token.UID
+	}
+
+	r.Body = &bodyReader{body: r.Body, fs: fs}
+	handler := &webdav.Handler{
+		Prefix:     s.Prefix,
+		FileSystem: fs,
+		LockSystem: &lockSystem{fs: fs, explicit: r.Method == "LOCK"},
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				log.MustNewLogger(nil).Debugf("webdav request %d %s %s: %s", record.ID, r.Method, r.URL.Path, err)
+			}
+		},
+	}
+	handler.ServeHTTP(recorder, r)
+}
@@ -0,0 +1,266 @@
+//  Copyright 2019 The bigfile Authors. All rights reserved.
+//  Use of this source code is governed by a MIT-style
+//  license that can be found in the LICENSE file.
+
+package webdav
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigfile/bigfile/databases/models"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/webdav"
+)
+
+func newServerForTest(t *testing.T) (*Server, *models.App, *gorm.DB, func(*testing.T)) {
+	app, trx, down, err := models.NewAppForTest(nil, t)
+	assert.Nil(t, err)
+	tempDir := models.NewTempDirForTest()
+	return &Server{db: trx, rootPath: &tempDir}, app, trx, func(t *testing.T) {
+		down(t)
+		_ = os.RemoveAll(tempDir)
+	}
+}
+
+// doRequest send a request with basic auth to server
+func doRequest(
+	s *Server, method, target, body, user, password string, header map[string]string,
+) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if user != "" {
+		r.SetBasicAuth(user, password)
+	}
+	for name, value := range header {
+		r.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestFileSystem_Path(t *testing.T) {
+	fs := &fileSystem{root: "/"}
+	assert.Equal(t, "/", fs.path(""))
+	assert.Equal(t, "/a/b", fs.path("/a/b/"))
+	assert.Equal(t, "/b", fs.path("/../../b"))
+	assert.Equal(t, "/a", fs.name("/a"))
+	assert.True(t, fs.contains("/a"))
+
+	fs = &fileSystem{root: "/scope"}
+	assert.Equal(t, "/scope", fs.path("/"))
+	assert.Equal(t, "/scope/b", fs.path("/../b"))
+	assert.Equal(t, "/b", fs.name("/scope/b"))
+	assert.Equal(t, "/", fs.name("/scope"))
+	assert.True(t, fs.contains("/scope"))
+	assert.True(t, fs.contains("/scope/b"))
+	assert.False(t, fs.contains("/scope2"))
+}
+
+func TestFileInfo(t *testing.T) {
+	info := &fileInfo{name: "a.txt", hash: "hash"}
+	etag, err := info.ETag(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, `"hash"`, etag)
+	contentType, err := info.ContentType(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", contentType)
+	assert.Equal(t, os.ModePerm, info.Mode())
+
+	info = &fileInfo{isDir: true}
+	_, err = info.ETag(context.TODO())
+	assert.Equal(t, webdav.ErrNotImplemented, err)
+	assert.Equal(t, "/", info.Name())
+	assert.True(t, info.Mode().IsDir())
+}
+
+func TestLockDuration(t *testing.T) {
+	assert.Equal(t, maxLockDuration, lockDuration(-1))
+	assert.Equal(t, maxLockDuration, lockDuration(48*time.Hour))
+	assert.Equal(t, time.Minute, lockDuration(time.Minute))
+}
+
+func TestServer_Authenticate(t *testing.T) {
+	server, app, trx, down := newServerForTest(t)
+	defer down(t)
+
+	w := doRequest(server, "PROPFIND", "/", "", "", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+
+	w = doRequest(server, "PROPFIND", "/", "", app.UID, "wrong secret", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(server, "PROPFIND", "/", "", tokenPrefix+"unknown", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(server, "PROPFIND", "/", "", app.UID, app.Secret, map[string]string{"Depth": "0"})
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+
+	record := &models.Request{}
+	assert.Nil(t, trx.Where("protocol = ?", "webdav").Order("id desc").First(record).Error)
+	assert.Equal(t, app.ID, *record.AppID)
+	assert.Equal(t, http.StatusMultiStatus, record.ResponseCode)
+}
+
+func TestServer_Files(t *testing.T) {
+	server, app, _, down := newServerForTest(t)
+	defer down(t)
+
+	w := doRequest(server, "MKCOL", "/a/b", "", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doRequest(server, "MKCOL", "/a", "", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doRequest(server, "MKCOL", "/a", "", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = doRequest(server, http.MethodPut, "/a/hello.txt", "hello world", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"`, w.Header().Get("ETag"))
+	w = doRequest(server, http.MethodPut, "/b/hello.txt", "hello world", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doRequest(server, http.MethodGet, "/a/hello.txt", "", app.UID, app.Secret,
+		map[string]string{"Range": "bytes=6-"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "world", w.Body.String())
+
+	w = doRequest(server, http.MethodPut, "/a/hello.txt", "overwritten", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doRequest(server, http.MethodGet, "/a/hello.txt", "", app.UID, app.Secret, nil)
+	assert.Equal(t, "overwritten", w.Body.String())
+
+	w = doRequest(server, "PROPFIND", "/a/", "", app.UID, app.Secret, map[string]string{"Depth": "1"})
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Contains(t, w.Body.String(), "/a/hello.txt")
+	assert.Contains(t, w.Body.String(), ">11</")
+
+	w = doRequest(server, "COPY", "/a/hello.txt", "", app.UID, app.Secret,
+		map[string]string{"Destination": "http://example.com/a/copied.txt"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doRequest(server, http.MethodGet, "/a/copied.txt", "", app.UID, app.Secret, nil)
+	assert.Equal(t, "overwritten", w.Body.String())
+
+	w = doRequest(server, "MOVE", "/a", "", app.UID, app.Secret,
+		map[string]string{"Destination": "http://example.com/c"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doRequest(server, http.MethodGet, "/c/copied.txt", "", app.UID, app.Secret, nil)
+	assert.Equal(t, "overwritten", w.Body.String())
+	w = doRequest(server, http.MethodGet, "/a/copied.txt", "", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doRequest(server, http.MethodDelete, "/c/hello.txt", "", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(server, http.MethodGet, "/c/hello.txt", "", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// the deleted file is restored by uploading
+	w = doRequest(server, http.MethodPut, "/c/hello.txt", "restored", app.UID, app.Secret, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doRequest(server, http.MethodGet, "/c/hello.txt", "", app.UID, app.Secret, nil)
+	assert.Equal(t, "restored", w.Body.String())
+}
+
+// failingReader return an error after the content
+type failingReader struct {
+	content io.Reader
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.content.Read(p)
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func TestServer_InterruptedUpload(t *testing.T) {
+	server, app, trx, down := newServerForTest(t)
+	defer down(t)
+
+	r := httptest.NewRequest(http.MethodPut, "/hello.txt", &failingReader{content: strings.NewReader("hello")})
+	r.SetBasicAuth(app.UID, app.Secret)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	_, err := models.FindFileByPath(app, "/hello.txt", trx)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestServer_Lock(t *testing.T) {
+	server, app, trx, down := newServerForTest(t)
+	defer down(t)
+
+	lockBody := `<?xml version="1.0" encoding="utf-8"?><D:lockinfo xmlns:D="DAV:">` +
+		`<D:lockscope><D:exclusive/></D:lockscope><D:locktype><D:write/></D:locktype>` +
+		`<D:owner>tester</D:owner></D:lockinfo>`
+	w := doRequest(server, "LOCK", "/locked.txt", lockBody, app.UID, app.Secret,
+		map[string]string{"Timeout": "Second-600"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	token := strings.Trim(w.Header().Get("Lock-Token"), "<>")
+	assert.True(t, strings.HasPrefix(token, lockTokenPrefix))
+
+	lock, err := models.FindFileLockByUID(strings.TrimPrefix(token, lockTokenPrefix), trx)
+	assert.Nil(t, err)
+	assert.Equal(t, app.UID, lock.Owner)
+	assert.Equal(t, int8(1), lock.Exclusive)
+
+	// the locked file can't be modified by others
+	other, err := models.NewToken(app, "/", nil, nil, nil, -1, 0, trx)
+	assert.Nil(t, err)
+	w = doRequest(server, http.MethodPut, "/locked.txt", "hello", tokenPrefix+other.UID, "", nil)
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	w = doRequest(server, http.MethodPut, "/locked.txt", "hello", app.UID, app.Secret,
+		map[string]string{"If": "(<" + token + ">)"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doRequest(server, http.MethodPut, "/locked.txt", "hello", app.UID, app.Secret,
+		map[string]string{"If": "(<" + lockTokenPrefix + "unknown>)"})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = doRequest(server, "LOCK", "/locked.txt", "", app.UID, app.Secret,
+		map[string]string{"If": "(<" + token + ">)", "Timeout": "Second-1200"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, regexp.MustCompile("Second-1200"), w.Body.String())
+
+	w = doRequest(server, "UNLOCK", "/locked.txt", "", app.UID, app.Secret,
+		map[string]string{"Lock-Token": "<" + token + ">"})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	_, err = models.FindFileLockByUID(lock.UID, trx)
+	assert.True(t, gorm.IsRecordNotFoundError(err))
+}
+
+func TestServer_Token(t *testing.T) {
+	secret := models.RandomWithMD5(32)
+	token, trx, down, err := models.NewTokenForTest(nil, t, "/scope", nil, nil, &secret, -1, 1)
+	assert.Nil(t, err)
+	defer down(t)
+	tempDir := models.NewTempDirForTest()
+	defer os.RemoveAll(tempDir)
+	server := &Server{db: trx, rootPath: &tempDir}
+
+	w := doRequest(server, http.MethodPut, "/scope/a.txt", "hello", token.App.UID, token.App.Secret, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	user := tokenPrefix + token.UID
+	w = doRequest(server, http.MethodGet, "/a.txt", "", user, "wrong secret", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doRequest(server, http.MethodGet, "/a.txt", "", user, secret, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+	w = doRequest(server, http.MethodGet, "/../scope/a.txt", "", user, secret, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// the token is read only
+	w = doRequest(server, http.MethodPut, "/b.txt", "hello", user, secret, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}